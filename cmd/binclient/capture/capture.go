@@ -0,0 +1,181 @@
+// Package capture implements the file format of network traffic captures.
+//
+// The capture file starts with the magic bytes, the format version and the start time of capture in nanoseconds
+// since the Unix epoch. The records follow, each of them is the kind byte, the time passed since the previous record
+// in microseconds as uvarint, the length of data as uvarint and the data itself. The data of record is the handshake
+// or the message exactly as it was sent over the network.
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	magic   = "WCAP"
+	version = 1
+
+	// MaxRecordSize limits the size of record data, it's a bit larger than the maximum size of network message.
+	MaxRecordSize = 128 * 1024 * 1024
+
+	headerSize = len(magic) + 1 + 8
+)
+
+// Direction of the recorded data relative to the recording side.
+type Direction byte
+
+const (
+	Inbound Direction = iota
+	Outbound
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Inbound:
+		return "in"
+	case Outbound:
+		return "out"
+	default:
+		return fmt.Sprintf("Direction(%d)", byte(d))
+	}
+}
+
+const (
+	directionMask byte = 0x01
+	handshakeFlag byte = 0x02
+)
+
+// Record is the handshake or the message received or sent at the time.
+type Record struct {
+	Time      time.Time
+	Direction Direction
+	Handshake bool
+	Data      []byte
+}
+
+func (r Record) kind() byte {
+	k := byte(r.Direction) & directionMask
+	if r.Handshake {
+		k |= handshakeFlag
+	}
+	return k
+}
+
+// Writer writes records to the capture. It's not safe for concurrent use.
+type Writer struct {
+	w    *bufio.Writer
+	last time.Time
+	buf  [1 + 2*binary.MaxVarintLen64]byte
+}
+
+// NewWriter writes the header of capture started at the given time and returns the Writer of records.
+func NewWriter(w io.Writer, start time.Time) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = append(header, version)
+	header = binary.BigEndian.AppendUint64(header, uint64(start.UnixNano()))
+	if _, err := bw.Write(header); err != nil {
+		return nil, errors.Wrap(err, "failed to write capture header")
+	}
+	return &Writer{w: bw, last: time.Unix(0, start.UnixNano())}, nil
+}
+
+// Write writes the record. Time of record is truncated to microseconds, the records are expected to be written in
+// chronological order, earlier records are stored with the time of previous one.
+func (w *Writer) Write(r Record) error {
+	if len(r.Data) > MaxRecordSize {
+		return errors.Errorf("record of size %d exceeds the maximum size %d", len(r.Data), MaxRecordSize)
+	}
+	var delta uint64
+	if d := r.Time.Sub(w.last) / time.Microsecond; d > 0 {
+		delta = uint64(d)
+	}
+	w.last = w.last.Add(time.Duration(delta) * time.Microsecond)
+	b := w.buf[:0]
+	b = append(b, r.kind())
+	b = binary.AppendUvarint(b, delta)
+	b = binary.AppendUvarint(b, uint64(len(r.Data)))
+	if _, err := w.w.Write(b); err != nil {
+		return errors.Wrap(err, "failed to write record")
+	}
+	if _, err := w.w.Write(r.Data); err != nil {
+		return errors.Wrap(err, "failed to write record")
+	}
+	return nil
+}
+
+// Flush writes the buffered records to the underlying writer.
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Reader reads records of the capture.
+type Reader struct {
+	r     *bufio.Reader
+	start time.Time
+	last  time.Time
+}
+
+// NewReader reads the header of capture and returns the Reader of records.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, errors.Wrap(err, "failed to read capture header")
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, errors.New("not a capture file")
+	}
+	if v := header[len(magic)]; v != version {
+		return nil, errors.Errorf("unsupported capture version %d", v)
+	}
+	start := time.Unix(0, int64(binary.BigEndian.Uint64(header[len(magic)+1:])))
+	return &Reader{r: br, start: start, last: start}, nil
+}
+
+// Start returns the start time of capture.
+func (r *Reader) Start() time.Time {
+	return r.start
+}
+
+// Read returns the next record of the capture or io.EOF at the end of it.
+func (r *Reader) Read() (Record, error) {
+	k, err := r.r.ReadByte()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Record{}, io.EOF
+		}
+		return Record{}, errors.Wrap(err, "failed to read record kind")
+	}
+	if k&^(directionMask|handshakeFlag) != 0 {
+		return Record{}, errors.Errorf("invalid record kind %d", k)
+	}
+	delta, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return Record{}, errors.Wrap(err, "failed to read record time")
+	}
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return Record{}, errors.Wrap(err, "failed to read record size")
+	}
+	if size > MaxRecordSize {
+		return Record{}, errors.Errorf("record size %d exceeds the maximum size %d", size, MaxRecordSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return Record{}, errors.Wrap(err, "failed to read record data")
+	}
+	r.last = r.last.Add(time.Duration(delta) * time.Microsecond)
+	return Record{
+		Time:      r.last,
+		Direction: Direction(k & directionMask),
+		Handshake: k&handshakeFlag != 0,
+		Data:      data,
+	}, nil
+}
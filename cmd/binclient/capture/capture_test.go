@@ -0,0 +1,65 @@
+package capture
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRead(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	records := []Record{
+		{Time: start.Add(time.Millisecond), Direction: Outbound, Handshake: true, Data: []byte{1, 2, 3}},
+		{Time: start.Add(2 * time.Millisecond), Direction: Inbound, Handshake: true, Data: []byte{4, 5}},
+		{Time: start.Add(3*time.Second + 1500*time.Nanosecond), Direction: Inbound, Data: []byte{6}},
+		{Time: start.Add(3 * time.Second), Direction: Outbound, Data: []byte{}},
+		{Time: start.Add(time.Hour), Direction: Inbound, Data: bytes.Repeat([]byte{7}, 1000)},
+	}
+	expectedTimes := []time.Duration{
+		time.Millisecond, 2 * time.Millisecond, 3*time.Second + time.Microsecond, 3*time.Second + time.Microsecond,
+		time.Hour,
+	}
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(buf, start)
+	require.NoError(t, err)
+	for _, r := range records {
+		require.NoError(t, w.Write(r))
+	}
+	require.NoError(t, w.Flush())
+
+	r, err := NewReader(buf)
+	require.NoError(t, err)
+	assert.True(t, start.Equal(r.Start()))
+	for i, expected := range records {
+		actual, rErr := r.Read()
+		require.NoError(t, rErr)
+		assert.Equal(t, expected.Direction, actual.Direction)
+		assert.Equal(t, expected.Handshake, actual.Handshake)
+		assert.Equal(t, expected.Data, actual.Data)
+		assert.Equal(t, expectedTimes[i], actual.Time.Sub(start))
+	}
+	_, err = r.Read()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestReadInvalid(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("WCAX\x01\x00\x00\x00\x00\x00\x00\x00\x00")))
+	assert.EqualError(t, err, "not a capture file")
+	_, err = NewReader(bytes.NewReader([]byte("WCAP\x02\x00\x00\x00\x00\x00\x00\x00\x00")))
+	assert.EqualError(t, err, "unsupported capture version 2")
+
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(buf, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, w.Write(Record{Time: time.Now(), Data: []byte{1, 2, 3, 4}}))
+	require.NoError(t, w.Flush())
+	truncated := buf.Bytes()[:buf.Len()-1]
+	r, err := NewReader(bytes.NewReader(truncated))
+	require.NoError(t, err)
+	_, err = r.Read()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
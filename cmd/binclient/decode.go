@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/mr-tron/base58"
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/cmd/binclient/capture"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const decodeUsage = `
+Usage:
+  binclient decode -capture <path> [flags]
+
+Prints the handshakes and messages of capture file.
+
+Flags:
+`
+
+func decode(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("decode", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), decodeUsage)
+		fs.PrintDefaults()
+	}
+	path := fs.String("capture", "", "Path to capture file.")
+	verbose := fs.Bool("verbose", false, "Print the blocks, microblocks, transactions and snapshots as JSON.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("option capture is not specified")
+	}
+	f, err := os.Open(*path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open capture")
+	}
+	defer func() { _ = f.Close() }()
+	r, err := capture.NewReader(f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Capture started at %s\n", r.Start().UTC().Format("2006-01-02T15:04:05.000000Z"))
+	scheme := proto.MainNetScheme
+	for i := 0; ; i++ {
+		rec, rErr := r.Read()
+		if rErr != nil {
+			if errors.Is(rErr, io.EOF) {
+				return nil
+			}
+			return errors.Wrapf(rErr, "failed to read record %d", i)
+		}
+		prefix := fmt.Sprintf("%6d %14.6f %-3s", i, rec.Time.Sub(r.Start()).Seconds(), rec.Direction)
+		if rec.Handshake {
+			h, hErr := parseHandshake(rec.Data)
+			if hErr != nil {
+				fmt.Printf("%s INVALID HANDSHAKE: %v: %s\n", prefix, hErr, dataPrefix(rec.Data))
+				continue
+			}
+			// Address scheme of the network is the last character of application name.
+			if rec.Direction == capture.Inbound && len(h.AppName) > 0 {
+				scheme = h.AppName[len(h.AppName)-1]
+			}
+			fmt.Printf("%s Handshake %s\n", prefix, describeHandshake(h))
+			continue
+		}
+		m, mErr := proto.UnmarshalMessage(rec.Data)
+		if mErr != nil {
+			fmt.Printf("%s INVALID MESSAGE: %v: %s\n", prefix, mErr, dataPrefix(rec.Data))
+			continue
+		}
+		summary, details, dErr := describe(scheme, m)
+		if dErr != nil {
+			fmt.Printf("%s %s INVALID: %v\n", prefix, summary, dErr)
+			continue
+		}
+		fmt.Printf("%s %s\n", prefix, summary)
+		if *verbose && details != nil {
+			js, jErr := json.MarshalIndent(details, "", "  ")
+			if jErr != nil {
+				return errors.Wrapf(jErr, "failed to marshal record %d", i)
+			}
+			fmt.Println(string(js))
+		}
+	}
+}
+
+func describeHandshake(h proto.Handshake) string {
+	return fmt.Sprintf("app '%s' version %s node '%s' nonce %d declared address '%s' timestamp %d",
+		h.AppName, h.Version.String(), h.NodeName, h.NodeNonce, h.DeclaredAddr.String(), h.Timestamp)
+}
+
+// dataPrefix returns the Base58 representation of the first bytes of data.
+func dataPrefix(data []byte) string {
+	const maxLen = 64
+	if len(data) > maxLen {
+		return base58.Encode(data[:maxLen]) + "..."
+	}
+	return base58.Encode(data)
+}
+
+// describe returns the summary of message and the decoded entity carried by the message, if any.
+func describe(scheme proto.Scheme, m proto.Message) (string, any, error) {
+	switch msg := m.(type) {
+	case *proto.GetPeersMessage:
+		return "GetPeers", nil, nil
+	case *proto.PeersMessage:
+		peers := make([]string, len(msg.Peers))
+		for i, p := range msg.Peers {
+			peers[i] = p.String()
+		}
+		return fmt.Sprintf("Peers [%s]", strings.Join(peers, ", ")), nil, nil
+	case *proto.GetSignaturesMessage:
+		return "GetSignatures " + describeSignatures(msg.Signatures), nil, nil
+	case *proto.SignaturesMessage:
+		return "Signatures " + describeSignatures(msg.Signatures), nil, nil
+	case *proto.GetBlockIDsMessage:
+		return "GetBlockIDs " + describeBlockIDs(msg.Blocks), nil, nil
+	case *proto.BlockIDsMessage:
+		return "BlockIDs " + describeBlockIDs(msg.Blocks), nil, nil
+	case *proto.GetBlockMessage:
+		return fmt.Sprintf("GetBlock '%s'", msg.BlockID.String()), nil, nil
+	case *proto.BlockMessage:
+		b := new(proto.Block)
+		if err := b.UnmarshalBinary(msg.BlockBytes, scheme); err != nil {
+			return "Block", nil, err
+		}
+		return "Block " + describeBlock(b), b, nil
+	case *proto.PBBlockMessage:
+		b := new(proto.Block)
+		if err := b.UnmarshalFromProtobuf(msg.PBBlockBytes); err != nil {
+			return "PBBlock", nil, err
+		}
+		return "PBBlock " + describeBlock(b), b, nil
+	case *proto.ScoreMessage:
+		return "Score " + new(big.Int).SetBytes(msg.Score).String(), nil, nil
+	case *proto.TransactionMessage:
+		tx, err := proto.BytesToTransaction(msg.Transaction, scheme)
+		if err != nil {
+			return "Transaction", nil, err
+		}
+		return describeTransaction("Transaction", scheme, tx)
+	case *proto.PBTransactionMessage:
+		tx, err := proto.SignedTxFromProtobuf(msg.Transaction)
+		if err != nil {
+			return "PBTransaction", nil, err
+		}
+		return describeTransaction("PBTransaction", scheme, tx)
+	case *proto.MicroBlockMessage:
+		mb := new(proto.MicroBlock)
+		if err := mb.UnmarshalBinary(msg.Body, scheme); err != nil {
+			return "MicroBlock", nil, err
+		}
+		return "MicroBlock " + describeMicroBlock(mb), mb, nil
+	case *proto.PBMicroBlockMessage:
+		mb := new(proto.MicroBlock)
+		if err := mb.UnmarshalFromProtobuf(msg.MicroBlockBytes); err != nil {
+			return "PBMicroBlock", nil, err
+		}
+		return "PBMicroBlock " + describeMicroBlock(mb), mb, nil
+	case *proto.MicroBlockInvMessage:
+		inv := new(proto.MicroBlockInv)
+		if err := inv.UnmarshalBinary(msg.Body); err != nil {
+			return "MicroBlockInv", nil, err
+		}
+		return fmt.Sprintf("MicroBlockInv '%s' reference '%s' generator '%s'",
+			inv.TotalBlockID.String(), inv.Reference.String(), inv.PublicKey.String()), nil, nil
+	case *proto.MicroBlockRequestMessage:
+		return fmt.Sprintf("MicroBlockRequest '%s'", msg.TotalBlockSig.String()), nil, nil
+	case *proto.GetBlockSnapshotMessage:
+		return fmt.Sprintf("GetBlockSnapshot '%s'", msg.BlockID.String()), nil, nil
+	case *proto.MicroBlockSnapshotRequestMessage:
+		return fmt.Sprintf("MicroBlockSnapshotRequest '%s'", msg.BlockID.String()), nil, nil
+	case *proto.BlockSnapshotMessage:
+		s := new(g.BlockSnapshot)
+		if err := s.UnmarshalVT(msg.Bytes); err != nil {
+			return "BlockSnapshot", nil, err
+		}
+		return describeSnapshot("BlockSnapshot", scheme, s.BlockId, s.Snapshots)
+	case *proto.MicroBlockSnapshotMessage:
+		s := new(g.MicroBlockSnapshot)
+		if err := s.UnmarshalVT(msg.Bytes); err != nil {
+			return "MicroBlockSnapshot", nil, err
+		}
+		return describeSnapshot("MicroBlockSnapshot", scheme, s.TotalBlockId, s.Snapshots)
+	default:
+		return fmt.Sprintf("%T", m), nil, nil
+	}
+}
+
+func describeSignatures(sigs proto.Signatures) string {
+	if len(sigs) == 0 {
+		return "[]"
+	}
+	return fmt.Sprintf("[%d] '%s'...'%s'", len(sigs), sigs[0].String(), sigs[len(sigs)-1].String())
+}
+
+func describeBlockIDs(ids proto.BlockIDsPayload) string {
+	if len(ids) == 0 {
+		return "[]"
+	}
+	return fmt.Sprintf("[%d] '%s'...'%s'", len(ids), ids[0].String(), ids[len(ids)-1].String())
+}
+
+func describeBlock(b *proto.Block) string {
+	return fmt.Sprintf("'%s' v%d parent '%s' generator '%s' timestamp %d transactions %d",
+		b.BlockID().String(), b.Version, b.Parent.String(), b.GeneratorPublicKey.String(), b.Timestamp,
+		b.TransactionCount)
+}
+
+func describeMicroBlock(mb *proto.MicroBlock) string {
+	return fmt.Sprintf("'%s' v%d reference '%s' generator '%s' transactions %d",
+		mb.TotalBlockID.String(), mb.VersionField, mb.Reference.String(), mb.SenderPK.String(), mb.TransactionCount)
+}
+
+func describeTransaction(name string, scheme proto.Scheme, tx proto.Transaction) (string, any, error) {
+	id, err := tx.GetID(scheme)
+	if err != nil {
+		return name, nil, err
+	}
+	return fmt.Sprintf("%s '%s' %s", name, base58.Encode(id), tx.GetTypeInfo().String()), tx, nil
+}
+
+func describeSnapshot(
+	name string, scheme proto.Scheme, id []byte, snapshots []*g.TransactionStateSnapshot,
+) (string, any, error) {
+	blockID, err := proto.NewBlockIDFromBytes(id)
+	if err != nil {
+		return name, nil, err
+	}
+	bs, err := proto.BlockSnapshotFromProtobuf(scheme, snapshots)
+	if err != nil {
+		return name, nil, err
+	}
+	return fmt.Sprintf("%s '%s' transactions %d", name, blockID.String(), len(bs.TxSnapshots)), bs, nil
+}
@@ -2,148 +2,187 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/binary"
 	"flag"
+	"fmt"
 	"io"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
-	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/cmd/binclient/capture"
+	"github.com/wavesplatform/gowaves/pkg/logging"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 )
 
-var (
-	wavesNetwork = flag.String("waves-network", "", "Waves network.")
-	address      = flag.String("address", "", "Address connect to.")
-	version      = flag.String("version", "", "Version, for example: (0.15.1).")
-)
+const usage = `
+Usage:
+  binclient [-log-level <level>] <command> [flags]
 
-func printCLIArgsToLog() {
-	type cliArgs struct {
-		wavesNetwork string
-		address      string
-		version      string
-	}
-	cli := cliArgs{
-		wavesNetwork: *wavesNetwork,
-		address:      *address,
-		version:      *version,
-	}
+Commands:
+  record  Connect to the peer and record every handshake and message to capture file
+  replay  Replay the messages received by recording side into the node
+  decode  Print the handshakes and messages of capture file
 
-	zap.S().Infof("CLI args: %+v", cli)
-}
+Run 'binclient <command> -h' to get the flags of command.
 
-func init() {
-	flag.Parse()
-	logger, _ := zap.NewDevelopment()
-	zap.ReplaceGlobals(logger)
-	printCLIArgsToLog()
-}
+Flags:
+`
 
 func main() {
-	if *wavesNetwork == "" {
-		zap.S().Fatal("please, provide 'waves-network' CLI argument")
-	}
-	if *address == "" {
-		zap.S().Fatal("please, provide 'address' CLI argument")
-	}
-	if *version == "" {
-		zap.S().Fatal("please, provide 'version' CLI argument")
+	logLevel := zapcore.InfoLevel
+	flag.Usage = func() {
+		_, _ = fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
 	}
+	flag.Var(&logLevel, "log-level",
+		"Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL. Default logging level INFO.")
+	flag.Parse()
 
-	parsedVersion, err := proto.NewVersionFromString(*version)
-	if err != nil {
-		zap.S().Error(err)
-		return
+	commands := map[string]func(ctx context.Context, args []string) error{
+		"record": record,
+		"replay": replay,
+		"decode": decode,
 	}
-
-	handshake := proto.Handshake{
-		AppName:      *wavesNetwork,
-		Version:      parsedVersion,
-		NodeName:     "nodename",
-		NodeNonce:    0x0,
-		DeclaredAddr: proto.HandshakeTCPAddr{},
-		Timestamp:    proto.NewTimestampFromTime(time.Now()),
-	}
-
-	conn, err := net.Dial("tcp", *address)
-	if err != nil {
-		zap.S().Error(err)
-		return
+	command, ok := commands[flag.Arg(0)]
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
 	}
 
+	logger := logging.SetupSimpleLogger(logLevel)
 	defer func() {
-		if err := conn.Close(); err != nil {
-			zap.S().Errorf("failed to close connection: %v", err)
+		err := logger.Sync()
+		if err != nil && errors.Is(err, os.ErrInvalid) {
+			panic(fmt.Sprintf("Failed to close logging subsystem: %v\n", err))
 		}
 	}()
 
-	_, err = handshake.WriteTo(conn)
-	if err != nil {
-		zap.S().Error(err)
-		return
-	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
-	zap.S().Info("reading handshake")
-	readH := proto.Handshake{}
-	_, err = readH.ReadFrom(bufio.NewReader(conn))
-	if err != nil {
-		zap.S().Error(err)
-		return
+	if err := command(ctx, flag.Args()[1:]); err != nil {
+		zap.S().Errorf("Failed to %s: %v", flag.Arg(0), err)
+		cancel()
+		os.Exit(1)
 	}
+}
 
-	zap.S().Infof("readed handshake %+v", readH)
+// readHandshake reads the handshake and returns it along with its bytes.
+func readHandshake(r io.Reader) (proto.Handshake, []byte, error) {
+	buf := new(bytes.Buffer)
+	var h proto.Handshake
+	if _, err := h.ReadFrom(io.TeeReader(r, buf)); err != nil {
+		return proto.Handshake{}, nil, errors.Wrap(err, "failed to read handshake")
+	}
+	return h, buf.Bytes(), nil
+}
 
-	go func() {
-		const expectedContentID = byte(proto.ContentIDSignatures)
+func parseHandshake(data []byte) (proto.Handshake, error) {
+	var h proto.Handshake
+	if _, err := h.ReadFrom(bytes.NewReader(data)); err != nil {
+		return proto.Handshake{}, err
+	}
+	return h, nil
+}
 
-		for {
-			bts, err := readPacket(conn)
-			if err != nil {
-				zap.S().Error(err)
-				return
-			}
+// readPacket reads the message as raw bytes, without parsing and validation apart from its length.
+func readPacket(r io.Reader) ([]byte, error) {
+	var packetLen [4]byte
+	if _, err := io.ReadFull(r, packetLen[:]); err != nil {
+		return nil, err
+	}
+	l := binary.BigEndian.Uint32(packetLen[:])
+	if l > capture.MaxRecordSize-uint32(len(packetLen)) {
+		return nil, errors.Errorf("too long message of size %d", l)
+	}
+	buf := make([]byte, l+4)
+	copy(buf, packetLen[:])
+	if _, err := io.ReadFull(r, buf[4:]); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
 
-			zap.S().Info("readed==", bts)
+// connection records everything written to and read from the network connection, if the capture writer is set.
+type connection struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *capture.Writer
+}
 
-			if bts[proto.HeaderContentIDPosition] == expectedContentID {
-				zap.S().Info(bts)
-			}
-			continue
-		}
+func dial(ctx context.Context, address string, w *capture.Writer) (*connection, error) {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to '%s'", address)
+	}
+	go func() { // Unblock reads and writes on cancellation.
+		<-ctx.Done()
+		_ = c.Close()
 	}()
+	return &connection{conn: c, r: bufio.NewReader(c), w: w}, nil
+}
 
-	sig, _ := crypto.NewSignatureFromBase58("FSH8eAAzZNqnG8xgTZtz5xuLqXySsXgAjmFEC25hXMbEufiGjqWPnGCZFt6gLiVLJny16ipxRNAkkzjjhqTjBE2")
+func (c *connection) Close() error {
+	return c.conn.Close()
+}
 
-	sigs := proto.GetSignaturesMessage{
-		Signatures: []crypto.Signature{sig},
+func (c *connection) handshake(h proto.Handshake) (proto.Handshake, error) {
+	buf := new(bytes.Buffer)
+	if _, err := h.WriteTo(buf); err != nil {
+		return proto.Handshake{}, errors.Wrap(err, "failed to marshal handshake")
 	}
-
-	zap.S().Info("writing GetSignaturesMessage bytes")
-	_, err = sigs.WriteTo(conn)
+	if err := c.write(buf.Bytes(), true); err != nil {
+		return proto.Handshake{}, err
+	}
+	remote, data, err := readHandshake(c.r)
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return proto.Handshake{}, err
+	}
+	if err := c.record(capture.Inbound, true, data); err != nil {
+		return proto.Handshake{}, err
 	}
+	return remote, nil
+}
 
-	time.Sleep(5 * time.Minute)
+func (c *connection) send(m proto.Message) error {
+	data, err := m.MarshalBinary()
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %T", m)
+	}
+	return c.write(data, false)
+}
 
+func (c *connection) write(data []byte, handshake bool) error {
+	if _, err := c.conn.Write(data); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+	return c.record(capture.Outbound, handshake, data)
 }
 
-func readPacket(r io.Reader) ([]byte, error) {
-	packetLen := [4]byte{}
-	_, err := io.ReadFull(r, packetLen[:])
+func (c *connection) receive() ([]byte, error) {
+	data, err := readPacket(c.r)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "failed to read message")
 	}
-	l := binary.BigEndian.Uint32(packetLen[:])
-	buf := make([]byte, l+4)
-	copy(buf, packetLen[:])
-	_, err = io.ReadFull(r, buf[4:])
-	if err != nil {
+	if err := c.record(capture.Inbound, false, data); err != nil {
 		return nil, err
 	}
-	return buf, nil
+	return data, nil
+}
+
+func (c *connection) record(d capture.Direction, handshake bool, data []byte) error {
+	if c.w == nil {
+		return nil
+	}
+	return c.w.Write(capture.Record{Time: time.Now(), Direction: d, Handshake: handshake, Data: data})
 }
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/cmd/binclient/capture"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const recordUsage = `
+Usage:
+  binclient record -address <host:port> -capture <path> [flags]
+
+Connects to the peer and records every handshake and message, sent or received, to capture file until interrupted.
+Requests for peers are answered with an empty list. With the option sync-from the blocks after the given one are
+requested from the peer.
+
+Flags:
+`
+
+func record(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("record", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), recordUsage)
+		fs.PrintDefaults()
+	}
+	address := fs.String("address", "", "Address of the peer to connect to.")
+	path := fs.String("capture", "", "Path to capture file to create.")
+	wavesNetwork := fs.String("waves-network", "wavesW", "Name of Waves network: wavesW/wavesT/wavesS.")
+	version := fs.String("version", proto.ProtocolVersion().String(), "Version of protocol, for example: 1.5.0.")
+	nodeName := fs.String("node-name", "binclient", "Name of node to put in the handshake.")
+	nonce := fs.Uint64("nonce", uint64(time.Now().UnixNano()), "Nonce of node to put in the handshake.")
+	duration := fs.Duration("duration", 0, "Duration of recording, by default records until interrupted.")
+	syncFrom := fs.String("sync-from", "", "ID of block to request the following blocks from.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *address == "" {
+		return errors.New("option address is not specified")
+	}
+	if *path == "" {
+		return errors.New("option capture is not specified")
+	}
+	if *wavesNetwork == "" {
+		return errors.New("option waves-network is not specified")
+	}
+	v, err := proto.NewVersionFromString(*version)
+	if err != nil {
+		return errors.Wrap(err, "invalid version")
+	}
+	var from *proto.BlockID
+	if *syncFrom != "" {
+		id, idErr := proto.NewBlockIDFromBase58(*syncFrom)
+		if idErr != nil {
+			return errors.Wrap(idErr, "invalid block ID to sync from")
+		}
+		from = &id
+	}
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	f, err := os.Create(*path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create capture")
+	}
+	defer func() {
+		if cErr := f.Close(); cErr != nil {
+			zap.S().Errorf("Failed to close capture: %v", cErr)
+		}
+	}()
+	w, err := capture.NewWriter(f, time.Now())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if fErr := w.Flush(); fErr != nil {
+			zap.S().Errorf("Failed to write capture: %v", fErr)
+		}
+	}()
+
+	c, err := dial(ctx, *address, w)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	remote, err := c.handshake(proto.Handshake{
+		AppName:   *wavesNetwork,
+		Version:   v,
+		NodeName:  *nodeName,
+		NodeNonce: *nonce,
+		Timestamp: proto.NewTimestampFromTime(time.Now()),
+	})
+	if err != nil {
+		return err
+	}
+	zap.S().Infof("Connected to '%s': %s", *address, describeHandshake(remote))
+
+	r := recorder{c: c, scheme: proto.Scheme((*wavesNetwork)[len(*wavesNetwork)-1])}
+	if from != nil {
+		if err := r.requestBlockIDs(*from); err != nil {
+			return err
+		}
+	}
+	for ctx.Err() == nil {
+		if rErr := r.receive(); rErr != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			if errors.Is(rErr, io.EOF) {
+				zap.S().Info("Peer closed the connection")
+				break
+			}
+			return rErr
+		}
+	}
+	zap.S().Infof("Recorded %d messages", r.count)
+	return nil
+}
+
+type recorder struct {
+	c      *connection
+	scheme proto.Scheme
+	count  int
+	last   *proto.BlockID // The last block requested from the peer.
+}
+
+func (r *recorder) receive() error {
+	data, err := r.c.receive()
+	if err != nil {
+		return err
+	}
+	r.count++
+	m, err := proto.UnmarshalMessage(data)
+	if err != nil {
+		zap.S().Warnf("Invalid message of size %d: %v", len(data), err)
+		return nil
+	}
+	if summary, _, dErr := describe(r.scheme, m); dErr != nil {
+		zap.S().Warnf("%s: %v", summary, dErr)
+	} else {
+		zap.S().Debug(summary)
+	}
+	switch msg := m.(type) {
+	case *proto.GetPeersMessage:
+		return r.c.send(&proto.PeersMessage{})
+	case *proto.BlockIDsMessage:
+		if r.last == nil {
+			return nil
+		}
+		ids := msg.Blocks
+		if len(ids) > 0 && ids[0] == *r.last { // The last common block goes first.
+			ids = ids[1:]
+		}
+		if len(ids) == 0 {
+			zap.S().Infof("All blocks after '%s' are requested", r.last.String())
+			return nil
+		}
+		for _, id := range ids {
+			if err := r.c.send(&proto.GetBlockMessage{BlockID: id}); err != nil {
+				return err
+			}
+		}
+		return r.requestBlockIDs(ids[len(ids)-1])
+	default:
+		return nil
+	}
+}
+
+func (r *recorder) requestBlockIDs(last proto.BlockID) error {
+	r.last = &last
+	return r.c.send(&proto.GetBlockIDsMessage{Blocks: proto.BlockIDsPayload{last}})
+}
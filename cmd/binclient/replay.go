@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/cmd/binclient/capture"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const replayUsage = `
+Usage:
+  binclient replay -capture <path> [flags]
+
+Connects to the node with the handshake of recorded peer and sends the messages received from the peer during
+the recording, exactly as they were received and keeping the intervals between them. The messages sent by recording side are skipped.
+The messages from the node are read and logged with DEBUG level.
+
+Flags:
+`
+
+func replay(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(fs.Output(), replayUsage)
+		fs.PrintDefaults()
+	}
+	path := fs.String("capture", "", "Path to capture file.")
+	address := fs.String("address", "127.0.0.1:6868", "Address of the node to replay the capture into.")
+	speed := fs.Float64("speed", 1,
+		"Speed of replay relative to the recording, zero disables the delays between messages.")
+	linger := fs.Duration("linger", 5*time.Second,
+		"Time to keep the connection to the node open after the last message is sent.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("option capture is not specified")
+	}
+	if *speed < 0 {
+		return errors.New("option speed must not be negative")
+	}
+
+	f, err := os.Open(*path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open capture")
+	}
+	defer func() { _ = f.Close() }()
+	r, err := capture.NewReader(f)
+	if err != nil {
+		return err
+	}
+	h, start, err := recordedHandshake(r)
+	if err != nil {
+		return err
+	}
+	scheme := proto.MainNetScheme
+	if len(h.AppName) > 0 {
+		scheme = h.AppName[len(h.AppName)-1]
+	}
+	// The node must not try to connect back to the recorded peer.
+	h.DeclaredAddr = proto.HandshakeTCPAddr{}
+	h.Timestamp = proto.NewTimestampFromTime(time.Now())
+
+	c, err := dial(ctx, *address, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = c.Close() }()
+	local, err := c.handshake(h)
+	if err != nil {
+		return err
+	}
+	zap.S().Infof("Connected to '%s': %s", *address, describeHandshake(local))
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- readNode(c, scheme)
+	}()
+
+	p := player{r: r, c: c, speed: *speed, last: start}
+	if err := p.play(ctx, closed); err != nil {
+		return err
+	}
+	zap.S().Infof("Replayed %d messages", p.count)
+	select {
+	case <-ctx.Done():
+	case err := <-closed:
+		zap.S().Warnf("Node closed the connection: %v", err)
+	case <-time.After(*linger):
+	}
+	return nil
+}
+
+// recordedHandshake returns the first handshake received by the recording side and the time it was received.
+func recordedHandshake(r *capture.Reader) (proto.Handshake, time.Time, error) {
+	for {
+		rec, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return proto.Handshake{}, time.Time{}, errors.New("no handshake of peer in capture")
+			}
+			return proto.Handshake{}, time.Time{}, err
+		}
+		if rec.Handshake && rec.Direction == capture.Inbound {
+			h, hErr := parseHandshake(rec.Data)
+			if hErr != nil {
+				return proto.Handshake{}, time.Time{}, errors.Wrap(hErr, "invalid handshake of peer in capture")
+			}
+			return h, rec.Time, nil
+		}
+	}
+}
+
+// readNode reads the messages of node until the connection is closed.
+func readNode(c *connection, scheme proto.Scheme) error {
+	for {
+		data, err := c.receive()
+		if err != nil {
+			return err
+		}
+		m, err := proto.UnmarshalMessage(data)
+		if err != nil {
+			zap.S().Warnf("Invalid message from node of size %d: %v", len(data), err)
+			continue
+		}
+		summary, _, err := describe(scheme, m)
+		if err != nil {
+			zap.S().Warnf("Invalid message from node: %s: %v", summary, err)
+			continue
+		}
+		zap.S().Debugf("Node: %s", summary)
+	}
+}
+
+type player struct {
+	r     *capture.Reader
+	c     *connection
+	speed float64
+	last  time.Time // Time of the last sent record.
+	count int
+}
+
+// play sends the inbound messages of the capture to the node until the end of capture or closing of connection.
+func (p *player) play(ctx context.Context, closed <-chan error) error {
+	for {
+		rec, err := p.r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if rec.Handshake || rec.Direction != capture.Inbound {
+			continue
+		}
+		if p.speed > 0 {
+			delay := time.Duration(float64(rec.Time.Sub(p.last)) / p.speed)
+			select {
+			case <-ctx.Done():
+				return nil
+			case err := <-closed:
+				return errors.Wrapf(err, "node closed the connection after %d messages", p.count)
+			case <-time.After(delay):
+			}
+		}
+		p.last = rec.Time
+		if err := p.c.write(rec.Data, false); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrapf(err, "failed to send message %d", p.count+1)
+		}
+		p.count++
+	}
+}
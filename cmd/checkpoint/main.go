@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
+	"github.com/wavesplatform/gowaves/pkg/logging"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/util/fdlimit"
+	"github.com/wavesplatform/gowaves/pkg/versioning"
+)
+
+const (
+	exportCommand = "export"
+	importCommand = "import"
+	verifyCommand = "verify"
+
+	clearance      = 10
+	requestTimeout = 30 * time.Second
+)
+
+var usage = `
+Usage:
+  checkpoint <command> [flags]
+
+Commands:
+  export    Export the state of stopped node at the given height to the checkpoint directory
+  import    Verify the checkpoint with a trusted node and import it to the empty state directory of a new node
+  verify    Verify the checkpoint files and its block ID and state hash with a trusted node
+
+Run 'checkpoint <command> -help' to see the flags of command.
+`
+
+type config struct {
+	logLevel       zapcore.Level
+	statePath      string
+	checkpointPath string
+	blockchainType string
+	cfgPath        string
+	height         uint64
+	extendedAPI    bool
+	stateHashes    bool
+	dbBackend      string
+	node           string
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	cmd := os.Args[1]
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	c := config{logLevel: zapcore.InfoLevel}
+	fs.Var(&c.logLevel, "log-level",
+		"Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL. Default logging level INFO.")
+	fs.StringVar(&c.checkpointPath, "checkpoint-path", "", "Path to checkpoint directory.")
+	switch cmd {
+	case exportCommand, importCommand:
+		fs.StringVar(&c.statePath, "state-path", "", "Path to node's state directory.")
+		fs.StringVar(&c.blockchainType, "blockchain-type", "mainnet", "Blockchain type: mainnet/testnet/stagenet/custom.")
+		fs.StringVar(&c.cfgPath, "cfg-path", "", "Path to configuration JSON file, only for custom blockchain.")
+		fs.BoolVar(&c.extendedAPI, "build-extended-api", false,
+			"The state is built with extended API data, must match the flag the state was imported with.")
+		fs.BoolVar(&c.stateHashes, "build-state-hashes", false,
+			"The state is built with legacy state hashes, must match the flag the state was imported with.")
+		fs.StringVar(&c.dbBackend, "db-backend", keyvalue.LevelDBBackend.String(), "State database backend: leveldb/pebble.")
+		if cmd == exportCommand {
+			fs.Uint64Var(&c.height, "height", 0, "Height of checkpoint, the current height of state by default.")
+		} else {
+			fs.StringVar(&c.node, "node", "", "URL of trusted node's API to take the block header of checkpoint from.")
+		}
+	case verifyCommand:
+		fs.StringVar(&c.node, "node", "", "URL of trusted node's API to take the block header of checkpoint from.")
+	default:
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	logger := logging.SetupSimpleLogger(c.logLevel)
+	defer func() {
+		err := logger.Sync()
+		if err != nil && errors.Is(err, os.ErrInvalid) {
+			panic(fmt.Sprintf("Failed to close logging subsystem: %v\n", err))
+		}
+	}()
+	zap.S().Infof("Gowaves Checkpoint version: %s", versioning.Version)
+
+	if err := run(cmd, &c); err != nil {
+		zap.S().Errorf("Failed to %s checkpoint: %v", cmd, err)
+		os.Exit(1)
+	}
+}
+
+func run(cmd string, c *config) error {
+	if c.checkpointPath == "" {
+		return errors.New("option checkpoint-path is not specified")
+	}
+	if cmd != exportCommand && c.node == "" {
+		return errors.New("option node is not specified")
+	}
+	if cmd == verifyCommand {
+		return verify(c)
+	}
+	if c.statePath == "" {
+		return errors.New("option state-path is not specified")
+	}
+	bs, err := blockchainSettings(c)
+	if err != nil {
+		return err
+	}
+	params, err := stateParams(c)
+	if err != nil {
+		return err
+	}
+	var cp *state.Checkpoint
+	switch cmd {
+	case exportCommand:
+		cp, err = state.ExportCheckpoint(c.statePath, c.checkpointPath, c.height, params, bs)
+	case importCommand:
+		trusted, thErr := trustedHeader(c)
+		if thErr != nil {
+			return thErr
+		}
+		cp, err = state.ImportCheckpoint(c.checkpointPath, c.statePath, trusted, params, bs)
+	}
+	if err != nil {
+		return err
+	}
+	zap.S().Infof("Checkpoint at height %d, block '%s', snapshot state hash '%s'",
+		cp.Height, cp.BlockID.String(), cp.SnapshotStateHash.Hex())
+	return nil
+}
+
+func verify(c *config) error {
+	trusted, err := trustedHeader(c)
+	if err != nil {
+		return err
+	}
+	cp, err := state.VerifyCheckpoint(c.checkpointPath, trusted)
+	if err != nil {
+		return err
+	}
+	zap.S().Infof("Checkpoint is intact and matches the trusted node: height %d, block '%s', snapshot state hash '%s'",
+		cp.Height, cp.BlockID.String(), cp.SnapshotStateHash.Hex())
+	return nil
+}
+
+// trustedHeader takes the block ID and the state hash from the header of the trusted node at the height of checkpoint.
+func trustedHeader(c *config) (state.TrustedHeader, error) {
+	cp, err := state.ReadCheckpoint(c.checkpointPath)
+	if err != nil {
+		return state.TrustedHeader{}, err
+	}
+	cl, err := client.NewClient(client.Options{BaseUrl: c.node, Client: &http.Client{Timeout: requestTimeout}})
+	if err != nil {
+		return state.TrustedHeader{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	header, _, err := cl.Blocks.HeadersAt(ctx, cp.Height)
+	if err != nil {
+		return state.TrustedHeader{}, fmt.Errorf("failed to get block header at height %d from node: %w", cp.Height, err)
+	}
+	if header.StateHash == "" {
+		return state.TrustedHeader{}, fmt.Errorf("block header at height %d of node has no state hash", cp.Height)
+	}
+	b, err := hex.DecodeString(header.StateHash)
+	if err != nil {
+		return state.TrustedHeader{}, fmt.Errorf("invalid state hash of block header at height %d: %w", cp.Height, err)
+	}
+	sh, err := crypto.NewDigestFromBytes(b)
+	if err != nil {
+		return state.TrustedHeader{}, fmt.Errorf("invalid state hash of block header at height %d: %w", cp.Height, err)
+	}
+	return state.TrustedHeader{BlockID: header.ID, StateHash: sh}, nil
+}
+
+func blockchainSettings(c *config) (*settings.BlockchainSettings, error) {
+	if c.cfgPath == "" {
+		return settings.BlockchainSettingsByTypeName(c.blockchainType)
+	}
+	f, err := os.Open(c.cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open configuration file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return settings.ReadBlockchainSettings(f)
+}
+
+func stateParams(c *config) (state.StateParams, error) {
+	maxFDs, err := fdlimit.MaxFDs()
+	if err != nil {
+		return state.StateParams{}, err
+	}
+	if _, err := fdlimit.RaiseMaxFDs(maxFDs); err != nil {
+		return state.StateParams{}, err
+	}
+	backend, err := keyvalue.ParseBackend(c.dbBackend)
+	if err != nil {
+		return state.StateParams{}, err
+	}
+	params := state.DefaultStateParams()
+	params.StorageParams.DbParams.OpenFilesCacheCapacity = int(maxFDs - clearance)
+	params.DbParams.Backend = backend
+	params.StoreExtendedApiData = c.extendedAPI
+	params.BuildStateHashes = c.stateHashes
+	return params, nil
+}
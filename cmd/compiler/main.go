@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/wavesplatform/gowaves/pkg/ride/compiler"
+	"github.com/wavesplatform/gowaves/pkg/ride/decompiler"
+	"github.com/wavesplatform/gowaves/pkg/ride/serialization"
 )
 
 var usage = `
@@ -17,6 +20,7 @@ Usage:
 Options:
 	-compaction	Compaction mode
     -remove-unused      Remove unused code
+    -decompile          Decompile the script given in base64 representation or as binary
 `
 
 func main() {
@@ -24,10 +28,12 @@ func main() {
 		scriptPath   string
 		compaction   bool
 		removeUnused bool
+		decompile    bool
 	)
 	flag.StringVar(&scriptPath, "script", "", "Path to script file")
 	flag.BoolVar(&compaction, "compaction", false, "Compaction mode")
 	flag.BoolVar(&removeUnused, "remove-unused", false, "Remove unused code")
+	flag.BoolVar(&decompile, "decompile", false, "Decompile the script given in base64 representation or as binary")
 
 	flag.Usage = func() {
 		fmt.Println(usage)
@@ -46,6 +52,16 @@ func main() {
 		os.Exit(0)
 	}
 
+	if decompile {
+		src, err := decompileScript(b)
+		if err != nil {
+			fmt.Printf("Failed to decompile script: %s", err)
+			os.Exit(0)
+		}
+		fmt.Print(src)
+		return
+	}
+
 	treeBytes, errors := compiler.Compile(string(b), compaction, removeUnused)
 	if len(errors) > 0 {
 		fmt.Println("Failed to compile script")
@@ -56,3 +72,17 @@ func main() {
 	}
 	fmt.Println(base64.StdEncoding.EncodeToString(treeBytes))
 }
+
+// decompileScript accepts the script in base64 representation, with or without prefix, or in binary form.
+func decompileScript(b []byte) (string, error) {
+	script := b
+	s := strings.TrimPrefix(strings.TrimSpace(string(b)), "base64:")
+	if decoded, err := base64.StdEncoding.DecodeString(s); err == nil {
+		script = decoded
+	}
+	tree, err := serialization.Parse(script)
+	if err != nil {
+		return "", err
+	}
+	return decompiler.Decompile(tree)
+}
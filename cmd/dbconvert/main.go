@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
+	"github.com/wavesplatform/gowaves/pkg/logging"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/util/fdlimit"
+	"github.com/wavesplatform/gowaves/pkg/versioning"
+)
+
+const (
+	MiB = 1024 * 1024
+
+	defaultBatchSize = 64 * MiB
+	clearance        = 10
+)
+
+func main() {
+	var (
+		logLevel = zap.LevelFlag("log-level", zapcore.InfoLevel,
+			"Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL. Default logging level INFO.")
+		statePath = flag.String("state-path", "", "Path to node's state directory")
+		to        = flag.String("to", keyvalue.PebbleBackend.String(),
+			"Target database backend: leveldb/pebble.")
+		batchSize = flag.Int("batch-size", defaultBatchSize, "Size of written batches in bytes.")
+	)
+
+	flag.Parse()
+
+	logger := logging.SetupSimpleLogger(*logLevel)
+	defer func() {
+		err := logger.Sync()
+		if err != nil && errors.Is(err, os.ErrInvalid) {
+			panic(fmt.Sprintf("Failed to close logging subsystem: %v\n", err))
+		}
+	}()
+	zap.S().Infof("Gowaves DB Converter version: %s", versioning.Version)
+
+	if *statePath == "" {
+		zap.S().Error("Path to state is not specified")
+		return
+	}
+	target, err := keyvalue.ParseBackend(*to)
+	if err != nil {
+		zap.S().Error(err)
+		return
+	}
+	maxFDs, err := fdlimit.MaxFDs()
+	if err != nil {
+		zap.S().Fatalf("Initialization error: %v", err)
+	}
+	_, err = fdlimit.RaiseMaxFDs(maxFDs)
+	if err != nil {
+		zap.S().Fatalf("Initialization error: %v", err)
+	}
+
+	if err := convert(state.KeyValueDir(*statePath), target, *batchSize, int(maxFDs-clearance)); err != nil {
+		zap.S().Errorf("Failed to convert state database: %v", err)
+		return
+	}
+}
+
+// convert copies the database into the new one with the target backend next to it.
+// On success the source database is kept with the name of its backend appended and the converted one takes its place.
+func convert(path string, target keyvalue.Backend, batchSize, openFiles int) error {
+	source, ok, err := keyvalue.DetectBackend(path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no database found at '%s'", path)
+	}
+	if source == target {
+		zap.S().Infof("Database at '%s' is already stored in %s backend", path, target)
+		return nil
+	}
+	tmpPath := path + "." + target.String() + ".tmp"
+	backupPath := path + "." + source.String()
+	for _, p := range []string{tmpPath, backupPath} {
+		if _, err := os.Stat(p); err == nil || !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("path '%s' already exists, remove it before conversion", p)
+		}
+	}
+	zap.S().Infof("Converting database at '%s' from %s to %s backend", path, source, target)
+	n, err := copyDatabase(path, source, tmpPath, target, batchSize, openFiles)
+	if err != nil {
+		if rmErr := os.RemoveAll(tmpPath); rmErr != nil {
+			zap.S().Errorf("Failed to remove incomplete database at '%s': %v", tmpPath, rmErr)
+		}
+		return err
+	}
+	if err := os.Rename(path, backupPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	zap.S().Infof("%d records converted, the original database is saved to '%s' and can be removed", n, backupPath)
+	return nil
+}
+
+func openDatabase(path string, backend keyvalue.Backend, openFiles int) (keyvalue.IterableKeyVal, error) {
+	params := keyvalue.KeyValParams{
+		// Bloom filter is not needed for sequential copying, the stored filter of the state stays valid.
+		BloomFilterParams:      keyvalue.BloomFilterParams{Disable: true},
+		Backend:                backend,
+		WriteBuffer:            state.DefaultWriteBuffer,
+		CompactionTableSize:    state.DefaultCompactionTableSize,
+		CompactionTotalSize:    state.DefaultCompactionTotalSize,
+		OpenFilesCacheCapacity: openFiles / 2,
+	}
+	return keyvalue.NewKeyValue(path, params)
+}
+
+func copyDatabase(
+	srcPath string, srcBackend keyvalue.Backend,
+	dstPath string, dstBackend keyvalue.Backend,
+	batchSize, openFiles int,
+) (_ int, err error) {
+	src, err := openDatabase(srcPath, srcBackend, openFiles)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open source database: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, src.Close())
+	}()
+	dst, err := openDatabase(dstPath, dstBackend, openFiles)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open target database: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, dst.Close())
+	}()
+	iter, err := src.NewKeyIterator(nil)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Release()
+	batch, err := dst.NewBatch()
+	if err != nil {
+		return 0, err
+	}
+	n, size := 0, 0
+	for iter.Next() {
+		key, value := iter.Key(), iter.Value()
+		batch.Put(key, value) // Batch copies the key and the value
+		n++
+		size += len(key) + len(value)
+		if size >= batchSize {
+			if err := dst.Flush(batch); err != nil {
+				return n, err
+			}
+			size = 0
+			zap.S().Infof("%d records converted", n)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return n, err
+	}
+	if err := dst.Flush(batch); err != nil {
+		return n, err
+	}
+	return n, nil
+}
@@ -20,6 +20,7 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/wavesplatform/gowaves/pkg/importer"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/logging"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/state"
@@ -71,6 +72,7 @@ type cfg struct {
 	cpuProfilePath            string
 	memProfilePath            string
 	disableBloomFilter        bool
+	dbBackend                 string
 }
 
 func parseFlags() cfg {
@@ -106,6 +108,8 @@ func parseFlags() cfg {
 	flag.StringVar(&c.memProfilePath, "memprofile", "", "Write memory profile to this file.")
 	flag.BoolVar(&c.disableBloomFilter, "disable-bloom", false,
 		"Disable bloom filter. Less memory usage, but decrease performance.")
+	flag.StringVar(&c.dbBackend, "db-backend", keyvalue.LevelDBBackend.String(),
+		"State database backend: 'leveldb' or 'pebble'.")
 	flag.Parse()
 	return c
 }
@@ -120,6 +124,9 @@ func (c *cfg) validateFlags() error {
 	if c.lightNodeMode && c.snapshotsPath == "" {
 		return errors.New("option snapshots-path is not specified in light mode, please specify it")
 	}
+	if _, err := keyvalue.ParseBackend(c.dbBackend); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -130,6 +137,7 @@ func (c *cfg) params(maxFDs int) state.StateParams {
 	params.VerificationGoroutinesNum = c.verificationGoroutinesNum
 	params.DbParams.WriteBuffer = c.writeBufferSize * MiB
 	params.DbParams.BloomFilterParams.Disable = c.disableBloomFilter
+	params.DbParams.Backend = keyvalue.Backend(c.dbBackend)
 	params.StoreExtendedApiData = c.buildDataForExtendedAPI
 	params.BuildStateHashes = c.buildStateHashes
 	params.ProvideExtendedApi = false // We do not need to provide any APIs during import.
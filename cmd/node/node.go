@@ -14,6 +14,7 @@ import (
 	"net/http/pprof"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/api"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/grpc/server"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/libs/microblock_cache"
 	"github.com/wavesplatform/gowaves/pkg/libs/ntptime"
 	"github.com/wavesplatform/gowaves/pkg/logging"
@@ -35,15 +37,19 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/miner/scheduler"
 	"github.com/wavesplatform/gowaves/pkg/miner/utxpool"
 	"github.com/wavesplatform/gowaves/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/node/blockchain_updates"
 	"github.com/wavesplatform/gowaves/pkg/node/blocks_applier"
 	"github.com/wavesplatform/gowaves/pkg/node/messages"
 	"github.com/wavesplatform/gowaves/pkg/node/network"
+	"github.com/wavesplatform/gowaves/pkg/node/notifications"
 	"github.com/wavesplatform/gowaves/pkg/node/peers"
 	peersPersistentStorage "github.com/wavesplatform/gowaves/pkg/node/peers/storage"
 	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
 	"github.com/wavesplatform/gowaves/pkg/services"
 	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/signer"
 	"github.com/wavesplatform/gowaves/pkg/state"
 	"github.com/wavesplatform/gowaves/pkg/types"
 	"github.com/wavesplatform/gowaves/pkg/util/common"
@@ -61,7 +67,11 @@ const (
 
 const profilerAddr = "localhost:6060"
 
-const utxPoolMaxSizeBytes = 1024 * mb
+const (
+	utxPoolMaxSizeBytes = 1024 * mb
+	// utxSaveInterval is the period of UTX pool saving, so the pool is restored even after the crash of node.
+	utxSaveInterval = time.Minute
+)
 
 var defaultPeers = map[string]string{
 	"mainnet":  "34.253.153.4:6868,168.119.116.189:6868,135.181.87.72:6868,162.55.39.115:6868,168.119.155.201:6868",
@@ -92,6 +102,7 @@ type config struct {
 	enableMetaMaskAPI          bool
 	enableMetaMaskAPILog       bool
 	enableGrpcAPI              bool
+	enableBlockchainUpdates    bool
 	blackListResidenceTime     time.Duration
 	buildExtendedAPI           bool
 	serveExtendedAPI           bool
@@ -104,6 +115,9 @@ type config struct {
 	obsolescencePeriod         time.Duration
 	walletPath                 string
 	walletPassword             string
+	signerURL                  string
+	signerAPIKey               string
+	signerTimeout              time.Duration
 	limitAllConnections        uint
 	minPeersMining             int
 	disableMiner               bool
@@ -117,6 +131,12 @@ type config struct {
 	disableNTP                 bool
 	microblockInterval         time.Duration
 	enableLightMode            bool
+	rideExecutor               string
+	dbBackend                  string
+	utxPerSenderLimit          int
+	utxPerDAppLimit            int
+	evaluateComplexityLimit    uint
+	evaluateTimeout            time.Duration
 }
 
 var errConfigNotParsed = stderrs.New("config is not parsed")
@@ -148,6 +168,7 @@ func (c *config) logParameters() {
 	zap.S().Debugf("api-key: %s", crypto.MustKeccak256([]byte(c.apiKey)).Hex())
 	zap.S().Debugf("grpc-address: %s", c.grpcAddr)
 	zap.S().Debugf("enable-grpc-api: %t", c.enableGrpcAPI)
+	zap.S().Debugf("enable-blockchain-updates: %t", c.enableBlockchainUpdates)
 	zap.S().Debugf("black-list-residence-time: %s", c.blackListResidenceTime)
 	zap.S().Debugf("build-extended-api: %t", c.buildExtendedAPI)
 	zap.S().Debugf("serve-extended-api: %t", c.serveExtendedAPI)
@@ -159,6 +180,9 @@ func (c *config) logParameters() {
 	zap.S().Debugf("disable-miner %t", c.disableMiner)
 	zap.S().Debugf("wallet-path: %s", c.walletPath)
 	zap.S().Debugf("hashed wallet-password: %s", crypto.MustKeccak256([]byte(c.walletPassword)).Hex())
+	zap.S().Debugf("signer-url: %s", c.signerURL)
+	zap.S().Debugf("hashed signer-api-key: %s", crypto.MustKeccak256([]byte(c.signerAPIKey)).Hex())
+	zap.S().Debugf("signer-timeout: %s", c.signerTimeout)
 	zap.S().Debugf("limit-connections: %d", c.limitAllConnections)
 	zap.S().Debugf("profiler: %t", c.profiler)
 	zap.S().Debugf("disable-bloom: %t", c.disableBloomFilter)
@@ -169,6 +193,12 @@ func (c *config) logParameters() {
 	zap.S().Debugf("disable-ntp: %t", c.disableNTP)
 	zap.S().Debugf("microblock-interval: %s", c.microblockInterval)
 	zap.S().Debugf("enable-light-mode: %t", c.enableLightMode)
+	zap.S().Debugf("ride-executor: %s", c.rideExecutor)
+	zap.S().Debugf("db-backend: %s", c.dbBackend)
+	zap.S().Debugf("utx-per-sender-limit: %d", c.utxPerSenderLimit)
+	zap.S().Debugf("utx-per-dapp-limit: %d", c.utxPerDAppLimit)
+	zap.S().Debugf("evaluate-complexity-limit: %d", c.evaluateComplexityLimit)
+	zap.S().Debugf("evaluate-timeout: %s", c.evaluateTimeout)
 }
 
 func (c *config) parse() {
@@ -182,6 +212,8 @@ func (c *config) parse() {
 		defaultConnectionsLimit           = 60
 		defaultNewConnectionLimit         = 10
 		defaultMicroblockInterval         = 5 * time.Second
+		defaultUtxPerSenderLimit          = 100
+		defaultUtxPerDAppLimit            = 1000
 	)
 	l := zap.LevelFlag("log-level", zapcore.InfoLevel,
 		"Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL.")
@@ -215,6 +247,8 @@ func (c *config) parse() {
 	flag.BoolVar(&c.enableMetaMaskAPILog, "enable-metamask-log", false,
 		"Enables/disables metamask API logging.")
 	flag.BoolVar(&c.enableGrpcAPI, "enable-grpc-api", false, "Enables/disables gRPC API.")
+	flag.BoolVar(&c.enableBlockchainUpdates, "enable-blockchain-updates", false,
+		"Enables/disables BlockchainUpdates gRPC API. Requires 'enable-grpc-api' flag.")
 	flag.DurationVar(&c.blackListResidenceTime, "blacklist-residence-time", defaultBlacklistResidenceDuration,
 		"Period of time for which the information about external peer stays in the blacklist. "+
 			"Default value is 5 min. To disable blacklisting pass zero value.")
@@ -240,6 +274,11 @@ func (c *config) parse() {
 		"Blockchain obsolescence period. Disable mining if last block older then given value.")
 	flag.StringVar(&c.walletPath, "wallet-path", "", "Path to wallet, or ~/.waves by default.")
 	flag.StringVar(&c.walletPassword, "wallet-password", "", "Pass password for wallet.")
+	flag.StringVar(&c.signerURL, "signer-url", "",
+		"URL of the remote signer daemon holding the wallet. If set, the local wallet is not used.")
+	flag.StringVar(&c.signerAPIKey, "signer-api-key", "", "API key of the remote signer daemon.")
+	flag.DurationVar(&c.signerTimeout, "signer-timeout", signer.DefaultTimeout,
+		"Timeout of requests to the remote signer daemon.")
 	flag.UintVar(&c.limitAllConnections, "limit-connections", defaultConnectionsLimit,
 		"Total limit of network connections, both inbound and outbound. Divided in half to limit each direction.")
 	flag.IntVar(&c.minPeersMining, "min-peers-mining", 1,
@@ -266,6 +305,18 @@ func (c *config) parse() {
 		"Interval between microblocks.")
 	flag.BoolVar(&c.enableLightMode, "enable-light-mode", false,
 		"Start node in light mode")
+	flag.StringVar(&c.rideExecutor, "ride-executor", ride.TreeExecution.String(),
+		"RIDE scripts executor: 'tree' evaluator, bytecode 'vm' or 'diff' that runs both and logs divergences.")
+	flag.StringVar(&c.dbBackend, "db-backend", keyvalue.LevelDBBackend.String(),
+		"State database backend: 'leveldb' or 'pebble'. Existing state must be converted with 'dbconvert' tool.")
+	flag.IntVar(&c.utxPerSenderLimit, "utx-per-sender-limit", defaultUtxPerSenderLimit,
+		"Maximum number of transactions from one sender in UTX pool, zero means no limit.")
+	flag.IntVar(&c.utxPerDAppLimit, "utx-per-dapp-limit", defaultUtxPerDAppLimit,
+		"Maximum number of invocations of one dApp in UTX pool, zero means no limit.")
+	flag.UintVar(&c.evaluateComplexityLimit, "evaluate-complexity-limit", api.DefaultEvaluateComplexityLimit,
+		"Complexity limit of expressions evaluated by '/utils/script/evaluate' REST API route.")
+	flag.DurationVar(&c.evaluateTimeout, "evaluate-timeout", api.DefaultEvaluateTimeout,
+		"Timeout of expressions evaluation by '/utils/script/evaluate' REST API route.")
 	flag.Parse()
 	c.logLevel = *l
 }
@@ -373,9 +424,9 @@ func runNode(ctx context.Context, nc *config) (_ io.Closer, retErr error) {
 		return nil, errors.Wrap(err, "failed to get node settings")
 	}
 
-	wal, err := embeddedWallet(nc, cfg.AddressSchemeCharacter)
+	wal, err := nodeWallet(nc, cfg.AddressSchemeCharacter)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get embedded wallet")
+		return nil, errors.Wrap(err, "failed to get wallet")
 	}
 
 	path, err := nc.StatePath()
@@ -424,7 +475,18 @@ func runNode(ctx context.Context, nc *config) (_ io.Closer, retErr error) {
 		return nil, errors.Wrap(err, "failed to initialize miner scheduler")
 	}
 
-	svs, err := createServices(nc, st, wal, cfg, ntpTime, peerManager, parent, minerScheduler)
+	utx, err := createUtxPool(nc, st, cfg, ntpTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create UTX pool")
+	}
+	utxPath := filepath.Join(path, utxpool.FileName)
+	if loaded, dropped, lErr := utx.Load(utxPath); lErr != nil {
+		zap.S().Warnf("Failed to restore UTX pool: %v", lErr)
+	} else {
+		zap.S().Infof("UTX pool restored: %d transactions loaded, %d invalid dropped", loaded, dropped)
+	}
+
+	svs, err := createServices(nc, st, wal, cfg, ntpTime, peerManager, parent, minerScheduler, utx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create services")
 	}
@@ -433,6 +495,10 @@ func runNode(ctx context.Context, nc *config) (_ io.Closer, retErr error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize application")
 	}
+	if nc.evaluateComplexityLimit > math.MaxUint32 {
+		return nil, errors.Errorf("invalid evaluate complexity limit %d", nc.evaluateComplexityLimit)
+	}
+	app.SetScriptEvaluationLimits(uint32(nc.evaluateComplexityLimit), nc.evaluateTimeout)
 
 	if pErr := spawnPeersByAddresses(ctx, conf.Addresses, peerManager); pErr != nil {
 		return nil, errors.Wrap(pErr, "failed to spawn peers by addresses")
@@ -442,7 +508,51 @@ func runNode(ctx context.Context, nc *config) (_ io.Closer, retErr error) {
 		return nil, errors.Wrap(apiErr, "failed to run APIs")
 	}
 
-	return startNode(ctx, nc, svs, features, minerScheduler, parent, declAddr), nil
+	n := startNode(ctx, nc, svs, features, minerScheduler, parent, declAddr)
+	return newUtxSavingCloser(ctx, n, utx, utxPath), nil
+}
+
+// utxSavingCloser saves UTX pool to the state directory periodically and after the node is closed.
+type utxSavingCloser struct {
+	io.Closer
+	utx    *utxpool.UtxImpl
+	path   string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newUtxSavingCloser(ctx context.Context, c io.Closer, utx *utxpool.UtxImpl, path string) *utxSavingCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	sc := &utxSavingCloser{Closer: c, utx: utx, path: path, cancel: cancel, done: make(chan struct{})}
+	go sc.run(ctx)
+	return sc
+}
+
+func (c *utxSavingCloser) run(ctx context.Context) {
+	defer close(c.done)
+	ticker := time.NewTicker(utxSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.utx.Save(c.path); err != nil {
+				zap.S().Warnf("Failed to save UTX pool: %v", err)
+			}
+		}
+	}
+}
+
+func (c *utxSavingCloser) Close() error {
+	err := c.Closer.Close()
+	c.cancel()
+	<-c.done // Periodic saving is stopped before the last one to not to write the file concurrently.
+	if sErr := c.utx.Save(c.path); sErr != nil {
+		return stderrs.Join(err, errors.Wrap(sErr, "failed to save UTX pool"))
+	}
+	zap.S().Infof("UTX pool with %d transactions saved", c.utx.Count())
+	return err
 }
 
 func startNode(
@@ -525,6 +635,16 @@ func stateParams(nc *config, ntpTime types.Time) (state.StateParams, error) {
 	params.BuildStateHashes = nc.buildStateHashes
 	params.Time = ntpTime
 	params.DbParams.BloomFilterParams.Disable = nc.disableBloomFilter
+	rideExecution, err := ride.ParseExecutionMode(nc.rideExecutor)
+	if err != nil {
+		return state.StateParams{}, errors.Wrap(err, "invalid 'ride-executor' flag value")
+	}
+	params.RideExecution = rideExecution
+	dbBackend, err := keyvalue.ParseBackend(nc.dbBackend)
+	if err != nil {
+		return state.StateParams{}, errors.Wrap(err, "invalid 'db-backend' flag value")
+	}
+	params.DbParams.Backend = dbBackend
 	return params, nil
 }
 
@@ -624,6 +744,17 @@ func nodeSettings(nc *config, scheme proto.Scheme) (*settings.NodeSettings, erro
 	return conf, nil
 }
 
+// nodeWallet returns the remote signer if its URL is set, otherwise the embedded wallet.
+func nodeWallet(nc *config, scheme proto.Scheme) (types.EmbeddedWallet, error) {
+	if nc.signerURL == "" {
+		return embeddedWallet(nc, scheme)
+	}
+	if nc.walletPassword != "" {
+		return nil, errors.New("wallet password can't be used with remote signer")
+	}
+	return signer.NewRemoteSigner(nc.signerURL, nc.signerAPIKey, scheme, nc.signerTimeout)
+}
+
 func embeddedWallet(nc *config, scheme proto.Scheme) (types.EmbeddedWallet, error) {
 	wal := wallet.NewEmbeddedWallet(wallet.NewLoader(nc.walletPath), wallet.NewWallet(), scheme)
 	if nc.walletPassword != "" {
@@ -742,6 +873,28 @@ func createPeerManager(
 	), nil
 }
 
+func createUtxPool(
+	nc *config,
+	st state.State,
+	cfg *settings.BlockchainSettings,
+	ntpTime types.Time,
+) (*utxpool.UtxImpl, error) {
+	if nc.utxPerSenderLimit < 0 || nc.utxPerDAppLimit < 0 {
+		return nil, errors.New("UTX pool limits must not be negative")
+	}
+	utxValidator, err := utxpool.NewValidator(st, ntpTime, nc.obsolescencePeriod)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize UTX")
+	}
+	limits := utxpool.Limits{
+		Size:      utxPoolMaxSizeBytes,
+		PerSender: nc.utxPerSenderLimit,
+		PerDApp:   nc.utxPerDAppLimit,
+	}
+	prioritizer := utxpool.NewStatePrioritizer(st, cfg.AddressSchemeCharacter)
+	return utxpool.NewWithLimits(limits, utxValidator, prioritizer, st, cfg), nil
+}
+
 func createServices(
 	nc *config,
 	st state.State,
@@ -751,24 +904,42 @@ func createServices(
 	peerManager peers.PeerManager,
 	parent peer.Parent,
 	scheduler Scheduler,
+	utx types.UtxPool,
 ) (services.Services, error) {
-	utxValidator, err := utxpool.NewValidator(st, ntpTime, nc.obsolescencePeriod)
+	hub := notifications.NewHub()
+	notifier, err := notifications.NewApplier(blocks_applier.NewBlocksApplier(), hub, st)
 	if err != nil {
-		return services.Services{}, errors.Wrap(err, "failed to initialize UTX")
+		return services.Services{}, errors.Wrap(err, "failed to initialize notifications")
+	}
+	var (
+		applier services.BlocksApplier = notifier
+		updates *blockchain_updates.Tracker
+	)
+	if nc.enableBlockchainUpdates {
+		if !nc.enableGrpcAPI {
+			return services.Services{}, errors.New("'enable-blockchain-updates' flag requires 'enable-grpc-api' flag")
+		}
+		updates, err = blockchain_updates.NewTracker(applier, st, cfg.AddressSchemeCharacter)
+		if err != nil {
+			return services.Services{}, errors.Wrap(err, "failed to initialize blockchain updates")
+		}
+		applier = updates
 	}
 	return services.Services{
-		State:           st,
-		Peers:           peerManager,
-		Scheduler:       scheduler,
-		BlocksApplier:   blocks_applier.NewBlocksApplier(),
-		UtxPool:         utxpool.New(utxPoolMaxSizeBytes, utxValidator, cfg),
-		Scheme:          cfg.AddressSchemeCharacter,
-		Time:            ntpTime,
-		Wallet:          wal,
-		MicroBlockCache: microblock_cache.NewMicroBlockCache(),
-		InternalChannel: messages.NewInternalChannel(),
-		MinPeersMining:  nc.minPeersMining,
-		SkipMessageList: parent.SkipMessageList,
+		State:             st,
+		Peers:             peerManager,
+		Scheduler:         scheduler,
+		BlocksApplier:     applier,
+		UtxPool:           notifications.NewUtxPool(utx, hub, cfg.AddressSchemeCharacter),
+		Scheme:            cfg.AddressSchemeCharacter,
+		Time:              ntpTime,
+		Wallet:            wal,
+		MicroBlockCache:   microblock_cache.NewMicroBlockCache(),
+		InternalChannel:   messages.NewInternalChannel(),
+		MinPeersMining:    nc.minPeersMining,
+		SkipMessageList:   parent.SkipMessageList,
+		BlockchainUpdates: updates,
+		Notifications:     hub,
 	}, nil
 }
 
@@ -835,6 +1006,7 @@ func apiRunOptsFromCLIFlags(c *config) *api.RunOptions {
 func grpcAPIRunOptsFromCLIFlags(c *config) *server.RunOptions {
 	opts := server.DefaultRunOptions()
 	opts.MaxConnections = c.grpcAPIMaxConnections
+	opts.APIKey = c.apiKey
 	return opts
 }
 
@@ -9,6 +9,7 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/logging"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/state"
@@ -30,6 +31,8 @@ func main() {
 			"Calculate and store state hashes for each block height.")
 		cfgPath            = flag.String("cfg-path", "", "Path to configuration JSON file, only for custom blockchain.")
 		disableBloomFilter = flag.Bool("disable-bloom", false, "Disable bloom filter for state.")
+		dbBackend          = flag.String("db-backend", keyvalue.LevelDBBackend.String(),
+			"State database backend: leveldb/pebble.")
 	)
 
 	flag.Parse()
@@ -74,6 +77,7 @@ func main() {
 	params := state.DefaultStateParams()
 	params.StorageParams.DbParams.OpenFilesCacheCapacity = int(maxFDs - 10)
 	params.DbParams.BloomFilterParams.Disable = *disableBloomFilter
+	params.DbParams.Backend = keyvalue.Backend(*dbBackend)
 	params.BuildStateHashes = *buildStateHashes
 	params.StoreExtendedApiData = *buildExtendedAPI
 
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/howeyc/gopass"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wavesplatform/gowaves/pkg/logging"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/signer"
+	"github.com/wavesplatform/gowaves/pkg/versioning"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+const (
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+type config struct {
+	logLevel          zapcore.Level
+	address           string
+	apiKey            string
+	walletPath        string
+	walletPassword    string
+	scheme            string
+	tlsCert           string
+	tlsKey            string
+	allowTransactions bool
+}
+
+func main() {
+	c := config{logLevel: zapcore.InfoLevel}
+	flag.Var(&c.logLevel, "log-level",
+		"Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL. Default logging level INFO.")
+	flag.StringVar(&c.address, "address", "127.0.0.1:6870", "Address to serve the signer API on.")
+	flag.StringVar(&c.apiKey, "api-key", "", "API key the node must present, required.")
+	flag.StringVar(&c.walletPath, "wallet-path", "", "Path to wallet, or ~/.waves by default.")
+	flag.StringVar(&c.walletPassword, "wallet-password", "",
+		"Password of wallet. It's asked interactively if not set.")
+	flag.StringVar(&c.scheme, "scheme", "W",
+		"Network scheme: MainNet=W, TestNet=T, StageNet=S, CustomNet=E. MainNet is default.")
+	flag.StringVar(&c.tlsCert, "tls-cert", "", "Path to TLS certificate file, the API is served over HTTPS if set.")
+	flag.StringVar(&c.tlsKey, "tls-key", "", "Path to TLS private key file.")
+	flag.BoolVar(&c.allowTransactions, "allow-transactions", false,
+		"Allow signing of transactions, by default only blocks, micro blocks and VRF proofs are signed.")
+	flag.Parse()
+
+	logger := logging.SetupSimpleLogger(c.logLevel)
+	defer func() {
+		err := logger.Sync()
+		if err != nil && errors.Is(err, os.ErrInvalid) {
+			panic(fmt.Sprintf("Failed to close logging subsystem: %v\n", err))
+		}
+	}()
+	zap.S().Infof("Gowaves Signer version: %s", versioning.Version)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := run(ctx, &c); err != nil {
+		zap.S().Errorf("Signer failed: %v", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, c *config) error {
+	if c.apiKey == "" {
+		return errors.New("option api-key is not specified")
+	}
+	if (c.tlsCert == "") != (c.tlsKey == "") {
+		return errors.New("both options tls-cert and tls-key must be specified")
+	}
+	if len(c.scheme) != 1 {
+		return errors.New("invalid scheme, one letter should be provided")
+	}
+	scheme := proto.Scheme(c.scheme[0])
+	w, err := loadWallet(c, scheme)
+	if err != nil {
+		return err
+	}
+	pks, err := w.PublicKeys()
+	if err != nil {
+		return err
+	}
+	for _, pk := range pks {
+		addr, aErr := proto.NewAddressFromPublicKey(scheme, pk)
+		if aErr != nil {
+			return aErr
+		}
+		zap.S().Infof("Serving account '%s' with public key '%s'", addr.String(), pk.String())
+	}
+	h, err := signer.NewHandler(w, signer.HandlerOptions{
+		Scheme:            scheme,
+		APIKey:            c.apiKey,
+		AllowTransactions: c.allowTransactions,
+	})
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Addr: c.address, Handler: h, ReadHeaderTimeout: readHeaderTimeout}
+	errCh := make(chan error, 1)
+	go func() {
+		zap.S().Infof("Starting signer API on '%s'", c.address)
+		if c.tlsCert != "" {
+			errCh <- srv.ListenAndServeTLS(c.tlsCert, c.tlsKey)
+		} else {
+			errCh <- srv.ListenAndServe()
+		}
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		zap.S().Info("Shutting down signer API")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+func loadWallet(c *config, scheme proto.Scheme) (*wallet.EmbeddedWalletImpl, error) {
+	password := []byte(c.walletPassword)
+	if len(password) == 0 {
+		fmt.Print("Enter password: ")
+		var err error
+		password, err = gopass.GetPasswd()
+		if err != nil {
+			return nil, err
+		}
+	}
+	w := wallet.NewEmbeddedWallet(wallet.NewLoader(c.walletPath), wallet.NewWallet(), scheme)
+	if err := w.Load(password); err != nil {
+		return nil, fmt.Errorf("failed to load wallet: %w", err)
+	}
+	return w, nil
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Components of state. The first nine of them correspond to the component hashes of legacy state hash.
+const (
+	wavesBalanceComponent      = "wavesBalance"
+	assetBalanceComponent      = "assetBalance"
+	leaseBalanceComponent      = "leaseBalance"
+	dataEntryComponent         = "dataEntry"
+	accountScriptComponent     = "accountScript"
+	assetScriptComponent       = "assetScript"
+	leaseStatusComponent       = "leaseStatus"
+	sponsorshipComponent       = "sponsorship"
+	aliasComponent             = "alias"
+	assetInfoComponent         = "assetInfo"
+	orderFillComponent         = "orderFill"
+	transactionStatusComponent = "transactionStatus"
+)
+
+var componentsOrder = []string{
+	wavesBalanceComponent, assetBalanceComponent, leaseBalanceComponent, dataEntryComponent, accountScriptComponent,
+	assetScriptComponent, leaseStatusComponent, sponsorshipComponent, aliasComponent, assetInfoComponent,
+	orderFillComponent, transactionStatusComponent,
+}
+
+// hashComponent is the component hash of legacy state hash.
+type hashComponent struct {
+	Component string        `json:"component"`
+	Left      crypto.Digest `json:"left"`
+	Right     crypto.Digest `json:"right"`
+}
+
+func differentHashes(left, right *proto.StateHash) []hashComponent {
+	l, r := left.FieldsHashes, right.FieldsHashes
+	all := []hashComponent{
+		{wavesBalanceComponent, l.WavesBalanceHash, r.WavesBalanceHash},
+		{assetBalanceComponent, l.AssetBalanceHash, r.AssetBalanceHash},
+		{leaseBalanceComponent, l.LeaseBalanceHash, r.LeaseBalanceHash},
+		{dataEntryComponent, l.DataEntryHash, r.DataEntryHash},
+		{accountScriptComponent, l.AccountScriptHash, r.AccountScriptHash},
+		{assetScriptComponent, l.AssetScriptHash, r.AssetScriptHash},
+		{leaseStatusComponent, l.LeaseStatusHash, r.LeaseStatusHash},
+		{sponsorshipComponent, l.SponsorshipHash, r.SponsorshipHash},
+		{aliasComponent, l.AliasesHash, r.AliasesHash},
+	}
+	var res []hashComponent
+	for _, c := range all {
+		if c.Left != c.Right {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// entries are the values of state changed by the block, by component and key.
+type entries map[string]map[string]string
+
+// entriesCollector collects the final values of keys changed by the block from its snapshots.
+type entriesCollector struct {
+	scheme  proto.Scheme
+	entries entries
+	tx      int
+}
+
+func collectEntries(scheme proto.Scheme, bs proto.BlockSnapshot) (entries, error) {
+	c := &entriesCollector{scheme: scheme, entries: make(entries)}
+	for i, txSnapshot := range bs.TxSnapshots {
+		c.tx = i
+		for _, s := range txSnapshot {
+			if err := s.Apply(c); err != nil {
+				return nil, errors.Wrapf(err, "failed to collect snapshot of transaction %d", i)
+			}
+		}
+	}
+	return c.entries, nil
+}
+
+func (c *entriesCollector) set(component, key, value string) {
+	m, ok := c.entries[component]
+	if !ok {
+		m = make(map[string]string)
+		c.entries[component] = m
+	}
+	m[key] = value
+}
+
+func (c *entriesCollector) ApplyWavesBalance(s proto.WavesBalanceSnapshot) error {
+	c.set(wavesBalanceComponent, s.Address.String(), strconv.FormatUint(s.Balance, 10))
+	return nil
+}
+
+func (c *entriesCollector) ApplyLeaseBalance(s proto.LeaseBalanceSnapshot) error {
+	c.set(leaseBalanceComponent, s.Address.String(), fmt.Sprintf("in=%d out=%d", s.LeaseIn, s.LeaseOut))
+	return nil
+}
+
+func (c *entriesCollector) ApplyAssetBalance(s proto.AssetBalanceSnapshot) error {
+	c.set(assetBalanceComponent, s.Address.String()+"/"+s.AssetID.String(), strconv.FormatUint(s.Balance, 10))
+	return nil
+}
+
+func (c *entriesCollector) ApplyAlias(s proto.AliasSnapshot) error {
+	c.set(aliasComponent, s.Alias, s.Address.String())
+	return nil
+}
+
+func (c *entriesCollector) ApplyNewAsset(s proto.NewAssetSnapshot) error {
+	c.set(assetInfoComponent, s.AssetID.String()+"/static",
+		fmt.Sprintf("issuer=%s decimals=%d nft=%t", s.IssuerPublicKey.String(), s.Decimals, s.IsNFT))
+	return nil
+}
+
+func (c *entriesCollector) ApplyAssetDescription(s proto.AssetDescriptionSnapshot) error {
+	c.set(assetInfoComponent, s.AssetID.String()+"/description",
+		fmt.Sprintf("name=%q description=%q", s.AssetName, s.AssetDescription))
+	return nil
+}
+
+func (c *entriesCollector) ApplyAssetVolume(s proto.AssetVolumeSnapshot) error {
+	c.set(assetInfoComponent, s.AssetID.String()+"/volume",
+		fmt.Sprintf("quantity=%s reissuable=%t", s.TotalQuantity.String(), s.IsReissuable))
+	return nil
+}
+
+func (c *entriesCollector) ApplyAssetScript(s proto.AssetScriptSnapshot) error {
+	c.set(assetScriptComponent, s.AssetID.String(), scriptString(s.Script))
+	return nil
+}
+
+func (c *entriesCollector) ApplySponsorship(s proto.SponsorshipSnapshot) error {
+	c.set(sponsorshipComponent, s.AssetID.String(), strconv.FormatUint(s.MinSponsoredFee, 10))
+	return nil
+}
+
+func (c *entriesCollector) ApplyAccountScript(s proto.AccountScriptSnapshot) error {
+	addr, err := proto.NewAddressFromPublicKey(c.scheme, s.SenderPublicKey)
+	if err != nil {
+		return err
+	}
+	c.set(accountScriptComponent, addr.String(),
+		fmt.Sprintf("%s complexity=%d", scriptString(s.Script), s.VerifierComplexity))
+	return nil
+}
+
+func (c *entriesCollector) ApplyFilledVolumeAndFee(s proto.FilledVolumeFeeSnapshot) error {
+	c.set(orderFillComponent, s.OrderID.String(), fmt.Sprintf("volume=%d fee=%d", s.FilledVolume, s.FilledFee))
+	return nil
+}
+
+func (c *entriesCollector) ApplyDataEntries(s proto.DataEntriesSnapshot) error {
+	for _, e := range s.DataEntries {
+		v, err := json.Marshal(e)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal data entry '%s'", e.GetKey())
+		}
+		c.set(dataEntryComponent, s.Address.String()+"/"+e.GetKey(), string(v))
+	}
+	return nil
+}
+
+func (c *entriesCollector) ApplyNewLease(s proto.NewLeaseSnapshot) error {
+	c.set(leaseStatusComponent, s.LeaseID.String(), fmt.Sprintf("active amount=%d sender=%s recipient=%s",
+		s.Amount, s.SenderPK.String(), s.RecipientAddr.String()))
+	return nil
+}
+
+func (c *entriesCollector) ApplyCancelledLease(s proto.CancelledLeaseSnapshot) error {
+	c.set(leaseStatusComponent, s.LeaseID.String(), "cancelled")
+	return nil
+}
+
+func (c *entriesCollector) ApplyTransactionsStatus(s proto.TransactionStatusSnapshot) error {
+	c.set(transactionStatusComponent, "#"+strconv.Itoa(c.tx), s.Status.String())
+	return nil
+}
+
+func scriptString(s proto.Script) string {
+	if s.IsEmpty() {
+		return "<no script>"
+	}
+	return s.String()
+}
+
+// entryDiff is the key of component changed differently by the block. The value is nil on the side where the block
+// doesn't change the key.
+type entryDiff struct {
+	Component string  `json:"component"`
+	Key       string  `json:"key"`
+	Left      *string `json:"left"`
+	Right     *string `json:"right"`
+}
+
+func diffEntries(left, right entries) []entryDiff {
+	var res []entryDiff
+	for _, component := range componentsOrder {
+		l, r := left[component], right[component]
+		keys := make([]string, 0, len(l)+len(r))
+		for k := range l {
+			keys = append(keys, k)
+		}
+		for k := range r {
+			if _, ok := l[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			lv, lok := l[k]
+			rv, rok := r[k]
+			if lok && rok && lv == rv {
+				continue
+			}
+			d := entryDiff{Component: component, Key: k}
+			if lok {
+				d.Left = &lv
+			}
+			if rok {
+				d.Right = &rv
+			}
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// report is the difference of two states at the height.
+type report struct {
+	Height proto.Height `json:"height"`
+	Left   string       `json:"left"`
+	Right  string       `json:"right"`
+	// Block IDs and hashes are not set if the state hashes are not available.
+	LeftBlockID  *proto.BlockID  `json:"leftBlockId,omitempty"`
+	RightBlockID *proto.BlockID  `json:"rightBlockId,omitempty"`
+	Hashes       []hashComponent `json:"differentHashes"`
+	// SnapshotHashesDiffer is set if the snapshot state hashes are different.
+	SnapshotHashesDiffer bool        `json:"snapshotHashesDiffer"`
+	Entries              []entryDiff `json:"differentEntries"`
+}
+
+func (r *report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (r *report) writeText(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Height %d, left '%s', right '%s'\n", r.Height, r.Left, r.Right)
+	switch {
+	case r.LeftBlockID == nil || r.RightBlockID == nil:
+		sb.WriteString("State hashes are not available\n")
+	case *r.LeftBlockID != *r.RightBlockID:
+		fmt.Fprintf(&sb, "Different blocks: left '%s', right '%s'\n", r.LeftBlockID.String(), r.RightBlockID.String())
+	default:
+		fmt.Fprintf(&sb, "Block '%s'\n", r.LeftBlockID.String())
+	}
+	if r.SnapshotHashesDiffer {
+		sb.WriteString("Snapshot state hashes are different\n")
+	}
+	for _, h := range r.Hashes {
+		fmt.Fprintf(&sb, "Different %s hash: left '%s', right '%s'\n", h.Component, h.Left.String(), h.Right.String())
+	}
+	if len(r.Entries) == 0 {
+		sb.WriteString("Changes of state by the block are equal\n")
+	}
+	component := ""
+	for _, e := range r.Entries {
+		if e.Component != component {
+			component = e.Component
+			fmt.Fprintf(&sb, "%s:\n", component)
+		}
+		fmt.Fprintf(&sb, "  %s\n    left:  %s\n    right: %s\n", e.Key, valueString(e.Left), valueString(e.Right))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func valueString(v *string) string {
+	if v == nil {
+		return "<unchanged>"
+	}
+	return *v
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func TestDifferentHashes(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		change   func(h *proto.FieldsHashes)
+		expected []string
+	}{
+		{"equal", func(*proto.FieldsHashes) {}, nil},
+		{"waves balance", func(h *proto.FieldsHashes) { h.WavesBalanceHash = crypto.Digest{1} },
+			[]string{wavesBalanceComponent}},
+		{"data entry and aliases", func(h *proto.FieldsHashes) {
+			h.AliasesHash = crypto.Digest{1}
+			h.DataEntryHash = crypto.Digest{2}
+		}, []string{dataEntryComponent, aliasComponent}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			left, right := &proto.StateHash{}, &proto.StateHash{}
+			test.change(&right.FieldsHashes)
+			res := differentHashes(left, right)
+			components := make([]string, 0, len(res))
+			for _, c := range res {
+				assert.Equal(t, crypto.Digest{}, c.Left)
+				assert.NotEqual(t, crypto.Digest{}, c.Right)
+				components = append(components, c.Component)
+			}
+			assert.ElementsMatch(t, test.expected, components)
+		})
+	}
+}
+
+func TestCollectEntries(t *testing.T) {
+	addr := proto.MustAddressFromString("3MrDis17gyNSusZDg8Eo1PuFnm5SQMda3gu")
+	asset := crypto.Digest{1, 2, 3}
+	bs := proto.BlockSnapshot{TxSnapshots: [][]proto.AtomicSnapshot{
+		{
+			&proto.WavesBalanceSnapshot{Address: addr, Balance: 100},
+			&proto.AssetBalanceSnapshot{Address: addr, AssetID: asset, Balance: 5},
+			&proto.TransactionStatusSnapshot{Status: proto.TransactionSucceeded},
+		},
+		{
+			&proto.WavesBalanceSnapshot{Address: addr, Balance: 50}, // The last value of the key is kept.
+			&proto.DataEntriesSnapshot{Address: addr, DataEntries: proto.DataEntries{
+				&proto.StringDataEntry{Key: "k", Value: "v"},
+			}},
+			&proto.CancelledLeaseSnapshot{LeaseID: asset},
+			&proto.TransactionStatusSnapshot{Status: proto.TransactionFailed},
+		},
+	}}
+	res, err := collectEntries(proto.TestNetScheme, bs)
+	require.NoError(t, err)
+	assert.Equal(t, entries{
+		wavesBalanceComponent: {addr.String(): "50"},
+		assetBalanceComponent: {addr.String() + "/" + asset.String(): "5"},
+		dataEntryComponent:    {addr.String() + "/k": `{"key":"k","type":"string","value":"v"}`},
+		leaseStatusComponent:  {asset.String(): "cancelled"},
+		transactionStatusComponent: {
+			"#0": "succeeded",
+			"#1": "failed",
+		},
+	}, res)
+}
+
+func TestDiffEntries(t *testing.T) {
+	str := func(s string) *string { return &s }
+	for _, test := range []struct {
+		name        string
+		left, right entries
+		expected    []entryDiff
+	}{
+		{"empty", entries{}, entries{}, nil},
+		{"equal",
+			entries{wavesBalanceComponent: {"a": "1"}},
+			entries{wavesBalanceComponent: {"a": "1"}},
+			nil,
+		},
+		{"different value",
+			entries{wavesBalanceComponent: {"a": "1", "b": "2"}},
+			entries{wavesBalanceComponent: {"a": "1", "b": "3"}},
+			[]entryDiff{{Component: wavesBalanceComponent, Key: "b", Left: str("2"), Right: str("3")}},
+		},
+		{"missing keys",
+			entries{aliasComponent: {"x": "1"}},
+			entries{aliasComponent: {"y": "2"}},
+			[]entryDiff{
+				{Component: aliasComponent, Key: "x", Left: str("1")},
+				{Component: aliasComponent, Key: "y", Right: str("2")},
+			},
+		},
+		{"components order",
+			entries{transactionStatusComponent: {"#0": "failed"}, wavesBalanceComponent: {"b": "1"}},
+			entries{transactionStatusComponent: {"#0": "succeeded"}, wavesBalanceComponent: {"a": "1"}},
+			[]entryDiff{
+				{Component: wavesBalanceComponent, Key: "a", Right: str("1")},
+				{Component: wavesBalanceComponent, Key: "b", Left: str("1")},
+				{Component: transactionStatusComponent, Key: "#0", Left: str("failed"), Right: str("succeeded")},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, diffEntries(test.left, test.right))
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
+	"github.com/wavesplatform/gowaves/pkg/logging"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/util/fdlimit"
+	"github.com/wavesplatform/gowaves/pkg/versioning"
+)
+
+const (
+	clearance           = 10
+	defaultSnapshotPath = "/go/blocks/snapshot/at/%d"
+)
+
+var usage = `
+Usage:
+  statediff -state-path <path> (-other-state-path <path> | -node <URL>) [flags]
+
+Compares the changes of two states by the block at the height, down to the keys of balances, data entries,
+scripts, leases, aliases and asset infos. If the height is not set, the first height with different state
+hashes is searched for.
+
+Flags:
+`
+
+type config struct {
+	logLevel       zapcore.Level
+	statePath      string
+	otherStatePath string
+	node           string
+	snapshotPath   string
+	blockchainType string
+	cfgPath        string
+	height         uint64
+	extendedAPI    bool
+	stateHashes    bool
+	dbBackend      string
+	jsonOutput     bool
+}
+
+func main() {
+	c := config{logLevel: zapcore.InfoLevel}
+	flag.Usage = func() {
+		_, _ = fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Var(&c.logLevel, "log-level",
+		"Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL. Default logging level INFO.")
+	flag.StringVar(&c.statePath, "state-path", "", "Path to the state directory.")
+	flag.StringVar(&c.otherStatePath, "other-state-path", "", "Path to the state directory to compare with.")
+	flag.StringVar(&c.node, "node", "", "URL of node's API to compare with.")
+	flag.StringVar(&c.snapshotPath, "snapshot-path", defaultSnapshotPath,
+		"Path of node's API returning block snapshot at the height, which is substituted for '%d'.")
+	flag.StringVar(&c.blockchainType, "blockchain-type", "mainnet", "Blockchain type: mainnet/testnet/stagenet/custom.")
+	flag.StringVar(&c.cfgPath, "cfg-path", "", "Path to configuration JSON file, only for custom blockchain.")
+	flag.Uint64Var(&c.height, "height", 0,
+		"Height to compare at. By default, the first height with different state hashes.")
+	flag.BoolVar(&c.extendedAPI, "build-extended-api", false,
+		"The states are built with extended API data, must match the flag the states were imported with.")
+	flag.BoolVar(&c.stateHashes, "build-state-hashes", true,
+		"The states are built with legacy state hashes, must match the flag the states were imported with. "+
+			"Without state hashes only the changes of state are compared, so the height must be set.")
+	flag.StringVar(&c.dbBackend, "db-backend", keyvalue.LevelDBBackend.String(), "State database backend: leveldb/pebble.")
+	flag.BoolVar(&c.jsonOutput, "json", false, "Print the difference as JSON.")
+	flag.Parse()
+
+	logger := logging.SetupSimpleLogger(c.logLevel)
+	defer func() {
+		err := logger.Sync()
+		if err != nil && errors.Is(err, os.ErrInvalid) {
+			panic(fmt.Sprintf("Failed to close logging subsystem: %v\n", err))
+		}
+	}()
+	zap.S().Debugf("Gowaves StateDiff version: %s", versioning.Version)
+
+	if err := run(context.Background(), &c); err != nil {
+		zap.S().Errorf("Failed to compare states: %v", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, c *config) error {
+	if c.statePath == "" {
+		return errors.New("option state-path is not specified")
+	}
+	if (c.otherStatePath == "") == (c.node == "") {
+		return errors.New("exactly one of options other-state-path and node must be specified")
+	}
+	if c.height == 0 && !c.stateHashes {
+		return errors.New("option height must be specified for states without state hashes")
+	}
+	if !strings.Contains(c.snapshotPath, "%d") {
+		return errors.New("option snapshot-path must contain '%d'")
+	}
+	bs, err := blockchainSettings(c)
+	if err != nil {
+		return err
+	}
+	statesCount := 1
+	if c.otherStatePath != "" {
+		statesCount = 2
+	}
+	params, err := stateParams(c, statesCount)
+	if err != nil {
+		return err
+	}
+	left, closeLeft, err := openState(c.statePath, params, bs)
+	if err != nil {
+		return err
+	}
+	defer closeLeft()
+	var right source
+	if c.otherStatePath != "" {
+		other, closeRight, oErr := openState(c.otherStatePath, params, bs)
+		if oErr != nil {
+			return oErr
+		}
+		defer closeRight()
+		right = other
+	} else {
+		right, err = newNodeSource(strings.TrimSuffix(c.node, "/"), c.snapshotPath)
+		if err != nil {
+			return err
+		}
+	}
+	height := c.height
+	if height == 0 {
+		h, found, sErr := findFirstDifference(ctx, left, right)
+		if sErr != nil {
+			return sErr
+		}
+		if !found {
+			zap.S().Infof("State hashes are equal up to height %d", h)
+			return nil
+		}
+		zap.S().Infof("First different state hashes at height %d", h)
+		height = h
+	}
+	r, err := compareAtHeight(ctx, bs.AddressSchemeCharacter, left, right, height)
+	if err != nil {
+		return err
+	}
+	if c.jsonOutput {
+		return r.writeJSON(os.Stdout)
+	}
+	return r.writeText(os.Stdout)
+}
+
+// findFirstDifference returns the first height with different state hashes. If there is no such height,
+// the top height of both states is returned.
+func findFirstDifference(ctx context.Context, left, right source) (proto.Height, bool, error) {
+	lh, err := left.Height(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	rh, err := right.Height(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	top := min(lh, rh)
+	equal := func(h proto.Height) (bool, error) {
+		lsh, shErr := left.StateHash(ctx, h)
+		if shErr != nil {
+			return false, shErr
+		}
+		rsh, shErr := right.StateHash(ctx, h)
+		if shErr != nil {
+			return false, shErr
+		}
+		return lsh.BlockID == rsh.BlockID && lsh.SumHash == rsh.SumHash && lsh.SnapshotHash == rsh.SnapshotHash, nil
+	}
+	ok, err := equal(top)
+	if err != nil {
+		return 0, false, err
+	}
+	if ok {
+		return top, false, nil
+	}
+	// The hashes are chained, so they differ on all heights after the first difference.
+	lo, hi := proto.Height(1), top
+	for lo < hi {
+		middle := lo + (hi-lo)/2
+		eq, eqErr := equal(middle)
+		if eqErr != nil {
+			return 0, false, eqErr
+		}
+		if eq {
+			lo = middle + 1
+		} else {
+			hi = middle
+		}
+	}
+	return lo, true, nil
+}
+
+func compareAtHeight(
+	ctx context.Context, scheme proto.Scheme, left, right source, height proto.Height,
+) (*report, error) {
+	r := &report{Height: height, Left: left.String(), Right: right.String()}
+	lsh, lErr := left.StateHash(ctx, height)
+	rsh, rErr := right.StateHash(ctx, height)
+	if lErr == nil && rErr == nil {
+		r.LeftBlockID, r.RightBlockID = &lsh.BlockID, &rsh.BlockID
+		r.Hashes = differentHashes(lsh.GetStateHash(), rsh.GetStateHash())
+		r.SnapshotHashesDiffer = lsh.SnapshotHash != rsh.SnapshotHash
+	} else {
+		zap.S().Warnf("State hashes are not compared: %v", errors.Join(lErr, rErr))
+	}
+	lbs, err := left.Snapshot(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	rbs, err := right.Snapshot(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	le, err := collectEntries(scheme, lbs)
+	if err != nil {
+		return nil, err
+	}
+	re, err := collectEntries(scheme, rbs)
+	if err != nil {
+		return nil, err
+	}
+	r.Entries = diffEntries(le, re)
+	return r, nil
+}
+
+func openState(
+	path string, params state.StateParams, bs *settings.BlockchainSettings,
+) (*localSource, func(), error) {
+	st, err := state.NewState(path, false, params, bs, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open state at '%s': %w", path, err)
+	}
+	closeState := func() {
+		if cErr := st.Close(); cErr != nil {
+			zap.S().Errorf("Failed to close state at '%s': %v", path, cErr)
+		}
+	}
+	return &localSource{path: path, st: st}, closeState, nil
+}
+
+func blockchainSettings(c *config) (*settings.BlockchainSettings, error) {
+	if c.cfgPath == "" {
+		return settings.BlockchainSettingsByTypeName(c.blockchainType)
+	}
+	f, err := os.Open(c.cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open configuration file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return settings.ReadBlockchainSettings(f)
+}
+
+func stateParams(c *config, statesCount int) (state.StateParams, error) {
+	maxFDs, err := fdlimit.MaxFDs()
+	if err != nil {
+		return state.StateParams{}, err
+	}
+	if _, err := fdlimit.RaiseMaxFDs(maxFDs); err != nil {
+		return state.StateParams{}, err
+	}
+	backend, err := keyvalue.ParseBackend(c.dbBackend)
+	if err != nil {
+		return state.StateParams{}, err
+	}
+	params := state.DefaultStateParams()
+	params.StorageParams.DbParams.OpenFilesCacheCapacity = (int(maxFDs) - clearance) / statesCount
+	params.DbParams.Backend = backend
+	params.StoreExtendedApiData = c.extendedAPI
+	params.BuildStateHashes = c.stateHashes
+	return params, nil
+}
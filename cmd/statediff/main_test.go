@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// fakeSource has the state hashes diverging from the height diverged, zero means no divergence.
+type fakeSource struct {
+	height   proto.Height
+	diverged proto.Height
+	requests []proto.Height
+}
+
+func (s *fakeSource) String() string {
+	return "fake"
+}
+
+func (s *fakeSource) Height(context.Context) (proto.Height, error) {
+	return s.height, nil
+}
+
+func (s *fakeSource) StateHash(_ context.Context, height proto.Height) (*proto.StateHashDebug, error) {
+	if height == 0 || height > s.height {
+		return nil, errors.Errorf("invalid height %d", height)
+	}
+	s.requests = append(s.requests, height)
+	sh := proto.StateHash{SumHash: crypto.Digest{byte(height)}}
+	if s.diverged != 0 && height >= s.diverged {
+		sh.SumHash[1] = 1
+	}
+	r := proto.NewStateHashJSDebug(sh, height, "fake", crypto.Digest{})
+	return &r, nil
+}
+
+func (s *fakeSource) Snapshot(context.Context, proto.Height) (proto.BlockSnapshot, error) {
+	return proto.BlockSnapshot{}, nil
+}
+
+func TestFindFirstDifference(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		left, right *fakeSource
+		height      proto.Height
+		found       bool
+		maxRequests int
+	}{
+		{"equal", &fakeSource{height: 100}, &fakeSource{height: 100}, 100, false, 1},
+		{"equal up to lower height", &fakeSource{height: 100}, &fakeSource{height: 70, diverged: 80}, 70, false, 1},
+		{"first height", &fakeSource{height: 100}, &fakeSource{height: 100, diverged: 1}, 1, true, 8},
+		{"top height", &fakeSource{height: 100}, &fakeSource{height: 100, diverged: 100}, 100, true, 8},
+		{"middle height", &fakeSource{height: 100, diverged: 37}, &fakeSource{height: 120}, 37, true, 8},
+		{"single block", &fakeSource{height: 1}, &fakeSource{height: 1, diverged: 1}, 1, true, 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			h, found, err := findFirstDifference(context.Background(), test.left, test.right)
+			require.NoError(t, err)
+			assert.Equal(t, test.height, h)
+			assert.Equal(t, test.found, found)
+			assert.LessOrEqual(t, len(test.left.requests), test.maxRequests)
+			assert.Equal(t, test.left.requests, test.right.requests)
+		})
+	}
+}
+
+func TestFindFirstDifferenceError(t *testing.T) {
+	// The state hash at zero height is requested from the empty state.
+	_, _, err := findFirstDifference(context.Background(), &fakeSource{}, &fakeSource{height: 10})
+	assert.ErrorContains(t, err, "invalid height 0")
+}
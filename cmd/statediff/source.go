@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+const requestTimeout = 30 * time.Second
+
+// source is the state to compare, the local one or the state of node behind its API.
+type source interface {
+	String() string
+	Height(ctx context.Context) (proto.Height, error)
+	// StateHash returns the state hashes at the height.
+	StateHash(ctx context.Context, height proto.Height) (*proto.StateHashDebug, error)
+	// Snapshot returns the changes of state by the block at the height.
+	Snapshot(ctx context.Context, height proto.Height) (proto.BlockSnapshot, error)
+}
+
+type localSource struct {
+	path string
+	st   state.StateInfo
+}
+
+func (s *localSource) String() string {
+	return s.path
+}
+
+func (s *localSource) Height(context.Context) (proto.Height, error) {
+	return s.st.Height()
+}
+
+func (s *localSource) StateHash(_ context.Context, height proto.Height) (*proto.StateHashDebug, error) {
+	const localVersion = "local"
+	lsh, err := s.st.LegacyStateHashAtHeight(height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get legacy state hash at height %d", height)
+	}
+	snapshotHash, err := s.st.SnapshotStateHashAtHeight(height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get snapshot state hash at height %d", height)
+	}
+	sh := proto.NewStateHashJSDebug(*lsh, height, localVersion, snapshotHash)
+	return &sh, nil
+}
+
+func (s *localSource) Snapshot(_ context.Context, height proto.Height) (proto.BlockSnapshot, error) {
+	bs, err := s.st.SnapshotsAtHeight(height)
+	if err != nil {
+		return proto.BlockSnapshot{}, errors.Wrapf(err, "failed to get block snapshot at height %d", height)
+	}
+	return bs, nil
+}
+
+type nodeSource struct {
+	url          string
+	snapshotPath string // Format of the path to block snapshot with the height argument.
+	cl           *client.Client
+	httpClient   *http.Client
+}
+
+func newNodeSource(url, snapshotPath string) (*nodeSource, error) {
+	httpClient := &http.Client{Timeout: requestTimeout}
+	cl, err := client.NewClient(client.Options{BaseUrl: url, Client: httpClient})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client for URL '%s'", url)
+	}
+	return &nodeSource{url: url, snapshotPath: snapshotPath, cl: cl, httpClient: httpClient}, nil
+}
+
+func (s *nodeSource) String() string {
+	return s.url
+}
+
+func (s *nodeSource) Height(ctx context.Context) (proto.Height, error) {
+	h, _, err := s.cl.Blocks.Height(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get height of node")
+	}
+	return h.Height, nil
+}
+
+func (s *nodeSource) StateHash(ctx context.Context, height proto.Height) (*proto.StateHashDebug, error) {
+	sh, _, err := s.cl.Debug.StateHashDebug(ctx, height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get state hash of node at height %d", height)
+	}
+	return sh, nil
+}
+
+func (s *nodeSource) Snapshot(ctx context.Context, height proto.Height) (proto.BlockSnapshot, error) {
+	u := s.url + fmt.Sprintf(s.snapshotPath, height)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return proto.BlockSnapshot{}, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return proto.BlockSnapshot{}, errors.Wrapf(err, "failed to get block snapshot of node at height %d", height)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return proto.BlockSnapshot{}, errors.Wrap(err, "failed to read block snapshot")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return proto.BlockSnapshot{}, errors.Errorf("failed to get block snapshot of node at height %d: %s: %s",
+			height, resp.Status, body)
+	}
+	var bs proto.BlockSnapshot
+	if err := json.Unmarshal(body, &bs); err != nil {
+		return proto.BlockSnapshot{}, errors.Wrap(err, "invalid block snapshot")
+	}
+	return bs, nil
+}
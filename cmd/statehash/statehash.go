@@ -16,6 +16,7 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/logging"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/settings"
@@ -51,6 +52,7 @@ func run() error {
 		showVersion        bool
 		onlyLegacy         bool
 		disableBloomFilter bool
+		dbBackend          string
 	)
 
 	logging.SetupLogger(zapcore.InfoLevel)
@@ -66,6 +68,7 @@ func run() error {
 	flag.BoolVar(&showVersion, "version", false, "Print version information and quit")
 	flag.BoolVar(&onlyLegacy, "legacy", false, "Compare only legacy state hashes")
 	flag.BoolVar(&disableBloomFilter, "disable-bloom", false, "Disable bloom filter")
+	flag.StringVar(&dbBackend, "db-backend", keyvalue.LevelDBBackend.String(), "State database backend: leveldb/pebble")
 	flag.Parse()
 
 	if showHelp {
@@ -105,6 +108,7 @@ func run() error {
 	params.VerificationGoroutinesNum = 2 * runtime.NumCPU()
 	params.DbParams.WriteBuffer = 16 * MB
 	params.DbParams.BloomFilterParams.Disable = disableBloomFilter
+	params.DbParams.Backend = keyvalue.Backend(dbBackend)
 	params.StoreExtendedApiData = extendedAPI
 	params.BuildStateHashes = true
 	params.ProvideExtendedApi = false
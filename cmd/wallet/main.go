@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/mr-tron/base58"
 
@@ -31,11 +32,16 @@ const (
 	seedPhraseOpt        = "seed-phrase"
 	seedPhraseBase58Opt  = "seed-phrase-base58"
 	accountSeedBase58Opt = "account-seed-base58"
+	removeOpt            = "remove"
+	renameOpt            = "rename"
+	migrateOpt           = "migrate"
 
 	schemeOpt = "scheme"
 )
 
-var primaryFlags = []string{newOpt, showOpt, seedPhraseOpt, seedPhraseBase58Opt, accountSeedBase58Opt}
+var primaryFlags = []string{
+	newOpt, showOpt, seedPhraseOpt, seedPhraseBase58Opt, accountSeedBase58Opt, removeOpt, renameOpt, migrateOpt,
+}
 
 const (
 	defaultBitSize    = 160
@@ -56,6 +62,10 @@ Examples:
 	./wallet -seed-phrase "..."			Import a seed phrase
 	./wallet -seed-phrase-base58 "..."		Import a Base58 encoded seed phrase
 	./wallet -account-seed-base58 "..."		Import a Base58 encoded account seed
+	./wallet -new -label "miner"			Generate a seed phrase and add the labelled account
+	./wallet -remove -number 1			Remove the account number 1
+	./wallet -rename "payments" -number 1		Change the label of the account number 1
+	./wallet -migrate				Convert the wallet of version 1 to the current format
 `
 
 func schemeFromString(s string) (proto.Scheme, error) {
@@ -71,12 +81,16 @@ type Opts struct {
 	seedPhrase        string
 	base58SeedPhrase  string
 	base58AccountSeed string
+	label             string
 }
 
 func main() {
 	var (
 		show          bool
 		newWallet     bool
+		remove        bool
+		migrate       bool
+		rename        string
 		walletPath    string
 		accountNumber int
 		sch           string
@@ -87,6 +101,11 @@ func main() {
 	flag.StringVar(&opts.seedPhrase, seedPhraseOpt, "", "Import a seed phrase (Primary flag)")
 	flag.StringVar(&opts.base58SeedPhrase, seedPhraseBase58Opt, "", "Import a base58-encoded seed phrase (Primary flag)")
 	flag.StringVar(&opts.base58AccountSeed, accountSeedBase58Opt, "", "Import a base58-encoded account seed (Primary flag)")
+	flag.BoolVar(&remove, removeOpt, false, "Remove the account with the given number from the wallet (Primary flag)")
+	flag.StringVar(&rename, renameOpt, "", "Set the label of the account with the given number (Primary flag)")
+	flag.BoolVar(&migrate, migrateOpt, false,
+		"Convert the wallet of version 1 to the current format, the original file is kept with '.v1' suffix (Primary flag)")
+	flag.StringVar(&opts.label, "label", "", "Label of the new account")
 	flag.StringVar(&walletPath, "wallet", "", "Path to the wallet file")
 	flag.IntVar(&accountNumber, "number", 0, "Account number. 0 is default")
 	flag.StringVar(&sch, schemeOpt, "W", "Network scheme: MainNet=W, TestNet=T, StageNet=S, CustomNet=E. MainNet is default")
@@ -127,6 +146,25 @@ func main() {
 		if err != nil {
 			log.Printf("Failed to create a new wallet: %v", err)
 		}
+	case removeOpt:
+		err = updateWallet(walletPath, func(wlt wallet.Wallet) error {
+			return wlt.RemoveAccount(accountNumber)
+		})
+		if err != nil {
+			log.Printf("Failed to remove the account: %v", err)
+		}
+	case renameOpt:
+		err = updateWallet(walletPath, func(wlt wallet.Wallet) error {
+			return wlt.RenameAccount(accountNumber, rename)
+		})
+		if err != nil {
+			log.Printf("Failed to rename the account: %v", err)
+		}
+	case migrateOpt:
+		err = migrateWallet(walletPath)
+		if err != nil {
+			log.Printf("Failed to migrate the wallet: %v", err)
+		}
 	default:
 		showUsageAndExit()
 	}
@@ -164,15 +202,27 @@ func isASCII(s []byte) bool {
 func printUserMessage(
 	w io.Writer,
 	i int,
+	account wallet.Account,
 	pk crypto.PublicKey,
 	sk crypto.SecretKey,
 	address proto.Address,
-	accountSeedBytes []byte,
 ) error {
 	_, err := fmt.Fprintf(w, "Account number:         %d\n", i)
 	if err != nil {
 		return err
 	}
+	if account.Label != "" {
+		_, err = fmt.Fprintf(w, "Label:                  %s\n", account.Label)
+		if err != nil {
+			return err
+		}
+	}
+	if !account.CreatedAt.IsZero() {
+		_, err = fmt.Fprintf(w, "Created at:             %s\n", account.CreatedAt.Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+	}
 	_, err = fmt.Fprintf(w, "Address:                %s\n", address.String())
 	if err != nil {
 		return err
@@ -185,13 +235,13 @@ func printUserMessage(
 	if err != nil {
 		return err
 	}
-	if isASCII(accountSeedBytes) {
-		_, err = fmt.Fprintf(w, "Account seed:           %s\n", string(accountSeedBytes))
+	if isASCII(account.Seed) {
+		_, err = fmt.Fprintf(w, "Account seed:           %s\n", string(account.Seed))
 		if err != nil {
 			return err
 		}
 	} else {
-		_, err = fmt.Fprintf(w, "Account seed in base58: %s\n", base58.Encode(accountSeedBytes))
+		_, err = fmt.Fprintf(w, "Account seed in base58: %s\n", base58.Encode(account.Seed))
 		if err != nil {
 			return err
 		}
@@ -213,13 +263,17 @@ func showWallet(walletPath string, scheme proto.Scheme) error {
 		return errors.Errorf("failed to read the wallet, %v", err)
 	}
 
-	for i, accountSeedBytes := range wlt.AccountSeeds() {
-		pk, sk, address, genErr := generateOnAccountSeed(accountSeedBytes, scheme)
+	for i, account := range wlt.Accounts() {
+		accountScheme := scheme
+		if account.Scheme != 0 && !isFlagPassed(schemeOpt) {
+			accountScheme = account.Scheme
+		}
+		pk, sk, address, genErr := generateOnAccountSeed(account.Seed, accountScheme)
 		if genErr != nil {
 			return errors.Wrap(genErr, "failed to receive wallet's credentials")
 		}
 		fmt.Println()
-		pErr := printUserMessage(os.Stdout, i, pk, sk, address, accountSeedBytes)
+		pErr := printUserMessage(os.Stdout, i, account, pk, sk, address)
 		if pErr != nil {
 			return errors.Wrap(pErr, "failed to print the user message")
 		}
@@ -421,7 +475,12 @@ func createWallet(
 		wlt = wallet.NewWallet()
 	}
 
-	err = wlt.AddAccountSeed(walletCredentials.accountSeed.Bytes())
+	err = wlt.AddAccount(wallet.Account{
+		Seed:      walletCredentials.accountSeed.Bytes(),
+		Label:     opts.label,
+		Scheme:    scheme,
+		CreatedAt: time.Now().UTC(),
+	})
 	if err != nil {
 		return errors.Wrap(err, "failed to add the account seed to the wallet")
 	}
@@ -457,6 +516,69 @@ func createWallet(
 	return nil
 }
 
+// updateWallet reads the existing wallet, changes it and writes it back encrypted with the same password.
+func updateWallet(walletPath string, update func(wlt wallet.Wallet) error) error {
+	walletPath, err := getWalletPath(walletPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to handle wallet's path")
+	}
+	if !exists(walletPath) {
+		return errors.New("wallet does not exist")
+	}
+	wlt, password, err := ReadWallet(walletPath)
+	if err != nil {
+		return err
+	}
+	if err := update(wlt); err != nil {
+		return err
+	}
+	bts, err := wlt.Encode(password)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the wallet")
+	}
+	if err := os.WriteFile(walletPath, bts, 0600); err != nil {
+		return errors.Wrap(err, "failed to write the wallet")
+	}
+	fmt.Printf("Wallet %s has been updated successfully\n", walletPath)
+	return nil
+}
+
+// migrateWallet re-encodes the wallet of version 1 in the current format. The original file is kept
+// with '.v1' suffix, so the migration can be undone by hand.
+func migrateWallet(walletPath string) error {
+	walletPath, err := getWalletPath(walletPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to handle wallet's path")
+	}
+	if !exists(walletPath) {
+		return errors.New("wallet does not exist")
+	}
+	wlt, password, err := ReadWallet(walletPath)
+	if err != nil {
+		return err
+	}
+	if w, ok := wlt.(*wallet.WalletImpl); !ok || w.Version != wallet.LegacyVersion {
+		fmt.Printf("Wallet %s is already in the current format\n", walletPath)
+		return nil
+	}
+	backupPath := walletPath + ".v1"
+	if exists(backupPath) {
+		return errors.Errorf("backup file %s already exists", backupPath)
+	}
+	bts, err := wlt.Encode(password)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the wallet")
+	}
+	if err := os.Rename(walletPath, backupPath); err != nil {
+		return errors.Wrap(err, "failed to back up the wallet")
+	}
+	if err := os.WriteFile(walletPath, bts, 0600); err != nil {
+		return errors.Wrap(err, "failed to write the wallet")
+	}
+	fmt.Printf("Wallet %s has been migrated successfully, the original wallet is saved to %s\n", walletPath, backupPath)
+	return nil
+}
+
 func userHomeDir() (string, error) {
 	u, err := user.Current()
 	if err != nil {
@@ -1,6 +1,19 @@
 package api
 
-import "github.com/pkg/errors"
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+
+	apiErrs "github.com/wavesplatform/gowaves/pkg/api/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
 
 func (a *App) Addresses() ([]string, error) {
 	accounts, err := a.Accounts()
@@ -15,3 +28,427 @@ func (a *App) Addresses() ([]string, error) {
 
 	return addresses, nil
 }
+
+type addressBalance struct {
+	Address       proto.WavesAddress `json:"address"`
+	Confirmations uint64             `json:"confirmations"`
+	Height        proto.Height       `json:"height,omitempty"`
+	Balance       uint64             `json:"balance"`
+}
+
+// confirmationsRange returns the range of heights which are covered by the given number of confirmations.
+func (a *App) confirmationsRange(confirmations uint64) (proto.Height, proto.Height, error) {
+	height, err := a.state.Height()
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to get state height")
+	}
+	if confirmations >= height {
+		return 0, 0, apiErrs.NewCustomValidationError("Number of confirmations exceeds the blockchain height")
+	}
+	return height - confirmations, height, nil
+}
+
+// AddressesBalance returns the minimal regular balance of the address over the last confirmations blocks.
+func (a *App) AddressesBalance(addr proto.WavesAddress, confirmations uint64) (uint64, error) {
+	rcp := proto.NewRecipientFromAddress(addr)
+	if confirmations == 0 {
+		return a.state.WavesBalance(rcp)
+	}
+	start, end, err := a.confirmationsRange(confirmations)
+	if err != nil {
+		return 0, err
+	}
+	return a.state.MinWavesBalanceInRange(rcp, start, end)
+}
+
+// AddressesBalanceAtHeight returns the regular balance of the address as of the given height.
+func (a *App) AddressesBalanceAtHeight(addr proto.WavesAddress, height proto.Height) (uint64, error) {
+	balance, err := a.state.WavesBalanceAtHeight(proto.NewRecipientFromAddress(addr), height)
+	if err != nil {
+		return 0, historyHeightError(err)
+	}
+	return balance, nil
+}
+
+// AddressesEffectiveBalance returns the minimal effective balance of the address over the last confirmations blocks.
+func (a *App) AddressesEffectiveBalance(addr proto.WavesAddress, confirmations uint64) (uint64, error) {
+	rcp := proto.NewRecipientFromAddress(addr)
+	if confirmations == 0 {
+		balance, err := a.state.FullWavesBalance(rcp)
+		if err != nil {
+			return 0, err
+		}
+		return balance.Effective, nil
+	}
+	start, end, err := a.confirmationsRange(confirmations)
+	if err != nil {
+		return 0, err
+	}
+	return a.state.MinEffectiveBalanceInRange(rcp, start, end)
+}
+
+type addressScriptInfo struct {
+	Address              proto.WavesAddress `json:"address"`
+	Script               *string            `json:"script,omitempty"`
+	ScriptText           *string            `json:"scriptText,omitempty"`
+	Version              int32              `json:"version"`
+	Complexity           uint64             `json:"complexity"`
+	VerifierComplexity   uint64             `json:"verifierComplexity"`
+	CallableComplexities map[string]uint64  `json:"callableComplexities"`
+	ExtraFee             uint64             `json:"extraFee"`
+}
+
+func (a *App) AddressesScriptInfo(addr proto.WavesAddress) (*addressScriptInfo, error) {
+	info := &addressScriptInfo{Address: addr, CallableComplexities: map[string]uint64{}}
+	scriptInfo, err := a.state.ScriptInfoByAccount(proto.NewRecipientFromAddress(addr))
+	if err != nil {
+		if state.IsNotFound(err) {
+			return info, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get script info of address %q", addr.String())
+	}
+	if len(scriptInfo.Bytes) == 0 {
+		return info, nil
+	}
+	sc, err := a.estimateScript(scriptInfo.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to estimate script of address %q", addr.String())
+	}
+	src, err := decompileScript(scriptInfo.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decompile script of address %q", addr.String())
+	}
+	script := proto.Script(scriptInfo.Bytes).String()
+	info.Script = &script
+	info.ScriptText = &src
+	info.Version = scriptInfo.Version
+	info.Complexity = scriptInfo.Complexity
+	info.VerifierComplexity = sc.verifier
+	info.CallableComplexities = sc.callables
+	info.ExtraFee = sc.extraFee
+	return info, nil
+}
+
+// AddressesData returns data entries of the address. If keys are given only entries with those keys are returned,
+// otherwise all entries with the keys matching the given regular expression (if any) are returned.
+// Removed entries are never returned.
+func (a *App) AddressesData(addr proto.WavesAddress, keys []string, matches *regexp.Regexp) (proto.DataEntries, error) {
+	rcp := proto.NewRecipientFromAddress(addr)
+	res := proto.DataEntries{} // ensure that empty array will be return instead of nil
+	if len(keys) > 0 {
+		for _, key := range keys {
+			entry, err := a.state.RetrieveEntry(rcp, key)
+			if err != nil {
+				if state.IsNotFound(err) {
+					continue
+				}
+				return nil, errors.Wrapf(err, "failed to get data entry %q of address %q", key, addr.String())
+			}
+			if entry.GetValueType() == proto.DataDelete {
+				continue
+			}
+			res = append(res, entry)
+		}
+		return res, nil
+	}
+	entries, err := a.state.RetrieveEntries(rcp)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return res, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get data entries of address %q", addr.String())
+	}
+	for _, entry := range entries {
+		if entry.GetValueType() == proto.DataDelete {
+			continue
+		}
+		if matches != nil && !matches.MatchString(entry.GetKey()) {
+			continue
+		}
+		res = append(res, entry)
+	}
+	return res, nil
+}
+
+func (a *App) AddressesDataKey(addr proto.WavesAddress, key string) (proto.DataEntry, error) {
+	entry, err := a.state.RetrieveEntry(proto.NewRecipientFromAddress(addr), key)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return nil, apiErrs.DataKeyDoesNotExist
+		}
+		return nil, errors.Wrapf(err, "failed to get data entry %q of address %q", key, addr.String())
+	}
+	if entry.GetValueType() == proto.DataDelete {
+		return nil, apiErrs.DataKeyDoesNotExist
+	}
+	return entry, nil
+}
+
+// AddressesDataKeyAtHeight returns the data entry of the address as of the given height.
+func (a *App) AddressesDataKeyAtHeight(addr proto.WavesAddress, key string, height proto.Height) (proto.DataEntry, error) {
+	entry, err := a.state.RetrieveEntryAtHeight(proto.NewRecipientFromAddress(addr), key, height)
+	if err != nil {
+		if state.IsInvalidInput(err) {
+			return nil, apiErrs.NewCustomValidationError(err.Error())
+		}
+		if state.IsNotFound(err) {
+			return nil, apiErrs.DataKeyDoesNotExist
+		}
+		return nil, errors.Wrapf(err, "failed to get data entry %q of address %q at height %d", key, addr.String(), height)
+	}
+	return entry, nil
+}
+
+// historyHeightError converts the state error of the request at height outside of the history range
+// to the validation error, other errors are returned as is.
+func historyHeightError(err error) error {
+	if state.IsInvalidInput(err) {
+		return apiErrs.NewCustomValidationError(err.Error())
+	}
+	return err
+}
+
+func addressFromURLParam(r *http.Request, key string) (proto.WavesAddress, error) {
+	s := chi.URLParam(r, key)
+	addr, err := proto.NewAddressFromString(s)
+	if err != nil {
+		if invalidRune, isInvalid := findFirstInvalidRuneInBase58String(s); isInvalid {
+			return proto.WavesAddress{}, wavesAddressInvalidCharErr(invalidRune, s)
+		}
+		return proto.WavesAddress{}, apiErrs.InvalidAddress
+	}
+	return addr, nil
+}
+
+func confirmationsFromURLParam(r *http.Request) (uint64, error) {
+	s := chi.URLParam(r, "confirmations")
+	if s == "" {
+		return 0, nil
+	}
+	confirmations, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, apiErrs.NewCustomValidationError("Invalid number of confirmations")
+	}
+	return confirmations, nil
+}
+
+// heightFromQuery returns the height of historical request from the optional 'height' query parameter.
+// Zero height means that the parameter is absent and the current state is requested.
+func heightFromQuery(r *http.Request) (proto.Height, error) {
+	s := r.URL.Query().Get("height")
+	if s == "" {
+		return 0, nil
+	}
+	height, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || height == 0 {
+		return 0, apiErrs.NewCustomValidationError("Invalid height")
+	}
+	return height, nil
+}
+
+func (a *NodeApi) AddressesBalance(w http.ResponseWriter, r *http.Request) error {
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	confirmations, err := confirmationsFromURLParam(r)
+	if err != nil {
+		return err
+	}
+	height, err := heightFromQuery(r)
+	if err != nil {
+		return err
+	}
+	var balance uint64
+	switch {
+	case height != 0 && confirmations != 0:
+		return apiErrs.NewCustomValidationError("Height and number of confirmations can't be used together")
+	case height != 0:
+		balance, err = a.app.AddressesBalanceAtHeight(addr, height)
+	default:
+		balance, err = a.app.AddressesBalance(addr, confirmations)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get balance of address %q", addr.String())
+	}
+	res := addressBalance{Address: addr, Confirmations: confirmations, Height: height, Balance: balance}
+	if err := trySendJson(w, res); err != nil {
+		return errors.Wrap(err, "AddressesBalance")
+	}
+	return nil
+}
+
+func (a *NodeApi) AddressesEffectiveBalance(w http.ResponseWriter, r *http.Request) error {
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	confirmations, err := confirmationsFromURLParam(r)
+	if err != nil {
+		return err
+	}
+	balance, err := a.app.AddressesEffectiveBalance(addr, confirmations)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get effective balance of address %q", addr.String())
+	}
+	if err := trySendJson(w, addressBalance{Address: addr, Confirmations: confirmations, Balance: balance}); err != nil {
+		return errors.Wrap(err, "AddressesEffectiveBalance")
+	}
+	return nil
+}
+
+func (a *NodeApi) AddressesBalanceDetails(w http.ResponseWriter, r *http.Request) error {
+	type balanceDetails struct {
+		Address    proto.WavesAddress `json:"address"`
+		Regular    uint64             `json:"regular"`
+		Generating uint64             `json:"generating"`
+		Available  uint64             `json:"available"`
+		Effective  uint64             `json:"effective"`
+	}
+
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	balance, err := a.state.FullWavesBalance(proto.NewRecipientFromAddress(addr))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get full balance of address %q", addr.String())
+	}
+	out := balanceDetails{
+		Address:    addr,
+		Regular:    balance.Regular,
+		Generating: balance.Generating,
+		Available:  balance.Available,
+		Effective:  balance.Effective,
+	}
+	if err := trySendJson(w, out); err != nil {
+		return errors.Wrap(err, "AddressesBalanceDetails")
+	}
+	return nil
+}
+
+func (a *NodeApi) AddressesScriptInfo(w http.ResponseWriter, r *http.Request) error {
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	info, err := a.app.AddressesScriptInfo(addr)
+	if err != nil {
+		return errors.Wrap(err, "AddressesScriptInfo")
+	}
+	if err := trySendJson(w, info); err != nil {
+		return errors.Wrap(err, "AddressesScriptInfo")
+	}
+	return nil
+}
+
+func (a *NodeApi) AddressesValidate(w http.ResponseWriter, r *http.Request) error {
+	type validateResponse struct {
+		Address string `json:"address"`
+		Valid   bool   `json:"valid"`
+	}
+
+	s := chi.URLParam(r, "address")
+	valid := false
+	if addr, err := proto.NewAddressFromString(s); err == nil {
+		valid, _ = addr.Valid(a.app.scheme())
+	}
+	if err := trySendJson(w, validateResponse{Address: s, Valid: valid}); err != nil {
+		return errors.Wrap(err, "AddressesValidate")
+	}
+	return nil
+}
+
+func (a *NodeApi) AddressesPublicKey(w http.ResponseWriter, r *http.Request) error {
+	type publicKeyResponse struct {
+		Address proto.WavesAddress `json:"address"`
+	}
+
+	pk, err := crypto.NewPublicKeyFromBase58(chi.URLParam(r, "publicKey"))
+	if err != nil {
+		return apiErrs.InvalidPublicKey
+	}
+	addr, err := proto.NewAddressFromPublicKey(a.app.scheme(), pk)
+	if err != nil {
+		return errors.Wrap(err, "failed to create address from public key")
+	}
+	if err := trySendJson(w, publicKeyResponse{Address: addr}); err != nil {
+		return errors.Wrap(err, "AddressesPublicKey")
+	}
+	return nil
+}
+
+func (a *NodeApi) AddressesDataGet(w http.ResponseWriter, r *http.Request) error {
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	var matches *regexp.Regexp
+	if m := query.Get("matches"); m != "" {
+		matches, err = regexp.Compile(m)
+		if err != nil {
+			return apiErrs.NewCustomValidationError("Cannot compile regex")
+		}
+	}
+	entries, err := a.app.AddressesData(addr, query["key"], matches)
+	if err != nil {
+		return errors.Wrap(err, "AddressesDataGet")
+	}
+	if err := trySendJson(w, entries); err != nil {
+		return errors.Wrap(err, "AddressesDataGet")
+	}
+	return nil
+}
+
+func (a *NodeApi) AddressesDataPost(w http.ResponseWriter, r *http.Request) error {
+	var req struct {
+		Keys []string `json:"keys"`
+	}
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	if err := tryParseJson(r.Body, &req); err != nil {
+		return errors.Wrap(err, "failed to parse AddressesDataPost request body as JSON")
+	}
+	if len(req.Keys) == 0 {
+		return apiErrs.NewCustomValidationError("Keys are not specified")
+	}
+	entries, err := a.app.AddressesData(addr, req.Keys, nil)
+	if err != nil {
+		return errors.Wrap(err, "AddressesDataPost")
+	}
+	if err := trySendJson(w, entries); err != nil {
+		return errors.Wrap(err, "AddressesDataPost")
+	}
+	return nil
+}
+
+func (a *NodeApi) AddressesDataKey(w http.ResponseWriter, r *http.Request) error {
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	key, err := url.PathUnescape(chi.URLParam(r, "key"))
+	if err != nil {
+		return apiErrs.NewCustomValidationError("Invalid data key")
+	}
+	height, err := heightFromQuery(r)
+	if err != nil {
+		return err
+	}
+	var entry proto.DataEntry
+	if height != 0 {
+		entry, err = a.app.AddressesDataKeyAtHeight(addr, key, height)
+	} else {
+		entry, err = a.app.AddressesDataKey(addr, key)
+	}
+	if err != nil {
+		return errors.Wrap(err, "AddressesDataKey")
+	}
+	if err := trySendJson(w, entry); err != nil {
+		return errors.Wrap(err, "AddressesDataKey")
+	}
+	return nil
+}
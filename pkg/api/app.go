@@ -30,17 +30,24 @@ type SchedulerEmits interface {
 const (
 	defaultBlockRequestLimit = 100
 	defaultAssetDetailsLimit = 100
+
+	DefaultEvaluateComplexityLimit = 52000
+	DefaultEvaluateTimeout         = 10 * time.Second
 )
 
 type appSettings struct {
-	BlockRequestLimit uint64
-	AssetDetailsLimit int
+	BlockRequestLimit       uint64
+	AssetDetailsLimit       int
+	EvaluateComplexityLimit uint32
+	EvaluateTimeout         time.Duration
 }
 
 func defaultAppSettings() *appSettings {
 	return &appSettings{
-		BlockRequestLimit: defaultBlockRequestLimit,
-		AssetDetailsLimit: defaultAssetDetailsLimit,
+		BlockRequestLimit:       defaultBlockRequestLimit,
+		AssetDetailsLimit:       defaultAssetDetailsLimit,
+		EvaluateComplexityLimit: DefaultEvaluateComplexityLimit,
+		EvaluateTimeout:         DefaultEvaluateTimeout,
 	}
 }
 
@@ -81,6 +88,13 @@ func newApp(apiKey string, scheduler SchedulerEmits, services services.Services,
 	}, nil
 }
 
+// SetScriptEvaluationLimits sets the complexity limit and the timeout of expressions evaluation
+// by /utils/script/evaluate. It must be called before the API is served.
+func (a *App) SetScriptEvaluationLimits(complexity uint32, timeout time.Duration) {
+	a.settings.EvaluateComplexityLimit = complexity
+	a.settings.EvaluateTimeout = timeout
+}
+
 func (a *App) TransactionsBroadcast(ctx context.Context, b []byte) (proto.Transaction, error) {
 	tt := proto.TransactionTypeVersion{}
 	err := json.Unmarshal(b, &tt)
@@ -140,14 +154,13 @@ func (a *App) LoadKeys(apiKey string, password []byte) error {
 }
 
 func (a *App) Accounts() ([]account, error) {
-	seeds := a.services.Wallet.AccountSeeds()
+	pks, err := a.services.Wallet.PublicKeys()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get public keys of wallet accounts")
+	}
 
-	accounts := make([]account, 0, len(seeds))
-	for _, seed := range seeds {
-		_, pk, err := crypto.GenerateKeyPair(seed)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to generate key pair for seed")
-		}
+	accounts := make([]account, 0, len(pks))
+	for _, pk := range pks {
 		addr, err := proto.NewAddressFromPublicKey(a.services.Scheme, pk)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to generate new address from public key")
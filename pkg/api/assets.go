@@ -1,13 +1,25 @@
 package api
 
 import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/go-chi/chi"
 	"github.com/pkg/errors"
+
 	apiErrs "github.com/wavesplatform/gowaves/pkg/api/errors"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/errs"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
 )
 
+// maxAssetDistributionLimit is the maximum number of items in one page of asset distribution.
+const maxAssetDistributionLimit = 1000
+
 type ScriptDetails struct {
 	ScriptComplexity uint64         `json:"scriptComplexity"`
 	Script           proto.B64Bytes `json:"script"`
@@ -126,3 +138,208 @@ func (a *App) generateAssetsDoesNotExistError(fullAssetsIDs []crypto.Digest) err
 	}
 	return nil
 }
+
+type assetBalanceDetails struct {
+	AssetID              crypto.Digest     `json:"assetId"`
+	Balance              uint64            `json:"balance"`
+	Reissuable           bool              `json:"reissuable"`
+	MinSponsoredAssetFee *uint64           `json:"minSponsoredAssetFee"`
+	SponsorBalance       *uint64           `json:"sponsorBalance"`
+	Quantity             uint64            `json:"quantity"`
+	IssueTransaction     proto.Transaction `json:"issueTransaction"`
+}
+
+// AssetsBalances returns balances of all assets of the address with the details of the assets ordered by asset ID.
+func (a *App) AssetsBalances(addr proto.WavesAddress) ([]assetBalanceDetails, error) {
+	balances, err := a.state.AssetBalances(proto.NewRecipientFromAddress(addr))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get asset balances of address %q", addr.String())
+	}
+	res := make([]assetBalanceDetails, 0, len(balances))
+	for id, balance := range balances {
+		info, err := a.state.FullAssetInfo(proto.AssetIDFromDigest(id))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get info of asset %q", id.String())
+		}
+		details := assetBalanceDetails{
+			AssetID:          id,
+			Balance:          balance,
+			Reissuable:       info.Reissuable,
+			Quantity:         info.Quantity,
+			IssueTransaction: info.IssueTransaction,
+		}
+		if info.SponsorshipCost != 0 {
+			cost, sponsorBalance := info.SponsorshipCost, info.SponsorBalance
+			details.MinSponsoredAssetFee = &cost
+			details.SponsorBalance = &sponsorBalance
+		}
+		res = append(res, details)
+	}
+	sort.Slice(res, func(i, j int) bool { return bytes.Compare(res[i].AssetID[:], res[j].AssetID[:]) < 0 })
+	return res, nil
+}
+
+// AssetsDistribution returns the page of distribution of the asset at the given height ordered by addresses.
+// The page starts right after the given address and contains no more than limit items, the flag reports
+// whether there are more items. Zero height means the current one.
+func (a *App) AssetsDistribution(
+	fullAssetID crypto.Digest, height proto.Height, after *proto.WavesAddress, limit int,
+) ([]state.AssetHolder, bool, error) {
+	assetID := proto.AssetIDFromDigest(fullAssetID)
+	exist, err := a.state.IsAssetExist(assetID)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to check asset=%q whether it exists or not", fullAssetID)
+	}
+	if !exist {
+		return nil, false, apiErrs.NewAssetDoesNotExistError(fullAssetID)
+	}
+	distribution, hasNext, err := a.state.AssetDistribution(assetID, height, after, limit)
+	if err != nil {
+		return nil, false, errors.Wrapf(historyHeightError(err), "failed to get distribution of asset %q",
+			fullAssetID.String())
+	}
+	return distribution, hasNext, nil
+}
+
+func assetIDFromURLParam(r *http.Request, key string) (crypto.Digest, error) {
+	id, err := crypto.NewDigestFromBase58(chi.URLParam(r, key))
+	if err != nil {
+		return crypto.Digest{}, apiErrs.InvalidAssetId
+	}
+	return id, nil
+}
+
+func (a *NodeApi) AssetsBalanceByAddress(w http.ResponseWriter, r *http.Request) error {
+	type balancesResponse struct {
+		Address  proto.WavesAddress    `json:"address"`
+		Balances []assetBalanceDetails `json:"balances"`
+	}
+
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	balances, err := a.app.AssetsBalances(addr)
+	if err != nil {
+		return errors.Wrap(err, "AssetsBalanceByAddress")
+	}
+	if err := trySendJson(w, balancesResponse{Address: addr, Balances: balances}); err != nil {
+		return errors.Wrap(err, "AssetsBalanceByAddress")
+	}
+	return nil
+}
+
+func (a *NodeApi) AssetsBalanceByAddressAndAsset(w http.ResponseWriter, r *http.Request) error {
+	type balanceResponse struct {
+		Address proto.WavesAddress `json:"address"`
+		AssetID crypto.Digest      `json:"assetId"`
+		Height  proto.Height       `json:"height,omitempty"`
+		Balance uint64             `json:"balance"`
+	}
+
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	assetID, err := assetIDFromURLParam(r, "assetId")
+	if err != nil {
+		return err
+	}
+	height, err := heightFromQuery(r)
+	if err != nil {
+		return err
+	}
+	rcp := proto.NewRecipientFromAddress(addr)
+	var balance uint64
+	if height != 0 {
+		balance, err = a.state.AssetBalanceAtHeight(rcp, proto.AssetIDFromDigest(assetID), height)
+		err = historyHeightError(err)
+	} else {
+		balance, err = a.state.AssetBalance(rcp, proto.AssetIDFromDigest(assetID))
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get balance of asset %q of address %q", assetID.String(), addr.String())
+	}
+	if err := trySendJson(w, balanceResponse{Address: addr, AssetID: assetID, Height: height, Balance: balance}); err != nil {
+		return errors.Wrap(err, "AssetsBalanceByAddressAndAsset")
+	}
+	return nil
+}
+
+// AssetsDistribution returns the whole distribution of the asset at the current height. The distribution is read
+// page by page, so the state is not locked for the whole iteration.
+func (a *NodeApi) AssetsDistribution(w http.ResponseWriter, r *http.Request) error {
+	assetID, err := assetIDFromURLParam(r, "id")
+	if err != nil {
+		return err
+	}
+	height, err := a.state.Height()
+	if err != nil {
+		return errors.Wrap(err, "failed to get state height")
+	}
+	out := make(map[string]uint64)
+	var after *proto.WavesAddress
+	for {
+		page, hasNext, pErr := a.app.AssetsDistribution(assetID, height, after, maxAssetDistributionLimit)
+		if pErr != nil {
+			return errors.Wrap(pErr, "AssetsDistribution")
+		}
+		for _, item := range page {
+			out[item.Address.String()] = item.Balance
+		}
+		if !hasNext || len(page) == 0 {
+			break
+		}
+		after = &page[len(page)-1].Address
+	}
+	if err := trySendJson(w, out); err != nil {
+		return errors.Wrap(err, "AssetsDistribution")
+	}
+	return nil
+}
+
+func (a *NodeApi) AssetsDistributionAtHeight(w http.ResponseWriter, r *http.Request) error {
+	type distributionResponse struct {
+		HasNext  bool                          `json:"hasNext"`
+		LastItem *proto.WavesAddress           `json:"lastItem"`
+		Items    map[proto.WavesAddress]uint64 `json:"items"`
+	}
+
+	assetID, err := assetIDFromURLParam(r, "id")
+	if err != nil {
+		return err
+	}
+	height, err := strconv.ParseUint(chi.URLParam(r, "height"), 10, 64)
+	if err != nil || height == 0 {
+		return apiErrs.NewCustomValidationError("Invalid height")
+	}
+	limit, err := strconv.ParseUint(chi.URLParam(r, "limit"), 10, 64)
+	if err != nil || limit == 0 || limit > maxAssetDistributionLimit {
+		return apiErrs.NewCustomValidationError(
+			fmt.Sprintf("Limit should be greater than 0 and less than or equal to %d", maxAssetDistributionLimit),
+		)
+	}
+	var after *proto.WavesAddress
+	if s := r.URL.Query().Get("after"); s != "" {
+		addr, err := proto.NewAddressFromString(s)
+		if err != nil {
+			return apiErrs.InvalidAddress
+		}
+		after = &addr
+	}
+	distribution, hasNext, err := a.app.AssetsDistribution(assetID, height, after, int(limit))
+	if err != nil {
+		return errors.Wrap(err, "AssetsDistributionAtHeight")
+	}
+	out := distributionResponse{HasNext: hasNext, Items: make(map[proto.WavesAddress]uint64, len(distribution))}
+	for _, item := range distribution {
+		out.Items[item.Address] = item.Balance
+	}
+	if len(distribution) > 0 {
+		out.LastItem = &distribution[len(distribution)-1].Address
+	}
+	if err := trySendJson(w, out); err != nil {
+		return errors.Wrap(err, "AssetsDistributionAtHeight")
+	}
+	return nil
+}
@@ -171,6 +171,28 @@ func NewCustomValidationError(message string) *CustomValidationError {
 	}
 }
 
+func NewScriptCompilerError(message string) *ScriptCompilerError {
+	return &ScriptCompilerError{
+		genericError: genericError{
+			ID:       ScriptCompilerErrorID,
+			HttpCode: http.StatusBadRequest,
+			Message:  message,
+		},
+	}
+}
+
+func NewScriptExecutionError(message string) *ScriptExecutionError {
+	return &ScriptExecutionError{
+		validationError: validationError{
+			genericError: genericError{
+				ID:       ScriptExecutionErrorErrorID,
+				HttpCode: http.StatusBadRequest,
+				Message:  message,
+			},
+		},
+	}
+}
+
 func NewAliasDoesNotExistError(aliasFull string) *AliasDoesNotExistError {
 	return &AliasDoesNotExistError{
 		genericError: genericError{
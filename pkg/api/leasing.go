@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const (
+	// maxActiveLeases is the maximum number of active leases returned for the address.
+	maxActiveLeases = 1000
+	// maxActiveLeasesSearchDepth is the number of the most recent transactions of the address
+	// that are looked through in search of active leases.
+	maxActiveLeasesSearchDepth = 10000
+)
+
+// LeasingActive returns active lease transactions in which the address is a sender or a recipient.
+// Only leases among the maxActiveLeasesSearchDepth most recent transactions of the address are returned.
+func (a *App) LeasingActive(addr proto.WavesAddress) ([]proto.Transaction, error) {
+	isActive := func(tx proto.Transaction) (bool, error) {
+		var id *crypto.Digest
+		switch t := tx.(type) {
+		case *proto.LeaseWithSig:
+			id = t.ID
+		case *proto.LeaseWithProofs:
+			id = t.ID
+		default:
+			return false, nil
+		}
+		active, err := a.state.IsActiveLeasing(*id)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to check whether lease %q is active", id.String())
+		}
+		return active, nil
+	}
+	return a.addressTransactions(addr, maxActiveLeases, maxActiveLeasesSearchDepth, nil, isActive)
+}
+
+func (a *NodeApi) LeasingActive(w http.ResponseWriter, r *http.Request) error {
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	leases, err := a.app.LeasingActive(addr)
+	if err != nil {
+		return errors.Wrap(err, "LeasingActive")
+	}
+	if err := trySendJson(w, leases); err != nil {
+		return errors.Wrap(err, "LeasingActive")
+	}
+	return nil
+}
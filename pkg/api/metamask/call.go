@@ -0,0 +1,136 @@
+package metamask
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/proto/ethabi"
+	"github.com/wavesplatform/gowaves/pkg/ride"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+// errNotDApp is returned if there is no DApp at the called address.
+var errNotDApp = errors.New("address is not a DApp")
+
+// dAppCall is the call of DApp callable function decoded from the Ethereum call data.
+type dAppCall struct {
+	dApp     proto.WavesAddress
+	tree     *ast.Tree
+	call     proto.FunctionCall
+	payments proto.ScriptPayments
+	caller   *state.EvaluationCaller
+}
+
+// newDAppCall decodes the call of DApp callable function by the account with the given Ethereum address.
+// The DApp calls itself if the address is not set.
+func newDAppCall(
+	st state.State, scheme proto.Scheme, from *proto.EthereumAddress, to proto.EthereumAddress, data []byte,
+) (dAppCall, error) {
+	var caller *state.EvaluationCaller
+	if from != nil {
+		addr, err := from.ToWavesAddress(scheme)
+		if err != nil {
+			return dAppCall{}, errors.Wrapf(err, "failed to convert ethereum address %q to waves address", from)
+		}
+		// The public key of Ethereum account can't be restored from the address, so it's left empty.
+		caller = &state.EvaluationCaller{Address: addr}
+	}
+	dApp, err := to.ToWavesAddress(scheme)
+	if err != nil {
+		return dAppCall{}, errors.Wrapf(err, "failed to convert ethereum address %q to waves address", to)
+	}
+	tree, err := st.NewestScriptByAccount(proto.NewRecipientFromAddress(dApp))
+	switch {
+	case state.IsNotFound(err):
+		return dAppCall{}, errNotDApp
+	case err != nil:
+		return dAppCall{}, errors.Wrapf(err, "failed to get script of address %q", dApp.String())
+	case !tree.IsDApp():
+		return dAppCall{}, errNotDApp
+	}
+	methods, err := ethabi.NewMethodsMapFromRideDAppMeta(tree.Meta)
+	if err != nil {
+		return dAppCall{}, err
+	}
+	distribution, err := st.IsActivated(int16(settings.BlockRewardDistribution))
+	if err != nil {
+		return dAppCall{}, err
+	}
+	decoded, err := methods.ParseCallDataRide(data, distribution)
+	if err != nil {
+		return dAppCall{}, errors.Wrap(err, "failed to parse call data")
+	}
+	args, err := proto.ConvertDecodedEthereumArgumentsToProtoArguments(decoded.Inputs)
+	if err != nil {
+		return dAppCall{}, errors.Wrap(err, "failed to convert call arguments")
+	}
+	payments := make(proto.ScriptPayments, len(decoded.Payments))
+	for i, p := range decoded.Payments {
+		if p.Amount <= 0 {
+			return dAppCall{}, errors.Errorf("invalid payment amount '%d'", p.Amount)
+		}
+		asset := proto.NewOptionalAsset(p.PresentAssetID, p.AssetID)
+		payments[i] = proto.ScriptPayment{Amount: uint64(p.Amount), Asset: asset}
+	}
+	return dAppCall{
+		dApp:     dApp,
+		tree:     tree,
+		call:     proto.NewFunctionCall(decoded.Name, args),
+		payments: payments,
+		caller:   caller,
+	}, nil
+}
+
+// evaluate calls the function read-only within the complexity limit of invocation of the DApp.
+func (c dAppCall) evaluate(st state.State) (ride.Result, error) {
+	limit, err := ride.MaxChainInvokeComplexityByVersion(c.tree.LibVersion)
+	if err != nil {
+		return nil, err
+	}
+	env, err := state.NewEvaluationEnvironment(st, c.dApp, c.tree, c.call, c.payments, c.caller, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create RIDE environment")
+	}
+	r, err := ride.CallFunction(env, c.tree, c.call)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to call function '%s'", c.call.Name())
+	}
+	return r, nil
+}
+
+// callDApp evaluates the call of DApp callable function and returns its result encoded according to the ABI.
+func callDApp(
+	st state.State, scheme proto.Scheme, from *proto.EthereumAddress, to proto.EthereumAddress, data []byte,
+) ([]byte, error) {
+	c, err := newDAppCall(st, scheme, from, to, data)
+	if err != nil {
+		return nil, err
+	}
+	r, err := c.evaluate(st)
+	if err != nil {
+		return nil, err
+	}
+	values, err := ride.NewABIValues(r)
+	if err != nil {
+		return nil, err
+	}
+	return ethabi.EncodeValuesToABI(values...)
+}
+
+// estimateDAppCallFee evaluates the call of DApp callable function and returns the fee required by the invocation
+// with the spent complexity.
+func estimateDAppCallFee(
+	st state.State, scheme proto.Scheme, from *proto.EthereumAddress, to proto.EthereumAddress, data []byte,
+) (uint64, error) {
+	c, err := newDAppCall(st, scheme, from, to, data)
+	if err != nil {
+		return 0, err
+	}
+	r, err := c.evaluate(st)
+	if err != nil {
+		return 0, err
+	}
+	return state.EvaluatedInvokeFee(st, c.tree.LibVersion, r.Complexity(), r.ScriptActions())
+}
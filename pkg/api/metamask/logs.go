@@ -0,0 +1,442 @@
+package metamask
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/proto/ethabi"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+// maxLogsBlockRange is the maximal number of blocks which can be scanned by one eth_getLogs request.
+const maxLogsBlockRange = 1000
+
+// Log is an ERC20 Transfer event log synthesized from the asset transfer of Waves transaction.
+type Log struct {
+	Address          proto.EthereumAddress `json:"address"`
+	Topics           []proto.EthereumHash  `json:"topics"`
+	Data             string                `json:"data"`
+	BlockNumber      string                `json:"blockNumber"`
+	TransactionHash  proto.EthereumHash    `json:"transactionHash"`
+	TransactionIndex string                `json:"transactionIndex"`
+	BlockHash        string                `json:"blockHash"`
+	LogIndex         string                `json:"logIndex"`
+	Removed          bool                  `json:"removed"`
+}
+
+// addressFilter is the list of contract addresses, it can be given as a single address or as an array.
+type addressFilter []proto.EthereumAddress
+
+func (f *addressFilter) UnmarshalJSON(data []byte) error {
+	var addr proto.EthereumAddress
+	if err := json.Unmarshal(data, &addr); err == nil {
+		*f = addressFilter{addr}
+		return nil
+	}
+	var addresses []proto.EthereumAddress
+	if err := json.Unmarshal(data, &addresses); err != nil {
+		return errors.Wrap(err, "address filter is neither an address nor an array of addresses")
+	}
+	*f = addresses
+	return nil
+}
+
+func (f addressFilter) match(addr proto.EthereumAddress) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, a := range f {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// topicsFilter is the list of topic alternatives by position. Empty alternatives match any topic.
+type topicsFilter [][]proto.EthereumHash
+
+func (f *topicsFilter) UnmarshalJSON(data []byte) error {
+	var positions []json.RawMessage
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return errors.Wrap(err, "topics filter is not an array")
+	}
+	out := make(topicsFilter, len(positions))
+	for i, p := range positions {
+		var topic *proto.EthereumHash
+		if err := json.Unmarshal(p, &topic); err == nil {
+			if topic != nil {
+				out[i] = []proto.EthereumHash{*topic}
+			}
+			continue
+		}
+		if err := json.Unmarshal(p, &out[i]); err != nil {
+			return errors.Wrapf(err, "topic filter at position %d is neither a topic nor an array of topics", i)
+		}
+	}
+	*f = out
+	return nil
+}
+
+func (f topicsFilter) match(topics []proto.EthereumHash) bool {
+	if len(f) > len(topics) {
+		return false
+	}
+	for i, alternatives := range f {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, t := range alternatives {
+			if t == topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type getLogsFilter struct {
+	FromBlock *string         `json:"fromBlock"`
+	ToBlock   *string         `json:"toBlock"`
+	Address   addressFilter   `json:"address"`
+	Topics    topicsFilter    `json:"topics"`
+	BlockHash *proto.HexBytes `json:"blockHash"`
+}
+
+func (f getLogsFilter) match(l Log) bool {
+	return f.Address.match(l.Address) && f.Topics.match(l.Topics)
+}
+
+// Eth_GetLogs returns ERC20 Transfer event logs of asset transfers, mass transfers and invoke payments
+// matching the filter.
+//   - filter: fromBlock and toBlock as QUANTITY|TAG or blockHash, address of asset as a single address or
+//     an array, topics as an array of topics or arrays of alternative topics
+func (s RPCService) Eth_GetLogs(filter getLogsFilter) ([]Log, error) {
+	from, to, err := s.logsBlockRange(filter)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]Log, 0)
+	for h := from; h <= to; h++ {
+		block, err := s.nodeRPCApp.State.BlockByHeight(h)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get block at height %d", h)
+		}
+		blockLogs, err := newBlockLogs(s.nodeRPCApp.State, s.nodeRPCApp.Scheme, block, h, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range blockLogs {
+			if filter.match(l) {
+				logs = append(logs, l)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// newBlockLogs returns the logs of block transactions starting from the transaction with the given index.
+func newBlockLogs(st state.State, scheme proto.Scheme, block *proto.Block, height proto.Height, first int) ([]Log, error) {
+	var logs []Log
+	blockHash := proto.EncodeToHexString(block.BlockID().Bytes())
+	logIndex := 0
+	for i, tx := range block.Transactions {
+		events, err := transferEvents(st, scheme, tx, height)
+		if err != nil {
+			return nil, err
+		}
+		txLogs, err := newTransferLogs(events, tx, scheme, blockHash, height, uint64(i), logIndex)
+		if err != nil {
+			return nil, err
+		}
+		logIndex += len(txLogs)
+		if i >= first {
+			logs = append(logs, txLogs...)
+		}
+	}
+	return logs, nil
+}
+
+func (s RPCService) logsBlockRange(filter getLogsFilter) (proto.Height, proto.Height, error) {
+	if filter.BlockHash != nil {
+		if filter.FromBlock != nil || filter.ToBlock != nil {
+			return 0, 0, errors.New("blockHash can't be used together with fromBlock or toBlock")
+		}
+		blockID, err := proto.NewBlockIDFromBytes(*filter.BlockHash)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to parse blockID from blockHash %q", filter.BlockHash.String())
+		}
+		h, err := s.nodeRPCApp.State.BlockIDToHeight(blockID)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "failed to fetch height of block %q", blockID.String())
+		}
+		return h, h, nil
+	}
+	from, err := s.blockHeight(filter.FromBlock)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid fromBlock")
+	}
+	to, err := s.blockHeight(filter.ToBlock)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid toBlock")
+	}
+	if from > to {
+		return 0, 0, errors.Errorf("fromBlock %d is greater than toBlock %d", from, to)
+	}
+	if to-from >= maxLogsBlockRange {
+		return 0, 0, errors.Errorf("block range is too wide, maximum is %d blocks", maxLogsBlockRange)
+	}
+	return from, to, nil
+}
+
+// blockHeight converts the block number or tag to the height, absent block means the latest one.
+func (s RPCService) blockHeight(blockOrTag *string) (proto.Height, error) {
+	height, err := s.nodeRPCApp.State.Height()
+	if err != nil {
+		return 0, err
+	}
+	if blockOrTag == nil {
+		return height, nil
+	}
+	switch *blockOrTag {
+	case "earliest":
+		return 1, nil
+	case "latest", "pending":
+		return height, nil
+	default:
+		n, err := hexUintToUint64(*blockOrTag)
+		if err != nil {
+			return 0, errors.New("block parameter is not number nor supported tag")
+		}
+		if n == 0 || n > height {
+			return 0, errors.Errorf("block %d is out of range [1, %d]", n, height)
+		}
+		return n, nil
+	}
+}
+
+// assetTransferEvent is the transfer of Waves asset which can be represented as ERC20 Transfer event.
+type assetTransferEvent struct {
+	asset proto.AssetID
+	event ethabi.ERC20TransferEvent
+}
+
+// transferEvents returns transfers of assets made by the transaction applied at the given height.
+// Transfers of WAVES and transfers of failed transactions are omitted.
+func transferEvents(
+	st state.State, scheme proto.Scheme, tx proto.Transaction, height proto.Height,
+) ([]assetTransferEvent, error) {
+	switch t := tx.(type) {
+	case *proto.TransferWithSig:
+		return transferEvent(st, scheme, tx, t.AmountAsset, t.Recipient, t.Amount)
+	case *proto.TransferWithProofs:
+		return transferEvent(st, scheme, tx, t.AmountAsset, t.Recipient, t.Amount)
+	case *proto.MassTransferWithProofs:
+		var events []assetTransferEvent
+		for _, e := range t.Transfers {
+			ev, err := transferEvent(st, scheme, tx, t.Asset, e.Recipient, e.Amount)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, ev...)
+		}
+		return events, nil
+	case *proto.InvokeScriptWithProofs:
+		if ok, err := succeeded(st, scheme, tx); err != nil || !ok {
+			return nil, err
+		}
+		var events []assetTransferEvent
+		for _, p := range t.Payments {
+			ev, err := transferEvent(st, scheme, tx, p.Asset, t.ScriptRecipient, p.Amount)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, ev...)
+		}
+		return events, nil
+	case *proto.EthereumTransaction:
+		if ok, err := succeeded(st, scheme, tx); err != nil || !ok {
+			return nil, err
+		}
+		return ethereumTransferEvents(st, scheme, t, height)
+	default:
+		return nil, nil
+	}
+}
+
+// ethereumTransferEvents returns transfers of assets made by the ethereum transaction applied at the given height.
+// Transactions with data that can't be decoded produce no events.
+func ethereumTransferEvents(
+	st state.State, scheme proto.Scheme, tx *proto.EthereumTransaction, height proto.Height,
+) ([]assetTransferEvent, error) {
+	kind, err := proto.GuessEthereumTransactionKindType(tx.Data())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to guess ethereum transaction kind")
+	}
+	from, err := tx.From()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sender of ethereum transaction")
+	}
+	to := tx.To()
+	if to == nil {
+		return nil, nil
+	}
+	id, err := tx.GetID(scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ethereum transaction ID")
+	}
+	undecodable := func(err error) ([]assetTransferEvent, error) {
+		zap.S().Debugf("Transfers of ethereum transaction '%s' are skipped: %v", proto.B58Bytes(id), err)
+		return nil, nil
+	}
+	distribution, err := st.IsActiveAtHeight(int16(settings.BlockRewardDistribution), height)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case proto.EthereumTransferAssetsKindType:
+		decoded, err := ethabi.NewErc20MethodsMap().ParseCallDataRide(tx.Data(), distribution)
+		if err != nil {
+			return undecodable(errors.Wrap(err, "failed to parse ethereum transaction data"))
+		}
+		args, err := ethabi.GetERC20TransferArguments(decoded)
+		if err != nil {
+			return undecodable(errors.Wrap(err, "failed to get erc20 arguments from ethereum transaction data"))
+		}
+		event := ethabi.ERC20TransferEvent{From: from, To: args.Recipient, Amount: args.Amount}
+		return []assetTransferEvent{{asset: proto.AssetID(*to), event: event}}, nil
+	case proto.EthereumInvokeKindType:
+		dApp, err := to.ToWavesAddress(scheme)
+		if err != nil {
+			return nil, err
+		}
+		tree, err := scriptAtHeight(st, dApp, height)
+		if err != nil {
+			if state.IsNotFound(err) {
+				return undecodable(err)
+			}
+			return nil, err
+		}
+		methods, err := ethabi.NewMethodsMapFromRideDAppMeta(tree.Meta)
+		if err != nil {
+			return undecodable(errors.Wrapf(err, "failed to get methods of dApp %q", dApp.String()))
+		}
+		decoded, err := methods.ParseCallDataRide(tx.Data(), distribution)
+		if err != nil {
+			return undecodable(errors.Wrap(err, "failed to parse ethereum transaction data"))
+		}
+		var events []assetTransferEvent
+		for _, p := range decoded.Payments {
+			if !p.PresentAssetID {
+				continue
+			}
+			event := ethabi.ERC20TransferEvent{From: from, To: *to, Amount: p.Amount}
+			events = append(events, assetTransferEvent{asset: proto.AssetIDFromDigest(p.AssetID), event: event})
+		}
+		return events, nil
+	default:
+		return nil, nil
+	}
+}
+
+// scriptAtHeight returns the script of the dApp as of the given height. The history of scripts is available
+// within the rollback window only, the newest script is returned for the heights below it.
+func scriptAtHeight(st state.State, dApp proto.WavesAddress, height proto.Height) (*ast.Tree, error) {
+	rcp := proto.NewRecipientFromAddress(dApp)
+	tree, err := st.ScriptByAccountAtHeight(rcp, height)
+	if state.IsInvalidInput(err) {
+		tree, err = st.NewestScriptByAccount(rcp)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get script of dApp %q", dApp.String())
+	}
+	return tree, nil
+}
+
+func transferEvent(
+	st state.State, scheme proto.Scheme, tx proto.Transaction, asset proto.OptionalAsset, rcp proto.Recipient,
+	amount uint64,
+) ([]assetTransferEvent, error) {
+	if !asset.Present {
+		return nil, nil
+	}
+	sender, err := tx.GetSender(scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sender of transaction")
+	}
+	recipient, err := recipientAddress(st, rcp)
+	if err != nil {
+		return nil, err
+	}
+	event := ethabi.ERC20TransferEvent{From: sender.ID(), To: recipient.ID(), Amount: int64(amount)}
+	return []assetTransferEvent{{asset: proto.AssetIDFromDigest(asset.ID), event: event}}, nil
+}
+
+func recipientAddress(st state.State, rcp proto.Recipient) (proto.WavesAddress, error) {
+	if addr := rcp.Address(); addr != nil {
+		return *addr, nil
+	}
+	if alias := rcp.Alias(); alias != nil {
+		addr, err := st.AddrByAlias(*alias)
+		if err != nil {
+			return proto.WavesAddress{}, errors.Wrapf(err, "failed to resolve alias %q", alias.String())
+		}
+		return addr, nil
+	}
+	return proto.WavesAddress{}, errors.New("empty recipient")
+}
+
+func succeeded(st state.State, scheme proto.Scheme, tx proto.Transaction) (bool, error) {
+	id, err := tx.GetID(scheme)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get transaction ID")
+	}
+	_, status, err := st.TransactionByIDWithStatus(id)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get status of transaction %q", crypto.Digest(id).String())
+	}
+	return !status.IsNotSucceeded(), nil
+}
+
+func newTransferLogs(
+	events []assetTransferEvent, tx proto.Transaction, scheme proto.Scheme, blockHash string, height proto.Height,
+	txIndex uint64, logIndex int,
+) ([]Log, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	id, err := tx.GetID(scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction ID")
+	}
+	logs := make([]Log, len(events))
+	for i, e := range events {
+		digests := e.event.Topics()
+		topics := make([]proto.EthereumHash, len(digests))
+		for j, d := range digests {
+			topics[j] = proto.EthereumHash(d)
+		}
+		logs[i] = Log{
+			Address:          proto.EthereumAddress(e.asset),
+			Topics:           topics,
+			Data:             proto.EncodeToHexString(e.event.Data()),
+			BlockNumber:      uint64ToHexString(height),
+			TransactionHash:  proto.BytesToEthereumHash(id),
+			TransactionIndex: uint64ToHexString(txIndex),
+			BlockHash:        blockHash,
+			LogIndex:         uint64ToHexString(uint64(logIndex + i)),
+		}
+	}
+	return logs, nil
+}
@@ -0,0 +1,199 @@
+package metamask
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/proto/ethabi"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/meta"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+func TestGetLogsFilter_UnmarshalJSON(t *testing.T) {
+	const (
+		addr  = "0x1111111111111111111111111111111111111111"
+		topic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	)
+	var f getLogsFilter
+	err := json.Unmarshal([]byte(`{"fromBlock":"0x1","address":"`+addr+`","topics":["`+topic+`",null,["`+
+		topic+`","`+topic+`"]]}`), &f)
+	require.NoError(t, err)
+	require.NotNil(t, f.FromBlock)
+	assert.Equal(t, "0x1", *f.FromBlock)
+	assert.Nil(t, f.ToBlock)
+	require.Len(t, f.Address, 1)
+	assert.Equal(t, addr, f.Address[0].Hex())
+	require.Len(t, f.Topics, 3)
+	assert.Len(t, f.Topics[0], 1)
+	assert.Empty(t, f.Topics[1])
+	assert.Len(t, f.Topics[2], 2)
+
+	err = json.Unmarshal([]byte(`{"address":["`+addr+`","`+addr+`"]}`), &f)
+	require.NoError(t, err)
+	assert.Len(t, f.Address, 2)
+
+	err = json.Unmarshal([]byte(`{"topics":[1]}`), &f)
+	assert.Error(t, err)
+}
+
+func TestTopicsFilter_Match(t *testing.T) {
+	a := proto.EthereumHash{1}
+	b := proto.EthereumHash{2}
+	topics := []proto.EthereumHash{a, b}
+	for i, tc := range []struct {
+		filter topicsFilter
+		match  bool
+	}{
+		{nil, true},
+		{topicsFilter{{a}}, true},
+		{topicsFilter{nil, {b}}, true},
+		{topicsFilter{{b, a}, {a, b}}, true},
+		{topicsFilter{{b}}, false},
+		{topicsFilter{nil, nil, nil}, false},
+	} {
+		assert.Equal(t, tc.match, tc.filter.match(topics), "case %d", i)
+	}
+}
+
+func TestRPCService_Eth_GetLogs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	st := mock.NewMockState(ctrl)
+	scheme := proto.TestNetScheme
+
+	_, pk, err := crypto.GenerateKeyPair([]byte("eth get logs test seed"))
+	require.NoError(t, err)
+	sender := proto.MustAddressFromPublicKey(scheme, pk)
+	recipient := proto.MustAddressFromPublicKey(scheme, crypto.PublicKey{1})
+	alias := proto.NewAlias(scheme, "recipient")
+	assetID := crypto.MustDigestFromBase58("8LQW8f7P5d5PZM7GtZEBgaqRPGSzS3DfPuiXrURJ4AJS")
+	asset := *proto.NewOptionalAssetFromDigest(assetID)
+	waves := proto.NewOptionalAssetWaves()
+
+	wavesTransfer := proto.NewUnsignedTransferWithProofs(2, pk, waves, waves, 1, 100, 100000,
+		proto.NewRecipientFromAddress(recipient), nil)
+	assetTransfer := proto.NewUnsignedTransferWithProofs(2, pk, asset, waves, 1, 200, 100000,
+		proto.NewRecipientFromAddress(recipient), nil)
+	massTransfer := proto.NewUnsignedMassTransferWithProofs(1, pk, asset, []proto.MassTransferEntry{
+		{Recipient: proto.NewRecipientFromAlias(*alias), Amount: 300},
+		{Recipient: proto.NewRecipientFromAddress(sender), Amount: 400},
+	}, 200000, 1, nil)
+	block := &proto.Block{
+		BlockHeader:  proto.BlockHeader{ID: proto.NewBlockIDFromSignature(crypto.Signature{1})},
+		Transactions: proto.Transactions{wavesTransfer, assetTransfer, massTransfer},
+	}
+	st.EXPECT().Height().Return(proto.Height(2), nil).AnyTimes()
+	st.EXPECT().BlockByHeight(proto.Height(2)).Return(block, nil).AnyTimes()
+	st.EXPECT().AddrByAlias(*alias).Return(recipient, nil).AnyTimes()
+
+	s := NewRPCService(&services.Services{State: st, Scheme: scheme})
+	latest := "latest"
+	logs, err := s.Eth_GetLogs(getLogsFilter{FromBlock: &latest})
+	require.NoError(t, err)
+	require.Len(t, logs, 3)
+
+	assetAddr := proto.EthereumAddress(proto.AssetIDFromDigest(assetID))
+	event := ethabi.ERC20TransferEvent{From: sender.ID(), To: recipient.ID(), Amount: 300}
+	topics := make([]proto.EthereumHash, 0, 3)
+	for _, d := range event.Topics() {
+		topics = append(topics, proto.EthereumHash(d))
+	}
+	massTransferID, err := massTransfer.GetID(scheme)
+	require.NoError(t, err)
+	assert.Equal(t, Log{
+		Address:          assetAddr,
+		Topics:           topics,
+		Data:             proto.EncodeToHexString(event.Data()),
+		BlockNumber:      "0x2",
+		TransactionHash:  proto.BytesToEthereumHash(massTransferID),
+		TransactionIndex: "0x2",
+		BlockHash:        proto.EncodeToHexString(block.BlockID().Bytes()),
+		LogIndex:         "0x1",
+	}, logs[1])
+	assert.Equal(t, "0x0", logs[0].LogIndex)
+	assert.Equal(t, "0x1", logs[0].TransactionIndex)
+	assert.Equal(t, "0x2", logs[2].LogIndex)
+
+	// Filter by the recipient topic.
+	logs, err = s.Eth_GetLogs(getLogsFilter{
+		Address: addressFilter{assetAddr},
+		Topics:  topicsFilter{nil, nil, {topics[2]}},
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "0x0", logs[0].LogIndex)
+	assert.Equal(t, "0x1", logs[1].LogIndex)
+
+	// Unknown asset.
+	logs, err = s.Eth_GetLogs(getLogsFilter{Address: addressFilter{{1}}})
+	require.NoError(t, err)
+	assert.Empty(t, logs)
+
+	// Invalid ranges.
+	earliest, future := "earliest", "0x3"
+	_, err = s.Eth_GetLogs(getLogsFilter{FromBlock: &latest, ToBlock: &earliest})
+	assert.Error(t, err)
+	_, err = s.Eth_GetLogs(getLogsFilter{ToBlock: &future})
+	assert.Error(t, err)
+}
+
+func TestEthereumTransferEvents_ScriptAtHeight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	st := mock.NewMockState(ctrl)
+	scheme := proto.TestNetScheme
+	const height = proto.Height(5)
+
+	senderPK, err := proto.NewEthereumPublicKeyFromHexString("c4f926702fee2456ac5f3d91c9b7aa578ff191d0792fa80b6e65200f2485d9810a89c1bb5830e6618119fb3f2036db47fac027f7883108cbc7b2953539b9cb53") //nolint:lll
+	require.NoError(t, err)
+	dApp := proto.EthereumAddress{1, 2, 3}
+	dAppAddr, err := dApp.ToWavesAddress(scheme)
+	require.NoError(t, err)
+	rcp := proto.NewRecipientFromAddress(dAppAddr)
+
+	fn := meta.Function{Name: "call", Arguments: []meta.Type{}}
+	sig, err := ethabi.NewSignatureFromRideFunctionMeta(fn, true)
+	require.NoError(t, err)
+	assetID := crypto.Digest{1}
+	selector := sig.Selector()
+	data := selector[:]
+	data = append(data, ethabi.Int(0x20).EncodeToABI()...) // Offset of payments.
+	data = append(data, ethabi.Int(1).EncodeToABI()...)    // Number of payments.
+	data = append(data, assetID[:]...)
+	data = append(data, ethabi.Int(100).EncodeToABI()...)
+	txData := &proto.EthereumLegacyTx{
+		Value: big.NewInt(0), To: &dApp, Data: data, GasPrice: big.NewInt(1), Gas: 500000, V: big.NewInt(1),
+	}
+	tx := proto.NewEthereumTransaction(txData, nil, &crypto.Digest{}, &senderPK, 0)
+
+	st.EXPECT().IsActiveAtHeight(int16(settings.BlockRewardDistribution), height).Return(true, nil).AnyTimes()
+
+	// The function is removed from the newest script, but the script at the height of transaction is used.
+	st.EXPECT().ScriptByAccountAtHeight(rcp, height).Return(&ast.Tree{Meta: meta.DApp{
+		Version: 2, Functions: []meta.Function{fn},
+	}}, nil)
+	events, err := ethereumTransferEvents(st, scheme, &tx, height)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, proto.AssetIDFromDigest(assetID), events[0].asset)
+	assert.Equal(t, int64(100), events[0].event.Amount)
+	assert.Equal(t, [ethabi.EthereumAddressSize]byte(dApp), events[0].event.To)
+
+	// The newest script is used below the rollback window, the transaction that can't be decoded is skipped.
+	st.EXPECT().ScriptByAccountAtHeight(rcp, height).
+		Return(nil, state.NewStateError(state.InvalidInputError, errors.New("height is outside of history")))
+	st.EXPECT().NewestScriptByAccount(rcp).Return(&ast.Tree{Meta: meta.DApp{Version: 2}}, nil)
+	events, err = ethereumTransferEvents(st, scheme, &tx, height)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
@@ -13,9 +13,10 @@ import (
 )
 
 var RPC = struct {
-	RPCService struct{ Eth_BlockNumber, Net_Version, Eth_ChainId, Eth_GetBalance, Eth_GetBlockByNumber, Eth_GetBlockByHash, Eth_GasPrice, Eth_EstimateGas, Eth_Call, Eth_GetCode, Eth_GetTransactionCount, Eth_SendRawTransaction, Eth_GetTransactionReceipt, Eth_GetTransactionByHash string }
+	RPCService struct{ Eth_GetLogs, Eth_BlockNumber, Net_Version, Eth_ChainId, Eth_GetBalance, Eth_GetBlockByNumber, Eth_GetBlockByHash, Eth_GasPrice, Eth_EstimateGas, Eth_Call, Eth_GetCode, Eth_GetTransactionCount, Eth_SendRawTransaction, Eth_GetTransactionReceipt, Eth_GetTransactionByHash string }
 }{
-	RPCService: struct{ Eth_BlockNumber, Net_Version, Eth_ChainId, Eth_GetBalance, Eth_GetBlockByNumber, Eth_GetBlockByHash, Eth_GasPrice, Eth_EstimateGas, Eth_Call, Eth_GetCode, Eth_GetTransactionCount, Eth_SendRawTransaction, Eth_GetTransactionReceipt, Eth_GetTransactionByHash string }{
+	RPCService: struct{ Eth_GetLogs, Eth_BlockNumber, Net_Version, Eth_ChainId, Eth_GetBalance, Eth_GetBlockByNumber, Eth_GetBlockByHash, Eth_GasPrice, Eth_EstimateGas, Eth_Call, Eth_GetCode, Eth_GetTransactionCount, Eth_SendRawTransaction, Eth_GetTransactionReceipt, Eth_GetTransactionByHash string }{
+		Eth_GetLogs:               "eth_getlogs",
 		Eth_BlockNumber:           "eth_blocknumber",
 		Net_Version:               "net_version",
 		Eth_ChainId:               "eth_chainid",
@@ -37,6 +38,123 @@ func (RPCService) SMD() smd.ServiceInfo {
 	return smd.ServiceInfo{
 		Description: ``,
 		Methods: map[string]smd.Service{
+			"Eth_GetLogs": {
+				Description: `Eth_GetLogs returns ERC20 Transfer event logs of asset transfers, mass transfers and invoke payments
+matching the filter.
+- filter: fromBlock and toBlock as QUANTITY|TAG or blockHash, address of asset as a single address or
+an array, topics as an array of topics or arrays of alternative topics`,
+				Parameters: []smd.JSONSchema{
+					{
+						Name:        "filter",
+						Optional:    false,
+						Description: ``,
+						Type:        smd.Object,
+						Properties: map[string]smd.Property{
+							"fromBlock": {
+								Description: ``,
+								Type:        smd.String,
+							},
+							"toBlock": {
+								Description: ``,
+								Type:        smd.String,
+							},
+							"address": {
+								Description: ``,
+								Ref:         "#/definitions/addressFilter",
+								Type:        smd.Object,
+							},
+							"topics": {
+								Description: ``,
+								Ref:         "#/definitions/topicsFilter",
+								Type:        smd.Object,
+							},
+							"blockHash": {
+								Description: ``,
+								Ref:         "#/definitions/proto.HexBytes",
+								Type:        smd.Object,
+							},
+						},
+						Definitions: map[string]smd.Definition{
+							"addressFilter": {
+								Type:       "object",
+								Properties: map[string]smd.Property{},
+							},
+							"topicsFilter": {
+								Type:       "object",
+								Properties: map[string]smd.Property{},
+							},
+							"proto.HexBytes": {
+								Type:       "object",
+								Properties: map[string]smd.Property{},
+							},
+						},
+					},
+				},
+				Returns: smd.JSONSchema{
+					Description: ``,
+					Optional:    false,
+					Type:        smd.Array,
+					Items: map[string]string{
+						"$ref": "#/definitions/Log",
+					},
+					Definitions: map[string]smd.Definition{
+						"Log": {
+							Type: "object",
+							Properties: map[string]smd.Property{
+								"address": {
+									Description: ``,
+									Ref:         "#/definitions/proto.EthereumAddress",
+									Type:        smd.Object,
+								},
+								"topics": {
+									Description: ``,
+									Type:        smd.Array,
+									Items: map[string]string{
+										"$ref": "#/definitions/proto.EthereumHash",
+									},
+								},
+								"data": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"blockNumber": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"transactionHash": {
+									Description: ``,
+									Ref:         "#/definitions/proto.EthereumHash",
+									Type:        smd.Object,
+								},
+								"transactionIndex": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"blockHash": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"logIndex": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"removed": {
+									Description: ``,
+									Type:        smd.Boolean,
+								},
+							},
+						},
+						"proto.EthereumAddress": {
+							Type:       "object",
+							Properties: map[string]smd.Property{},
+						},
+						"proto.EthereumHash": {
+							Type:       "object",
+							Properties: map[string]smd.Property{},
+						},
+					},
+				},
+			},
 			"Eth_BlockNumber": {
 				Description: `Eth_BlockNumber returns the number of most recent block`,
 				Parameters:  []smd.JSONSchema{},
@@ -197,7 +315,7 @@ func (RPCService) SMD() smd.ServiceInfo {
 				},
 			},
 			"Eth_Call": {
-				Description: `Eth_Call returns information about assets.
+				Description: `Eth_Call returns information about assets or the result of read-only call of dApp callable function.
 - params: the tx call object
 - block: QUANTITY|TAG - integer block number, or the string "latest", "earliest" or "pending"`,
 				Parameters: []smd.JSONSchema{
@@ -364,7 +482,7 @@ func (RPCService) SMD() smd.ServiceInfo {
 							Description: ``,
 							Type:        smd.Array,
 							Items: map[string]string{
-								"type": smd.String,
+								"$ref": "#/definitions/Log",
 							},
 						},
 						"logsBloom": {
@@ -386,6 +504,52 @@ func (RPCService) SMD() smd.ServiceInfo {
 							Type:       "object",
 							Properties: map[string]smd.Property{},
 						},
+						"Log": {
+							Type: "object",
+							Properties: map[string]smd.Property{
+								"address": {
+									Description: ``,
+									Ref:         "#/definitions/proto.EthereumAddress",
+									Type:        smd.Object,
+								},
+								"topics": {
+									Description: ``,
+									Type:        smd.Array,
+									Items: map[string]string{
+										"$ref": "#/definitions/proto.EthereumHash",
+									},
+								},
+								"data": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"blockNumber": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"transactionHash": {
+									Description: ``,
+									Ref:         "#/definitions/proto.EthereumHash",
+									Type:        smd.Object,
+								},
+								"transactionIndex": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"blockHash": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"logIndex": {
+									Description: ``,
+									Type:        smd.String,
+								},
+								"removed": {
+									Description: ``,
+									Type:        smd.Boolean,
+								},
+							},
+						},
 					},
 				},
 			},
@@ -500,6 +664,25 @@ func (s RPCService) Invoke(ctx context.Context, method string, params json.RawMe
 	var err error
 
 	switch method {
+	case RPC.RPCService.Eth_GetLogs:
+		var args = struct {
+			Filter getLogsFilter `json:"filter"`
+		}{}
+
+		if zenrpc.IsArray(params) {
+			if params, err = zenrpc.ConvertToObject([]string{"filter"}, params); err != nil {
+				return zenrpc.NewResponseError(nil, zenrpc.InvalidParams, "", err.Error())
+			}
+		}
+
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &args); err != nil {
+				return zenrpc.NewResponseError(nil, zenrpc.InvalidParams, "", err.Error())
+			}
+		}
+
+		resp.Set(s.Eth_GetLogs(args.Filter))
+
 	case RPC.RPCService.Eth_BlockNumber:
 		resp.Set(s.Eth_BlockNumber())
 
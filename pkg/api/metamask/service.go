@@ -142,6 +142,7 @@ func (s RPCService) Eth_GasPrice() string {
 }
 
 type estimateGasRequest struct {
+	From  *proto.EthereumAddress `json:"from"`
 	To    *proto.EthereumAddress `json:"to"`
 	Value *string                `json:"value"`
 	Data  *string                `json:"data"`
@@ -192,19 +193,28 @@ func (s RPCService) Eth_EstimateGas(req estimateGasRequest) (string, error) {
 		}
 		return uint64ToHexString(uint64(fee)), nil
 	case proto.EthereumInvokeKindType:
-		return uint64ToHexString(proto.MinFeeInvokeScript), nil
+		fee, err := estimateDAppCallFee(s.nodeRPCApp.State, s.nodeRPCApp.Scheme, req.From, *req.To, data)
+		if err != nil {
+			zap.S().Debugf("Eth_EstimateGas: failed to evaluate dApp call: %v", err)
+			return "", errors.Errorf("failed to estimate dApp call, %v", err)
+		}
+		return uint64ToHexString(fee), nil
 	default:
 		return "", errors.Errorf("unexpected ethereum tx kind")
 	}
 }
 
 type ethCallParams struct {
-	To   proto.EthereumAddress `json:"to"`
-	Data string                `json:"data"`
+	From *proto.EthereumAddress `json:"from"`
+	To   proto.EthereumAddress  `json:"to"`
+	Data string                 `json:"data"`
 }
 
 func (c ethCallParams) String() string {
-	return fmt.Sprintf("Eth_callParams(to=%s,data=%s)", c.To, c.Data)
+	if c.From == nil {
+		return fmt.Sprintf("Eth_callParams(to=%s,data=%s)", c.To, c.Data)
+	}
+	return fmt.Sprintf("Eth_callParams(from=%s,to=%s,data=%s)", c.From, c.To, c.Data)
 }
 
 var (
@@ -214,7 +224,7 @@ var (
 	erc20SupportsInterfaceSelector = ethabi.Signature("supportsInterface(bytes4)").Selector() // "0x01ffc9a7"
 )
 
-// Eth_Call returns information about assets.
+// Eth_Call returns information about assets or the result of read-only call of dApp callable function.
 //   - params: the tx call object
 //   - block: QUANTITY|TAG - integer block number, or the string "latest", "earliest" or "pending"
 func (s RPCService) Eth_Call(params ethCallParams, blockOrTag string) (string, error) {
@@ -291,7 +301,11 @@ func ethCall(state state.State, scheme proto.Scheme, params ethCallParams) ([]by
 	case erc20SupportsInterfaceSelector:
 		return ethabi.Bool(false).EncodeToABI(), nil
 	default:
-		return nil, nil // according to the scala node implementation ("0x" in the result will be returned)
+		abiVal, err := callDApp(state, scheme, params.From, params.To, callData)
+		if errors.Is(err, errNotDApp) {
+			return nil, nil // according to the scala node implementation ("0x" in the result will be returned)
+		}
+		return abiVal, err
 	}
 }
 
@@ -420,7 +434,7 @@ type GetTransactionReceiptResponse struct {
 	CumulativeGasUsed string                 `json:"cumulativeGasUsed"`
 	GasUsed           string                 `json:"gasUsed"`
 	ContractAddress   *proto.EthereumAddress `json:"contractAddress"`
-	Logs              []string               `json:"logs"`
+	Logs              []Log                  `json:"logs"`
 	LogsBloom         proto.EthereumHash     `json:"logsBloom"`
 	Status            string                 `json:"status"`
 }
@@ -469,18 +483,36 @@ func (s RPCService) Eth_GetTransactionReceipt(ethTxID proto.EthereumHash) (*GetT
 		txStatus = "0x0"
 	}
 	gasLimit := uint64ToHexString(tx.GetFee())
+	blockHash := proto.EncodeToHexString(lastBlockHeader.ID.Bytes()) // should be always 32bytes
+
+	logs := make([]Log, 0)
+	if !status.IsNotSucceeded() {
+		events, err := ethereumTransferEvents(s.nodeRPCApp.State, s.nodeRPCApp.Scheme, ethTx, blockHeight)
+		if err != nil {
+			zap.S().Errorf(
+				"Eth_GetTransactionReceipt: failed to get transfers of tx with ID=%q or ethID=%q: %v",
+				txID, ethTxID, err,
+			)
+			return nil, errors.New("failed to get logs of transaction")
+		}
+		txLogs, err := newTransferLogs(events, ethTx, s.nodeRPCApp.Scheme, blockHash, blockHeight, 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, txLogs...)
+	}
 
 	resp := &GetTransactionReceiptResponse{
 		TransactionHash:   ethTxID,
-		TransactionIndex:  "0x01",                                              // according to the scala node implementation
-		BlockHash:         proto.EncodeToHexString(lastBlockHeader.ID.Bytes()), // should be always 32bytes
+		TransactionIndex:  "0x01", // according to the scala node implementation
+		BlockHash:         blockHash,
 		BlockNumber:       uint64ToHexString(blockHeight),
 		From:              from,
 		To:                to,
 		CumulativeGasUsed: gasLimit,
 		GasUsed:           gasLimit,
 		ContractAddress:   nil,
-		Logs:              []string{},
+		Logs:              logs,
 		LogsBloom:         proto.EthereumHash{},
 		Status:            txStatus,
 	}
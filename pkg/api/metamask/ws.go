@@ -0,0 +1,329 @@
+package metamask
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/semrush/zenrpc/v2"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/node/notifications"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const (
+	wsEventsBufferSize    = 1024
+	wsWriteTimeout        = 10 * time.Second
+	wsMaxSubscriptions    = 100
+	wsSubscriptionIDBytes = 16
+
+	subscribeMethod    = "eth_subscribe"
+	unsubscribeMethod  = "eth_unsubscribe"
+	subscriptionMethod = "eth_subscription"
+
+	newHeadsSubscription               = "newHeads"
+	logsSubscription                   = "logs"
+	newPendingTransactionsSubscription = "newPendingTransactions"
+)
+
+type rpcServer interface {
+	Do(ctx context.Context, req []byte) ([]byte, error)
+}
+
+// WSHandler serves the RPC service over WebSocket. Besides the methods of RPCService it supports
+// eth_subscribe and eth_unsubscribe for newHeads, logs and newPendingTransactions subscriptions.
+type WSHandler struct {
+	rpc      rpcServer
+	service  RPCService
+	hub      *notifications.Hub
+	upgrader websocket.Upgrader
+}
+
+// NewWSHandler creates the WebSocket handler, subscriptions are not supported if the hub is nil.
+func NewWSHandler(rpc zenrpc.Server, service RPCService, hub *notifications.Hub) *WSHandler {
+	return &WSHandler{
+		rpc:      rpc,
+		service:  service,
+		hub:      hub,
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+	}
+}
+
+func (h *WSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		zap.S().Debugf("MetaMaskRPC: failed to upgrade connection from '%s': %v", r.RemoteAddr, err)
+		return
+	}
+	c := &wsConnection{handler: h, conn: conn, subs: make(map[string]wsSubscription)}
+	defer c.close()
+	c.serve(r.Context())
+}
+
+// Header is the block header in the format of newHeads subscription.
+type Header struct {
+	Number        string                `json:"number"`
+	Hash          string                `json:"hash"`
+	ParentHash    string                `json:"parentHash"`
+	Timestamp     string                `json:"timestamp"`
+	Miner         proto.EthereumAddress `json:"miner"`
+	Nonce         string                `json:"nonce"`
+	Difficulty    string                `json:"difficulty"`
+	GasLimit      string                `json:"gasLimit"`
+	GasUsed       string                `json:"gasUsed"`
+	BaseFeePerGas string                `json:"baseFeePerGas"`
+	ExtraData     string                `json:"extraData"`
+}
+
+func newHeader(scheme proto.Scheme, height proto.Height, block *proto.Block) (Header, error) {
+	generator, err := proto.NewAddressFromPublicKey(scheme, block.GeneratorPublicKey)
+	if err != nil {
+		return Header{}, errors.Wrap(err, "failed to get block generator address")
+	}
+	return Header{
+		Number:        uint64ToHexString(height),
+		Hash:          proto.EncodeToHexString(block.BlockID().Bytes()),
+		ParentHash:    proto.EncodeToHexString(block.Parent.Bytes()),
+		Timestamp:     uint64ToHexString(block.Timestamp / 1000),
+		Miner:         generator.EthereumAddress(),
+		Nonce:         "0x0000000000000000",
+		Difficulty:    "0x0",
+		GasLimit:      "0x0",
+		GasUsed:       "0x0",
+		BaseFeePerGas: "0x0",
+		ExtraData:     "0x",
+	}, nil
+}
+
+type wsSubscription struct {
+	kind   string
+	filter getLogsFilter
+}
+
+type subscriptionResult struct {
+	Subscription string `json:"subscription"`
+	Result       any    `json:"result"`
+}
+
+type subscriptionNotification struct {
+	Version string             `json:"jsonrpc"`
+	Method  string             `json:"method"`
+	Params  subscriptionResult `json:"params"`
+}
+
+type wsConnection struct {
+	handler *WSHandler
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	subs   map[string]wsSubscription
+	events *notifications.Subscription // Subscription to the hub, nil until the first eth_subscribe.
+}
+
+func (c *wsConnection) serve(ctx context.Context) {
+	for {
+		mt, message, err := c.conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				zap.S().Debugf("MetaMaskRPC: failed to read WebSocket message: %v", err)
+			}
+			return
+		}
+		resp, err := c.handle(ctx, message)
+		if err != nil {
+			zap.S().Debugf("MetaMaskRPC: failed to handle WebSocket message: %v", err)
+			return
+		}
+		if err := c.write(mt, resp); err != nil {
+			zap.S().Debugf("MetaMaskRPC: failed to write WebSocket message: %v", err)
+			return
+		}
+	}
+}
+
+func (c *wsConnection) handle(ctx context.Context, message []byte) ([]byte, error) {
+	var req zenrpc.Request
+	if bytes.HasPrefix(bytes.TrimSpace(message), []byte("[")) || json.Unmarshal(message, &req) != nil {
+		return c.handler.rpc.Do(ctx, message)
+	}
+	var resp zenrpc.Response
+	switch strings.ToLower(req.Method) {
+	case subscribeMethod:
+		resp.Set(c.subscribe(req.Params))
+	case unsubscribeMethod:
+		resp.Set(c.unsubscribe(req.Params))
+	default:
+		return c.handler.rpc.Do(ctx, message)
+	}
+	resp.ID = req.ID
+	return json.Marshal(resp)
+}
+
+func (c *wsConnection) subscribe(params json.RawMessage) (string, error) {
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 || len(args) > 2 {
+		return "", zenrpc.NewStringError(zenrpc.InvalidParams, "expected subscription type and optional filter")
+	}
+	var s wsSubscription
+	if err := json.Unmarshal(args[0], &s.kind); err != nil {
+		return "", zenrpc.NewError(zenrpc.InvalidParams, err)
+	}
+	switch s.kind {
+	case newHeadsSubscription, newPendingTransactionsSubscription:
+	case logsSubscription:
+		if len(args) == 2 {
+			if err := json.Unmarshal(args[1], &s.filter); err != nil {
+				return "", zenrpc.NewError(zenrpc.InvalidParams, err)
+			}
+		}
+	default:
+		return "", zenrpc.NewStringError(zenrpc.InvalidParams, "unsupported subscription type "+s.kind)
+	}
+	if c.handler.hub == nil {
+		return "", zenrpc.NewStringError(zenrpc.ServerError, "subscriptions are not supported")
+	}
+	var b [wsSubscriptionIDBytes]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	id := proto.EncodeToHexString(b[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.subs) >= wsMaxSubscriptions {
+		return "", zenrpc.NewStringError(zenrpc.ServerError, "too many subscriptions")
+	}
+	if c.events == nil {
+		c.events = c.handler.hub.Subscribe(wsEventsBufferSize)
+		go c.pump(c.events)
+	}
+	c.subs[id] = s
+	return id, nil
+}
+
+func (c *wsConnection) unsubscribe(params json.RawMessage) (bool, error) {
+	var ids []string
+	if err := json.Unmarshal(params, &ids); err != nil || len(ids) != 1 {
+		return false, zenrpc.NewStringError(zenrpc.InvalidParams, "expected subscription ID")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.subs[ids[0]]
+	delete(c.subs, ids[0])
+	return ok, nil
+}
+
+// pump delivers events to the subscriptions of the connection. The connection is closed if it can't keep up
+// with the events.
+func (c *wsConnection) pump(events *notifications.Subscription) {
+	for e := range events.Events() {
+		if err := c.dispatch(e); err != nil {
+			zap.S().Debugf("MetaMaskRPC: failed to send WebSocket notification: %v", err)
+			_ = c.conn.Close()
+			return
+		}
+	}
+	if err := events.Err(); err != nil {
+		zap.S().Debugf("MetaMaskRPC: WebSocket subscription terminated: %v", err)
+		c.writeMu.Lock()
+		_ = c.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error()), time.Now().Add(wsWriteTimeout))
+		c.writeMu.Unlock()
+		_ = c.conn.Close()
+	}
+}
+
+func (c *wsConnection) dispatch(e notifications.Event) error {
+	c.mu.Lock()
+	subs := make(map[string]wsSubscription, len(c.subs))
+	for id, s := range c.subs {
+		subs[id] = s
+	}
+	c.mu.Unlock()
+
+	st, scheme := c.handler.service.nodeRPCApp.State, c.handler.service.nodeRPCApp.Scheme
+	var (
+		header *Header
+		logs   []Log
+	)
+	for id, s := range subs {
+		var results []any
+		switch ev := e.(type) {
+		case notifications.BlockEvent:
+			switch s.kind {
+			case newHeadsSubscription:
+				if header == nil {
+					h, err := newHeader(scheme, ev.Height, ev.Block)
+					if err != nil {
+						return err
+					}
+					header = &h
+				}
+				results = append(results, header)
+			case logsSubscription:
+				if logs == nil {
+					l, err := newBlockLogs(st, scheme, ev.Block, ev.Height, ev.First)
+					if err != nil {
+						return err
+					}
+					logs = append(make([]Log, 0, len(l)), l...)
+				}
+				for _, l := range logs {
+					if s.filter.match(l) {
+						results = append(results, l)
+					}
+				}
+			}
+		case notifications.UtxEvent:
+			if s.kind == newPendingTransactionsSubscription {
+				txID, err := ev.Transaction.GetID(scheme)
+				if err != nil {
+					return err
+				}
+				results = append(results, proto.BytesToEthereumHash(txID))
+			}
+		}
+		for _, r := range results {
+			n := subscriptionNotification{
+				Version: zenrpc.Version,
+				Method:  subscriptionMethod,
+				Params:  subscriptionResult{Subscription: id, Result: r},
+			}
+			msg, err := json.Marshal(n)
+			if err != nil {
+				return err
+			}
+			if err := c.write(websocket.TextMessage, msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *wsConnection) write(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(messageType, data)
+}
+
+func (c *wsConnection) close() {
+	c.mu.Lock()
+	if c.events != nil {
+		c.handler.hub.Unsubscribe(c.events)
+	}
+	c.mu.Unlock()
+	_ = c.conn.Close()
+}
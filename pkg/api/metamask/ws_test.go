@@ -0,0 +1,130 @@
+package metamask
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/semrush/zenrpc/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/node/notifications"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/types"
+)
+
+type stubApplier struct {
+	notifications.BlocksApplier
+}
+
+func (stubApplier) Apply(state.State, []*proto.Block) (proto.Height, error) {
+	return 0, nil
+}
+
+type stubUtxPool struct {
+	types.UtxPool
+}
+
+func (stubUtxPool) Add(proto.Transaction) error {
+	return nil
+}
+
+func (stubUtxPool) Count() int {
+	return 0
+}
+
+func TestWSHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	st := mock.NewMockState(ctrl)
+	scheme := proto.TestNetScheme
+
+	genesis := &proto.Block{BlockHeader: proto.BlockHeader{BlockSignature: crypto.Signature{1}}}
+	_, pk, err := crypto.GenerateKeyPair([]byte("ws handler test seed"))
+	require.NoError(t, err)
+	block := &proto.Block{BlockHeader: proto.BlockHeader{
+		BlockSignature:     crypto.Signature{2},
+		Parent:             genesis.BlockID(),
+		Timestamp:          1700000000000,
+		GeneratorPublicKey: pk,
+	}}
+	blocks := []*proto.Block{genesis}
+	st.EXPECT().Height().DoAndReturn(func() (proto.Height, error) {
+		return proto.Height(len(blocks)), nil
+	}).AnyTimes()
+	st.EXPECT().TopBlock().DoAndReturn(func() *proto.Block { return blocks[len(blocks)-1] }).AnyTimes()
+	st.EXPECT().BlockIDToHeight(genesis.BlockID()).Return(proto.Height(1), nil).AnyTimes()
+
+	hub := notifications.NewHub()
+	applier, err := notifications.NewApplier(stubApplier{}, hub, st)
+	require.NoError(t, err)
+	utx := notifications.NewUtxPool(stubUtxPool{}, hub, scheme)
+
+	service := NewRPCService(&services.Services{State: st, Scheme: scheme})
+	rpc := zenrpc.NewServer(zenrpc.Options{})
+	rpc.Register("", service)
+	server := httptest.NewServer(NewWSHandler(rpc, service, hub))
+	defer server.Close()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	call := func(req string) map[string]json.RawMessage {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(req)))
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		_, msg, rErr := conn.ReadMessage()
+		require.NoError(t, rErr)
+		var out map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(msg, &out))
+		return out
+	}
+
+	resp := call(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)
+	assert.JSONEq(t, `"0x54"`, string(resp["result"]))
+
+	resp = call(`{"jsonrpc":"2.0","id":2,"method":"eth_subscribe","params":["unknown"]}`)
+	assert.Contains(t, string(resp["error"]), "unsupported subscription type")
+
+	resp = call(`{"jsonrpc":"2.0","id":3,"method":"eth_subscribe","params":["newHeads"]}`)
+	assert.JSONEq(t, `3`, string(resp["id"]))
+	var heads string
+	require.NoError(t, json.Unmarshal(resp["result"], &heads))
+	resp = call(`{"jsonrpc":"2.0","id":4,"method":"eth_subscribe","params":["newPendingTransactions"]}`)
+	var pending string
+	require.NoError(t, json.Unmarshal(resp["result"], &pending))
+
+	blocks = append(blocks, block)
+	_, err = applier.Apply(st, []*proto.Block{block})
+	require.NoError(t, err)
+	var n subscriptionNotification
+	require.NoError(t, conn.ReadJSON(&n))
+	assert.Equal(t, subscriptionMethod, n.Method)
+	assert.Equal(t, heads, n.Params.Subscription)
+	header := n.Params.Result.(map[string]any)
+	assert.Equal(t, "0x2", header["number"])
+	assert.Equal(t, "0x6553f100", header["timestamp"])
+	assert.Equal(t, proto.EncodeToHexString(block.BlockID().Bytes()), header["hash"])
+	assert.Equal(t, proto.EncodeToHexString(genesis.BlockID().Bytes()), header["parentHash"])
+
+	tx := proto.NewUnsignedTransferWithProofs(2, pk, proto.NewOptionalAssetWaves(), proto.NewOptionalAssetWaves(),
+		1, 1, 100000, proto.NewRecipientFromAddress(proto.MustAddressFromPublicKey(scheme, pk)), nil)
+	require.NoError(t, utx.Add(tx))
+	id, err := tx.GetID(scheme)
+	require.NoError(t, err)
+	require.NoError(t, conn.ReadJSON(&n))
+	assert.Equal(t, pending, n.Params.Subscription)
+	assert.Equal(t, proto.BytesToEthereumHash(id).String(), n.Params.Result)
+
+	resp = call(`{"jsonrpc":"2.0","id":5,"method":"eth_unsubscribe","params":["` + heads + `"]}`)
+	assert.JSONEq(t, `true`, string(resp["result"]))
+	resp = call(`{"jsonrpc":"2.0","id":6,"method":"eth_unsubscribe","params":["` + heads + `"]}`)
+	assert.JSONEq(t, `false`, string(resp["result"]))
+}
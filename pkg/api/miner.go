@@ -25,7 +25,7 @@ func (a *App) Miner() MinerInfo {
 	next := make([]Next, 0, len(e))
 	for _, row := range e {
 		next = append(next, Next{
-			PublicKey: row.KeyPair.Public,
+			PublicKey: row.PublicKey,
 			Time:      time.Unix(int64(row.Timestamp/1000), 0).Add(time.Duration(row.Timestamp%1000) * time.Millisecond),
 		})
 	}
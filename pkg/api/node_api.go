@@ -19,6 +19,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/errs"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
 	"github.com/wavesplatform/gowaves/pkg/state"
 	"github.com/wavesplatform/gowaves/pkg/util/limit_listener"
 )
@@ -652,12 +653,39 @@ func (a *NodeApi) BlocksGenerators(w http.ResponseWriter, _ *http.Request) error
 }
 
 func (a *NodeApi) poolTransactions(w http.ResponseWriter, _ *http.Request) error {
+	type poolTransaction struct {
+		ID         crypto.Digest `json:"id"`
+		Sender     string        `json:"sender"`
+		DApp       string        `json:"dApp,omitempty"`
+		Size       int           `json:"size"`
+		Fee        uint64        `json:"fee"`
+		FeeInWaves uint64        `json:"feeInWaves"`
+		Complexity uint64        `json:"complexity"`
+	}
+	type eviction struct {
+		ID        crypto.Digest `json:"id"`
+		Reason    string        `json:"reason"`
+		Timestamp int64         `json:"timestamp"`
+	}
 	type poolTransactions struct {
-		Count int `json:"count"`
+		Count        int               `json:"count"`
+		Size         uint64            `json:"size"`
+		Transactions []poolTransaction `json:"transactions"`
+		Evictions    []eviction        `json:"evictions"`
 	}
 
+	details := a.app.PoolDetails()
 	rs := poolTransactions{
-		Count: a.app.PoolTransactions(),
+		Count:        len(details.Transactions),
+		Size:         details.Size,
+		Transactions: make([]poolTransaction, len(details.Transactions)),
+		Evictions:    make([]eviction, len(details.Evictions)),
+	}
+	for i, tx := range details.Transactions {
+		rs.Transactions[i] = poolTransaction(tx)
+	}
+	for i, e := range details.Evictions {
+		rs.Evictions[i] = eviction{ID: e.ID, Reason: e.Reason, Timestamp: e.Timestamp.UnixMilli()}
 	}
 	if err := trySendJson(w, rs); err != nil {
 		return errors.Wrap(err, "poolTransactions")
@@ -693,7 +721,10 @@ func (a *NodeApi) RollbackToHeight(w http.ResponseWriter, r *http.Request) error
 	if err := tryParseJson(r.Body, rollbackReq); err != nil {
 		return errors.Wrap(err, "failed to parse RollbackToHeight body as JSON")
 	}
-	err := a.state.RollbackToHeight(rollbackReq.Height)
+	id, err := a.state.HeightToBlockID(rollbackReq.Height)
+	if err == nil {
+		err = a.app.rollbackTo(id)
+	}
 	if err != nil {
 		origErr := errors.Cause(err)
 		if state.IsNotFound(origErr) {
@@ -723,7 +754,7 @@ func (a *NodeApi) RollbackTo(w http.ResponseWriter, r *http.Request) error {
 	if err != nil {
 		return err
 	}
-	if err = a.state.RollbackTo(id); err != nil {
+	if err = a.app.rollbackTo(id); err != nil {
 		return errors.Wrapf(err, "failed to rollback to block %s", id)
 	}
 	if err = trySendJson(w, rollbackResponse{id}); err != nil {
@@ -871,6 +902,51 @@ func (a *NodeApi) snapshotStateHash(w http.ResponseWriter, r *http.Request) erro
 	return nil
 }
 
+// invokeTrace replays the invoke transaction against the current state and returns the trace of script evaluation.
+func (a *NodeApi) invokeTrace(w http.ResponseWriter, r *http.Request) error {
+	type out struct {
+		ID           crypto.Digest `json:"id"`
+		Trace        ride.Trace    `json:"trace"`
+		Complexity   int           `json:"complexity"`
+		Error        string        `json:"error,omitempty"`
+		CallStack    []string      `json:"callStack,omitempty"`
+		StateChanges stateChanges  `json:"stateChanges"`
+	}
+	s := chi.URLParam(r, "id")
+	id, err := crypto.NewDigestFromBase58(s)
+	if err != nil {
+		if invalidRune, isInvalid := findFirstInvalidRuneInBase58String(s); isInvalid {
+			return transactionIDAtInvalidCharErr(invalidRune, s)
+		}
+		return transactionIDAtInvalidLenErr(s)
+	}
+	res, err := a.state.TraceInvoke(id.Bytes(), ride.DefaultMaxTraceSteps)
+	if err != nil {
+		origErr := errors.Cause(err)
+		if state.IsNotFound(origErr) {
+			return apiErrs.TransactionDoesNotExist
+		}
+		if state.IsInvalidInput(origErr) {
+			return apiErrs.NewCustomValidationError(origErr.Error())
+		}
+		return errors.Wrapf(err, "failed to trace invoke transaction %q", s)
+	}
+	o := out{
+		ID:           id,
+		Trace:        res.Trace,
+		Complexity:   res.Complexity,
+		StateChanges: newStateChanges(res.Actions),
+	}
+	if res.Error != nil {
+		o.Error = res.Error.Error()
+		o.CallStack = ride.EvaluationErrorCallStack(res.Error)
+	}
+	if sendErr := trySendJson(w, o); sendErr != nil {
+		return errors.Wrap(sendErr, "invokeTrace")
+	}
+	return nil
+}
+
 func wavesAddressInvalidCharErr(invalidChar rune, id string) *apiErrs.CustomValidationError {
 	return apiErrs.NewCustomValidationError(
 		fmt.Sprintf(
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+func newTestClient(t *testing.T, s *mock.MockState) *client.Client {
+	app, err := NewApp("api-key", nil, services.Services{State: s, Scheme: proto.TestNetScheme})
+	require.NoError(t, err)
+	opts := DefaultRunOptions()
+	opts.RateLimiterOpts = nil
+	router, err := NewNodeAPI(app, s).routes(opts)
+	require.NoError(t, err)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	cl, err := client.NewClient(client.Options{BaseUrl: srv.URL, Client: srv.Client(), ChainID: proto.TestNetScheme})
+	require.NoError(t, err)
+	return cl
+}
+
+func TestNodeApi_AddressesRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s := mock.NewMockState(ctrl)
+	cl := newTestClient(t, s)
+	ctx := context.Background()
+
+	pk := crypto.MustPublicKeyFromBase58("8TLsCqkkroPhBDC5HHE1QxdkzcwwhjKqfLk7yXcNfjGj")
+	addr := proto.MustAddressFromPublicKey(proto.TestNetScheme, pk)
+	rcp := proto.NewRecipientFromAddress(addr)
+
+	s.EXPECT().WavesBalance(rcp).Return(uint64(100500), nil)
+	balance, _, err := cl.Addresses.Balance(ctx, addr)
+	require.NoError(t, err)
+	assert.Equal(t, &client.AddressesBalance{Address: addr, Balance: 100500}, balance)
+
+	s.EXPECT().Height().Return(proto.Height(10), nil)
+	s.EXPECT().MinWavesBalanceInRange(rcp, proto.Height(7), proto.Height(10)).Return(uint64(500), nil)
+	confirmed, _, err := cl.Addresses.BalanceAfterConfirmations(ctx, addr, 3)
+	require.NoError(t, err)
+	assert.Equal(t, &client.BalanceAfterConfirmations{Address: addr, Confirmations: 3, Balance: 500}, confirmed)
+
+	s.EXPECT().WavesBalanceAtHeight(rcp, proto.Height(5)).Return(uint64(300), nil)
+	atHeight, _, err := cl.Addresses.BalanceAtHeight(ctx, addr, 5)
+	require.NoError(t, err)
+	assert.Equal(t, &client.BalanceAtHeight{Address: addr, Height: 5, Balance: 300}, atHeight)
+
+	outOfRange := state.NewStateError(state.InvalidInputError,
+		errors.New("height 3000 is outside of the available history range [1, 10]"))
+	s.EXPECT().WavesBalanceAtHeight(rcp, proto.Height(3000)).Return(uint64(0), outOfRange)
+	_, resp, err := cl.Addresses.BalanceAtHeight(ctx, addr, 3000)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.ErrorContains(t, err, "outside of the available history range")
+
+	full := &proto.FullWavesBalance{Regular: 4, Generating: 3, Available: 2, Effective: 1}
+	s.EXPECT().FullWavesBalance(rcp).Return(full, nil).Times(2)
+	details, _, err := cl.Addresses.BalanceDetails(ctx, addr)
+	require.NoError(t, err)
+	assert.Equal(t, &client.AddressesBalanceDetails{
+		Address: addr, Regular: 4, Generating: 3, Available: 2, Effective: 1,
+	}, details)
+	effective, _, err := cl.Addresses.EffectiveBalance(ctx, addr)
+	require.NoError(t, err)
+	assert.Equal(t, &client.AddressesEffectiveBalance{Address: addr, Balance: 1}, effective)
+
+	valid, _, err := cl.Addresses.Validate(ctx, addr)
+	require.NoError(t, err)
+	assert.True(t, valid.Valid)
+	mainNetAddr := proto.MustAddressFromString("3PAWwWa6GbwcJaFzwqXQN5KQm7H96Y7SHTQ")
+	valid, _, err = cl.Addresses.Validate(ctx, mainNetAddr)
+	require.NoError(t, err)
+	assert.False(t, valid.Valid)
+
+	pkAddr, _, err := cl.Addresses.PublicKey(ctx, pk.String())
+	require.NoError(t, err)
+	assert.Equal(t, addr, *pkAddr)
+
+	entries := proto.DataEntries{
+		&proto.IntegerDataEntry{Key: "int", Value: 12345},
+		&proto.StringDataEntry{Key: "str", Value: "value"},
+		&proto.DeleteDataEntry{Key: "deleted"},
+	}
+	s.EXPECT().RetrieveEntries(rcp).Return([]proto.DataEntry(entries), nil)
+	data, _, err := cl.Addresses.AddressesData(ctx, addr)
+	require.NoError(t, err)
+	assert.Equal(t, entries[:2], data)
+
+	s.EXPECT().RetrieveEntry(rcp, "str").Return(entries[1], nil)
+	entry, _, err := cl.Addresses.AddressesDataKey(ctx, addr, "str")
+	require.NoError(t, err)
+	assert.Equal(t, entries[1], entry)
+
+	s.EXPECT().RetrieveEntry(rcp, "int").Return(entries[0], nil)
+	s.EXPECT().RetrieveEntry(rcp, "missing").Return(nil, state.NewStateError(state.NotFoundError, nil))
+	data, _, err = cl.Addresses.AddressesDataKeys(ctx, addr, []string{"int", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, entries[:1], data)
+
+	s.EXPECT().ScriptInfoByAccount(rcp).Return(nil, state.NewStateError(state.NotFoundError, nil))
+	scriptInfo, _, err := cl.Addresses.ScriptInfo(ctx, addr)
+	require.NoError(t, err)
+	assert.Equal(t, addr, scriptInfo.Address)
+	assert.Empty(t, scriptInfo.Script)
+}
+
+func TestNodeApi_AssetsRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s := mock.NewMockState(ctrl)
+	cl := newTestClient(t, s)
+	ctx := context.Background()
+
+	addr1 := proto.MustAddressFromPublicKey(proto.TestNetScheme,
+		crypto.MustPublicKeyFromBase58("8TLsCqkkroPhBDC5HHE1QxdkzcwwhjKqfLk7yXcNfjGj"))
+	addr2 := proto.MustAddressFromPublicKey(proto.TestNetScheme,
+		crypto.MustPublicKeyFromBase58("AfZtLRQxLNYH5iradMkTeuXGe71uAiATVbr8DpXEEQa8"))
+	assetID := crypto.MustDigestFromBase58("BrjUWjndUanm5VsJkbUip8VRYy6LWJePtxya3FNv4TQa")
+
+	s.EXPECT().AssetBalance(proto.NewRecipientFromAddress(addr1), proto.AssetIDFromDigest(assetID)).
+		Return(uint64(42), nil)
+	balance, _, err := cl.Assets.BalanceByAddressAndAsset(ctx, addr1, assetID)
+	require.NoError(t, err)
+	assert.Equal(t, &client.AssetsBalanceAndAsset{Address: addr1, AssetId: assetID, Balance: 42}, balance)
+
+	// Legacy distribution is read page by page at the current height.
+	s.EXPECT().Height().Return(proto.Height(100), nil)
+	s.EXPECT().IsAssetExist(proto.AssetIDFromDigest(assetID)).Return(true, nil).Times(2)
+	s.EXPECT().AssetDistribution(proto.AssetIDFromDigest(assetID), proto.Height(100), nil, maxAssetDistributionLimit).
+		Return([]state.AssetHolder{{Address: addr1, Balance: 10}}, true, nil)
+	s.EXPECT().AssetDistribution(proto.AssetIDFromDigest(assetID), proto.Height(100), &addr1, maxAssetDistributionLimit).
+		Return([]state.AssetHolder{{Address: addr2, Balance: 20}}, false, nil)
+	distribution, _, err := cl.Assets.Distribution(ctx, assetID)
+	require.NoError(t, err)
+	assert.Equal(t, client.AssetsDistribution{addr1.String(): 10, addr2.String(): 20}, distribution)
+
+	s.EXPECT().IsAssetExist(proto.AssetIDFromDigest(assetID)).Return(true, nil)
+	s.EXPECT().AssetDistribution(proto.AssetIDFromDigest(assetID), proto.Height(90), &addr1, 1).
+		Return([]state.AssetHolder{{Address: addr2, Balance: 5}}, true, nil)
+	page, _, err := cl.Assets.DistributionAtHeight(ctx, assetID, 90, 1, &addr1)
+	require.NoError(t, err)
+	assert.Equal(t, &client.AssetsDistributionAtHeight{
+		HasNext: true, LastItem: addr2, Items: map[proto.WavesAddress]uint64{addr2: 5},
+	}, page)
+
+	s.EXPECT().IsAssetExist(proto.AssetIDFromDigest(assetID)).Return(true, nil)
+	s.EXPECT().AssetDistribution(proto.AssetIDFromDigest(assetID), proto.Height(1), nil, 1).
+		Return(nil, false, state.NewStateError(state.InvalidInputError, errors.New("height is out of range")))
+	_, resp, err := cl.Assets.DistributionAtHeight(ctx, assetID, 1, 1, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestNodeApi_UtilsRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s := mock.NewMockState(ctrl)
+	cl := newTestClient(t, s)
+	ctx := context.Background()
+
+	seed, _, err := cl.Utils.Seed(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, seed)
+
+	const message = "hello"
+	secure, _, err := cl.Utils.HashSecure(ctx, message)
+	require.NoError(t, err)
+	expectedSecure, err := crypto.SecureHash([]byte(message))
+	require.NoError(t, err)
+	assert.Equal(t, &client.UtilsHashSecure{Message: message, Hash: expectedSecure.String()}, secure)
+
+	fast, _, err := cl.Utils.HashFast(ctx, message)
+	require.NoError(t, err)
+	expectedFast, err := crypto.FastHash([]byte(message))
+	require.NoError(t, err)
+	assert.Equal(t, &client.UtilsHashFast{Message: message, Hash: expectedFast.String()}, fast)
+
+	s.EXPECT().EstimatorVersion().Return(4, nil).Times(2)
+	s.EXPECT().IsActivated(gomock.Any()).Return(true, nil).Times(2)
+	const code = "{-# STDLIB_VERSION 6 #-}\n{-# CONTENT_TYPE EXPRESSION #-}\n{-# SCRIPT_TYPE ACCOUNT #-}\ntrue"
+	compiled, _, err := cl.Utils.ScriptCompileCode(ctx, code, false)
+	require.NoError(t, err)
+	assert.NotEmpty(t, compiled.Script)
+	assert.Zero(t, compiled.ExtraFee)
+
+	estimated, _, err := cl.Utils.ScriptEstimate(ctx, compiled.Script)
+	require.NoError(t, err)
+	assert.Equal(t, compiled.Complexity, estimated.Complexity)
+	_, err = base64.StdEncoding.DecodeString(estimated.Script[len(scriptBase64Prefix):])
+	require.NoError(t, err)
+
+	decompiled, _, err := cl.Utils.ScriptDecompile(ctx, compiled.Script)
+	require.NoError(t, err)
+	const source = "{-# STDLIB_VERSION 6 #-}\n{-# CONTENT_TYPE EXPRESSION #-}\n{-# SCRIPT_TYPE ACCOUNT #-}\n\ntrue\n"
+	assert.Equal(t, source, decompiled.Script)
+	assert.Equal(t, source, estimated.ScriptText)
+}
+
+func TestNodeApi_TransactionsAndLeasingRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s := mock.NewMockState(ctrl)
+	cl := newTestClient(t, s)
+	ctx := context.Background()
+
+	sk, pk, err := crypto.GenerateKeyPair([]byte("round-trip"))
+	require.NoError(t, err)
+	addr := proto.MustAddressFromPublicKey(proto.TestNetScheme, pk)
+	rcp := proto.NewRecipientFromAddress(addr)
+	leases := make([]proto.Transaction, 2)
+	for i := range leases {
+		tx := proto.NewUnsignedLeaseWithSig(pk, rcp, uint64(i+1)*100000, 100000, uint64(1700000000000+i))
+		require.NoError(t, tx.Sign(proto.TestNetScheme, sk))
+		leases[i] = tx
+	}
+	expectIteration := func(times int) {
+		iter := mock.NewMockTransactionIterator(ctrl)
+		s.EXPECT().ProvidesExtendedApi().Return(true, nil)
+		s.EXPECT().NewAddrTransactionsIterator(addr).Return(iter, nil)
+		for i := 0; i < times; i++ {
+			iter.EXPECT().Next().Return(true)
+			iter.EXPECT().Transaction().Return(leases[i], proto.TransactionSucceeded, nil)
+		}
+		if times == len(leases) {
+			iter.EXPECT().Next().Return(false)
+		}
+		iter.EXPECT().Release()
+		iter.EXPECT().Error().Return(nil).Times(2)
+	}
+
+	expectIteration(1)
+	txs, _, err := cl.Transactions.Address(ctx, addr, 1)
+	require.NoError(t, err)
+	assert.Equal(t, leases[:1], txs)
+
+	expectIteration(2)
+	s.EXPECT().IsActiveLeasing(*leases[0].(*proto.LeaseWithSig).ID).Return(false, nil)
+	s.EXPECT().IsActiveLeasing(*leases[1].(*proto.LeaseWithSig).ID).Return(true, nil)
+	active, _, err := cl.Leasing.Active(ctx, addr)
+	require.NoError(t, err)
+	assert.Equal(t, []*proto.LeaseWithSig{leases[1].(*proto.LeaseWithSig)}, active)
+}
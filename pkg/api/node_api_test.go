@@ -1,11 +1,23 @@
 package api
 
 import (
+	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/state"
 )
 
 const apiKey = "X-API-Key"
@@ -53,3 +65,58 @@ func TestNodeApi_FindFirstInvalidRuneInBase58String(t *testing.T) {
 		assert.Equal(t, testCase.expected, actual)
 	}
 }
+
+func TestNodeApi_InvokeTrace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s := mock.NewMockState(ctrl)
+	app, err := NewApp("api-key", nil, services.Services{State: s, Scheme: proto.TestNetScheme})
+	require.NoError(t, err)
+	opts := DefaultRunOptions()
+	opts.RateLimiterOpts = nil
+	router, err := NewNodeAPI(app, s).routes(opts)
+	require.NoError(t, err)
+
+	id := crypto.MustDigestFromBase58("5ToCHGDE6QHCGxBFCDUZb8whjREEGmUJrQn7UMsYxNjD")
+	trace := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/debug/invoke/trace/"+id, nil)
+		req.Header.Set(apiKey, "api-key")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("Failed", func(t *testing.T) {
+		steps := []ride.TraceStep{
+			{Kind: ride.TraceStepCall, Name: "throw", Args: []ride.Value{{Type: "String", Value: "boom"}}, Error: "boom"},
+		}
+		s.EXPECT().TraceInvoke(id.Bytes(), ride.DefaultMaxTraceSteps).Return(&state.InvokeTrace{
+			Trace:      ride.Trace{Steps: steps},
+			Complexity: 1,
+			Error:      ride.UserError.New("boom"),
+		}, nil)
+		resp := trace(id.String())
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var out struct {
+			ID         string     `json:"id"`
+			Trace      ride.Trace `json:"trace"`
+			Complexity int        `json:"complexity"`
+			Error      string     `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &out))
+		assert.Equal(t, id.String(), out.ID)
+		assert.Equal(t, 1, out.Complexity)
+		assert.Equal(t, "boom", out.Error)
+		require.Len(t, out.Trace.Steps, 1)
+		assert.Equal(t, "throw", out.Trace.Steps[0].Name)
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		s.EXPECT().TraceInvoke(id.Bytes(), ride.DefaultMaxTraceSteps).
+			Return(nil, state.NewStateError(state.NotFoundError, errors.New("not found")))
+		resp := trace(id.String())
+		assert.Equal(t, http.StatusNotFound, resp.Code, resp.Body.String())
+	})
+	t.Run("InvalidID", func(t *testing.T) {
+		resp := trace("invalid")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+	})
+}
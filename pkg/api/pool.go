@@ -1,5 +1,46 @@
 package api
 
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/types"
+)
+
 func (a *App) PoolTransactions() int {
 	return a.utx.Count()
 }
+
+// PoolDetails returns the transactions of the UTX pool in the order of priority and the recent evictions.
+func (a *App) PoolDetails() types.UtxPoolDetails {
+	return a.utx.Details()
+}
+
+// UnconfirmedTransactions returns all transactions from the UTX pool.
+func (a *App) UnconfirmedTransactions() []proto.Transaction {
+	txs := a.utx.AllTransactions()
+	res := make([]proto.Transaction, len(txs))
+	for i, tx := range txs {
+		res[i] = tx.T
+	}
+	return res
+}
+
+// UnconfirmedTransactionByID returns the transaction from the UTX pool by its ID or notFound error.
+func (a *App) UnconfirmedTransactionByID(id []byte) (proto.Transaction, error) {
+	if !a.utx.ExistsByID(id) {
+		return nil, notFound
+	}
+	for _, tx := range a.utx.AllTransactions() {
+		txID, err := tx.T.GetID(a.scheme())
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get transaction ID")
+		}
+		if bytes.Equal(txID, id) {
+			return tx.T, nil
+		}
+	}
+	return nil, notFound // the transaction has left the pool between the checks
+}
@@ -7,9 +7,11 @@ import (
 	"github.com/go-chi/chi/middleware"
 	"github.com/pkg/errors"
 	"github.com/semrush/zenrpc/v2"
+	"github.com/throttled/throttled/v2"
 	"go.uber.org/zap"
 
 	"github.com/wavesplatform/gowaves/pkg/api/metamask"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 )
 
 type HandleErrorFunc func(w http.ResponseWriter, r *http.Request, err error)
@@ -34,11 +36,13 @@ func (a *NodeApi) routes(opts *RunOptions) (chi.Router, error) {
 	if opts.CollectMetrics {
 		r.Use(chiHttpApiGeneralMetricsMiddleware)
 	}
+	var rateLimiter *throttled.HTTPRateLimiterCtx
 	if opts.RateLimiterOpts != nil {
-		rateLimiter, err := createRateLimiter(opts.RateLimiterOpts)
+		rl, err := createRateLimiter(opts.RateLimiterOpts)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
+		rateLimiter = &rl
 		r.Use(rateLimiter.RateLimit)
 	}
 	if opts.RequestIDMiddleware {
@@ -119,10 +123,41 @@ func (a *NodeApi) routes(opts *RunOptions) (chi.Router, error) {
 			r.Get("/details/{id}", wrapper(a.AssetsDetailsByID))
 			r.Get("/details", wrapper(a.AssetsDetailsByIDsGet))
 			r.Post("/details", wrapper(a.AssetsDetailsByIDsPost))
+			r.Get("/balance/{address}", wrapper(a.AssetsBalanceByAddress))
+			r.Get("/balance/{address}/{assetId}", wrapper(a.AssetsBalanceByAddressAndAsset))
+			r.Get("/{id}/distribution", wrapper(a.AssetsDistribution))
+			r.Get("/{id}/distribution/{height:\\d+}/limit/{limit:\\d+}", wrapper(a.AssetsDistributionAtHeight))
 		})
 
 		r.Route("/addresses", func(r chi.Router) {
 			r.Get("/", wrapper(a.Addresses))
+			r.Get("/balance/{address}", wrapper(a.AddressesBalance))
+			r.Get("/balance/{address}/{confirmations:\\d+}", wrapper(a.AddressesBalance))
+			r.Get("/balance/details/{address}", wrapper(a.AddressesBalanceDetails))
+			r.Get("/effectiveBalance/{address}", wrapper(a.AddressesEffectiveBalance))
+			r.Get("/effectiveBalance/{address}/{confirmations:\\d+}", wrapper(a.AddressesEffectiveBalance))
+			r.Get("/scriptInfo/{address}", wrapper(a.AddressesScriptInfo))
+			r.Get("/validate/{address}", wrapper(a.AddressesValidate))
+			r.Get("/publicKey/{publicKey}", wrapper(a.AddressesPublicKey))
+			r.Get("/data/{address}", wrapper(a.AddressesDataGet))
+			r.Post("/data/{address}", wrapper(a.AddressesDataPost))
+			r.Get("/data/{address}/{key}", wrapper(a.AddressesDataKey))
+		})
+
+		r.Route("/leasing", func(r chi.Router) {
+			r.Get("/active/{address}", wrapper(a.LeasingActive))
+		})
+
+		r.Route("/utils", func(r chi.Router) {
+			r.Get("/seed", wrapper(a.UtilsSeed))
+			r.Get("/seed/{length:\\d+}", wrapper(a.UtilsSeed))
+			r.Post("/hash/secure", wrapper(utilsHash(crypto.SecureHash)))
+			r.Post("/hash/fast", wrapper(utilsHash(crypto.FastHash)))
+			r.Get("/time", wrapper(a.UtilsTime))
+			r.Post("/script/compileCode", wrapper(a.UtilsScriptCompileCode))
+			r.Post("/script/estimate", wrapper(a.UtilsScriptEstimate))
+			r.Post("/script/decompile", wrapper(a.UtilsScriptDecompile))
+			r.Post("/script/evaluate/{address}", wrapper(a.UtilsScriptEvaluate))
 		})
 
 		r.Route("/alias", func(r chi.Router) {
@@ -131,9 +166,17 @@ func (a *NodeApi) routes(opts *RunOptions) (chi.Router, error) {
 		})
 
 		r.Route("/transactions", func(r chi.Router) {
+			r.Get("/unconfirmed", wrapper(a.TransactionsUnconfirmed))
 			r.Get("/unconfirmed/size", wrapper(a.unconfirmedSize))
+			r.Get("/unconfirmed/info/{id}", wrapper(a.TransactionsUnconfirmedInfo))
+			r.Get("/address/{address}/limit/{limit:\\d+}", wrapper(a.TransactionsByAddress))
 			r.Get("/info/{id}", wrapper(a.TransactionInfo))
 			r.Post("/broadcast", wrapper(a.TransactionsBroadcast))
+
+			rAuth := r.With(checkAuthMiddleware)
+
+			rAuth.Post("/sign", wrapper(a.TransactionsSign))
+			rAuth.Post("/sign/{signerAddress}", wrapper(a.TransactionsSign))
 		})
 
 		r.Route("/peers", func(r chi.Router) {
@@ -154,9 +197,11 @@ func (a *NodeApi) routes(opts *RunOptions) (chi.Router, error) {
 
 			rAuth := r.With(checkAuthMiddleware)
 
+			rAuth.Post("/validate", wrapper(a.debugValidate))
 			rAuth.Post("/print", wrapper(a.debugPrint))
 			rAuth.Post("/rollback", wrapper(a.RollbackToHeight))
 			rAuth.Post("/rollback-to/{id}", wrapper(a.RollbackTo))
+			rAuth.Get("/invoke/trace/{id}", wrapper(a.invokeTrace))
 		})
 		r.Route("/node", func(r chi.Router) {
 			r.Get("/version", wrapper(a.version))
@@ -179,6 +224,7 @@ func (a *NodeApi) routes(opts *RunOptions) (chi.Router, error) {
 				}
 				rpc.Register("", service)
 				r.Handle("/", rpc)
+				r.Handle("/ws", metamask.NewWSHandler(rpc, service, a.app.services.Notifications))
 			}
 		})
 
@@ -187,6 +233,8 @@ func (a *NodeApi) routes(opts *RunOptions) (chi.Router, error) {
 			r.Get("/rewards/{height}", wrapper(a.blockchainRewardsAtHeight))
 		})
 
+		r.Handle("/ws", newWSHandler(a.app, rateLimiter, errHandler.Handle))
+
 		// enable or disable history sync
 		//r.Get("/debug/sync/{enabled:\\d+}", a.DebugSyncEnabled)
 	})
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	apiErrs "github.com/wavesplatform/gowaves/pkg/api/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler"
+	"github.com/wavesplatform/gowaves/pkg/ride/decompiler"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+// evaluatedFunctionName is the name of the function the evaluated expression is wrapped into to be compiled
+// together with the declarations of the DApp.
+const evaluatedFunctionName = "evaluatedExpression"
+
+// scriptEvaluationRequest is the request of expression evaluation, either the expression or the call
+// of callable function must be set.
+type scriptEvaluationRequest struct {
+	Expr string              `json:"expr,omitempty"`
+	Call *proto.FunctionCall `json:"call,omitempty"`
+}
+
+// EvaluateScript evaluates the expression or calls the callable function of the DApp at the given address against
+// the current state. State changes produced by the evaluation are returned, but not applied to the state.
+func (a *App) EvaluateScript(
+	ctx context.Context, addr proto.WavesAddress, req scriptEvaluationRequest,
+) (ride.Evaluation, error) {
+	if (req.Expr == "") == (req.Call == nil) {
+		return ride.Evaluation{}, apiErrs.NewCustomValidationError("Either 'expr' or 'call' must be specified")
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.settings.EvaluateTimeout)
+	defer cancel()
+	ev, err := a.evaluateScript(ctx, addr, req)
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		return ride.Evaluation{}, apiErrs.NewScriptExecutionError(
+			fmt.Sprintf("Evaluation timed out after %s", a.settings.EvaluateTimeout))
+	}
+	return ev, err
+}
+
+func (a *App) evaluateScript(
+	ctx context.Context, addr proto.WavesAddress, req scriptEvaluationRequest,
+) (ride.Evaluation, error) {
+	rcp := proto.NewRecipientFromAddress(addr)
+	tree, err := a.state.NewestScriptByAccount(rcp)
+	if err != nil {
+		if !state.IsNotFound(err) {
+			return ride.Evaluation{}, errors.Wrapf(err, "failed to get script of address %q", addr.String())
+		}
+		tree = nil
+	}
+	if req.Call != nil && (tree == nil || !tree.IsDApp()) {
+		return ride.Evaluation{}, apiErrs.NewCustomValidationError(
+			fmt.Sprintf("Address %s is not a DApp", addr.String()))
+	}
+	call := proto.NewFunctionCall(evaluatedFunctionName, nil)
+	if req.Call != nil {
+		call = *req.Call
+	}
+	env, err := state.NewEvaluationEnvironment(a.state, addr, tree, call, nil, nil, a.settings.EvaluateComplexityLimit)
+	if err != nil {
+		return ride.Evaluation{}, errors.Wrap(err, "failed to create RIDE environment")
+	}
+	env.SetContext(ctx)
+	if req.Call != nil {
+		r, cErr := ride.CallFunction(env, tree, *req.Call)
+		if cErr != nil {
+			return ride.Evaluation{}, evaluationError(ctx, cErr)
+		}
+		return ride.NewEvaluation(r), nil
+	}
+	expr, err := compileEvaluatedExpression(tree, req.Expr)
+	if err != nil {
+		return ride.Evaluation{}, err
+	}
+	if tree == nil {
+		tree = emptyDApp(ast.CurrentMaxLibraryVersion())
+	}
+	ev, err := ride.EvaluateExpression(env, tree, expr)
+	if err != nil {
+		return ride.Evaluation{}, evaluationError(ctx, err)
+	}
+	return ev, nil
+}
+
+// evaluationError returns the context error if the evaluation was interrupted, otherwise the script execution error.
+func evaluationError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return apiErrs.NewScriptExecutionError(err.Error())
+}
+
+// emptyDApp returns the DApp without declarations, it's the scope of expressions evaluated against addresses
+// without scripts.
+func emptyDApp(version ast.LibraryVersion) *ast.Tree {
+	return &ast.Tree{
+		LibVersion:   version,
+		ContentType:  ast.ContentTypeApplication,
+		Declarations: []ast.Node{},
+		Functions:    []ast.Node{},
+	}
+}
+
+// compileEvaluatedExpression type checks the expression in the scope of the DApp declarations and returns
+// the compiled expression to be evaluated against the original DApp tree. The expression is wrapped into
+// the function that is appended to the decompiled declarations, only the body of the function is taken from
+// the compilation result. Expressions against addresses without scripts are compiled with the latest library version.
+func compileEvaluatedExpression(tree *ast.Tree, expr string) (ast.Node, error) {
+	version := ast.CurrentMaxLibraryVersion()
+	declarations := ""
+	if tree != nil {
+		version = tree.LibVersion
+		var err error
+		if declarations, err = decompiler.DecompileDeclarations(tree); err != nil {
+			return nil, errors.Wrap(err, "failed to restore DApp declarations")
+		}
+	}
+	if version < ast.LibV3 { // DApps are supported since V3
+		version = ast.LibV3
+	}
+	code := fmt.Sprintf("{-# STDLIB_VERSION %d #-}\n{-# CONTENT_TYPE DAPP #-}\n{-# SCRIPT_TYPE ACCOUNT #-}\n\n%s"+
+		"func %s() = %s\n", version, declarations, evaluatedFunctionName, expr)
+	compiled, errs := compiler.CompileToTree(code)
+	if len(errs) > 0 {
+		return nil, newScriptCompilerError(errs)
+	}
+	last := len(compiled.Declarations) - 1
+	if last < 0 {
+		return nil, apiErrs.NewScriptCompilerError("Failed to compile expression")
+	}
+	fn, ok := compiled.Declarations[last].(*ast.FunctionDeclarationNode)
+	if !ok || fn.Name != evaluatedFunctionName {
+		return nil, apiErrs.NewScriptCompilerError("Failed to compile expression")
+	}
+	return fn.Body, nil
+}
+
+type transferChange struct {
+	Address proto.Recipient     `json:"address"`
+	Asset   proto.OptionalAsset `json:"asset"`
+	Amount  int64               `json:"amount"`
+}
+
+type issueChange struct {
+	AssetID     crypto.Digest `json:"assetId"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Quantity    int64         `json:"quantity"`
+	Decimals    int32         `json:"decimals"`
+	Reissuable  bool          `json:"isReissuable"`
+	Nonce       int64         `json:"nonce"`
+}
+
+type reissueChange struct {
+	AssetID    crypto.Digest `json:"assetId"`
+	Reissuable bool          `json:"isReissuable"`
+	Quantity   int64         `json:"quantity"`
+}
+
+type burnChange struct {
+	AssetID  crypto.Digest `json:"assetId"`
+	Quantity int64         `json:"quantity"`
+}
+
+type sponsorFeeChange struct {
+	AssetID              crypto.Digest `json:"assetId"`
+	MinSponsoredAssetFee int64         `json:"minSponsoredAssetFee"`
+}
+
+type leaseChange struct {
+	ID        crypto.Digest   `json:"id"`
+	Recipient proto.Recipient `json:"recipient"`
+	Amount    int64           `json:"amount"`
+}
+
+type leaseCancelChange struct {
+	ID crypto.Digest `json:"id"`
+}
+
+// stateChanges is the representation of the actions produced by the script in the form of transaction
+// state changes.
+type stateChanges struct {
+	Data         proto.DataEntries   `json:"data"`
+	Transfers    []transferChange    `json:"transfers"`
+	Issues       []issueChange       `json:"issues"`
+	Reissues     []reissueChange     `json:"reissues"`
+	Burns        []burnChange        `json:"burns"`
+	SponsorFees  []sponsorFeeChange  `json:"sponsorFees"`
+	Leases       []leaseChange       `json:"leases"`
+	LeaseCancels []leaseCancelChange `json:"leaseCancels"`
+}
+
+// newStateChanges groups the actions by their types, the payments attached to internal invocations are omitted.
+func newStateChanges(actions []proto.ScriptAction) stateChanges {
+	sc := stateChanges{
+		Data:         proto.DataEntries{},
+		Transfers:    []transferChange{},
+		Issues:       []issueChange{},
+		Reissues:     []reissueChange{},
+		Burns:        []burnChange{},
+		SponsorFees:  []sponsorFeeChange{},
+		Leases:       []leaseChange{},
+		LeaseCancels: []leaseCancelChange{},
+	}
+	for _, action := range actions {
+		switch a := action.(type) {
+		case *proto.DataEntryScriptAction:
+			sc.Data = append(sc.Data, a.Entry)
+		case *proto.TransferScriptAction:
+			sc.Transfers = append(sc.Transfers, transferChange{Address: a.Recipient, Asset: a.Asset, Amount: a.Amount})
+		case *proto.IssueScriptAction:
+			sc.Issues = append(sc.Issues, issueChange{
+				AssetID:     a.ID,
+				Name:        a.Name,
+				Description: a.Description,
+				Quantity:    a.Quantity,
+				Decimals:    a.Decimals,
+				Reissuable:  a.Reissuable,
+				Nonce:       a.Nonce,
+			})
+		case *proto.ReissueScriptAction:
+			sc.Reissues = append(sc.Reissues,
+				reissueChange{AssetID: a.AssetID, Reissuable: a.Reissuable, Quantity: a.Quantity})
+		case *proto.BurnScriptAction:
+			sc.Burns = append(sc.Burns, burnChange{AssetID: a.AssetID, Quantity: a.Quantity})
+		case *proto.SponsorshipScriptAction:
+			sc.SponsorFees = append(sc.SponsorFees, sponsorFeeChange{AssetID: a.AssetID, MinSponsoredAssetFee: a.MinFee})
+		case *proto.LeaseScriptAction:
+			sc.Leases = append(sc.Leases, leaseChange{ID: a.ID, Recipient: a.Recipient, Amount: a.Amount})
+		case *proto.LeaseCancelScriptAction:
+			sc.LeaseCancels = append(sc.LeaseCancels, leaseCancelChange{ID: a.LeaseID})
+		}
+	}
+	return sc
+}
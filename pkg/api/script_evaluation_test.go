@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiErrs "github.com/wavesplatform/gowaves/pkg/api/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler"
+	"github.com/wavesplatform/gowaves/pkg/ride/serialization"
+	"github.com/wavesplatform/gowaves/pkg/services"
+)
+
+func TestCompileEvaluatedExpression(t *testing.T) {
+	b, errs := compiler.Compile(`
+		{-# STDLIB_VERSION 6 #-}
+		{-# CONTENT_TYPE DAPP #-}
+		{-# SCRIPT_TYPE ACCOUNT #-}
+		let config = "config"
+		func amountKey(address: String) = config + "_" + address
+
+		@Callable(inv)
+		func store(amount: Int) = [IntegerEntry(amountKey(toString(inv.caller)), amount)]`, true, false)
+	require.Empty(t, errs)
+	dApp, err := serialization.Parse(b)
+	require.NoError(t, err)
+
+	expr, err := compileEvaluatedExpression(dApp, `amountKey("a")`)
+	require.NoError(t, err)
+	call, ok := expr.(*ast.FunctionCallNode)
+	require.True(t, ok)
+	assert.Equal(t, "amountKey", call.Function.Name())
+	assert.Len(t, dApp.Declarations, 2) // The DApp itself is evaluated without modifications
+
+	expr, err = compileEvaluatedExpression(nil, "1 + 1")
+	require.NoError(t, err)
+	assert.IsType(t, &ast.FunctionCallNode{}, expr)
+
+	_, err = compileEvaluatedExpression(dApp, "unknown(1)")
+	var ce *apiErrs.ScriptCompilerError
+	assert.ErrorAs(t, err, &ce)
+}
+
+func TestEvaluateScriptRequestValidation(t *testing.T) {
+	app, err := NewApp("", nil, services.Services{})
+	require.NoError(t, err)
+	call := proto.NewFunctionCall("f", nil)
+	for _, req := range []scriptEvaluationRequest{{}, {Expr: "1", Call: &call}} {
+		_, err = app.EvaluateScript(context.Background(), proto.WavesAddress{}, req)
+		var ve *apiErrs.CustomValidationError
+		assert.ErrorAs(t, err, &ve)
+	}
+}
+
+func TestNewStateChanges(t *testing.T) {
+	rcp := proto.NewRecipientFromAddress(proto.WavesAddress{1})
+	sc := newStateChanges([]proto.ScriptAction{
+		&proto.DataEntryScriptAction{Entry: &proto.IntegerDataEntry{Key: "k", Value: 1}},
+		&proto.TransferScriptAction{Recipient: rcp, Amount: 2, Asset: proto.NewOptionalAssetWaves()},
+		&proto.AttachedPaymentScriptAction{Recipient: rcp, Amount: 3},
+		&proto.BurnScriptAction{Quantity: 4},
+	})
+	assert.Equal(t, proto.DataEntries{&proto.IntegerDataEntry{Key: "k", Value: 1}}, sc.Data)
+	assert.Equal(t, []transferChange{{Address: rcp, Asset: proto.NewOptionalAssetWaves(), Amount: 2}}, sc.Transfers)
+	assert.Equal(t, []burnChange{{Quantity: 4}}, sc.Burns)
+	assert.Empty(t, sc.Issues)
+	assert.NotNil(t, sc.Leases)
+}
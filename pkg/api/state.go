@@ -9,3 +9,12 @@ import (
 func (a *App) RollbackToHeight(apiKey string, height proto.Height) error {
 	return errors.New("api method disabled")
 }
+
+// rollbackTo rolls back the state to the given block. The rollback is made through the blocks applier if it's set,
+// so the decorators of the applier (for example, blockchain updates tracker) are notified about it.
+func (a *App) rollbackTo(blockID proto.BlockID) error {
+	if a.services.BlocksApplier == nil {
+		return a.state.RollbackTo(blockID)
+	}
+	return a.services.BlocksApplier.RollbackTo(a.state, blockID)
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	apiErrs "github.com/wavesplatform/gowaves/pkg/api/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+// maxTransactionsByAddressLimit is the maximum number of transactions that can be requested by address at once.
+const maxTransactionsByAddressLimit = 1000
+
+var extendedAPIIsNotProvidedErr = apiErrs.NewCustomValidationError(
+	"Node's state does not have information required for extended API",
+)
+
+// addressTransactions returns transactions of the address accepted by the filter from the most recent to the oldest
+// ones, but no more than limit. Only depth most recent transactions of the address are looked through.
+// If after is not nil the transactions are returned starting from the next one after the transaction with
+// the given ID.
+func (a *App) addressTransactions(
+	addr proto.WavesAddress,
+	limit, depth int,
+	after []byte,
+	filter func(proto.Transaction) (bool, error),
+) ([]proto.Transaction, error) {
+	extendedAPI, err := a.state.ProvidesExtendedApi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check extended API availability")
+	}
+	if !extendedAPI {
+		return nil, extendedAPIIsNotProvidedErr
+	}
+	iter, err := a.state.NewAddrTransactionsIterator(addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create transactions iterator for address %q", addr.String())
+	}
+	res := make([]proto.Transaction, 0)
+	if iter == nil {
+		// Nothing to iterate.
+		return res, nil
+	}
+	defer func() {
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			zap.S().Fatalf("Iterator error: %v", err)
+		}
+	}()
+	skip := after != nil
+	for i := 0; i < depth && len(res) < limit && iter.Next(); i++ {
+		tx, _, err := iter.Transaction()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get transaction from iterator")
+		}
+		if skip {
+			id, err := tx.GetID(a.scheme())
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get transaction ID")
+			}
+			skip = !bytes.Equal(id, after)
+			continue
+		}
+		ok, err := filter(tx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res = append(res, tx)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, errors.Wrap(err, "iterator error")
+	}
+	return res, nil
+}
+
+func (a *App) TransactionsByAddress(addr proto.WavesAddress, limit int, after []byte) ([]proto.Transaction, error) {
+	return a.addressTransactions(addr, limit, math.MaxInt, after,
+		func(proto.Transaction) (bool, error) { return true, nil },
+	)
+}
+
+// signerAccount returns the wallet account which is the signer of the transaction given as JSON fields.
+// The account is the one with the signer address if it is not nil, otherwise it's the sender of the transaction
+// which is determined by the sender public key or the sender address.
+func (a *App) signerAccount(fields map[string]json.RawMessage, signer *proto.WavesAddress) (account, error) {
+	accounts, err := a.Accounts()
+	if err != nil {
+		return account{}, errors.Wrap(err, "failed to get wallet accounts")
+	}
+	var match func(acc account) bool
+	switch {
+	case signer != nil:
+		match = func(acc account) bool { return acc.Address == *signer }
+	case fields["senderPublicKey"] != nil:
+		var pk crypto.PublicKey
+		if err := json.Unmarshal(fields["senderPublicKey"], &pk); err != nil {
+			return account{}, apiErrs.InvalidPublicKey
+		}
+		match = func(acc account) bool { return acc.PublicKey == pk }
+	case fields["sender"] != nil:
+		var addr proto.WavesAddress
+		if err := json.Unmarshal(fields["sender"], &addr); err != nil {
+			return account{}, apiErrs.InvalidAddress
+		}
+		match = func(acc account) bool { return acc.Address == addr }
+	default:
+		return account{}, apiErrs.NewCustomValidationError("Transaction sender is not specified")
+	}
+	for _, acc := range accounts {
+		if match(acc) {
+			return acc, nil
+		}
+	}
+	return account{}, apiErrs.MissingSenderPrivateKey
+}
+
+func (a *App) unmarshalTransaction(fields map[string]json.RawMessage) (proto.Transaction, error) {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal transaction fields")
+	}
+	tt := proto.TransactionTypeVersion{}
+	if err := json.Unmarshal(b, &tt); err != nil {
+		return nil, apiErrs.NewCustomValidationError(fmt.Sprintf("Invalid transaction: %v", err))
+	}
+	tx, err := proto.GuessTransactionType(&tt)
+	if err != nil {
+		return nil, apiErrs.NewCustomValidationError(fmt.Sprintf("Invalid transaction: %v", err))
+	}
+	if err := proto.UnmarshalTransactionFromJSON(b, a.services.Scheme, tx); err != nil {
+		return nil, apiErrs.NewCustomValidationError(fmt.Sprintf("Invalid transaction: %v", err))
+	}
+	return tx, nil
+}
+
+// TransactionsSign signs the transaction given as JSON with the key of the wallet account. The account is the one
+// with the signer address if it is not nil, otherwise it's the sender of the transaction. Omitted sender public key,
+// timestamp and fee are filled in from the account, the node's time and the state's fee rules respectively.
+func (a *App) TransactionsSign(body []byte, signer *proto.WavesAddress) (proto.Transaction, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, apiErrs.NewCustomValidationError(fmt.Sprintf("Invalid transaction: %v", err))
+	}
+	acc, err := a.signerAccount(fields, signer)
+	if err != nil {
+		return nil, err
+	}
+	if fields["senderPublicKey"] == nil {
+		pk, err := json.Marshal(acc.PublicKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal public key")
+		}
+		fields["senderPublicKey"] = pk
+	}
+	if fields["timestamp"] == nil {
+		now := time.Now()
+		if t := a.services.Time; t != nil {
+			now = t.Now()
+		}
+		fields["timestamp"] = json.RawMessage(strconv.FormatInt(now.UnixMilli(), 10))
+	}
+	tx, err := a.unmarshalTransaction(fields)
+	if err != nil {
+		return nil, err
+	}
+	if tx.GetFee() == 0 {
+		fee, err := a.state.MinFee(tx)
+		if err != nil {
+			return nil, apiErrs.NewCustomValidationError(fmt.Sprintf("Failed to calculate fee: %v", err))
+		}
+		fields["fee"] = json.RawMessage(strconv.FormatUint(fee, 10))
+		if tx, err = a.unmarshalTransaction(fields); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.services.Wallet.SignTransactionWith(acc.PublicKey, tx); err != nil {
+		if errors.Is(err, wallet.PublicKeyNotFound) {
+			return nil, apiErrs.MissingSenderPrivateKey
+		}
+		return nil, errors.Wrap(err, "failed to sign transaction")
+	}
+	return tx, nil
+}
+
+func (a *NodeApi) TransactionsSign(w http.ResponseWriter, r *http.Request) error {
+	var signer *proto.WavesAddress
+	if chi.URLParam(r, "signerAddress") != "" {
+		addr, err := addressFromURLParam(r, "signerAddress")
+		if err != nil {
+			return err
+		}
+		signer = &addr
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, postMessageSizeLimit))
+	if err != nil {
+		return errors.Wrap(err, "failed to read request body")
+	}
+	tx, err := a.app.TransactionsSign(body, signer)
+	if err != nil {
+		return errors.Wrap(err, "TransactionsSign")
+	}
+	if err := trySendJson(w, tx); err != nil {
+		return errors.Wrap(err, "TransactionsSign")
+	}
+	return nil
+}
+
+func (a *NodeApi) TransactionsByAddress(w http.ResponseWriter, r *http.Request) error {
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	limit, err := strconv.Atoi(chi.URLParam(r, "limit"))
+	if err != nil || limit <= 0 || limit > maxTransactionsByAddressLimit {
+		return apiErrs.NewCustomValidationError(
+			fmt.Sprintf("Limit should be greater than 0 and less than or equal to %d", maxTransactionsByAddressLimit),
+		)
+	}
+	var after []byte
+	if s := r.URL.Query().Get("after"); s != "" {
+		id, err := crypto.NewDigestFromBase58(s)
+		if err != nil {
+			return transactionIDAtInvalidLenErr(s)
+		}
+		after = id.Bytes()
+	}
+	txs, err := a.app.TransactionsByAddress(addr, limit, after)
+	if err != nil {
+		return errors.Wrap(err, "TransactionsByAddress")
+	}
+	// The result is wrapped into an outer array for compatibility with the Scala node.
+	if err := trySendJson(w, [][]proto.Transaction{txs}); err != nil {
+		return errors.Wrap(err, "TransactionsByAddress")
+	}
+	return nil
+}
+
+func (a *NodeApi) TransactionsUnconfirmed(w http.ResponseWriter, _ *http.Request) error {
+	if err := trySendJson(w, a.app.UnconfirmedTransactions()); err != nil {
+		return errors.Wrap(err, "TransactionsUnconfirmed")
+	}
+	return nil
+}
+
+func (a *NodeApi) TransactionsUnconfirmedInfo(w http.ResponseWriter, r *http.Request) error {
+	s := chi.URLParam(r, "id")
+	id, err := crypto.NewDigestFromBase58(s)
+	if err != nil {
+		if invalidRune, isInvalid := findFirstInvalidRuneInBase58String(s); isInvalid {
+			return transactionIDAtInvalidCharErr(invalidRune, s)
+		}
+		return transactionIDAtInvalidLenErr(s)
+	}
+	tx, err := a.app.UnconfirmedTransactionByID(id.Bytes())
+	if err != nil {
+		if errors.Is(err, notFound) {
+			return apiErrs.TransactionDoesNotExist
+		}
+		return errors.Wrapf(err, "failed to get unconfirmed transaction %q", s)
+	}
+	if err := trySendJson(w, tx); err != nil {
+		return errors.Wrap(err, "TransactionsUnconfirmedInfo")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+func TestNodeApi_TransactionsSign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s := mock.NewMockState(ctrl)
+
+	seed := []byte("transactions sign test seed")
+	w := wallet.NewWallet()
+	require.NoError(t, w.AddAccountSeed(seed))
+	_, pk, err := crypto.GenerateKeyPair(seed)
+	require.NoError(t, err)
+	sender := proto.MustAddressFromPublicKey(proto.TestNetScheme, pk)
+	_, otherPK, err := crypto.GenerateKeyPair([]byte("other seed"))
+	require.NoError(t, err)
+	other := proto.MustAddressFromPublicKey(proto.TestNetScheme, otherPK)
+
+	app, err := NewApp("api-key", nil, services.Services{
+		State:  s,
+		Scheme: proto.TestNetScheme,
+		Wallet: wallet.NewEmbeddedWallet(nil, w, proto.TestNetScheme),
+	})
+	require.NoError(t, err)
+	opts := DefaultRunOptions()
+	opts.RateLimiterOpts = nil
+	router, err := NewNodeAPI(app, s).routes(opts)
+	require.NoError(t, err)
+
+	sign := func(path, body, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(apiKey, key)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	transfer := `{"type":4,"version":2,"sender":"` + sender.String() + `","recipient":"` + other.String() +
+		`","amount":100,"timestamp":1700000000000}`
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp := sign("/transactions/sign", transfer, "")
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+	t.Run("FeeFromState", func(t *testing.T) {
+		s.EXPECT().MinFee(gomock.Any()).Return(uint64(500000), nil)
+		resp := sign("/transactions/sign", transfer, "api-key")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		tx := new(proto.TransferWithProofs)
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), tx))
+		assert.Equal(t, pk, tx.SenderPK)
+		assert.Equal(t, uint64(500000), tx.Fee)
+		assert.Equal(t, uint64(1700000000000), tx.Timestamp)
+		ok, err := tx.Verify(proto.TestNetScheme, pk)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("ExplicitFeeAndSigner", func(t *testing.T) {
+		body := `{"type":4,"version":2,"recipient":"` + other.String() + `","amount":100,"fee":100000}`
+		resp := sign("/transactions/sign/"+sender.String(), body, "api-key")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		tx := new(proto.TransferWithProofs)
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), tx))
+		assert.Equal(t, uint64(100000), tx.Fee)
+		assert.NotZero(t, tx.Timestamp)
+		ok, err := tx.Verify(proto.TestNetScheme, pk)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("UnknownSigner", func(t *testing.T) {
+		resp := sign("/transactions/sign/"+other.String(), transfer, "api-key")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,300 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/mr-tron/base58"
+	"github.com/pkg/errors"
+
+	apiErrs "github.com/wavesplatform/gowaves/pkg/api/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler"
+	"github.com/wavesplatform/gowaves/pkg/ride/decompiler"
+	"github.com/wavesplatform/gowaves/pkg/ride/serialization"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+const (
+	defaultSeedLength  = 32
+	maxSeedLength      = 1024
+	scriptBase64Prefix = "base64:"
+)
+
+type scriptEstimation struct {
+	complexity uint64
+	verifier   uint64
+	callables  map[string]uint64
+	extraFee   uint64
+}
+
+// estimateTree estimates the script tree with the estimator of the current state and calculates
+// the extra fee that is taken for the script execution.
+func (a *App) estimateTree(tree *ast.Tree) (scriptEstimation, error) {
+	version, err := a.state.EstimatorVersion()
+	if err != nil {
+		return scriptEstimation{}, errors.Wrap(err, "failed to get estimator version")
+	}
+	est, err := ride.EstimateTree(tree, version)
+	if err != nil {
+		return scriptEstimation{}, errors.Wrap(err, "failed to estimate script")
+	}
+	rideV5Activated, err := a.state.IsActivated(int16(settings.RideV5))
+	if err != nil {
+		return scriptEstimation{}, errors.Wrap(err, "failed to check RideV5 activation")
+	}
+	res := scriptEstimation{
+		complexity: uint64(est.Estimation),
+		verifier:   uint64(est.Verifier),
+		callables:  make(map[string]uint64, len(est.Functions)),
+	}
+	for name, c := range est.Functions {
+		res.callables[name] = uint64(c)
+	}
+	if !(rideV5Activated && est.Verifier <= state.FreeVerifierComplexity) {
+		res.extraFee = state.ScriptExtraFee
+	}
+	return res, nil
+}
+
+func (a *App) estimateScript(script []byte) (scriptEstimation, error) {
+	tree, err := serialization.Parse(script)
+	if err != nil {
+		return scriptEstimation{}, errors.Wrap(err, "failed to parse script")
+	}
+	return a.estimateTree(tree)
+}
+
+// decompileScript restores the source code of the compiled script.
+func decompileScript(script []byte) (string, error) {
+	tree, err := serialization.Parse(script)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse script")
+	}
+	return decompiler.Decompile(tree)
+}
+
+func newScriptCompilerError(errs []error) error {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return apiErrs.NewScriptCompilerError(strings.Join(messages, "; "))
+}
+
+func (a *NodeApi) UtilsSeed(w http.ResponseWriter, r *http.Request) error {
+	type seedResponse struct {
+		Seed string `json:"seed"`
+	}
+
+	length := defaultSeedLength
+	if s := chi.URLParam(r, "length"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l <= 0 || l > maxSeedLength {
+			return apiErrs.NewCustomValidationError("Invalid seed length")
+		}
+		length = l
+	}
+	seed := make([]byte, length)
+	if _, err := rand.Read(seed); err != nil {
+		return errors.Wrap(err, "failed to generate random seed")
+	}
+	if err := trySendJson(w, seedResponse{Seed: base58.Encode(seed)}); err != nil {
+		return errors.Wrap(err, "UtilsSeed")
+	}
+	return nil
+}
+
+type hashResponse struct {
+	Message string        `json:"message"`
+	Hash    crypto.Digest `json:"hash"`
+}
+
+func utilsHash(hash func([]byte) (crypto.Digest, error)) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		message, err := io.ReadAll(io.LimitReader(r.Body, postMessageSizeLimit))
+		if err != nil {
+			return errors.Wrap(err, "failed to read request body")
+		}
+		d, err := hash(message)
+		if err != nil {
+			return errors.Wrap(err, "failed to calculate hash")
+		}
+		if err := trySendJson(w, hashResponse{Message: string(message), Hash: d}); err != nil {
+			return errors.Wrap(err, "utilsHash")
+		}
+		return nil
+	}
+}
+
+func (a *NodeApi) UtilsTime(w http.ResponseWriter, _ *http.Request) error {
+	type timeResponse struct {
+		System int64 `json:"system"`
+		NTP    int64 `json:"NTP"`
+	}
+
+	system := time.Now()
+	ntp := system
+	if t := a.app.services.Time; t != nil {
+		ntp = t.Now()
+	}
+	if err := trySendJson(w, timeResponse{System: system.UnixMilli(), NTP: ntp.UnixMilli()}); err != nil {
+		return errors.Wrap(err, "UtilsTime")
+	}
+	return nil
+}
+
+func (a *NodeApi) UtilsScriptCompileCode(w http.ResponseWriter, r *http.Request) error {
+	type compileResponse struct {
+		Script               proto.Script      `json:"script"`
+		Complexity           uint64            `json:"complexity"`
+		VerifierComplexity   uint64            `json:"verifierComplexity"`
+		CallableComplexities map[string]uint64 `json:"callableComplexities"`
+		ExtraFee             uint64            `json:"extraFee"`
+	}
+
+	var compact bool
+	if c := r.URL.Query().Get("compact"); c != "" {
+		var err error
+		if compact, err = strconv.ParseBool(c); err != nil {
+			return apiErrs.NewCustomValidationError("Invalid 'compact' query parameter")
+		}
+	}
+	code, err := io.ReadAll(io.LimitReader(r.Body, postMessageSizeLimit))
+	if err != nil {
+		return errors.Wrap(err, "failed to read request body")
+	}
+	script, errs := compiler.Compile(string(code), compact, false)
+	if len(errs) > 0 {
+		return newScriptCompilerError(errs)
+	}
+	est, err := a.app.estimateScript(script)
+	if err != nil {
+		return apiErrs.NewScriptCompilerError(err.Error())
+	}
+	out := compileResponse{
+		Script:               script,
+		Complexity:           est.complexity,
+		VerifierComplexity:   est.verifier,
+		CallableComplexities: est.callables,
+		ExtraFee:             est.extraFee,
+	}
+	if err := trySendJson(w, out); err != nil {
+		return errors.Wrap(err, "UtilsScriptCompileCode")
+	}
+	return nil
+}
+
+func (a *NodeApi) UtilsScriptEstimate(w http.ResponseWriter, r *http.Request) error {
+	type estimateResponse struct {
+		Script               proto.Script      `json:"script"`
+		ScriptText           string            `json:"scriptText"`
+		Complexity           uint64            `json:"complexity"`
+		VerifierComplexity   uint64            `json:"verifierComplexity"`
+		CallableComplexities map[string]uint64 `json:"callableComplexities"`
+		ExtraFee             uint64            `json:"extraFee"`
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, postMessageSizeLimit))
+	if err != nil {
+		return errors.Wrap(err, "failed to read request body")
+	}
+	s := strings.TrimPrefix(strings.TrimSpace(string(body)), scriptBase64Prefix)
+	script, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return apiErrs.NewCustomValidationError("Invalid base64 script")
+	}
+	est, err := a.app.estimateScript(script)
+	if err != nil {
+		return apiErrs.NewScriptCompilerError(err.Error())
+	}
+	src, err := decompileScript(script)
+	if err != nil {
+		return apiErrs.NewScriptCompilerError(err.Error())
+	}
+	out := estimateResponse{
+		Script:               script,
+		ScriptText:           src,
+		Complexity:           est.complexity,
+		VerifierComplexity:   est.verifier,
+		CallableComplexities: est.callables,
+		ExtraFee:             est.extraFee,
+	}
+	if err := trySendJson(w, out); err != nil {
+		return errors.Wrap(err, "UtilsScriptEstimate")
+	}
+	return nil
+}
+
+// UtilsScriptDecompile restores the source code of the compiled script given in base64 representation.
+func (a *NodeApi) UtilsScriptDecompile(w http.ResponseWriter, r *http.Request) error {
+	type decompileResponse struct {
+		Script string `json:"script"`
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, postMessageSizeLimit))
+	if err != nil {
+		return errors.Wrap(err, "failed to read request body")
+	}
+	s := strings.TrimPrefix(strings.TrimSpace(string(body)), scriptBase64Prefix)
+	script, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return apiErrs.NewCustomValidationError("Invalid base64 script")
+	}
+	src, err := decompileScript(script)
+	if err != nil {
+		return apiErrs.NewScriptCompilerError(err.Error())
+	}
+	if err := trySendJson(w, decompileResponse{Script: src}); err != nil {
+		return errors.Wrap(err, "UtilsScriptDecompile")
+	}
+	return nil
+}
+
+// UtilsScriptEvaluate evaluates the expression or calls the callable function of the DApp against the current state.
+// State changes produced by the evaluation are returned without application.
+func (a *NodeApi) UtilsScriptEvaluate(w http.ResponseWriter, r *http.Request) error {
+	type evaluateResponse struct {
+		Address      proto.WavesAddress  `json:"address"`
+		Expr         string              `json:"expr,omitempty"`
+		Call         *proto.FunctionCall `json:"call,omitempty"`
+		Result       ride.Value          `json:"result"`
+		Complexity   int                 `json:"complexity"`
+		StateChanges stateChanges        `json:"stateChanges"`
+	}
+
+	addr, err := addressFromURLParam(r, "address")
+	if err != nil {
+		return err
+	}
+	req := scriptEvaluationRequest{}
+	if err := tryParseJson(io.LimitReader(r.Body, postMessageSizeLimit), &req); err != nil {
+		return errors.Wrap(err, "failed to parse UtilsScriptEvaluate request body as JSON")
+	}
+	ev, err := a.app.EvaluateScript(r.Context(), addr, req)
+	if err != nil {
+		return errors.Wrap(err, "UtilsScriptEvaluate")
+	}
+	out := evaluateResponse{
+		Address:      addr,
+		Expr:         req.Expr,
+		Call:         req.Call,
+		Result:       ev.Value,
+		Complexity:   ev.Complexity,
+		StateChanges: newStateChanges(ev.Actions),
+	}
+	if err := trySendJson(w, out); err != nil {
+		return errors.Wrap(err, "UtilsScriptEvaluate")
+	}
+	return nil
+}
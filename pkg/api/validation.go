@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apiErrs "github.com/wavesplatform/gowaves/pkg/api/errors"
+	"github.com/wavesplatform/gowaves/pkg/errs"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+type validationError struct {
+	Type    string `json:"type,omitempty"`
+	Message string `json:"message"`
+}
+
+type feeCheck struct {
+	Fee        uint64              `json:"fee"`
+	FeeAssetID proto.OptionalAsset `json:"feeAssetId"`
+	MinFee     uint64              `json:"minFee"`
+	Sufficient bool                `json:"sufficient"`
+}
+
+// transactionValidation is the result of transaction validation by /debug/validate.
+type transactionValidation struct {
+	Transaction  proto.Transaction `json:"transaction"`
+	Valid        bool              `json:"valid"`
+	Error        *validationError  `json:"error,omitempty"`
+	Fee          feeCheck          `json:"fee"`
+	Complexity   int               `json:"complexity"`
+	Snapshot     proto.TxSnapshot  `json:"snapshot,omitempty"`
+	StateChanges *stateChanges     `json:"stateChanges,omitempty"`
+}
+
+// hasSignature checks that the transaction given as JSON has a signature or non-empty proofs.
+func hasSignature(fields map[string]json.RawMessage) bool {
+	var sig any
+	if err := json.Unmarshal(fields["signature"], &sig); err == nil && sig != nil {
+		return true
+	}
+	var proofs []string
+	if err := json.Unmarshal(fields["proofs"], &proofs); err == nil && len(proofs) > 0 {
+		return true
+	}
+	return false
+}
+
+// ValidateTransaction validates the transaction given as JSON against the newest state adjusted by the transactions
+// of UTX pool. Signatures of the transaction without signature and proofs are not verified.
+// Nothing is changed in the state and the transaction is not broadcast.
+func (a *App) ValidateTransaction(body []byte) (*transactionValidation, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, apiErrs.NewCustomValidationError(fmt.Sprintf("Invalid transaction: %v", err))
+	}
+	tx, err := a.unmarshalTransaction(fields)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Validate(proto.TransactionValidationParams{Scheme: a.services.Scheme}); err != nil {
+		return nil, apiErrs.NewCustomValidationError(fmt.Sprintf("Invalid transaction: %v", err))
+	}
+	var pending []proto.Transaction
+	if a.utx != nil {
+		for _, t := range a.utx.AllTransactions() {
+			pending = append(pending, t.T)
+		}
+	}
+	now := time.Now()
+	if t := a.services.Time; t != nil {
+		now = t.Now()
+	}
+	r, err := state.DryRun(a.state, pending, tx, proto.NewTimestampFromTime(now), hasSignature(fields))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to validate transaction")
+	}
+	out := &transactionValidation{
+		Transaction: tx,
+		Valid:       r.Error == nil,
+		Fee:         feeCheck{Fee: tx.GetFee(), FeeAssetID: tx.GetFeeAsset()},
+		Complexity:  r.Complexity,
+		Snapshot:    r.Snapshots,
+	}
+	if minFee, feeErr := a.state.MinFee(tx); feeErr == nil {
+		out.Fee.MinFee = minFee
+		out.Fee.Sufficient = tx.GetFee() >= minFee
+	}
+	if r.Error != nil {
+		out.Error = &validationError{Type: errs.TypeName(r.Error), Message: r.Error.Error()}
+	}
+	if r.ScriptResult != nil {
+		sc := newStateChanges(r.Actions)
+		out.StateChanges = &sc
+	}
+	return out, nil
+}
+
+// debugValidate validates the transaction without its broadcasting.
+func (a *NodeApi) debugValidate(w http.ResponseWriter, r *http.Request) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, postMessageSizeLimit))
+	if err != nil {
+		return errors.Wrap(err, "failed to read request body")
+	}
+	res, err := a.app.ValidateTransaction(body)
+	if err != nil {
+		return errors.Wrap(err, "debugValidate")
+	}
+	if err := trySendJson(w, res); err != nil {
+		return errors.Wrap(err, "debugValidate")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/errs"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+func TestNodeApi_DebugValidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	s := mock.NewMockState(ctrl)
+	v := mock.NewMockTxValidation(ctrl)
+
+	_, pk, err := crypto.GenerateKeyPair([]byte("debug validate test seed"))
+	require.NoError(t, err)
+	sender := proto.MustAddressFromPublicKey(proto.TestNetScheme, pk)
+	app, err := NewApp("api-key", nil, services.Services{State: s, Scheme: proto.TestNetScheme})
+	require.NoError(t, err)
+	opts := DefaultRunOptions()
+	opts.RateLimiterOpts = nil
+	router, err := NewNodeAPI(app, s).routes(opts)
+	require.NoError(t, err)
+
+	s.EXPECT().BlockchainSettings().Return(settings.MustTestNetSettings(), nil).AnyTimes()
+	s.EXPECT().TopBlock().Return(&proto.Block{BlockHeader: proto.BlockHeader{Timestamp: 1700000000000}}).AnyTimes()
+	s.EXPECT().TxValidation(gomock.Any()).DoAndReturn(func(f func(state.TxValidation) error) error {
+		return f(v)
+	}).AnyTimes()
+	s.EXPECT().MinFee(gomock.Any()).Return(uint64(100000), nil).AnyTimes()
+
+	validate := func(body string) (int, map[string]json.RawMessage) {
+		req := httptest.NewRequest(http.MethodPost, "/debug/validate", strings.NewReader(body))
+		req.Header.Set(apiKey, "api-key")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		var out map[string]json.RawMessage
+		if resp.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &out))
+		}
+		return resp.Code, out
+	}
+	transfer := `{"type":4,"version":2,"senderPublicKey":"` + pk.String() + `","recipient":"` + sender.String() +
+		`","amount":100,"fee":100000,"timestamp":1700000000000`
+
+	t.Run("Valid", func(t *testing.T) {
+		snapshot := []proto.AtomicSnapshot{
+			&proto.TransactionStatusSnapshot{Status: proto.TransactionSucceeded},
+			&proto.WavesBalanceSnapshot{Address: sender, Balance: 1},
+		}
+		v.EXPECT().DryRunNextTx(gomock.Any(), gomock.Any(), uint64(1700000000000), gomock.Any(), false).
+			Return(&state.DryRunResult{Snapshots: snapshot}, nil)
+		code, out := validate(transfer + `}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `true`, string(out["valid"]))
+		assert.JSONEq(t, `{"fee":100000,"feeAssetId":null,"minFee":100000,"sufficient":true}`, string(out["fee"]))
+		assert.Contains(t, string(out["snapshot"]), `"applicationStatus":"succeeded"`)
+		assert.NotContains(t, out, "error")
+		assert.NotContains(t, out, "stateChanges")
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		sig := crypto.MustSignatureFromBase58(
+			"5LdrGwvsZ2dEpYxRudZLkHuswfjXFk5SDrWmqX8SwcvTYpuFhN8DZCjuLNpaUMtK2JLwf6tKAWcvKxXcs67vV5so")
+		v.EXPECT().DryRunNextTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), true).
+			Return(&state.DryRunResult{}, errs.NewAccountBalanceError("negative balance"))
+		code, out := validate(transfer + `,"proofs":["` + sig.String() + `"]}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `false`, string(out["valid"]))
+		assert.JSONEq(t, `{"type":"AccountBalanceError","message":"negative balance"}`, string(out["error"]))
+		assert.NotContains(t, out, "snapshot")
+	})
+	t.Run("BadRequest", func(t *testing.T) {
+		code, _ := validate(`{"type":4,"version":2,"amount":"x"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = validate(`{"type":4,"version":2,"senderPublicKey":"` + pk.String() + `"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
@@ -0,0 +1,538 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/throttled/throttled/v2"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/node/notifications"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+const (
+	wsEventsBufferSize = 1024
+	wsWriteTimeout     = 10 * time.Second
+	wsMaxMessageSize   = 4 * 1024
+	wsMaxSubscriptions = 100
+
+	wsSubscribeMethod   = "subscribe"
+	wsUnsubscribeMethod = "unsubscribe"
+
+	wsBlocksChannel       = "blocks"
+	wsMicroBlocksChannel  = "microblocks"
+	wsRollbacksChannel    = "rollbacks"
+	wsUtxAddedChannel     = "utx-added"
+	wsUtxRemovedChannel   = "utx-removed"
+	wsTransactionsChannel = "transactions"
+)
+
+var errWSRateLimitExceeded = errors.New("rate limit exceeded")
+
+// wsHandler serves the events of the node over WebSocket.
+//
+// Client sends JSON requests {"id": any, "method": "subscribe", "channel": string} to subscribe to one of the
+// channels: "blocks", "microblocks", "rollbacks", "utx-added", "utx-removed" or "transactions". The subscription
+// to "transactions" requires either "address" or "asset" filter and delivers confirmed transactions that touched
+// the address or the asset. The response {"id": any, "subscription": number} contains the ID of subscription,
+// which is used in events {"subscription": number, "channel": string, "data": any} and to unsubscribe with
+// {"id": any, "method": "unsubscribe", "subscription": number}. Failed requests are answered with
+// {"id": any, "error": string}.
+//
+// Requests of clients without valid API key are subject to the rate limiter of the API and the number of their
+// subscriptions is limited. The connection of the client that can't keep up with the events is closed.
+type wsHandler struct {
+	app         *App
+	rateLimiter *throttled.HTTPRateLimiterCtx
+	errHandler  HandleErrorFunc
+	upgrader    websocket.Upgrader
+}
+
+func newWSHandler(app *App, rateLimiter *throttled.HTTPRateLimiterCtx, errHandler HandleErrorFunc) *wsHandler {
+	return &wsHandler{
+		app:         app,
+		rateLimiter: rateLimiter,
+		errHandler:  errHandler,
+		upgrader:    websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+	}
+}
+
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	authorized := false
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		if err := h.app.checkAuth(apiKey); err != nil {
+			h.errHandler(w, r, err)
+			return
+		}
+		authorized = true
+	}
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		zap.S().Debugf("WebSocket API: failed to upgrade connection from '%s': %v", r.RemoteAddr, err)
+		return
+	}
+	conn.SetReadLimit(wsMaxMessageSize)
+	c := &wsConnection{handler: h, conn: conn, authorized: authorized, subs: make(map[uint64]wsSubscription)}
+	if h.rateLimiter != nil && !authorized {
+		c.rateLimitKey = h.rateLimiter.VaryBy.Key(r)
+	}
+	defer c.close()
+	c.serve(r.Context())
+}
+
+type wsRequest struct {
+	ID           json.RawMessage `json:"id,omitempty"`
+	Method       string          `json:"method"`
+	Channel      string          `json:"channel,omitempty"`
+	Address      string          `json:"address,omitempty"`
+	Asset        string          `json:"asset,omitempty"`
+	Subscription uint64          `json:"subscription,omitempty"`
+}
+
+type wsResponse struct {
+	ID           json.RawMessage `json:"id,omitempty"`
+	Subscription uint64          `json:"subscription,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+type wsEvent struct {
+	Subscription uint64 `json:"subscription"`
+	Channel      string `json:"channel"`
+	Data         any    `json:"data"`
+}
+
+type wsMicroBlock struct {
+	Height       proto.Height        `json:"height"`
+	TotalBlockID proto.BlockID       `json:"totalBlockId"`
+	Transactions []proto.Transaction `json:"transactions"`
+}
+
+type wsRollback struct {
+	Height  proto.Height  `json:"height"`
+	BlockID proto.BlockID `json:"blockId"`
+}
+
+type wsTransaction struct {
+	Height      proto.Height      `json:"height"`
+	BlockID     proto.BlockID     `json:"blockId"`
+	Transaction proto.Transaction `json:"transaction"`
+}
+
+type wsSubscription struct {
+	channel string
+	address *proto.WavesAddress
+	asset   *crypto.Digest
+}
+
+func newWSSubscription(req wsRequest) (wsSubscription, error) {
+	s := wsSubscription{channel: req.Channel}
+	switch req.Channel {
+	case wsBlocksChannel, wsMicroBlocksChannel, wsRollbacksChannel, wsUtxAddedChannel, wsUtxRemovedChannel:
+		if req.Address != "" || req.Asset != "" {
+			return wsSubscription{}, errors.Errorf("channel '%s' doesn't support filters", req.Channel)
+		}
+		return s, nil
+	case wsTransactionsChannel:
+		if (req.Address == "") == (req.Asset == "") {
+			return wsSubscription{}, errors.New("either address or asset is required")
+		}
+		if req.Address != "" {
+			addr, err := proto.NewAddressFromString(req.Address)
+			if err != nil {
+				return wsSubscription{}, errors.Wrapf(err, "invalid address '%s'", req.Address)
+			}
+			s.address = &addr
+			return s, nil
+		}
+		asset, err := crypto.NewDigestFromBase58(req.Asset)
+		if err != nil {
+			return wsSubscription{}, errors.Wrapf(err, "invalid asset '%s'", req.Asset)
+		}
+		s.asset = &asset
+		return s, nil
+	default:
+		return wsSubscription{}, errors.Errorf("unsupported channel '%s'", req.Channel)
+	}
+}
+
+func (s wsSubscription) match(t touchedTransaction) bool {
+	if s.address != nil {
+		_, ok := t.addresses[*s.address]
+		return ok
+	}
+	_, ok := t.assets[*s.asset]
+	return ok
+}
+
+type wsConnection struct {
+	handler      *wsHandler
+	conn         *websocket.Conn
+	authorized   bool
+	rateLimitKey string // Key of the client in the rate limiter, empty if requests are not limited.
+	writeMu      sync.Mutex
+
+	mu     sync.Mutex
+	lastID uint64
+	subs   map[uint64]wsSubscription
+	events *notifications.Subscription // Subscription to the hub, nil until the first subscribe.
+}
+
+func (c *wsConnection) serve(ctx context.Context) {
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				zap.S().Debugf("WebSocket API: failed to read message: %v", err)
+			}
+			return
+		}
+		resp, err := json.Marshal(c.handle(ctx, message))
+		if err != nil {
+			zap.S().Debugf("WebSocket API: failed to marshal response: %v", err)
+			return
+		}
+		if err := c.write(resp); err != nil {
+			zap.S().Debugf("WebSocket API: failed to write message: %v", err)
+			return
+		}
+	}
+}
+
+func (c *wsConnection) handle(ctx context.Context, message []byte) wsResponse {
+	var req wsRequest
+	err := json.Unmarshal(message, &req)
+	resp := wsResponse{ID: req.ID}
+	if rlErr := c.checkRateLimit(ctx); rlErr != nil {
+		resp.Error = rlErr.Error()
+		return resp
+	}
+	if err != nil {
+		resp.Error = errors.Wrap(err, "invalid request").Error()
+		return resp
+	}
+	switch req.Method {
+	case wsSubscribeMethod:
+		resp.Subscription, err = c.subscribe(req)
+	case wsUnsubscribeMethod:
+		resp.Subscription, err = c.unsubscribe(req.Subscription)
+	default:
+		err = errors.Errorf("unsupported method '%s'", req.Method)
+	}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+func (c *wsConnection) checkRateLimit(ctx context.Context) error {
+	if c.rateLimitKey == "" {
+		return nil
+	}
+	limited, _, err := c.handler.rateLimiter.RateLimiter.RateLimitCtx(ctx, c.rateLimitKey, 1)
+	if err != nil {
+		return errors.Wrap(err, "failed to check rate limit")
+	}
+	if limited {
+		return errWSRateLimitExceeded
+	}
+	return nil
+}
+
+func (c *wsConnection) subscribe(req wsRequest) (uint64, error) {
+	s, err := newWSSubscription(req)
+	if err != nil {
+		return 0, err
+	}
+	hub := c.handler.app.services.Notifications
+	if hub == nil {
+		return 0, errors.New("events are not supported by the node")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.authorized && len(c.subs) >= wsMaxSubscriptions {
+		return 0, errors.New("too many subscriptions")
+	}
+	if c.events == nil {
+		c.events = hub.Subscribe(wsEventsBufferSize)
+		go c.pump(c.events)
+	}
+	c.lastID++
+	c.subs[c.lastID] = s
+	return c.lastID, nil
+}
+
+func (c *wsConnection) unsubscribe(id uint64) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.subs[id]; !ok {
+		return 0, errors.Errorf("unknown subscription %d", id)
+	}
+	delete(c.subs, id)
+	return id, nil
+}
+
+// pump delivers events to the subscriptions of the connection. The connection is closed if it can't keep up
+// with the events.
+func (c *wsConnection) pump(events *notifications.Subscription) {
+	for e := range events.Events() {
+		if err := c.dispatch(e); err != nil {
+			zap.S().Debugf("WebSocket API: failed to send event: %v", err)
+			_ = c.conn.Close()
+			return
+		}
+	}
+	if err := events.Err(); err != nil {
+		zap.S().Debugf("WebSocket API: subscription terminated: %v", err)
+		c.writeMu.Lock()
+		_ = c.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error()), time.Now().Add(wsWriteTimeout))
+		c.writeMu.Unlock()
+		_ = c.conn.Close()
+	}
+}
+
+func (c *wsConnection) dispatch(e notifications.Event) error {
+	c.mu.Lock()
+	ids := make([]uint64, 0, len(c.subs))
+	subs := make(map[uint64]wsSubscription, len(c.subs))
+	for id, s := range c.subs {
+		ids = append(ids, id)
+		subs[id] = s
+	}
+	c.mu.Unlock()
+	slices.Sort(ids)
+
+	scheme := c.handler.app.services.Scheme
+	var touched []touchedTransaction
+	for _, id := range ids {
+		s := subs[id]
+		var data []any
+		switch ev := e.(type) {
+		case notifications.BlockEvent:
+			switch {
+			case s.channel == wsBlocksChannel && !ev.Micro:
+				b, err := newAPIBlock(ev.Block, scheme, ev.Height)
+				if err != nil {
+					return err
+				}
+				data = append(data, b)
+			case s.channel == wsMicroBlocksChannel && ev.Micro:
+				data = append(data, wsMicroBlock{
+					Height:       ev.Height,
+					TotalBlockID: ev.Block.BlockID(),
+					Transactions: ev.NewTransactions(),
+				})
+			case s.channel == wsTransactionsChannel:
+				if touched == nil {
+					var err error
+					if touched, err = newTouchedTransactions(c.handler.app.state, scheme, ev); err != nil {
+						return err
+					}
+				}
+				for _, t := range touched {
+					if s.match(t) {
+						data = append(data, wsTransaction{
+							Height:      ev.Height,
+							BlockID:     ev.Block.BlockID(),
+							Transaction: t.transaction,
+						})
+					}
+				}
+			}
+		case notifications.RollbackEvent:
+			if s.channel == wsRollbacksChannel {
+				data = append(data, wsRollback{Height: ev.Height, BlockID: ev.BlockID})
+			}
+		case notifications.UtxEvent:
+			if s.channel == wsUtxAddedChannel {
+				data = append(data, ev.Transaction)
+			}
+		case notifications.UtxRemovedEvent:
+			if s.channel == wsUtxRemovedChannel {
+				data = append(data, ev.Transaction)
+			}
+		}
+		for _, d := range data {
+			msg, err := json.Marshal(wsEvent{Subscription: id, Channel: s.channel, Data: d})
+			if err != nil {
+				return err
+			}
+			if err := c.write(msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *wsConnection) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *wsConnection) close() {
+	c.mu.Lock()
+	if c.events != nil {
+		c.handler.app.services.Notifications.Unsubscribe(c.events)
+	}
+	c.mu.Unlock()
+	_ = c.conn.Close()
+}
+
+// touchedTransaction is the transaction with the addresses and assets which were affected by it.
+type touchedTransaction struct {
+	transaction proto.Transaction
+	addresses   map[proto.WavesAddress]struct{}
+	assets      map[crypto.Digest]struct{}
+}
+
+// newTouchedTransactions collects the addresses and assets touched by the transactions added by the event.
+// They are taken from the snapshots of transactions, if the snapshots are not available because the state has
+// already changed, only the senders of transactions are collected.
+func newTouchedTransactions(
+	st state.StateInfo,
+	scheme proto.Scheme,
+	ev notifications.BlockEvent,
+) ([]touchedTransaction, error) {
+	txs := ev.NewTransactions()
+	var snapshots [][]proto.AtomicSnapshot
+	if bs, err := st.SnapshotsAtHeight(ev.Height); err == nil && len(bs.TxSnapshots) == len(ev.Block.Transactions) {
+		snapshots = bs.TxSnapshots[ev.First:]
+	} else {
+		zap.S().Debugf("WebSocket API: snapshots of block '%s' are not available", ev.Block.BlockID().String())
+	}
+	r := make([]touchedTransaction, len(txs))
+	for i, tx := range txs {
+		c := &touchedCollector{
+			scheme:    scheme,
+			addresses: make(map[proto.WavesAddress]struct{}),
+			assets:    make(map[crypto.Digest]struct{}),
+		}
+		sender, err := tx.GetSender(scheme)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get transaction sender")
+		}
+		if err := c.addAddress(sender); err != nil {
+			return nil, err
+		}
+		if snapshots != nil {
+			for _, s := range snapshots[i] {
+				if err := s.Apply(c); err != nil {
+					return nil, errors.Wrap(err, "failed to collect touched addresses and assets")
+				}
+			}
+		}
+		r[i] = touchedTransaction{transaction: tx, addresses: c.addresses, assets: c.assets}
+	}
+	return r, nil
+}
+
+// touchedCollector implements proto.SnapshotApplier to collect the addresses and assets changed by snapshots.
+type touchedCollector struct {
+	scheme    proto.Scheme
+	addresses map[proto.WavesAddress]struct{}
+	assets    map[crypto.Digest]struct{}
+}
+
+func (c *touchedCollector) addAddress(addr proto.Address) error {
+	wa, err := addr.ToWavesAddress(c.scheme)
+	if err != nil {
+		return errors.Wrap(err, "failed to convert address")
+	}
+	c.addresses[wa] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) addPublicKey(pk crypto.PublicKey) error {
+	addr, err := proto.NewAddressFromPublicKey(c.scheme, pk)
+	if err != nil {
+		return errors.Wrap(err, "failed to create address from public key")
+	}
+	c.addresses[addr] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplyWavesBalance(s proto.WavesBalanceSnapshot) error {
+	c.addresses[s.Address] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplyLeaseBalance(s proto.LeaseBalanceSnapshot) error {
+	c.addresses[s.Address] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplyAssetBalance(s proto.AssetBalanceSnapshot) error {
+	c.addresses[s.Address] = struct{}{}
+	c.assets[s.AssetID] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplyAlias(s proto.AliasSnapshot) error {
+	c.addresses[s.Address] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplyNewAsset(s proto.NewAssetSnapshot) error {
+	c.assets[s.AssetID] = struct{}{}
+	return c.addPublicKey(s.IssuerPublicKey)
+}
+
+func (c *touchedCollector) ApplyAssetDescription(s proto.AssetDescriptionSnapshot) error {
+	c.assets[s.AssetID] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplyAssetVolume(s proto.AssetVolumeSnapshot) error {
+	c.assets[s.AssetID] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplyAssetScript(s proto.AssetScriptSnapshot) error {
+	c.assets[s.AssetID] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplySponsorship(s proto.SponsorshipSnapshot) error {
+	c.assets[s.AssetID] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplyAccountScript(s proto.AccountScriptSnapshot) error {
+	return c.addPublicKey(s.SenderPublicKey)
+}
+
+func (c *touchedCollector) ApplyFilledVolumeAndFee(proto.FilledVolumeFeeSnapshot) error {
+	return nil
+}
+
+func (c *touchedCollector) ApplyDataEntries(s proto.DataEntriesSnapshot) error {
+	c.addresses[s.Address] = struct{}{}
+	return nil
+}
+
+func (c *touchedCollector) ApplyNewLease(s proto.NewLeaseSnapshot) error {
+	c.addresses[s.RecipientAddr] = struct{}{}
+	return c.addPublicKey(s.SenderPK)
+}
+
+func (c *touchedCollector) ApplyCancelledLease(proto.CancelledLeaseSnapshot) error {
+	return nil
+}
+
+func (c *touchedCollector) ApplyTransactionsStatus(proto.TransactionStatusSnapshot) error {
+	return nil
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/node/notifications"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/types"
+)
+
+type wsStubApplier struct {
+	notifications.BlocksApplier
+}
+
+func (wsStubApplier) Apply(state.State, []*proto.Block) (proto.Height, error) {
+	return 0, nil
+}
+
+// wsStubUtxPool accepts all transactions and forgets them immediately.
+type wsStubUtxPool struct {
+	types.UtxPool
+}
+
+func (wsStubUtxPool) Add(proto.Transaction) error {
+	return nil
+}
+
+func (wsStubUtxPool) ExistsByID([]byte) bool {
+	return false
+}
+
+type wsTestClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+func dialWS(t *testing.T, server *httptest.Server, header http.Header) (*wsTestClient, error) {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &wsTestClient{t: t, conn: conn}, nil
+}
+
+func (c *wsTestClient) read() map[string]json.RawMessage {
+	require.NoError(c.t, c.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, msg, err := c.conn.ReadMessage()
+	require.NoError(c.t, err)
+	var out map[string]json.RawMessage
+	require.NoError(c.t, json.Unmarshal(msg, &out))
+	return out
+}
+
+func (c *wsTestClient) call(req string) map[string]json.RawMessage {
+	require.NoError(c.t, c.conn.WriteMessage(websocket.TextMessage, []byte(req)))
+	return c.read()
+}
+
+func TestWSHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	st := mock.NewMockState(ctrl)
+	scheme := proto.TestNetScheme
+
+	_, senderPK, err := crypto.GenerateKeyPair([]byte("ws sender"))
+	require.NoError(t, err)
+	_, recipientPK, err := crypto.GenerateKeyPair([]byte("ws recipient"))
+	require.NoError(t, err)
+	recipient := proto.MustAddressFromPublicKey(scheme, recipientPK)
+	tx := proto.NewUnsignedTransferWithProofs(2, senderPK, proto.NewOptionalAssetWaves(), proto.NewOptionalAssetWaves(),
+		1, 1, 100000, proto.NewRecipientFromAddress(recipient), nil)
+	txID, err := tx.GetID(scheme)
+	require.NoError(t, err)
+
+	genesis := &proto.Block{BlockHeader: proto.BlockHeader{BlockSignature: crypto.Signature{1}}}
+	block := &proto.Block{
+		BlockHeader: proto.BlockHeader{
+			Version:            proto.RewardBlockVersion,
+			BlockSignature:     crypto.Signature{2},
+			Parent:             genesis.BlockID(),
+			GeneratorPublicKey: senderPK,
+		},
+		Transactions: proto.Transactions{tx},
+	}
+	blocks := []*proto.Block{genesis}
+	st.EXPECT().Height().DoAndReturn(func() (proto.Height, error) {
+		return proto.Height(len(blocks)), nil
+	}).AnyTimes()
+	st.EXPECT().TopBlock().DoAndReturn(func() *proto.Block { return blocks[len(blocks)-1] }).AnyTimes()
+	st.EXPECT().BlockIDToHeight(genesis.BlockID()).Return(proto.Height(1), nil).AnyTimes()
+	st.EXPECT().SnapshotsAtHeight(proto.Height(2)).Return(proto.BlockSnapshot{
+		TxSnapshots: [][]proto.AtomicSnapshot{{&proto.WavesBalanceSnapshot{Address: recipient, Balance: 1}}},
+	}, nil).AnyTimes()
+
+	hub := notifications.NewHub()
+	applier, err := notifications.NewApplier(wsStubApplier{}, hub, st)
+	require.NoError(t, err)
+	utx := notifications.NewUtxPool(wsStubUtxPool{}, hub, scheme)
+
+	app, err := newApp("", nil, services.Services{State: st, Scheme: scheme, Notifications: hub}, nil)
+	require.NoError(t, err)
+	server := httptest.NewServer(newWSHandler(app, nil, wsErrorHandler()))
+	defer server.Close()
+	c, err := dialWS(t, server, nil)
+	require.NoError(t, err)
+
+	resp := c.call(`{"id":1,"method":"subscribe","channel":"unknown"}`)
+	assert.Contains(t, string(resp["error"]), "unsupported channel")
+	resp = c.call(`{"id":2,"method":"subscribe","channel":"transactions"}`)
+	assert.Contains(t, string(resp["error"]), "either address or asset is required")
+	resp = c.call(`{"id":3,"method":"subscribe","channel":"blocks","asset":"WAVES"}`)
+	assert.Contains(t, string(resp["error"]), "doesn't support filters")
+
+	resp = c.call(`{"id":"a","method":"subscribe","channel":"transactions","address":"` + recipient.String() + `"}`)
+	assert.JSONEq(t, `{"id":"a","subscription":1}`, string(mustMarshal(t, resp)))
+	other := proto.MustAddressFromPublicKey(scheme, genesis.GeneratorPublicKey)
+	resp = c.call(`{"id":"b","method":"subscribe","channel":"transactions","address":"` + other.String() + `"}`)
+	assert.JSONEq(t, `2`, string(resp["subscription"]))
+	resp = c.call(`{"id":"c","method":"subscribe","channel":"blocks"}`)
+	assert.JSONEq(t, `3`, string(resp["subscription"]))
+	resp = c.call(`{"id":"d","method":"subscribe","channel":"utx-added"}`)
+	assert.JSONEq(t, `4`, string(resp["subscription"]))
+	resp = c.call(`{"id":"e","method":"subscribe","channel":"utx-removed"}`)
+	assert.JSONEq(t, `5`, string(resp["subscription"]))
+
+	blocks = append(blocks, block)
+	_, err = applier.Apply(st, []*proto.Block{block})
+	require.NoError(t, err)
+	e := c.read()
+	assert.JSONEq(t, `1`, string(e["subscription"]))
+	assert.JSONEq(t, `"transactions"`, string(e["channel"]))
+	var confirmed struct {
+		Height      proto.Height  `json:"height"`
+		BlockID     proto.BlockID `json:"blockId"`
+		Transaction struct {
+			ID crypto.Digest `json:"id"`
+		} `json:"transaction"`
+	}
+	require.NoError(t, json.Unmarshal(e["data"], &confirmed))
+	assert.Equal(t, proto.Height(2), confirmed.Height)
+	assert.Equal(t, block.BlockID(), confirmed.BlockID)
+	assert.Equal(t, txID, confirmed.Transaction.ID.Bytes())
+	e = c.read()
+	assert.JSONEq(t, `3`, string(e["subscription"]))
+	var b struct {
+		Height    proto.Height  `json:"height"`
+		Reference proto.BlockID `json:"reference"`
+	}
+	require.NoError(t, json.Unmarshal(e["data"], &b))
+	assert.Equal(t, proto.Height(2), b.Height)
+	assert.Equal(t, genesis.BlockID(), b.Reference)
+
+	require.NoError(t, utx.Add(tx))
+	e = c.read()
+	assert.JSONEq(t, `4`, string(e["subscription"]))
+	utx.PublishRemoved()
+	e = c.read()
+	assert.JSONEq(t, `5`, string(e["subscription"]))
+	assert.JSONEq(t, `"utx-removed"`, string(e["channel"]))
+
+	resp = c.call(`{"id":6,"method":"unsubscribe","subscription":3}`)
+	assert.JSONEq(t, `{"id":6,"subscription":3}`, string(mustMarshal(t, resp)))
+	resp = c.call(`{"id":7,"method":"unsubscribe","subscription":3}`)
+	assert.Contains(t, string(resp["error"]), "unknown subscription")
+}
+
+func TestWSHandlerRateLimitAndAuth(t *testing.T) {
+	rl, err := createRateLimiter(&RateLimiterOptions{MemoryCacheSize: 10, MaxRequestsPerSecond: 1, MaxBurst: 1})
+	require.NoError(t, err)
+	app, err := newApp("secret", nil, services.Services{Scheme: proto.TestNetScheme}, nil)
+	require.NoError(t, err)
+	server := httptest.NewServer(newWSHandler(app, &rl, wsErrorHandler()))
+	defer server.Close()
+
+	_, err = dialWS(t, server, http.Header{"X-API-Key": []string{"wrong"}})
+	assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+
+	c, err := dialWS(t, server, nil)
+	require.NoError(t, err)
+	var limited bool
+	for range 5 {
+		resp := c.call(`{"id":1,"method":"unsubscribe","subscription":1}`)
+		if strings.Contains(string(resp["error"]), errWSRateLimitExceeded.Error()) {
+			limited = true
+			break
+		}
+	}
+	assert.True(t, limited)
+
+	authorized, err := dialWS(t, server, http.Header{"X-API-Key": []string{"secret"}})
+	require.NoError(t, err)
+	for range 5 {
+		resp := authorized.call(`{"id":1,"method":"unsubscribe","subscription":1}`)
+		assert.Contains(t, string(resp["error"]), "unknown subscription")
+	}
+}
+
+func wsErrorHandler() HandleErrorFunc {
+	h := NewErrorHandler(zap.NewNop())
+	return h.Handle
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return b
+}
@@ -239,6 +239,35 @@ func (a *Addresses) BalanceAfterConfirmations(
 	return out, response, nil
 }
 
+type BalanceAtHeight struct {
+	Address proto.WavesAddress `json:"address"`
+	Height  proto.Height       `json:"height"`
+	Balance uint64             `json:"balance"`
+}
+
+// BalanceAtHeight returns balance of an address as of the given height within the rollback window of node.
+func (a *Addresses) BalanceAtHeight(
+	ctx context.Context, address proto.WavesAddress, height proto.Height) (*BalanceAtHeight, *Response, error) {
+
+	u, err := joinUrl(a.options.BaseUrl, fmt.Sprintf("/addresses/balance/%s?height=%d", address.String(), height))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := new(BalanceAtHeight)
+	response, err := doHttp(ctx, a.options, req, out)
+	if err != nil {
+		return nil, response, err
+	}
+
+	return out, response, nil
+}
+
 type AddressesDataParams struct {
 	matches string
 	keys    []string
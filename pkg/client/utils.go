@@ -206,3 +206,28 @@ func (a *Utils) ScriptEstimate(ctx context.Context, base64code string) (*UtilsSc
 
 	return out, response, nil
 }
+
+type UtilsScriptDecompile struct {
+	Script string `json:"script"`
+}
+
+// ScriptDecompile returns the source code of compiled script in base64 representation.
+func (a *Utils) ScriptDecompile(ctx context.Context, base64code string) (*UtilsScriptDecompile, *Response, error) {
+	url, err := joinUrl(a.options.BaseUrl, "/utils/script/decompile")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequest("POST", url.String(), strings.NewReader(base64code))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := new(UtilsScriptDecompile)
+	response, err := doHttp(ctx, a.options, req, out)
+	if err != nil {
+		return nil, response, err
+	}
+
+	return out, response, nil
+}
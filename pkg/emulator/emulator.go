@@ -0,0 +1,266 @@
+// Package emulator provides the blockchain emulated in process for testing of RIDE scripts.
+//
+// Emulator wraps the real state with custom blockchain settings. Blocks are mined instantly by the single generator,
+// which owns all Waves of genesis block, without any networking. The time of emulated blockchain is virtual,
+// it's advanced by the generation delays of mined blocks.
+package emulator
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/miner"
+	"github.com/wavesplatform/gowaves/pkg/miner/scheduler"
+	"github.com/wavesplatform/gowaves/pkg/node/blocks_applier"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/types"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+const (
+	// GeneratorBalance is the amount of Waves owned by the generator in genesis block.
+	GeneratorBalance = 100_000_000 * proto.PriceConstant
+	// GenesisTimestamp is the timestamp of genesis block, the virtual time of emulated blockchain starts here.
+	GenesisTimestamp = proto.Timestamp(1_700_000_000_000)
+
+	genesisBaseTarget = 1000
+	generatorSeed     = "emulator generator"
+	accountSeedFormat = "emulator account %d"
+	noRewardVote      = -1
+)
+
+// clock is the virtual time of emulated blockchain.
+type clock struct {
+	now atomic.Uint64
+}
+
+func (c *clock) Now() time.Time {
+	return time.UnixMilli(int64(c.now.Load()))
+}
+
+func (c *clock) set(ts proto.Timestamp) {
+	c.now.Store(ts)
+}
+
+// Account is the account of emulated blockchain.
+type Account struct {
+	proto.KeyPair
+	Address proto.WavesAddress
+}
+
+func newAccount(scheme proto.Scheme, seed []byte) (Account, error) {
+	kp, err := proto.NewKeyPair(seed)
+	if err != nil {
+		return Account{}, errors.Wrap(err, "failed to create key pair")
+	}
+	addr, err := kp.Addr(scheme)
+	if err != nil {
+		return Account{}, errors.Wrap(err, "failed to create address")
+	}
+	return Account{KeyPair: kp, Address: addr}, nil
+}
+
+// Emulator is the blockchain emulated in process. It's not safe for concurrent use.
+type Emulator struct {
+	settings  *settings.BlockchainSettings
+	state     state.State
+	clock     *clock
+	pool      *pool
+	generator Account
+	signer    types.EmbeddedWallet
+	accounts  int
+	txTime    proto.Timestamp // The last timestamp returned by Now.
+
+	applier    *blocks_applier.BlocksApplier
+	keyMiner   *miner.MicroblockMiner
+	microMiner *miner.MicroMiner
+}
+
+// New creates the emulated blockchain with the state in the given directory. If the settings are nil,
+// DefaultSettings are used. The genesis block of the settings is replaced by the block funding the generator.
+func New(dataDir string, bs *settings.BlockchainSettings) (*Emulator, error) {
+	if bs == nil {
+		bs = DefaultSettings()
+	}
+	generator, err := newAccount(bs.AddressSchemeCharacter, []byte(generatorSeed))
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := newGenesisBlock(bs.AddressSchemeCharacter, generator.Address)
+	if err != nil {
+		return nil, err
+	}
+	bs.Genesis = *genesis
+
+	c := &clock{}
+	c.set(GenesisTimestamp)
+	params := state.DefaultTestingStateParams()
+	params.Time = c
+	params.StoreExtendedApiData = true
+	params.ProvideExtendedApi = true
+	st, err := state.NewState(dataDir, false, params, bs, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create state")
+	}
+	p := newPool(bs.AddressSchemeCharacter)
+	w := wallet.NewEmbeddedWallet(nil, wallet.Stub{S: [][]byte{[]byte(generatorSeed)}}, bs.AddressSchemeCharacter)
+	s := services.Services{State: st, UtxPool: p, Scheme: bs.AddressSchemeCharacter, Time: c, Wallet: w}
+	return &Emulator{
+		settings:   bs,
+		state:      st,
+		clock:      c,
+		pool:       p,
+		generator:  generator,
+		signer:     w,
+		txTime:     GenesisTimestamp,
+		applier:    blocks_applier.NewBlocksApplier(),
+		keyMiner:   miner.NewMicroblockMiner(s, nil, noRewardVote),
+		microMiner: miner.NewMicroMiner(s),
+	}, nil
+}
+
+// Close closes the state of emulated blockchain.
+func (e *Emulator) Close() error {
+	return e.state.Close()
+}
+
+// State returns the state of emulated blockchain. It must not be modified directly.
+func (e *Emulator) State() state.State {
+	return e.state
+}
+
+// Scheme returns the address scheme of emulated blockchain.
+func (e *Emulator) Scheme() proto.Scheme {
+	return e.settings.AddressSchemeCharacter
+}
+
+// Generator returns the account that mines all blocks and funds new accounts.
+func (e *Emulator) Generator() Account {
+	return e.generator
+}
+
+// Height returns the height of emulated blockchain.
+func (e *Emulator) Height() (proto.Height, error) {
+	return e.state.Height()
+}
+
+// Now returns the timestamp for a new transaction. Timestamps are increased on each call, so the same
+// transactions created one after another get different IDs.
+func (e *Emulator) Now() proto.Timestamp {
+	e.txTime = max(e.txTime+1, uint64(e.clock.Now().UnixMilli()))
+	return e.txTime
+}
+
+// NewAccount creates new account funded by the generator with the given amount of Waves.
+// The funding transaction is mined in the new block immediately.
+func (e *Emulator) NewAccount(balance uint64) (Account, error) {
+	e.accounts++
+	a, err := newAccount(e.Scheme(), fmt.Appendf(nil, accountSeedFormat, e.accounts))
+	if err != nil {
+		return Account{}, err
+	}
+	if balance == 0 {
+		return a, nil
+	}
+	tx, err := e.Transfer(e.generator, a.Address, balance)
+	if err != nil {
+		return Account{}, err
+	}
+	if err := e.Execute(tx); err != nil {
+		return Account{}, errors.Wrapf(err, "failed to fund account '%s'", a.Address.String())
+	}
+	return a, nil
+}
+
+// Submit validates the transaction against the state and the pending transactions and adds it to the pending
+// transactions, which are put into the next mined block.
+func (e *Emulator) Submit(tx proto.Transaction) error {
+	r, err := state.DryRun(e.state, e.pool.transactions(), tx, uint64(e.clock.Now().UnixMilli()), true)
+	if err != nil {
+		return errors.Wrap(err, "failed to validate transaction")
+	}
+	if r.Error != nil {
+		return r.Error
+	}
+	return e.pool.Add(tx)
+}
+
+// Execute submits the transactions and mines the block with them.
+func (e *Emulator) Execute(txs ...proto.Transaction) error {
+	for _, tx := range txs {
+		if err := e.Submit(tx); err != nil {
+			return err
+		}
+	}
+	_, err := e.Mine()
+	return err
+}
+
+// Mine mines the new key block and puts all pending transactions into it with micro blocks, like the node does.
+// The virtual time is advanced to the timestamp of the block. The error is returned if some of the pending
+// transactions can't be put into the block, they are dropped.
+func (e *Emulator) Mine() (*proto.Block, error) {
+	height, err := e.state.Height()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get height")
+	}
+	top := e.state.TopBlock()
+	emits, err := scheduler.Schedule(e.state, e.signer, []crypto.PublicKey{e.generator.Public}, e.settings, top, height)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to schedule block generation")
+	}
+	if len(emits) == 0 {
+		return nil, errors.New("generator can't mine blocks")
+	}
+	emit := emits[0]
+	e.clock.set(emit.Timestamp)
+	block, limits, err := e.keyMiner.MineKeyBlock(context.Background(), emit.Timestamp, emit.PublicKey, emit.Parent,
+		emit.BaseTarget, emit.GenSignature, emit.VRF)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to mine key block")
+	}
+	if _, err := e.applier.Apply(e.state, []*proto.Block{block}); err != nil {
+		return nil, errors.Wrapf(err, "failed to apply key block '%s'", block.BlockID().String())
+	}
+	for e.pool.Count() > 0 {
+		b, _, rest, mErr := e.microMiner.Micro(block, limits, e.generator.Public)
+		if errors.Is(mErr, miner.NoTransactionsErr) {
+			break
+		}
+		if mErr != nil {
+			return nil, errors.Wrap(mErr, "failed to mine micro block")
+		}
+		if _, err := e.applier.ApplyMicro(e.state, b); err != nil {
+			return nil, errors.Wrapf(err, "failed to apply micro block '%s'", b.BlockID().String())
+		}
+		block, limits = b, rest
+	}
+	if rejected := e.pool.clear(); len(rejected) > 0 {
+		return block, e.rejectionError(rejected)
+	}
+	return block, nil
+}
+
+func (e *Emulator) rejectionError(rejected []proto.Transaction) error {
+	ids := make([]string, len(rejected))
+	for i, tx := range rejected {
+		id, err := tx.GetID(e.Scheme())
+		if err != nil {
+			return errors.Wrap(err, "failed to get ID of rejected transaction")
+		}
+		d, err := crypto.NewDigestFromBytes(id)
+		if err != nil {
+			return errors.Wrap(err, "invalid ID of rejected transaction")
+		}
+		ids[i] = d.String()
+	}
+	return errors.Errorf("transactions %v were not put into the block", ids)
+}
@@ -0,0 +1,69 @@
+package emulator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const testDApp = `
+{-# STDLIB_VERSION 6 #-}
+{-# CONTENT_TYPE DAPP #-}
+{-# SCRIPT_TYPE ACCOUNT #-}
+
+@Callable(i)
+func deposit() = {
+  let amount = i.payments[0].amount
+  let key = toString(i.caller)
+  let balance = getInteger(this, key).valueOrElse(0)
+  [IntegerEntry(key, balance + amount)]
+}
+
+@Callable(i)
+func withdraw(amount: Int) = {
+  let key = toString(i.caller)
+  let balance = getInteger(this, key).valueOrElse(0)
+  if (amount > balance) then throw("not enough funds") else
+  [IntegerEntry(key, balance - amount), ScriptTransfer(i.caller, amount, unit)]
+}
+`
+
+func TestEmulator(t *testing.T) {
+	e, err := New(t.TempDir(), nil)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, e.Close()) }()
+
+	dApp, err := e.NewAccount(10 * proto.PriceConstant)
+	require.NoError(t, err)
+	user, err := e.NewAccount(10 * proto.PriceConstant)
+	require.NoError(t, err)
+	require.NoError(t, e.SetScript(dApp, testDApp))
+
+	payment := proto.ScriptPayments{{Amount: 3 * proto.PriceConstant, Asset: proto.NewOptionalAssetWaves()}}
+	r, err := e.Invoke(user, dApp.Address, proto.NewFunctionCall("deposit", nil), payment)
+	require.NoError(t, err)
+	require.Empty(t, r.ErrorMsg.Text)
+	entry, err := e.DataEntry(dApp.Address, user.Address.String())
+	require.NoError(t, err)
+	assert.Equal(t, &proto.IntegerDataEntry{Key: user.Address.String(), Value: 3 * proto.PriceConstant}, entry)
+
+	args := proto.Arguments{proto.NewIntegerArgument(proto.PriceConstant)}
+	r, err = e.Invoke(user, dApp.Address, proto.NewFunctionCall("withdraw", args), nil)
+	require.NoError(t, err)
+	require.Len(t, r.Transfers, 1)
+	assert.Equal(t, int64(proto.PriceConstant), r.Transfers[0].Amount)
+	balance, err := e.WavesBalance(user.Address)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8*proto.PriceConstant-2*proto.MinFeeInvokeScript), balance)
+
+	args = proto.Arguments{proto.NewIntegerArgument(10 * proto.PriceConstant)}
+	_, err = e.Invoke(user, dApp.Address, proto.NewFunctionCall("withdraw", args), nil)
+	assert.ErrorContains(t, err, "not enough funds")
+
+	tx, err := e.Transfer(user, dApp.Address, 100*proto.PriceConstant)
+	require.NoError(t, err)
+	assert.Error(t, e.Submit(tx))
+}
@@ -0,0 +1,95 @@
+package emulator
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/types"
+)
+
+// pool is the pending transactions of emulator. Unlike the node's UTX pool it keeps the order of submission,
+// so transactions are put into blocks in the order they were submitted.
+type pool struct {
+	scheme proto.Scheme
+	txs    []*types.TransactionWithBytes
+}
+
+func newPool(scheme proto.Scheme) *pool {
+	return &pool{scheme: scheme}
+}
+
+func (p *pool) Add(t proto.Transaction) error {
+	b, err := proto.MarshalTx(p.scheme, t)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal transaction")
+	}
+	return p.AddWithBytes(t, b)
+}
+
+func (p *pool) AddWithBytes(t proto.Transaction, b []byte) error {
+	if p.Exists(t) {
+		return errors.New("transaction is already pending")
+	}
+	p.txs = append(p.txs, &types.TransactionWithBytes{T: t, B: b})
+	return nil
+}
+
+func (p *pool) Exists(t proto.Transaction) bool {
+	id, err := t.GetID(p.scheme)
+	if err != nil {
+		return false
+	}
+	return p.ExistsByID(id)
+}
+
+func (p *pool) Pop() *types.TransactionWithBytes {
+	if len(p.txs) == 0 {
+		return nil
+	}
+	t := p.txs[0]
+	p.txs = p.txs[1:]
+	return t
+}
+
+func (p *pool) AllTransactions() []*types.TransactionWithBytes {
+	return append([]*types.TransactionWithBytes(nil), p.txs...)
+}
+
+func (p *pool) Count() int {
+	return len(p.txs)
+}
+
+func (p *pool) ExistsByID(id []byte) bool {
+	for _, t := range p.txs {
+		if tid, err := t.T.GetID(p.scheme); err == nil && bytes.Equal(tid, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *pool) Details() types.UtxPoolDetails {
+	var d types.UtxPoolDetails
+	for _, t := range p.txs {
+		d.Size += uint64(len(t.B))
+	}
+	return d
+}
+
+// transactions returns the pending transactions in the order of submission.
+func (p *pool) transactions() []proto.Transaction {
+	txs := make([]proto.Transaction, len(p.txs))
+	for i, t := range p.txs {
+		txs[i] = t.T
+	}
+	return txs
+}
+
+// clear drops all pending transactions and returns them.
+func (p *pool) clear() []proto.Transaction {
+	txs := p.transactions()
+	p.txs = nil
+	return txs
+}
@@ -0,0 +1,39 @@
+package emulator
+
+import (
+	"sort"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/util/genesis_generator"
+)
+
+const (
+	defaultMinBlockTime = 5000
+	defaultDelayDelta   = 8
+)
+
+// DefaultSettings returns the settings of custom blockchain with all implemented features activated at genesis.
+func DefaultSettings() *settings.BlockchainSettings {
+	s := settings.MustDefaultCustomSettings()
+	s.MinBlockTime = defaultMinBlockTime
+	s.DelayDelta = defaultDelayDelta
+	s.FeaturesVotingPeriod = 1
+	s.VotesForFeatureActivation = 1
+	features := make([]int16, 0, len(settings.FeaturesInfo))
+	for f, info := range settings.FeaturesInfo {
+		if info.Implemented {
+			features = append(features, int16(f))
+		}
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i] < features[j] })
+	s.PreactivatedFeatures = features
+	return s
+}
+
+func newGenesisBlock(scheme proto.Scheme, generator proto.WavesAddress) (*proto.Block, error) {
+	txs := []genesis_generator.GenesisTransactionInfo{
+		{Address: generator, Amount: GeneratorBalance, Timestamp: GenesisTimestamp},
+	}
+	return genesis_generator.GenerateGenesisBlock(scheme, txs, genesisBaseTarget, GenesisTimestamp)
+}
@@ -0,0 +1,106 @@
+package emulator
+
+import (
+	stderrs "errors"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler"
+)
+
+// build creates the transaction with the minimal fee in Waves according to the current state and signs it
+// by the sender. The builder is called twice, the first time with zero fee to calculate the minimal one.
+func (e *Emulator) build(sender Account, builder func(fee uint64) proto.Transaction) (proto.Transaction, error) {
+	fee, err := e.state.MinFee(builder(0))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to calculate fee")
+	}
+	tx := builder(fee)
+	if err := tx.Sign(e.Scheme(), sender.Secret); err != nil {
+		return nil, errors.Wrap(err, "failed to sign transaction")
+	}
+	return tx, nil
+}
+
+// Transfer creates the transfer of Waves with the minimal fee.
+func (e *Emulator) Transfer(from Account, to proto.WavesAddress, amount uint64) (proto.Transaction, error) {
+	ts := e.Now()
+	waves := proto.NewOptionalAssetWaves()
+	return e.build(from, func(fee uint64) proto.Transaction {
+		return proto.NewUnsignedTransferWithProofs(3, from.Public, waves, waves, ts, amount, fee,
+			proto.NewRecipientFromAddress(to), nil)
+	})
+}
+
+// SetScriptTx creates the transaction setting the script compiled from the RIDE source code on the account.
+func (e *Emulator) SetScriptTx(account Account, src string) (proto.Transaction, error) {
+	script, errs := compiler.Compile(src, false, false)
+	if len(errs) > 0 {
+		return nil, errors.Wrap(stderrs.Join(errs...), "failed to compile script")
+	}
+	ts := e.Now()
+	return e.build(account, func(fee uint64) proto.Transaction {
+		return proto.NewUnsignedSetScriptWithProofs(2, account.Public, script, fee, ts)
+	})
+}
+
+// SetScript compiles the RIDE source code and sets the script on the account in the new block.
+func (e *Emulator) SetScript(account Account, src string) error {
+	tx, err := e.SetScriptTx(account, src)
+	if err != nil {
+		return err
+	}
+	return e.Execute(tx)
+}
+
+// InvokeTx creates the invocation of the DApp function with the minimal fee. The fee doesn't include
+// the fee for assets issued by the DApp, the transaction must be built by hand for such invocations.
+func (e *Emulator) InvokeTx(
+	caller Account, dApp proto.WavesAddress, call proto.FunctionCall, payments proto.ScriptPayments,
+) (proto.Transaction, error) {
+	ts := e.Now()
+	return e.build(caller, func(fee uint64) proto.Transaction {
+		return proto.NewUnsignedInvokeScriptWithProofs(2, caller.Public, proto.NewRecipientFromAddress(dApp), call,
+			payments, proto.NewOptionalAssetWaves(), fee, ts)
+	})
+}
+
+// Invoke invokes the DApp function in the new block and returns the result of invocation.
+// Like the node's UTX pool, the emulator rejects failing invocations, so the error of invocation is returned.
+func (e *Emulator) Invoke(
+	caller Account, dApp proto.WavesAddress, call proto.FunctionCall, payments proto.ScriptPayments,
+) (*proto.ScriptResult, error) {
+	tx, err := e.InvokeTx(caller, dApp, call, payments)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.Execute(tx); err != nil {
+		return nil, err
+	}
+	id, err := tx.GetID(e.Scheme())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction ID")
+	}
+	d, err := crypto.NewDigestFromBytes(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid transaction ID")
+	}
+	return e.state.InvokeResultByID(d)
+}
+
+// WavesBalance returns the available Waves balance of the address.
+func (e *Emulator) WavesBalance(addr proto.WavesAddress) (uint64, error) {
+	return e.state.WavesBalance(proto.NewRecipientFromAddress(addr))
+}
+
+// AssetBalance returns the balance of the asset on the address.
+func (e *Emulator) AssetBalance(addr proto.WavesAddress, asset crypto.Digest) (uint64, error) {
+	return e.state.AssetBalance(proto.NewRecipientFromAddress(addr), proto.AssetIDFromDigest(asset))
+}
+
+// DataEntry returns the data entry of the account by the key.
+func (e *Emulator) DataEntry(addr proto.WavesAddress, key string) (proto.DataEntry, error) {
+	return e.state.RetrieveEntry(proto.NewRecipientFromAddress(addr), key)
+}
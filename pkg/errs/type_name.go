@@ -0,0 +1,28 @@
+package errs
+
+import "reflect"
+
+var pkgPath = reflect.TypeOf(TxValidationError{}).PkgPath()
+
+// TypeName returns the name of the type of the first error from this package in the chain of wrapped errors.
+// The empty string is returned if there is no such error in the chain.
+func TypeName(err error) string {
+	for err != nil {
+		t := reflect.TypeOf(err)
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.PkgPath() == pkgPath {
+			return t.Name()
+		}
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Cause() error }:
+			err = e.Cause()
+		default:
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,19 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeName(t *testing.T) {
+	require.Equal(t, "FeeValidation", TypeName(NewFeeValidation("fee")))
+	require.Equal(t, "AccountBalanceError", TypeName(Extend(NewAccountBalanceError("a"), "b")))
+	require.Equal(t, "Mistiming", TypeName(fmt.Errorf("c: %w", pkgerrors.Wrap(NewMistiming("a"), "b"))))
+	require.Equal(t, "NonPositiveAmount", TypeName(*NewNonPositiveAmount(-1, "waves")))
+	require.Empty(t, TypeName(pkgerrors.Wrap(errors.New("a"), "b")))
+	require.Empty(t, TypeName(nil))
+}
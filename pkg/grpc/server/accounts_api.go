@@ -12,6 +12,7 @@ import (
 	pb "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves"
 	g "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/node/grpc"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
 )
 
 func (s *Server) GetBalances(req *g.BalancesRequest, srv g.AccountsApi_GetBalancesServer) error {
@@ -21,6 +22,13 @@ func (s *Server) GetBalances(req *g.BalancesRequest, srv g.AccountsApi_GetBalanc
 		return status.Error(codes.InvalidArgument, err.Error())
 	}
 	rcp := proto.NewRecipientFromAddress(addr)
+	height, err := heightFromMetadata(srv.Context())
+	if err != nil {
+		return err
+	}
+	if height != 0 {
+		return s.sendBalancesAtHeight(rcp, req.Assets, height, srv)
+	}
 	if len(req.Assets) == 0 {
 		// TODO(nickeskov): send waves balance AND all assets balances (portfolio)
 		//  by the given address according to the scala node implementation
@@ -58,6 +66,47 @@ func (s *Server) GetBalances(req *g.BalancesRequest, srv g.AccountsApi_GetBalanc
 	return nil
 }
 
+// sendBalancesAtHeight sends the balances as of the given height, only regular Waves balance is kept in history.
+func (s *Server) sendBalancesAtHeight(
+	rcp proto.Recipient,
+	assets [][]byte,
+	height proto.Height,
+	srv g.AccountsApi_GetBalancesServer,
+) error {
+	if len(assets) == 0 {
+		assets = [][]byte{nil}
+	}
+	for _, asset := range assets {
+		var res g.BalanceResponse
+		if len(asset) == 0 {
+			balance, err := s.state.WavesBalanceAtHeight(rcp, height)
+			if err != nil {
+				return historyStatusError(err, codes.Internal)
+			}
+			res.Balance = &g.BalanceResponse_Waves{Waves: &g.BalanceResponse_WavesBalances{Regular: int64(balance)}}
+		} else {
+			fullAssetID, err := crypto.NewDigestFromBytes(asset)
+			if err != nil {
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+			balance, err := s.state.AssetBalanceAtHeight(rcp, proto.AssetIDFromDigest(fullAssetID), height)
+			if err != nil {
+				return historyStatusError(err, codes.NotFound)
+			}
+			res.Balance = &g.BalanceResponse_Asset{
+				Asset: &pb.Amount{
+					AssetId: fullAssetID.Bytes(),
+					Amount:  int64(balance),
+				},
+			}
+		}
+		if err := srv.Send(&res); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
+
 func (s *Server) GetScript(_ context.Context, req *g.AccountRequest) (*g.ScriptResponse, error) {
 	c := proto.ProtobufConverter{FallbackChainID: s.scheme}
 	addr, err := c.Address(s.scheme, req.Address)
@@ -120,6 +169,27 @@ func (s *Server) GetDataEntries(req *g.DataRequest, srv g.AccountsApi_GetDataEnt
 		return status.Error(codes.InvalidArgument, err.Error())
 	}
 	rcp := proto.NewRecipientFromAddress(addr)
+	height, err := heightFromMetadata(srv.Context())
+	if err != nil {
+		return err
+	}
+	if height != 0 {
+		if req.Key == "" {
+			return status.Error(codes.InvalidArgument, "data entry key is required for request at height")
+		}
+		entry, err := s.state.RetrieveEntryAtHeight(rcp, req.Key, height)
+		if err != nil {
+			if !state.IsInvalidInput(err) && state.IsNotFound(err) {
+				return nil
+			}
+			return historyStatusError(err, codes.Internal)
+		}
+		res := &g.DataEntryResponse{Address: req.Address, Entry: entry.ToProtobuf()}
+		if err := srv.Send(res); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		return nil
+	}
 	if req.Key != "" {
 		entry, err := s.state.RetrieveEntry(rcp, req.Key)
 		if err != nil {
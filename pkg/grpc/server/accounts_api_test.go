@@ -7,6 +7,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/wavesplatform/gowaves/pkg/crypto"
@@ -47,6 +50,20 @@ func TestGetBalances(t *testing.T) {
 	assert.Equal(t, correctBalance, res.Balance)
 	_, err = stream.Recv()
 	assert.Equal(t, io.EOF, err)
+
+	stream, err = cl.GetBalances(metadata.AppendToOutgoingContext(ctx, HeightMetadataKey, "1"), req)
+	require.NoError(t, err)
+	res, err = stream.Recv()
+	require.NoError(t, err)
+	regular := &g.BalanceResponse_Waves{Waves: &g.BalanceResponse_WavesBalances{Regular: 9999999500000000}}
+	assert.Equal(t, regular, res.Balance)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	stream, err = cl.GetBalances(metadata.AppendToOutgoingContext(ctx, HeightMetadataKey, "2"), req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
 }
 
 func TestGetActiveLeases(t *testing.T) {
@@ -7,5 +7,6 @@ type GrpcHandlers interface {
 	grpc.AssetsApiServer
 	grpc.BlockchainApiServer
 	grpc.BlocksApiServer
+	grpc.DebugApiServer
 	grpc.TransactionsApiServer
 }
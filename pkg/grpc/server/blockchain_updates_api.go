@@ -0,0 +1,267 @@
+package server
+
+import (
+	"bytes"
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/events"
+	eg "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/events/grpc"
+	"github.com/wavesplatform/gowaves/pkg/node/blockchain_updates"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+const (
+	// maxBlockUpdatesRange is the maximum number of blocks returned by GetBlockUpdatesRange.
+	maxBlockUpdatesRange = 1000
+	// subscribeReplayGap is the distance to the tip at which Subscribe stops the replay of stored blocks and
+	// subscribes to the live updates. It has to be much less than the size of subscription buffer.
+	subscribeReplayGap = 100
+	// maxReplayedBlocks is the number of the last replayed blocks remembered to detect the rollbacks during replay.
+	// It's equal to the maximum depth of rollback in the state.
+	maxReplayedBlocks = 2000
+)
+
+// replayedBlocks keeps the IDs of the last blocks sent by the replay, starting from the height from.
+type replayedBlocks struct {
+	from proto.Height
+	ids  []proto.BlockID
+}
+
+func (r *replayedBlocks) add(height proto.Height, id proto.BlockID) {
+	if len(r.ids) == 0 {
+		r.from = height
+	}
+	r.ids = append(r.ids, id)
+	if len(r.ids) > maxReplayedBlocks {
+		r.ids = r.ids[1:]
+		r.from++
+	}
+}
+
+// commonHeight returns the height of the last replayed block which is still in the state.
+func (r *replayedBlocks) commonHeight(st state.StateInfo) (proto.Height, bool, error) {
+	stateHeight, err := st.Height()
+	if err != nil {
+		return 0, false, err
+	}
+	for i := len(r.ids) - 1; i >= 0; i-- {
+		h := r.from + proto.Height(i)
+		if h > stateHeight {
+			continue
+		}
+		header, hErr := st.HeaderByHeight(h)
+		if hErr != nil {
+			return 0, false, hErr
+		}
+		if header.BlockID() == r.ids[i] {
+			return h, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+func (s *Server) blockUpdate(height proto.Height) (*events.BlockchainUpdated, error) {
+	// All reads are made under the state lock, so the liquid block can't be changed in the middle.
+	r, err := s.state.MapR(func(st state.StateInfo) (interface{}, error) {
+		stateHeight, err := st.Height()
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if height < 1 || height > stateHeight {
+			return nil, status.Errorf(codes.NotFound, "block at height %d not found", height)
+		}
+		u, err := blockchain_updates.BlockAppendUpdate(st, s.scheme, height)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return u, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.(*events.BlockchainUpdated), nil
+}
+
+func (s *Server) GetBlockUpdate(_ context.Context, req *eg.GetBlockUpdateRequest) (*eg.GetBlockUpdateResponse, error) {
+	u, err := s.blockUpdate(proto.Height(req.Height))
+	if err != nil {
+		return nil, err
+	}
+	return &eg.GetBlockUpdateResponse{Update: u}, nil
+}
+
+// GetBlockUpdatesRange returns the updates of blocks in the range [FromHeight, ToHeight].
+// The range is limited by maxBlockUpdatesRange blocks, use Subscribe to get more.
+func (s *Server) GetBlockUpdatesRange(
+	_ context.Context,
+	req *eg.GetBlockUpdatesRangeRequest,
+) (*eg.GetBlockUpdatesRangeResponse, error) {
+	if req.FromHeight < 1 || req.ToHeight < req.FromHeight {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid heights range [%d, %d]", req.FromHeight, req.ToHeight)
+	}
+	if req.ToHeight-req.FromHeight >= maxBlockUpdatesRange {
+		return nil, status.Errorf(codes.InvalidArgument, "heights range [%d, %d] is longer than %d blocks",
+			req.FromHeight, req.ToHeight, maxBlockUpdatesRange)
+	}
+	stateHeight, err := s.state.Height()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	to := min(proto.Height(req.ToHeight), stateHeight)
+	res := &eg.GetBlockUpdatesRangeResponse{}
+	for h := proto.Height(req.FromHeight); h <= to; h++ {
+		u, uErr := s.blockUpdate(h)
+		if uErr != nil {
+			return nil, uErr
+		}
+		res.Updates = append(res.Updates, u)
+	}
+	return res, nil
+}
+
+// Subscribe sends the updates of blocks starting from the requested height. First, the blocks stored in
+// the state are replayed, then the updates made by the node are sent as soon as they happen.
+// The stream is finished after the update of the block at ToHeight if it's set.
+func (s *Server) Subscribe(req *eg.SubscribeRequest, srv eg.BlockchainUpdatesApi_SubscribeServer) error {
+	if req.FromHeight < 1 {
+		return status.Errorf(codes.InvalidArgument, "invalid from height %d", req.FromHeight)
+	}
+	if req.ToHeight != 0 && req.ToHeight < req.FromHeight {
+		return status.Errorf(codes.InvalidArgument, "invalid heights range [%d, %d]", req.FromHeight, req.ToHeight)
+	}
+	from, to := proto.Height(req.FromHeight), proto.Height(req.ToHeight)
+	tracker := s.services.BlockchainUpdates
+	replayed := &replayedBlocks{}
+	send := func(h proto.Height) (*events.BlockchainUpdated, error) {
+		u, err := s.blockUpdate(h)
+		if err != nil {
+			return nil, err
+		}
+		if err = srv.Send(&eg.SubscribeEvent{Update: u}); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		id, err := proto.NewBlockIDFromBytes(u.Id)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		replayed.add(h, id)
+		return u, nil
+	}
+	// The replay of stored blocks could take a long time, so it's made without the subscription until the tip
+	// is close enough. Otherwise, the live updates would overflow the subscription buffer.
+	// The rollbacks made until the subscription are not reported by the tracker, so the replayed blocks are checked
+	// before sending the next one.
+	h := from
+	for tip := tracker.Tip(); h+subscribeReplayGap < tip.Height; tip = tracker.Tip() {
+		for ; h+subscribeReplayGap < tip.Height; h++ {
+			var err error
+			if h, err = s.replayRollback(srv, replayed, h); err != nil {
+				return err
+			}
+			if _, err = send(h); err != nil {
+				return err
+			}
+			if h == to {
+				return nil
+			}
+		}
+	}
+	sub, tip := tracker.Subscribe()
+	defer tracker.Unsubscribe(sub)
+	h, err := s.replayRollback(srv, replayed, h)
+	if err != nil {
+		return err
+	}
+	// The last replayed block is read from the state and could already include the transactions of
+	// micro blocks applied after the subscription. Updates of such micro blocks are skipped.
+	var covered *events.BlockchainUpdated
+	for ; h <= tip.Height; h++ {
+		u, err := send(h)
+		if err != nil {
+			return err
+		}
+		if h == to {
+			return nil
+		}
+		if h == tip.Height && !bytes.Equal(u.Id, tip.BlockID.Bytes()) {
+			covered = u
+		}
+	}
+	started := from <= tip.Height
+	for {
+		select {
+		case <-srv.Context().Done():
+			return status.FromContextError(srv.Context().Err()).Err()
+		case u, ok := <-sub.Updates():
+			if !ok {
+				if err := sub.Err(); err != nil {
+					return status.Error(codes.Unavailable, err.Error())
+				}
+				return status.Error(codes.Unavailable, "subscription terminated")
+			}
+			if covered != nil {
+				if u.Height == covered.Height && u.GetAppend().GetMicroBlock() != nil {
+					if bytes.Equal(u.Id, covered.Id) {
+						covered = nil
+					}
+					continue
+				}
+				covered = nil
+			}
+			h := proto.Height(u.Height)
+			if !started && h < from { // The requested height is not reached yet.
+				continue
+			}
+			started = true
+			if err := srv.Send(&eg.SubscribeEvent{Update: u}); err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			if to != 0 && h >= to && u.GetAppend() != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// replayRollback checks that the replayed blocks are still in the state. If the state was rolled back below
+// the last replayed block, the rollback is sent to the subscriber and the height following the common block is
+// returned to continue the replay from it. Otherwise, the given height is returned.
+func (s *Server) replayRollback(
+	srv eg.BlockchainUpdatesApi_SubscribeServer,
+	replayed *replayedBlocks,
+	height proto.Height,
+) (proto.Height, error) {
+	if len(replayed.ids) == 0 {
+		return height, nil
+	}
+	var found bool
+	r, err := s.state.MapR(func(st state.StateInfo) (interface{}, error) {
+		common, ok, cErr := replayed.commonHeight(st)
+		found = ok
+		return common, cErr
+	})
+	if err != nil {
+		return 0, status.Error(codes.Internal, err.Error())
+	}
+	if !found {
+		return 0, status.Error(codes.Unavailable, "rollback is deeper than the replayed blocks")
+	}
+	common := r.(proto.Height)
+	last := replayed.from + proto.Height(len(replayed.ids)) - 1
+	if common == last {
+		return height, nil
+	}
+	replayed.ids = replayed.ids[:common-replayed.from+1]
+	u, err := blockchain_updates.RollbackUpdate(s.scheme, replayed.ids[len(replayed.ids)-1], common, nil)
+	if err != nil {
+		return 0, status.Error(codes.Internal, err.Error())
+	}
+	if err = srv.Send(&eg.SubscribeEvent{Update: u}); err != nil {
+		return 0, status.Error(codes.Internal, err.Error())
+	}
+	return common + 1, nil
+}
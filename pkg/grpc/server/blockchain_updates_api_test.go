@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+
+	"github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/events"
+	eg "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/events/grpc"
+	"github.com/wavesplatform/gowaves/pkg/node/blockchain_updates"
+	"github.com/wavesplatform/gowaves/pkg/node/blocks_applier"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+type testSubscribeServer struct {
+	grpc.ServerStream
+	ctx     context.Context
+	updates []*events.BlockchainUpdated
+	onSend  func(u *events.BlockchainUpdated)
+}
+
+func (s *testSubscribeServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testSubscribeServer) Send(e *eg.SubscribeEvent) error {
+	s.updates = append(s.updates, e.Update)
+	if s.onSend != nil {
+		s.onSend(e.Update)
+	}
+	return nil
+}
+
+func stateWithMainnetBlocks(t *testing.T, height proto.Height) (state.State, []*proto.Block) {
+	st := newTestState(t, true, defaultStateParams(), settings.MustMainNetSettings())
+	blocks, err := state.ReadMainnetBlocksToHeight(height)
+	require.NoError(t, err)
+	return st, blocks
+}
+
+func assertBlockUpdate(t *testing.T, st state.StateInfo, height proto.Height, u *events.BlockchainUpdated) {
+	expected, err := blockchain_updates.BlockAppendUpdate(st, proto.MainNetScheme, height)
+	require.NoError(t, err)
+	assert.True(t, protobuf.Equal(expected, u), "update at height %d differs", height)
+}
+
+func TestGetBlockUpdate(t *testing.T) {
+	st, blocks := stateWithMainnetBlocks(t, 50)
+	_, err := st.AddDeserializedBlocks(blocks)
+	require.NoError(t, err)
+	require.NoError(t, server.initServer(st, nil, nil))
+	ctx := withAutoCancel(t, context.Background())
+
+	res, err := server.GetBlockUpdate(ctx, &eg.GetBlockUpdateRequest{Height: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), res.Update.Height)
+	assert.Equal(t, blocks[8].BlockID().Bytes(), res.Update.Id)
+	assertBlockUpdate(t, st, 10, res.Update)
+
+	for _, h := range []int32{0, 51} {
+		_, err = server.GetBlockUpdate(ctx, &eg.GetBlockUpdateRequest{Height: h})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	}
+}
+
+func TestGetBlockUpdatesRange(t *testing.T) {
+	st, blocks := stateWithMainnetBlocks(t, 50)
+	_, err := st.AddDeserializedBlocks(blocks)
+	require.NoError(t, err)
+	require.NoError(t, server.initServer(st, nil, nil))
+	ctx := withAutoCancel(t, context.Background())
+
+	res, err := server.GetBlockUpdatesRange(ctx, &eg.GetBlockUpdatesRangeRequest{FromHeight: 5, ToHeight: 9})
+	require.NoError(t, err)
+	require.Len(t, res.Updates, 5)
+	for i, u := range res.Updates {
+		assertBlockUpdate(t, st, proto.Height(5+i), u)
+	}
+	// The end of range is limited by the state height.
+	res, err = server.GetBlockUpdatesRange(ctx, &eg.GetBlockUpdatesRangeRequest{FromHeight: 45, ToHeight: 100})
+	require.NoError(t, err)
+	assert.Len(t, res.Updates, 6)
+
+	for _, req := range []*eg.GetBlockUpdatesRangeRequest{
+		{FromHeight: 0, ToHeight: 10},
+		{FromHeight: 10, ToHeight: 9},
+		{FromHeight: 1, ToHeight: maxBlockUpdatesRange + 1},
+	} {
+		_, err = server.GetBlockUpdatesRange(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	st, blocks := stateWithMainnetBlocks(t, 60)
+	_, err := st.AddDeserializedBlocks(blocks[:39]) // The genesis block is not included, so the height is 40.
+	require.NoError(t, err)
+	tracker, err := blockchain_updates.NewTracker(blocks_applier.NewBlocksApplier(), st, proto.MainNetScheme)
+	require.NoError(t, err)
+	require.NoError(t, server.initServer(st, nil, nil))
+	server.services.BlockchainUpdates = tracker
+	t.Cleanup(func() { server.services.BlockchainUpdates = nil })
+
+	t.Run("replay", func(t *testing.T) {
+		srv := &testSubscribeServer{ctx: withAutoCancel(t, context.Background())}
+		err = server.Subscribe(&eg.SubscribeRequest{FromHeight: 10, ToHeight: 20}, srv)
+		require.NoError(t, err)
+		require.Len(t, srv.updates, 11)
+		for i, u := range srv.updates {
+			assertBlockUpdate(t, st, proto.Height(10+i), u)
+		}
+	})
+	t.Run("replay and live", func(t *testing.T) {
+		srv := &testSubscribeServer{ctx: withAutoCancel(t, context.Background())}
+		srv.onSend = func(u *events.BlockchainUpdated) {
+			if u.Height == 40 { // The replay is finished, new blocks are applied by the node.
+				_, applyErr := tracker.Apply(st, blocks[39:])
+				require.NoError(t, applyErr)
+			}
+		}
+		err = server.Subscribe(&eg.SubscribeRequest{FromHeight: 30, ToHeight: 55}, srv)
+		require.NoError(t, err)
+		require.Len(t, srv.updates, 26)
+		for i, u := range srv.updates {
+			assert.Equal(t, int32(30+i), u.Height)
+			assertBlockUpdate(t, st, proto.Height(30+i), u)
+		}
+	})
+	t.Run("invalid range", func(t *testing.T) {
+		srv := &testSubscribeServer{ctx: withAutoCancel(t, context.Background())}
+		err = server.Subscribe(&eg.SubscribeRequest{FromHeight: 10, ToHeight: 5}, srv)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestSubscribeRollbackDuringReplay(t *testing.T) {
+	st, blocks := stateWithMainnetBlocks(t, 200)
+	_, err := st.AddDeserializedBlocks(blocks[:199])
+	require.NoError(t, err)
+	tracker, err := blockchain_updates.NewTracker(blocks_applier.NewBlocksApplier(), st, proto.MainNetScheme)
+	require.NoError(t, err)
+	require.NoError(t, server.initServer(st, nil, nil))
+	server.services.BlockchainUpdates = tracker
+	t.Cleanup(func() { server.services.BlockchainUpdates = nil })
+
+	srv := &testSubscribeServer{ctx: withAutoCancel(t, context.Background())}
+	srv.onSend = func(u *events.BlockchainUpdated) {
+		switch {
+		case u.Height == 50 && u.GetAppend() != nil && len(srv.updates) == 41:
+			// The state is rolled back during the replay, before the subscription to the tracker.
+			require.NoError(t, tracker.RollbackTo(st, blocks[43].BlockID()))
+		case u.GetRollback() != nil:
+			_, applyErr := tracker.Apply(st, blocks[44:])
+			require.NoError(t, applyErr)
+		}
+	}
+	err = server.Subscribe(&eg.SubscribeRequest{FromHeight: 10, ToHeight: 60}, srv)
+	require.NoError(t, err)
+	require.Len(t, srv.updates, 41+1+15)
+	for i, u := range srv.updates[:41] {
+		assert.Equal(t, int32(10+i), u.Height)
+	}
+	rollback := srv.updates[41]
+	require.NotNil(t, rollback.GetRollback())
+	assert.Equal(t, int32(45), rollback.Height)
+	assert.Equal(t, blocks[43].BlockID().Bytes(), rollback.Id)
+	for i, u := range srv.updates[42:] {
+		assert.Equal(t, int32(46+i), u.Height)
+		assertBlockUpdate(t, st, proto.Height(46+i), u)
+	}
+}
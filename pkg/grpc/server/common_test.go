@@ -30,8 +30,9 @@ import (
 )
 
 const (
-	sleepTime = 2 * time.Second
-	utxSize   = 1000
+	sleepTime  = 2 * time.Second
+	utxSize    = 1000
+	testAPIKey = "test-api-key"
 )
 
 var (
@@ -137,7 +138,9 @@ func TestMain(m *testing.M) {
 	grpcTestAddr = fmt.Sprintf("127.0.0.1:%d", freeport.GetPort())
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
-		if err := server.Run(ctx, grpcTestAddr, nil); err != nil {
+		opts := DefaultRunOptions()
+		opts.APIKey = testAPIKey
+		if err := server.Run(ctx, grpcTestAddr, opts); err != nil {
 			log.Fatalf("server.Run(): %v\n", err)
 		}
 	}()
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/wavesplatform/gowaves/pkg/errs"
+	pb "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/node/grpc"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+func (s *Server) Validate(ctx context.Context, req *g.ValidateRequest) (*g.ValidateResponse, error) {
+	if err := s.checkAPIKey(ctx); err != nil {
+		return nil, err
+	}
+	st, ok := s.state.(state.State)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "transaction validation is not supported by state")
+	}
+	c := proto.ProtobufConverter{FallbackChainID: s.scheme}
+	tx, err := c.SignedTransaction(req.GetTransaction())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := tx.Validate(proto.TransactionValidationParams{Scheme: s.scheme}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	_, isEthereum := req.GetTransaction().GetTransaction().(*pb.SignedTransaction_EthereumTransaction)
+	checkSignatures := isEthereum || len(req.GetTransaction().GetProofs()) > 0
+	var pending []proto.Transaction
+	if s.utx != nil {
+		for _, t := range s.utx.AllTransactions() {
+			pending = append(pending, t.T)
+		}
+	}
+	now := time.Now()
+	if t := s.services.Time; t != nil {
+		now = t.Now()
+	}
+	r, err := state.DryRun(st, pending, tx, proto.NewTimestampFromTime(now), checkSignatures)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	feeAsset := tx.GetFeeAsset()
+	res := &g.ValidateResponse{
+		Valid:      r.Error == nil,
+		Fee:        &g.FeeCheck{Fee: &pb.Amount{AssetId: feeAsset.ToID(), Amount: int64(tx.GetFee())}},
+		Complexity: int64(r.Complexity),
+	}
+	if minFee, feeErr := s.state.MinFee(tx); feeErr == nil {
+		res.Fee.MinFee = int64(minFee)
+		res.Fee.Sufficient = tx.GetFee() >= minFee
+	}
+	if r.Error != nil {
+		res.Error = &g.ValidationError{Type: errs.TypeName(r.Error), Message: r.Error.Error()}
+		return res, nil
+	}
+	res.Snapshot = new(pb.TransactionStateSnapshot)
+	for _, snapshot := range r.Snapshots {
+		if err := snapshot.AppendToProtobuf(res.Snapshot); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	if r.ScriptResult != nil {
+		if res.ScriptResult, err = r.ScriptResult.ToProtobuf(); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/node/grpc"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+)
+
+func TestValidateRequiresAPIKey(t *testing.T) {
+	params := defaultStateParams()
+	st := newTestState(t, true, params, settings.MustMainNetSettings())
+	ctx := withAutoCancel(t, context.Background())
+	sch := createTestNetWallet(t)
+
+	err := server.initServer(st, nil, sch)
+	require.NoError(t, err)
+
+	conn := connectAutoClose(t, grpcTestAddr)
+	cl := g.NewDebugApiClient(conn)
+
+	addr, err := proto.NewAddressFromString("3PAWwWa6GbwcJaFzwqXQN5KQm7H96Y7SHTQ")
+	require.NoError(t, err)
+	waves := proto.NewOptionalAssetWaves()
+	tx := proto.NewUnsignedTransferWithSig(keyPairs[0].Public, waves, waves, 100, 100, 100000,
+		proto.NewRecipientFromAddress(addr), nil)
+	require.NoError(t, tx.Sign(server.scheme, keyPairs[0].Secret))
+	txProto, err := tx.ToProtobufSigned(server.scheme)
+	require.NoError(t, err)
+	req := &g.ValidateRequest{Transaction: txProto}
+
+	_, err = cl.Validate(ctx, req)
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Unauthenticated, s.Code())
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, APIKeyMetadataKey, testAPIKey)
+	res, err := cl.Validate(authCtx, req)
+	require.NoError(t, err)
+	assert.False(t, res.Valid) // Sender has no balance.
+}
@@ -11,6 +11,8 @@ import (
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	eg "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/events/grpc"
 	g "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/node/grpc"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/services"
@@ -24,16 +26,20 @@ const (
 )
 
 type Server struct {
-	state      state.StateInfo
-	scheme     proto.Scheme
-	utx        types.UtxPool
-	wallet     types.EmbeddedWallet
-	services   services.Services
-	grpcServer *grpc.Server
+	state         state.StateInfo
+	scheme        proto.Scheme
+	utx           types.UtxPool
+	wallet        types.EmbeddedWallet
+	services      services.Services
+	grpcServer    *grpc.Server
+	hashedAPIKey  crypto.Digest
+	apiKeyEnabled bool
 }
 
 type RunOptions struct {
 	MaxConnections int
+	// APIKey protects the methods that use the node's wallet. Such methods are disabled if the key is empty.
+	APIKey string
 }
 
 func DefaultRunOptions() *RunOptions {
@@ -45,6 +51,9 @@ func DefaultRunOptions() *RunOptions {
 func NewServer(services services.Services) (*Server, error) {
 	s := &Server{}
 	s.grpcServer = createGRPCServerWithHandlers(s)
+	if services.BlockchainUpdates != nil {
+		eg.RegisterBlockchainUpdatesApiServer(s.grpcServer, s)
+	}
 	s.services = services
 	if err := s.initServer(services.State, services.UtxPool, services.Wallet); err != nil {
 		return nil, err
@@ -63,6 +72,7 @@ func createGRPCServerWithHandlers(handlers GrpcHandlers) *grpc.Server {
 	g.RegisterAssetsApiServer(grpcServer, handlers)
 	g.RegisterBlockchainApiServer(grpcServer, handlers)
 	g.RegisterBlocksApiServer(grpcServer, handlers)
+	g.RegisterDebugApiServer(grpcServer, handlers)
 	g.RegisterTransactionsApiServer(grpcServer, handlers)
 	reflection.Register(grpcServer) // Register reflection service on gRPC server.
 	return grpcServer
@@ -76,10 +86,23 @@ func (s *Server) initServer(state state.StateInfo, utx types.UtxPool, sch types.
 	return nil
 }
 
+func (s *Server) setAPIKey(apiKey string) error {
+	digest, err := crypto.SecureHash([]byte(apiKey))
+	if err != nil {
+		return errors.Wrap(err, "failed to calculate secure hash for API key")
+	}
+	s.hashedAPIKey = digest
+	s.apiKeyEnabled = len(apiKey) > 0
+	return nil
+}
+
 func (s *Server) Run(ctx context.Context, address string, opts *RunOptions) error {
 	if opts == nil {
 		opts = DefaultRunOptions()
 	}
+	if err := s.setAPIKey(opts.APIKey); err != nil {
+		return err
+	}
 
 	conn, err := net.Listen("tcp", address)
 	if err != nil {
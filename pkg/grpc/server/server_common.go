@@ -1,14 +1,70 @@
 package server
 
 import (
+	"context"
+	"strconv"
+
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	g "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/node/grpc"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/state"
 )
 
+// APIKeyMetadataKey is the name of the gRPC metadata entry that holds the API key.
+const APIKeyMetadataKey = "x-api-key" // #nosec: it's a metadata key name
+
+// checkAPIKey checks the API key passed in the metadata of the incoming request.
+func (s *Server) checkAPIKey(ctx context.Context) error {
+	if !s.apiKeyEnabled {
+		return status.Error(codes.PermissionDenied, "API key is disabled")
+	}
+	keys := metadata.ValueFromIncomingContext(ctx, APIKeyMetadataKey)
+	if len(keys) == 0 {
+		return status.Error(codes.Unauthenticated, "API key is not provided")
+	}
+	d, err := crypto.SecureHash([]byte(keys[0]))
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if d != s.hashedAPIKey {
+		return status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	return nil
+}
+
+// HeightMetadataKey is the name of the gRPC metadata entry that holds the height of historical request.
+// Request messages are shared with other node implementations, so the height is passed in metadata.
+const HeightMetadataKey = "x-height"
+
+// heightFromMetadata returns the height passed in the metadata of the incoming request.
+// Zero height is returned if no height is provided and the current state is requested.
+func heightFromMetadata(ctx context.Context) (proto.Height, error) {
+	values := metadata.ValueFromIncomingContext(ctx, HeightMetadataKey)
+	if len(values) == 0 {
+		return 0, nil
+	}
+	height, err := strconv.ParseUint(values[0], 10, 64)
+	if err != nil || height == 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid height %q", values[0])
+	}
+	return height, nil
+}
+
+// historyStatusError returns OutOfRange status for the request at height outside of the history range
+// and the status with the given code for other errors.
+func historyStatusError(err error, code codes.Code) error {
+	if state.IsInvalidInput(err) {
+		return status.Error(codes.OutOfRange, err.Error())
+	}
+	return status.Error(code, err.Error())
+}
+
 func (s *Server) transactionToTransactionResponse(
 	tx proto.Transaction,
 	confirmed bool,
@@ -1,12 +1,14 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"time"
 
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
 
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/errs"
@@ -17,6 +19,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/state"
 	"github.com/wavesplatform/gowaves/pkg/util/iterators"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
 )
 
 type getTransactionsHandler struct {
@@ -74,12 +77,76 @@ func (s *Server) GetTransactions(req *g.TransactionsRequest, srv g.TransactionsA
 	return nil
 }
 
+// transactionSnapshot returns the snapshot of confirmed transaction with the given ID or nil if there is no such
+// transaction. The transaction is looked up in its block to find the corresponding snapshot of the block.
+func (s *Server) transactionSnapshot(id []byte) (*g.TransactionSnapshotResponse, error) {
+	r, err := s.state.MapR(func(st state.StateInfo) (interface{}, error) {
+		_, txStatus, err := st.TransactionByIDWithStatus(id)
+		if err != nil {
+			return (*g.TransactionSnapshotResponse)(nil), nil
+		}
+		height, err := st.TransactionHeightByID(id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get transaction height")
+		}
+		block, err := st.BlockByHeight(height)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get block at height %d", height)
+		}
+		blockSnapshot, err := st.SnapshotsAtHeight(height)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get block snapshot at height %d", height)
+		}
+		idx := -1
+		for i, tx := range block.Transactions {
+			txID, idErr := tx.GetID(s.scheme)
+			if idErr != nil {
+				return nil, errors.Wrap(idErr, "failed to get transaction ID")
+			}
+			if bytes.Equal(txID, id) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 || idx >= len(blockSnapshot.TxSnapshots) {
+			return nil, errors.Errorf("snapshot of transaction is not found in block '%s'", block.BlockID().String())
+		}
+		snapshot := &pb.TransactionStateSnapshot{}
+		for _, as := range blockSnapshot.TxSnapshots[idx] {
+			if err = as.AppendToProtobuf(snapshot); err != nil {
+				return nil, errors.Wrap(err, "failed to convert transaction snapshot to protobuf")
+			}
+		}
+		if err = (proto.TransactionStatusSnapshot{Status: txStatus}).AppendToProtobuf(snapshot); err != nil {
+			return nil, err
+		}
+		return &g.TransactionSnapshotResponse{Id: id, Snapshot: snapshot}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.(*g.TransactionSnapshotResponse), nil
+}
+
+// GetTransactionSnapshots sends the snapshots of confirmed transactions with the requested IDs in the order of IDs.
+// Unknown and unconfirmed transactions are skipped the same way as GetTransactions does.
 func (s *Server) GetTransactionSnapshots(
 	req *g.TransactionSnapshotsRequest,
 	srv g.TransactionsApi_GetTransactionSnapshotsServer,
 ) error {
-	unimplementedServer := g.UnimplementedTransactionsApiServer{} // TODO: implement GetTransactionSnapshots
-	return unimplementedServer.GetTransactionSnapshots(req, srv)
+	for _, id := range req.TransactionIds {
+		res, err := s.transactionSnapshot(id)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if res == nil {
+			continue
+		}
+		if err = srv.Send(res); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+	return nil
 }
 
 type getStateChangesHandler struct {
@@ -221,7 +288,53 @@ func (s *Server) GetUnconfirmed(req *g.TransactionsRequest, srv g.TransactionsAp
 }
 
 func (s *Server) Sign(ctx context.Context, req *g.SignRequest) (*pb.SignedTransaction, error) {
-	return g.UnimplementedTransactionsApiServer{}.Sign(ctx, req)
+	if err := s.checkAPIKey(ctx); err != nil {
+		return nil, err
+	}
+	if s.wallet == nil {
+		return nil, status.Error(codes.FailedPrecondition, "wallet is not available")
+	}
+	if req.Transaction == nil {
+		return nil, status.Error(codes.InvalidArgument, "empty transaction")
+	}
+	signer, err := crypto.NewPublicKeyFromBytes(req.SignerPublicKey)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid signer public key: %v", err)
+	}
+	txProto, ok := protobuf.Clone(req.Transaction).(*pb.Transaction)
+	if !ok {
+		return nil, status.Error(codes.Internal, "failed to copy transaction")
+	}
+	if len(txProto.SenderPublicKey) == 0 {
+		txProto.SenderPublicKey = signer.Bytes()
+	}
+	c := proto.ProtobufConverter{FallbackChainID: s.scheme}
+	tx, err := c.Transaction(txProto)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if tx.GetFee() == 0 {
+		// Fill in the minimal fee in the fee asset of the transaction.
+		fee, err := s.state.MinFee(tx)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to calculate fee: %v", err)
+		}
+		txProto.Fee = &pb.Amount{AssetId: txProto.GetFee().GetAssetId(), Amount: int64(fee)}
+		if tx, err = c.Transaction(txProto); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if err := s.wallet.SignTransactionWith(signer, tx); err != nil {
+		if errors.Is(err, wallet.PublicKeyNotFound) {
+			return nil, status.Error(codes.NotFound, "signer account is not found in the wallet")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to sign transaction: %v", err)
+	}
+	res, err := tx.ToProtobufSigned(s.scheme)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return res, nil
 }
 
 func (s *Server) Broadcast(ctx context.Context, tx *pb.SignedTransaction) (out *pb.SignedTransaction, err error) {
@@ -14,7 +14,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
 
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	pb "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves"
@@ -24,6 +26,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/mock"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
 )
 
 func TestGetTransactions(t *testing.T) {
@@ -162,6 +165,44 @@ func TestGetStatuses(t *testing.T) {
 	assert.Equal(t, io.EOF, err)
 }
 
+func TestGetTransactionSnapshots(t *testing.T) {
+	st := newTestState(t, true, defaultStateParams(), settings.MustMainNetSettings())
+	blocks, err := state.ReadMainnetBlocksToHeight(10)
+	require.NoError(t, err)
+	_, err = st.AddDeserializedBlocks(blocks)
+	require.NoError(t, err)
+	ctx := withAutoCancel(t, context.Background())
+	err = server.initServer(st, nil, nil)
+	require.NoError(t, err)
+
+	conn := connectAutoClose(t, grpcTestAddr)
+	cl := g.NewTransactionsApiClient(conn)
+
+	// id0 is the second transaction from Mainnet genesis block.
+	genesis, err := st.BlockByHeight(1)
+	require.NoError(t, err)
+	id0, err := genesis.Transactions[1].GetID(proto.MainNetScheme)
+	require.NoError(t, err)
+	// id1 is unknown.
+	id1 := []byte{1}
+	genesisSnapshot, err := st.SnapshotsAtHeight(1)
+	require.NoError(t, err)
+	correctSnapshots, err := genesisSnapshot.ToProtobuf()
+	require.NoError(t, err)
+	correctSnapshot := correctSnapshots[1]
+	correctSnapshot.TransactionStatus = pb.TransactionStatus_SUCCEEDED
+
+	stream, err := cl.GetTransactionSnapshots(ctx, &g.TransactionSnapshotsRequest{TransactionIds: [][]byte{id1, id0}})
+	require.NoError(t, err)
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, id0, res.Id)
+	assert.NotEmpty(t, res.Snapshot.Balances)
+	assert.True(t, protobuf.Equal(correctSnapshot, res.Snapshot))
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
 func TestGetUnconfirmed(t *testing.T) {
 	bs := settings.MustMainNetSettings()
 	params := defaultStateParams()
@@ -266,21 +307,43 @@ func TestSign(t *testing.T) {
 	addr, err := proto.NewAddressFromString("3PAWwWa6GbwcJaFzwqXQN5KQm7H96Y7SHTQ")
 	require.NoError(t, err)
 	waves := proto.NewOptionalAssetWaves()
-	tx := proto.NewUnsignedTransferWithSig(pk, waves, waves, 100, 1, 100, proto.NewRecipientFromAddress(addr), []byte("attachment"))
-	err = tx.GenerateID(server.scheme)
-	require.NoError(t, err)
-	require.NoError(t, err)
+	// Fee is omitted and must be filled in by the server.
+	tx := proto.NewUnsignedTransferWithSig(pk, waves, waves, 100, 100, 0, proto.NewRecipientFromAddress(addr), []byte("attachment"))
 	txProto, err := tx.ToProtobuf(server.scheme)
 	require.NoError(t, err)
 
 	cl := g.NewTransactionsApiClient(conn)
 	req := &g.SignRequest{Transaction: txProto, SignerPublicKey: pk.Bytes()}
+
 	_, err = cl.Sign(ctx, req)
-	require.Error(t, err)
 	s, ok := status.FromError(err)
 	require.True(t, ok)
-	require.Equal(t, codes.Unimplemented, s.Code())
-	require.Equal(t, "method Sign not implemented", s.Message())
+	assert.Equal(t, codes.Unauthenticated, s.Code())
+
+	_, err = cl.Sign(metadata.AppendToOutgoingContext(ctx, APIKeyMetadataKey, "wrong-key"), req)
+	s, ok = status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Unauthenticated, s.Code())
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, APIKeyMetadataKey, testAPIKey)
+	res, err := cl.Sign(authCtx, req)
+	require.NoError(t, err)
+	c := proto.ProtobufConverter{FallbackChainID: server.scheme}
+	signed, err := c.SignedTransaction(res)
+	require.NoError(t, err)
+	stx, ok := signed.(*proto.TransferWithSig)
+	require.True(t, ok)
+	assert.Equal(t, uint64(state.FeeUnit), stx.Fee)
+	valid, err := stx.Verify(server.scheme, pk)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	unknown, err := crypto.NewPublicKeyFromBase58("AfZtLRQxLNYH5iradMkTeuXGe71uAiATVbr8DpXEEQa8")
+	require.NoError(t, err)
+	_, err = cl.Sign(authCtx, &g.SignRequest{Transaction: txProto, SignerPublicKey: unknown.Bytes()})
+	s, ok = status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.NotFound, s.Code())
 }
 
 func TestBroadcast(t *testing.T) {
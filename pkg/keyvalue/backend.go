@@ -0,0 +1,78 @@
+package keyvalue
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Backend is the storage engine of key-value database.
+type Backend string
+
+const (
+	LevelDBBackend Backend = "leveldb"
+	PebbleBackend  Backend = "pebble"
+)
+
+func (b Backend) String() string {
+	if b == "" {
+		return string(LevelDBBackend)
+	}
+	return string(b)
+}
+
+// ParseBackend returns the backend by its name, empty name stands for the default LevelDB backend.
+func ParseBackend(name string) (Backend, error) {
+	switch Backend(name) {
+	case "", LevelDBBackend:
+		return LevelDBBackend, nil
+	case PebbleBackend:
+		return PebbleBackend, nil
+	default:
+		return "", errors.Errorf("unsupported database backend '%s'", name)
+	}
+}
+
+// DetectBackend returns the backend that created the database at the given path.
+// False is returned if there is no database at the path.
+func DetectBackend(path string) (Backend, bool, error) {
+	if _, err := os.Stat(filepath.Join(path, "CURRENT")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		return "", false, errors.Wrapf(err, "failed to check database at '%s'", path)
+	}
+	// Pebble always keeps the OPTIONS file along with the MANIFEST, LevelDB doesn't create it.
+	options, err := filepath.Glob(filepath.Join(path, "OPTIONS-*"))
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to check database at '%s'", path)
+	}
+	if len(options) > 0 {
+		return PebbleBackend, true, nil
+	}
+	return LevelDBBackend, true, nil
+}
+
+// NewKeyValue opens the database at the given path with the backend selected by parameters.
+// It fails if the existing database was created by another backend.
+func NewKeyValue(path string, params KeyValParams) (IterableKeyVal, error) {
+	backend, err := ParseBackend(string(params.Backend))
+	if err != nil {
+		return nil, err
+	}
+	existing, ok, err := DetectBackend(path)
+	if err != nil {
+		return nil, err
+	}
+	if ok && existing != backend {
+		return nil, errors.Errorf("database at '%s' was created by %s backend, but %s backend is selected",
+			path, existing, backend)
+	}
+	switch backend {
+	case PebbleBackend:
+		return NewPebbleKeyVal(path, params)
+	default:
+		return NewKeyVal(path, params)
+	}
+}
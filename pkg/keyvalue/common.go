@@ -60,6 +60,8 @@ func SafeValue(k ValueInter) []byte {
 type IterableKeyVal interface {
 	KeyValue
 	NewKeyIterator(prefix []byte) (Iterator, error)
+	// NewKeyIteratorFrom returns the iterator over the keys with the given prefix that are not less than start.
+	NewKeyIteratorFrom(prefix, start []byte) (Iterator, error)
 }
 
 type CacheParams struct {
@@ -1,6 +1,7 @@
 package keyvalue
 
 import (
+	"bytes"
 	"sync"
 
 	"github.com/coocood/freecache"
@@ -96,23 +97,22 @@ type KeyVal struct {
 	mu     *sync.RWMutex
 }
 
-func initBloomFilter(kv *KeyVal, params BloomFilterParams) error {
+func initBloomFilter(kv IterableKeyVal, params BloomFilterParams) (BloomFilter, error) {
 	zap.S().Info("Loading stored bloom filter...")
 	filter, err := newBloomFilterFromStore(params)
 	if err == nil {
-		kv.filter = filter
 		zap.S().Info("Bloom filter loaded successfully")
-		return nil
+		return filter, nil
 	}
 	zap.S().Info("No stored bloom filter found")
 	zap.S().Info("Rebuilding bloom filter from DB can take up a few minutes")
 	filter, err = newBloomFilter(params)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	iter, err := kv.NewKeyIterator([]byte{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		iter.Release()
@@ -123,16 +123,17 @@ func initBloomFilter(kv *KeyVal, params BloomFilterParams) error {
 
 	for iter.Next() {
 		if err := filter.add(iter.Key()); err != nil {
-			return err
+			return nil, err
 		}
 	}
-	kv.filter = filter
-	return nil
+	return filter, nil
 }
 
 type KeyValParams struct {
 	CacheParams
 	BloomFilterParams
+	// Backend is the storage engine of the database, LevelDB is used if it is not set.
+	Backend                Backend
 	WriteBuffer            int
 	CompactionTableSize    int
 	CompactionTotalSize    int
@@ -164,9 +165,12 @@ func NewKeyVal(path string, params KeyValParams) (*KeyVal, error) {
 	}
 	cache := freecache.NewCache(params.CacheParams.Size)
 	kv := &KeyVal{db: db, cache: cache, mu: &sync.RWMutex{}}
-	if err := initBloomFilter(kv, params.BloomFilterParams); err != nil {
+	filter, err := initBloomFilter(kv, params.BloomFilterParams)
+	if err != nil {
 		return nil, err
 	}
+	kv.filter = filter
+	metricDB.set(db, cache)
 	return kv, nil
 }
 
@@ -271,9 +275,20 @@ func (k *KeyVal) NewKeyIterator(prefix []byte) (Iterator, error) {
 	}
 }
 
+func (k *KeyVal) NewKeyIteratorFrom(prefix, start []byte) (Iterator, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	r := util.BytesPrefix(prefix)
+	if bytes.Compare(start, r.Start) > 0 {
+		r.Start = start
+	}
+	return k.db.NewIterator(r, nil), nil
+}
+
 func (k *KeyVal) Close() error {
 	k.mu.Lock()
 	defer k.mu.Unlock()
+	metricDB.unset(k.cache)
 	zap.S().Infof("Cache hit rate: %v", k.cache.HitRate())
 	err := storeBloomFilter(k.filter)
 	if err != nil {
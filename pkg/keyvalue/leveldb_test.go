@@ -3,7 +3,9 @@ package keyvalue
 import (
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -13,17 +15,29 @@ const (
 	compactionTotalSize = 10 * 1024 * 1024
 )
 
-func TestKeyVal(t *testing.T) {
-	dbDir := t.TempDir()
-	params := KeyValParams{
+func testKeyValParams(backend Backend) KeyValParams {
+	return KeyValParams{
 		CacheParams:         CacheParams{cacheSize},
 		BloomFilterParams:   BloomFilterParams{n, falsePositiveProbability, NoOpStore{}, false},
+		Backend:             backend,
 		WriteBuffer:         writeBuffer,
 		CompactionTableSize: sstableSize,
 		CompactionTotalSize: compactionTotalSize,
 	}
-	kv, err := NewKeyVal(dbDir, params)
-	assert.NoError(t, err, "NewKeyVal() failed")
+}
+
+func TestKeyVal(t *testing.T) {
+	for _, backend := range []Backend{LevelDBBackend, PebbleBackend} {
+		t.Run(backend.String(), func(t *testing.T) {
+			testKeyVal(t, backend)
+		})
+	}
+}
+
+func testKeyVal(t *testing.T, backend Backend) {
+	dbDir := t.TempDir()
+	kv, err := NewKeyValue(dbDir, testKeyValParams(backend))
+	require.NoError(t, err, "NewKeyValue() failed")
 
 	t.Cleanup(func() {
 		err = kv.Close()
@@ -97,3 +111,106 @@ func TestKeyVal(t *testing.T) {
 	err = iter.Error()
 	assert.NoError(t, err, "iterator error")
 }
+
+func TestKeyIteratorFrom(t *testing.T) {
+	for _, backend := range []Backend{LevelDBBackend, PebbleBackend} {
+		t.Run(backend.String(), func(t *testing.T) {
+			kv, err := NewKeyValue(t.TempDir(), testKeyValParams(backend))
+			require.NoError(t, err)
+			defer func() { assert.NoError(t, kv.Close()) }()
+			for _, k := range []string{"a1", "b1", "b2", "b3", "c1"} {
+				require.NoError(t, kv.Put([]byte(k), []byte(k)))
+			}
+			for _, test := range []struct {
+				start    string
+				expected []string
+			}{
+				{"", []string{"b1", "b2", "b3"}},
+				{"a", []string{"b1", "b2", "b3"}},
+				{"b2", []string{"b2", "b3"}},
+				{"b21", []string{"b3"}},
+				{"c", nil},
+			} {
+				iter, err := kv.NewKeyIteratorFrom([]byte("b"), []byte(test.start))
+				require.NoError(t, err)
+				var keys []string
+				for iter.Next() {
+					keys = append(keys, string(iter.Key()))
+				}
+				iter.Release()
+				require.NoError(t, iter.Error())
+				assert.Equal(t, test.expected, keys, "start %q", test.start)
+			}
+		})
+	}
+}
+
+func TestNewKeyValueBackendMismatch(t *testing.T) {
+	dbDir := t.TempDir()
+	kv, err := NewKeyValue(dbDir, testKeyValParams(PebbleBackend))
+	require.NoError(t, err)
+	require.NoError(t, kv.Put([]byte("key"), []byte("value")))
+	require.NoError(t, kv.Close())
+
+	backend, ok, err := DetectBackend(dbDir)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, PebbleBackend, backend)
+
+	_, err = NewKeyValue(dbDir, testKeyValParams(LevelDBBackend))
+	assert.Error(t, err)
+
+	kv, err = NewKeyValue(dbDir, testKeyValParams(PebbleBackend))
+	require.NoError(t, err)
+	val, err := kv.Get([]byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), val)
+	require.NoError(t, kv.Close())
+}
+
+func TestParseBackend(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		backend Backend
+		err     bool
+	}{
+		{"", LevelDBBackend, false},
+		{"leveldb", LevelDBBackend, false},
+		{"pebble", PebbleBackend, false},
+		{"rocksdb", "", true},
+	} {
+		backend, err := ParseBackend(test.name)
+		if test.err {
+			assert.Error(t, err)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, test.backend, backend)
+	}
+}
+
+func TestDBCollector(t *testing.T) {
+	kv, err := NewKeyVal(t.TempDir(), testKeyValParams(LevelDBBackend))
+	require.NoError(t, err)
+	require.NoError(t, kv.Put([]byte("key"), []byte("value")))
+	_, err = kv.Get([]byte("key"))
+	require.NoError(t, err)
+
+	names := func() map[string]bool {
+		ch := make(chan prometheus.Metric, 100)
+		metricDB.Collect(ch)
+		close(ch)
+		res := make(map[string]bool)
+		for m := range ch {
+			res[m.Desc().String()] = true
+		}
+		return res
+	}
+	collected := names()
+	assert.Contains(t, collected, metricDB.cacheHits.String())
+	assert.Contains(t, collected, metricDB.ioWrite.String())
+	assert.Contains(t, collected, metricDB.compactions.String())
+
+	require.NoError(t, kv.Close())
+	assert.Empty(t, names())
+}
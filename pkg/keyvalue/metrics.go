@@ -0,0 +1,130 @@
+package keyvalue
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/coocood/freecache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const keyValueMetricsNamespace = "keyvalue"
+
+// dbCollector exports the statistics of the last opened database and its cache on every scrape.
+type dbCollector struct {
+	mu    sync.Mutex
+	db    *leveldb.DB // Nil for databases with other backends.
+	cache *freecache.Cache
+
+	cacheHits        *prometheus.Desc
+	cacheMisses      *prometheus.Desc
+	cacheEntries     *prometheus.Desc
+	cacheHitRate     *prometheus.Desc
+	ioRead           *prometheus.Desc
+	ioWrite          *prometheus.Desc
+	writeDelays      *prometheus.Desc
+	writeDelayTime   *prometheus.Desc
+	aliveSnapshots   *prometheus.Desc
+	aliveIterators   *prometheus.Desc
+	blockCacheSize   *prometheus.Desc
+	openedTables     *prometheus.Desc
+	levelSize        *prometheus.Desc
+	levelTables      *prometheus.Desc
+	compactions      *prometheus.Desc
+	compactionsSpent *prometheus.Desc
+}
+
+func newDBCollector() *dbCollector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(keyValueMetricsNamespace, "", name), help, labels, nil)
+	}
+	return &dbCollector{
+		cacheHits:        desc("cache_hits_total", "Number of hits of database cache."),
+		cacheMisses:      desc("cache_misses_total", "Number of misses of database cache."),
+		cacheEntries:     desc("cache_entries", "Number of entries in database cache."),
+		cacheHitRate:     desc("cache_hit_rate", "Ratio of hits to all lookups of database cache."),
+		ioRead:           desc("leveldb_io_read_bytes_total", "Number of bytes read by LevelDB."),
+		ioWrite:          desc("leveldb_io_write_bytes_total", "Number of bytes written by LevelDB."),
+		writeDelays:      desc("leveldb_write_delays_total", "Number of write delays caused by compactions."),
+		writeDelayTime:   desc("leveldb_write_delay_seconds_total", "Time of write delays caused by compactions."),
+		aliveSnapshots:   desc("leveldb_alive_snapshots", "Number of alive LevelDB snapshots."),
+		aliveIterators:   desc("leveldb_alive_iterators", "Number of alive LevelDB iterators."),
+		blockCacheSize:   desc("leveldb_block_cache_bytes", "Size of LevelDB block cache."),
+		openedTables:     desc("leveldb_opened_tables", "Number of opened LevelDB tables."),
+		levelSize:        desc("leveldb_level_size_bytes", "Size of LevelDB level.", "level"),
+		levelTables:      desc("leveldb_level_tables", "Number of tables on LevelDB level.", "level"),
+		compactions:      desc("leveldb_compactions_total", "Number of LevelDB compactions by type.", "type"),
+		compactionsSpent: desc("leveldb_level_compaction_seconds_total", "Time of compactions of LevelDB level.", "level"),
+	}
+}
+
+var metricDB = newDBCollector()
+
+func init() {
+	prometheus.MustRegister(metricDB)
+}
+
+func (c *dbCollector) set(db *leveldb.DB, cache *freecache.Cache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.db = db
+	c.cache = cache
+}
+
+// unset stops reporting of the closed database if it's still the reported one.
+func (c *dbCollector) unset(cache *freecache.Cache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cache == cache {
+		c.db = nil
+		c.cache = nil
+	}
+}
+
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cache == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.cacheHits, prometheus.CounterValue, float64(c.cache.HitCount()))
+	ch <- prometheus.MustNewConstMetric(c.cacheMisses, prometheus.CounterValue, float64(c.cache.MissCount()))
+	ch <- prometheus.MustNewConstMetric(c.cacheEntries, prometheus.GaugeValue, float64(c.cache.EntryCount()))
+	ch <- prometheus.MustNewConstMetric(c.cacheHitRate, prometheus.GaugeValue, c.cache.HitRate())
+	if c.db == nil {
+		return
+	}
+	var s leveldb.DBStats
+	if err := c.db.Stats(&s); err != nil {
+		return // Database is closed.
+	}
+	ch <- prometheus.MustNewConstMetric(c.ioRead, prometheus.CounterValue, float64(s.IORead))
+	ch <- prometheus.MustNewConstMetric(c.ioWrite, prometheus.CounterValue, float64(s.IOWrite))
+	ch <- prometheus.MustNewConstMetric(c.writeDelays, prometheus.CounterValue, float64(s.WriteDelayCount))
+	ch <- prometheus.MustNewConstMetric(c.writeDelayTime, prometheus.CounterValue, s.WriteDelayDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.aliveSnapshots, prometheus.GaugeValue, float64(s.AliveSnapshots))
+	ch <- prometheus.MustNewConstMetric(c.aliveIterators, prometheus.GaugeValue, float64(s.AliveIterators))
+	ch <- prometheus.MustNewConstMetric(c.blockCacheSize, prometheus.GaugeValue, float64(s.BlockCacheSize))
+	ch <- prometheus.MustNewConstMetric(c.openedTables, prometheus.GaugeValue, float64(s.OpenedTablesCount))
+	for i, size := range s.LevelSizes {
+		level := strconv.Itoa(i)
+		ch <- prometheus.MustNewConstMetric(c.levelSize, prometheus.GaugeValue, float64(size), level)
+		if i < len(s.LevelTablesCounts) {
+			ch <- prometheus.MustNewConstMetric(c.levelTables, prometheus.GaugeValue,
+				float64(s.LevelTablesCounts[i]), level)
+		}
+		if i < len(s.LevelDurations) {
+			ch <- prometheus.MustNewConstMetric(c.compactionsSpent, prometheus.CounterValue,
+				s.LevelDurations[i].Seconds(), level)
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(s.MemComp), "memory")
+	ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(s.Level0Comp), "level0")
+	ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(s.NonLevel0Comp), "non_level0")
+	ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(s.SeekComp), "seek")
+}
@@ -0,0 +1,339 @@
+package keyvalue
+
+import (
+	"bytes"
+	stderrs "errors"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+	"github.com/coocood/freecache"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const pebbleBloomBitsPerKey = 10
+
+// pebbleLogger redirects informational messages of Pebble to debug log.
+type pebbleLogger struct{}
+
+func (pebbleLogger) Infof(format string, args ...interface{}) {
+	zap.S().Debugf("Pebble: "+format, args...)
+}
+
+func (pebbleLogger) Errorf(format string, args ...interface{}) {
+	zap.S().Errorf("Pebble: "+format, args...)
+}
+
+func (pebbleLogger) Fatalf(format string, args ...interface{}) {
+	zap.S().Fatalf("Pebble: "+format, args...)
+}
+
+func (b *batch) pebbleBatch(db *pebble.DB) (*pebble.Batch, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pebbleBatch := db.NewBatch()
+	for _, pair := range b.pairs {
+		var err error
+		if pair.deletion {
+			err = pebbleBatch.Delete(pair.key, nil)
+		} else {
+			err = pebbleBatch.Set(pair.key, pair.value, nil)
+		}
+		if err != nil {
+			return nil, errors.Wrap(stderrs.Join(err, pebbleBatch.Close()), "failed to build pebble batch")
+		}
+	}
+	return pebbleBatch, nil
+}
+
+// pebbleIterator adapts Pebble iterator to the semantics of LevelDB one: the first call to Next or Prev
+// positions the iterator at the first or the last key respectively.
+type pebbleIterator struct {
+	iter       *pebble.Iterator
+	positioned bool
+	released   bool
+	err        error
+}
+
+func (i *pebbleIterator) Key() []byte {
+	if i.released || !i.iter.Valid() {
+		return nil
+	}
+	return i.iter.Key()
+}
+
+func (i *pebbleIterator) Value() []byte {
+	if i.released || !i.iter.Valid() {
+		return nil
+	}
+	return i.iter.Value()
+}
+
+func (i *pebbleIterator) Next() bool {
+	if i.released {
+		return false
+	}
+	if !i.positioned {
+		return i.First()
+	}
+	return i.iter.Next()
+}
+
+func (i *pebbleIterator) Prev() bool {
+	if i.released {
+		return false
+	}
+	if !i.positioned {
+		return i.Last()
+	}
+	return i.iter.Prev()
+}
+
+func (i *pebbleIterator) First() bool {
+	if i.released {
+		return false
+	}
+	i.positioned = true
+	return i.iter.First()
+}
+
+func (i *pebbleIterator) Last() bool {
+	if i.released {
+		return false
+	}
+	i.positioned = true
+	return i.iter.Last()
+}
+
+func (i *pebbleIterator) Error() error {
+	if i.released {
+		return i.err
+	}
+	return i.iter.Error()
+}
+
+func (i *pebbleIterator) Release() {
+	if i.released {
+		return
+	}
+	i.released = true
+	i.err = i.iter.Close()
+}
+
+// prefixUpperBound returns the smallest key that is greater than all keys with the given prefix.
+func prefixUpperBound(prefix []byte) []byte {
+	limit := make([]byte, len(prefix))
+	copy(limit, prefix)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] < 0xff {
+			limit[i]++
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+// PebbleKeyVal is the key-value database backed by Pebble with the same bloom filter and cache as KeyVal.
+type PebbleKeyVal struct {
+	db     *pebble.DB
+	filter BloomFilter
+	cache  *freecache.Cache
+	mu     *sync.RWMutex
+}
+
+func NewPebbleKeyVal(path string, params KeyValParams) (*PebbleKeyVal, error) {
+	dbOptions := &pebble.Options{
+		Logger:       pebbleLogger{},
+		MaxOpenFiles: params.OpenFilesCacheCapacity,
+		Levels:       make([]pebble.LevelOptions, 7),
+	}
+	if params.WriteBuffer > 0 {
+		dbOptions.MemTableSize = uint64(params.WriteBuffer)
+	}
+	if params.CompactionTotalSize > 0 {
+		dbOptions.LBaseMaxBytes = int64(params.CompactionTotalSize)
+	}
+	for i := range dbOptions.Levels {
+		dbOptions.Levels[i].FilterPolicy = bloom.FilterPolicy(pebbleBloomBitsPerKey)
+		if params.CompactionTableSize > 0 {
+			dbOptions.Levels[i].TargetFileSize = int64(params.CompactionTableSize)
+		}
+	}
+	db, err := pebble.Open(path, dbOptions)
+	if err != nil {
+		return nil, err
+	}
+	cache := freecache.NewCache(params.CacheParams.Size)
+	kv := &PebbleKeyVal{db: db, cache: cache, mu: &sync.RWMutex{}}
+	filter, err := initBloomFilter(kv, params.BloomFilterParams)
+	if err != nil {
+		return nil, stderrs.Join(err, db.Close())
+	}
+	kv.filter = filter
+	metricDB.set(nil, cache)
+	return kv, nil
+}
+
+func (k *PebbleKeyVal) NewBatch() (Batch, error) {
+	return &batch{mu: &sync.Mutex{}}, nil
+}
+
+func (k *PebbleKeyVal) addToCache(key, val []byte) {
+	if err := k.cache.Set(key, val, 0); err != nil {
+		// If we can not set the value for some reason, at least make sure the old one is gone.
+		k.cache.Del(key)
+	}
+}
+
+// get returns the copy of the value, Pebble owns the returned slice until the closer is called.
+func (k *PebbleKeyVal) get(key []byte) ([]byte, error) {
+	val, closer, err := k.db.Get(key)
+	if err != nil {
+		if errors.Is(err, pebble.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	res := make([]byte, len(val))
+	copy(res, val)
+	if err := closer.Close(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (k *PebbleKeyVal) Get(key []byte) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if val, err := k.cache.Get(key); err == nil { // If `segment.NotFound` error is returned it ignored here
+		return val, nil
+	}
+	// No entry in cache, looking up in DB
+	if k.filter != nil {
+		notInTheSet, err := k.filter.notInTheSet(key)
+		if err != nil {
+			return nil, err // Hashing error here
+		}
+		if notInTheSet {
+			return nil, ErrNotFound
+		}
+	}
+	val, err := k.get(key)
+	if err != nil {
+		return nil, err
+	}
+	k.addToCache(key, val)
+	return val, nil
+}
+
+func (k *PebbleKeyVal) Has(key []byte) (bool, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.filter != nil {
+		notInTheSet, err := k.filter.notInTheSet(key)
+		if err != nil {
+			return false, err
+		}
+		if notInTheSet {
+			return false, nil
+		}
+	}
+	if _, err := k.cache.Get(key); err == nil {
+		return true, nil
+	}
+	if _, err := k.get(key); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (k *PebbleKeyVal) Delete(key []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.cache.Del(key)
+	return k.db.Delete(key, pebble.NoSync)
+}
+
+func (k *PebbleKeyVal) Put(key, val []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if err := k.db.Set(key, val, pebble.NoSync); err != nil {
+		return err
+	}
+	if err := k.filter.add(key); err != nil {
+		return err
+	}
+	k.addToCache(key, val)
+	return nil
+}
+
+func (k *PebbleKeyVal) Flush(b1 Batch) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	b, ok := b1.(*batch)
+	if !ok {
+		return errors.New("can't convert Batch interface to pebble batch")
+	}
+	pb, err := b.pebbleBatch(k.db)
+	if err != nil {
+		return err
+	}
+	if err := pb.Commit(pebble.NoSync); err != nil {
+		return stderrs.Join(err, pb.Close())
+	}
+	if err := pb.Close(); err != nil {
+		return err
+	}
+	b.addToCache(k.cache)
+	if err := b.addToFilter(k.filter); err != nil {
+		return err
+	}
+	b.Reset()
+	return nil
+}
+
+func (k *PebbleKeyVal) NewKeyIterator(prefix []byte) (Iterator, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	var opts *pebble.IterOptions
+	if len(prefix) > 0 {
+		opts = &pebble.IterOptions{LowerBound: prefix, UpperBound: prefixUpperBound(prefix)}
+	}
+	iter, err := k.db.NewIter(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pebbleIterator{iter: iter}, nil
+}
+
+func (k *PebbleKeyVal) NewKeyIteratorFrom(prefix, start []byte) (Iterator, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	opts := &pebble.IterOptions{LowerBound: prefix, UpperBound: prefixUpperBound(prefix)}
+	if bytes.Compare(start, prefix) > 0 {
+		opts.LowerBound = start
+	}
+	iter, err := k.db.NewIter(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pebbleIterator{iter: iter}, nil
+}
+
+func (k *PebbleKeyVal) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	metricDB.unset(k.cache)
+	zap.S().Infof("Cache hit rate: %v", k.cache.HitRate())
+	err := storeBloomFilter(k.filter)
+	if err != nil {
+		zap.S().Errorf("Failed to save bloom filter: %v", err)
+	} else {
+		zap.S().Info("Bloom filter stored successfully")
+	}
+	return k.db.Close()
+}
@@ -23,6 +23,14 @@ func (a *OrderedBlocks) Contains(sig proto.BlockID) bool {
 	return ok
 }
 
+// Received reports whether the block and, for light node, its snapshot are received.
+func (a *OrderedBlocks) Received(sig proto.BlockID, isLightNode bool) bool {
+	if a.blocks[sig] == nil {
+		return false
+	}
+	return !isLightNode || a.snapshots[sig] != nil
+}
+
 func (a *OrderedBlocks) SetBlock(b *proto.Block) {
 	a.blocks[b.BlockID()] = b
 }
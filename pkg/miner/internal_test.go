@@ -7,6 +7,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
 )
 
 func TestMineBlock(t *testing.T) {
@@ -20,7 +21,8 @@ func TestMineBlock(t *testing.T) {
 	require.NoError(t, err)
 	parentSig := crypto.MustSignatureFromBase58("4f6Nkihj7j3t2ohNPk69MUZzpdHHwXG9hM2qjgeRmKmDPFiRYeedv6ewc9dhvNo1BxvE5CTgTjTTyAYPfR42eBXP")
 	parent := proto.NewBlockIDFromSignature(parentSig)
-	b, err := MineBlock(4, nxt, kp, []settings.Feature{13, 14}, 1581610238465, parent, 600000000, scheme)
+	signer := wallet.NewEmbeddedWallet(nil, wallet.Stub{S: [][]byte{[]byte("abc")}}, scheme)
+	b, err := MineBlock(4, nxt, kp.Public, signer, []settings.Feature{13, 14}, 1581610238465, parent, 600000000, scheme)
 	require.NoError(t, err)
 
 	bts, err := b.MarshalBinary(scheme)
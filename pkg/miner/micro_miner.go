@@ -23,6 +23,7 @@ var StateChangedErr = errors.New("state changed")
 type MicroMiner struct {
 	state  state.State
 	utx    types.UtxPool
+	signer types.Signer
 	scheme proto.Scheme
 }
 
@@ -30,11 +31,12 @@ func NewMicroMiner(services services.Services) *MicroMiner {
 	return &MicroMiner{
 		state:  services.State,
 		utx:    services.UtxPool,
+		signer: services.Wallet,
 		scheme: services.Scheme,
 	}
 }
 
-func (a *MicroMiner) Micro(minedBlock *proto.Block, rest proto.MiningLimits, keyPair proto.KeyPair) (*proto.Block, *proto.MicroBlock, proto.MiningLimits, error) {
+func (a *MicroMiner) Micro(minedBlock *proto.Block, rest proto.MiningLimits, pk crypto.PublicKey) (*proto.Block, *proto.MicroBlock, proto.MiningLimits, error) {
 	// way to stop mine microblocks
 	if minedBlock == nil {
 		return nil, nil, rest, errors.New("no block provided")
@@ -172,13 +174,11 @@ func (a *MicroMiner) Micro(minedBlock *proto.Block, rest proto.MiningLimits, key
 	if err != nil {
 		return nil, nil, rest, err
 	}
-	sk := keyPair.Secret
-
 	err = newBlock.SetTransactionsRootIfPossible(a.scheme)
 	if err != nil {
 		return nil, nil, rest, err
 	}
-	err = newBlock.Sign(a.scheme, keyPair.Secret)
+	err = a.signer.SignBlock(pk, newBlock)
 	if err != nil {
 		return nil, nil, rest, err
 	}
@@ -188,7 +188,7 @@ func (a *MicroMiner) Micro(minedBlock *proto.Block, rest proto.MiningLimits, key
 	}
 	micro := proto.MicroBlock{
 		VersionField:          byte(newBlock.Version),
-		SenderPK:              keyPair.Public,
+		SenderPK:              pk,
 		Transactions:          transactions,
 		TransactionCount:      uint32(txCount),
 		Reference:             a.state.TopBlock().BlockID(),
@@ -197,7 +197,7 @@ func (a *MicroMiner) Micro(minedBlock *proto.Block, rest proto.MiningLimits, key
 		StateHash:             sh,
 	}
 
-	err = micro.Sign(a.scheme, sk)
+	err = a.signer.SignMicroBlock(pk, &micro)
 	if err != nil {
 		return nil, nil, rest, err
 	}
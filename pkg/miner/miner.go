@@ -6,6 +6,7 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/miner/scheduler"
 	"github.com/wavesplatform/gowaves/pkg/node/messages"
 	"github.com/wavesplatform/gowaves/pkg/node/peers"
@@ -22,6 +23,7 @@ type MicroblockMiner struct {
 	peer        peers.PeerManager
 	constraints Constraints
 	services    services.Services
+	signer      types.Signer
 	features    Features
 	reward      int64
 }
@@ -33,13 +35,14 @@ func NewMicroblockMiner(services services.Services, features Features, reward in
 		peer:        services.Peers,
 		constraints: DefaultConstraints(),
 		services:    services,
+		signer:      services.Wallet,
 		features:    features,
 		reward:      reward,
 	}
 }
 
 func (a *MicroblockMiner) MineKeyBlock(
-	_ context.Context, t proto.Timestamp, k proto.KeyPair, parent proto.BlockID, baseTarget types.BaseTarget,
+	_ context.Context, t proto.Timestamp, pk crypto.PublicKey, parent proto.BlockID, baseTarget types.BaseTarget,
 	gs []byte, _ []byte,
 ) (*proto.Block, proto.MiningLimits, error) {
 	nxt := proto.NxtConsensus{
@@ -55,7 +58,7 @@ func (a *MicroblockMiner) MineKeyBlock(
 		if err != nil {
 			return nil, err
 		}
-		b, err := MineBlock(v, nxt, k, validatedFeatured, t, parent, a.reward, a.services.Scheme)
+		b, err := MineBlock(v, nxt, pk, a.signer, validatedFeatured, t, parent, a.reward, a.services.Scheme)
 		if err != nil {
 			return nil, err
 		}
@@ -85,7 +88,7 @@ func (a *MicroblockMiner) MineKeyBlock(
 		}
 		b.StateHash = &sh
 		// Resign block
-		if err = b.Sign(a.services.Scheme, k.Secret); err != nil {
+		if err = a.signer.SignBlock(pk, b); err != nil {
 			return nil, proto.MiningLimits{}, errors.Wrap(err,
 				"failed to resign key block with filled state hash field")
 		}
@@ -143,13 +146,13 @@ func Run(ctx context.Context, a types.Miner, s Mine, internalCh chan<- messages.
 		case <-ctx.Done():
 			return
 		case v := <-s.Mine():
-			block, limits, err := a.MineKeyBlock(ctx, v.Timestamp, v.KeyPair, v.Parent, v.BaseTarget, v.GenSignature,
+			block, limits, err := a.MineKeyBlock(ctx, v.Timestamp, v.PublicKey, v.Parent, v.BaseTarget, v.GenSignature,
 				v.VRF)
 			if err != nil {
 				zap.S().Errorf("Failed to mine key block: %v", err)
 				continue
 			}
-			internalCh <- messages.NewMinedBlockInternalMessage(block, limits, v.KeyPair, v.VRF)
+			internalCh <- messages.NewMinedBlockInternalMessage(block, limits, v.PublicKey, v.VRF)
 		}
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/wavesplatform/gowaves/pkg/consensus"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/state"
@@ -20,7 +21,7 @@ import (
 
 type Emit struct {
 	Timestamp    uint64
-	KeyPair      proto.KeyPair
+	PublicKey    crypto.PublicKey
 	GenSignature []byte
 	VRF          []byte
 	BaseTarget   types.BaseTarget
@@ -28,7 +29,7 @@ type Emit struct {
 }
 
 type Default struct {
-	seeder       seeder
+	signer       signer
 	mine         chan Emit
 	cancel       []func()
 	settings     *settings.BlockchainSettings
@@ -44,7 +45,8 @@ type Default struct {
 type internal interface {
 	schedule(
 		state state.StateInfo,
-		keyPairs []proto.KeyPair,
+		signer signer,
+		pks []crypto.PublicKey,
 		settings *settings.BlockchainSettings,
 		confirmedBlock *proto.Block,
 		confirmedBlockHeight uint64,
@@ -55,7 +57,8 @@ type internalImpl struct{}
 
 func (a internalImpl) schedule(
 	storage state.StateInfo,
-	keyPairs []proto.KeyPair,
+	signer signer,
+	pks []crypto.PublicKey,
 	blockchainSettings *settings.BlockchainSettings,
 	confirmedBlock *proto.Block,
 	confirmedBlockHeight uint64,
@@ -65,9 +68,9 @@ func (a internalImpl) schedule(
 		return nil, errors.Wrap(err, "failed get vrfActivated")
 	}
 	if vrfActivated {
-		return a.scheduleWithVrf(storage, keyPairs, blockchainSettings, confirmedBlock, confirmedBlockHeight)
+		return a.scheduleWithVrf(storage, signer, pks, blockchainSettings, confirmedBlock, confirmedBlockHeight)
 	}
-	return a.scheduleWithoutVrf(storage, keyPairs, blockchainSettings, confirmedBlock, confirmedBlockHeight)
+	return a.scheduleWithoutVrf(storage, pks, blockchainSettings, confirmedBlock, confirmedBlockHeight)
 }
 
 func (a internalImpl) prepareDataForSchedule(
@@ -106,7 +109,8 @@ func (a internalImpl) prepareDataForSchedule(
 
 func (a internalImpl) scheduleWithVrf(
 	storage state.StateInfo,
-	keyPairs []proto.KeyPair,
+	signer signer,
+	pks []crypto.PublicKey,
 	blockchainSettings *settings.BlockchainSettings,
 	confirmedBlock *proto.Block,
 	confirmedBlockHeight uint64,
@@ -118,11 +122,6 @@ func (a internalImpl) scheduleWithVrf(
 		return nil, err
 	}
 
-	gsp := consensus.NXTGenerationSignatureProvider
-	if blockV5Activated {
-		gsp = consensus.VRFGenerationSignatureProvider
-	}
-
 	heightForHit := pos.HeightForHit(confirmedBlockHeight)
 	hitSourceAtHeight, err := storage.HitSourceAtHeight(heightForHit)
 	if err != nil {
@@ -134,16 +133,21 @@ func (a internalImpl) scheduleWithVrf(
 	)
 
 	var out []Emit
-	for _, keyPair := range keyPairs {
-		sk := keyPair.Secret
-		genSig, err := gsp.GenerationSignature(sk, hitSourceAtHeight)
+	for _, pk := range pks {
+		// The VRF proof is the generation signature, the hit source is the VRF calculated from the proof.
+		// It's verified here, because the proof may be produced by the remote signer.
+		genSig, err := signer.SignVRF(pk, hitSourceAtHeight)
 		if err != nil {
 			zap.S().Errorf("Scheduler: Failed to schedule mining, can't get generation signature at height %d: %v",
 				heightForHit, err,
 			)
 			continue
 		}
-		source, err := gsp.HitSource(sk, hitSourceAtHeight)
+		ok, source, err := consensus.VRFGenerationSignatureProvider.VerifyGenerationSignature(pk, hitSourceAtHeight,
+			genSig)
+		if err == nil && !ok {
+			err = errors.New("invalid generation signature")
+		}
 		if err != nil {
 			zap.S().Errorf("Scheduler: Failed to schedule mining, failed to get hit source at height %d: %v",
 				heightForHit, err,
@@ -160,7 +164,7 @@ func (a internalImpl) scheduleWithVrf(
 			continue
 		}
 
-		addr, err := keyPair.Addr(blockchainSettings.AddressSchemeCharacter)
+		addr, err := proto.NewAddressFromPublicKey(blockchainSettings.AddressSchemeCharacter, pk)
 		if err != nil {
 			zap.S().Errorf("Scheduler: Failed to schedule mining, failed to create address from PK: %v", err)
 			continue
@@ -197,7 +201,7 @@ func (a internalImpl) scheduleWithVrf(
 			time.UnixMilli(int64(confirmedBlock.Timestamp+delay)).Format("2006-01-02 15:04:05.000 MST"))
 		out = append(out, Emit{
 			Timestamp:    confirmedBlock.Timestamp + delay,
-			KeyPair:      keyPair,
+			PublicKey:    pk,
 			GenSignature: genSig,
 			VRF:          vrf,
 			BaseTarget:   baseTarget,
@@ -209,7 +213,7 @@ func (a internalImpl) scheduleWithVrf(
 
 func (a internalImpl) scheduleWithoutVrf(
 	storage state.StateInfo,
-	keyPairs []proto.KeyPair,
+	pks []crypto.PublicKey,
 	blockchainSettings *settings.BlockchainSettings,
 	confirmedBlock *proto.Block,
 	confirmedBlockHeight uint64,
@@ -237,8 +241,7 @@ func (a internalImpl) scheduleWithoutVrf(
 		confirmedBlock.BaseTarget,
 	)
 	var out []Emit
-	for _, keyPair := range keyPairs {
-		pk := keyPair.Public
+	for _, pk := range pks {
 		genSigBlock := confirmedBlock.BlockHeader
 		genSig, err := gsp.GenerationSignature(pk, genSigBlock.GenSignature)
 		if err != nil {
@@ -299,7 +302,7 @@ func (a internalImpl) scheduleWithoutVrf(
 			ts, common.UnixMillisToTime(int64(ts)).String()) // #nosec: used only for logging
 		out = append(out, Emit{
 			Timestamp:    ts,
-			KeyPair:      keyPair,
+			PublicKey:    pk,
 			GenSignature: genSig,
 			VRF:          nil, // because without VRF
 			BaseTarget:   baseTarget,
@@ -309,13 +312,28 @@ func (a internalImpl) scheduleWithoutVrf(
 	return out, nil
 }
 
-type seeder interface {
-	AccountSeeds() [][]byte
+// Schedule calculates the emits of key blocks by the given accounts on top of the confirmed block at the given height.
+// Unlike Default, it neither waits for the time of emits nor checks whether the mining is allowed.
+func Schedule(
+	storage state.StateInfo,
+	signer signer,
+	pks []crypto.PublicKey,
+	blockchainSettings *settings.BlockchainSettings,
+	confirmedBlock *proto.Block,
+	confirmedBlockHeight uint64,
+) ([]Emit, error) {
+	return internalImpl{}.schedule(storage, signer, pks, blockchainSettings, confirmedBlock, confirmedBlockHeight)
+}
+
+// signer provides the public keys of accounts to mine with and generates the VRF proofs of their blocks.
+type signer interface {
+	PublicKeys() ([]crypto.PublicKey, error)
+	SignVRF(pk crypto.PublicKey, msg []byte) ([]byte, error)
 }
 
 func NewScheduler(
 	state state.State,
-	seeder seeder,
+	signer signer,
 	settings *settings.BlockchainSettings,
 	tm types.Time,
 	consensus types.MinerConsensus,
@@ -323,16 +341,16 @@ func NewScheduler(
 	if minerDelay <= 0 {
 		return nil, errors.New("minerDelay must be positive")
 	}
-	return newScheduler(internalImpl{}, state, seeder, settings, tm, consensus, minerDelay), nil
+	return newScheduler(internalImpl{}, state, signer, settings, tm, consensus, minerDelay), nil
 }
 
-func newScheduler(internal internal, state state.State, seeder seeder, settings *settings.BlockchainSettings,
+func newScheduler(internal internal, state state.State, signer signer, settings *settings.BlockchainSettings,
 	tm types.Time, consensus types.MinerConsensus, minerDelay time.Duration) *Default {
-	if seeder == nil {
-		seeder = wallet.NewWallet()
+	if signer == nil {
+		signer = wallet.NewEmbeddedWallet(nil, wallet.NewWallet(), 0)
 	}
 	return &Default{
-		seeder:       seeder,
+		signer:       signer,
 		mine:         make(chan Emit, 1),
 		settings:     settings,
 		internal:     internal,
@@ -349,12 +367,17 @@ func (a *Default) Mine() chan Emit {
 }
 
 func (a *Default) Reschedule() {
-	if len(a.seeder.AccountSeeds()) == 0 {
-		zap.S().Debug("Scheduler: Mining is not possible because no seeds registered")
+	pks, err := a.signer.PublicKeys()
+	if err != nil {
+		zap.S().Errorf("Scheduler: Failed to get public keys of accounts: %v", err)
+		return
+	}
+	if len(pks) == 0 {
+		zap.S().Debug("Scheduler: Mining is not possible because no accounts registered")
 		return
 	}
 
-	zap.S().Debugf("Scheduler: Trying to mine with %d seeds", len(a.seeder.AccountSeeds()))
+	zap.S().Debugf("Scheduler: Trying to mine with %d accounts", len(pks))
 
 	if !a.consensus.IsMiningAllowed() {
 		zap.S().Debug("Scheduler: Mining is not allowed because of lack of connected nodes")
@@ -383,13 +406,10 @@ func (a *Default) Reschedule() {
 		return
 	}
 
-	a.reschedule(block, h)
+	a.reschedule(pks, block, h)
 }
 
-func (a *Default) reschedule(confirmedBlock *proto.Block, confirmedBlockHeight uint64) {
-	if len(a.seeder.AccountSeeds()) == 0 {
-		return
-	}
+func (a *Default) reschedule(pks []crypto.PublicKey, confirmedBlock *proto.Block, confirmedBlockHeight uint64) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -400,14 +420,8 @@ func (a *Default) reschedule(confirmedBlock *proto.Block, confirmedBlockHeight u
 	a.cancel = nil
 	a.emits = nil
 
-	keyPairs, err := makeKeyPairs(a.seeder.AccountSeeds())
-	if err != nil {
-		zap.S().Errorf("Scheduler: Failed to make key pairs from seeds: %v", err)
-		return
-	}
-
 	rs, err := a.storage.MapR(func(info state.StateInfo) (i interface{}, err error) {
-		return a.internal.schedule(info, keyPairs, a.settings, confirmedBlock, confirmedBlockHeight)
+		return a.internal.schedule(info, a.signer, pks, a.settings, confirmedBlock, confirmedBlockHeight)
 	})
 	if err != nil {
 		zap.S().Errorf("Scheduler: Failed to schedule: %v", err)
@@ -445,15 +459,3 @@ func (a *Default) Emits() []Emit {
 	defer a.mu.Unlock()
 	return a.emits
 }
-
-func makeKeyPairs(seeds [][]byte) ([]proto.KeyPair, error) {
-	var out []proto.KeyPair
-	for _, bts := range seeds {
-		kp, err := proto.NewKeyPair(bts)
-		if err != nil {
-			return nil, err
-		}
-		out = append(out, kp)
-	}
-	return out, nil
-}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/state"
@@ -15,7 +16,8 @@ type mockInternal struct {
 
 func (a mockInternal) schedule(
 	state.StateInfo,
-	[]proto.KeyPair,
+	signer,
+	[]crypto.PublicKey,
 	*settings.BlockchainSettings,
 	*proto.Block,
 	uint64,
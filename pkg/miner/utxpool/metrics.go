@@ -0,0 +1,67 @@
+package utxpool
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const utxMetricsNamespace = "utx"
+
+// Reasons of transactions rejection.
+const (
+	rejectionInvalid     = "invalid"
+	rejectionDuplicate   = "duplicate"
+	rejectionTooLarge    = "too_large"
+	rejectionSizeLimit   = "size_limit"
+	rejectionSenderLimit = "sender_limit"
+	rejectionDAppLimit   = "dapp_limit"
+)
+
+// limitLabels maps the eviction reasons to the metric labels.
+var limitLabels = map[string]string{
+	EvictionReasonSizeLimit:   rejectionSizeLimit,
+	EvictionReasonSenderLimit: rejectionSenderLimit,
+	EvictionReasonDAppLimit:   rejectionDAppLimit,
+}
+
+var (
+	metricUtxTransactions = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: utxMetricsNamespace,
+			Name:      "transactions",
+			Help:      "Number of transactions in UTX pool.",
+		},
+	)
+
+	metricUtxSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: utxMetricsNamespace,
+			Name:      "size_bytes",
+			Help:      "Total size of transactions in UTX pool.",
+		},
+	)
+
+	metricUtxEvictions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: utxMetricsNamespace,
+			Name:      "evictions_total",
+			Help:      "Number of transactions evicted from UTX pool by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	metricUtxRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: utxMetricsNamespace,
+			Name:      "rejections_total",
+			Help:      "Number of transactions rejected by UTX pool by reason.",
+		},
+		[]string{"reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricUtxTransactions,
+		metricUtxSize,
+		metricUtxEvictions,
+		metricUtxRejections,
+	)
+}
@@ -0,0 +1,124 @@
+package utxpool
+
+import (
+	"bufio"
+	"encoding/binary"
+	stderrs "errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// FileName is the name of the file in the state directory the pool is saved to.
+const FileName = "utx.bin"
+
+const (
+	binaryFormat   byte = 0
+	protobufFormat byte = 1
+
+	recordHeaderSize = 1 + 4
+	maxRecordSize    = 10 * 1024 * 1024
+)
+
+// Save writes the transactions of the pool to the file in the order of priority.
+// The file is written next to the destination and renamed, so the previously saved pool stays intact on failure.
+func (a *UtxImpl) Save(path string) (err error) {
+	a.mu.Lock()
+	entries := a.sortedEntries()
+	records := make([][]byte, len(entries))
+	for i, e := range entries {
+		format := binaryFormat
+		if proto.IsProtobufTx(e.tx.T) {
+			format = protobufFormat
+		}
+		records[i] = make([]byte, recordHeaderSize+len(e.tx.B))
+		records[i][0] = format
+		binary.BigEndian.PutUint32(records[i][1:recordHeaderSize], uint32(len(e.tx.B)))
+		copy(records[i][recordHeaderSize:], e.tx.B)
+	}
+	a.mu.Unlock()
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(filepath.Clean(tmp), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create UTX pool file")
+	}
+	defer func() {
+		if err != nil {
+			err = stderrs.Join(err, os.Remove(tmp))
+		}
+	}()
+	w := bufio.NewWriter(f)
+	for _, r := range records {
+		if _, wErr := w.Write(r); wErr != nil {
+			return stderrs.Join(errors.Wrap(wErr, "failed to write UTX pool file"), f.Close())
+		}
+	}
+	if fErr := w.Flush(); fErr != nil {
+		return stderrs.Join(errors.Wrap(fErr, "failed to write UTX pool file"), f.Close())
+	}
+	if sErr := f.Sync(); sErr != nil {
+		return stderrs.Join(errors.Wrap(sErr, "failed to sync UTX pool file"), f.Close())
+	}
+	if cErr := f.Close(); cErr != nil {
+		return errors.Wrap(cErr, "failed to close UTX pool file")
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load adds the transactions saved to the file to the pool. Transactions are validated as usual,
+// so the invalid ones, for example already included in blocks, are dropped.
+// The numbers of loaded and dropped transactions are returned, missing file is not an error.
+func (a *UtxImpl) Load(path string) (int, int, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, 0, nil
+		}
+		return 0, 0, errors.Wrap(err, "failed to open UTX pool file")
+	}
+	defer func() { _ = f.Close() }()
+	r := bufio.NewReader(f)
+	loaded, dropped := 0, 0
+	header := make([]byte, recordHeaderSize)
+	for {
+		if _, rErr := io.ReadFull(r, header); rErr != nil {
+			if errors.Is(rErr, io.EOF) {
+				return loaded, dropped, nil
+			}
+			return loaded, dropped, errors.Wrap(rErr, "failed to read UTX pool file")
+		}
+		size := binary.BigEndian.Uint32(header[1:])
+		if size == 0 || size > maxRecordSize {
+			return loaded, dropped, errors.Errorf("invalid transaction size %d in UTX pool file", size)
+		}
+		b := make([]byte, size)
+		if _, rErr := io.ReadFull(r, b); rErr != nil {
+			return loaded, dropped, errors.Wrap(rErr, "failed to read UTX pool file")
+		}
+		tx, uErr := a.unmarshalTx(header[0], b)
+		if uErr != nil {
+			return loaded, dropped, uErr
+		}
+		if aErr := a.AddWithBytes(tx, b); aErr != nil {
+			dropped++
+			continue
+		}
+		loaded++
+	}
+}
+
+func (a *UtxImpl) unmarshalTx(format byte, b []byte) (proto.Transaction, error) {
+	switch format {
+	case binaryFormat:
+		return proto.BytesToTransaction(b, a.settings.AddressSchemeCharacter)
+	case protobufFormat:
+		return proto.SignedTxFromProtobuf(b)
+	default:
+		return nil, errors.Errorf("invalid transaction format %d in UTX pool file", format)
+	}
+}
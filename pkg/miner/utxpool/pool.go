@@ -3,7 +3,9 @@ package utxpool
 import (
 	"container/heap"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/mr-tron/base58"
 	"github.com/pkg/errors"
@@ -13,21 +15,65 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/types"
 )
 
-type transactionsHeap []*types.TransactionWithBytes
+const maxEvictionsHistory = 100
+
+const (
+	EvictionReasonSizeLimit   = "pool size limit reached"
+	EvictionReasonSenderLimit = "sender transactions limit reached"
+	EvictionReasonDAppLimit   = "dApp invocations limit reached"
+)
+
+// Limits restricts the contents of the pool.
+type Limits struct {
+	Size      uint64 // Max total size of transactions in bytes.
+	PerSender int    // Max number of transactions from one sender, zero means no limit.
+	PerDApp   int    // Max number of invocations of one dApp, zero means no limit.
+}
+
+type poolEntry struct {
+	tx       *types.TransactionWithBytes
+	id       crypto.Digest
+	sender   string
+	dApp     string // Empty if transaction is not an invocation.
+	priority Priority
+	seq      uint64 // Order of arrival, the earlier transaction wins in case of equal priorities.
+	index    int
+}
+
+// higher reports whether the entry has higher priority than the other one.
+func (e *poolEntry) higher(other *poolEntry) bool {
+	if e.priority.Less(other.priority) {
+		return false
+	}
+	if other.priority.Less(e.priority) {
+		return true
+	}
+	// skip division by zero, check it when we add transaction
+	feePerByte := e.tx.T.GetFee() / uint64(len(e.tx.B))
+	otherFeePerByte := other.tx.T.GetFee() / uint64(len(other.tx.B))
+	if feePerByte != otherFeePerByte {
+		return feePerByte > otherFeePerByte
+	}
+	return e.seq < other.seq
+}
+
+type transactionsHeap []*poolEntry
 
 func (a transactionsHeap) Len() int { return len(a) }
 
 func (a transactionsHeap) Less(i, j int) bool {
-	// skip division by zero, check it when we add transaction
-	return a[i].T.GetFee()/uint64(len(a[i].B)) > a[j].T.GetFee()/uint64(len(a[j].B))
+	return a[i].higher(a[j])
 }
 
 func (a transactionsHeap) Swap(i, j int) {
 	a[i], a[j] = a[j], a[i]
+	a[i].index = i
+	a[j].index = j
 }
 
 func (a *transactionsHeap) Push(x interface{}) {
-	item := x.(*types.TransactionWithBytes)
+	item := x.(*poolEntry)
+	item.index = len(*a)
 	*a = append(*a, item)
 }
 
@@ -35,6 +81,7 @@ func (a *transactionsHeap) Pop() interface{} {
 	old := *a
 	n := len(old)
 	item := old[n-1]
+	old[n-1] = nil
 	*a = old[0 : n-1]
 	return item
 }
@@ -42,18 +89,45 @@ func (a *transactionsHeap) Pop() interface{} {
 type UtxImpl struct {
 	mu             sync.Mutex
 	transactions   transactionsHeap
-	transactionIds map[crypto.Digest]struct{}
-	sizeLimit      uint64 // max transaction size in bytes
+	transactionIds map[crypto.Digest]*poolEntry
+	senders        map[string]int
+	dApps          map[string]int
+	limits         Limits
 	curSize        uint64
+	seq            uint64
+	evictions      []types.UtxEviction
 	validator      Validator
+	prioritizer    Prioritizer
+	resolver       AliasResolver
 	settings       *settings.BlockchainSettings
 }
 
+// AliasResolver returns the address of account by its alias.
+type AliasResolver interface {
+	AddrByAlias(alias proto.Alias) (proto.WavesAddress, error)
+}
+
 func New(sizeLimit uint64, validator Validator, settings *settings.BlockchainSettings) *UtxImpl {
+	return NewWithLimits(Limits{Size: sizeLimit}, validator, feePrioritizer{}, nil, settings)
+}
+
+// NewWithLimits creates the pool with the limits. The resolver is used to count the invocations of dApp by its
+// aliases and by its address together, if it's nil the invocations by aliases are counted separately.
+func NewWithLimits(
+	limits Limits,
+	validator Validator,
+	prioritizer Prioritizer,
+	resolver AliasResolver,
+	settings *settings.BlockchainSettings,
+) *UtxImpl {
 	return &UtxImpl{
-		transactionIds: make(map[crypto.Digest]struct{}),
-		sizeLimit:      sizeLimit,
+		transactionIds: make(map[crypto.Digest]*poolEntry),
+		senders:        make(map[string]int),
+		dApps:          make(map[string]int),
+		limits:         limits,
 		validator:      validator,
+		prioritizer:    prioritizer,
+		resolver:       resolver,
 		settings:       settings,
 	}
 }
@@ -63,7 +137,9 @@ func (a *UtxImpl) AllTransactions() []*types.TransactionWithBytes {
 	defer a.mu.Unlock()
 
 	res := make([]*types.TransactionWithBytes, len(a.transactions))
-	copy(res, a.transactions)
+	for i, e := range a.transactions {
+		res[i] = e.tx
+	}
 	return res
 }
 
@@ -99,11 +175,12 @@ func (a *UtxImpl) AddWithBytes(t proto.Transaction, b []byte) error {
 
 func (a *UtxImpl) addWithBytes(t proto.Transaction, b []byte) error {
 	if len(b) == 0 {
+		metricUtxRejections.WithLabelValues(rejectionInvalid).Inc()
 		return errors.New("transaction with empty bytes")
 	}
-	// exceed limit
-	if a.curSize+uint64(len(b)) > a.sizeLimit {
-		return errors.Errorf("size overflow, curSize: %d, limit: %d", a.curSize, a.sizeLimit)
+	if uint64(len(b)) > a.limits.Size {
+		metricUtxRejections.WithLabelValues(rejectionTooLarge).Inc()
+		return errors.Errorf("size overflow, transaction size: %d, limit: %d", len(b), a.limits.Size)
 	}
 	if err := t.GenerateID(a.settings.AddressSchemeCharacter); err != nil {
 		return errors.Errorf("failed to generate ID: %v", err)
@@ -113,23 +190,180 @@ func (a *UtxImpl) addWithBytes(t proto.Transaction, b []byte) error {
 		return err
 	}
 	if a.exists(t) {
+		metricUtxRejections.WithLabelValues(rejectionDuplicate).Inc()
 		return proto.NewInfoMsg(errors.Errorf("transaction with id %s exists", base58.Encode(tID)))
 	}
 	err = a.validator.Validate(t)
+	if err != nil {
+		metricUtxRejections.WithLabelValues(rejectionInvalid).Inc()
+		return err
+	}
+	entry, err := a.newEntry(t, b)
+	if err != nil {
+		return err
+	}
+	evicted, reasons, err := a.selectEvictions(entry)
 	if err != nil {
 		return err
 	}
-	tb := &types.TransactionWithBytes{
-		T: t,
-		B: b,
+	for i, e := range evicted {
+		a.remove(e)
+		a.recordEviction(e, reasons[i])
+	}
+	heap.Push(&a.transactions, entry)
+	a.transactionIds[entry.id] = entry
+	a.senders[entry.sender]++
+	if entry.dApp != "" {
+		a.dApps[entry.dApp]++
 	}
-	heap.Push(&a.transactions, tb)
-	id := makeDigest(t.GetID(a.settings.AddressSchemeCharacter))
-	a.transactionIds[id] = struct{}{}
 	a.curSize += uint64(len(b))
+	a.updateMetrics()
 	return nil
 }
 
+func (a *UtxImpl) newEntry(t proto.Transaction, b []byte) (*poolEntry, error) {
+	scheme := a.settings.AddressSchemeCharacter
+	priority, err := a.prioritizer.Priority(t)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to calculate transaction priority")
+	}
+	sender, err := t.GetSender(scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction sender")
+	}
+	entry := &poolEntry{
+		tx:       &types.TransactionWithBytes{T: t, B: b},
+		id:       makeDigest(t.GetID(scheme)),
+		sender:   sender.String(),
+		priority: priority,
+		seq:      a.seq,
+	}
+	if dApp, ok := invokedDApp(t, scheme); ok {
+		entry.dApp, err = a.dAppKey(dApp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	a.seq++
+	return entry, nil
+}
+
+// dAppKey returns the key the invocations of dApp are counted by, which is the address of dApp.
+func (a *UtxImpl) dAppKey(dApp proto.Recipient) (string, error) {
+	alias := dApp.Alias()
+	if alias == nil || a.resolver == nil {
+		return dApp.String(), nil
+	}
+	addr, err := a.resolver.AddrByAlias(*alias)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve alias '%s' of dApp", alias.String())
+	}
+	return addr.String(), nil
+}
+
+// selectEvictions selects the transactions of lower priority than the new one that should be evicted from the pool
+// to make room for it. Error is returned if the new transaction can't be added without breaking the limits.
+func (a *UtxImpl) selectEvictions(entry *poolEntry) ([]*poolEntry, []string, error) {
+	var (
+		evicted  []*poolEntry
+		reasons  []string
+		selected = make(map[*poolEntry]struct{})
+	)
+	evict := func(match func(e *poolEntry) bool, reason string) error {
+		victim := a.lowest(match, selected)
+		if victim == nil || !entry.higher(victim) {
+			metricUtxRejections.WithLabelValues(limitLabels[reason]).Inc()
+			return errors.Errorf("%s, transaction priority is too low", reason)
+		}
+		selected[victim] = struct{}{}
+		evicted = append(evicted, victim)
+		reasons = append(reasons, reason)
+		return nil
+	}
+	if a.limits.PerSender > 0 && a.senders[entry.sender] >= a.limits.PerSender {
+		sameSender := func(e *poolEntry) bool { return e.sender == entry.sender }
+		if err := evict(sameSender, EvictionReasonSenderLimit); err != nil {
+			return nil, nil, err
+		}
+	}
+	if entry.dApp != "" && a.limits.PerDApp > 0 {
+		n := a.dApps[entry.dApp]
+		for _, e := range evicted { // Eviction by sender could free the room
+			if e.dApp == entry.dApp {
+				n--
+			}
+		}
+		if n >= a.limits.PerDApp {
+			sameDApp := func(e *poolEntry) bool { return e.dApp == entry.dApp }
+			if err := evict(sameDApp, EvictionReasonDAppLimit); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	size := a.curSize + uint64(len(entry.tx.B))
+	for _, e := range evicted {
+		size -= uint64(len(e.tx.B))
+	}
+	for size > a.limits.Size {
+		anyEntry := func(*poolEntry) bool { return true }
+		if err := evict(anyEntry, EvictionReasonSizeLimit); err != nil {
+			return nil, nil, errors.Errorf("size overflow, curSize: %d, limit: %d, %v", a.curSize, a.limits.Size, err)
+		}
+		size -= uint64(len(evicted[len(evicted)-1].tx.B))
+	}
+	return evicted, reasons, nil
+}
+
+// lowest returns the entry of the lowest priority that matches and is not excluded.
+func (a *UtxImpl) lowest(match func(e *poolEntry) bool, excluded map[*poolEntry]struct{}) *poolEntry {
+	var res *poolEntry
+	for _, e := range a.transactions {
+		if _, ok := excluded[e]; ok || !match(e) {
+			continue
+		}
+		if res == nil || res.higher(e) {
+			res = e
+		}
+	}
+	return res
+}
+
+func (a *UtxImpl) remove(e *poolEntry) {
+	heap.Remove(&a.transactions, e.index)
+	a.forget(e)
+}
+
+func (a *UtxImpl) forget(e *poolEntry) {
+	delete(a.transactionIds, e.id)
+	if a.senders[e.sender]--; a.senders[e.sender] == 0 {
+		delete(a.senders, e.sender)
+	}
+	if e.dApp != "" {
+		if a.dApps[e.dApp]--; a.dApps[e.dApp] == 0 {
+			delete(a.dApps, e.dApp)
+		}
+	}
+	if uint64(len(e.tx.B)) > a.curSize {
+		panic(fmt.Sprintf("UtxImpl: size of transaction %d > than current size %d", len(e.tx.B), a.curSize))
+	}
+	a.curSize -= uint64(len(e.tx.B))
+	a.updateMetrics()
+}
+
+func (a *UtxImpl) updateMetrics() {
+	metricUtxTransactions.Set(float64(len(a.transactions)))
+	metricUtxSize.Set(float64(a.curSize))
+}
+
+func (a *UtxImpl) recordEviction(e *poolEntry, reason string) {
+	if len(a.evictions) == maxEvictionsHistory {
+		copy(a.evictions, a.evictions[1:])
+		a.evictions = a.evictions[:len(a.evictions)-1]
+	}
+	a.evictions = append(a.evictions, types.UtxEviction{ID: e.id, Reason: reason, Timestamp: time.Now()})
+	metricUtxEvictions.WithLabelValues(limitLabels[reason]).Inc()
+}
+
 func (a *UtxImpl) Count() int {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -168,17 +402,46 @@ func (a *UtxImpl) Pop() *types.TransactionWithBytes {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	if a.transactions.Len() > 0 {
-		tb := heap.Pop(&a.transactions).(*types.TransactionWithBytes)
-		delete(a.transactionIds, makeDigest(tb.T.GetID(a.settings.AddressSchemeCharacter)))
-		if uint64(len(tb.B)) > a.curSize {
-			panic(fmt.Sprintf("UtxImpl Pop: size of transaction %d > than current size %d", len(tb.B), a.curSize))
-		}
-		a.curSize -= uint64(len(tb.B))
-		return tb
+		e := heap.Pop(&a.transactions).(*poolEntry)
+		a.forget(e)
+		return e.tx
 	}
 	return nil
 }
 
+// Details returns the snapshot of the pool contents in the order of priority and the recent evictions.
+func (a *UtxImpl) Details() types.UtxPoolDetails {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entries := a.sortedEntries()
+	res := types.UtxPoolDetails{
+		Size:         a.curSize,
+		Transactions: make([]types.UtxTransactionDetails, len(entries)),
+		Evictions:    make([]types.UtxEviction, len(a.evictions)),
+	}
+	for i, e := range entries {
+		res.Transactions[i] = types.UtxTransactionDetails{
+			ID:         e.id,
+			Sender:     e.sender,
+			DApp:       e.dApp,
+			Size:       len(e.tx.B),
+			Fee:        e.tx.T.GetFee(),
+			FeeInWaves: e.priority.FeeInWaves,
+			Complexity: e.priority.Complexity,
+		}
+	}
+	copy(res.Evictions, a.evictions)
+	return res
+}
+
+// sortedEntries returns the entries of the pool sorted by priority, the highest first.
+func (a *UtxImpl) sortedEntries() []*poolEntry {
+	entries := make([]*poolEntry, len(a.transactions))
+	copy(entries, a.transactions)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].higher(entries[j]) })
+	return entries
+}
+
 func (a *UtxImpl) CurSize() uint64 {
 	a.mu.Lock()
 	defer a.mu.Unlock()
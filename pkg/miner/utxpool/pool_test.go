@@ -2,9 +2,13 @@ package utxpool
 
 import (
 	"bytes"
+	"math"
 	"math/rand"
+	"path/filepath"
 	"testing"
 
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 
 	"github.com/wavesplatform/gowaves/pkg/crypto"
@@ -15,8 +19,9 @@ import (
 )
 
 type transaction struct {
-	fee uint64
-	id  []byte
+	fee    uint64
+	id     []byte
+	sender proto.WavesAddress
 }
 
 func (a transaction) BinarySize() int {
@@ -108,7 +113,7 @@ func (a transaction) GetSenderPK() crypto.PublicKey {
 }
 
 func (a transaction) GetSender(_ proto.Scheme) (proto.Address, error) {
-	panic("not implemented")
+	return a.sender, nil
 }
 
 func tr(fee uint64) *transaction {
@@ -203,3 +208,150 @@ func TestUtxImpl_TransactionExists(t *testing.T) {
 	require.True(t, a.ExistsByID(byte_helpers.BurnWithSig.Transaction.ID.Bytes()))
 	require.False(t, a.ExistsByID(byte_helpers.TransferWithSig.Transaction.ID.Bytes()))
 }
+
+func from(sender byte, b []byte, fee uint64) *transaction {
+	return &transaction{fee: fee, id: b, sender: proto.WavesAddress{sender}}
+}
+
+func TestUtxPool_EvictsLowerPriorityOnSizeLimit(t *testing.T) {
+	a := New(10, NoOpValidator{}, settings.MustMainNetSettings())
+	require.NoError(t, a.AddWithBytes(id([]byte{1}, 10), bytes.Repeat([]byte{1}, 5)))
+	require.NoError(t, a.AddWithBytes(id([]byte{2}, 20), bytes.Repeat([]byte{1}, 5)))
+
+	// Lower priority transaction is rejected.
+	err := a.AddWithBytes(id([]byte{3}, 5), bytes.Repeat([]byte{1}, 5))
+	require.ErrorContains(t, err, "size overflow")
+	// Higher priority transaction evicts the lowest one.
+	require.NoError(t, a.AddWithBytes(id([]byte{4}, 30), bytes.Repeat([]byte{1}, 5)))
+	require.Equal(t, 2, a.Len())
+	require.False(t, a.ExistsByID(padID(1)))
+
+	details := a.Details()
+	require.Len(t, details.Evictions, 1)
+	require.Equal(t, crypto.Digest(padID(1)), details.Evictions[0].ID)
+	require.Equal(t, EvictionReasonSizeLimit, details.Evictions[0].Reason)
+	require.EqualValues(t, 10, details.Size)
+	require.Len(t, details.Transactions, 2)
+	require.EqualValues(t, 30, details.Transactions[0].Fee)
+	require.EqualValues(t, 20, details.Transactions[1].Fee)
+}
+
+func TestUtxPool_PerSenderAndDAppLimits(t *testing.T) {
+	limits := Limits{Size: 10000, PerSender: 2}
+	a := NewWithLimits(limits, NoOpValidator{}, feePrioritizer{}, nil, settings.MustMainNetSettings())
+	require.NoError(t, a.AddWithBytes(from(1, []byte{1}, 10), []byte{1}))
+	require.NoError(t, a.AddWithBytes(from(1, []byte{2}, 20), []byte{1}))
+	require.NoError(t, a.AddWithBytes(from(2, []byte{3}, 5), []byte{1}))
+
+	err := a.AddWithBytes(from(1, []byte{4}, 10), []byte{1})
+	require.ErrorContains(t, err, EvictionReasonSenderLimit)
+	require.NoError(t, a.AddWithBytes(from(1, []byte{5}, 15), []byte{1}))
+	require.False(t, a.ExistsByID(padID(1)))
+	require.True(t, a.ExistsByID(padID(3)), "transaction of other sender is kept")
+	require.Equal(t, EvictionReasonSenderLimit, a.Details().Evictions[0].Reason)
+}
+
+func TestUtxPool_DAppLimit(t *testing.T) {
+	sch := settings.MustMainNetSettings()
+	limits := Limits{Size: 10000, PerDApp: 1}
+	a := NewWithLimits(limits, NoOpValidator{}, feePrioritizer{}, nil, sch)
+	dApp := proto.MustAddressFromString("3PAWwWa6GbwcJaFzwqXQN5KQm7H96Y7SHTQ")
+	invoke := func(fee uint64, ts uint64) *proto.InvokeScriptWithProofs {
+		tx := proto.NewUnsignedInvokeScriptWithProofs(2, crypto.PublicKey{},
+			proto.NewRecipientFromAddress(dApp), proto.FunctionCall{}, nil, proto.NewOptionalAssetWaves(), fee, ts)
+		require.NoError(t, tx.GenerateID(sch.AddressSchemeCharacter))
+		return tx
+	}
+	require.NoError(t, a.AddWithBytes(invoke(500000, 1), []byte{1}))
+	err := a.AddWithBytes(invoke(400000, 2), []byte{1})
+	require.ErrorContains(t, err, EvictionReasonDAppLimit)
+	require.NoError(t, a.AddWithBytes(invoke(900000, 3), []byte{1}))
+	require.Equal(t, 1, a.Len())
+	details := a.Details()
+	require.Equal(t, dApp.String(), details.Transactions[0].DApp)
+	require.Equal(t, EvictionReasonDAppLimit, details.Evictions[0].Reason)
+}
+
+type aliasResolver map[string]proto.WavesAddress
+
+func (r aliasResolver) AddrByAlias(alias proto.Alias) (proto.WavesAddress, error) {
+	addr, ok := r[alias.Alias]
+	if !ok {
+		return proto.WavesAddress{}, errors.New("alias not found")
+	}
+	return addr, nil
+}
+
+func TestUtxPool_DAppLimitByAlias(t *testing.T) {
+	sch := settings.MustMainNetSettings()
+	dApp := proto.MustAddressFromString("3PAWwWa6GbwcJaFzwqXQN5KQm7H96Y7SHTQ")
+	alias := proto.NewAlias(sch.AddressSchemeCharacter, "dapp")
+	resolver := aliasResolver{"dapp": dApp}
+	a := NewWithLimits(Limits{Size: 10000, PerDApp: 1}, NoOpValidator{}, feePrioritizer{}, resolver, sch)
+	invoke := func(rcp proto.Recipient, fee uint64, ts uint64) *proto.InvokeScriptWithProofs {
+		tx := proto.NewUnsignedInvokeScriptWithProofs(2, crypto.PublicKey{},
+			rcp, proto.FunctionCall{}, nil, proto.NewOptionalAssetWaves(), fee, ts)
+		require.NoError(t, tx.GenerateID(sch.AddressSchemeCharacter))
+		return tx
+	}
+	require.NoError(t, a.AddWithBytes(invoke(proto.NewRecipientFromAddress(dApp), 500000, 1), []byte{1}))
+	err := a.AddWithBytes(invoke(proto.NewRecipientFromAlias(*alias), 400000, 2), []byte{1})
+	require.ErrorContains(t, err, EvictionReasonDAppLimit)
+	require.NoError(t, a.AddWithBytes(invoke(proto.NewRecipientFromAlias(*alias), 900000, 3), []byte{1}))
+	require.Equal(t, 1, a.Len())
+	require.Equal(t, dApp.String(), a.Details().Transactions[0].DApp)
+
+	unknown := proto.NewAlias(sch.AddressSchemeCharacter, "unknown")
+	err = a.AddWithBytes(invoke(proto.NewRecipientFromAlias(*unknown), 900000, 4), []byte{1})
+	require.ErrorContains(t, err, "failed to resolve alias")
+}
+
+func TestUtxPool_SaveLoad(t *testing.T) {
+	sch := settings.MustMainNetSettings()
+	a := New(10000, NoOpValidator{}, sch)
+	require.NoError(t, a.AddWithBytes(byte_helpers.BurnWithSig.Transaction, byte_helpers.BurnWithSig.TransactionBytes))
+	require.NoError(t, a.AddWithBytes(byte_helpers.TransferWithProofs.Transaction,
+		byte_helpers.TransferWithProofs.TransactionBytes))
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, a.Save(path))
+
+	b := New(10000, NoOpValidator{}, sch)
+	loaded, dropped, err := b.Load(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, loaded)
+	require.Equal(t, 0, dropped)
+	require.True(t, b.ExistsByID(byte_helpers.BurnWithSig.Transaction.ID.Bytes()))
+	require.True(t, b.ExistsByID(byte_helpers.TransferWithProofs.Transaction.ID.Bytes()))
+	require.Equal(t, a.CurSize(), b.CurSize())
+
+	loaded, dropped, err = New(10000, NoOpValidator{}, sch).Load(filepath.Join(t.TempDir(), FileName))
+	require.NoError(t, err)
+	require.Zero(t, loaded)
+	require.Zero(t, dropped)
+}
+
+func TestPriority_Less(t *testing.T) {
+	require.True(t, Priority{FeeInWaves: 100, Complexity: 200}.Less(Priority{FeeInWaves: 100, Complexity: 100}))
+	require.False(t, Priority{FeeInWaves: 200, Complexity: 200}.Less(Priority{FeeInWaves: 100, Complexity: 100}))
+	require.True(t, Priority{FeeInWaves: math.MaxUint64, Complexity: 3}.Less(Priority{FeeInWaves: math.MaxUint64, Complexity: 2}))
+}
+
+func padID(b byte) []byte {
+	d := crypto.Digest{b}
+	return d.Bytes()
+}
+
+func TestUtxPool_Metrics(t *testing.T) {
+	evictions := metricUtxEvictions.WithLabelValues(rejectionSizeLimit)
+	rejections := metricUtxRejections.WithLabelValues(rejectionSizeLimit)
+	evicted, rejected := testutil.ToFloat64(evictions), testutil.ToFloat64(rejections)
+
+	a := New(10, NoOpValidator{}, settings.MustMainNetSettings())
+	require.NoError(t, a.AddWithBytes(id([]byte{1}, 10), bytes.Repeat([]byte{1}, 6)))
+	require.Error(t, a.AddWithBytes(id([]byte{2}, 5), bytes.Repeat([]byte{1}, 6)))
+	require.NoError(t, a.AddWithBytes(id([]byte{3}, 20), bytes.Repeat([]byte{1}, 6)))
+	require.Equal(t, evicted+1, testutil.ToFloat64(evictions))
+	require.Equal(t, rejected+1, testutil.ToFloat64(rejections))
+	require.EqualValues(t, 6, testutil.ToFloat64(metricUtxSize))
+	require.EqualValues(t, 1, testutil.ToFloat64(metricUtxTransactions))
+}
@@ -0,0 +1,154 @@
+package utxpool
+
+import (
+	"math/bits"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+// baseComplexity is attributed to every transaction, it equals to the complexity of account script
+// that is verified without extra fee. So the complexity of transaction without scripts is not zero,
+// and scripts add their complexities to it.
+const baseComplexity = state.FreeVerifierComplexity
+
+// Priority of transaction in the pool is its fee in WAVES per unit of complexity.
+type Priority struct {
+	FeeInWaves uint64
+	Complexity uint64
+}
+
+// Less reports whether the priority is lower than the other one.
+func (p Priority) Less(other Priority) bool {
+	// Compare p.FeeInWaves/p.Complexity < other.FeeInWaves/other.Complexity without division and overflow.
+	hi1, lo1 := bits.Mul64(p.FeeInWaves, other.Complexity)
+	hi2, lo2 := bits.Mul64(other.FeeInWaves, p.Complexity)
+	return hi1 < hi2 || (hi1 == hi2 && lo1 < lo2)
+}
+
+// Prioritizer calculates the priority of transaction.
+type Prioritizer interface {
+	Priority(tx proto.Transaction) (Priority, error)
+}
+
+// feePrioritizer takes into account only the fee of transaction, it's used when no state is available.
+type feePrioritizer struct{}
+
+func (feePrioritizer) Priority(tx proto.Transaction) (Priority, error) {
+	return Priority{FeeInWaves: tx.GetFee(), Complexity: baseComplexity}, nil
+}
+
+type priorityState interface {
+	AssetIsSponsored(assetID proto.AssetID) (bool, error)
+	FullAssetInfo(assetID proto.AssetID) (*proto.FullAssetInfo, error)
+	ScriptBasicInfoByAccount(account proto.Recipient) (*proto.ScriptBasicInfo, error)
+	ScriptInfoByAccount(account proto.Recipient) (*proto.ScriptInfo, error)
+}
+
+// StatePrioritizer converts sponsored fees to WAVES and estimates the complexity of transaction
+// as the sum of complexities of sender's verifier and invoked dApp.
+// The complexities are the estimations stored in state, so they are the upper bounds of actual ones.
+type StatePrioritizer struct {
+	state  priorityState
+	scheme proto.Scheme
+}
+
+func NewStatePrioritizer(state priorityState, scheme proto.Scheme) *StatePrioritizer {
+	return &StatePrioritizer{state: state, scheme: scheme}
+}
+
+func (p *StatePrioritizer) Priority(tx proto.Transaction) (Priority, error) {
+	fee, err := p.feeInWaves(tx)
+	if err != nil {
+		return Priority{}, err
+	}
+	complexity := uint64(baseComplexity)
+	sender, err := tx.GetSender(p.scheme)
+	if err != nil {
+		return Priority{}, errors.Wrap(err, "failed to get sender")
+	}
+	senderAddr, err := sender.ToWavesAddress(p.scheme)
+	if err != nil {
+		return Priority{}, errors.Wrap(err, "failed to get sender")
+	}
+	c, err := p.verifierComplexity(proto.NewRecipientFromAddress(senderAddr))
+	if err != nil {
+		return Priority{}, err
+	}
+	complexity += c
+	if dApp, ok := invokedDApp(tx, p.scheme); ok {
+		info, err := p.state.ScriptInfoByAccount(dApp)
+		if err != nil && !state.IsNotFound(err) {
+			return Priority{}, errors.Wrapf(err, "failed to get script of dApp '%s'", dApp.String())
+		}
+		if info != nil {
+			complexity += info.Complexity
+		}
+	}
+	return Priority{FeeInWaves: fee, Complexity: complexity}, nil
+}
+
+func (p *StatePrioritizer) feeInWaves(tx proto.Transaction) (uint64, error) {
+	feeAsset := tx.GetFeeAsset()
+	if !feeAsset.Present {
+		return tx.GetFee(), nil
+	}
+	assetID := proto.AssetIDFromDigest(feeAsset.ID)
+	sponsored, err := p.state.AssetIsSponsored(assetID)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to check sponsorship of asset '%s'", feeAsset.ID.String())
+	}
+	if !sponsored {
+		return 0, errors.Errorf("fee asset '%s' is not sponsored", feeAsset.ID.String())
+	}
+	info, err := p.state.FullAssetInfo(assetID)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get info of asset '%s'", feeAsset.ID.String())
+	}
+	if info.SponsorshipCost == 0 {
+		return 0, errors.Errorf("fee asset '%s' is not sponsored", feeAsset.ID.String())
+	}
+	hi, lo := bits.Mul64(tx.GetFee(), state.FeeUnit)
+	if hi >= info.SponsorshipCost {
+		return 0, errors.New("fee in WAVES overflow")
+	}
+	fee, _ := bits.Div64(hi, lo, info.SponsorshipCost)
+	return fee, nil
+}
+
+func (p *StatePrioritizer) verifierComplexity(account proto.Recipient) (uint64, error) {
+	basic, err := p.state.ScriptBasicInfoByAccount(account)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return 0, nil // Account without script.
+		}
+		return 0, errors.Wrapf(err, "failed to get script of account '%s'", account.String())
+	}
+	if !basic.HasVerifier {
+		return 0, nil
+	}
+	info, err := p.state.ScriptInfoByAccount(account)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get script of account '%s'", account.String())
+	}
+	return info.Complexity, nil
+}
+
+// invokedDApp returns the dApp invoked by transaction, if it's an invocation.
+func invokedDApp(tx proto.Transaction, scheme proto.Scheme) (proto.Recipient, bool) {
+	switch t := tx.(type) {
+	case *proto.InvokeScriptWithProofs:
+		return t.ScriptRecipient, true
+	case *proto.EthereumTransaction:
+		if _, ok := t.TxKind.(*proto.EthereumInvokeScriptTxKind); ok && t.To() != nil {
+			addr, err := t.To().ToWavesAddress(scheme)
+			if err != nil {
+				return proto.Recipient{}, false
+			}
+			return proto.NewRecipientFromAddress(addr), true
+		}
+	}
+	return proto.Recipient{}, false
+}
@@ -208,6 +208,52 @@ func (mr *MockStateInfoMockRecorder) AssetBalance(account, assetID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetBalance", reflect.TypeOf((*MockStateInfo)(nil).AssetBalance), account, assetID)
 }
 
+// AssetBalanceAtHeight mocks base method.
+func (m *MockStateInfo) AssetBalanceAtHeight(account proto.Recipient, assetID proto.AssetID, height proto.Height) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssetBalanceAtHeight", account, assetID, height)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssetBalanceAtHeight indicates an expected call of AssetBalanceAtHeight.
+func (mr *MockStateInfoMockRecorder) AssetBalanceAtHeight(account, assetID, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetBalanceAtHeight", reflect.TypeOf((*MockStateInfo)(nil).AssetBalanceAtHeight), account, assetID, height)
+}
+
+// AssetBalances mocks base method.
+func (m *MockStateInfo) AssetBalances(account proto.Recipient) (map[crypto.Digest]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssetBalances", account)
+	ret0, _ := ret[0].(map[crypto.Digest]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssetBalances indicates an expected call of AssetBalances.
+func (mr *MockStateInfoMockRecorder) AssetBalances(account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetBalances", reflect.TypeOf((*MockStateInfo)(nil).AssetBalances), account)
+}
+
+// AssetDistribution mocks base method.
+func (m *MockStateInfo) AssetDistribution(assetID proto.AssetID, height proto.Height, after *proto.WavesAddress, limit int) ([]state.AssetHolder, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssetDistribution", assetID, height, after, limit)
+	ret0, _ := ret[0].([]state.AssetHolder)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AssetDistribution indicates an expected call of AssetDistribution.
+func (mr *MockStateInfoMockRecorder) AssetDistribution(assetID, height, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetDistribution", reflect.TypeOf((*MockStateInfo)(nil).AssetDistribution), assetID, height, after, limit)
+}
+
 // AssetInfo mocks base method.
 func (m *MockStateInfo) AssetInfo(assetID proto.AssetID) (*proto.AssetInfo, error) {
 	m.ctrl.T.Helper()
@@ -658,6 +704,51 @@ func (mr *MockStateInfoMockRecorder) MapR(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MapR", reflect.TypeOf((*MockStateInfo)(nil).MapR), arg0)
 }
 
+// MinEffectiveBalanceInRange mocks base method.
+func (m *MockStateInfo) MinEffectiveBalanceInRange(account proto.Recipient, startHeight, endHeight proto.Height) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MinEffectiveBalanceInRange", account, startHeight, endHeight)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MinEffectiveBalanceInRange indicates an expected call of MinEffectiveBalanceInRange.
+func (mr *MockStateInfoMockRecorder) MinEffectiveBalanceInRange(account, startHeight, endHeight interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinEffectiveBalanceInRange", reflect.TypeOf((*MockStateInfo)(nil).MinEffectiveBalanceInRange), account, startHeight, endHeight)
+}
+
+// MinFee mocks base method.
+func (m *MockStateInfo) MinFee(tx proto.Transaction) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MinFee", tx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MinFee indicates an expected call of MinFee.
+func (mr *MockStateInfoMockRecorder) MinFee(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinFee", reflect.TypeOf((*MockStateInfo)(nil).MinFee), tx)
+}
+
+// MinWavesBalanceInRange mocks base method.
+func (m *MockStateInfo) MinWavesBalanceInRange(account proto.Recipient, startHeight, endHeight proto.Height) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MinWavesBalanceInRange", account, startHeight, endHeight)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MinWavesBalanceInRange indicates an expected call of MinWavesBalanceInRange.
+func (mr *MockStateInfoMockRecorder) MinWavesBalanceInRange(account, startHeight, endHeight interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinWavesBalanceInRange", reflect.TypeOf((*MockStateInfo)(nil).MinWavesBalanceInRange), account, startHeight, endHeight)
+}
+
 // NFTList mocks base method.
 func (m *MockStateInfo) NFTList(account proto.Recipient, limit uint64, afterAssetID *proto.AssetID) ([]*proto.FullAssetInfo, error) {
 	m.ctrl.T.Helper()
@@ -808,6 +899,21 @@ func (mr *MockStateInfoMockRecorder) RetrieveEntry(account, key interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveEntry", reflect.TypeOf((*MockStateInfo)(nil).RetrieveEntry), account, key)
 }
 
+// RetrieveEntryAtHeight mocks base method.
+func (m *MockStateInfo) RetrieveEntryAtHeight(account proto.Recipient, key string, height proto.Height) (proto.DataEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveEntryAtHeight", account, key, height)
+	ret0, _ := ret[0].(proto.DataEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveEntryAtHeight indicates an expected call of RetrieveEntryAtHeight.
+func (mr *MockStateInfoMockRecorder) RetrieveEntryAtHeight(account, key, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveEntryAtHeight", reflect.TypeOf((*MockStateInfo)(nil).RetrieveEntryAtHeight), account, key, height)
+}
+
 // RetrieveIntegerEntry mocks base method.
 func (m *MockStateInfo) RetrieveIntegerEntry(account proto.Recipient, key string) (*proto.IntegerDataEntry, error) {
 	m.ctrl.T.Helper()
@@ -898,6 +1004,21 @@ func (mr *MockStateInfoMockRecorder) ScriptBasicInfoByAccount(account interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptBasicInfoByAccount", reflect.TypeOf((*MockStateInfo)(nil).ScriptBasicInfoByAccount), account)
 }
 
+// ScriptByAccountAtHeight mocks base method.
+func (m *MockStateInfo) ScriptByAccountAtHeight(account proto.Recipient, height proto.Height) (*ast.Tree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptByAccountAtHeight", account, height)
+	ret0, _ := ret[0].(*ast.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptByAccountAtHeight indicates an expected call of ScriptByAccountAtHeight.
+func (mr *MockStateInfoMockRecorder) ScriptByAccountAtHeight(account, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptByAccountAtHeight", reflect.TypeOf((*MockStateInfo)(nil).ScriptByAccountAtHeight), account, height)
+}
+
 // ScriptInfoByAccount mocks base method.
 func (m *MockStateInfo) ScriptInfoByAccount(account proto.Recipient) (*proto.ScriptInfo, error) {
 	m.ctrl.T.Helper()
@@ -1002,6 +1123,21 @@ func (mr *MockStateInfoMockRecorder) TotalWavesAmount(height interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TotalWavesAmount", reflect.TypeOf((*MockStateInfo)(nil).TotalWavesAmount), height)
 }
 
+// TraceInvoke mocks base method.
+func (m *MockStateInfo) TraceInvoke(id []byte, maxSteps int) (*state.InvokeTrace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TraceInvoke", id, maxSteps)
+	ret0, _ := ret[0].(*state.InvokeTrace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TraceInvoke indicates an expected call of TraceInvoke.
+func (mr *MockStateInfoMockRecorder) TraceInvoke(id, maxSteps interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TraceInvoke", reflect.TypeOf((*MockStateInfo)(nil).TraceInvoke), id, maxSteps)
+}
+
 // TransactionByID mocks base method.
 func (m *MockStateInfo) TransactionByID(id []byte) (proto.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -1108,6 +1244,21 @@ func (mr *MockStateInfoMockRecorder) WavesBalance(account interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WavesBalance", reflect.TypeOf((*MockStateInfo)(nil).WavesBalance), account)
 }
 
+// WavesBalanceAtHeight mocks base method.
+func (m *MockStateInfo) WavesBalanceAtHeight(account proto.Recipient, height proto.Height) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WavesBalanceAtHeight", account, height)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WavesBalanceAtHeight indicates an expected call of WavesBalanceAtHeight.
+func (mr *MockStateInfoMockRecorder) WavesBalanceAtHeight(account, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WavesBalanceAtHeight", reflect.TypeOf((*MockStateInfo)(nil).WavesBalanceAtHeight), account, height)
+}
+
 // MockStateModifier is a mock of StateModifier interface.
 type MockStateModifier struct {
 	ctrl     *gomock.Controller
@@ -1233,6 +1384,21 @@ func (mr *MockStateModifierMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStateModifier)(nil).Close))
 }
 
+// DryRunNextTx mocks base method.
+func (m *MockStateModifier) DryRunNextTx(tx proto.Transaction, currentTimestamp, parentTimestamp uint64, blockVersion proto.BlockVersion, checkSignatures bool) (*state.DryRunResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRunNextTx", tx, currentTimestamp, parentTimestamp, blockVersion, checkSignatures)
+	ret0, _ := ret[0].(*state.DryRunResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DryRunNextTx indicates an expected call of DryRunNextTx.
+func (mr *MockStateModifierMockRecorder) DryRunNextTx(tx, currentTimestamp, parentTimestamp, blockVersion, checkSignatures interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunNextTx", reflect.TypeOf((*MockStateModifier)(nil).DryRunNextTx), tx, currentTimestamp, parentTimestamp, blockVersion, checkSignatures)
+}
+
 // Map mocks base method.
 func (m *MockStateModifier) Map(arg0 func(state.NonThreadSafeState) error) error {
 	m.ctrl.T.Helper()
@@ -1367,6 +1533,21 @@ func (m *MockTxValidation) EXPECT() *MockTxValidationMockRecorder {
 	return m.recorder
 }
 
+// DryRunNextTx mocks base method.
+func (m *MockTxValidation) DryRunNextTx(tx proto.Transaction, currentTimestamp, parentTimestamp uint64, blockVersion proto.BlockVersion, checkSignatures bool) (*state.DryRunResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRunNextTx", tx, currentTimestamp, parentTimestamp, blockVersion, checkSignatures)
+	ret0, _ := ret[0].(*state.DryRunResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DryRunNextTx indicates an expected call of DryRunNextTx.
+func (mr *MockTxValidationMockRecorder) DryRunNextTx(tx, currentTimestamp, parentTimestamp, blockVersion, checkSignatures interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunNextTx", reflect.TypeOf((*MockTxValidation)(nil).DryRunNextTx), tx, currentTimestamp, parentTimestamp, blockVersion, checkSignatures)
+}
+
 // ValidateNextTx mocks base method.
 func (m *MockTxValidation) ValidateNextTx(tx proto.Transaction, currentTimestamp, parentTimestamp uint64, blockVersion proto.BlockVersion, acceptFailed bool) ([]proto.AtomicSnapshot, error) {
 	m.ctrl.T.Helper()
@@ -1583,6 +1764,52 @@ func (mr *MockStateMockRecorder) AssetBalance(account, assetID interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetBalance", reflect.TypeOf((*MockState)(nil).AssetBalance), account, assetID)
 }
 
+// AssetBalanceAtHeight mocks base method.
+func (m *MockState) AssetBalanceAtHeight(account proto.Recipient, assetID proto.AssetID, height proto.Height) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssetBalanceAtHeight", account, assetID, height)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssetBalanceAtHeight indicates an expected call of AssetBalanceAtHeight.
+func (mr *MockStateMockRecorder) AssetBalanceAtHeight(account, assetID, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetBalanceAtHeight", reflect.TypeOf((*MockState)(nil).AssetBalanceAtHeight), account, assetID, height)
+}
+
+// AssetBalances mocks base method.
+func (m *MockState) AssetBalances(account proto.Recipient) (map[crypto.Digest]uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssetBalances", account)
+	ret0, _ := ret[0].(map[crypto.Digest]uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssetBalances indicates an expected call of AssetBalances.
+func (mr *MockStateMockRecorder) AssetBalances(account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetBalances", reflect.TypeOf((*MockState)(nil).AssetBalances), account)
+}
+
+// AssetDistribution mocks base method.
+func (m *MockState) AssetDistribution(assetID proto.AssetID, height proto.Height, after *proto.WavesAddress, limit int) ([]state.AssetHolder, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssetDistribution", assetID, height, after, limit)
+	ret0, _ := ret[0].([]state.AssetHolder)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AssetDistribution indicates an expected call of AssetDistribution.
+func (mr *MockStateMockRecorder) AssetDistribution(assetID, height, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetDistribution", reflect.TypeOf((*MockState)(nil).AssetDistribution), assetID, height, after, limit)
+}
+
 // AssetInfo mocks base method.
 func (m *MockState) AssetInfo(assetID proto.AssetID) (*proto.AssetInfo, error) {
 	m.ctrl.T.Helper()
@@ -1747,6 +1974,21 @@ func (mr *MockStateMockRecorder) CurrentScore() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentScore", reflect.TypeOf((*MockState)(nil).CurrentScore))
 }
 
+// DryRunNextTx mocks base method.
+func (m *MockState) DryRunNextTx(tx proto.Transaction, currentTimestamp, parentTimestamp uint64, blockVersion proto.BlockVersion, checkSignatures bool) (*state.DryRunResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRunNextTx", tx, currentTimestamp, parentTimestamp, blockVersion, checkSignatures)
+	ret0, _ := ret[0].(*state.DryRunResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DryRunNextTx indicates an expected call of DryRunNextTx.
+func (mr *MockStateMockRecorder) DryRunNextTx(tx, currentTimestamp, parentTimestamp, blockVersion, checkSignatures interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunNextTx", reflect.TypeOf((*MockState)(nil).DryRunNextTx), tx, currentTimestamp, parentTimestamp, blockVersion, checkSignatures)
+}
+
 // EnrichedFullAssetInfo mocks base method.
 func (m *MockState) EnrichedFullAssetInfo(assetID proto.AssetID) (*proto.EnrichedFullAssetInfo, error) {
 	m.ctrl.T.Helper()
@@ -2061,6 +2303,51 @@ func (mr *MockStateMockRecorder) MapR(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MapR", reflect.TypeOf((*MockState)(nil).MapR), arg0)
 }
 
+// MinEffectiveBalanceInRange mocks base method.
+func (m *MockState) MinEffectiveBalanceInRange(account proto.Recipient, startHeight, endHeight proto.Height) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MinEffectiveBalanceInRange", account, startHeight, endHeight)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MinEffectiveBalanceInRange indicates an expected call of MinEffectiveBalanceInRange.
+func (mr *MockStateMockRecorder) MinEffectiveBalanceInRange(account, startHeight, endHeight interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinEffectiveBalanceInRange", reflect.TypeOf((*MockState)(nil).MinEffectiveBalanceInRange), account, startHeight, endHeight)
+}
+
+// MinFee mocks base method.
+func (m *MockState) MinFee(tx proto.Transaction) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MinFee", tx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MinFee indicates an expected call of MinFee.
+func (mr *MockStateMockRecorder) MinFee(tx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinFee", reflect.TypeOf((*MockState)(nil).MinFee), tx)
+}
+
+// MinWavesBalanceInRange mocks base method.
+func (m *MockState) MinWavesBalanceInRange(account proto.Recipient, startHeight, endHeight proto.Height) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MinWavesBalanceInRange", account, startHeight, endHeight)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MinWavesBalanceInRange indicates an expected call of MinWavesBalanceInRange.
+func (mr *MockStateMockRecorder) MinWavesBalanceInRange(account, startHeight, endHeight interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinWavesBalanceInRange", reflect.TypeOf((*MockState)(nil).MinWavesBalanceInRange), account, startHeight, endHeight)
+}
+
 // NFTList mocks base method.
 func (m *MockState) NFTList(account proto.Recipient, limit uint64, afterAssetID *proto.AssetID) ([]*proto.FullAssetInfo, error) {
 	m.ctrl.T.Helper()
@@ -2237,6 +2524,21 @@ func (mr *MockStateMockRecorder) RetrieveEntry(account, key interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveEntry", reflect.TypeOf((*MockState)(nil).RetrieveEntry), account, key)
 }
 
+// RetrieveEntryAtHeight mocks base method.
+func (m *MockState) RetrieveEntryAtHeight(account proto.Recipient, key string, height proto.Height) (proto.DataEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetrieveEntryAtHeight", account, key, height)
+	ret0, _ := ret[0].(proto.DataEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetrieveEntryAtHeight indicates an expected call of RetrieveEntryAtHeight.
+func (mr *MockStateMockRecorder) RetrieveEntryAtHeight(account, key, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetrieveEntryAtHeight", reflect.TypeOf((*MockState)(nil).RetrieveEntryAtHeight), account, key, height)
+}
+
 // RetrieveIntegerEntry mocks base method.
 func (m *MockState) RetrieveIntegerEntry(account proto.Recipient, key string) (*proto.IntegerDataEntry, error) {
 	m.ctrl.T.Helper()
@@ -2355,6 +2657,21 @@ func (mr *MockStateMockRecorder) ScriptBasicInfoByAccount(account interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptBasicInfoByAccount", reflect.TypeOf((*MockState)(nil).ScriptBasicInfoByAccount), account)
 }
 
+// ScriptByAccountAtHeight mocks base method.
+func (m *MockState) ScriptByAccountAtHeight(account proto.Recipient, height proto.Height) (*ast.Tree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptByAccountAtHeight", account, height)
+	ret0, _ := ret[0].(*ast.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptByAccountAtHeight indicates an expected call of ScriptByAccountAtHeight.
+func (mr *MockStateMockRecorder) ScriptByAccountAtHeight(account, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptByAccountAtHeight", reflect.TypeOf((*MockState)(nil).ScriptByAccountAtHeight), account, height)
+}
+
 // ScriptInfoByAccount mocks base method.
 func (m *MockState) ScriptInfoByAccount(account proto.Recipient) (*proto.ScriptInfo, error) {
 	m.ctrl.T.Helper()
@@ -2473,6 +2790,21 @@ func (mr *MockStateMockRecorder) TotalWavesAmount(height interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TotalWavesAmount", reflect.TypeOf((*MockState)(nil).TotalWavesAmount), height)
 }
 
+// TraceInvoke mocks base method.
+func (m *MockState) TraceInvoke(id []byte, maxSteps int) (*state.InvokeTrace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TraceInvoke", id, maxSteps)
+	ret0, _ := ret[0].(*state.InvokeTrace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TraceInvoke indicates an expected call of TraceInvoke.
+func (mr *MockStateMockRecorder) TraceInvoke(id, maxSteps interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TraceInvoke", reflect.TypeOf((*MockState)(nil).TraceInvoke), id, maxSteps)
+}
+
 // TransactionByID mocks base method.
 func (m *MockState) TransactionByID(id []byte) (proto.Transaction, error) {
 	m.ctrl.T.Helper()
@@ -2607,3 +2939,18 @@ func (mr *MockStateMockRecorder) WavesBalance(account interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WavesBalance", reflect.TypeOf((*MockState)(nil).WavesBalance), account)
 }
+
+// WavesBalanceAtHeight mocks base method.
+func (m *MockState) WavesBalanceAtHeight(account proto.Recipient, height proto.Height) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WavesBalanceAtHeight", account, height)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WavesBalanceAtHeight indicates an expected call of WavesBalanceAtHeight.
+func (mr *MockStateMockRecorder) WavesBalanceAtHeight(account, height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WavesBalanceAtHeight", reflect.TypeOf((*MockState)(nil).WavesBalanceAtHeight), account, height)
+}
@@ -3,6 +3,7 @@ package networking
 import (
 	"fmt"
 	"net"
+	"time"
 )
 
 type addressable interface {
@@ -10,6 +11,10 @@ type addressable interface {
 	RemoteAddr() net.Addr
 }
 
+type writeDeadlineSetter interface {
+	SetWriteDeadline(t time.Time) error
+}
+
 type sessionAddress struct {
 	addr string
 }
@@ -84,10 +84,10 @@ func newSession(ctx context.Context, config *Config, conn io.ReadWriteCloser, tp
 	attrs := append(sa[:], config.attributes...)
 	s.logger = slog.New(slogHandler).With(attrs...)
 
-	s.g.Run(s.receiveLoop)
-	s.g.Run(s.sendLoop)
+	s.run(s.receiveLoop)
+	s.run(s.sendLoop)
 	if s.config.keepAlive {
-		s.g.Run(s.keepaliveLoop)
+		s.run(s.keepaliveLoop)
 	}
 
 	return s, nil
@@ -137,6 +137,26 @@ func (s *Session) Close() error {
 	return err
 }
 
+// run starts the loop in the task group. If the loop fails, the session is terminated, so the other loops exit too.
+func (s *Session) run(loop func() error) {
+	s.g.Run(func() error {
+		err := loop()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			s.terminate()
+		}
+		return err
+	})
+}
+
+// terminate interrupts the loops of the session by closing the underlying connection and cancelling the context.
+// Unlike Close, it doesn't wait for the loops to finish, so it's safe to call it from the loops.
+func (s *Session) terminate() {
+	s.cancel()
+	if err := s.conn.Close(); err != nil {
+		s.logger.Debug("Failed to close underlying connection on termination", "error", err)
+	}
+}
+
 // Write is used to write to the session. It is safe to call Write and/or Close concurrently.
 func (s *Session) Write(msg []byte) (int, error) {
 	s.sendLock.Lock()
@@ -218,7 +238,12 @@ func (s *Session) sendLoop() error {
 
 			if dataBuf.Len() > 0 {
 				s.logger.Debug("Writing data into connection", "len", len(dataBuf.Bytes()))
-				_, err := s.conn.Write(dataBuf.Bytes()) // TODO: We are locking here, because no timeout set on connection itself.
+				if err := s.setWriteDeadline(); err != nil {
+					s.logger.Error("Failed to set write deadline", "error", err)
+					s.asyncSendErr(packet.err, err)
+					return err
+				}
+				_, err := s.conn.Write(dataBuf.Bytes())
 				if err != nil {
 					s.logger.Error("Failed to write data into connection", "error", err)
 					s.asyncSendErr(packet.err, err)
@@ -233,6 +258,15 @@ func (s *Session) sendLoop() error {
 	}
 }
 
+// setWriteDeadline sets the write deadline on the underlying connection if it supports deadlines, so the write
+// to a dead connection doesn't block the send loop forever.
+func (s *Session) setWriteDeadline() error {
+	if d, ok := s.conn.(writeDeadlineSetter); ok {
+		return d.SetWriteDeadline(time.Now().Add(s.config.connectionWriteTimeout))
+	}
+	return nil
+}
+
 // receiveLoop continues to receive data until a fatal error is encountered or underlying connection is closed.
 // Receive loop works after handshake and accepts only length-prepended messages.
 func (s *Session) receiveLoop() error {
@@ -241,8 +275,8 @@ func (s *Session) receiveLoop() error {
 	}
 	for {
 		if err := s.receive(); err != nil {
+			s.config.handler.OnClose(s)
 			if errors.Is(err, ErrConnectionClosedOnRead) {
-				s.config.handler.OnClose(s)
 				return nil // Exit normally on connection close.
 			}
 			return err
@@ -307,6 +341,7 @@ func (s *Session) readMessage(hdr Header) error {
 			s.logger.Error("Failed to discard message", "error", err)
 			return err
 		}
+		return nil
 	}
 	// Read the new data
 	if err := s.readMessagePayload(hdr, s.bufRead); err != nil {
@@ -370,7 +405,7 @@ func (s *Session) keepaliveLoop() error {
 				if errors.Is(sndErr, ErrSessionShutdown) {
 					return nil // Exit normally on session termination.
 				}
-				s.logger.Error("Failed to send ping message", "error", err)
+				s.logger.Error("Failed to send ping message", "error", sndErr)
 				return ErrKeepAliveTimeout
 			}
 		}
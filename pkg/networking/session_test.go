@@ -434,6 +434,127 @@ func TestCloseParentContext(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDiscardedMessage(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	p := netmocks.NewMockProtocol(t)
+	p.On("EmptyHandshake").Return(&textHandshake{})
+	p.On("EmptyHeader").Return(&textHeader{})
+
+	clientHandler := netmocks.NewMockHandler(t)
+	serverHandler := netmocks.NewMockHandler(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := testConnPipe()
+	net := networking.NewNetwork()
+
+	cs, err := net.NewSession(ctx, clientConn, testConfig(t, p, clientHandler, "client"))
+	require.NoError(t, err)
+	ss, err := net.NewSession(ctx, serverConn, testConfig(t, p, serverHandler, "server"))
+	require.NoError(t, err)
+
+	p.On("IsAcceptableHandshake", ss, &textHandshake{v: "hello"}).Once().Return(true)
+	p.On("IsAcceptableMessage", ss, &textHeader{l: 4}).Once().Return(false)
+	p.On("IsAcceptableMessage", ss, &textHeader{l: 6}).Once().Return(true)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	serverHandler.On("OnHandshake", ss, &textHandshake{v: "hello"}).Once().Return()
+	// Only the acceptable message is received, the payload of discarded one is skipped.
+	serverHandler.On("OnReceive", ss, bytes.NewBuffer(encodeMessage("Accept"))).Once().Return().
+		Run(func(_ mock.Arguments) { wg.Done() })
+
+	_, err = cs.Write([]byte("hello"))
+	require.NoError(t, err)
+	_, err = cs.Write(encodeMessage("Skip"))
+	require.NoError(t, err)
+	_, err = cs.Write(encodeMessage("Accept"))
+	require.NoError(t, err)
+	wg.Wait()
+
+	clientHandler.On("OnClose", cs).Return()
+	serverHandler.On("OnClose", ss).Return()
+	assert.NoError(t, cs.Close())
+	assert.NoError(t, ss.Close())
+}
+
+func TestTerminationOnReceiveFailure(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	p := netmocks.NewMockProtocol(t)
+	p.On("EmptyHandshake").Return(&textHandshake{})
+
+	clientHandler := netmocks.NewMockHandler(t)
+	serverHandler := netmocks.NewMockHandler(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := testConnPipe()
+	net := networking.NewNetwork()
+
+	cs, err := net.NewSession(ctx, clientConn, testConfig(t, p, clientHandler, "client"))
+	require.NoError(t, err)
+	ss, err := net.NewSession(ctx, serverConn, testConfig(t, p, serverHandler, "server"))
+	require.NoError(t, err)
+
+	p.On("IsAcceptableHandshake", ss, &textHandshake{v: "hello"}).Once().Return(false)
+	serverHandler.On("OnHandshakeFailed", ss, &textHandshake{v: "hello"}).Once().Return()
+	serverHandler.On("OnClose", ss).Once().Return()
+
+	// Rejected handshake terminates the server session, so the client session is closed by the other side.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	clientHandler.On("OnClose", cs).Once().Return().Run(func(_ mock.Arguments) { wg.Done() })
+
+	_, err = cs.Write([]byte("hello"))
+	require.NoError(t, err)
+	wg.Wait()
+
+	assert.ErrorIs(t, ss.Close(), networking.ErrUnacceptableHandshake)
+	assert.NoError(t, cs.Close())
+}
+
+func TestTerminationOnMessageReadFailure(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	p := netmocks.NewMockProtocol(t)
+	p.On("EmptyHandshake").Return(&textHandshake{})
+	p.On("EmptyHeader").Return(&invalidHeader{})
+
+	clientHandler := netmocks.NewMockHandler(t)
+	serverHandler := netmocks.NewMockHandler(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := testConnPipe()
+	net := networking.NewNetwork()
+
+	cs, err := net.NewSession(ctx, clientConn, testConfig(t, p, clientHandler, "client"))
+	require.NoError(t, err)
+	ss, err := net.NewSession(ctx, serverConn, testConfig(t, p, serverHandler, "server"))
+	require.NoError(t, err)
+
+	p.On("IsAcceptableHandshake", ss, &textHandshake{v: "hello"}).Once().Return(true)
+	serverHandler.On("OnHandshake", ss, &textHandshake{v: "hello"}).Once().Return()
+
+	// Failure to read the message header is reported to the handler of the terminated session once.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	serverHandler.On("OnClose", ss).Once().Return().Run(func(_ mock.Arguments) { wg.Done() })
+	clientHandler.On("OnClose", cs).Once().Return().Run(func(_ mock.Arguments) { wg.Done() })
+
+	_, err = cs.Write([]byte("hello"))
+	require.NoError(t, err)
+	wg.Wait()
+
+	assert.ErrorIs(t, ss.Close(), errInvalidHeader)
+	assert.NoError(t, cs.Close())
+}
+
 func testConfig(t testing.TB, p networking.Protocol, h networking.Handler, direction string) *networking.Config {
 	log := slogt.New(t)
 	return networking.NewConfig().
@@ -532,3 +653,14 @@ func (h *textHeader) WriteTo(w io.Writer) (int64, error) {
 	n, err := w.Write(buf)
 	return int64(n), err
 }
+
+var errInvalidHeader = errors.New("invalid header")
+
+// invalidHeader fails to read the header without reading the connection.
+type invalidHeader struct {
+	textHeader
+}
+
+func (h *invalidHeader) ReadFrom(io.Reader) (int64, error) {
+	return 0, errInvalidHeader
+}
@@ -0,0 +1,270 @@
+package blockchain_updates
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/events"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+const subscriptionBufferSize = 1024
+
+// ErrSubscriptionOverflow is returned by Subscription.Err if the subscriber was too slow to read updates.
+var ErrSubscriptionOverflow = errors.New("subscription buffer overflow")
+
+type BlocksApplier interface {
+	BlockExists(state state.State, block *proto.Block) (bool, error)
+	Apply(state state.State, block []*proto.Block) (proto.Height, error)
+	ApplyMicro(state state.State, block *proto.Block) (proto.Height, error)
+	ApplyWithSnapshots(state state.State, block []*proto.Block, snapshots []*proto.BlockSnapshot) (proto.Height, error)
+	ApplyMicroWithSnapshots(state state.State, block *proto.Block, snapshots *proto.BlockSnapshot) (proto.Height, error)
+	RollbackTo(state state.State, blockID proto.BlockID) error
+}
+
+// Subscription delivers BlockchainUpdated events produced after the moment of subscription.
+// The channel returned by Updates is closed when the subscription is terminated, Err returns the reason.
+type Subscription struct {
+	updates chan *events.BlockchainUpdated
+	err     error
+}
+
+func (s *Subscription) Updates() <-chan *events.BlockchainUpdated {
+	return s.updates
+}
+
+// Err returns the reason of subscription termination. It must be called only after the updates channel is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Tip describes the last block reported by the tracker.
+type Tip struct {
+	Height  proto.Height
+	BlockID proto.BlockID
+}
+
+// Tracker is the BlocksApplier decorator, which tracks the changes of the blockchain made by the node
+// and publishes them as BlockchainUpdated events to subscribers.
+type Tracker struct {
+	applier BlocksApplier
+	scheme  proto.Scheme
+
+	mu     sync.Mutex
+	height proto.Height  // Height of the last reported block.
+	top    proto.BlockID // ID of the last reported block.
+	txs    int           // Number of transactions in the last reported block.
+	subs   map[*Subscription]struct{}
+}
+
+func NewTracker(applier BlocksApplier, st state.StateInfo, scheme proto.Scheme) (*Tracker, error) {
+	height, err := st.Height()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get state height")
+	}
+	top := st.TopBlock()
+	return &Tracker{
+		applier: applier,
+		scheme:  scheme,
+		height:  height,
+		top:     top.BlockID(),
+		txs:     len(top.Transactions),
+		subs:    make(map[*Subscription]struct{}),
+	}, nil
+}
+
+// Tip returns the last reported block.
+func (t *Tracker) Tip() Tip {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Tip{Height: t.height, BlockID: t.top}
+}
+
+// Subscribe registers new subscription and returns it along with the last reported block.
+// All the events after this block will be delivered to the subscription.
+func (t *Tracker) Subscribe() (*Subscription, Tip) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &Subscription{updates: make(chan *events.BlockchainUpdated, subscriptionBufferSize)}
+	t.subs[s] = struct{}{}
+	return s, Tip{Height: t.height, BlockID: t.top}
+}
+
+// Unsubscribe terminates the subscription. It's safe to call it for already terminated subscription.
+func (t *Tracker) Unsubscribe(s *Subscription) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.terminate(s, nil)
+}
+
+func (t *Tracker) BlockExists(state state.State, block *proto.Block) (bool, error) {
+	return t.applier.BlockExists(state, block)
+}
+
+func (t *Tracker) Apply(state state.State, blocks []*proto.Block) (proto.Height, error) {
+	if len(blocks) == 0 {
+		return t.applier.Apply(state, blocks)
+	}
+	removed := t.beforeApply(state, blocks[0].Parent)
+	h, err := t.applier.Apply(state, blocks)
+	if err != nil {
+		return h, err
+	}
+	t.afterApply(state, blocks[0].Parent, removed, false)
+	return h, nil
+}
+
+func (t *Tracker) ApplyMicro(state state.State, block *proto.Block) (proto.Height, error) {
+	t.beforeApply(state, block.Parent)
+	h, err := t.applier.ApplyMicro(state, block)
+	if err != nil {
+		return h, err
+	}
+	t.afterApply(state, block.Parent, nil, true)
+	return h, nil
+}
+
+func (t *Tracker) ApplyWithSnapshots(
+	state state.State,
+	blocks []*proto.Block,
+	snapshots []*proto.BlockSnapshot,
+) (proto.Height, error) {
+	if len(blocks) == 0 {
+		return t.applier.ApplyWithSnapshots(state, blocks, snapshots)
+	}
+	removed := t.beforeApply(state, blocks[0].Parent)
+	h, err := t.applier.ApplyWithSnapshots(state, blocks, snapshots)
+	if err != nil {
+		return h, err
+	}
+	t.afterApply(state, blocks[0].Parent, removed, false)
+	return h, nil
+}
+
+func (t *Tracker) ApplyMicroWithSnapshots(
+	state state.State,
+	block *proto.Block,
+	snapshot *proto.BlockSnapshot,
+) (proto.Height, error) {
+	t.beforeApply(state, block.Parent)
+	h, err := t.applier.ApplyMicroWithSnapshots(state, block, snapshot)
+	if err != nil {
+		return h, err
+	}
+	t.afterApply(state, block.Parent, nil, true)
+	return h, nil
+}
+
+// RollbackTo rolls back the state to the given block and reports the rollback with the removed blocks.
+func (t *Tracker) RollbackTo(state state.State, blockID proto.BlockID) error {
+	removed := t.beforeApply(state, blockID)
+	if err := t.applier.RollbackTo(state, blockID); err != nil {
+		return err
+	}
+	t.afterApply(state, blockID, removed, false)
+	return nil
+}
+
+// beforeApply reports the rollbacks made by passing the tracker and returns the blocks that are going to be
+// removed by the rollback to the given parent block. The removed blocks of the rollbacks made by passing the
+// tracker are unknown, so such rollbacks are reported without them.
+func (t *Tracker) beforeApply(st state.StateInfo, parent proto.BlockID) []*proto.Block {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	height, err := st.Height()
+	if err != nil {
+		t.fail(errors.Wrap(err, "failed to get state height"))
+		return nil
+	}
+	top := st.TopBlock()
+	if height < t.height || (height == t.height && top.BlockID() != t.top) {
+		if len(t.subs) > 0 {
+			t.publish(RollbackUpdate(t.scheme, top.BlockID(), height, nil))
+		}
+		t.height, t.top, t.txs = height, top.BlockID(), len(top.Transactions)
+	}
+	if len(t.subs) == 0 {
+		return nil
+	}
+	parentHeight, err := st.BlockIDToHeight(parent)
+	if err != nil {
+		return nil // Parent block is unknown, the application of blocks will fail.
+	}
+	var removed []*proto.Block
+	for h := parentHeight + 1; h <= height; h++ {
+		b, bErr := st.BlockByHeight(h)
+		if bErr != nil {
+			t.fail(errors.Wrapf(bErr, "failed to get block at height %d", h))
+			return nil
+		}
+		removed = append(removed, b)
+	}
+	return removed
+}
+
+func (t *Tracker) afterApply(st state.StateInfo, parent proto.BlockID, removed []*proto.Block, micro bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	height, err := st.Height()
+	if err != nil {
+		t.fail(errors.Wrap(err, "failed to get state height"))
+		return
+	}
+	top := st.TopBlock()
+	defer func() {
+		t.height, t.top, t.txs = height, top.BlockID(), len(top.Transactions)
+	}()
+	if len(t.subs) == 0 {
+		return
+	}
+	parentHeight, err := st.BlockIDToHeight(parent)
+	if err != nil {
+		t.fail(errors.Wrapf(err, "failed to get height of parent block '%s'", parent.String()))
+		return
+	}
+	if micro && height == t.height && parentHeight+1 == height {
+		t.publish(MicroBlockAppendUpdate(st, t.scheme, height, t.txs))
+		return
+	}
+	if parentHeight < t.height {
+		t.publish(RollbackUpdate(t.scheme, parent, parentHeight, removed))
+	}
+	for h := parentHeight + 1; h <= height; h++ {
+		t.publish(BlockAppendUpdate(st, t.scheme, h))
+	}
+}
+
+// publish sends the update to all subscribers. Subscribers which are not able to receive the update are terminated.
+// If the update creation failed, all subscriptions are terminated with the error.
+func (t *Tracker) publish(u *events.BlockchainUpdated, err error) {
+	if err != nil {
+		t.fail(err)
+		return
+	}
+	for s := range t.subs {
+		select {
+		case s.updates <- u:
+		default:
+			t.terminate(s, ErrSubscriptionOverflow)
+		}
+	}
+}
+
+func (t *Tracker) fail(err error) {
+	zap.S().Errorf("Failed to create blockchain update: %v", err)
+	for s := range t.subs {
+		t.terminate(s, err)
+	}
+}
+
+func (t *Tracker) terminate(s *Subscription, err error) {
+	if _, ok := t.subs[s]; !ok {
+		return
+	}
+	delete(t.subs, s)
+	s.err = err
+	close(s.updates)
+}
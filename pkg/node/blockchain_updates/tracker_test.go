@@ -0,0 +1,332 @@
+package blockchain_updates
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/events"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+// testState keeps the chain of blocks in memory and implements only the methods used by the tracker.
+type testState struct {
+	state.State
+	blocks    []*proto.Block
+	snapshots []proto.BlockSnapshot
+}
+
+func (s *testState) Height() (proto.Height, error) {
+	return proto.Height(len(s.blocks)), nil
+}
+
+func (s *testState) TopBlock() *proto.Block {
+	return s.blocks[len(s.blocks)-1]
+}
+
+func (s *testState) BlockByHeight(height proto.Height) (*proto.Block, error) {
+	if height < 1 || height > proto.Height(len(s.blocks)) {
+		return nil, errors.Errorf("block at height %d not found", height)
+	}
+	return s.blocks[height-1], nil
+}
+
+func (s *testState) SnapshotsAtHeight(height proto.Height) (proto.BlockSnapshot, error) {
+	if height < 1 || height > proto.Height(len(s.snapshots)) {
+		return proto.BlockSnapshot{}, errors.Errorf("snapshot at height %d not found", height)
+	}
+	return s.snapshots[height-1], nil
+}
+
+func (s *testState) BlockIDToHeight(blockID proto.BlockID) (proto.Height, error) {
+	for i, b := range s.blocks {
+		if b.BlockID() == blockID {
+			return proto.Height(i + 1), nil
+		}
+	}
+	return 0, errors.Errorf("block '%s' not found", blockID.String())
+}
+
+func (s *testState) BlockVRF(*proto.BlockHeader, proto.Height) ([]byte, error) {
+	return nil, nil
+}
+
+func (s *testState) BlockRewards(proto.WavesAddress, proto.Height) (proto.Rewards, error) {
+	return nil, nil
+}
+
+func (s *testState) TotalWavesAmount(proto.Height) (uint64, error) {
+	return 10_000_000_000_000_000, nil
+}
+
+func (s *testState) IsActiveAtHeight(int16, proto.Height) (bool, error) {
+	return false, nil
+}
+
+func (s *testState) ProvidesExtendedApi() (bool, error) {
+	return false, nil
+}
+
+func (s *testState) rollbackTo(blockID proto.BlockID) error {
+	h, err := s.BlockIDToHeight(blockID)
+	if err != nil {
+		return err
+	}
+	s.blocks, s.snapshots = s.blocks[:h], s.snapshots[:h]
+	return nil
+}
+
+func (s *testState) add(blocks ...*proto.Block) {
+	for _, b := range blocks {
+		snapshot := proto.BlockSnapshot{TxSnapshots: make([][]proto.AtomicSnapshot, len(b.Transactions))}
+		for i := range b.Transactions {
+			snapshot.TxSnapshots[i] = []proto.AtomicSnapshot{
+				&proto.WavesBalanceSnapshot{Address: testAddress, Balance: uint64(i)},
+			}
+		}
+		s.blocks = append(s.blocks, b)
+		s.snapshots = append(s.snapshots, snapshot)
+	}
+}
+
+// testApplier applies blocks to testState the same way as blocks_applier does.
+type testApplier struct{}
+
+func (a *testApplier) BlockExists(st state.State, block *proto.Block) (bool, error) {
+	_, err := st.BlockIDToHeight(block.BlockID())
+	return err == nil, nil
+}
+
+func (a *testApplier) Apply(st state.State, blocks []*proto.Block) (proto.Height, error) {
+	ts := st.(*testState)
+	if err := ts.rollbackTo(blocks[0].Parent); err != nil {
+		return 0, err
+	}
+	ts.add(blocks...)
+	return ts.Height()
+}
+
+func (a *testApplier) ApplyMicro(st state.State, block *proto.Block) (proto.Height, error) {
+	return a.Apply(st, []*proto.Block{block})
+}
+
+func (a *testApplier) ApplyWithSnapshots(
+	st state.State,
+	blocks []*proto.Block,
+	_ []*proto.BlockSnapshot,
+) (proto.Height, error) {
+	return a.Apply(st, blocks)
+}
+
+func (a *testApplier) ApplyMicroWithSnapshots(
+	st state.State,
+	block *proto.Block,
+	_ *proto.BlockSnapshot,
+) (proto.Height, error) {
+	return a.Apply(st, []*proto.Block{block})
+}
+
+func (a *testApplier) RollbackTo(st state.State, blockID proto.BlockID) error {
+	return st.(*testState).rollbackTo(blockID)
+}
+
+var (
+	testKeyPair = proto.MustKeyPair([]byte("blockchain updates test seed"))
+	testAddress = proto.MustAddressFromPublicKey(proto.TestNetScheme, testKeyPair.Public)
+)
+
+func testTransfer(t *testing.T, amount uint64) proto.Transaction {
+	tx := proto.NewUnsignedTransferWithSig(testKeyPair.Public, proto.NewOptionalAssetWaves(),
+		proto.NewOptionalAssetWaves(), 1, amount, 100000, proto.NewRecipientFromAddress(testAddress), nil)
+	require.NoError(t, tx.Sign(proto.TestNetScheme, testKeyPair.Secret))
+	return tx
+}
+
+// testBlock creates the block with the given parent and transactions, seed makes the block ID unique.
+func testBlock(parent proto.BlockID, seed byte, txs ...proto.Transaction) *proto.Block {
+	sig := crypto.Signature{}
+	sig[0] = seed
+	return &proto.Block{
+		BlockHeader: proto.BlockHeader{
+			Version:          proto.NgBlockVersion,
+			Parent:           parent,
+			BlockSignature:   sig,
+			TransactionCount: len(txs),
+		},
+		Transactions: txs,
+	}
+}
+
+func newTestTracker(t *testing.T) (*Tracker, *testState) {
+	st := &testState{}
+	st.add(testBlock(proto.BlockID{}, 1))
+	tr, err := NewTracker(&testApplier{}, st, proto.TestNetScheme)
+	require.NoError(t, err)
+	return tr, st
+}
+
+func receive(t *testing.T, sub *Subscription) *events.BlockchainUpdated {
+	select {
+	case u, ok := <-sub.Updates():
+		require.True(t, ok, "subscription terminated: %v", sub.Err())
+		return u
+	default:
+		require.FailNow(t, "no update")
+		return nil
+	}
+}
+
+func assertNoUpdates(t *testing.T, sub *Subscription) {
+	select {
+	case u := <-sub.Updates():
+		assert.Failf(t, "unexpected update", "%v", u)
+	default:
+	}
+}
+
+func TestTrackerBlockAppend(t *testing.T) {
+	tr, st := newTestTracker(t)
+	sub, tip := tr.Subscribe()
+	assert.Equal(t, proto.Height(1), tip.Height)
+	assert.Equal(t, st.TopBlock().BlockID(), tip.BlockID)
+
+	tx := testTransfer(t, 1)
+	b2 := testBlock(st.TopBlock().BlockID(), 2, tx)
+	h, err := tr.Apply(st, []*proto.Block{b2})
+	require.NoError(t, err)
+	assert.Equal(t, proto.Height(2), h)
+
+	u := receive(t, sub)
+	assert.Equal(t, int32(2), u.Height)
+	assert.Equal(t, b2.BlockID().Bytes(), u.Id)
+	require.NotNil(t, u.GetAppend().GetBlock())
+	id, err := tx.GetID(proto.TestNetScheme)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{id}, u.GetAppend().TransactionIds)
+	require.Len(t, u.GetAppend().TransactionStateUpdates, 1)
+	assert.Len(t, u.GetAppend().TransactionStateUpdates[0].Balances, 1)
+	assertNoUpdates(t, sub)
+	assert.Equal(t, Tip{Height: 2, BlockID: b2.BlockID()}, tr.Tip())
+}
+
+func TestTrackerMicroBlockAppend(t *testing.T) {
+	tr, st := newTestTracker(t)
+	tx1, tx2, tx3 := testTransfer(t, 1), testTransfer(t, 2), testTransfer(t, 3)
+	parent := st.TopBlock().BlockID()
+	_, err := tr.Apply(st, []*proto.Block{testBlock(parent, 2, tx1)})
+	require.NoError(t, err)
+	sub, _ := tr.Subscribe()
+
+	micro := testBlock(parent, 3, tx1, tx2, tx3)
+	h, err := tr.ApplyMicro(st, micro)
+	require.NoError(t, err)
+	assert.Equal(t, proto.Height(2), h)
+
+	u := receive(t, sub)
+	assert.Equal(t, int32(2), u.Height)
+	assert.Equal(t, micro.BlockID().Bytes(), u.Id)
+	require.NotNil(t, u.GetAppend().GetMicroBlock())
+	// Only the transactions added by the micro block are reported.
+	id2, err := tx2.GetID(proto.TestNetScheme)
+	require.NoError(t, err)
+	id3, err := tx3.GetID(proto.TestNetScheme)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{id2, id3}, u.GetAppend().TransactionIds)
+	assert.Len(t, u.GetAppend().TransactionStateUpdates, 2)
+	assertNoUpdates(t, sub)
+}
+
+func TestTrackerRollbackOnApply(t *testing.T) {
+	tr, st := newTestTracker(t)
+	genesis := st.TopBlock().BlockID()
+	tx := testTransfer(t, 1)
+	b2 := testBlock(genesis, 2, tx)
+	b3 := testBlock(b2.BlockID(), 3)
+	_, err := tr.Apply(st, []*proto.Block{b2, b3})
+	require.NoError(t, err)
+	sub, _ := tr.Subscribe()
+
+	fork := testBlock(genesis, 4)
+	h, err := tr.Apply(st, []*proto.Block{fork})
+	require.NoError(t, err)
+	assert.Equal(t, proto.Height(2), h)
+
+	rb := receive(t, sub)
+	assert.Equal(t, int32(1), rb.Height)
+	assert.Equal(t, genesis.Bytes(), rb.Id)
+	require.NotNil(t, rb.GetRollback())
+	assert.Len(t, rb.GetRollback().RemovedBlocks, 2)
+	id, err := tx.GetID(proto.TestNetScheme)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{id}, rb.GetRollback().RemovedTransactionIds)
+
+	u := receive(t, sub)
+	assert.Equal(t, int32(2), u.Height)
+	assert.Equal(t, fork.BlockID().Bytes(), u.Id)
+	assert.NotNil(t, u.GetAppend().GetBlock())
+	assertNoUpdates(t, sub)
+}
+
+func TestTrackerRollbackTo(t *testing.T) {
+	tr, st := newTestTracker(t)
+	genesis := st.TopBlock().BlockID()
+	b2 := testBlock(genesis, 2, testTransfer(t, 1))
+	_, err := tr.Apply(st, []*proto.Block{b2})
+	require.NoError(t, err)
+	sub, _ := tr.Subscribe()
+
+	require.NoError(t, tr.RollbackTo(st, genesis))
+	rb := receive(t, sub)
+	assert.Equal(t, int32(1), rb.Height)
+	require.NotNil(t, rb.GetRollback())
+	assert.Len(t, rb.GetRollback().RemovedBlocks, 1)
+	assert.Len(t, rb.GetRollback().RemovedTransactionIds, 1)
+	assertNoUpdates(t, sub)
+	assert.Equal(t, Tip{Height: 1, BlockID: genesis}, tr.Tip())
+}
+
+func TestTrackerExternalRollback(t *testing.T) {
+	tr, st := newTestTracker(t)
+	genesis := st.TopBlock().BlockID()
+	b2 := testBlock(genesis, 2)
+	_, err := tr.Apply(st, []*proto.Block{b2})
+	require.NoError(t, err)
+	sub, _ := tr.Subscribe()
+
+	// Rollback made by passing the tracker is reported before the next applied block.
+	require.NoError(t, st.rollbackTo(genesis))
+	b3 := testBlock(genesis, 3)
+	_, err = tr.Apply(st, []*proto.Block{b3})
+	require.NoError(t, err)
+
+	rb := receive(t, sub)
+	assert.Equal(t, int32(1), rb.Height)
+	assert.Equal(t, genesis.Bytes(), rb.Id)
+	assert.NotNil(t, rb.GetRollback())
+	u := receive(t, sub)
+	assert.Equal(t, int32(2), u.Height)
+	assert.Equal(t, b3.BlockID().Bytes(), u.Id)
+	assertNoUpdates(t, sub)
+}
+
+func TestTrackerSubscriptionOverflow(t *testing.T) {
+	tr, st := newTestTracker(t)
+	sub, _ := tr.Subscribe()
+	for i := 0; i <= subscriptionBufferSize; i++ {
+		b := testBlock(st.TopBlock().BlockID(), 2)
+		b.BlockSignature[1], b.BlockSignature[2] = byte(i>>8), byte(i) // Makes the block ID unique.
+		_, err := tr.Apply(st, []*proto.Block{b})
+		require.NoError(t, err)
+	}
+	n := 0
+	for range sub.Updates() {
+		n++
+	}
+	assert.Equal(t, subscriptionBufferSize, n)
+	assert.ErrorIs(t, sub.Err(), ErrSubscriptionOverflow)
+	tr.Unsubscribe(sub) // Safe to call for terminated subscription.
+}
@@ -0,0 +1,510 @@
+package blockchain_updates
+
+import (
+	"sort"
+
+	"github.com/mr-tron/base58"
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	pb "github.com/wavesplatform/gowaves/pkg/grpc/generated/waves"
+	"github.com/wavesplatform/gowaves/pkg/grpc/generated/waves/events"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+// BlockAppendUpdate creates the BlockchainUpdated event for the block stored in the state at the given height.
+// The event is built from the block and its snapshot, so only the values after the block application are set,
+// the `*_before` fields of state updates are left empty.
+func BlockAppendUpdate(st state.StateInfo, scheme proto.Scheme, height proto.Height) (*events.BlockchainUpdated, error) {
+	block, err := st.BlockByHeight(height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get block at height %d", height)
+	}
+	snapshot, err := st.SnapshotsAtHeight(height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get block snapshot at height %d", height)
+	}
+	blockProto, err := block.ToProtobuf(scheme)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert block '%s' to protobuf", block.BlockID().String())
+	}
+	vrf, err := st.BlockVRF(&block.BlockHeader, height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to calculate VRF of block '%s'", block.BlockID().String())
+	}
+	rewardShares, err := blockRewardShares(st, scheme, &block.BlockHeader, height)
+	if err != nil {
+		return nil, err
+	}
+	totalWaves, err := st.TotalWavesAmount(height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get total Waves amount at height %d", height)
+	}
+	activated, err := activatedFeatures(st, height)
+	if err != nil {
+		return nil, err
+	}
+	appendProto, err := transactionsAppend(st, scheme, block.Transactions, snapshot.TxSnapshots)
+	if err != nil {
+		return nil, err
+	}
+	appendProto.Body = &events.BlockchainUpdated_Append_Block{
+		Block: &events.BlockchainUpdated_Append_BlockAppend{
+			Block:              blockProto,
+			UpdatedWavesAmount: int64(totalWaves),
+			ActivatedFeatures:  activated,
+			Vrf:                vrf,
+			RewardShares:       rewardShares,
+		},
+	}
+	return &events.BlockchainUpdated{
+		Id:     block.BlockID().Bytes(),
+		Height: int32(height),
+		Update: &events.BlockchainUpdated_Append_{Append: appendProto},
+	}, nil
+}
+
+// MicroBlockAppendUpdate creates the BlockchainUpdated event for the liquid block at the given height,
+// which was extended with new transactions. Transactions and snapshots with indexes less than prevTxCount
+// are treated as already reported and are not included in the event.
+func MicroBlockAppendUpdate(
+	st state.StateInfo,
+	scheme proto.Scheme,
+	height proto.Height,
+	prevTxCount int,
+) (*events.BlockchainUpdated, error) {
+	block, err := st.BlockByHeight(height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get block at height %d", height)
+	}
+	snapshot, err := st.SnapshotsAtHeight(height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get block snapshot at height %d", height)
+	}
+	if prevTxCount > len(block.Transactions) || prevTxCount > len(snapshot.TxSnapshots) {
+		return nil, errors.Errorf("invalid previous transactions count %d for block '%s'",
+			prevTxCount, block.BlockID().String(),
+		)
+	}
+	appendProto, err := transactionsAppend(st, scheme,
+		block.Transactions[prevTxCount:], snapshot.TxSnapshots[prevTxCount:],
+	)
+	if err != nil {
+		return nil, err
+	}
+	appendProto.Body = &events.BlockchainUpdated_Append_MicroBlock{
+		MicroBlock: &events.BlockchainUpdated_Append_MicroBlockAppend{
+			UpdatedTransactionsRoot: block.TransactionsRoot,
+		},
+	}
+	return &events.BlockchainUpdated{
+		Id:     block.BlockID().Bytes(),
+		Height: int32(height),
+		Update: &events.BlockchainUpdated_Append_{Append: appendProto},
+	}, nil
+}
+
+// RollbackUpdate creates the BlockchainUpdated event for the rollback to the block with given ID and height.
+// Removed blocks are optional and can be nil if they are unknown at the moment of the rollback.
+func RollbackUpdate(
+	scheme proto.Scheme,
+	blockID proto.BlockID,
+	height proto.Height,
+	removed []*proto.Block,
+) (*events.BlockchainUpdated, error) {
+	rollback := &events.BlockchainUpdated_Rollback{Type: events.BlockchainUpdated_Rollback_BLOCK}
+	for _, b := range removed {
+		bp, err := b.ToProtobuf(scheme)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert removed block '%s' to protobuf", b.BlockID().String())
+		}
+		rollback.RemovedBlocks = append(rollback.RemovedBlocks, bp)
+		for _, tx := range b.Transactions {
+			id, err := tx.GetID(scheme)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get ID of removed transaction")
+			}
+			rollback.RemovedTransactionIds = append(rollback.RemovedTransactionIds, id)
+		}
+	}
+	return &events.BlockchainUpdated{
+		Id:     blockID.Bytes(),
+		Height: int32(height),
+		Update: &events.BlockchainUpdated_Rollback_{Rollback: rollback},
+	}, nil
+}
+
+func blockRewardShares(
+	st state.StateInfo,
+	scheme proto.Scheme,
+	header *proto.BlockHeader,
+	height proto.Height,
+) ([]*pb.RewardShare, error) {
+	generator, err := proto.NewAddressFromPublicKey(scheme, header.GeneratorPublicKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create generator address from public key '%s'",
+			header.GeneratorPublicKey.String(),
+		)
+	}
+	rewards, err := st.BlockRewards(generator, height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to calculate block rewards at height %d", height)
+	}
+	rewards = rewards.Sorted()
+	res := make([]*pb.RewardShare, len(rewards))
+	for i, r := range rewards {
+		res[i] = &pb.RewardShare{Address: r.Address().Bytes(), Reward: int64(r.Amount())}
+	}
+	return res, nil
+}
+
+func activatedFeatures(st state.StateInfo, height proto.Height) ([]int32, error) {
+	var res []int32
+	for f := range settings.FeaturesInfo {
+		activated, err := st.IsActiveAtHeight(int16(f), height)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check activation of feature %d", f)
+		}
+		if !activated {
+			continue
+		}
+		activationHeight, err := st.ActivationHeight(int16(f))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get activation height of feature %d", f)
+		}
+		if activationHeight == height {
+			res = append(res, int32(f))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+func transactionsAppend(
+	st state.StateInfo,
+	scheme proto.Scheme,
+	txs []proto.Transaction,
+	snapshots [][]proto.AtomicSnapshot,
+) (*events.BlockchainUpdated_Append, error) {
+	if len(txs) != len(snapshots) {
+		return nil, errors.Errorf("transactions count %d doesn't match snapshots count %d",
+			len(txs), len(snapshots),
+		)
+	}
+	extended, err := st.ProvidesExtendedApi()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check extended API support")
+	}
+	res := &events.BlockchainUpdated_Append{
+		TransactionIds:          make([][]byte, len(txs)),
+		TransactionsMetadata:    make([]*events.TransactionMetadata, len(txs)),
+		TransactionStateUpdates: make([]*events.StateUpdate, len(txs)),
+	}
+	for i, tx := range txs {
+		id, err := tx.GetID(scheme)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get transaction ID")
+		}
+		md, err := transactionMetadata(st, scheme, tx, extended)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create metadata of transaction '%s'", base58.Encode(id))
+		}
+		su, err := stateUpdateFromSnapshots(st, scheme, snapshots[i], id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create state update of transaction '%s'", base58.Encode(id))
+		}
+		res.TransactionIds[i] = id
+		res.TransactionsMetadata[i] = md
+		res.TransactionStateUpdates[i] = su
+	}
+	return res, nil
+}
+
+func resolveRecipient(st state.StateInfo, scheme proto.Scheme, r proto.Recipient) ([]byte, error) {
+	if addr := r.Address(); addr != nil {
+		return addr.Bytes(), nil
+	}
+	if alias := r.Alias(); alias != nil {
+		addr, err := st.AddrByAlias(*alias)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve alias '%s'", alias.String())
+		}
+		return addr.Bytes(), nil
+	}
+	return nil, errors.Errorf("empty recipient for scheme '%c'", scheme)
+}
+
+func transactionMetadata(
+	st state.StateInfo,
+	scheme proto.Scheme,
+	tx proto.Transaction,
+	extended bool,
+) (*events.TransactionMetadata, error) {
+	sender, err := tx.GetSender(scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sender")
+	}
+	res := &events.TransactionMetadata{SenderAddress: sender.Bytes()}
+	switch t := tx.(type) {
+	case *proto.TransferWithSig:
+		rcp, err := resolveRecipient(st, scheme, t.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		res.Metadata = &events.TransactionMetadata_Transfer{
+			Transfer: &events.TransactionMetadata_TransferMetadata{RecipientAddress: rcp},
+		}
+	case *proto.TransferWithProofs:
+		rcp, err := resolveRecipient(st, scheme, t.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		res.Metadata = &events.TransactionMetadata_Transfer{
+			Transfer: &events.TransactionMetadata_TransferMetadata{RecipientAddress: rcp},
+		}
+	case *proto.MassTransferWithProofs:
+		rcps := make([][]byte, len(t.Transfers))
+		for i, e := range t.Transfers {
+			rcp, err := resolveRecipient(st, scheme, e.Recipient)
+			if err != nil {
+				return nil, err
+			}
+			rcps[i] = rcp
+		}
+		res.Metadata = &events.TransactionMetadata_MassTransfer{
+			MassTransfer: &events.TransactionMetadata_MassTransferMetadata{RecipientsAddresses: rcps},
+		}
+	case *proto.LeaseWithSig:
+		rcp, err := resolveRecipient(st, scheme, t.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		res.Metadata = &events.TransactionMetadata_Lease{
+			Lease: &events.TransactionMetadata_LeaseMetadata{RecipientAddress: rcp},
+		}
+	case *proto.LeaseWithProofs:
+		rcp, err := resolveRecipient(st, scheme, t.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		res.Metadata = &events.TransactionMetadata_Lease{
+			Lease: &events.TransactionMetadata_LeaseMetadata{RecipientAddress: rcp},
+		}
+	case *proto.InvokeScriptWithProofs:
+		dApp, err := resolveRecipient(st, scheme, t.ScriptRecipient)
+		if err != nil {
+			return nil, err
+		}
+		md := &events.TransactionMetadata_InvokeScriptMetadata{
+			DAppAddress:  dApp,
+			FunctionName: t.FunctionCall.Name(),
+			Payments:     make([]*pb.Amount, len(t.Payments)),
+		}
+		for i, p := range t.Payments {
+			md.Payments[i] = &pb.Amount{AssetId: p.Asset.ToID(), Amount: int64(p.Amount)}
+		}
+		// Invoke results are stored only if the state provides extended API.
+		if extended && t.ID != nil {
+			sr, srErr := st.InvokeResultByID(*t.ID)
+			switch {
+			case srErr == nil:
+				if md.Result, err = sr.ToProtobuf(); err != nil {
+					return nil, errors.Wrap(err, "failed to convert invoke result to protobuf")
+				}
+			case !state.IsNotFound(srErr):
+				return nil, errors.Wrap(srErr, "failed to get invoke result")
+			}
+		}
+		res.Metadata = &events.TransactionMetadata_InvokeScript{InvokeScript: md}
+	}
+	return res, nil
+}
+
+// stateUpdateCollector implements proto.SnapshotApplier to convert atomic snapshots of one transaction
+// into the StateUpdate event.
+type stateUpdateCollector struct {
+	st      state.StateInfo
+	scheme  proto.Scheme
+	txID    []byte
+	res     *events.StateUpdate
+	details map[crypto.Digest]*events.StateUpdate_AssetDetails
+	order   []crypto.Digest
+}
+
+func stateUpdateFromSnapshots(
+	st state.StateInfo,
+	scheme proto.Scheme,
+	snapshots []proto.AtomicSnapshot,
+	txID []byte,
+) (*events.StateUpdate, error) {
+	c := &stateUpdateCollector{
+		st:      st,
+		scheme:  scheme,
+		txID:    txID,
+		res:     &events.StateUpdate{},
+		details: make(map[crypto.Digest]*events.StateUpdate_AssetDetails),
+	}
+	for _, s := range snapshots {
+		if err := s.Apply(c); err != nil {
+			return nil, err
+		}
+	}
+	c.res.Assets = make([]*events.StateUpdate_AssetStateUpdate, len(c.order))
+	for i, id := range c.order {
+		c.res.Assets[i] = &events.StateUpdate_AssetStateUpdate{After: c.details[id]}
+	}
+	return c.res, nil
+}
+
+// assetDetails returns the asset details accumulated for the transaction. On the first access only the immutable
+// fields of the asset are filled from the state, the other fields are set from the snapshots of the transaction,
+// so the details of historical events don't contain the current values of the asset.
+func (c *stateUpdateCollector) assetDetails(id crypto.Digest) (*events.StateUpdate_AssetDetails, error) {
+	if d, ok := c.details[id]; ok {
+		return d, nil
+	}
+	info, err := c.st.EnrichedFullAssetInfo(proto.AssetIDFromDigest(id))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get info of asset '%s'", id.String())
+	}
+	d := &events.StateUpdate_AssetDetails{
+		AssetId:         id.Bytes(),
+		Issuer:          info.IssuerPublicKey.Bytes(),
+		Decimals:        int32(info.Decimals),
+		IssueHeight:     int32(info.IssueHeight),
+		SequenceInBlock: int32(info.SequenceInBlock),
+	}
+	c.details[id] = d
+	c.order = append(c.order, id)
+	return d, nil
+}
+
+func (c *stateUpdateCollector) ApplyWavesBalance(s proto.WavesBalanceSnapshot) error {
+	c.res.Balances = append(c.res.Balances, &events.StateUpdate_BalanceUpdate{
+		Address:     s.Address.Bytes(),
+		AmountAfter: &pb.Amount{Amount: int64(s.Balance)},
+	})
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyAssetBalance(s proto.AssetBalanceSnapshot) error {
+	c.res.Balances = append(c.res.Balances, &events.StateUpdate_BalanceUpdate{
+		Address:     s.Address.Bytes(),
+		AmountAfter: &pb.Amount{AssetId: s.AssetID.Bytes(), Amount: int64(s.Balance)},
+	})
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyLeaseBalance(s proto.LeaseBalanceSnapshot) error {
+	c.res.LeasingForAddress = append(c.res.LeasingForAddress, &events.StateUpdate_LeasingUpdate{
+		Address:  s.Address.Bytes(),
+		InAfter:  int64(s.LeaseIn),
+		OutAfter: int64(s.LeaseOut),
+	})
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyDataEntries(s proto.DataEntriesSnapshot) error {
+	for _, e := range s.DataEntries {
+		c.res.DataEntries = append(c.res.DataEntries, &events.StateUpdate_DataEntryUpdate{
+			Address:   s.Address.Bytes(),
+			DataEntry: e.ToProtobuf(),
+		})
+	}
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyAccountScript(s proto.AccountScriptSnapshot) error {
+	addr, err := proto.NewAddressFromPublicKey(c.scheme, s.SenderPublicKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to create address of account script snapshot")
+	}
+	c.res.Scripts = append(c.res.Scripts, &events.StateUpdate_ScriptUpdate{
+		Address: addr.Bytes(),
+		After:   s.Script,
+	})
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyNewLease(s proto.NewLeaseSnapshot) error {
+	c.res.IndividualLeases = append(c.res.IndividualLeases, &events.StateUpdate_LeaseUpdate{
+		LeaseId:             s.LeaseID.Bytes(),
+		StatusAfter:         events.StateUpdate_LeaseUpdate_ACTIVE,
+		Amount:              int64(s.Amount),
+		Sender:              s.SenderPK.Bytes(),
+		Recipient:           s.RecipientAddr.Bytes(),
+		OriginTransactionId: c.txID,
+	})
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyCancelledLease(s proto.CancelledLeaseSnapshot) error {
+	c.res.IndividualLeases = append(c.res.IndividualLeases, &events.StateUpdate_LeaseUpdate{
+		LeaseId:     s.LeaseID.Bytes(),
+		StatusAfter: events.StateUpdate_LeaseUpdate_INACTIVE,
+	})
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyNewAsset(s proto.NewAssetSnapshot) error {
+	d, err := c.assetDetails(s.AssetID)
+	if err != nil {
+		return err
+	}
+	d.Issuer = s.IssuerPublicKey.Bytes()
+	d.Decimals = int32(s.Decimals)
+	d.Nft = s.IsNFT
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyAssetVolume(s proto.AssetVolumeSnapshot) error {
+	d, err := c.assetDetails(s.AssetID)
+	if err != nil {
+		return err
+	}
+	d.Volume = s.TotalQuantity.Int64()
+	d.SafeVolume = s.TotalQuantity.Bytes()
+	d.Reissuable = s.IsReissuable
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyAssetDescription(s proto.AssetDescriptionSnapshot) error {
+	d, err := c.assetDetails(s.AssetID)
+	if err != nil {
+		return err
+	}
+	d.Name = s.AssetName
+	d.Description = s.AssetDescription
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyAssetScript(s proto.AssetScriptSnapshot) error {
+	d, err := c.assetDetails(s.AssetID)
+	if err != nil {
+		return err
+	}
+	d.ScriptInfo = &events.StateUpdate_AssetDetails_AssetScriptInfo{Script: s.Script}
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplySponsorship(s proto.SponsorshipSnapshot) error {
+	d, err := c.assetDetails(s.AssetID)
+	if err != nil {
+		return err
+	}
+	d.Sponsorship = int64(s.MinSponsoredFee)
+	return nil
+}
+
+// ApplyAlias does nothing, because StateUpdate has no field for the new aliases.
+func (c *stateUpdateCollector) ApplyAlias(proto.AliasSnapshot) error { return nil }
+
+func (c *stateUpdateCollector) ApplyFilledVolumeAndFee(proto.FilledVolumeFeeSnapshot) error {
+	return nil
+}
+
+func (c *stateUpdateCollector) ApplyTransactionsStatus(proto.TransactionStatusSnapshot) error {
+	return nil
+}
@@ -0,0 +1,66 @@
+package blockchain_updates
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+// assetState returns the current info of the single known asset.
+type assetState struct {
+	state.StateInfo
+	id   crypto.Digest
+	info proto.EnrichedFullAssetInfo
+}
+
+func (s *assetState) EnrichedFullAssetInfo(id proto.AssetID) (*proto.EnrichedFullAssetInfo, error) {
+	if id != proto.AssetIDFromDigest(s.id) {
+		return nil, errors.New("asset not found")
+	}
+	return &s.info, nil
+}
+
+func TestStateUpdateAssetDetails(t *testing.T) {
+	id := crypto.MustDigestFromBase58("HzfaJp8YQWLvQG4FkUxq2Q7iYWMYQ2k8UsnVvGSXWpoT")
+	pk := crypto.MustPublicKeyFromBase58("7Fqkc1FnsLJQdp7fQ9BxbnXDEDpNWF2oMs6JBPFQK1mD")
+	st := &assetState{id: id}
+	st.info.IssuerPublicKey = pk
+	st.info.Decimals = 2
+	st.info.IssueHeight = 100
+	st.info.SequenceInBlock = 3
+	// Current values of the asset differ from the historical ones.
+	st.info.Name = "current"
+	st.info.Description = "current description"
+	st.info.Quantity = 5000
+	st.info.Reissuable = false
+	st.info.SponsorshipCost = 10
+
+	su, err := stateUpdateFromSnapshots(st, proto.TestNetScheme, []proto.AtomicSnapshot{
+		&proto.AssetVolumeSnapshot{AssetID: id, TotalQuantity: *big.NewInt(1000), IsReissuable: true},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, su.Assets, 1)
+	d := su.Assets[0].After
+	assert.Equal(t, id.Bytes(), d.AssetId)
+	assert.Equal(t, pk.Bytes(), d.Issuer)
+	assert.Equal(t, int32(2), d.Decimals)
+	assert.Equal(t, int32(100), d.IssueHeight)
+	assert.Equal(t, int32(3), d.SequenceInBlock)
+	assert.Equal(t, int64(1000), d.Volume)
+	assert.True(t, d.Reissuable)
+	assert.Empty(t, d.Name)
+	assert.Empty(t, d.Description)
+	assert.Zero(t, d.Sponsorship)
+
+	_, err = stateUpdateFromSnapshots(st, proto.TestNetScheme, []proto.AtomicSnapshot{
+		&proto.SponsorshipSnapshot{AssetID: crypto.Digest{1}, MinSponsoredFee: 1},
+	}, nil)
+	assert.ErrorContains(t, err, "asset not found")
+}
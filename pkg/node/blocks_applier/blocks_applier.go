@@ -3,6 +3,7 @@ package blocks_applier
 import (
 	stderrors "errors"
 	"math/big"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/wavesplatform/gowaves/pkg/proto"
@@ -356,14 +357,20 @@ func (a *BlocksApplier) Apply(
 	state state.State,
 	blocks []*proto.Block,
 ) (proto.Height, error) {
-	return a.inner.apply(state, blocks)
+	start := time.Now()
+	height, err := a.inner.apply(state, blocks)
+	observeApply(kindBlock, start, err)
+	return height, err
 }
 
 func (a *BlocksApplier) ApplyMicro(
 	state state.State,
 	block *proto.Block,
 ) (proto.Height, error) {
-	return a.inner.applyMicro(state, block)
+	start := time.Now()
+	height, err := a.inner.applyMicro(state, block)
+	observeApply(kindMicroBlock, start, err)
+	return height, err
 }
 
 func (a *BlocksApplier) ApplyWithSnapshots(
@@ -371,7 +378,10 @@ func (a *BlocksApplier) ApplyWithSnapshots(
 	blocks []*proto.Block,
 	snapshots []*proto.BlockSnapshot,
 ) (proto.Height, error) {
-	return a.inner.applyWithSnapshots(state, blocks, snapshots)
+	start := time.Now()
+	height, err := a.inner.applyWithSnapshots(state, blocks, snapshots)
+	observeApply(kindBlock, start, err)
+	return height, err
 }
 
 func (a *BlocksApplier) ApplyMicroWithSnapshots(
@@ -379,7 +389,14 @@ func (a *BlocksApplier) ApplyMicroWithSnapshots(
 	block *proto.Block,
 	snapshot *proto.BlockSnapshot,
 ) (proto.Height, error) {
-	return a.inner.applyMicroWithSnapshot(state, block, snapshot)
+	start := time.Now()
+	height, err := a.inner.applyMicroWithSnapshot(state, block, snapshot)
+	observeApply(kindMicroBlock, start, err)
+	return height, err
+}
+
+func (a *BlocksApplier) RollbackTo(state state.State, blockID proto.BlockID) error {
+	return state.RollbackTo(blockID)
 }
 
 func calcMultipleScore(blocks []*proto.Block) (*big.Int, error) {
@@ -0,0 +1,34 @@
+package blocks_applier
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	kindBlock      = "block"
+	kindMicroBlock = "microblock"
+)
+
+var metricApplyDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "node",
+		Name:      "apply_duration_seconds",
+		Help:      "Duration of blocks and microblocks application including rollbacks.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+	},
+	[]string{"kind", "result"},
+)
+
+func init() {
+	prometheus.MustRegister(metricApplyDuration)
+}
+
+func observeApply(kind string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	metricApplyDuration.WithLabelValues(kind, result).Observe(time.Since(start).Seconds())
+}
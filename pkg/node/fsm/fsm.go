@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/qmuntal/stateless"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/libs/microblock_cache"
 	"github.com/wavesplatform/gowaves/pkg/miner"
 	"github.com/wavesplatform/gowaves/pkg/miner/utxpool"
@@ -45,6 +46,7 @@ type BlocksApplier interface {
 		block *proto.Block,
 		snapshots *proto.BlockSnapshot,
 	) (proto.Height, error)
+	RollbackTo(state storage.State, blockID proto.BlockID) error
 }
 
 type BaseInfo struct {
@@ -66,6 +68,7 @@ type BaseInfo struct {
 	scheduler types.Scheduler
 
 	microMiner         *miner.MicroMiner
+	signer             types.Signer
 	MicroBlockCache    services.MicroBlockCache
 	MicroBlockInvCache services.MicroBlockInvCache
 	microblockInterval time.Duration
@@ -93,6 +96,15 @@ func (a *BaseInfo) BroadcastTransaction(t proto.Transaction, receivedFrom peer.P
 
 func (a *BaseInfo) CleanUtx() {
 	utxpool.NewCleaner(a.storage, a.utx, a.tm).Clean()
+	a.publishUtxRemovals()
+}
+
+// publishUtxRemovals notifies about transactions that left UTX pool, if the pool supports notifications.
+// It must be called after the popped transactions that are still valid are returned to the pool.
+func (a *BaseInfo) publishUtxRemovals() {
+	if p, ok := a.utx.(interface{ PublishRemoved() }); ok {
+		p.PublishRemoved()
+	}
 }
 
 // States.
@@ -141,8 +153,9 @@ type State interface {
 }
 
 type StateData struct {
-	Name  stateless.State
-	State State
+	Name    stateless.State
+	State   State
+	entered time.Time
 }
 
 func NewFSM(
@@ -168,6 +181,7 @@ func NewFSM(
 		scheduler: services.Scheduler,
 
 		microMiner: miner.NewMicroMiner(services),
+		signer:     services.Wallet,
 
 		MicroBlockCache:    services.MicroBlockCache,
 		MicroBlockInvCache: microblock_cache.NewMicroblockInvCache(),
@@ -187,9 +201,11 @@ func NewFSM(
 	info.scheduler.Reschedule()
 
 	state := &StateData{
-		Name:  IdleStateName,
-		State: newIdleState(info),
+		Name:    IdleStateName,
+		State:   newIdleState(info),
+		entered: time.Now(),
 	}
+	metricCurrentState.WithLabelValues(IdleStateName).Set(1)
 
 	// default tasks
 	t := Async{
@@ -200,6 +216,10 @@ func NewFSM(
 	fsm := stateless.NewStateMachineWithExternalStorage(func(_ context.Context) (stateless.State, error) {
 		return state.Name, nil
 	}, func(_ context.Context, s stateless.State) error {
+		if s != state.Name {
+			observeTransition(state.Name, s, state.entered)
+			state.entered = time.Now()
+		}
 		state.Name = s
 		return nil
 	}, stateless.FiringQueued)
@@ -248,11 +268,11 @@ func (f *FSM) Task(task tasks.AsyncTask) (Async, error) {
 func (f *FSM) MinedBlock(
 	block *proto.Block,
 	limits proto.MiningLimits,
-	keyPair proto.KeyPair,
+	pk crypto.PublicKey,
 	vrf []byte,
 ) (Async, error) {
 	asyncRes := &Async{}
-	err := f.fsm.Fire(MinedBlockEvent, asyncRes, block, limits, keyPair, vrf)
+	err := f.fsm.Fire(MinedBlockEvent, asyncRes, block, limits, pk, vrf)
 	return *asyncRes, err
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"time"
 
 	"github.com/mr-tron/base58"
@@ -11,6 +12,7 @@ import (
 	"github.com/qmuntal/stateless"
 	"go.uber.org/zap"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/libs/signatures"
 	"github.com/wavesplatform/gowaves/pkg/logging"
 	"github.com/wavesplatform/gowaves/pkg/node/fsm/sync_internal"
@@ -24,6 +26,10 @@ import (
 const (
 	askPeersInterval   = 5 * time.Minute
 	defaultSyncTimeout = 30 * time.Second
+	// blockRequestTimeout is the time a peer has to send the requested block before it's requested from other peer.
+	blockRequestTimeout = 10 * time.Second
+	// maxDownloadPeers is the maximum number of peers blocks are downloaded from simultaneously during sync.
+	maxDownloadPeers = 4
 )
 
 // Set args types for events.
@@ -47,7 +53,7 @@ func eventArgsTypes(event stateless.Trigger) []reflect.Type {
 	case MinedBlockEvent:
 		return []reflect.Type{
 			reflect.TypeOf(&Async{}), reflect.TypeOf(&proto.Block{}), reflect.TypeOf(proto.MiningLimits{}),
-			reflect.TypeOf(proto.KeyPair{}), reflect.TypeOf([]byte{}),
+			reflect.TypeOf(crypto.PublicKey{}), reflect.TypeOf([]byte{}),
 		}
 	case BlockIDsEvent:
 		return []reflect.Type{
@@ -90,6 +96,7 @@ func syncWithNewPeer(state State, baseInfo BaseInfo, p peer.Peer) (State, Async,
 	internal := sync_internal.InternalFromLastSignatures(
 		extension.NewPeerExtension(p, baseInfo.scheme),
 		lastSignatures,
+		sync_internal.NewDispatcher(downloadPeers(baseInfo, p), blockRequestTimeout, baseInfo.enableLightMode),
 		baseInfo.enableLightMode,
 	)
 	c := conf{
@@ -106,6 +113,45 @@ func syncWithNewPeer(state State, baseInfo BaseInfo, p peer.Peer) (State, Async,
 	}, nil, nil
 }
 
+// downloadPeer is a peer blocks are downloaded from during synchronization.
+type downloadPeer struct {
+	extension.PeerExtension
+	peer peer.Peer
+}
+
+func newDownloadPeer(p peer.Peer, scheme proto.Scheme) downloadPeer {
+	return downloadPeer{PeerExtension: extension.NewPeerExtension(p, scheme), peer: p}
+}
+
+func (p downloadPeer) ID() string {
+	return p.peer.ID().String()
+}
+
+// downloadPeers selects the peers to download blocks from. The sync peer goes first, it's followed by
+// the peers with the highest scores, but not lower than the score of sync peer. The dispatcher uses them
+// only after they respond with the same block IDs as the sync peer.
+func downloadPeers(baseInfo BaseInfo, syncPeer peer.Peer) []sync_internal.DownloadPeer {
+	type scoredPeer struct {
+		peer  peer.Peer
+		score *proto.Score
+	}
+	var candidates []scoredPeer
+	if syncScore, err := baseInfo.peers.Score(syncPeer); err == nil {
+		baseInfo.peers.EachConnected(func(p peer.Peer, score *proto.Score) {
+			if score != nil && score.Cmp(syncScore) >= 0 && !p.Equal(syncPeer) {
+				candidates = append(candidates, scoredPeer{peer: p, score: score})
+			}
+		})
+	}
+	slices.SortFunc(candidates, func(a, b scoredPeer) int { return b.score.Cmp(a.score) })
+	r := make([]sync_internal.DownloadPeer, 0, maxDownloadPeers)
+	r = append(r, newDownloadPeer(syncPeer, baseInfo.scheme))
+	for _, c := range candidates[:min(len(candidates), maxDownloadPeers-1)] {
+		r = append(r, newDownloadPeer(c.peer, baseInfo.scheme))
+	}
+	return r
+}
+
 func tryBroadcastTransaction(
 	fsm State, baseInfo BaseInfo, p peer.Peer, t proto.Transaction,
 ) (_ State, _ Async, err error) {
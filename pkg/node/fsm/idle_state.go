@@ -7,6 +7,7 @@ import (
 	"github.com/qmuntal/stateless"
 	"go.uber.org/zap"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/logging"
 	"github.com/wavesplatform/gowaves/pkg/metrics"
 	"github.com/wavesplatform/gowaves/pkg/node/fsm/tasks"
@@ -49,13 +50,13 @@ func (a *IdleState) StartMining() (State, Async, error) {
 }
 
 func (a *IdleState) MinedBlock(
-	block *proto.Block, limits proto.MiningLimits, keyPair proto.KeyPair, vrf []byte,
+	block *proto.Block, limits proto.MiningLimits, pk crypto.PublicKey, vrf []byte,
 ) (State, Async, error) {
 	newA, ok := newNGState(a.baseInfo).(*NGState)
 	if !ok {
 		return a, nil, a.Errorf(errors.Errorf("unexpected type '%T' expected '*NGState'", a.baseInfo))
 	}
-	return newA.MinedBlock(block, limits, keyPair, vrf)
+	return newA.MinedBlock(block, limits, pk, vrf)
 }
 
 func (a *IdleState) Task(task tasks.AsyncTask) (State, Async, error) {
@@ -172,7 +173,7 @@ func initIdleStateInFSM(state *StateData, fsm *stateless.StateMachine, b BaseInf
 					return a, nil, a.Errorf(errors.Errorf("unexpected type '%T' expected '*IdleState'",
 						state.State))
 				}
-				return a.MinedBlock(args[0].(*proto.Block), args[1].(proto.MiningLimits), args[2].(proto.KeyPair),
+				return a.MinedBlock(args[0].(*proto.Block), args[1].(proto.MiningLimits), args[2].(crypto.PublicKey),
 					args[3].([]byte))
 			})).
 		PermitDynamic(HaltEvent,
@@ -0,0 +1,57 @@
+package fsm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/qmuntal/stateless"
+)
+
+const fsmMetricsNamespace = "fsm"
+
+var (
+	metricStateTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: fsmMetricsNamespace,
+			Name:      "transitions_total",
+			Help:      "Number of FSM transitions between states.",
+		},
+		[]string{"from", "to"},
+	)
+
+	metricStateDuration = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: fsmMetricsNamespace,
+			Name:      "state_seconds_total",
+			Help:      "Time spent by FSM in the state, updated on leaving the state.",
+		},
+		[]string{"state"},
+	)
+
+	metricCurrentState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: fsmMetricsNamespace,
+			Name:      "current_state",
+			Help:      "Current state of FSM, the gauge of current state is set to 1.",
+		},
+		[]string{"state"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricStateTransitions,
+		metricStateDuration,
+		metricCurrentState,
+	)
+}
+
+// observeTransition records the transition of FSM from the state that was entered at the given time.
+func observeTransition(from, to stateless.State, entered time.Time) {
+	f, t := fmt.Sprint(from), fmt.Sprint(to)
+	metricStateTransitions.WithLabelValues(f, t).Inc()
+	metricStateDuration.WithLabelValues(f).Add(time.Since(entered).Seconds())
+	metricCurrentState.WithLabelValues(f).Set(0)
+	metricCurrentState.WithLabelValues(t).Set(1)
+}
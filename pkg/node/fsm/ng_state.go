@@ -7,6 +7,7 @@ import (
 	"github.com/qmuntal/stateless"
 	"go.uber.org/zap"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/logging"
 	"github.com/wavesplatform/gowaves/pkg/metrics"
 	"github.com/wavesplatform/gowaves/pkg/miner"
@@ -68,7 +69,7 @@ func (a *NGState) Task(task tasks.AsyncTask) (State, Async, error) {
 			return a, nil, a.Errorf(errors.Errorf(
 				"unexpected type %T, expected 'tasks.MineMicroTaskData'", task.Data))
 		}
-		return a.mineMicro(t.Block, t.Limits, t.KeyPair, t.Vrf)
+		return a.mineMicro(t.Block, t.Limits, t.PublicKey, t.Vrf)
 	case tasks.SnapshotTimeout:
 		return a, nil, nil
 	default:
@@ -96,7 +97,7 @@ func (a *NGState) Score(p peer.Peer, score *proto.Score) (State, Async, error) {
 
 func (a *NGState) rollbackToStateFromCache(blockFromCache *proto.Block) error {
 	previousBlockID := blockFromCache.Parent
-	err := a.baseInfo.storage.RollbackTo(previousBlockID)
+	err := a.baseInfo.blocksApplier.RollbackTo(a.baseInfo.storage, previousBlockID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to rollback to parent block '%s' of cached block '%s'",
 			previousBlockID.String(), blockFromCache.ID.String())
@@ -127,7 +128,7 @@ func (a *NGState) rollbackToStateFromCacheInLightNode(parentID proto.BlockID) er
 	zap.S().Named(logging.FSMNamespace).Debugf("[%s] Re-applying block '%s' from cache",
 		a, blockFromCache.ID.String())
 	previousBlockID := blockFromCache.Parent
-	err := a.baseInfo.storage.RollbackTo(previousBlockID)
+	err := a.baseInfo.blocksApplier.RollbackTo(a.baseInfo.storage, previousBlockID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to rollback to parent block '%s' of cached block '%s'",
 			previousBlockID.String(), blockFromCache.ID.String())
@@ -201,7 +202,7 @@ func (a *NGState) Block(peer peer.Peer, block *proto.Block) (State, Async, error
 }
 
 func (a *NGState) MinedBlock(
-	block *proto.Block, limits proto.MiningLimits, keyPair proto.KeyPair, vrf []byte,
+	block *proto.Block, limits proto.MiningLimits, pk crypto.PublicKey, vrf []byte,
 ) (State, Async, error) {
 	metrics.FSMKeyBlockGenerated("ng", block)
 	err := a.baseInfo.storage.Map(func(state state.NonThreadSafeState) error {
@@ -227,7 +228,7 @@ func (a *NGState) MinedBlock(
 	a.baseInfo.actions.SendScore(a.baseInfo.storage)
 	a.baseInfo.CleanUtx()
 
-	return a, tasks.Tasks(tasks.NewMineMicroTask(0, block, limits, keyPair, vrf)), nil
+	return a, tasks.Tasks(tasks.NewMineMicroTask(0, block, limits, pk, vrf)), nil
 }
 
 func (a *NGState) MicroBlock(p peer.Peer, micro *proto.MicroBlock) (State, Async, error) {
@@ -264,13 +265,14 @@ func (a *NGState) MicroBlock(p peer.Peer, micro *proto.MicroBlock) (State, Async
 
 // mineMicro handles a new microblock generated by miner.
 func (a *NGState) mineMicro(
-	minedBlock *proto.Block, rest proto.MiningLimits, keyPair proto.KeyPair, vrf []byte,
+	minedBlock *proto.Block, rest proto.MiningLimits, pk crypto.PublicKey, vrf []byte,
 ) (State, Async, error) {
-	block, micro, rest, err := a.baseInfo.microMiner.Micro(minedBlock, rest, keyPair)
+	block, micro, rest, err := a.baseInfo.microMiner.Micro(minedBlock, rest, pk)
+	defer a.baseInfo.publishUtxRemovals()
 	switch {
 	case errors.Is(err, miner.NoTransactionsErr):
 		zap.S().Named(logging.FSMNamespace).Debugf("[%s] No transactions to put in microblock: %v", a, err)
-		return a, tasks.Tasks(tasks.NewMineMicroTask(a.baseInfo.microblockInterval, minedBlock, rest, keyPair, vrf)), nil
+		return a, tasks.Tasks(tasks.NewMineMicroTask(a.baseInfo.microblockInterval, minedBlock, rest, pk, vrf)), nil
 	case errors.Is(err, miner.StateChangedErr):
 		return a, nil, a.Errorf(proto.NewInfoMsg(err))
 	case err != nil:
@@ -295,7 +297,7 @@ func (a *NGState) mineMicro(
 		micro.SenderPK,
 		block.BlockID(),
 		micro.Reference)
-	err = inv.Sign(keyPair.Secret, a.baseInfo.scheme)
+	err = a.baseInfo.signer.SignMicroBlockInv(pk, inv)
 	if err != nil {
 		return a, nil, a.Errorf(err)
 	}
@@ -324,7 +326,7 @@ func (a *NGState) mineMicro(
 	a.baseInfo.MicroBlockCache.AddMicroBlock(block.BlockID(), micro)
 	a.baseInfo.MicroBlockInvCache.Add(block.BlockID(), inv)
 
-	return a, tasks.Tasks(tasks.NewMineMicroTask(a.baseInfo.microblockInterval, block, rest, keyPair, vrf)), nil
+	return a, tasks.Tasks(tasks.NewMineMicroTask(a.baseInfo.microblockInterval, block, rest, pk, vrf)), nil
 }
 
 // checkAndAppendMicroBlock checks that microblock is appendable and appends it.
@@ -510,7 +512,7 @@ func initNGStateInFSM(state *StateData, fsm *stateless.StateMachine, info BaseIn
 						"unexpected type '%T' expected '*NGState'", state.State))
 				}
 				return a.MinedBlock(args[0].(*proto.Block), args[1].(proto.MiningLimits),
-					args[2].(proto.KeyPair), args[3].([]byte))
+					args[2].(crypto.PublicKey), args[3].([]byte))
 			})).
 		PermitDynamic(MicroBlockEvent,
 			createPermitDynamicCallback(MicroBlockEvent, state, func(args ...interface{}) (State, Async, error) {
@@ -0,0 +1,253 @@
+package sync_internal
+
+import (
+	"slices"
+	"time"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// maxStalls is the number of stalls after which the peer is excluded from the download.
+const maxStalls = 3
+
+// DownloadPeer is a peer blocks are downloaded from.
+type DownloadPeer interface {
+	ID() string
+	AskBlocksIDs(ids []proto.BlockID)
+	AskBlock(id proto.BlockID)
+	AskBlockSnapshot(id proto.BlockID)
+}
+
+type blockRequest struct {
+	peer    string
+	askedAt time.Time
+}
+
+// candidate is a peer that is going to be used for download once it confirms that it has the same blocks
+// as the sync peer. The block IDs are the last response of the peer, nil if the peer hasn't responded yet.
+type candidate struct {
+	peer DownloadPeer
+	ids  []proto.BlockID
+}
+
+// Dispatcher spreads the requests of blocks and their snapshots among several peers in round-robin manner.
+// Blocks that are not received in time are requested again from other peers.
+// The first peer is the sync peer, it's always used for download. The other peers are asked for the block IDs
+// along with the sync peer and are used for download only if their response starts with the block IDs received
+// from the sync peer, so they have the same blocks after the common ancestor.
+type Dispatcher struct {
+	peers       []DownloadPeer
+	candidates  []*candidate
+	expected    []proto.BlockID
+	next        int
+	requests    map[proto.BlockID]blockRequest
+	stalls      map[string]int
+	timeout     time.Duration
+	isLightNode bool
+}
+
+func NewDispatcher(peers []DownloadPeer, timeout time.Duration, isLightNode bool) *Dispatcher {
+	d := &Dispatcher{
+		requests:    make(map[proto.BlockID]blockRequest),
+		stalls:      make(map[string]int),
+		timeout:     timeout,
+		isLightNode: isLightNode,
+	}
+	d.SetPeers(peers)
+	return d
+}
+
+// SetPeers replaces the peers to download blocks from, the first one is the sync peer.
+// Peers that were excluded because of stalls are skipped. Other peers stay in use if they were used before,
+// the new ones are used after they confirm the blocks of sync peer.
+// Blocks that are already requested stay assigned to their peers.
+func (d *Dispatcher) SetPeers(peers []DownloadPeer) {
+	active, candidates := d.peers, d.candidates
+	d.peers, d.candidates = nil, nil
+	seen := make(map[string]struct{}, len(peers))
+	for i, p := range peers {
+		id := p.ID()
+		if _, ok := seen[id]; ok || d.stalls[id] >= maxStalls {
+			continue
+		}
+		seen[id] = struct{}{}
+		if i == 0 {
+			d.peers = append(d.peers, p)
+			continue
+		}
+		inUse := slices.ContainsFunc(active, sameID(id))
+		if inUse {
+			d.peers = append(d.peers, p)
+		}
+		// Peers in use that are asked for the block IDs again stay candidates, so they are checked on response.
+		if j := slices.IndexFunc(candidates, candidateID(id)); j >= 0 {
+			d.candidates = append(d.candidates, &candidate{peer: p, ids: candidates[j].ids})
+		} else if !inUse {
+			d.candidates = append(d.candidates, &candidate{peer: p})
+		}
+	}
+	d.next = 0
+}
+
+// AskBlocksIDs asks the peers other than the sync peer for the block IDs with the same locator the sync peer
+// is asked with. The peers in use stay in use until they respond with other blocks.
+func (d *Dispatcher) AskBlocksIDs(ids []proto.BlockID) {
+	d.expected = nil
+	for _, c := range d.candidates {
+		c.ids = nil
+		c.peer.AskBlocksIDs(ids)
+	}
+	if len(d.peers) > 1 {
+		for _, p := range d.peers[1:] {
+			d.candidates = append(d.candidates, &candidate{peer: p})
+			p.AskBlocksIDs(ids)
+		}
+	}
+}
+
+// Expect sets the block IDs received from the sync peer, the peers that have already responded with the same
+// blocks are taken into use and the peers that have responded with other blocks are put out of use.
+func (d *Dispatcher) Expect(ids []proto.BlockID) {
+	d.expected = ids
+	for _, c := range slices.Clone(d.candidates) {
+		if c.ids != nil {
+			d.confirm(c)
+		}
+	}
+}
+
+// Confirm handles the block IDs received from the peer other than the sync peer.
+func (d *Dispatcher) Confirm(id string, ids []proto.BlockID) {
+	i := slices.IndexFunc(d.candidates, candidateID(id))
+	if i < 0 {
+		return
+	}
+	c := d.candidates[i]
+	c.ids = ids
+	if d.expected != nil {
+		d.confirm(c)
+	}
+}
+
+func (d *Dispatcher) confirm(c *candidate) {
+	d.candidates = slices.DeleteFunc(d.candidates, func(other *candidate) bool { return other == c })
+	i := slices.IndexFunc(d.peers, sameID(c.peer.ID()))
+	if d.stalls[c.peer.ID()] >= maxStalls {
+		return
+	}
+	if len(c.ids) >= len(d.expected) && slices.Equal(c.ids[:len(d.expected)], d.expected) {
+		if i < 0 {
+			d.peers = append(d.peers, c.peer)
+		}
+		return
+	}
+	if i > 0 { // The sync peer is never put out of use.
+		d.peers = slices.Delete(d.peers, i, i+1)
+	}
+	c.ids = nil
+	d.candidates = append(d.candidates, c)
+}
+
+// Has reports whether blocks are downloaded from the peer with given ID.
+func (d *Dispatcher) Has(id string) bool {
+	return slices.ContainsFunc(d.peers, sameID(id))
+}
+
+// IsCandidate reports whether the peer with given ID is asked for the block IDs to be used for download.
+func (d *Dispatcher) IsCandidate(id string) bool {
+	return slices.ContainsFunc(d.candidates, candidateID(id))
+}
+
+// PeersCount returns the number of peers blocks are downloaded from.
+func (d *Dispatcher) PeersCount() int {
+	return len(d.peers)
+}
+
+// PendingCount returns the number of requested, but not yet received blocks.
+func (d *Dispatcher) PendingCount() int {
+	return len(d.requests)
+}
+
+// Request asks the block from the next peer.
+func (d *Dispatcher) Request(id proto.BlockID, now time.Time) {
+	if len(d.peers) == 0 {
+		return
+	}
+	p := d.peers[d.next%len(d.peers)]
+	d.next++
+	d.ask(p, id, now)
+}
+
+// Done forgets the request of the block, it's called when the block and its snapshot are received.
+// The stalls of the peer that has delivered the block are forgiven.
+func (d *Dispatcher) Done(id proto.BlockID) {
+	if r, ok := d.requests[id]; ok {
+		delete(d.stalls, r.peer)
+		delete(d.requests, id)
+	}
+}
+
+// Stalled requests again from other peers the blocks that were not received in time. Every peer that failed
+// to respond in time gets a stall, the peers that have stalled too many times are excluded and returned.
+func (d *Dispatcher) Stalled(now time.Time) []DownloadPeer {
+	var ids []proto.BlockID
+	stalledPeers := make(map[string]struct{})
+	for id, r := range d.requests {
+		if now.Sub(r.askedAt) < d.timeout {
+			continue
+		}
+		ids = append(ids, id)
+		stalledPeers[r.peer] = struct{}{}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var excluded []DownloadPeer
+	for id := range stalledPeers {
+		d.stalls[id]++
+		if d.stalls[id] < maxStalls {
+			continue
+		}
+		if i := slices.IndexFunc(d.peers, sameID(id)); i >= 0 {
+			excluded = append(excluded, d.peers[i])
+			d.peers = slices.Delete(d.peers, i, i+1)
+		}
+		d.candidates = slices.DeleteFunc(d.candidates, candidateID(id))
+	}
+	if len(d.peers) == 0 {
+		return excluded
+	}
+	slices.SortFunc(ids, func(a, b proto.BlockID) int { return slices.Compare(a.Bytes(), b.Bytes()) })
+	for _, id := range ids {
+		d.ask(d.another(d.requests[id].peer), id, now)
+	}
+	return excluded
+}
+
+// another returns the next peer that differs from the given one, if possible.
+func (d *Dispatcher) another(id string) DownloadPeer {
+	for range d.peers {
+		p := d.peers[d.next%len(d.peers)]
+		d.next++
+		if p.ID() != id {
+			return p
+		}
+	}
+	return d.peers[0]
+}
+
+func (d *Dispatcher) ask(p DownloadPeer, id proto.BlockID, now time.Time) {
+	p.AskBlock(id)
+	if d.isLightNode {
+		p.AskBlockSnapshot(id)
+	}
+	d.requests[id] = blockRequest{peer: p.ID(), askedAt: now}
+}
+
+func sameID(id string) func(p DownloadPeer) bool {
+	return func(p DownloadPeer) bool { return p.ID() == id }
+}
+
+func candidateID(id string) func(c *candidate) bool {
+	return func(c *candidate) bool { return c.peer.ID() == id }
+}
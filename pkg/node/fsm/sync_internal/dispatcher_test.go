@@ -0,0 +1,176 @@
+package sync_internal_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	. "github.com/wavesplatform/gowaves/pkg/node/fsm/sync_internal"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+type recordingPeer struct {
+	id        string
+	locators  [][]proto.BlockID
+	blocks    []proto.BlockID
+	snapshots []proto.BlockID
+}
+
+func (p *recordingPeer) ID() string {
+	return p.id
+}
+
+func (p *recordingPeer) AskBlocksIDs(ids []proto.BlockID) {
+	p.locators = append(p.locators, ids)
+}
+
+func (p *recordingPeer) AskBlock(id proto.BlockID) {
+	p.blocks = append(p.blocks, id)
+}
+
+func (p *recordingPeer) AskBlockSnapshot(id proto.BlockID) {
+	p.snapshots = append(p.snapshots, id)
+}
+
+// maxTestStalls is the number of stalls after which the peer is excluded.
+const maxTestStalls = 3
+
+func blockID(i byte) proto.BlockID {
+	return proto.NewBlockIDFromDigest(crypto.Digest{i})
+}
+
+// newConfirmedDispatcher returns the dispatcher with all peers confirmed to have the same blocks.
+func newConfirmedDispatcher(peers []DownloadPeer, isLightNode bool) *Dispatcher {
+	d := NewDispatcher(peers, time.Second, isLightNode)
+	locator := []proto.BlockID{blockID(100)}
+	d.AskBlocksIDs(locator)
+	for _, p := range peers[1:] {
+		d.Confirm(p.ID(), locator)
+	}
+	d.Expect(locator)
+	return d
+}
+
+func TestDispatcher_SpreadsRequests(t *testing.T) {
+	p1, p2 := &recordingPeer{id: "p1"}, &recordingPeer{id: "p2"}
+	d := newConfirmedDispatcher([]DownloadPeer{p1, p2, p1}, true)
+	require.Equal(t, 2, d.PeersCount())
+	now := time.Now()
+	for i := byte(1); i <= 4; i++ {
+		d.Request(blockID(i), now)
+	}
+	require.Equal(t, []proto.BlockID{blockID(1), blockID(3)}, p1.blocks)
+	require.Equal(t, []proto.BlockID{blockID(2), blockID(4)}, p2.blocks)
+	require.Equal(t, p1.blocks, p1.snapshots)
+	require.Equal(t, 4, d.PendingCount())
+	d.Done(blockID(1))
+	require.Equal(t, 3, d.PendingCount())
+	require.True(t, d.Has("p2"))
+	require.False(t, d.Has("p3"))
+}
+
+func TestDispatcher_Stalled(t *testing.T) {
+	p1, p2 := &recordingPeer{id: "p1"}, &recordingPeer{id: "p2"}
+	d := newConfirmedDispatcher([]DownloadPeer{p1, p2}, false)
+	now := time.Now()
+	d.Request(blockID(1), now)
+	d.Request(blockID(2), now)
+	d.Done(blockID(2))
+
+	require.Empty(t, d.Stalled(now.Add(time.Millisecond)))
+	require.Equal(t, []proto.BlockID{blockID(1)}, p1.blocks)
+
+	// The block is requested from the other peer on every stall.
+	var excluded []DownloadPeer
+	for range 4 {
+		now = now.Add(time.Second)
+		excluded = append(excluded, d.Stalled(now)...)
+	}
+	require.Equal(t, []proto.BlockID{blockID(2), blockID(1), blockID(1)}, p2.blocks)
+	require.Equal(t, []proto.BlockID{blockID(1), blockID(1), blockID(1)}, p1.blocks)
+	require.Empty(t, excluded)
+	require.Equal(t, 1, d.PendingCount())
+
+	// The first peer stalls the third time and is excluded, the block is requested from the remaining peer.
+	now = now.Add(time.Second)
+	excluded = d.Stalled(now)
+	require.Len(t, excluded, 1)
+	require.Equal(t, "p1", excluded[0].ID())
+	require.Equal(t, 1, d.PeersCount())
+	require.Len(t, p2.blocks, 4)
+
+	d.SetPeers([]DownloadPeer{p1, p2})
+	require.False(t, d.Has("p1"), "excluded peer is not returned")
+	require.True(t, d.Has("p2"))
+}
+
+func TestDispatcher_ConfirmsPeers(t *testing.T) {
+	syncPeer, same, longer, fork, late := &recordingPeer{id: "sync"}, &recordingPeer{id: "same"},
+		&recordingPeer{id: "longer"}, &recordingPeer{id: "fork"}, &recordingPeer{id: "late"}
+	d := NewDispatcher([]DownloadPeer{syncPeer, same, longer, fork, late}, time.Second, false)
+	require.Equal(t, 1, d.PeersCount(), "only sync peer is used before confirmation")
+
+	locator := []proto.BlockID{blockID(1)}
+	d.AskBlocksIDs(locator)
+	for _, p := range []*recordingPeer{same, longer, fork, late} {
+		require.Equal(t, [][]proto.BlockID{locator}, p.locators)
+		require.True(t, d.IsCandidate(p.id))
+	}
+	require.Empty(t, syncPeer.locators, "sync peer is asked separately")
+
+	ids := []proto.BlockID{blockID(1), blockID(2), blockID(3)}
+	d.Confirm("same", ids)
+	d.Confirm("longer", append(slices.Clone(ids), blockID(4)))
+	d.Confirm("fork", []proto.BlockID{blockID(1), blockID(5), blockID(6)})
+	d.Confirm("unknown", ids)
+	require.Equal(t, 1, d.PeersCount(), "peers are not used until the blocks of sync peer are known")
+
+	d.SetPeers([]DownloadPeer{syncPeer, same, longer, fork, late})
+	d.Expect(ids)
+	require.True(t, d.Has("same"))
+	require.True(t, d.Has("longer"))
+	require.False(t, d.Has("fork"))
+	require.False(t, d.Has("late"))
+	d.Confirm("late", ids[:2])
+	require.False(t, d.Has("late"), "peer without all blocks is not used")
+
+	// The peers in use are checked again on the next block IDs request.
+	next := []proto.BlockID{blockID(3)}
+	d.AskBlocksIDs(next)
+	require.Equal(t, [][]proto.BlockID{locator, next}, same.locators)
+	require.True(t, d.Has("same"))
+	d.SetPeers([]DownloadPeer{syncPeer, same, longer, fork, late})
+	d.Confirm("same", []proto.BlockID{blockID(3), blockID(7)})
+	d.Confirm("longer", []proto.BlockID{blockID(3), blockID(8)})
+	d.Expect([]proto.BlockID{blockID(3), blockID(7)})
+	require.True(t, d.Has("same"))
+	require.False(t, d.Has("longer"), "peer that switched to another fork is not used")
+	require.Equal(t, 2, d.PeersCount())
+}
+
+func TestDispatcher_DoneResetsStalls(t *testing.T) {
+	p := &recordingPeer{id: "p"}
+	d := NewDispatcher([]DownloadPeer{p}, time.Second, false)
+	now := time.Now()
+	// Every stall is followed by the successful download, so the peer is never excluded.
+	for i := byte(1); i <= 2*maxTestStalls; i++ {
+		d.Request(blockID(i), now)
+		now = now.Add(time.Second)
+		require.Empty(t, d.Stalled(now))
+		d.Done(blockID(i))
+	}
+	require.Equal(t, 1, d.PeersCount())
+
+	for i := byte(1); i <= maxTestStalls; i++ {
+		d.Request(blockID(100+i), now)
+	}
+	var excluded []DownloadPeer
+	for range maxTestStalls {
+		now = now.Add(time.Second)
+		excluded = append(excluded, d.Stalled(now)...)
+	}
+	require.Len(t, excluded, 1, "peer is excluded after consecutive stalls")
+}
@@ -2,6 +2,7 @@ package sync_internal
 
 import (
 	"errors"
+	"time"
 
 	"github.com/wavesplatform/gowaves/pkg/libs/ordered_blocks"
 	"github.com/wavesplatform/gowaves/pkg/libs/signatures"
@@ -17,15 +18,10 @@ type BlockApplied bool
 var NoSignaturesExpectedErr = proto.NewInfoMsg(errors.New("no signatures expected"))
 var UnexpectedBlockErr = proto.NewInfoMsg(errors.New("unexpected block"))
 
-type PeerExtension interface {
-	AskBlocksIDs(id []proto.BlockID)
-	AskBlock(id proto.BlockID)
-	AskBlockSnapshot(id proto.BlockID)
-}
-
 type Internal struct {
 	respondedSignatures  *signatures.BlockIDs
 	orderedBlocks        *ordered_blocks.OrderedBlocks
+	dispatcher           *Dispatcher
 	waitingForSignatures bool
 	isLightNode          bool
 }
@@ -33,14 +29,17 @@ type Internal struct {
 func InternalFromLastSignatures(
 	p extension.PeerExtension,
 	signatures *signatures.ReverseOrdering,
+	dispatcher *Dispatcher,
 	isLightNode bool,
 ) Internal {
 	p.AskBlocksIDs(signatures.BlockIDS())
-	return NewInternal(ordered_blocks.NewOrderedBlocks(), signatures, true, isLightNode)
+	dispatcher.AskBlocksIDs(signatures.BlockIDS())
+	return NewInternal(ordered_blocks.NewOrderedBlocks(), dispatcher, signatures, true, isLightNode)
 }
 
 func NewInternal(
 	orderedBlocks *ordered_blocks.OrderedBlocks,
+	dispatcher *Dispatcher,
 	respondedSignatures *signatures.ReverseOrdering,
 	waitingForSignatures bool,
 	isLightNode bool,
@@ -48,15 +47,18 @@ func NewInternal(
 	return Internal{
 		respondedSignatures:  respondedSignatures,
 		orderedBlocks:        orderedBlocks,
+		dispatcher:           dispatcher,
 		waitingForSignatures: waitingForSignatures,
 		isLightNode:          isLightNode,
 	}
 }
 
-func (a Internal) BlockIDs(p PeerExtension, ids []proto.BlockID) (Internal, error) {
+// BlockIDs requests the blocks with new IDs, the requests are spread among the peers of dispatcher.
+func (a Internal) BlockIDs(ids []proto.BlockID, now time.Time) (Internal, error) {
 	if !a.waitingForSignatures {
 		return a, NoSignaturesExpectedErr
 	}
+	a.dispatcher.Expect(ids)
 	var newIDs []proto.BlockID
 	for _, id := range ids {
 		if a.respondedSignatures.Exists(id) {
@@ -64,14 +66,11 @@ func (a Internal) BlockIDs(p PeerExtension, ids []proto.BlockID) (Internal, erro
 		}
 		newIDs = append(newIDs, id)
 		if a.orderedBlocks.Add(id) {
-			p.AskBlock(id)
-			if a.isLightNode {
-				p.AskBlockSnapshot(id)
-			}
+			a.dispatcher.Request(id, now)
 		}
 	}
 	respondedSignatures := signatures.NewSignatures(newIDs...).Revert()
-	return NewInternal(a.orderedBlocks, respondedSignatures, false, a.isLightNode), nil
+	return NewInternal(a.orderedBlocks, a.dispatcher, respondedSignatures, false, a.isLightNode), nil
 }
 
 func (a Internal) WaitingForSignatures() bool {
@@ -83,6 +82,7 @@ func (a Internal) Block(block *proto.Block) (Internal, error) {
 		return a, UnexpectedBlockErr
 	}
 	a.orderedBlocks.SetBlock(block)
+	a.done(block.BlockID())
 	return a, nil
 }
 
@@ -91,30 +91,53 @@ func (a Internal) SetSnapshot(blockID proto.BlockID, snapshot *proto.BlockSnapsh
 		return a, UnexpectedBlockErr
 	}
 	a.orderedBlocks.SetSnapshot(blockID, snapshot)
+	a.done(blockID)
 	return a, nil
 }
 
+func (a Internal) done(blockID proto.BlockID) {
+	if a.orderedBlocks.Received(blockID, a.isLightNode) {
+		a.dispatcher.Done(blockID)
+	}
+}
+
+// Stalled requests again the blocks that were not received in time from other peers and
+// returns the peers excluded from the download because of stalls.
+func (a Internal) Stalled(now time.Time) []DownloadPeer {
+	return a.dispatcher.Stalled(now)
+}
+
+// Dispatcher returns the dispatcher of block requests.
+func (a Internal) Dispatcher() *Dispatcher {
+	return a.dispatcher
+}
+
 type peerExtension interface {
 	AskBlocksIDs(id []proto.BlockID)
 }
 
 func (a Internal) Blocks() (Internal, Blocks, Snapshots, Eof) {
 	if a.waitingForSignatures {
-		return NewInternal(a.orderedBlocks, a.respondedSignatures, a.waitingForSignatures, a.isLightNode), nil, nil, false
+		return NewInternal(a.orderedBlocks, a.dispatcher, a.respondedSignatures, a.waitingForSignatures, a.isLightNode),
+			nil, nil, false
 	}
 	if a.orderedBlocks.RequestedCount() > a.orderedBlocks.ReceivedCount(a.isLightNode) {
-		return NewInternal(a.orderedBlocks, a.respondedSignatures, a.waitingForSignatures, a.isLightNode), nil, nil, false
+		return NewInternal(a.orderedBlocks, a.dispatcher, a.respondedSignatures, a.waitingForSignatures, a.isLightNode),
+			nil, nil, false
 	}
 	if a.orderedBlocks.RequestedCount() < 100 {
 		bs, ss := a.orderedBlocks.PopAll(a.isLightNode)
-		return NewInternal(a.orderedBlocks, a.respondedSignatures, false, a.isLightNode), bs, ss, true
+		return NewInternal(a.orderedBlocks, a.dispatcher, a.respondedSignatures, false, a.isLightNode), bs, ss, true
 	}
 	bs, ss := a.orderedBlocks.PopAll(a.isLightNode)
-	return NewInternal(a.orderedBlocks, a.respondedSignatures, true, a.isLightNode), bs, ss, false
+	return NewInternal(a.orderedBlocks, a.dispatcher, a.respondedSignatures, true, a.isLightNode), bs, ss, false
 }
 
+// AskBlocksIDs asks the sync peer and the other peers of dispatcher for the block IDs after the last received ones.
 func (a Internal) AskBlocksIDs(p peerExtension) {
-	p.AskBlocksIDs(a.respondedSignatures.BlockIDS())
+	ids := a.respondedSignatures.BlockIDS()
+	p.AskBlocksIDs(ids)
+	a.dispatcher.AskBlocksIDs(ids)
 }
 
 func (a Internal) AvailableCount() int {
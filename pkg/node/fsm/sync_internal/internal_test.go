@@ -2,6 +2,7 @@ package sync_internal_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -15,6 +16,10 @@ import (
 type noopWrapper struct {
 }
 
+func (noopWrapper) ID() string {
+	return "noop"
+}
+
 func (noopWrapper) AskBlocksIDs(_ []proto.BlockID) {
 }
 
@@ -41,15 +46,15 @@ func TestSigFSM_Signatures(t *testing.T) {
 	sigs := signatures.NewSignatures()
 
 	t.Run("error on receive unexpected signatures", func(t *testing.T) {
-		fsm := NewInternal(or, sigs, false, false)
-		rs2, err := fsm.BlockIDs(nil, blocksFromSigs(sig1, sig2))
+		fsm := NewInternal(or, NewDispatcher(nil, time.Second, false), sigs, false, false)
+		rs2, err := fsm.BlockIDs(blocksFromSigs(sig1, sig2), time.Now())
 		require.Equal(t, NoSignaturesExpectedErr, err)
 		require.NotNil(t, rs2)
 	})
 
 	t.Run("successful receive signatures", func(t *testing.T) {
-		fsm := NewInternal(or, sigs, true, false)
-		rs2, err := fsm.BlockIDs(noopWrapper{}, blocksFromSigs(sig1, sig2))
+		fsm := NewInternal(or, NewDispatcher([]DownloadPeer{noopWrapper{}}, time.Second, false), sigs, true, false)
+		rs2, err := fsm.BlockIDs(blocksFromSigs(sig1, sig2), time.Now())
 		require.NoError(t, err)
 		require.NotNil(t, rs2)
 		require.False(t, rs2.WaitingForSignatures())
@@ -67,12 +72,15 @@ func block(sig crypto.Signature) *proto.Block {
 func TestSigFSM_Block(t *testing.T) {
 	or := ordered_blocks.NewOrderedBlocks()
 	sigs := signatures.NewSignatures()
-	fsm := NewInternal(or, sigs, true, false)
-	fsm, _ = fsm.BlockIDs(noopWrapper{}, blocksFromSigs(sig1, sig2))
+	d := NewDispatcher([]DownloadPeer{noopWrapper{}}, time.Second, false)
+	fsm := NewInternal(or, d, sigs, true, false)
+	fsm, _ = fsm.BlockIDs(blocksFromSigs(sig1, sig2), time.Now())
+	require.Equal(t, 2, d.PendingCount())
 
 	fsm, _ = fsm.Block(block(sig1))
 	fsm, _ = fsm.Block(block(sig2))
 	require.Equal(t, 2, fsm.AvailableCount())
+	require.Zero(t, d.PendingCount())
 
 	// no panic, cause `nearEnd` is True
 	_, blocks, _, _ := fsm.Blocks()
@@ -82,6 +90,6 @@ func TestSigFSM_Block(t *testing.T) {
 func TestSigFSM_BlockGetSignatures(t *testing.T) {
 	or := ordered_blocks.NewOrderedBlocks()
 	sigs := signatures.NewSignatures()
-	_, bs, _, _ := NewInternal(or, sigs, false, false).Blocks()
+	_, bs, _, _ := NewInternal(or, NewDispatcher(nil, time.Second, false), sigs, false, false).Blocks()
 	require.Nil(t, bs)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/qmuntal/stateless"
 	"go.uber.org/zap"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/errs"
 	"github.com/wavesplatform/gowaves/pkg/logging"
 	"github.com/wavesplatform/gowaves/pkg/metrics"
@@ -106,6 +107,9 @@ func (a *SyncState) Task(task tasks.AsyncTask) (State, Async, error) {
 				a.conf.timeout.String(), a.conf.peerSyncWith.ID())
 			return newIdleState(a.baseInfo), nil, a.Errorf(TimeoutErr)
 		}
+		if excluded := a.internal.Stalled(a.baseInfo.tm.Now()); len(excluded) > 0 {
+			return a.suspendStalledPeers(excluded)
+		}
 		return a, nil, nil
 	case tasks.MineMicro:
 		return a, nil, nil
@@ -128,11 +132,16 @@ func (a *SyncState) BlockIDs(peer peer.Peer, signatures []proto.BlockID) (State,
 	zap.S().Named(logging.FSMNamespace).Debugf("[Sync] Block IDs [%s...%s] received from peer %s",
 		signatures[0].ShortString(), signatures[len(signatures)-1].ShortString(), peer.ID().String())
 	if !peer.Equal(a.conf.peerSyncWith) {
+		if d := a.internal.Dispatcher(); d.IsCandidate(peer.ID().String()) {
+			d.Confirm(peer.ID().String(), signatures)
+			return a, nil, nil
+		}
 		zap.S().Named(logging.FSMNamespace).Debugf("[Sync] Block IDs received from incorrect peer %s, expected %s",
 			peer.ID().String(), a.baseInfo.syncPeer.GetPeer().ID().String())
 		return a, nil, nil
 	}
-	internal, err := a.internal.BlockIDs(extension.NewPeerExtension(peer, a.baseInfo.scheme), signatures)
+	a.internal.Dispatcher().SetPeers(downloadPeers(a.baseInfo, a.conf.peerSyncWith))
+	internal, err := a.internal.BlockIDs(signatures, a.baseInfo.tm.Now())
 	if err != nil {
 		zap.S().Named(logging.FSMNamespace).Debugf("[Sync] No signatures expected from peer '%s' but received",
 			peer.ID().String())
@@ -164,7 +173,7 @@ func (a *SyncState) Score(p peer.Peer, score *proto.Score) (State, Async, error)
 }
 
 func (a *SyncState) Block(p peer.Peer, block *proto.Block) (State, Async, error) {
-	if !p.Equal(a.conf.peerSyncWith) {
+	if !a.internal.Dispatcher().Has(p.ID().String()) {
 		return a, nil, nil
 	}
 	metrics.FSMKeyBlockReceived("sync", block, p.Handshake().NodeName)
@@ -182,7 +191,7 @@ func (a *SyncState) BlockSnapshot(
 	blockID proto.BlockID,
 	snapshot proto.BlockSnapshot,
 ) (State, Async, error) {
-	if !p.Equal(a.conf.peerSyncWith) {
+	if !a.internal.Dispatcher().Has(p.ID().String()) {
 		return a, nil, nil
 	}
 	zap.S().Named(logging.FSMNamespace).Debugf("[Sync][%s] Received snapshot for block %s", p.ID(), blockID.String())
@@ -193,8 +202,30 @@ func (a *SyncState) BlockSnapshot(
 	return a.applyBlocksWithSnapshots(a.baseInfo, a.conf.Now(a.baseInfo.tm), internal)
 }
 
+// suspendStalledPeers suspends the peers that have stalled blocks download too many times.
+// Synchronization is stopped if the sync peer is among them or no peers to download blocks from left.
+func (a *SyncState) suspendStalledPeers(excluded []sync_internal.DownloadPeer) (State, Async, error) {
+	stopSync := a.internal.Dispatcher().PeersCount() == 0
+	for _, ep := range excluded {
+		dp, ok := ep.(downloadPeer)
+		if !ok {
+			continue
+		}
+		zap.S().Named(logging.FSMNamespace).Debugf("[Sync] Suspending peer '%s' because of stalled blocks download",
+			dp.ID())
+		a.baseInfo.peers.Suspend(dp.peer, a.baseInfo.tm.Now(), "stalled blocks download")
+		if dp.peer.Equal(a.conf.peerSyncWith) {
+			stopSync = true
+		}
+	}
+	if stopSync {
+		return newIdleState(a.baseInfo), nil, a.Errorf(TimeoutErr)
+	}
+	return a, nil, nil
+}
+
 func (a *SyncState) MinedBlock(
-	block *proto.Block, limits proto.MiningLimits, keyPair proto.KeyPair, vrf []byte,
+	block *proto.Block, limits proto.MiningLimits, pk crypto.PublicKey, vrf []byte,
 ) (State, Async, error) {
 	metrics.FSMKeyBlockGenerated("sync", block)
 	zap.S().Named(logging.FSMNamespace).Infof("[Sync] New block '%s' mined", block.ID.String())
@@ -212,7 +243,7 @@ func (a *SyncState) MinedBlock(
 	// first we should send block
 	a.baseInfo.actions.SendBlock(block)
 	a.baseInfo.actions.SendScore(a.baseInfo.storage)
-	return a, tasks.Tasks(tasks.NewMineMicroTask(defaultMicroblockInterval, block, limits, keyPair, vrf)), nil
+	return a, tasks.Tasks(tasks.NewMineMicroTask(defaultMicroblockInterval, block, limits, pk, vrf)), nil
 }
 
 func (a *SyncState) Halt() (State, Async, error) {
@@ -386,7 +417,7 @@ func initSyncStateInFSM(state *StateData, fsm *stateless.StateMachine, info Base
 						"unexpected type '%T' expected '*SyncState'", state.State))
 				}
 				return a.MinedBlock(args[0].(*proto.Block), args[1].(proto.MiningLimits),
-					args[2].(proto.KeyPair), args[3].([]byte))
+					args[2].(crypto.PublicKey), args[3].([]byte))
 			})).
 		PermitDynamic(TransactionEvent,
 			createPermitDynamicCallback(TransactionEvent, state, func(args ...interface{}) (State, Async, error) {
@@ -6,6 +6,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/logging"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 )
@@ -110,10 +111,10 @@ func (PingTask) Run(ctx context.Context, output chan AsyncTask) error {
 }
 
 type MineMicroTaskData struct {
-	Block   *proto.Block
-	Limits  proto.MiningLimits
-	KeyPair proto.KeyPair
-	Vrf     []byte
+	Block     *proto.Block
+	Limits    proto.MiningLimits
+	PublicKey crypto.PublicKey
+	Vrf       []byte
 }
 
 func (MineMicroTaskData) taskDataMarker() {}
@@ -123,17 +124,17 @@ type MineMicroTask struct {
 	MineMicroTaskData MineMicroTaskData
 }
 
-func NewMineMicroTask(timeout time.Duration, block *proto.Block, limits proto.MiningLimits, keyPair proto.KeyPair, vrf []byte) MineMicroTask {
+func NewMineMicroTask(timeout time.Duration, block *proto.Block, limits proto.MiningLimits, pk crypto.PublicKey, vrf []byte) MineMicroTask {
 	if block == nil {
 		panic("NewMineMicroTask block is nil")
 	}
 	return MineMicroTask{
 		timeout: timeout,
 		MineMicroTaskData: MineMicroTaskData{
-			Block:   block,
-			Limits:  limits,
-			KeyPair: keyPair,
-			Vrf:     vrf,
+			Block:     block,
+			Limits:    limits,
+			PublicKey: pk,
+			Vrf:       vrf,
 		},
 	}
 }
@@ -163,7 +163,7 @@ func (a *Node) Run(
 		case internalMess := <-internalMessageCh:
 			switch t := internalMess.(type) {
 			case *messages.MinedBlockInternalMessage:
-				async, err = m.MinedBlock(t.Block, t.Limits, t.KeyPair, t.Vrf)
+				async, err = m.MinedBlock(t.Block, t.Limits, t.PublicKey, t.Vrf)
 			case *messages.HaltMessage:
 				async, err = m.Halt()
 				t.Complete()
@@ -0,0 +1,139 @@
+package notifications
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+type BlocksApplier interface {
+	BlockExists(state state.State, block *proto.Block) (bool, error)
+	Apply(state state.State, block []*proto.Block) (proto.Height, error)
+	ApplyMicro(state state.State, block *proto.Block) (proto.Height, error)
+	ApplyWithSnapshots(state state.State, block []*proto.Block, snapshots []*proto.BlockSnapshot) (proto.Height, error)
+	ApplyMicroWithSnapshots(state state.State, block *proto.Block, snapshots *proto.BlockSnapshot) (proto.Height, error)
+	RollbackTo(state state.State, blockID proto.BlockID) error
+}
+
+// Applier is the BlocksApplier decorator, which publishes block and rollback events to the hub.
+type Applier struct {
+	applier BlocksApplier
+	hub     *Hub
+
+	mu     sync.Mutex
+	height proto.Height  // Height of the last seen block.
+	top    proto.BlockID // ID of the last seen block.
+	txs    int           // Number of transactions in the last seen block.
+}
+
+func NewApplier(applier BlocksApplier, hub *Hub, st state.StateInfo) (*Applier, error) {
+	height, err := st.Height()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get state height")
+	}
+	top := st.TopBlock()
+	return &Applier{applier: applier, hub: hub, height: height, top: top.BlockID(), txs: len(top.Transactions)}, nil
+}
+
+func (a *Applier) BlockExists(state state.State, block *proto.Block) (bool, error) {
+	return a.applier.BlockExists(state, block)
+}
+
+func (a *Applier) Apply(state state.State, blocks []*proto.Block) (proto.Height, error) {
+	h, err := a.applier.Apply(state, blocks)
+	if err != nil || len(blocks) == 0 {
+		return h, err
+	}
+	a.afterApply(state, blocks[0].Parent, false)
+	return h, nil
+}
+
+func (a *Applier) ApplyMicro(state state.State, block *proto.Block) (proto.Height, error) {
+	h, err := a.applier.ApplyMicro(state, block)
+	if err != nil {
+		return h, err
+	}
+	a.afterApply(state, block.Parent, true)
+	return h, nil
+}
+
+func (a *Applier) ApplyWithSnapshots(
+	state state.State,
+	blocks []*proto.Block,
+	snapshots []*proto.BlockSnapshot,
+) (proto.Height, error) {
+	h, err := a.applier.ApplyWithSnapshots(state, blocks, snapshots)
+	if err != nil || len(blocks) == 0 {
+		return h, err
+	}
+	a.afterApply(state, blocks[0].Parent, false)
+	return h, nil
+}
+
+func (a *Applier) ApplyMicroWithSnapshots(
+	state state.State,
+	block *proto.Block,
+	snapshot *proto.BlockSnapshot,
+) (proto.Height, error) {
+	h, err := a.applier.ApplyMicroWithSnapshots(state, block, snapshot)
+	if err != nil {
+		return h, err
+	}
+	a.afterApply(state, block.Parent, true)
+	return h, nil
+}
+
+func (a *Applier) RollbackTo(state state.State, blockID proto.BlockID) error {
+	if err := a.applier.RollbackTo(state, blockID); err != nil {
+		return err
+	}
+	a.afterApply(state, blockID, false)
+	return nil
+}
+
+func (a *Applier) afterApply(st state.StateInfo, parent proto.BlockID, micro bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	height, err := st.Height()
+	if err != nil {
+		zap.S().Errorf("Failed to get state height: %v", err)
+		return
+	}
+	top := st.TopBlock()
+	defer func() {
+		a.height, a.top, a.txs = height, top.BlockID(), len(top.Transactions)
+	}()
+	if !a.hub.hasSubscribers() {
+		return
+	}
+	parentHeight, err := st.BlockIDToHeight(parent)
+	if err != nil {
+		zap.S().Errorf("Failed to get height of parent block '%s': %v", parent.String(), err)
+		return
+	}
+	if micro && height == a.height && parentHeight+1 == height {
+		first := a.txs
+		if first > len(top.Transactions) {
+			first = len(top.Transactions)
+		}
+		a.hub.publish(BlockEvent{Height: height, Block: top, First: first, Micro: true})
+		return
+	}
+	if parentHeight < a.height {
+		a.hub.publish(RollbackEvent{Height: parentHeight, BlockID: parent})
+	}
+	for h := parentHeight + 1; h <= height; h++ {
+		b := top
+		if h != height {
+			if b, err = st.BlockByHeight(h); err != nil {
+				zap.S().Errorf("Failed to get block at height %d: %v", h, err)
+				return
+			}
+		}
+		a.hub.publish(BlockEvent{Height: h, Block: b})
+	}
+}
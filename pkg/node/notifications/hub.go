@@ -0,0 +1,123 @@
+package notifications
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// ErrSubscriptionOverflow is returned by Subscription.Err if the subscriber was too slow to read events.
+var ErrSubscriptionOverflow = errors.New("subscription buffer overflow")
+
+// Event is the notification about the change of the blockchain or UTX pool made by the node.
+type Event interface {
+	event()
+}
+
+// BlockEvent is published after the application of key block or micro block.
+type BlockEvent struct {
+	Height proto.Height
+	// Block is the applied block. For micro block it's the whole liquid block with all its transactions.
+	Block *proto.Block
+	// First is the index of the first transaction of Block added by the event, it's zero for key blocks.
+	First int
+	Micro bool
+}
+
+// NewTransactions returns the transactions added to the blockchain by the event.
+func (e BlockEvent) NewTransactions() []proto.Transaction {
+	return e.Block.Transactions[e.First:]
+}
+
+// RollbackEvent is published after the rollback of the blockchain to the given block.
+type RollbackEvent struct {
+	Height  proto.Height
+	BlockID proto.BlockID
+}
+
+// UtxEvent is published when a new transaction is added to UTX pool.
+type UtxEvent struct {
+	Transaction proto.Transaction
+}
+
+// UtxRemovedEvent is published when a transaction leaves UTX pool, because it was put into a block
+// or became invalid.
+type UtxRemovedEvent struct {
+	Transaction proto.Transaction
+}
+
+func (BlockEvent) event()      {}
+func (RollbackEvent) event()   {}
+func (UtxEvent) event()        {}
+func (UtxRemovedEvent) event() {}
+
+// Subscription delivers events published after the moment of subscription.
+// The channel returned by Events is closed when the subscription is terminated, Err returns the reason.
+type Subscription struct {
+	events chan Event
+	err    error
+}
+
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns the reason of subscription termination. It must be called only after the events channel is closed.
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Hub delivers events to subscribers. Subscribers that can't keep up with events are terminated.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers new subscription with the buffer of given size.
+func (h *Hub) Subscribe(bufferSize int) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := &Subscription{events: make(chan Event, bufferSize)}
+	h.subs[s] = struct{}{}
+	return s
+}
+
+// Unsubscribe terminates the subscription. It's safe to call it for already terminated subscription.
+func (h *Hub) Unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.terminate(s, nil)
+}
+
+func (h *Hub) hasSubscribers() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs) > 0
+}
+
+func (h *Hub) publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for s := range h.subs {
+		select {
+		case s.events <- e:
+		default:
+			h.terminate(s, ErrSubscriptionOverflow)
+		}
+	}
+}
+
+func (h *Hub) terminate(s *Subscription, err error) {
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	delete(h.subs, s)
+	s.err = err
+	close(s.events)
+}
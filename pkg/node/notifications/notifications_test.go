@@ -0,0 +1,256 @@
+package notifications
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/types"
+)
+
+// testState keeps the chain of blocks in memory and implements only the methods used by the applier.
+type testState struct {
+	state.State
+	blocks []*proto.Block
+}
+
+func (s *testState) Height() (proto.Height, error) {
+	return proto.Height(len(s.blocks)), nil
+}
+
+func (s *testState) TopBlock() *proto.Block {
+	return s.blocks[len(s.blocks)-1]
+}
+
+func (s *testState) BlockByHeight(height proto.Height) (*proto.Block, error) {
+	if height < 1 || height > proto.Height(len(s.blocks)) {
+		return nil, errors.Errorf("block at height %d not found", height)
+	}
+	return s.blocks[height-1], nil
+}
+
+func (s *testState) BlockIDToHeight(blockID proto.BlockID) (proto.Height, error) {
+	for i, b := range s.blocks {
+		if b.BlockID() == blockID {
+			return proto.Height(i + 1), nil
+		}
+	}
+	return 0, errors.Errorf("block '%s' not found", blockID.String())
+}
+
+func (s *testState) rollbackTo(blockID proto.BlockID) error {
+	h, err := s.BlockIDToHeight(blockID)
+	if err != nil {
+		return err
+	}
+	s.blocks = s.blocks[:h]
+	return nil
+}
+
+// testApplier applies blocks to testState the same way as blocks_applier does.
+type testApplier struct{}
+
+func (a *testApplier) BlockExists(st state.State, block *proto.Block) (bool, error) {
+	_, err := st.BlockIDToHeight(block.BlockID())
+	return err == nil, nil
+}
+
+func (a *testApplier) Apply(st state.State, blocks []*proto.Block) (proto.Height, error) {
+	ts := st.(*testState)
+	if err := ts.rollbackTo(blocks[0].Parent); err != nil {
+		return 0, err
+	}
+	ts.blocks = append(ts.blocks, blocks...)
+	return ts.Height()
+}
+
+func (a *testApplier) ApplyMicro(st state.State, block *proto.Block) (proto.Height, error) {
+	return a.Apply(st, []*proto.Block{block})
+}
+
+func (a *testApplier) ApplyWithSnapshots(st state.State, blocks []*proto.Block, _ []*proto.BlockSnapshot) (proto.Height, error) {
+	return a.Apply(st, blocks)
+}
+
+func (a *testApplier) ApplyMicroWithSnapshots(st state.State, block *proto.Block, _ *proto.BlockSnapshot) (proto.Height, error) {
+	return a.Apply(st, []*proto.Block{block})
+}
+
+func (a *testApplier) RollbackTo(st state.State, blockID proto.BlockID) error {
+	return st.(*testState).rollbackTo(blockID)
+}
+
+var testKeyPair = proto.MustKeyPair([]byte("notifications test seed"))
+
+func testTransfer(t *testing.T, amount uint64) proto.Transaction {
+	addr := proto.MustAddressFromPublicKey(proto.TestNetScheme, testKeyPair.Public)
+	tx := proto.NewUnsignedTransferWithSig(testKeyPair.Public, proto.NewOptionalAssetWaves(),
+		proto.NewOptionalAssetWaves(), 1, amount, 100000, proto.NewRecipientFromAddress(addr), nil)
+	require.NoError(t, tx.Sign(proto.TestNetScheme, testKeyPair.Secret))
+	return tx
+}
+
+func testBlock(parent proto.BlockID, seed byte, txs ...proto.Transaction) *proto.Block {
+	sig := crypto.Signature{}
+	sig[0] = seed
+	return &proto.Block{
+		BlockHeader:  proto.BlockHeader{Version: proto.NgBlockVersion, Parent: parent, BlockSignature: sig},
+		Transactions: txs,
+	}
+}
+
+func receive(t *testing.T, sub *Subscription) Event {
+	select {
+	case e, ok := <-sub.Events():
+		require.True(t, ok, "subscription terminated: %v", sub.Err())
+		return e
+	default:
+		require.FailNow(t, "no event")
+		return nil
+	}
+}
+
+func TestApplier(t *testing.T) {
+	st := &testState{blocks: []*proto.Block{testBlock(proto.BlockID{}, 1)}}
+	hub := NewHub()
+	a, err := NewApplier(&testApplier{}, hub, st)
+	require.NoError(t, err)
+
+	// Events are not collected without subscribers.
+	b2 := testBlock(st.TopBlock().BlockID(), 2, testTransfer(t, 1))
+	_, err = a.Apply(st, []*proto.Block{b2})
+	require.NoError(t, err)
+
+	sub := hub.Subscribe(10)
+	tx1, tx2 := testTransfer(t, 2), testTransfer(t, 3)
+	b3 := testBlock(b2.BlockID(), 3, tx1)
+	_, err = a.Apply(st, []*proto.Block{b3})
+	require.NoError(t, err)
+	assert.Equal(t, BlockEvent{Height: 3, Block: b3}, receive(t, sub))
+
+	b3m := testBlock(b2.BlockID(), 4, tx1, tx2)
+	_, err = a.ApplyMicro(st, b3m)
+	require.NoError(t, err)
+	e := receive(t, sub)
+	assert.Equal(t, BlockEvent{Height: 3, Block: b3m, First: 1, Micro: true}, e)
+	assert.Equal(t, []proto.Transaction{tx2}, e.(BlockEvent).NewTransactions())
+
+	// Fork: the liquid block is replaced by another one.
+	b3f := testBlock(b2.BlockID(), 5)
+	b4f := testBlock(b3f.BlockID(), 6)
+	_, err = a.Apply(st, []*proto.Block{b3f, b4f})
+	require.NoError(t, err)
+	assert.Equal(t, RollbackEvent{Height: 2, BlockID: b2.BlockID()}, receive(t, sub))
+	assert.Equal(t, BlockEvent{Height: 3, Block: b3f}, receive(t, sub))
+	assert.Equal(t, BlockEvent{Height: 4, Block: b4f}, receive(t, sub))
+
+	require.NoError(t, a.RollbackTo(st, b3f.BlockID()))
+	assert.Equal(t, RollbackEvent{Height: 3, BlockID: b3f.BlockID()}, receive(t, sub))
+
+	hub.Unsubscribe(sub)
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.NoError(t, sub.Err())
+}
+
+func TestHubOverflow(t *testing.T) {
+	hub := NewHub()
+	slow, fast := hub.Subscribe(1), hub.Subscribe(2)
+	hub.publish(RollbackEvent{Height: 1})
+	hub.publish(RollbackEvent{Height: 2})
+	receive(t, slow)
+	_, ok := <-slow.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, slow.Err(), ErrSubscriptionOverflow)
+	assert.Equal(t, RollbackEvent{Height: 1}, receive(t, fast))
+	assert.Equal(t, RollbackEvent{Height: 2}, receive(t, fast))
+}
+
+// testUtxPool keeps transactions in the order of addition.
+type testUtxPool struct {
+	types.UtxPool
+	txs []*types.TransactionWithBytes
+}
+
+func (p *testUtxPool) Add(t proto.Transaction) error {
+	return p.AddWithBytes(t, []byte{1})
+}
+
+func (p *testUtxPool) AddWithBytes(t proto.Transaction, b []byte) error {
+	for _, tx := range p.txs {
+		if tx.T == t {
+			return errors.New("exists")
+		}
+	}
+	p.txs = append(p.txs, &types.TransactionWithBytes{T: t, B: b})
+	return nil
+}
+
+func (p *testUtxPool) Pop() *types.TransactionWithBytes {
+	if len(p.txs) == 0 {
+		return nil
+	}
+	tx := p.txs[0]
+	p.txs = p.txs[1:]
+	return tx
+}
+
+func (p *testUtxPool) Count() int {
+	return len(p.txs)
+}
+
+func (p *testUtxPool) AllTransactions() []*types.TransactionWithBytes {
+	return p.txs
+}
+
+func (p *testUtxPool) ExistsByID(id []byte) bool {
+	for _, tx := range p.txs {
+		if txID, err := tx.T.GetID(proto.TestNetScheme); err == nil && bytes.Equal(txID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUtxPool(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(10)
+	utx := NewUtxPool(&testUtxPool{}, hub, proto.TestNetScheme)
+
+	tx := testTransfer(t, 1)
+	require.NoError(t, utx.Add(tx))
+	assert.Equal(t, UtxEvent{Transaction: tx}, receive(t, sub))
+	assert.Error(t, utx.Add(tx))
+
+	// Transaction returned to the pool is not announced again.
+	popped := utx.Pop()
+	require.NotNil(t, popped)
+	require.NoError(t, utx.AddWithBytes(popped.T, popped.B))
+	utx.PublishRemoved()
+	select {
+	case e := <-sub.Events():
+		assert.Failf(t, "unexpected event", "%v", e)
+	default:
+	}
+
+	// Transaction not returned to the pool is published as removed only once.
+	require.NotNil(t, utx.Pop())
+	utx.PublishRemoved()
+	assert.Equal(t, UtxRemovedEvent{Transaction: tx}, receive(t, sub))
+	utx.PublishRemoved()
+	select {
+	case e := <-sub.Events():
+		assert.Failf(t, "unexpected event", "%v", e)
+	default:
+	}
+
+	// Removed transaction is announced again when it's added to the pool.
+	require.NoError(t, utx.Add(tx))
+	assert.Equal(t, UtxEvent{Transaction: tx}, receive(t, sub))
+}
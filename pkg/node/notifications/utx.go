@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"sync"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/types"
+)
+
+// UtxPool is the UTX pool decorator, which publishes the transactions added to the pool to the hub.
+// Transactions returned to the pool after Pop, for example by the miner, are not announced again.
+// Removals are published by PublishRemoved, which must be called after all the popped transactions
+// that are still valid are returned to the pool.
+type UtxPool struct {
+	types.UtxPool
+	hub    *Hub
+	scheme proto.Scheme
+
+	mu        sync.Mutex
+	announced map[crypto.Digest]proto.Transaction
+}
+
+func NewUtxPool(utx types.UtxPool, hub *Hub, scheme proto.Scheme) *UtxPool {
+	return &UtxPool{UtxPool: utx, hub: hub, scheme: scheme, announced: make(map[crypto.Digest]proto.Transaction)}
+}
+
+func (u *UtxPool) Add(t proto.Transaction) error {
+	if err := u.UtxPool.Add(t); err != nil {
+		return err
+	}
+	u.announce(t)
+	return nil
+}
+
+func (u *UtxPool) AddWithBytes(t proto.Transaction, b []byte) error {
+	if err := u.UtxPool.AddWithBytes(t, b); err != nil {
+		return err
+	}
+	u.announce(t)
+	return nil
+}
+
+// PublishRemoved publishes the removal of announced transactions that are not in the pool anymore.
+func (u *UtxPool) PublishRemoved() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for d, t := range u.announced {
+		if u.UtxPool.ExistsByID(d.Bytes()) {
+			continue
+		}
+		delete(u.announced, d)
+		u.hub.publish(UtxRemovedEvent{Transaction: t})
+	}
+}
+
+func (u *UtxPool) announce(t proto.Transaction) {
+	id, err := t.GetID(u.scheme)
+	if err != nil {
+		return
+	}
+	d, err := crypto.NewDigestFromBytes(id)
+	if err != nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.announced[d]; ok {
+		return
+	}
+	u.announced[d] = t
+	u.hub.publish(UtxEvent{Transaction: t})
+}
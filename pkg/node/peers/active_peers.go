@@ -30,6 +30,7 @@ func (ap *activePeers) add(p peer.Peer) {
 
 	ap.m[p.ID()] = newPeerInfo(p)
 	ap.sortedByScore = append(ap.sortedByScore, p.ID())
+	connectedPeersGauge(p).Inc()
 }
 
 func (ap *activePeers) updateScore(peerID peer.ID, score *big.Int) error {
@@ -46,11 +47,13 @@ func (ap *activePeers) updateScore(peerID peer.ID, score *big.Int) error {
 }
 
 func (ap *activePeers) remove(peerID peer.ID) {
-	if _, ok := ap.get(peerID); !ok {
+	info, ok := ap.get(peerID)
+	if !ok {
 		return
 	}
 
 	delete(ap.m, peerID)
+	connectedPeersGauge(info.peer).Dec()
 
 	i := 0
 	for ; i < len(ap.sortedByScore); i++ {
@@ -0,0 +1,50 @@
+package peers
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
+)
+
+const peersMetricsNamespace = "peers"
+
+var (
+	metricConnectedPeers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: peersMetricsNamespace,
+			Name:      "connected",
+			Help:      "Number of connected peers by direction.",
+		},
+		[]string{"direction"},
+	)
+
+	metricSuspendedPeers = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: peersMetricsNamespace,
+			Name:      "suspensions_total",
+			Help:      "Number of peers suspensions.",
+		},
+	)
+
+	metricBlackListedPeers = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: peersMetricsNamespace,
+			Name:      "blacklistings_total",
+			Help:      "Number of peers added to black list.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricConnectedPeers,
+		metricSuspendedPeers,
+		metricBlackListedPeers,
+	)
+}
+
+func connectedPeersGauge(p peer.Peer) prometheus.Gauge {
+	return metricConnectedPeers.WithLabelValues(strings.ToLower(p.Direction().String()))
+}
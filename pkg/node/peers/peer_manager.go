@@ -185,6 +185,7 @@ func (a *PeerManagerImpl) Suspend(p peer.Peer, suspendTime time.Time, reason str
 	if err := a.peerStorage.AddSuspended([]storage.SuspendedPeer{suspended}); err != nil {
 		zap.S().Errorf("[%s] Failed to suspend peer, reason %q: %v", p.ID(), reason, err)
 	} else {
+		metricSuspendedPeers.Inc()
 		zap.S().Named(logging.NetworkNamespace).Debugf("[%s] Suspend peer, reason: %s ", p.ID(), reason)
 	}
 }
@@ -210,6 +211,7 @@ func (a *PeerManagerImpl) AddToBlackList(p peer.Peer, blockTime time.Time, reaso
 	if err := a.peerStorage.AddToBlackList([]storage.BlackListedPeer{blackListed}); err != nil {
 		zap.S().Errorf("[%s] Failed to add peer to black list, reason %q: %v", p.ID(), reason, err)
 	} else {
+		metricBlackListedPeers.Inc()
 		zap.S().Named(logging.NetworkNamespace).Debugf("[%s] Peer added to black list, reason: %s ",
 			p.ID(), reason)
 	}
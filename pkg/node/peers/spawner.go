@@ -3,84 +3,66 @@ package peers
 import (
 	"context"
 	"net"
+	"time"
 
-	"github.com/wavesplatform/gowaves/pkg/node/messages"
-	"github.com/wavesplatform/gowaves/pkg/p2p/conn"
-	"github.com/wavesplatform/gowaves/pkg/p2p/incoming"
-	"github.com/wavesplatform/gowaves/pkg/p2p/outgoing"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/logging"
+	"github.com/wavesplatform/gowaves/pkg/networking"
 	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 )
 
+const outgoingPeerDialTimeout = 5 * time.Second
+
 type DuplicateChecker interface {
 	Add([]byte) bool
 }
 
-func NewSkipFilter(list *messages.SkipMessageList) conn.SkipFilter {
-	return func(header proto.Header) bool {
-		return func(h proto.Header, l *messages.SkipMessageList) bool {
-			for _, id := range l.List() {
-				if h.ContentID == id {
-					return true
-				}
-			}
-			return false
-		}(header, list)
-	}
-}
-
 type PeerSpawner interface {
 	SpawnOutgoing(ctx context.Context, addr proto.TCPAddr) error
 	SpawnIncoming(ctx context.Context, c net.Conn) error
 }
 
+// PeerSpawnerImpl runs the sessions of peers on the [networking.Network].
+// Spawn methods block until the session with the peer is finished.
 type PeerSpawnerImpl struct {
-	parent       peer.Parent
-	wavesNetwork string
-	declAddr     proto.TCPAddr
-	skipFunc     conn.SkipFilter
-	nodeName     string
-	nodeNonce    uint64
-	version      proto.Version
+	network *networking.Network
+	params  peer.SessionParams
 }
 
 func NewPeerSpawner(parent peer.Parent, WavesNetwork string, declAddr proto.TCPAddr, nodeName string, nodeNonce uint64, version proto.Version) *PeerSpawnerImpl {
 	return &PeerSpawnerImpl{
-		skipFunc:     NewSkipFilter(parent.SkipMessageList),
-		parent:       parent,
-		wavesNetwork: WavesNetwork,
-		declAddr:     declAddr,
-		nodeName:     nodeName,
-		nodeNonce:    nodeNonce,
-		version:      version,
+		network: networking.NewNetwork(),
+		params: peer.SessionParams{
+			WavesNetwork: WavesNetwork,
+			Version:      version,
+			NodeName:     nodeName,
+			NodeNonce:    nodeNonce,
+			DeclAddr:     declAddr,
+			Parent:       parent,
+		},
 	}
 }
 
 func (a *PeerSpawnerImpl) SpawnOutgoing(ctx context.Context, address proto.TCPAddr) error {
-	params := outgoing.EstablishParams{
-		Address:      address,
-		WavesNetwork: a.wavesNetwork,
-		Parent:       a.parent,
-		DeclAddr:     a.declAddr,
-		Skip:         a.skipFunc,
-		NodeName:     a.nodeName,
-		NodeNonce:    a.nodeNonce,
+	addr := address.String()
+	dialer := net.Dialer{Timeout: outgoingPeerDialTimeout}
+	c, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		zap.S().Named(logging.NetworkNamespace).Debugf("Outgoing connection to address '%s' failed with error: %v",
+			addr, err)
+		return errors.Wrapf(err, "failed to dial with addr %q", addr)
 	}
-
-	return outgoing.EstablishConnection(ctx, params, a.version)
+	if err := peer.RunSession(ctx, a.network, c, peer.Outgoing, a.params); err != nil {
+		zap.S().Named(logging.NetworkNamespace).Debugf("Outgoing connection to address '%s' failed with error: %v",
+			addr, err)
+		return errors.Wrapf(err, "%q", addr)
+	}
+	return nil
 }
 
 func (a *PeerSpawnerImpl) SpawnIncoming(ctx context.Context, c net.Conn) error {
-	params := incoming.PeerParams{
-		WavesNetwork: a.wavesNetwork,
-		Conn:         c,
-		Skip:         a.skipFunc,
-		Parent:       a.parent,
-		DeclAddr:     a.declAddr,
-		NodeName:     a.nodeName,
-		NodeNonce:    a.nodeNonce,
-		Version:      a.version,
-	}
-
-	return incoming.RunIncomingPeer(ctx, params)
+	return peer.RunSession(ctx, a.network, c, peer.Incoming, a.params)
 }
@@ -27,6 +27,7 @@ type Peer struct {
 	IncomeCh              chan peer.ProtoMessage
 	HandshakeField        proto.Handshake
 	RemoteAddress         proto.TCPAddr
+	DirectionField        peer.Direction
 	mu                    sync.Mutex
 }
 
@@ -38,8 +39,8 @@ func (a *Peer) RemoteAddr() proto.TCPAddr {
 	return a.RemoteAddress
 }
 
-func (*Peer) Direction() peer.Direction {
-	panic("implement me")
+func (a *Peer) Direction() peer.Direction {
+	return a.DirectionField
 }
 
 func (*Peer) Reconnect() error {
@@ -0,0 +1,105 @@
+package peer
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/networking"
+	"github.com/wavesplatform/gowaves/pkg/node/messages"
+	"github.com/wavesplatform/gowaves/pkg/p2p/conn"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const maxMessageSize = 100 * conn.MiB
+
+// knownContentIDs is the set of messages the node is able to unmarshal.
+var knownContentIDs = map[proto.PeerMessageID]struct{}{
+	proto.ContentIDGetPeers:                  {},
+	proto.ContentIDPeers:                     {},
+	proto.ContentIDGetSignatures:             {},
+	proto.ContentIDSignatures:                {},
+	proto.ContentIDGetBlock:                  {},
+	proto.ContentIDBlock:                     {},
+	proto.ContentIDScore:                     {},
+	proto.ContentIDTransaction:               {},
+	proto.ContentIDInvMicroblock:             {},
+	proto.ContentIDMicroblockRequest:         {},
+	proto.ContentIDMicroblock:                {},
+	proto.ContentIDPBBlock:                   {},
+	proto.ContentIDPBMicroBlock:              {},
+	proto.ContentIDPBTransaction:             {},
+	proto.ContentIDGetBlockIDs:               {},
+	proto.ContentIDBlockIDs:                  {},
+	proto.ContentIDGetBlockSnapshot:          {},
+	proto.ContentIDMicroBlockSnapshotRequest: {},
+	proto.ContentIDBlockSnapshot:             {},
+	proto.ContentIDMicroBlockSnapshot:        {},
+}
+
+// header validates the message header on reading, so the session is terminated on malformed or too long messages.
+type header struct {
+	proto.Header
+}
+
+func (h *header) ReadFrom(r io.Reader) (int64, error) {
+	n, err := h.Header.ReadFrom(r)
+	if err != nil {
+		return n, err
+	}
+	if vErr := h.Validate(h.ContentID); vErr != nil {
+		return n, vErr
+	}
+	if l := h.HeaderLength() + h.PayloadLength(); l > maxMessageSize {
+		return n, errors.Errorf("received too long message, size=%d > max=%d", l, maxMessageSize)
+	}
+	return n, nil
+}
+
+// Protocol is the Waves network protocol for the [networking.Session].
+// Handshakes are validated by the peer manager, the protocol only discards unknown and skipped messages.
+type Protocol struct {
+	skip *messages.SkipMessageList
+}
+
+func NewProtocol(skip *messages.SkipMessageList) *Protocol {
+	return &Protocol{skip: skip}
+}
+
+func (p *Protocol) EmptyHandshake() networking.Handshake {
+	return &proto.Handshake{}
+}
+
+func (p *Protocol) EmptyHeader() networking.Header {
+	return &header{}
+}
+
+// Ping always fails, because the Waves protocol has no message that is ignored by the receiving side.
+// The keep-alive of the session must be disabled, the idle connections are detected by the read timeout.
+func (p *Protocol) Ping() ([]byte, error) {
+	return nil, errors.New("keep-alive is not supported by Waves protocol")
+}
+
+func (p *Protocol) IsAcceptableHandshake(_ *networking.Session, h networking.Handshake) bool {
+	_, ok := h.(*proto.Handshake)
+	return ok
+}
+
+func (p *Protocol) IsAcceptableMessage(_ *networking.Session, h networking.Header) bool {
+	hdr, ok := h.(*header)
+	if !ok {
+		return false
+	}
+	if _, ok = knownContentIDs[hdr.ContentID]; !ok {
+		return false
+	}
+	if p.skip == nil {
+		return true
+	}
+	for _, id := range p.skip.List() {
+		if hdr.ContentID == id {
+			return false
+		}
+	}
+	return true
+}
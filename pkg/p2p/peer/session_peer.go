@@ -0,0 +1,346 @@
+package peer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/logging"
+	"github.com/wavesplatform/gowaves/pkg/networking"
+	"github.com/wavesplatform/gowaves/pkg/p2p/conn"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const (
+	handshakeTimeout    = 30 * time.Second
+	sessionWriteTimeout = 15 * time.Second
+)
+
+// SessionParams are the parameters of the node used to run the peer session.
+type SessionParams struct {
+	WavesNetwork string
+	Version      proto.Version
+	NodeName     string
+	NodeNonce    uint64
+	DeclAddr     proto.TCPAddr
+	Parent       Parent
+}
+
+func (p SessionParams) handshake() proto.Handshake {
+	return proto.Handshake{
+		AppName:      p.WavesNetwork,
+		Version:      p.Version,
+		NodeName:     p.NodeName,
+		NodeNonce:    p.NodeNonce,
+		DeclaredAddr: proto.HandshakeTCPAddr(p.DeclAddr),
+		Timestamp:    proto.NewTimestampFromTime(time.Now()),
+	}
+}
+
+// RunSession runs the session with the peer over the connection until the session is closed by any side or
+// the context is canceled. The outgoing peer sends the handshake first, the incoming one replies on the received
+// handshake. The parent is notified about the connected peer after the handshakes exchange.
+// An error is returned only if the handshakes exchange fails.
+func RunSession(ctx context.Context, n *networking.Network, c net.Conn, direction Direction, params SessionParams) error {
+	p := newSessionPeer(c, direction, params)
+	conf := networking.NewConfig().
+		WithProtocol(NewProtocol(params.Parent.SkipMessageList)).
+		WithHandler(p).
+		WithSlogHandler(logging.NewSlogHandler(zap.L().Named(logging.NetworkNamespace))).
+		WithWriteTimeout(sessionWriteTimeout).
+		WithKeepAliveDisabled().
+		WithSlogAttribute(slog.String("direction", direction.String()))
+	s, err := n.NewSession(ctx, idleTimeoutConn{Conn: c, timeout: conn.MaxConnIdleIODuration}, conf)
+	if err != nil {
+		_ = c.Close()
+		return errors.Wrap(err, "failed to create session")
+	}
+	p.setSession(s)
+	defer func() {
+		if clErr := p.Close(); clErr != nil {
+			zap.S().Named(logging.NetworkNamespace).Debugf("[%s] Session closed with error: %v",
+				c.RemoteAddr().String(), clErr)
+		}
+	}()
+
+	if direction == Outgoing {
+		if wErr := p.writeHandshake(s); wErr != nil {
+			return wErr
+		}
+	}
+	timer := time.NewTimer(handshakeTimeout)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil
+	case hErr := <-p.handshakeCh:
+		if hErr != nil {
+			return hErr
+		}
+	case <-p.closed:
+		return errors.New("connection closed before handshake")
+	case <-timer.C:
+		return errors.New("handshake timeout")
+	}
+	return p.sendLoop(ctx)
+}
+
+// SessionPeer is the [Peer] working on top of the [networking.Session].
+type SessionPeer struct {
+	conn      net.Conn
+	direction Direction
+	params    SessionParams
+
+	mu        sync.Mutex
+	session   *networking.Session
+	handshake proto.Handshake
+	id        peerImplID
+
+	sendCh      chan []byte
+	handshakeCh chan error
+	established atomic.Bool // Handshakes are exchanged and the parent is notified about the connection.
+	errSent     atomic.Bool // Error is reported to the parent, so it's going to close the peer.
+	done        chan struct{}
+	doneOnce    sync.Once
+	closed      chan struct{}
+	closedOnce  sync.Once
+}
+
+func newSessionPeer(c net.Conn, direction Direction, params SessionParams) *SessionPeer {
+	return &SessionPeer{
+		conn:        c,
+		direction:   direction,
+		params:      params,
+		sendCh:      make(chan []byte, chSizeInLightMode),
+		handshakeCh: make(chan error, 1),
+		done:        make(chan struct{}),
+		closed:      make(chan struct{}),
+	}
+}
+
+func (p *SessionPeer) setSession(s *networking.Session) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.session = s
+}
+
+func (p *SessionPeer) getSession() *networking.Session {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.session
+}
+
+func (p *SessionPeer) Direction() Direction {
+	return p.direction
+}
+
+// Close closes the session and waits for its loops to finish. It's safe to call Close multiple times.
+func (p *SessionPeer) Close() error {
+	p.doneOnce.Do(func() { close(p.done) })
+	if s := p.getSession(); s != nil {
+		return s.Close()
+	}
+	return p.conn.Close()
+}
+
+// SendMessage marshals provided message and puts it into the send queue of the peer.
+// It reports the error to the parent if the queue is full.
+func (p *SessionPeer) SendMessage(m proto.Message) {
+	b, err := m.MarshalBinary()
+	if err != nil {
+		zap.S().Errorf("Failed to send message %T: %v", m, err)
+		return
+	}
+	zap.S().Named(logging.NetworkDataNamespace).Debugf("[%s] Sending to network: %s", p.ID(), proto.B64Bytes(b))
+	select {
+	case p.sendCh <- b:
+	default:
+		p.reportError(errors.Errorf("send queue overflow on peer '%s'", p.ID()))
+	}
+}
+
+func (p *SessionPeer) ID() ID {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.id
+}
+
+func (p *SessionPeer) Connection() conn.Connection {
+	return sessionConnection{p: p}
+}
+
+func (p *SessionPeer) Handshake() proto.Handshake {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.handshake
+}
+
+func (p *SessionPeer) RemoteAddr() proto.TCPAddr {
+	addr := p.conn.RemoteAddr().(*net.TCPAddr)
+	return proto.TCPAddr(*addr)
+}
+
+func (p *SessionPeer) Equal(other Peer) bool {
+	if other == nil {
+		return false
+	}
+	return p.ID() == other.ID()
+}
+
+// OnReceive unmarshals the message and resends it to the parent. The first malformed message is reported to the
+// parent as an error, the following messages are ignored.
+func (p *SessionPeer) OnReceive(_ *networking.Session, r io.Reader) {
+	if !p.established.Load() || p.errSent.Load() {
+		return
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		p.reportError(errors.Wrap(err, "failed to read message"))
+		return
+	}
+	zap.S().Named(logging.NetworkDataNamespace).Debugf("[%s] Receiving from network: %s",
+		p.ID(), proto.B64Bytes(data))
+	if mErr := bytesToMessage(data, p.params.Parent.MessageCh, p); mErr != nil {
+		p.reportError(mErr)
+	}
+}
+
+// OnHandshake replies with the handshake to the incoming peer and notifies the parent about the connection.
+// It's called from the receive loop of the session, so no messages are received until it returns.
+func (p *SessionPeer) OnHandshake(s *networking.Session, h networking.Handshake) {
+	hs, ok := h.(*proto.Handshake)
+	if !ok {
+		p.handshakeCh <- errors.Errorf("unexpected handshake type %T", h)
+		return
+	}
+	id, err := newPeerImplID(p.conn.RemoteAddr(), hs.NodeNonce)
+	if err != nil {
+		p.handshakeCh <- errors.Wrap(err, "failed to create new peer")
+		return
+	}
+	if p.direction == Incoming {
+		if wErr := p.writeHandshake(s); wErr != nil {
+			p.handshakeCh <- wErr
+			return
+		}
+	}
+	p.mu.Lock()
+	p.handshake = *hs
+	p.id = id
+	p.mu.Unlock()
+	p.established.Store(true)
+	p.notifyParent(&Connected{Peer: p})
+	p.handshakeCh <- nil
+}
+
+func (p *SessionPeer) OnHandshakeFailed(_ *networking.Session, _ networking.Handshake) {
+	p.handshakeCh <- networking.ErrUnacceptableHandshake
+}
+
+// OnClose reports the unexpected closing of the session of connected peer to the parent.
+func (p *SessionPeer) OnClose(_ *networking.Session) {
+	p.closedOnce.Do(func() { close(p.closed) })
+	if p.established.Load() {
+		p.reportError(errors.Errorf("connection with peer '%s' closed", p.ID()))
+	}
+}
+
+// sendLoop writes the queued messages to the session until the session is closed or the context is canceled.
+func (p *SessionPeer) sendLoop(ctx context.Context) error {
+	s := p.getSession()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-p.closed:
+			return nil
+		case <-p.done:
+			return nil
+		case b := <-p.sendCh:
+			if _, err := s.Write(b); err != nil {
+				p.reportError(errors.Wrapf(err, "failed to send message to peer '%s'", p.ID()))
+			}
+		}
+	}
+}
+
+func (p *SessionPeer) writeHandshake(s *networking.Session) error {
+	h := p.params.handshake()
+	buf := new(bytes.Buffer)
+	if _, err := h.WriteTo(buf); err != nil {
+		return errors.Wrap(err, "failed to marshal handshake")
+	}
+	if _, err := s.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "failed to send handshake")
+	}
+	return nil
+}
+
+// reportError sends the error to the parent once.
+func (p *SessionPeer) reportError(err error) {
+	if !p.errSent.CompareAndSwap(false, true) {
+		return
+	}
+	p.notifyParent(&InternalErr{Err: err})
+}
+
+// notifyParent sends the info message to the parent, unless the peer is closed already.
+func (p *SessionPeer) notifyParent(v InfoMessageValue) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	select {
+	case p.params.Parent.InfoCh <- InfoMessage{Peer: p, Value: v}:
+	case <-p.done:
+	}
+}
+
+// sessionConnection provides the [conn.Connection] of the [SessionPeer].
+type sessionConnection struct {
+	p *SessionPeer
+}
+
+func (c sessionConnection) Close() error {
+	return c.p.Close()
+}
+
+func (c sessionConnection) Conn() net.Conn {
+	return c.p.conn
+}
+
+func (c sessionConnection) SendClosed() bool {
+	return c.ReceiveClosed()
+}
+
+func (c sessionConnection) ReceiveClosed() bool {
+	select {
+	case <-c.p.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// idleTimeoutConn sets the read deadline before each read, so the session of the peer that sends nothing,
+// including the keep-alive messages, is terminated.
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c idleTimeoutConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
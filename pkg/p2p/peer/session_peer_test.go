@@ -0,0 +1,163 @@
+package peer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/networking"
+	"github.com/wavesplatform/gowaves/pkg/node/messages"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const testTimeout = 5 * time.Second
+
+func TestProtocolIsAcceptableMessage(t *testing.T) {
+	skip := &messages.SkipMessageList{}
+	skip.SetList(proto.PeerMessageIDs{proto.ContentIDTransaction})
+	p := NewProtocol(skip)
+	for _, test := range []struct {
+		id       proto.PeerMessageID
+		expected bool
+	}{
+		{proto.ContentIDGetPeers, true},
+		{proto.ContentIDMicroBlockSnapshot, true},
+		{proto.ContentIDTransaction, false},
+		{proto.PeerMessageID(0x7f), false},
+	} {
+		h := p.EmptyHeader().(*header)
+		h.ContentID = test.id
+		assert.Equal(t, test.expected, p.IsAcceptableMessage(nil, h), "ContentID %d", test.id)
+	}
+}
+
+func TestProtocolPing(t *testing.T) {
+	// No keep-alive message is sent to the peers, so the peer exchange isn't triggered.
+	_, err := NewProtocol(nil).Ping()
+	assert.Error(t, err)
+}
+
+func TestProtocolHeaderValidation(t *testing.T) {
+	data, err := (&proto.ScoreMessage{Score: []byte{1, 2, 3}}).MarshalBinary()
+	require.NoError(t, err)
+	h := new(header)
+	_, err = h.ReadFrom(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, proto.ContentIDScore, h.ContentID)
+
+	invalid := bytes.Clone(data)
+	binary.BigEndian.PutUint32(invalid[4:8], 0xdeadbeef) // Wrong magic.
+	_, err = new(header).ReadFrom(bytes.NewReader(invalid))
+	assert.ErrorContains(t, err, "wrong magic")
+
+	tooLong := bytes.Clone(data)
+	binary.BigEndian.PutUint32(tooLong[9:13], maxMessageSize) // Payload length.
+	binary.BigEndian.PutUint32(tooLong[0:4], maxMessageSize+13)
+	_, err = new(header).ReadFrom(bytes.NewReader(tooLong))
+	assert.ErrorContains(t, err, "too long message")
+}
+
+func TestRunSession(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	n := networking.NewNetwork()
+	inParent, outParent := NewParent(false), NewParent(false)
+	inDone, outDone := make(chan error, 1), make(chan error, 1)
+	go func() {
+		c, aErr := l.Accept()
+		if aErr != nil {
+			inDone <- aErr
+			return
+		}
+		inDone <- RunSession(ctx, n, c, Incoming, testSessionParams(100, inParent))
+	}()
+	c, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	go func() {
+		outDone <- RunSession(ctx, n, c, Outgoing, testSessionParams(200, outParent))
+	}()
+
+	in := awaitInfo[*Connected](t, inParent).Peer
+	out := awaitInfo[*Connected](t, outParent).Peer
+	assert.Equal(t, Incoming, in.Direction())
+	assert.Equal(t, Outgoing, out.Direction())
+	assert.Equal(t, uint64(200), in.Handshake().NodeNonce)
+	assert.Equal(t, uint64(100), out.Handshake().NodeNonce)
+	assert.Equal(t, "127.0.0.1-200", in.ID().String())
+
+	out.SendMessage(&proto.ScoreMessage{Score: []byte{1, 2, 3}})
+	select {
+	case m := <-inParent.MessageCh:
+		assert.Equal(t, in, m.ID)
+		assert.Equal(t, &proto.ScoreMessage{Score: []byte{1, 2, 3}}, m.Message)
+	case <-time.After(testTimeout):
+		require.FailNow(t, "message is not received")
+	}
+
+	// Closing of one side is reported to the other one, but not to the parent of the closed peer.
+	_ = out.Close()
+	awaitInfo[*InternalErr](t, inParent)
+	assert.True(t, in.Connection().ReceiveClosed())
+	_ = in.Close()
+	for _, done := range []chan error{inDone, outDone} {
+		select {
+		case rErr := <-done:
+			assert.NoError(t, rErr)
+		case <-time.After(testTimeout):
+			require.FailNow(t, "session is not finished")
+		}
+	}
+	assert.Empty(t, outParent.InfoCh)
+}
+
+func TestRunSessionHandshakeFailure(t *testing.T) {
+	server, client := net.Pipe()
+	defer func() { _ = client.Close() }()
+	go func() { _, _ = client.Write([]byte{0xff, 0xff, 0xff}) }() // Incomplete handshake.
+	done := make(chan error, 1)
+	go func() {
+		done <- RunSession(context.Background(), networking.NewNetwork(), server, Incoming,
+			testSessionParams(100, NewParent(false)))
+	}()
+	_ = client.Close()
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(testTimeout):
+		require.FailNow(t, "session is not finished")
+	}
+}
+
+func testSessionParams(nonce uint64, parent Parent) SessionParams {
+	return SessionParams{
+		WavesNetwork: "wavesL",
+		Version:      proto.ProtocolVersion(),
+		NodeName:     "test",
+		NodeNonce:    nonce,
+		Parent:       parent,
+	}
+}
+
+func awaitInfo[T InfoMessageValue](t *testing.T, parent Parent) T {
+	select {
+	case m := <-parent.InfoCh:
+		v, ok := m.Value.(T)
+		require.True(t, ok, "unexpected info message %T", m.Value)
+		return v
+	case <-time.After(testTimeout):
+		require.FailNow(t, "info message is not received")
+	}
+	var zero T
+	return zero
+}
@@ -164,6 +164,20 @@ func (bs *BlockSnapshot) UnmarshalJSON(bytes []byte) error {
 	return nil
 }
 
+// TxSnapshot is the list of atomic snapshots of a single transaction. It's marshaled to JSON
+// the same way as the transaction snapshots of BlockSnapshot.
+type TxSnapshot []AtomicSnapshot
+
+func (s TxSnapshot) MarshalJSON() ([]byte, error) {
+	var js txSnapshotJSON
+	for _, snapshot := range s {
+		if err := snapshot.Apply(&js); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(&js)
+}
+
 type balanceSnapshotJSON struct {
 	Address WavesAddress  `json:"address"`
 	Asset   OptionalAsset `json:"asset"`
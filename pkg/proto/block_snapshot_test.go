@@ -359,3 +359,20 @@ func Test_txSnapshotJSON_MarshalJSON_UnmarshalJSON(t *testing.T) {
 	assert.Len(t, unmEmptyBs.TxSnapshots, 0)
 	assert.Nil(t, unmEmptyBs.TxSnapshots)
 }
+
+func TestTxSnapshot_MarshalJSON(t *testing.T) {
+	addr, err := proto.NewAddressFromString("3NA26AC1aLjj6uYnuoTahauhUPPPB3VBPUe")
+	require.NoError(t, err)
+	snapshot := proto.TxSnapshot{
+		&proto.TransactionStatusSnapshot{Status: proto.TransactionFailed},
+		&proto.WavesBalanceSnapshot{Address: addr, Balance: 49315001748316},
+	}
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	bsData, err := json.Marshal(proto.BlockSnapshot{TxSnapshots: [][]proto.AtomicSnapshot{snapshot}})
+	require.NoError(t, err)
+	assert.JSONEq(t, "["+string(data)+"]", string(bsData))
+
+	_, err = json.Marshal(proto.TxSnapshot{})
+	assert.Error(t, err)
+}
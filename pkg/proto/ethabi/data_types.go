@@ -3,6 +3,8 @@ package ethabi
 import (
 	"encoding/binary"
 	"math/big"
+
+	"github.com/pkg/errors"
 )
 
 type DataType interface{ ethABIDataTypeMarker() }
@@ -16,6 +18,7 @@ type (
 	Bytes  []byte
 	String string
 	List   []DataType
+	Tuple  []DataType
 )
 
 func (Int) ethABIDataTypeMarker()    {}
@@ -24,10 +27,16 @@ func (Bool) ethABIDataTypeMarker()   {}
 func (Bytes) ethABIDataTypeMarker()  {}
 func (String) ethABIDataTypeMarker() {}
 func (List) ethABIDataTypeMarker()   {}
+func (Tuple) ethABIDataTypeMarker()  {}
 
 const abiSlotSize = 32
 
 func (i Int) encodeToABISlot() (slot [abiSlotSize]byte) {
+	if i < 0 { // sign extension of the int256 value
+		for j := range slot[:abiSlotSize-8] {
+			slot[j] = 0xff
+		}
+	}
 	binary.BigEndian.PutUint64(slot[abiSlotSize-8:], uint64(i))
 	return slot
 }
@@ -62,3 +71,119 @@ func (s String) EncodeToABI() []byte {
 	out = append(out, s[:]...)
 	return out[:outSize]
 }
+
+// Bounds of int256 ABI type.
+var (
+	minInt256 = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 8*abiSlotSize-1))
+	maxInt256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 8*abiSlotSize-1), big.NewInt(1))
+)
+
+func (b BigInt) encodeToABISlot() (slot [abiSlotSize]byte, _ error) {
+	if b.V == nil {
+		return slot, errors.New("nil big integer")
+	}
+	if b.V.Cmp(minInt256) < 0 || b.V.Cmp(maxInt256) > 0 {
+		return slot, errors.Errorf("big integer %s doesn't fit int256", b.V.String())
+	}
+	v := b.V
+	if v.Sign() < 0 { // two's complement representation
+		v = new(big.Int).Add(v, new(big.Int).Lsh(big.NewInt(1), 8*abiSlotSize))
+	}
+	v.FillBytes(slot[:])
+	return slot, nil
+}
+
+// EncodeValuesToABI encodes the values according to the ABI specification as the tuple of function outputs.
+// Lists are encoded as dynamic arrays of their elements, Tuple as the tuple type, Bytes and String as the dynamic
+// bytes and string types.
+func EncodeValuesToABI(values ...DataType) ([]byte, error) {
+	return encodeTupleToABI(values)
+}
+
+// isDynamicValue reports whether the value is encoded in place or in the tail of the enclosing tuple.
+func isDynamicValue(v DataType) bool {
+	switch v := v.(type) {
+	case Bytes, String, List:
+		return true
+	case Tuple:
+		for _, e := range v {
+			if isDynamicValue(e) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func encodeValueToABI(v DataType) ([]byte, error) {
+	switch v := v.(type) {
+	case Int:
+		return v.EncodeToABI(), nil
+	case BigInt:
+		slot, err := v.encodeToABISlot()
+		if err != nil {
+			return nil, err
+		}
+		return slot[:], nil
+	case Bool:
+		return v.EncodeToABI(), nil
+	case Bytes:
+		return encodeBytesToABI(v), nil
+	case String:
+		return encodeBytesToABI([]byte(v)), nil
+	case List:
+		size := Int(len(v)).encodeToABISlot()
+		elements, err := encodeTupleToABI(v)
+		if err != nil {
+			return nil, err
+		}
+		return append(size[:], elements...), nil
+	case Tuple:
+		return encodeTupleToABI(v)
+	default:
+		return nil, errors.Errorf("unsupported ABI data type %T", v)
+	}
+}
+
+// encodeBytesToABI encodes the length of the data followed by the data padded to the slot size.
+func encodeBytesToABI(data []byte) []byte {
+	slots := (len(data) + abiSlotSize - 1) / abiSlotSize
+	size := Int(len(data)).encodeToABISlot()
+	out := make([]byte, abiSlotSize+slots*abiSlotSize)
+	copy(out, size[:])
+	copy(out[abiSlotSize:], data)
+	return out
+}
+
+// encodeTupleToABI encodes the static values and the offsets of dynamic values in the head followed
+// by the encoded dynamic values.
+func encodeTupleToABI(values []DataType) ([]byte, error) {
+	encoded := make([][]byte, len(values))
+	headSize := 0
+	for i, v := range values {
+		e, err := encodeValueToABI(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encode value at position %d", i)
+		}
+		encoded[i] = e
+		if isDynamicValue(v) {
+			headSize += abiSlotSize
+		} else {
+			headSize += len(e)
+		}
+	}
+	head := make([]byte, 0, headSize)
+	var tail []byte
+	for i, v := range values {
+		if !isDynamicValue(v) {
+			head = append(head, encoded[i]...)
+			continue
+		}
+		offset := Int(headSize + len(tail)).encodeToABISlot()
+		head = append(head, offset[:]...)
+		tail = append(tail, encoded[i]...)
+	}
+	return append(head, tail...), nil
+}
@@ -2,6 +2,7 @@ package ethabi
 
 import (
 	"encoding/hex"
+	"math/big"
 	"strings"
 	"testing"
 
@@ -33,3 +34,47 @@ func TestString_EncodeToABI(t *testing.T) {
 	}
 
 }
+
+func TestEncodeValuesToABI(t *testing.T) {
+	slot := func(s string) string { return strings.Repeat("0", 64-len(s)) + s }
+	maxInt256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1))
+	tests := []struct {
+		values []DataType
+		hexABI string
+	}{
+		{values: nil, hexABI: ""},
+		{values: []DataType{Int(-1)}, hexABI: strings.Repeat("f", 64)},
+		{values: []DataType{BigInt{V: big.NewInt(-2)}}, hexABI: strings.Repeat("f", 63) + "e"},
+		{values: []DataType{BigInt{V: maxInt256}}, hexABI: "7" + strings.Repeat("f", 63)},
+		{values: []DataType{Tuple{Int(1), Bool(true)}, Int(2)}, hexABI: slot("1") + slot("1") + slot("2")},
+		{ // Example of the ABI specification: sam(bytes,bool,uint256[]) with ("dave", true, [1, 2, 3])
+			values: []DataType{Bytes("dave"), Bool(true), List{Int(1), Int(2), Int(3)}},
+			hexABI: slot("60") + slot("1") + slot("a0") +
+				slot("4") + "64617665" + strings.Repeat("0", 56) +
+				slot("3") + slot("1") + slot("2") + slot("3"),
+		},
+		{ // Example of the ABI specification: g(uint256[][],string[]) with ([[1, 2], [3]], ["one", "two", "three"])
+			values: []DataType{
+				List{List{Int(1), Int(2)}, List{Int(3)}},
+				List{String("one"), String("two"), String("three")},
+			},
+			hexABI: slot("40") + slot("140") +
+				slot("2") + slot("40") + slot("a0") + slot("2") + slot("1") + slot("2") + slot("1") + slot("3") +
+				slot("3") + slot("60") + slot("a0") + slot("e0") +
+				slot("3") + "6f6e65" + strings.Repeat("0", 58) +
+				slot("3") + "74776f" + strings.Repeat("0", 58) +
+				slot("5") + "7468726565" + strings.Repeat("0", 54),
+		},
+		{ // Dynamic tuple is encoded in the tail
+			values: []DataType{Int(7), Tuple{String(""), Int(8)}},
+			hexABI: slot("7") + slot("40") + slot("40") + slot("8") + slot("0"),
+		},
+	}
+	for _, tc := range tests {
+		actual, err := EncodeValuesToABI(tc.values...)
+		require.NoError(t, err)
+		require.Equal(t, tc.hexABI, hex.EncodeToString(actual))
+	}
+	_, err := EncodeValuesToABI(List{BigInt{V: new(big.Int).Add(maxInt256, big.NewInt(1))}})
+	require.Error(t, err)
+}
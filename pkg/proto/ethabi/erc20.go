@@ -2,10 +2,13 @@ package ethabi
 
 import (
 	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 )
 
 const (
 	erc20TransferSignature         Signature = "transfer(address,uint256)"
+	erc20TransferEventSignature    Signature = "Transfer(address,address,uint256)"
 	EthereumAddressSize            int       = 20
 	NumberOfERC20TransferArguments int       = 2
 	ERC20TransferCallDataSize                = SelectorSize + 2*abiSlotSize // selector + _to + _value
@@ -14,6 +17,9 @@ const (
 var (
 	erc20TransferSelector = erc20TransferSignature.Selector()
 
+	// ERC20TransferEventTopic is the first topic of ERC20 Transfer event log.
+	ERC20TransferEventTopic = erc20TransferEventSignature.Hash()
+
 	methods = map[Selector]Method{
 		erc20TransferSelector: {
 			RawName: "transfer",
@@ -85,3 +91,26 @@ func GetERC20TransferArguments(decodedData *DecodedCallData) (ERC20TransferArgum
 
 	return ERC20TransferArguments{Recipient: ethRecipient, Amount: transferAmount.V.Int64()}, nil
 }
+
+// ERC20TransferEvent is the ERC20 Transfer(address,address,uint256) event.
+type ERC20TransferEvent struct {
+	From   [EthereumAddressSize]byte
+	To     [EthereumAddressSize]byte
+	Amount int64
+}
+
+// Topics returns the topics of the event log: the event signature hash and indexed sender and recipient addresses.
+func (e ERC20TransferEvent) Topics() []crypto.Digest {
+	return []crypto.Digest{ERC20TransferEventTopic, addressTopic(e.From), addressTopic(e.To)}
+}
+
+// Data returns ABI encoded not indexed amount of the event.
+func (e ERC20TransferEvent) Data() []byte {
+	return Int(e.Amount).EncodeToABI()
+}
+
+func addressTopic(addr [EthereumAddressSize]byte) crypto.Digest {
+	var topic crypto.Digest
+	copy(topic[abiSlotSize-EthereumAddressSize:], addr[:])
+	return topic
+}
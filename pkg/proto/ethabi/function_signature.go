@@ -36,6 +36,11 @@ func (s Signature) Selector() Selector {
 	return NewSelector(s)
 }
 
+// Hash returns Keccak256 hash of the signature. The hash of event signature is the first topic of event log.
+func (s Signature) Hash() crypto.Digest {
+	return crypto.MustKeccak256([]byte(s))
+}
+
 const SelectorSize = 4
 
 type Selector [SelectorSize]byte
@@ -63,6 +63,20 @@ func TestGetERC20TransferArguments(t *testing.T) {
 	require.Equal(t, expectedSecondArg, fmt.Sprintf("%d", transferArgs.Amount))
 }
 
+func TestERC20TransferEvent(t *testing.T) {
+	var from, to [EthereumAddressSize]byte
+	copy(from[:], bytes.Repeat([]byte{0x11}, EthereumAddressSize))
+	copy(to[:], bytes.Repeat([]byte{0x22}, EthereumAddressSize))
+	e := ERC20TransferEvent{From: from, To: to, Amount: 31650332672000}
+
+	topics := e.Topics()
+	require.Len(t, topics, 3)
+	require.Equal(t, "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", hex.EncodeToString(topics[0][:]))
+	require.Equal(t, "000000000000000000000000"+strings.Repeat("11", EthereumAddressSize), hex.EncodeToString(topics[1][:]))
+	require.Equal(t, "000000000000000000000000"+strings.Repeat("22", EthereumAddressSize), hex.EncodeToString(topics[2][:]))
+	require.Equal(t, "00000000000000000000000000000000000000000000000000001cc92ad60000", hex.EncodeToString(e.Data()))
+}
+
 func TestRandomFunctionABIParsing(t *testing.T) {
 	// taken and modified from https://etherscan.io/tx/0x2667bb17f2076cad4966849255898fbcaca68f2eb0d9ba585b310c79c098e970
 
@@ -11,473 +11,468 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/ride/ast"
 )
 
+// Compile translates the tree into the code of the bytecode VM.
+// The code preserves the evaluation order of the tree, so the scoping rules, complexity and errors of the VM
+// are the same as of the tree evaluator.
 func Compile(tree *ast.Tree) (RideScript, error) {
-	fCheck, err := selectFunctionChecker(tree.LibVersion)
+	c, err := newCompiler(tree)
 	if err != nil {
 		return nil, errors.Wrap(err, "compile")
 	}
-	cCheck, err := selectConstantsChecker(tree.LibVersion)
-	if err != nil {
-		return nil, errors.Wrap(err, "compile")
-	}
-	c := &compiler{
-		constants:     newRideConstants(),
-		checkFunction: fCheck,
-		checkConstant: cCheck,
-		values:        make([]rideValue, 0),
-		functions:     make([]*localFunction, 0),
-		declarations:  make([]rideDeclaration, 0),
-		patcher:       newPatcher(),
-	}
 	if tree.IsDApp() {
 		return c.compileDAppScript(tree)
 	}
 	return c.compileSimpleScript(tree)
 }
 
-type compiler struct {
-	constants     *rideConstants
-	checkFunction func(string) (uint16, bool)
-	checkConstant func(string) (uint16, bool)
-	values        []rideValue
-	functions     []*localFunction
-	declarations  []rideDeclaration
-	patcher       *patcher
-	callable      *rideCallable
+// pending is an expression that is compiled after the main code, the position of the expression is
+// passed to the patch function.
+type pending struct {
+	node  ast.Node
+	patch func(pos int)
 }
 
-func (c *compiler) compileSimpleScript(tree *ast.Tree) (*SimpleScript, error) {
-	bb := new(bytes.Buffer)
-	err := c.compile(bb, tree.Verifier)
-	if err != nil {
+type compiler struct {
+	version   ast.LibraryVersion
+	constants *rideConstants
+	names     []string
+	nameIDs   map[string]uint16
+	natives   []nativeFunction
+	nativeIDs map[string]uint16
+	functions []userFunction
+	declared  map[string]struct{} // Names of user functions declared anywhere in the tree
+	queue     []pending
+	bb        *bytes.Buffer
+}
+
+func newCompiler(tree *ast.Tree) (*compiler, error) {
+	if _, err := selectConstantsChecker(tree.LibVersion); err != nil {
 		return nil, err
 	}
-	bb.WriteByte(OpHalt)
-	for _, d := range c.declarations {
-		pos := bb.Len()
-		c.patcher.setOrigin(d.buffer(), pos)
-		bb.Write(patchedCode(d.buffer(), pos))
-		bb.WriteByte(OpReturn)
-		for _, ref := range d.references() {
-			c.patcher.setPosition(ref, uint16(pos))
+	c := &compiler{
+		version:   tree.LibVersion,
+		constants: newRideConstants(),
+		names:     make([]string, 0),
+		nameIDs:   make(map[string]uint16),
+		natives:   make([]nativeFunction, 0),
+		nativeIDs: make(map[string]uint16),
+		functions: make([]userFunction, 0),
+		declared:  make(map[string]struct{}),
+		bb:        new(bytes.Buffer),
+	}
+	for _, n := range tree.Declarations {
+		c.collect(n)
+	}
+	for _, n := range tree.Functions {
+		c.collect(n)
+	}
+	c.collect(tree.Verifier)
+	return c, nil
+}
+
+// collect gathers names of all user functions declared in the node.
+func (c *compiler) collect(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.ConditionalNode:
+		c.collect(n.Condition)
+		c.collect(n.TrueExpression)
+		c.collect(n.FalseExpression)
+	case *ast.AssignmentNode:
+		c.collect(n.Expression)
+		c.collect(n.Block)
+	case *ast.FunctionDeclarationNode:
+		c.declared[n.Name] = struct{}{}
+		c.collect(n.Body)
+		c.collect(n.Block)
+	case *ast.FunctionCallNode:
+		for _, a := range n.Arguments {
+			c.collect(a)
 		}
+	case *ast.PropertyNode:
+		c.collect(n.Object)
 	}
-	code := bb.Bytes()
-	patches, err := c.patcher.get()
-	if err != nil {
+}
+
+func (c *compiler) compileSimpleScript(tree *ast.Tree) (*SimpleScript, error) {
+	if err := c.compile(tree.Verifier); err != nil {
 		return nil, err
 	}
-	for pos, addr := range patches {
-		binary.BigEndian.PutUint16(code[pos:], addr)
+	c.bb.WriteByte(OpHalt)
+	p, err := c.program()
+	if err != nil {
+		return nil, err
 	}
-	return &SimpleScript{
-		LibVersion: tree.LibVersion,
-		EntryPoint: 0,
-		Code:       bb.Bytes(),
-		Constants:  c.constants.items,
-	}, nil
+	return &SimpleScript{program: p, EntryPoint: 0}, nil
 }
 
 func (c *compiler) compileDAppScript(tree *ast.Tree) (*DAppScript, error) {
-	// Compile global declarations.
-	// Each declaration goes to the declaration stack with code compiled in its own buffer.
-	functions := make(map[string]callable)
-	bb := new(bytes.Buffer)
-	for _, node := range tree.Declarations {
-		err := c.compile(bb, node)
-		if err != nil {
-			return nil, err
+	declarations := make([]declaration, len(tree.Declarations))
+	for i, node := range tree.Declarations {
+		switch d := node.(type) {
+		case *ast.AssignmentNode:
+			id, err := c.name(d.Name)
+			if err != nil {
+				return nil, err
+			}
+			declarations[i] = declaration{id: id}
+			c.postpone(d.Expression, func(pos int) { declarations[i].expression = pos })
+		case *ast.FunctionDeclarationNode:
+			fid, err := c.function(d)
+			if err != nil {
+				return nil, err
+			}
+			declarations[i] = declaration{function: true, id: fid}
+		default:
+			return nil, errors.Errorf("invalid declaration type '%T'", node)
 		}
 	}
+	entryPoints := make(map[string]callable, len(tree.Functions)+1)
 	for _, n := range tree.Functions {
 		fn, ok := n.(*ast.FunctionDeclarationNode)
 		if !ok {
 			return nil, errors.Errorf("invalid node type %T", n)
 		}
-		c.callable = &rideCallable{
-			name:      fn.Name,
-			parameter: fn.InvocationParameter,
+		if _, ok := entryPoints[fn.Name]; ok { // Only the first callable with the name is accessible
+			continue
 		}
-		err := c.compile(bb, fn)
+		ep, err := c.callable(fn)
 		if err != nil {
 			return nil, err
 		}
+		entryPoints[fn.Name] = ep
 	}
 	if tree.HasVerifier() {
 		v, ok := tree.Verifier.(*ast.FunctionDeclarationNode)
 		if !ok {
 			return nil, errors.Errorf("invalid node type for DApp's verifier '%T'", tree.Verifier)
 		}
-		c.callable = &rideCallable{
-			name:      "", // Verifier has empty name
-			parameter: v.InvocationParameter,
-		}
-		err := c.compile(bb, v)
+		ep, err := c.callable(v)
 		if err != nil {
 			return nil, err
 		}
-		functions[""] = callable{
-			entryPoint:    0,
-			parameterName: v.InvocationParameter,
-		}
+		entryPoints[""] = ep // Verifier has empty name
 	}
-	// All declarations go here after verifier and public functions
-	for _, d := range c.declarations {
-		pos := bb.Len()
-		c.patcher.setOrigin(d.buffer(), pos)
-		bb.Write(patchedCode(d.buffer(), pos))
-		if c := d.callable(); c != nil {
-			bb.WriteByte(OpHalt)
-			// Add reference to entry point
-			functions[c.name] = callable{
-				entryPoint:    pos,
-				parameterName: c.parameter,
-			}
-		} else {
-			bb.WriteByte(OpReturn)
-		}
-		for _, ref := range d.references() {
-			c.patcher.setPosition(ref, uint16(pos))
+	p, err := c.program()
+	if err != nil {
+		return nil, err
+	}
+	return &DAppScript{program: p, EntryPoints: entryPoints, Declarations: declarations}, nil
+}
+
+func (c *compiler) callable(fn *ast.FunctionDeclarationNode) (callable, error) {
+	parameter, err := c.name(fn.InvocationParameter)
+	if err != nil {
+		return callable{}, err
+	}
+	args, err := c.arguments(fn.Arguments)
+	if err != nil {
+		return callable{}, err
+	}
+	ep := callable{entryPoint: c.bb.Len(), parameter: parameter, arguments: args}
+	if err := c.compile(fn.Body); err != nil {
+		return callable{}, err
+	}
+	c.bb.WriteByte(OpHalt)
+	return ep, nil
+}
+
+// program compiles all pending expressions and links the program.
+func (c *compiler) program() (program, error) {
+	for len(c.queue) > 0 {
+		var p pending
+		p, c.queue = c.queue[0], c.queue[1:]
+		p.patch(c.bb.Len())
+		if err := c.compile(p.node); err != nil {
+			return program{}, err
 		}
+		c.bb.WriteByte(OpReturn)
 	}
-	code := bb.Bytes()
-	patches, err := c.patcher.get()
+	if uint64(c.bb.Len()) > math.MaxUint32 {
+		return program{}, errors.New("max code length reached")
+	}
+	constantChecker, err := selectConstantsChecker(c.version)
 	if err != nil {
-		return nil, err
+		return program{}, err
 	}
-	for pos, addr := range patches {
-		binary.BigEndian.PutUint16(code[pos:], addr)
+	constantProvider, err := selectConstants(c.version)
+	if err != nil {
+		return program{}, err
+	}
+	globals := make([]rideConstructor, len(c.names))
+	for i, n := range c.names {
+		if id, ok := constantChecker(n); ok {
+			globals[i] = constantProvider(int(id))
+		}
 	}
-	return &DAppScript{
-		LibVersion:  tree.LibVersion,
-		Code:        bb.Bytes(),
-		Constants:   c.constants.items,
-		EntryPoints: functions,
+	return program{
+		LibVersion: c.version,
+		Code:       c.bb.Bytes(),
+		Constants:  c.constants.items,
+		names:      c.names,
+		globals:    globals,
+		natives:    c.natives,
+		functions:  c.functions,
 	}, nil
 }
 
-func (c *compiler) compile(bb *bytes.Buffer, node ast.Node) error {
+func (c *compiler) postpone(node ast.Node, patch func(pos int)) {
+	c.queue = append(c.queue, pending{node: node, patch: patch})
+}
+
+// placeholder writes empty code position and returns function to set it.
+func (c *compiler) placeholder() func(pos int) {
+	at := c.bb.Len()
+	c.bb.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	return func(pos int) {
+		binary.BigEndian.PutUint32(c.bb.Bytes()[at:], uint32(pos))
+	}
+}
+
+func (c *compiler) compile(node ast.Node) error {
 	switch n := node.(type) {
 	case *ast.LongNode:
-		return c.longNode(bb, n)
+		return c.push(rideInt(n.Value))
 	case *ast.BytesNode:
-		return c.bytesNode(bb, n)
+		return c.push(rideByteVector(n.Value))
 	case *ast.StringNode:
-		return c.stringNode(bb, n)
+		return c.push(rideString(n.Value))
 	case *ast.BooleanNode:
-		return c.booleanNode(bb, n)
+		if n.Value {
+			c.bb.WriteByte(OpTrue)
+		} else {
+			c.bb.WriteByte(OpFalse)
+		}
+		return nil
 	case *ast.ConditionalNode:
-		return c.conditionalNode(bb, n)
+		return c.conditionalNode(n)
 	case *ast.AssignmentNode:
-		return c.assignmentNode(bb, n)
+		return c.assignmentNode(n)
 	case *ast.ReferenceNode:
-		return c.referenceNode(bb, n)
+		id, err := c.name(n.Name)
+		if err != nil {
+			return err
+		}
+		c.bb.WriteByte(OpLoad)
+		c.bb.Write(encode(id))
+		return nil
 	case *ast.FunctionDeclarationNode:
-		return c.functionDeclarationNode(bb, n)
+		return c.functionDeclarationNode(n)
 	case *ast.FunctionCallNode:
-		return c.callNode(bb, n)
+		return c.callNode(n)
 	case *ast.PropertyNode:
-		return c.propertyNode(bb, n)
+		return c.propertyNode(n)
 	default:
 		return errors.Errorf("unexpected node type '%T'", node)
 	}
 }
 
-func (c *compiler) longNode(bb *bytes.Buffer, node *ast.LongNode) error {
-	cid, err := c.constants.put(rideInt(node.Value))
+func (c *compiler) push(v rideType) error {
+	cid, err := c.constants.put(v)
 	if err != nil {
 		return err
 	}
-	bb.WriteByte(OpPush)
-	bb.Write(encode(cid))
+	c.bb.WriteByte(OpPush)
+	c.bb.Write(encode(cid))
 	return nil
 }
 
-func (c *compiler) bytesNode(bb *bytes.Buffer, node *ast.BytesNode) error {
-	cid, err := c.constants.put(rideByteVector(node.Value))
-	if err != nil {
+func (c *compiler) conditionalNode(node *ast.ConditionalNode) error {
+	c.bb.WriteByte(OpCondition)
+	if err := c.compile(node.Condition); err != nil {
 		return err
 	}
-	bb.WriteByte(OpPush)
-	bb.Write(encode(cid))
-	return nil
-}
-
-func (c *compiler) stringNode(bb *bytes.Buffer, node *ast.StringNode) error {
-	cid, err := c.constants.put(rideString(node.Value))
-	if err != nil {
+	c.bb.WriteByte(OpJumpIfFalse)
+	otherwise := c.placeholder()
+	if err := c.compile(node.TrueExpression); err != nil {
 		return err
 	}
-	bb.WriteByte(OpPush)
-	bb.Write(encode(cid))
-	return nil
-}
-
-func (c *compiler) booleanNode(bb *bytes.Buffer, node *ast.BooleanNode) error {
-	if node.Value {
-		bb.WriteByte(OpTrue)
-	} else {
-		bb.WriteByte(OpFalse)
+	c.bb.WriteByte(OpJump)
+	end := c.placeholder()
+	otherwise(c.bb.Len())
+	if err := c.compile(node.FalseExpression); err != nil {
+		return err
 	}
+	end(c.bb.Len())
+	c.bb.WriteByte(OpEndCondition)
 	return nil
 }
 
-func (c *compiler) conditionalNode(bb *bytes.Buffer, node *ast.ConditionalNode) error {
-	err := c.compile(bb, node.Condition)
-	if err != nil {
-		return err
+func (c *compiler) assignmentNode(node *ast.AssignmentNode) error {
+	if node.Block == nil {
+		return errors.Errorf("no block after declaration of variable '%s'", node.Name)
 	}
-	bb.WriteByte(OpJumpIfFalse)
-	otherwise := bb.Len()
-	bb.Write([]byte{0xff, 0xff})
-
-	// Truthy branch
-	bb.WriteByte(OpPop) // Remove condition result from stack
-
-	err = c.compile(bb, node.TrueExpression)
+	id, err := c.name(node.Name)
 	if err != nil {
 		return err
 	}
-
-	bb.WriteByte(OpJump)
-	end := bb.Len()
-	bb.Write([]byte{0xff, 0xff})
-
-	// Patch jump to alternative branch
-	code := bb.Bytes()
-	binary.BigEndian.PutUint16(code[otherwise:], uint16(bb.Len()))
-
-	// Alternative branch
-	bb.WriteByte(OpPop) // Remove condition result from stack
-	err = c.compile(bb, node.FalseExpression)
-	if err != nil {
+	c.bb.WriteByte(OpLet)
+	c.bb.Write(encode(id))
+	c.postpone(node.Expression, c.placeholder())
+	if err := c.compile(node.Block); err != nil {
 		return err
 	}
-
-	// Patch jump to the end of alternative branch
-	code = bb.Bytes()
-	binary.BigEndian.PutUint16(code[end:], uint16(bb.Len()))
-
+	c.bb.WriteByte(OpEndLet)
 	return nil
 }
 
-func (c *compiler) assignmentNode(bb *bytes.Buffer, node *ast.AssignmentNode) error {
-	err := c.pushGlobalValue(node.Name, node.Expression, c.callable)
+func (c *compiler) functionDeclarationNode(node *ast.FunctionDeclarationNode) error {
+	if node.Block == nil {
+		return errors.Errorf("no block after declaration of function '%s'", node.Name)
+	}
+	fid, err := c.function(node)
 	if err != nil {
 		return err
 	}
-	if node.Block != nil {
-		err = c.compile(bb, node.Block)
-		if err != nil {
-			return err
-		}
-		err = c.popValue()
-		if err != nil {
-			return err
-		}
-	} else {
-		err = c.peekValue()
-		if err != nil {
-			return err
-		}
+	c.bb.WriteByte(OpFunction)
+	c.bb.Write(encode(fid))
+	if err := c.compile(node.Block); err != nil {
+		return err
 	}
+	c.bb.WriteByte(OpEndFunction)
 	return nil
 }
 
-func (c *compiler) referenceNode(bb *bytes.Buffer, node *ast.ReferenceNode) error {
-	if id, ok := c.checkConstant(node.Name); ok {
-		//Globally declared constant
-		bb.WriteByte(OpGlobal)
-		bb.Write(encode(id))
-		return nil
-	} else {
-		v, err := c.lookupValue(node.Name)
-		if err != nil {
-			return err
-		}
-		switch value := v.(type) {
-		case *localValue:
-			bb.WriteByte(OpLoadLocal)
-			bb.Write(encode(value.position))
-			return nil
-		case *globalValue:
-			bb.WriteByte(OpLoad)
-			value.refer(c.patcher.add(bb))
-			bb.Write([]byte{0xff, 0xff})
-			return nil
-		default:
-			return errors.Errorf("unexpected value type '%T'", value)
-		}
+// function registers user function, the body of the function is compiled later.
+func (c *compiler) function(node *ast.FunctionDeclarationNode) (uint16, error) {
+	if len(c.functions) >= math.MaxUint16 {
+		return 0, errors.New("max number of functions reached")
+	}
+	name, err := c.name(node.Name)
+	if err != nil {
+		return 0, err
+	}
+	args, err := c.arguments(node.Arguments)
+	if err != nil {
+		return 0, err
 	}
+	fid := len(c.functions)
+	c.functions = append(c.functions, userFunction{name: name, arguments: args})
+	c.postpone(node.Body, func(pos int) { c.functions[fid].body = pos })
+	return uint16(fid), nil
 }
 
-func (c *compiler) functionDeclarationNode(bb *bytes.Buffer, node *ast.FunctionDeclarationNode) error {
-	var args []string
-	if c.callable != nil { // DApp callable function
-		args = make([]string, len(node.Arguments)+1)
-		args[0] = c.callable.parameter
-		copy(args[1:], node.Arguments)
-	} else {
-		args = node.Arguments
+func (c *compiler) callNode(node *ast.FunctionCallNode) error {
+	if len(node.Arguments) > math.MaxUint16 {
+		return errors.New("max number of arguments reached")
 	}
-	err := c.pushFunction(node.Name, args, node.Body, c.callable)
+	name := node.Function.Name()
+	nid, err := c.native(name)
 	if err != nil {
 		return err
 	}
-	if node.Block != nil {
-		err = c.compile(bb, node.Block)
-		if err != nil {
-			return err
-		}
-		err = c.popFunction()
+	user := false
+	switch node.Function.(type) {
+	case ast.NativeFunction:
+	case ast.UserFunction:
+		_, user = c.declared[name]
+	default:
+		return errors.Errorf("unknown function type: %s", node.Function.Type())
+	}
+	var id uint16
+	if user {
+		id, err = c.name(name)
 		if err != nil {
 			return err
 		}
+		c.bb.WriteByte(OpPrepareCall)
+		c.bb.Write(encode(id))
+		c.bb.Write(encode(nid))
 	} else {
-		err = c.peekFunction()
-		if err != nil {
-			return err
-		}
+		c.bb.WriteByte(OpExternal)
+		c.bb.Write(encode(nid))
 	}
-	return nil
-}
-
-func (c *compiler) callNode(bb *bytes.Buffer, node *ast.FunctionCallNode) error {
 	for _, arg := range node.Arguments {
-		err := c.compile(bb, arg)
-		if err != nil {
+		if err := c.compile(arg); err != nil {
 			return err
 		}
 	}
 	cnt := encode(uint16(len(node.Arguments)))
-
-	if id, ok := c.checkFunction(node.Function.Name()); ok {
-		//External function
-		bb.WriteByte(OpExternalCall)
-		bb.Write(encode(id))
-		bb.Write(cnt)
+	if user {
+		c.bb.WriteByte(OpCall)
+		c.bb.Write(encode(id))
+		c.bb.Write(cnt)
+		c.bb.Write(encode(nid))
 	} else {
-		//Internal function
-		decl, err := c.lookupFunction(node.Function.Name())
-		if err != nil {
-			return err
-		}
-		bb.WriteByte(OpCall)
-		decl.call(c.patcher.add(bb))
-		bb.Write([]byte{0xff, 0xff})
-		bb.Write(cnt)
+		c.bb.WriteByte(OpExternalCall)
+		c.bb.Write(encode(nid))
+		c.bb.Write(cnt)
 	}
 	return nil
 }
 
-func (c *compiler) propertyNode(bb *bytes.Buffer, node *ast.PropertyNode) error {
-	err := c.compile(bb, node.Object)
-	if err != nil {
-		return err
-	}
+func (c *compiler) propertyNode(node *ast.PropertyNode) error {
 	id, err := c.constants.put(rideString(node.Name))
 	if err != nil {
 		return err
 	}
-	bb.WriteByte(OpProperty)
-	bb.Write(encode(id))
-	return nil
-}
-
-func (c *compiler) pushGlobalValue(name string, node ast.Node, annex *rideCallable) error {
-	d := newGlobalValue(name, annex)
-	c.callable = nil
-	c.values = append(c.values, d)
-	return c.compile(d.bb, node)
-}
-
-func (c *compiler) popValue() error {
-	l := len(c.values)
-	if l == 0 {
-		return errors.New("failed to pop value from empty stack")
-	}
-	var v rideValue
-	v, c.values = c.values[l-1], c.values[:l-1]
-	if d, ok := v.(rideDeclaration); ok {
-		c.declarations = append(c.declarations, d)
+	c.bb.WriteByte(OpProperty)
+	c.bb.Write(encode(id))
+	if err := c.compile(node.Object); err != nil {
+		return err
 	}
+	c.bb.WriteByte(OpGetProperty)
 	return nil
 }
 
-func (c *compiler) peekValue() error {
-	l := len(c.values)
-	if l == 0 {
-		return errors.New("failed to peek value from empty stack")
+func (c *compiler) name(name string) (uint16, error) {
+	if id, ok := c.nameIDs[name]; ok {
+		return id, nil
 	}
-	v := c.values[l-1]
-	if d, ok := v.(rideDeclaration); ok {
-		c.declarations = append(c.declarations, d)
+	if len(c.names) >= math.MaxUint16 {
+		return 0, errors.New("max number of names reached")
 	}
-	return nil
+	id := uint16(len(c.names))
+	c.names = append(c.names, name)
+	c.nameIDs[name] = id
+	return id, nil
 }
 
-func (c *compiler) lookupValue(name string) (rideValue, error) {
-	for i := len(c.values) - 1; i >= 0; i-- {
-		if c.values[i].id() == name {
-			return c.values[i], nil
+func (c *compiler) arguments(args []string) ([]uint16, error) {
+	r := make([]uint16, len(args))
+	for i, a := range args {
+		id, err := c.name(a)
+		if err != nil {
+			return nil, err
 		}
+		r[i] = id
 	}
-	return nil, errors.Errorf("value '%s' is not declared", name)
+	return r, nil
 }
 
-func (c *compiler) pushFunction(name string, args []string, node ast.Node, annex *rideCallable) error {
-	d := newLocalFunction(name, args, annex)
-	c.functions = append(c.functions, d)
-	for i, a := range args {
-		c.values = append(c.values, newLocalValue(a, i))
+// native resolves standard library function for both invocation modes and both versions of evaluator.
+// Missing functions and costs are not errors here, they produce the same errors as the tree evaluator at runtime.
+func (c *compiler) native(name string) (uint16, error) {
+	if id, ok := c.nativeIDs[name]; ok {
+		return id, nil
 	}
-	c.callable = nil
-	err := c.compile(d.bb, node)
-	if err != nil {
-		return err
+	if len(c.natives) >= math.MaxUint16 {
+		return 0, errors.New("max number of functions reached")
 	}
-	for range args {
-		err := c.popValue()
+	f := nativeFunction{name: name}
+	for i, invocation := range []bool{false, true} {
+		fs, err := selectFunctionsByName(c.version, invocation)
 		if err != nil {
-			return err
+			return 0, err
+		}
+		if fn, ok := fs(name); ok {
+			f.functions[i] = fn
 		}
 	}
-	return nil
-}
-
-func (c *compiler) popFunction() error {
-	l := len(c.functions)
-	if l == 0 {
-		return errors.New("failed to pop function from empty stack")
-	}
-	var f *localFunction
-	f, c.functions = c.functions[l-1], c.functions[:l-1]
-	c.declarations = append(c.declarations, f)
-	return nil
-}
-
-func (c *compiler) peekFunction() error {
-	l := len(c.functions)
-	if l == 0 {
-		return errors.New("failed to peek function from empty stack")
-	}
-	f := c.functions[l-1]
-	c.declarations = append(c.declarations, f)
-	return nil
-}
-
-func (c *compiler) lookupFunction(name string) (*localFunction, error) {
-	for i := len(c.functions) - 1; i >= 0; i-- {
-		if c.functions[i].name == name {
-			return c.functions[i], nil
+	for i := range f.costs {
+		costs, err := selectEvaluationCostsProvider(c.version, i+1)
+		if err != nil {
+			return 0, err
 		}
+		cost, ok := costs[name]
+		if !ok {
+			cost = -1
+		}
+		f.costs[i] = cost
 	}
-	return nil, errors.Errorf("function '%s' is not declared", name)
-}
-
-type rideCallable struct {
-	name      string
-	parameter string
+	id := uint16(len(c.natives))
+	c.natives = append(c.natives, f)
+	c.nativeIDs[name] = id
+	return id, nil
 }
 
 type rideConstants struct {
@@ -522,196 +517,8 @@ func (c *rideConstants) append(value rideType) (uint16, error) {
 	return uint16(len(c.items) - 1), nil
 }
 
-type rideDeclaration interface {
-	buffer() *bytes.Buffer
-	references() []int
-	callable() *rideCallable
-}
-
-type rideValue interface {
-	id() string
-}
-
-type localValue struct {
-	name     string
-	position uint16
-}
-
-func newLocalValue(name string, pos int) *localValue {
-	return &localValue{
-		name:     name,
-		position: uint16(pos),
-	}
-}
-
-func (v *localValue) id() string {
-	return v.name
-}
-
-type globalValue struct {
-	name   string
-	annex  *rideCallable
-	bb     *bytes.Buffer
-	usages []int
-	using  []*globalValue
-}
-
-func newGlobalValue(name string, annex *rideCallable) *globalValue {
-	return &globalValue{
-		name:   name,
-		annex:  annex,
-		bb:     new(bytes.Buffer),
-		usages: make([]int, 0),
-		using:  make([]*globalValue, 0),
-	}
-}
-
-func (v *globalValue) buffer() *bytes.Buffer {
-	return v.bb
-}
-
-func (v *globalValue) references() []int {
-	return v.usages
-}
-
-func (v *globalValue) id() string {
-	return v.name
-}
-
-func (v *globalValue) refer(patch int) {
-	v.usages = append(v.usages, patch)
-}
-
-func (v *globalValue) callable() *rideCallable {
-	return v.annex
-}
-
-type localFunction struct {
-	name  string
-	annex *rideCallable
-	bb    *bytes.Buffer
-	calls []int
-	args  []string
-}
-
-func newLocalFunction(name string, args []string, annex *rideCallable) *localFunction {
-	return &localFunction{
-		name:  name,
-		annex: annex,
-		bb:    new(bytes.Buffer),
-		calls: make([]int, 0),
-		args:  args,
-	}
-}
-
-func (f *localFunction) buffer() *bytes.Buffer {
-	return f.bb
-}
-
-func (f *localFunction) references() []int {
-	return f.calls
-}
-
-func (f *localFunction) call(pos int) {
-	f.calls = append(f.calls, pos)
-}
-
-func (f *localFunction) callable() *rideCallable {
-	return f.annex
-}
-
-type patch struct {
-	id     int
-	origin int
-	pos    int
-	addr   uint16
-}
-
-type patcher struct {
-	count   int
-	buffers map[*bytes.Buffer][]patch
-}
-
-func newPatcher() *patcher {
-	return &patcher{
-		count:   0,
-		buffers: make(map[*bytes.Buffer][]patch),
-	}
-}
-
-func (p *patcher) add(bb *bytes.Buffer) int {
-	pt := patch{
-		id:  p.count,
-		pos: bb.Len(),
-	}
-	if bps, ok := p.buffers[bb]; ok {
-		p.buffers[bb] = append(bps, pt)
-	} else {
-		p.buffers[bb] = []patch{pt}
-	}
-	p.count++
-	return pt.id
-}
-
-func (p *patcher) setOrigin(bb *bytes.Buffer, origin int) {
-	if ps, ok := p.buffers[bb]; ok {
-		for i := range ps {
-			ps[i].origin = origin
-		}
-	}
-}
-
-func (p *patcher) setPosition(id int, addr uint16) {
-	for _, v := range p.buffers {
-		for i := range v {
-			if v[i].id == id {
-				v[i].addr = addr
-			}
-		}
-	}
-}
-
-func (p *patcher) get() (map[int]uint16, error) {
-	r := make(map[int]uint16)
-	for _, v := range p.buffers {
-		for i := range v {
-			abs := v[i].origin + v[i].pos
-			if _, ok := r[abs]; ok {
-				return nil, errors.Errorf("duplicate patch at position %d", abs)
-			}
-			r[abs] = v[i].addr
-		}
-	}
-	return r, nil
-}
-
 func encode(v uint16) []byte {
 	b := make([]byte, 2)
 	binary.BigEndian.PutUint16(b, v)
 	return b
 }
-
-func patchedCode(bb *bytes.Buffer, origin int) []byte {
-	bts := bb.Bytes()
-	i := 0
-	for i < bb.Len() {
-		switch bts[i] {
-		case OpJumpIfFalse, OpJump:
-			addr := bts[i+1 : i+3]
-			update(addr, origin)
-			i += 3
-		case OpPush, OpProperty, OpLoad, OpLoadLocal, OpGlobal:
-			i += 3
-		case OpCall, OpExternalCall:
-			i += 5
-		default:
-			i++
-		}
-	}
-	return bts
-}
-
-func update(b []byte, n int) {
-	v := binary.BigEndian.Uint16(b)
-	binary.BigEndian.PutUint16(b, uint16(int(v)+n))
-}
@@ -285,3 +285,19 @@ func removeUnusedCode(tree *ast.Tree) {
 	}
 	tree.Declarations = newDecl
 }
+
+// OriginalNames restores the mapping of compacted names of the tree to the original ones.
+// The compacted names are reproduced in the same order as Compaction generates them.
+func OriginalNames(tree *ast.Tree) map[string]string {
+	names := tree.Meta.Abbreviations.Names()
+	r := make(map[string]string, len(names))
+	c := NewCompaction(tree)
+	for _, n := range names {
+		for c.hasConflict(idxToName(c.counter, "")) {
+			c.counter++
+		}
+		r[idxToName(c.counter, "")] = n
+		c.counter++
+	}
+	return r
+}
@@ -2,7 +2,9 @@ package ride
 
 import (
 	"encoding/base64"
-	"encoding/hex"
+	"encoding/binary"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,6 +16,85 @@ func c(values ...rideType) []rideType {
 	return values
 }
 
+// disassemble returns the human-readable listing of the program's code, instructions are separated by semicolons.
+// Names, user and native functions are printed by their names, constants by their IDs, code positions are
+// prefixed with '@'. Expected code is given as listings, so every expectation can be checked against the
+// instruction set in opcodes.go by reading, unlike the raw bytecode.
+func disassemble(t *testing.T, p *program) string {
+	u16 := func(pos int) uint16 { return binary.BigEndian.Uint16(p.Code[pos:]) }
+	u32 := func(pos int) uint32 { return binary.BigEndian.Uint32(p.Code[pos:]) }
+	var sb strings.Builder
+	for pos := 0; pos < len(p.Code); {
+		if pos > 0 {
+			sb.WriteString("; ")
+		}
+		op := p.Code[pos]
+		pos++
+		switch op {
+		case OpHalt:
+			sb.WriteString("HALT")
+		case OpReturn:
+			sb.WriteString("RET")
+		case OpPush:
+			fmt.Fprintf(&sb, "PUSH #%d", u16(pos))
+			pos += 2
+		case OpTrue:
+			sb.WriteString("TRUE")
+		case OpFalse:
+			sb.WriteString("FALSE")
+		case OpCondition:
+			sb.WriteString("COND")
+		case OpJumpIfFalse:
+			fmt.Fprintf(&sb, "JMPF @%d", u32(pos))
+			pos += 4
+		case OpJump:
+			fmt.Fprintf(&sb, "JMP @%d", u32(pos))
+			pos += 4
+		case OpEndCondition:
+			sb.WriteString("ENDCOND")
+		case OpLet:
+			fmt.Fprintf(&sb, "LET %s @%d", p.names[u16(pos)], u32(pos+2))
+			pos += 6
+		case OpEndLet:
+			sb.WriteString("ENDLET")
+		case OpFunction:
+			f := p.functions[u16(pos)]
+			fmt.Fprintf(&sb, "FUNC %s @%d", p.names[f.name], f.body)
+			pos += 2
+		case OpEndFunction:
+			sb.WriteString("ENDFUNC")
+		case OpLoad:
+			fmt.Fprintf(&sb, "LOAD %s", p.names[u16(pos)])
+			pos += 2
+		case OpProperty:
+			fmt.Fprintf(&sb, "PROP #%d", u16(pos))
+			pos += 2
+		case OpGetProperty:
+			sb.WriteString("GETPROP")
+		case OpExternal:
+			fmt.Fprintf(&sb, "EXT %s", p.natives[u16(pos)].name)
+			pos += 2
+		case OpExternalCall:
+			fmt.Fprintf(&sb, "EXTCALL %s/%d", p.natives[u16(pos)].name, u16(pos+2))
+			pos += 4
+		case OpPrepareCall:
+			fmt.Fprintf(&sb, "PREP %s", p.names[u16(pos)])
+			pos += 4
+		case OpCall:
+			fmt.Fprintf(&sb, "CALL %s/%d", p.names[u16(pos)], u16(pos+2))
+			pos += 6
+		default:
+			require.FailNow(t, "unknown operation code", "code %#x at position %d", op, pos-1)
+		}
+	}
+	return sb.String()
+}
+
+type testEntryPoint struct {
+	position  int
+	parameter string
+}
+
 func TestSimpleScriptsCompilation(t *testing.T) {
 	for _, test := range []struct {
 		comment   string
@@ -21,62 +102,62 @@ func TestSimpleScriptsCompilation(t *testing.T) {
 		code      string
 		constants []rideType
 	}{
-		{`V1: true`, "AQa3b8tH", "0400", nil},
-		{`V3: let x = 1; true`, "AwQAAAABeAAAAAAAAAAAAQbtAkXn", "040002000001", c(rideInt(1))},
-		{`V3: let x = "abc"; true`, "AwQAAAABeAIAAAADYWJjBrpUkE4=", "040002000001", c(rideString("abc"))},
+		{`V1: true`, "AQa3b8tH", "TRUE; HALT", nil},
+		{`V3: let x = 1; true`, "AwQAAAABeAAAAAAAAAAAAQbtAkXn", "LET x @10; TRUE; ENDLET; HALT; PUSH #0; RET", c(rideInt(1))},
+		{`V3: let x = "abc"; true`, "AwQAAAABeAIAAAADYWJjBrpUkE4=", "LET x @10; TRUE; ENDLET; HALT; PUSH #0; RET", c(rideString("abc"))},
 		{`V3: func A() = 1; func B() = 2; true`, "AwoBAAAAAUEAAAAAAAAAAAAAAAABCgEAAAABQgAAAAAAAAAAAAAAAAIG+N0aQQ==",
-			"04000200010102000001", c(rideInt(1), rideInt(2))},
+			"FUNC A @10; FUNC B @14; TRUE; ENDFUNC; ENDFUNC; HALT; PUSH #0; RET; PUSH #1; RET", c(rideInt(1), rideInt(2))},
 		{`V3: func A() = 1; func B() = 2; A() != B()`, "AwoBAAAAAUEAAAAAAAAAAAAAAAABCgEAAAABQgAAAAAAAAAAAAAAAAIJAQAAAAIhPQAAAAIJAQAAAAFBAAAAAAkBAAAAAUIAAAAAv/Pmkg==",
-			"0a001400000a001000000900010002000200010102000001", c(rideInt(1), rideInt(2))},
+			"FUNC A @41; FUNC B @45; EXT !=; PREP A; CALL A/0; PREP B; CALL B/0; EXTCALL !=/2; ENDFUNC; ENDFUNC; HALT; PUSH #0; RET; PUSH #1; RET", c(rideInt(1), rideInt(2))},
 		{`V1: let i = 1; let s = "string"; toString(i) == s`, "AQQAAAABaQAAAAAAAAAAAQQAAAABcwIAAAAGc3RyaW5nCQAAAAAAAAIJAAGkAAAAAQUAAAABaQUAAAABcwIsH74=",
-			"0c001509002700010c00110900030002000200010102000001", c(rideInt(1), rideString("string"))},
+			"LET i @39; LET s @43; EXT 0; EXT 420; LOAD i; EXTCALL 420/1; LOAD s; EXTCALL 0/2; ENDLET; ENDLET; HALT; PUSH #0; RET; PUSH #1; RET", c(rideInt(1), rideString("string"))},
 		{`V3: if true then if true then true else false else false`, "AwMGAwYGBwdYjCji",
-			"04070013030407000e03040600100305060015030500", nil},
+			"COND; TRUE; JMPF @27; COND; TRUE; JMPF @20; TRUE; JMP @21; FALSE; ENDCOND; JMP @28; FALSE; ENDCOND; HALT", nil},
 		{`V3: if (true) then {let r = true; r} else {let r = false; r}`, "AwMGBAAAAAFyBgUAAAABcgQAAAABcgcFAAAAAXJ/ok0E",
-			"0407000b030c001006000f030c00120004010501", nil},
+			"COND; TRUE; JMPF @23; LET r @36; LOAD r; ENDLET; JMP @34; LET r @38; LOAD r; ENDLET; ENDCOND; HALT; TRUE; RET; FALSE; RET", nil},
 		{`V3: if (let a = 1; a == 0) then {let a = 2; a == 0} else {let a = 0; a == 0}`, "AwMEAAAAAWEAAAAAAAAAAAEJAAAAAAAAAgUAAAABYQAAAAAAAAAAAAQAAAABYQAAAAAAAAAAAgkAAAAAAAACBQAAAAFhAAAAAAAAAAAABAAAAAFhAAAAAAAAAAAACQAAAAAAAAIFAAAAAWEAAAAAAAAAAAB3u9Yb",
-			"0c002a020001090003000207001d030c002e0200030900030002060029030c0032020005090003000200020000010200020102000401", c(rideInt(1), rideInt(0), rideInt(2), rideInt(0), rideInt(0), rideInt(0))},
+			"COND; LET a @79; EXT 0; LOAD a; PUSH #0; EXTCALL 0/2; ENDLET; JMPF @55; LET a @83; EXT 0; LOAD a; PUSH #1; EXTCALL 0/2; ENDLET; JMP @77; LET a @87; EXT 0; LOAD a; PUSH #2; EXTCALL 0/2; ENDLET; ENDCOND; HALT; PUSH #3; RET; PUSH #4; RET; PUSH #5; RET", c(rideInt(1), rideInt(0), rideInt(2), rideInt(0), rideInt(0), rideInt(0))},
 		{`let a = 1; let b = a; let c = b; a == c`,
 			"AwQAAAABYQAAAAAAAAAAAQQAAAABYgUAAAABYQQAAAABYwUAAAABYgkAAAAAAAACBQAAAAFhBQAAAAFjUFI1Og==",
-			"0c00140c000c0900030002000c0010010c00140102000001", c(rideInt(1))},
+			"LET a @39; LET b @43; LET c @47; EXT 0; LOAD a; LOAD c; EXTCALL 0/2; ENDLET; ENDLET; ENDLET; HALT; PUSH #0; RET; LOAD a; RET; LOAD b; RET", c(rideInt(1))},
 		{`let x = addressFromString("3PJaDyprvekvPXPuAtxrapacuDJopgJRaU3"); let a = x; let b = a; let c = b; let d = c; let e = d; let f = e; f == e`,
 			"AQQAAAABeAkBAAAAEWFkZHJlc3NGcm9tU3RyaW5nAAAAAQIAAAAjM1BKYUR5cHJ2ZWt2UFhQdUF0eHJhcGFjdURKb3BnSlJhVTMEAAAAAWEFAAAAAXgEAAAAAWIFAAAAAWEEAAAAAWMFAAAAAWIEAAAAAWQFAAAAAWMEAAAAAWUFAAAAAWQEAAAAAWYFAAAAAWUJAAAAAAAAAgUAAAABZgUAAAABZS5FHzs=",
-			"0c000c0c00100900030002000c0010010c0014010c0018010c001c010c0020010c002401020000090046000101", c(rideString("3PJaDyprvekvPXPuAtxrapacuDJopgJRaU3"))},
+			"LET x @71; LET a @83; LET b @87; LET c @91; LET d @95; LET e @99; LET f @103; EXT 0; LOAD f; LOAD e; EXTCALL 0/2; ENDLET; ENDLET; ENDLET; ENDLET; ENDLET; ENDLET; ENDLET; HALT; EXT addressFromString; PUSH #0; EXTCALL addressFromString/1; RET; LOAD x; RET; LOAD a; RET; LOAD b; RET; LOAD c; RET; LOAD d; RET; LOAD e; RET", c(rideString("3PJaDyprvekvPXPuAtxrapacuDJopgJRaU3"))},
 		{`V3: let x = { let y = 1; y == 0 }; let y = { let z = 2; z == 0 } x == y`,
 			"AwQAAAABeAQAAAABeQAAAAAAAAAAAQkAAAAAAAACBQAAAAF5AAAAAAAAAAAABAAAAAF5BAAAAAF6AAAAAAAAAAACCQAAAAAAAAIFAAAAAXoAAAAAAAAAAAAJAAAAAAAAAgUAAAABeAUAAAABedn8HVg=",
-			"0c00200c001409000300020002000001020002010c00100200030900030002010c000c020001090003000201", c(rideInt(1), rideInt(0), rideInt(2), rideInt(0))},
+			"LET x @31; LET y @54; EXT 0; LOAD x; LOAD y; EXTCALL 0/2; ENDLET; ENDLET; HALT; LET y @77; EXT 0; LOAD y; PUSH #0; EXTCALL 0/2; ENDLET; RET; LET z @81; EXT 0; LOAD z; PUSH #1; EXTCALL 0/2; ENDLET; RET; PUSH #2; RET; PUSH #3; RET", c(rideInt(1), rideInt(0), rideInt(2), rideInt(0))},
 		{`V3: let z = 0; let a = {let b = 1; b == z}; let b = {let c = 2; c == z}; a == b`,
 			"AwQAAAABegAAAAAAAAAAAAQAAAABYQQAAAABYgAAAAAAAAAAAQkAAAAAAAACBQAAAAFiBQAAAAF6BAAAAAFiBAAAAAFjAAAAAAAAAAACCQAAAAAAAAIFAAAAAWMFAAAAAXoJAAAAAAAAAgUAAAABYQUAAAABYnau3I8=",
-			"0c00200c001409000300020002000101020002010c00100c002c0900030002010c000c0c002c09000300020102000001", c(rideInt(0), rideInt(1), rideInt(2))},
+			"LET z @39; LET a @43; LET b @66; EXT 0; LOAD a; LOAD b; EXTCALL 0/2; ENDLET; ENDLET; ENDLET; HALT; PUSH #0; RET; LET b @89; EXT 0; LOAD b; LOAD z; EXTCALL 0/2; ENDLET; RET; LET c @93; EXT 0; LOAD c; LOAD z; EXTCALL 0/2; ENDLET; RET; PUSH #1; RET; PUSH #2; RET", c(rideInt(0), rideInt(1), rideInt(2))},
 		{`V3: func abs(i:Int) = if (i >= 0) then i else -i; abs(-10) == 10`, "AwoBAAAAA2FicwAAAAEAAAABaQMJAABnAAAAAgUAAAABaQAAAAAAAAAAAAUAAAABaQkBAAAAAS0AAAABBQAAAAFpCQAAAAAAAAIJAQAAAANhYnMAAAABAP/////////2AAAAAAAAAAAKmp8BWw==",
-			"0200010a001100010200020900030002000d000002000009000d0002070026030d000006002f030d0000090002000101", c(rideInt(0), rideInt(-10), rideInt(10))},
+			"FUNC abs @31; EXT 0; PREP abs; PUSH #0; CALL abs/1; PUSH #1; EXTCALL 0/2; ENDFUNC; HALT; COND; EXT 103; LOAD i; PUSH #2; EXTCALL 103/2; JMPF @59; LOAD i; JMP @70; EXT -; LOAD i; EXTCALL -/1; ENDCOND; RET", c(rideInt(0), rideInt(-10), rideInt(10))},
 		{`V3: if (true) then {if (false) then {func XX() = true; XX()} else {func XX() = false; XX()}} else {if (true) then {let x = false; x} else {let x = true; x}}`,
 			"AwMGAwcKAQAAAAJYWAAAAAAGCQEAAAACWFgAAAAACgEAAAACWFgAAAAABwkBAAAAAlhYAAAAAAMGBAAAAAF4BwUAAAABeAQAAAABeAYFAAAAAXgYYeMi",
-			"0407001b0305070012030a002c0000060018030a002e000006002b0304070027030c003006002b030c0032000401050105010401", nil},
+			"COND; TRUE; JMPF @57; COND; FALSE; JMPF @35; FUNC XX @94; PREP XX; CALL XX/0; ENDFUNC; JMP @51; FUNC XX @96; PREP XX; CALL XX/0; ENDFUNC; ENDCOND; JMP @92; COND; TRUE; JMPF @80; LET x @98; LOAD x; ENDLET; JMP @91; LET x @100; LOAD x; ENDLET; ENDCOND; ENDCOND; HALT; TRUE; RET; FALSE; RET; FALSE; RET; TRUE; RET", nil},
 		{`tx.sender == Address(base58'11111111111111111')`, "AwkAAAAAAAACCAUAAAACdHgAAAAGc2VuZGVyCQEAAAAHQWRkcmVzcwAAAAEBAAAAEQAAAAAAAAAAAAAAAAAAAAAAWc7d/w==",
-			"0b00180800000200010900510001090003000200", c(rideString("sender"), rideByteVector{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})},
+			"EXT 0; PROP #0; LOAD tx; GETPROP; EXT Address; PUSH #1; EXTCALL Address/1; EXTCALL 0/2; HALT", c(rideString("sender"), rideByteVector{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})},
 		{`func b(x: Int) = {func a(y: Int) = x + y; a(1) + a(2)}; b(2) + b(3) == 0`, "AwoBAAAAAWIAAAABAAAAAXgKAQAAAAFhAAAAAQAAAAF5CQAAZAAAAAIFAAAAAXgFAAAAAXkJAABkAAAAAgkBAAAAAWEAAAABAAAAAAAAAAABCQEAAAABYQAAAAEAAAAAAAAAAAIJAAAAAAAAAgkAAGQAAAACCQEAAAABYgAAAAEAAAAAAAAAAAIJAQAAAAFiAAAAAQAAAAAAAAAAAwAAAAAAAAAAAPsZlhQ=",
-			"0200020a002a00010200030a002a000109000500020200040900030002000d00000d00000900050002010200000a001e00010200010a001e0001090005000201", c(rideInt(1), rideInt(2), rideInt(2), rideInt(3), rideInt(0))},
+			"FUNC b @54; EXT 0; EXT 100; PREP b; PUSH #0; CALL b/1; PREP b; PUSH #1; CALL b/1; EXTCALL 100/2; PUSH #2; EXTCALL 0/2; ENDFUNC; HALT; FUNC a @97; EXT 100; PREP a; PUSH #3; CALL a/1; PREP a; PUSH #4; CALL a/1; EXTCALL 100/2; ENDFUNC; RET; EXT 100; LOAD x; LOAD y; EXTCALL 100/2; RET", c(rideInt(1), rideInt(2), rideInt(2), rideInt(3), rideInt(0))},
 		{`func first(a: Int, b: Int) = {let x = a + b; x}; first(1, 2) == 0`, "AwoBAAAABWZpcnN0AAAAAgAAAAFhAAAAAWIEAAAAAXgJAABkAAAAAgUAAAABYQUAAAABYgUAAAABeAkAAAAAAAACCQEAAAAFZmlyc3QAAAACAAAAAAAAAAABAAAAAAAAAAACAAAAAAAAAAAAm+QHtw==",
-			"0200000200010a002000020200020900030002000d00000d00010900050002010c001401", c(rideInt(1), rideInt(2), rideInt(0))},
+			"FUNC first @34; EXT 0; PREP first; PUSH #0; PUSH #1; CALL first/2; PUSH #2; EXTCALL 0/2; ENDFUNC; HALT; LET x @46; LOAD x; ENDLET; RET; EXT 100; LOAD a; LOAD b; EXTCALL 100/2; RET", c(rideInt(1), rideInt(2), rideInt(0))},
 		{`func A(x: Int, y: Int) = {let r = x + y; r}; func B(x: Int, y: Int) = {let r = A(x, y); r}; B(1, 2) == 3`, "AwoBAAAAAUEAAAACAAAAAXgAAAABeQQAAAABcgkAAGQAAAACBQAAAAF4BQAAAAF5BQAAAAFyCgEAAAABQgAAAAIAAAABeAAAAAF5BAAAAAFyCQEAAAABQQAAAAIFAAAAAXgFAAAAAXkFAAAAAXIJAAAAAAAAAgkBAAAAAUIAAAACAAAAAAAAAAABAAAAAAAAAAACAAAAAAAAAAADSAdb8g==",
-			"0200000200010a002c00020200020900030002000d00000d00010900050002010d00000d00010a00300002010c0020010c001401", c(rideInt(1), rideInt(2), rideInt(3))},
+			"FUNC A @38; FUNC B @50; EXT 0; PREP B; PUSH #0; PUSH #1; CALL B/2; PUSH #2; EXTCALL 0/2; ENDFUNC; ENDFUNC; HALT; LET r @62; LOAD r; ENDLET; RET; LET r @77; LOAD r; ENDLET; RET; EXT 100; LOAD x; LOAD y; EXTCALL 100/2; RET; PREP A; LOAD x; LOAD y; CALL A/2; RET", c(rideInt(1), rideInt(2), rideInt(3))},
 		{`func f1(a: Int, b: Int) = a + b; func f2(a: Int, b: Int) = a - b; f2(f1(1, 2), 3) == 0`, "AwoBAAAAAmYxAAAAAgAAAAFhAAAAAWIJAABkAAAAAgUAAAABYQUAAAABYgoBAAAAAmYyAAAAAgAAAAFhAAAAAWIJAABlAAAAAgUAAAABYQUAAAABYgkAAAAAAAACCQEAAAACZjIAAAACCQEAAAACZjEAAAACAAAAAAAAAAABAAAAAAAAAAACAAAAAAAAAAADAAAAAAAAAAAALZ/RdA==",
-			"0200000200010a002800020200020a001c00020200030900030002000d00000d000109000b0002010d00000d0001090005000201", c(rideInt(1), rideInt(2), rideInt(3), rideInt(0))},
+			"FUNC f1 @53; FUNC f2 @68; EXT 0; PREP f2; PREP f1; PUSH #0; PUSH #1; CALL f1/2; PUSH #2; CALL f2/2; PUSH #3; EXTCALL 0/2; ENDFUNC; ENDFUNC; HALT; EXT 100; LOAD a; LOAD b; EXTCALL 100/2; RET; EXT 101; LOAD a; LOAD b; EXTCALL 101/2; RET", c(rideInt(1), rideInt(2), rideInt(3), rideInt(0))},
 		{`func f1(a: Int, b: Int) = a + b; func f2(a: Int, b: Int) = a - b; let x = f1(1, 2); f2(x, 3) == 0`, "AwoBAAAAAmYxAAAAAgAAAAFhAAAAAWIJAABkAAAAAgUAAAABYQUAAAABYgoBAAAAAmYyAAAAAgAAAAFhAAAAAWIJAABlAAAAAgUAAAABYQUAAAABYgQAAAABeAkBAAAAAmYxAAAAAgAAAAAAAAAAAQAAAAAAAAAAAgkAAAAAAAACCQEAAAACZjIAAAACBQAAAAF4AAAAAAAAAAADAAAAAAAAAAAAr1ooAg==",
-			"0c00140200020a002000020200030900030002000200000200010a002c0002010d00000d000109000b0002010d00000d0001090005000201", c(rideInt(1), rideInt(2), rideInt(3), rideInt(0))},
+			"FUNC f1 @46; FUNC f2 @61; LET x @76; EXT 0; PREP f2; LOAD x; PUSH #0; CALL f2/2; PUSH #1; EXTCALL 0/2; ENDLET; ENDFUNC; ENDFUNC; HALT; EXT 100; LOAD a; LOAD b; EXTCALL 100/2; RET; EXT 101; LOAD a; LOAD b; EXTCALL 101/2; RET; PREP f1; PUSH #2; PUSH #3; CALL f1/2; RET", c(rideInt(1), rideInt(2), rideInt(3), rideInt(0))},
 		{`func f1(a: Int, b: Int) = a + b; func f2(a: Int, b: Int) = b; f2(f1(1, 2), 3) == 3`, "AwoBAAAAAmYxAAAAAgAAAAFhAAAAAWIJAABkAAAAAgUAAAABYQUAAAABYgoBAAAAAmYyAAAAAgAAAAFhAAAAAWIFAAAAAWIJAAAAAAAAAgkBAAAAAmYyAAAAAgkBAAAAAmYxAAAAAgAAAAAAAAAAAQAAAAAAAAAAAgAAAAAAAAAAAwAAAAAAAAAAA1cKYN4=",
-			"0200000200010a002000020200020a001c00020200030900030002000d0001010d00000d0001090005000201", c(rideInt(1), rideInt(2), rideInt(3), rideInt(3))},
+			"FUNC f1 @53; FUNC f2 @68; EXT 0; PREP f2; PREP f1; PUSH #0; PUSH #1; CALL f1/2; PUSH #2; CALL f2/2; PUSH #3; EXTCALL 0/2; ENDFUNC; ENDFUNC; HALT; EXT 100; LOAD a; LOAD b; EXTCALL 100/2; RET; LOAD b; RET", c(rideInt(1), rideInt(2), rideInt(3), rideInt(3))},
 		{`func f1(a: Int, b: Int) = a + b; func f2(a: Int, b: Int) = b; let x = f1(1, 2); f2(x, 3) == 3`, "AwoBAAAAAmYxAAAAAgAAAAFhAAAAAWIJAABkAAAAAgUAAAABYQUAAAABYgoBAAAAAmYyAAAAAgAAAAFhAAAAAWIFAAAAAWIEAAAAAXgJAQAAAAJmMQAAAAIAAAAAAAAAAAEAAAAAAAAAAAIJAAAAAAAAAgkBAAAAAmYyAAAAAgUAAAABeAAAAAAAAAAAAwAAAAAAAAAAA6avbPE=",
-			"0c00140200020a002000020200030900030002000200000200010a00240002010d0001010d00000d0001090005000201", c(rideInt(1), rideInt(2), rideInt(3), rideInt(3))},
+			"FUNC f1 @46; FUNC f2 @61; LET x @65; EXT 0; PREP f2; LOAD x; PUSH #0; CALL f2/2; PUSH #1; EXTCALL 0/2; ENDLET; ENDFUNC; ENDFUNC; HALT; EXT 100; LOAD a; LOAD b; EXTCALL 100/2; RET; LOAD b; RET; PREP f1; PUSH #2; PUSH #3; CALL f1/2; RET", c(rideInt(1), rideInt(2), rideInt(3), rideInt(3))},
 		{`let x = 1; func add(i: Int) = i + 1; add(x) == 2`, "AwQAAAABeAAAAAAAAAAAAQoBAAAAA2FkZAAAAAEAAAABaQkAAGQAAAACBQAAAAFpAAAAAAAAAAABCQAAAAAAAAIJAQAAAANhZGQAAAABBQAAAAF4AAAAAAAAAAACfr6U6w==",
-			"0c001d0a001100010200020900030002000d000002000109000500020102000001", c(rideInt(1), rideInt(1), rideInt(2))},
+			"LET x @39; FUNC add @43; EXT 0; PREP add; LOAD x; CALL add/1; PUSH #0; EXTCALL 0/2; ENDFUNC; ENDLET; HALT; PUSH #1; RET; EXT 100; LOAD i; PUSH #2; EXTCALL 100/2; RET", c(rideInt(1), rideInt(1), rideInt(2))},
 		{`let b = base16'0000000000000001'; func add(b: ByteVector) = toInt(b) + 1; add(b) == 2`, "AwQAAAABYgEAAAAIAAAAAAAAAAEKAQAAAANhZGQAAAABAAAAAWIJAABkAAAAAgkABLEAAAABBQAAAAFiAAAAAAAAAAABCQAAAAAAAAIJAQAAAANhZGQAAAABBQAAAAFiAAAAAAAAAAACX00biA==",
-			"0c00220a001100010200020900030002000d0000090020000102000109000500020102000001", c(rideByteVector{0, 0, 0, 0, 0, 0, 0, 1}, rideInt(1), rideInt(2))},
+			"LET b @39; FUNC add @43; EXT 0; PREP add; LOAD b; CALL add/1; PUSH #0; EXTCALL 0/2; ENDFUNC; ENDLET; HALT; PUSH #1; RET; EXT 100; EXT 1201; LOAD b; EXTCALL 1201/1; PUSH #2; EXTCALL 100/2; RET", c(rideByteVector{0, 0, 0, 0, 0, 0, 0, 1}, rideInt(1), rideInt(2))},
 		{`let b = base16'0000000000000001'; func add(v: ByteVector) = toInt(v) + 1; add(b) == 2`, "AwQAAAABYgEAAAAIAAAAAAAAAAEKAQAAAANhZGQAAAABAAAAAXYJAABkAAAAAgkABLEAAAABBQAAAAF2AAAAAAAAAAABCQAAAAAAAAIJAQAAAANhZGQAAAABBQAAAAFiAAAAAAAAAAACI7gYxg==",
-			"0c00220a001100010200020900030002000d0000090020000102000109000500020102000001", c(rideByteVector{0, 0, 0, 0, 0, 0, 0, 1}, rideInt(1), rideInt(2))},
+			"LET b @39; FUNC add @43; EXT 0; PREP add; LOAD b; CALL add/1; PUSH #0; EXTCALL 0/2; ENDFUNC; ENDLET; HALT; PUSH #1; RET; EXT 100; EXT 1201; LOAD v; EXTCALL 1201/1; PUSH #2; EXTCALL 100/2; RET", c(rideByteVector{0, 0, 0, 0, 0, 0, 0, 1}, rideInt(1), rideInt(2))},
 		{`let b = base16'0000000000000001'; func add(v: ByteVector) = toInt(b) + 1; add(b) == 2`, "AwQAAAABYgEAAAAIAAAAAAAAAAEKAQAAAANhZGQAAAABAAAAAXYJAABkAAAAAgkABLEAAAABBQAAAAFiAAAAAAAAAAABCQAAAAAAAAIJAQAAAANhZGQAAAABBQAAAAFiAAAAAAAAAAAChRvwnQ==",
-			"0c00220a001100010200020900030002000c0022090020000102000109000500020102000001", c(rideByteVector{0, 0, 0, 0, 0, 0, 0, 1}, rideInt(1), rideInt(2))},
+			"LET b @39; FUNC add @43; EXT 0; PREP add; LOAD b; CALL add/1; PUSH #0; EXTCALL 0/2; ENDFUNC; ENDLET; HALT; PUSH #1; RET; EXT 100; EXT 1201; LOAD b; EXTCALL 1201/1; PUSH #2; EXTCALL 100/2; RET", c(rideByteVector{0, 0, 0, 0, 0, 0, 0, 1}, rideInt(1), rideInt(2))},
 	} {
 		src, err := base64.StdEncoding.DecodeString(test.source)
 		require.NoError(t, err, test.comment)
@@ -91,8 +172,7 @@ func TestSimpleScriptsCompilation(t *testing.T) {
 		script, ok := rideScript.(*SimpleScript)
 		require.True(t, ok, test.comment)
 
-		code := hex.EncodeToString(script.Code)
-		assert.Equal(t, test.code, code, test.comment)
+		assert.Equal(t, test.code, disassemble(t, &script.program), test.comment)
 		assert.ElementsMatch(t, test.constants, script.Constants, test.comment)
 	}
 }
@@ -103,41 +183,41 @@ func TestDAppScriptsCompilation(t *testing.T) {
 		source    string
 		code      string
 		constants []rideType
-		entries   map[string]callable
+		entries   map[string]testEntryPoint
 	}{
 		{`@Verifier(tx) func verify() = false`, "AAIDAAAAAAAAAAIIAQAAAAAAAAAAAAAAAQAAAAJ0eAEAAAAGdmVyaWZ5AAAAAAcysh6J",
-			"0500", nil, map[string]callable{"": {0, "tx"}}},
+			"FALSE; HALT", nil, map[string]testEntryPoint{"": {0, "tx"}}},
 		{`let a = 1\n@Verifier(tx) func verify() = false`, "AAIDAAAAAAAAAAIIAQAAAAEAAAAAAWEAAAAAAAAAAAEAAAAAAAAAAQAAAAJ0eAEAAAAGdmVyaWZ5AAAAAAdVrdkQ",
-			"020000010500", c(rideInt(1)), map[string]callable{"": {4, "tx"}}},
+			"FALSE; HALT; PUSH #0; RET", c(rideInt(1)), map[string]testEntryPoint{"": {0, "tx"}}},
 		{`let a = 1\nfunc inc(v: Int) = {v + 1}\n@Verifier(tx) func verify() = false`, "AAIDAAAAAAAAAAIIAQAAAAIAAAAAAWEAAAAAAAAAAAEBAAAAA2luYwAAAAEAAAABdgkAAGQAAAACBQAAAAF2AAAAAAAAAAABAAAAAAAAAAEAAAACdHgBAAAABnZlcmlmeQAAAAAHDMc8rg==",
-			"020000010d00000200010900050002010500", c(rideInt(1), rideInt(1)), map[string]callable{"": {16, "tx"}}},
+			"FALSE; HALT; PUSH #0; RET; EXT 100; LOAD v; PUSH #1; EXTCALL 100/2; RET", c(rideInt(1), rideInt(1)), map[string]testEntryPoint{"": {0, "tx"}}},
 		{`let a = 1\nfunc inc(v: Int) = {v + 1}\n@Verifier(tx) func verify() = inc(a) == 2`, "AAIDAAAAAAAAAAIIAQAAAAIAAAAAAWEAAAAAAAAAAAEBAAAAA2luYwAAAAEAAAABdgkAAGQAAAACBQAAAAF2AAAAAAAAAAABAAAAAAAAAAEAAAACdHgBAAAABnZlcmlmeQAAAAAJAAAAAAAAAgkBAAAAA2luYwAAAAEFAAAAAWEAAAAAAAAAAAJtD5WX",
-			"020000010d00000200010900050002010c00000a00040001020002090003000200", c(rideInt(1), rideInt(1), rideInt(2)),
-			map[string]callable{"": {16, "tx"}}},
+			"EXT 0; PREP inc; LOAD a; CALL inc/1; PUSH #0; EXTCALL 0/2; HALT; PUSH #1; RET; EXT 100; LOAD v; PUSH #2; EXTCALL 100/2; RET", c(rideInt(1), rideInt(1), rideInt(2)),
+			map[string]testEntryPoint{"": {0, "tx"}}},
 		{`let a = 1\nlet b = 1\nfunc inc(v: Int) = {v + 1}\nfunc add(x: Int, y: Int) = {x + y}\n@Verifier(tx) func verify() = inc(a) == add(a, b)`, "AAIDAAAAAAAAAAIIAQAAAAQAAAAAAWEAAAAAAAAAAAEAAAAAAWIAAAAAAAAAAAEBAAAAA2luYwAAAAEAAAABdgkAAGQAAAACBQAAAAF2AAAAAAAAAAABAQAAAANhZGQAAAACAAAAAXgAAAABeQkAAGQAAAACBQAAAAF4BQAAAAF5AAAAAAAAAAEAAAACdHgBAAAABnZlcmlmeQAAAAAJAAAAAAAAAgkBAAAAA2luYwAAAAEFAAAAAWEJAQAAAANhZGQAAAACBQAAAAFhBQAAAAFiDbIkmw==",
-			"02000001020001010d00000200020900050002010d00000d00010900050002010c00000a000800010c00000c00040a00140002090003000200",
+			"EXT 0; PREP inc; LOAD a; CALL inc/1; PREP add; LOAD a; LOAD b; CALL add/2; EXTCALL 0/2; HALT; PUSH #0; RET; PUSH #1; RET; EXT 100; LOAD v; PUSH #2; EXTCALL 100/2; RET; EXT 100; LOAD x; LOAD y; EXTCALL 100/2; RET",
 			c(rideInt(1), rideInt(1), rideInt(1)),
-			map[string]callable{"": {32, "tx"}}},
+			map[string]testEntryPoint{"": {0, "tx"}}},
 		{`let a = 1\nlet b = 1\nlet messages = ["INFO", "WARN"]\nfunc inc(v: Int) = {v + 1}\nfunc add(x: Int, y: Int) = {x + y}\nfunc msg(i: Int) = {messages[i]}\n@Verifier(tx) func verify() = if inc(a) == add(a, b) then throw(msg(a)) else throw(msg(b))`, "AAIDAAAAAAAAAAIIAQAAAAYAAAAAAWEAAAAAAAAAAAEAAAAAAWIAAAAAAAAAAAEAAAAACG1lc3NhZ2VzCQAETAAAAAICAAAABElORk8JAARMAAAAAgIAAAAEV0FSTgUAAAADbmlsAQAAAANpbmMAAAABAAAAAXYJAABkAAAAAgUAAAABdgAAAAAAAAAAAQEAAAADYWRkAAAAAgAAAAF4AAAAAXkJAABkAAAAAgUAAAABeAUAAAABeQEAAAADbXNnAAAAAQAAAAFpCQABkQAAAAIFAAAACG1lc3NhZ2VzBQAAAAFpAAAAAAAAAAEAAAACdHgBAAAABnZlcmlmeQAAAAADCQAAAAAAAAIJAQAAAANpbmMAAAABBQAAAAFhCQEAAAADYWRkAAAAAgUAAAABYQUAAAABYgkAAAIAAAABCQEAAAADbXNnAAAAAQUAAAABYQkAAAIAAAABCQEAAAADbXNnAAAAAQUAAAABYvi7IpM=",
-			"02000001020001010200020200030b001609001e000209001e0002010d00000200040900050002010d00000d00010900050002010c00080d00000900320002010c00000a001c00010c00000c00040a00280002090003000207006c030c00000a00340001090028000106007a030c00040a00340001090028000100",
+			"COND; EXT 0; PREP inc; LOAD a; CALL inc/1; PREP add; LOAD a; LOAD b; CALL add/2; EXTCALL 0/2; JMPF @75; EXT 2; PREP msg; LOAD a; CALL msg/1; EXTCALL 2/1; JMP @98; EXT 2; PREP msg; LOAD b; CALL msg/1; EXTCALL 2/1; ENDCOND; HALT; PUSH #0; RET; PUSH #1; RET; EXT 1100; PUSH #2; EXT 1100; PUSH #3; LOAD nil; EXTCALL 1100/2; EXTCALL 1100/2; RET; EXT 100; LOAD v; PUSH #4; EXTCALL 100/2; RET; EXT 100; LOAD x; LOAD y; EXTCALL 100/2; RET; EXT 401; LOAD messages; LOAD i; EXTCALL 401/2; RET",
 			c(rideInt(1), rideInt(1), rideString("INFO"), rideString("WARN"), rideInt(1)),
-			map[string]callable{"": {64, "tx"}}},
+			map[string]testEntryPoint{"": {0, "tx"}}},
 		{`@Callable(i)func f() = {WriteSet([DataEntry("YYY", "XXX")]}`, "AAIDAAAAAAAAAAQIARIAAAAAAAAAAAEAAAABaQEAAAABZgAAAAAJAQAAAAhXcml0ZVNldAAAAAEJAARMAAAAAgkBAAAACURhdGFFbnRyeQAAAAICAAAAA1lZWQIAAAADWFhYBQAAAANuaWwAAAAAeFguLA==",
-			"02000002000109005b00020b001609001e0002090082000100", c(rideString("YYY"), rideString("XXX")),
-			map[string]callable{"f": {0, "i"}}},
+			"EXT WriteSet; EXT 1100; EXT DataEntry; PUSH #0; PUSH #1; EXTCALL DataEntry/2; LOAD nil; EXTCALL 1100/2; EXTCALL WriteSet/1; HALT", c(rideString("YYY"), rideString("XXX")),
+			map[string]testEntryPoint{"f": {0, "i"}}},
 		{`@Callable(i)func f() = {let callerAddress = toBase58String(i.caller.bytes); WriteSet([DataEntry(callerAddress, "XXX")]}`, "AAIDAAAAAAAAAAQIARIAAAAAAAAAAAEAAAABaQEAAAABZgAAAAAEAAAADWNhbGxlckFkZHJlc3MJAAJYAAAAAQgIBQAAAAFpAAAABmNhbGxlcgAAAAVieXRlcwkBAAAACFdyaXRlU2V0AAAAAQkABEwAAAACCQEAAAAJRGF0YUVudHJ5AAAAAgUAAAANY2FsbGVyQWRkcmVzcwIAAAADWFhYBQAAAANuaWwAAAAAe3xtyw==",
-			"0d000008000008000109003d0001010c000002000209005b00020b001609001e0002090082000100",
+			"LET callerAddress @42; EXT WriteSet; EXT 1100; EXT DataEntry; LOAD callerAddress; PUSH #0; EXTCALL DataEntry/2; LOAD nil; EXTCALL 1100/2; EXTCALL WriteSet/1; ENDLET; HALT; EXT 600; PROP #1; PROP #2; LOAD i; GETPROP; GETPROP; EXTCALL 600/1; RET",
 			c(rideString("caller"), rideString("bytes"), rideString("XXX")),
-			map[string]callable{"f": {15, "i"}}},
+			map[string]testEntryPoint{"f": {0, "i"}}},
 		{`let messages = ["INFO", "WARN"]\nfunc msg(i: Int) = {messages[i]}\n@Callable(i)func tellme(x: Int) = {WriteSet([DataEntry("m", msg(x))]}`, "AAIDAAAAAAAAAAcIARIDCgEBAAAAAgAAAAAIbWVzc2FnZXMJAARMAAAAAgIAAAAESU5GTwkABEwAAAACAgAAAARXQVJOBQAAAANuaWwBAAAAA21zZwAAAAEAAAABaQkAAZEAAAACBQAAAAhtZXNzYWdlcwUAAAABaQAAAAEAAAABaQEAAAAGdGVsbG1lAAAAAQAAAAF4CQEAAAAIV3JpdGVTZXQAAAABCQAETAAAAAIJAQAAAAlEYXRhRW50cnkAAAACAgAAAAFtCQEAAAADbXNnAAAAAQUAAAABeAUAAAADbmlsAAAAAO4TltI=",
-			"0200000200010b001609001e000209001e0002010c00000d00000900320002010200020d00010a0014000109005b00020b001609001e0002090082000100", c(rideString("INFO"), rideString("WARN"), rideString("m")),
-			map[string]callable{"tellme": {32, "i"}}},
+			"EXT WriteSet; EXT 1100; EXT DataEntry; PUSH #0; PREP msg; LOAD x; CALL msg/1; EXTCALL DataEntry/2; LOAD nil; EXTCALL 1100/2; EXTCALL WriteSet/1; HALT; EXT 1100; PUSH #1; EXT 1100; PUSH #2; LOAD nil; EXTCALL 1100/2; EXTCALL 1100/2; RET; EXT 401; LOAD messages; LOAD i; EXTCALL 401/2; RET", c(rideString("INFO"), rideString("WARN"), rideString("m")),
+			map[string]testEntryPoint{"tellme": {0, "i"}}},
 		{`let messages = ["INFO", "WARN"]\nfunc msg(i: Int) = {messages[i]}\n@Callable(i)func tellme(x: Int, y: Int) = {WriteSet([DataEntry("m", msg(x))]}`, "AAIDAAAAAAAAAAgIARIECgIBAQAAAAIAAAAACG1lc3NhZ2VzCQAETAAAAAICAAAABElORk8JAARMAAAAAgIAAAAEV0FSTgUAAAADbmlsAQAAAANtc2cAAAABAAAAAWkJAAGRAAAAAgUAAAAIbWVzc2FnZXMFAAAAAWkAAAABAAAAAWkBAAAABnRlbGxtZQAAAAIAAAABeAAAAAF5CQEAAAAIV3JpdGVTZXQAAAABCQAETAAAAAIJAQAAAAlEYXRhRW50cnkAAAACAgAAAAFtCQEAAAADbXNnAAAAAQUAAAABeAUAAAADbmlsAAAAAD8Tlfs=",
-			"0200000200010b001609001e000209001e0002010c00000d00000900320002010200020d00010a0014000109005b00020b001609001e0002090082000100", c(rideString("INFO"), rideString("WARN"), rideString("m")),
-			map[string]callable{"tellme": {32, "i"}}},
+			"EXT WriteSet; EXT 1100; EXT DataEntry; PUSH #0; PREP msg; LOAD x; CALL msg/1; EXTCALL DataEntry/2; LOAD nil; EXTCALL 1100/2; EXTCALL WriteSet/1; HALT; EXT 1100; PUSH #1; EXT 1100; PUSH #2; LOAD nil; EXTCALL 1100/2; EXTCALL 1100/2; RET; EXT 401; LOAD messages; LOAD i; EXTCALL 401/2; RET", c(rideString("INFO"), rideString("WARN"), rideString("m")),
+			map[string]testEntryPoint{"tellme": {0, "i"}}},
 		{`let a = 1; let messages = ["INFO", "WARN"]; func msg(i: Int) = {messages[i]}; @Callable(i)func tellme(x: Int) = {let m = msg(x); let callerAddress = toBase58String(i.caller.bytes); WriteSet([DataEntry(callerAddress + "-m", m)]}`, "AAIDAAAAAAAAAAcIARIDCgEBAAAAAwAAAAABYQAAAAAAAAAAAQAAAAAIbWVzc2FnZXMJAARMAAAAAgIAAAAESU5GTwkABEwAAAACAgAAAARXQVJOBQAAAANuaWwBAAAAA21zZwAAAAEAAAABaQkAAZEAAAACBQAAAAhtZXNzYWdlcwUAAAABaQAAAAEAAAABaQEAAAAGdGVsbG1lAAAAAQAAAAF4BAAAAAFtCQEAAAADbXNnAAAAAQUAAAABeAQAAAANY2FsbGVyQWRkcmVzcwkAAlgAAAABCAgFAAAAAWkAAAAGY2FsbGVyAAAABWJ5dGVzCQEAAAAIV3JpdGVTZXQAAAABCQAETAAAAAIJAQAAAAlEYXRhRW50cnkAAAACCQABLAAAAAIFAAAADWNhbGxlckFkZHJlc3MCAAAAAi1tBQAAAAFtBQAAAANuaWwAAAAAgveN3A==",
-			"020000010200010200020b001609001e000209001e0002010c00040d00000900320002010d000008000308000409003d0001010d00010a00180001010c002402000509002d00020c003309005b00020b001609001e0002090082000100", c(rideInt(1), rideString("INFO"), rideString("WARN"), rideString("caller"), rideString("bytes"), rideString("-m")),
-			map[string]callable{"tellme": {60, "i"}}},
+			"LET m @106; LET callerAddress @122; EXT WriteSet; EXT 1100; EXT DataEntry; EXT 300; LOAD callerAddress; PUSH #0; EXTCALL 300/2; LOAD m; EXTCALL DataEntry/2; LOAD nil; EXTCALL 1100/2; EXTCALL WriteSet/1; ENDLET; ENDLET; HALT; PUSH #1; RET; EXT 1100; PUSH #2; EXT 1100; PUSH #3; LOAD nil; EXTCALL 1100/2; EXTCALL 1100/2; RET; EXT 401; LOAD messages; LOAD i; EXTCALL 401/2; RET; PREP msg; LOAD x; CALL msg/1; RET; EXT 600; PROP #4; PROP #5; LOAD i; GETPROP; GETPROP; EXTCALL 600/1; RET", c(rideInt(1), rideString("INFO"), rideString("WARN"), rideString("caller"), rideString("bytes"), rideString("-m")),
+			map[string]testEntryPoint{"tellme": {0, "i"}}},
 	} {
 		src, err := base64.StdEncoding.DecodeString(test.source)
 		require.NoError(t, err, test.comment)
@@ -152,9 +232,12 @@ func TestDAppScriptsCompilation(t *testing.T) {
 		script, ok := rideScript.(*DAppScript)
 		require.True(t, ok, test.comment)
 
-		code := hex.EncodeToString(script.Code)
-		assert.Equal(t, test.code, code, test.comment)
+		assert.Equal(t, test.code, disassemble(t, &script.program), test.comment)
 		assert.ElementsMatch(t, test.constants, script.Constants, test.comment)
-		assert.Equal(t, test.entries, script.EntryPoints, test.comment)
+		entries := make(map[string]testEntryPoint, len(script.EntryPoints))
+		for name, ep := range script.EntryPoints {
+			entries[name] = testEntryPoint{ep.entryPoint, script.names[ep.parameter]}
+		}
+		assert.Equal(t, test.entries, entries, test.comment)
 	}
 }
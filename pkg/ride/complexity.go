@@ -1,6 +1,7 @@
 package ride
 
 import (
+	goctx "context"
 	"fmt"
 
 	"github.com/wavesplatform/gowaves/pkg/util/common"
@@ -24,6 +25,24 @@ type complexityCalculator interface {
 	clone() complexityCalculator
 }
 
+// contextComplexityCalculator reports the error of the done context, so the evaluation is interrupted
+// the same way as on exceeding of the complexity limit.
+type contextComplexityCalculator struct {
+	complexityCalculator
+	ctx goctx.Context
+}
+
+func (cc contextComplexityCalculator) error() error {
+	if err := cc.ctx.Err(); err != nil {
+		return err
+	}
+	return cc.complexityCalculator.error()
+}
+
+func (cc contextComplexityCalculator) clone() complexityCalculator {
+	return contextComplexityCalculator{complexityCalculator: cc.complexityCalculator.clone(), ctx: cc.ctx}
+}
+
 type complexityCalculatorError interface {
 	error
 	EvaluationErrorWrapType() EvaluationError
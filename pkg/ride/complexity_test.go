@@ -1,6 +1,7 @@
 package ride
 
 import (
+	goctx "context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	ridec "github.com/wavesplatform/gowaves/pkg/ride/compiler"
 )
 
 func checkVerifierSpentComplexity(t *testing.T, env environment, code string, complexity int, comment string) {
@@ -576,3 +578,20 @@ func TestComplexityOverflow(t *testing.T) {
 	_, err := CallFunction(env.toEnv(), tree1, proto.NewFunctionCall("call", proto.Arguments{}))
 	require.EqualError(t, err, "failed to test complexity of system function: node '500' with complexity 200 has exceeded the complexity limit 26000 with result complexity 26149") //nolint:lll
 }
+
+func TestContextComplexityCalculator(t *testing.T) {
+	tree, errs := ridec.CompileToTree("{-# STDLIB_VERSION 6 #-}\n{-# CONTENT_TYPE EXPRESSION #-}\nlet a = 1\na == 1")
+	require.Empty(t, errs)
+	env := newTestEnv(t).withLibVersion(ast.LibV6).withComplexityLimit(2000).withRideV6Activated().toEnv()
+	ctx, cancel := goctx.WithCancel(goctx.Background())
+	env.setComplexityCalculator(contextComplexityCalculator{complexityCalculator: env.complexityCalculator(), ctx: ctx})
+	r, err := CallVerifier(env, tree)
+	require.NoError(t, err)
+	assert.True(t, r.Result())
+
+	cancel()
+	_, err = CallVerifier(env, tree)
+	assert.ErrorIs(t, err, goctx.Canceled)
+	_, ok := env.complexityCalculator().clone().(contextComplexityCalculator)
+	assert.True(t, ok)
+}
@@ -3416,3 +3416,31 @@ func TestConvertToActionLease(t *testing.T) {
 		})
 	}
 }
+
+func TestSetInvokeCaller(t *testing.T) {
+	tx := byte_helpers.InvokeScriptWithProofs.Transaction.Clone()
+	caller := proto.MustAddressFromString("3MrDis17gyNSusZDg8Eo1PuFnm5SQMda3gu")
+	pk := []byte{1, 2, 3}
+	for _, v := range []ast.LibraryVersion{ast.LibV3, ast.LibV4, ast.LibV5, ast.LibV8} {
+		env := &EvaluationEnvironment{sch: proto.TestNetScheme}
+		require.NoError(t, env.SetInvoke(tx, v), v)
+		require.NoError(t, env.SetInvokeCaller(caller, pk), v)
+		inv := env.invocation()
+		c, err := inv.get(callerField)
+		require.NoError(t, err, v)
+		assert.Equal(t, rideAddress(caller), c, v)
+		cpk, err := inv.get(callerPublicKeyField)
+		require.NoError(t, err, v)
+		assert.Equal(t, rideByteVector(pk), cpk, v)
+		if v >= ast.LibV5 {
+			oc, oErr := inv.get(originCallerField)
+			require.NoError(t, oErr, v)
+			assert.Equal(t, rideAddress(caller), oc, v)
+			ocpk, oErr := inv.get(originCallerPublicKeyField)
+			require.NoError(t, oErr, v)
+			assert.Equal(t, rideByteVector(pk), ocpk, v)
+		}
+	}
+	env := &EvaluationEnvironment{sch: proto.TestNetScheme}
+	assert.Error(t, env.SetInvokeCaller(caller, pk))
+}
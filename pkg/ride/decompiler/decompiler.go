@@ -0,0 +1,362 @@
+// Package decompiler restores RIDE source code from the tree of compiled script.
+//
+// The source code produced by decompiler compiles back to the same tree. Names of compacted DApps are restored
+// from meta, types of arguments of user functions are inferred from their usage.
+package decompiler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mr-tron/base58"
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+)
+
+const (
+	indentation = "    "
+	// maxLineLength is the length of conditional expression that is split into several lines.
+	maxLineLength = 100
+	// maxBase58Length is the maximum length of byte vector literal that is encoded in Base58.
+	maxBase58Length = 64
+)
+
+// Decompile returns the source code of the script.
+func Decompile(tree *ast.Tree) (string, error) {
+	if tree == nil {
+		return "", errors.New("empty script tree")
+	}
+	if tree.LibVersion < ast.LibV1 || tree.LibVersion > ast.CurrentMaxLibraryVersion() {
+		return "", errors.Errorf("unsupported library version %d", tree.LibVersion)
+	}
+	s, err := lift(decompact(tree))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decompile script")
+	}
+	infer(s)
+	return printScript(s), nil
+}
+
+// DecompileDeclarations returns the source code of global declarations of the DApp without directives,
+// callables and verifier. The source code of declarations is empty for the scripts that are not DApps.
+func DecompileDeclarations(tree *ast.Tree) (string, error) {
+	if tree == nil {
+		return "", errors.New("empty script tree")
+	}
+	if !tree.IsDApp() {
+		return "", nil
+	}
+	s, err := lift(decompact(tree))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decompile script")
+	}
+	infer(s)
+	var sb strings.Builder
+	for _, d := range s.declarations {
+		sb.WriteString(printDeclaration(d, 0))
+		sb.WriteString("\n\n")
+	}
+	return sb.String(), nil
+}
+
+func printScript(s *script) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("{-# STDLIB_VERSION %d #-}\n", s.version))
+	if s.dApp {
+		sb.WriteString("{-# CONTENT_TYPE DAPP #-}\n")
+	} else {
+		sb.WriteString("{-# CONTENT_TYPE EXPRESSION #-}\n")
+	}
+	if s.asset {
+		sb.WriteString("{-# SCRIPT_TYPE ASSET #-}\n")
+	} else {
+		sb.WriteString("{-# SCRIPT_TYPE ACCOUNT #-}\n")
+	}
+	sb.WriteString("\n")
+	if !s.dApp {
+		body := s.expression
+		if b, ok := body.(*block); ok {
+			for _, d := range b.declarations {
+				sb.WriteString(printDeclaration(d, 0))
+				sb.WriteString("\n")
+			}
+			sb.WriteString("\n")
+			sb.WriteString(printBody(b.body, 0))
+		} else {
+			sb.WriteString(printExpression(body, 0))
+		}
+		sb.WriteString("\n")
+		return sb.String()
+	}
+	for _, d := range s.declarations {
+		sb.WriteString(printDeclaration(d, 0))
+		sb.WriteString("\n\n")
+	}
+	callables := s.callables
+	if s.verifier != nil {
+		callables = append(callables[:len(callables):len(callables)], s.verifier)
+	}
+	for _, c := range callables {
+		sb.WriteString(fmt.Sprintf("@%s(%s)\n", c.annotation, c.parameter))
+		sb.WriteString(printDeclaration(c.function, 0))
+		sb.WriteString("\n\n")
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+func indent(level int) string {
+	return strings.Repeat(indentation, level)
+}
+
+func printDeclaration(d declaration, level int) string {
+	switch d := d.(type) {
+	case *variable:
+		return fmt.Sprintf("%s %s = %s", keyword(d.strict), d.name, printExpression(d.value, level))
+	case *destructuring:
+		return fmt.Sprintf("%s (%s) = %s", keyword(d.strict), strings.Join(d.names, ", "),
+			printExpression(d.value, level))
+	case *function:
+		args := make([]string, len(d.args))
+		for i, a := range d.args {
+			args[i] = a + ": " + typeString(d.types[i])
+		}
+		return fmt.Sprintf("func %s(%s) = %s", d.name, strings.Join(args, ", "), printExpression(d.body, level))
+	default:
+		panic(fmt.Sprintf("unexpected declaration type %T", d))
+	}
+}
+
+func keyword(strict bool) string {
+	if strict {
+		return "strict"
+	}
+	return "let"
+}
+
+// printBody prints the expression that follows declarations, the expression that starts with minus sign
+// is enclosed in parentheses to prevent its parsing as subtraction from the last declaration.
+func printBody(e expression, level int) string {
+	s := printExpression(e, level)
+	if strings.HasPrefix(s, "-") {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// printLines prints the declarations and the expression of the block without braces.
+func printLines(e expression, level int) string {
+	b, ok := e.(*block)
+	if !ok {
+		return printExpression(e, level)
+	}
+	var sb strings.Builder
+	for _, d := range b.declarations {
+		sb.WriteString(printDeclaration(d, level))
+		sb.WriteString("\n")
+		sb.WriteString(indent(level))
+	}
+	sb.WriteString(printBody(b.body, level))
+	return sb.String()
+}
+
+func printOperand(e expression, precedence, level int) string {
+	s := printExpression(e, level)
+	if e.precedence() < precedence {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func printExpressions(es []expression, level int) string {
+	r := make([]string, len(es))
+	for i, e := range es {
+		r[i] = printExpression(e, level)
+	}
+	return strings.Join(r, ", ")
+}
+
+func printExpression(e expression, level int) string {
+	switch e := e.(type) {
+	case *literal:
+		return printLiteral(e.node)
+	case *reference:
+		return e.name
+	case *call:
+		return e.name + "(" + printExpressions(e.args, level) + ")"
+	case *operator:
+		if len(e.args) == 1 {
+			return e.op + printOperand(e.args[0], precedenceAtom, level)
+		}
+		p := e.precedence()
+		left := p
+		if p == precedenceList {
+			left++ // The compiler doesn't update the type of list in chains of list operators
+		}
+		return printOperand(e.args[0], left, level) + " " + e.op + " " + printOperand(e.args[1], p+1, level)
+	case *list:
+		return "[" + printExpressions(e.items, level) + "]"
+	case *tuple:
+		return "(" + printExpressions(e.items, level) + ")"
+	case *index:
+		return printOperand(e.list, precedenceAtom, level) + "[" + printExpression(e.index, level) + "]"
+	case *property:
+		return printOperand(e.object, precedenceAtom, level) + "." + e.name
+	case *cast:
+		if e.exact {
+			return printOperand(e.value, precedenceAtom, level) + ".exactAs[" + e.typ + "]"
+		}
+		return printOperand(e.value, precedenceAtom, level) + ".as[" + e.typ + "]"
+	case *fold:
+		return fmt.Sprintf("FOLD<%d>(%s, %s, %s)", e.limit, printExpression(e.list, level),
+			printExpression(e.start, level), e.function)
+	case *condition:
+		return printCondition(e, level)
+	case *block:
+		return "{\n" + indent(level+1) + printLines(e, level+1) + "\n" + indent(level) + "}"
+	case *match:
+		return printMatch(e, level)
+	default:
+		panic(fmt.Sprintf("unexpected expression type %T", e))
+	}
+}
+
+func printCondition(e *condition, level int) string {
+	c := printExpression(e.condition, level)
+	t := printExpression(e.then, level+1)
+	f := printExpression(e.otherwise, level+1)
+	line := "if (" + c + ") then " + t + " else " + f
+	if !strings.Contains(line, "\n") && len(line)+len(indent(level)) <= maxLineLength {
+		return line
+	}
+	return "if (" + c + ")\n" + indent(level+1) + "then " + t + "\n" + indent(level+1) + "else " + f
+}
+
+func printMatch(e *match, level int) string {
+	var sb strings.Builder
+	sb.WriteString("match " + printOperand(e.value, precedenceOr, level) + " {\n")
+	for _, c := range e.cases {
+		sb.WriteString(indent(level+1) + "case " + printPattern(c.pattern, level+1) + " =>\n")
+		body := printLines(c.body, level+2)
+		if _, ok := c.body.(*block); !ok && strings.HasPrefix(body, "{") {
+			// Body that starts with brace is parsed as a block till the closing brace
+			body = "(" + body + ")"
+		}
+		sb.WriteString(indent(level+2) + body + "\n")
+	}
+	sb.WriteString(indent(level) + "}")
+	return sb.String()
+}
+
+func printPattern(p pattern, level int) string {
+	switch p := p.(type) {
+	case *typePattern:
+		return placeholder(p.name) + ": " + strings.Join(p.types, "|")
+	case *valuePattern:
+		s := printExpression(p.value, level)
+		c, isCall := p.value.(*call)
+		if strings.HasPrefix(s, "(") || strings.HasPrefix(s, "_") || isCall && len(c.args) == 0 {
+			// Such expressions are parsed as tuple, placeholder or object patterns
+			return "{ " + s + " }"
+		}
+		return s
+	case *objectPattern:
+		fields := make([]string, len(p.fields))
+		for i, f := range p.fields {
+			if f.value != nil {
+				fields[i] = f.field + " = " + printPatternValue(f.value, level)
+			} else {
+				fields[i] = f.field + " = " + f.name
+			}
+		}
+		return p.typ + "(" + strings.Join(fields, ", ") + ")"
+	case *tuplePattern:
+		items := make([]string, len(p.items))
+		for i, it := range p.items {
+			switch {
+			case it.value != nil:
+				items[i] = printPatternValue(it.value, level)
+			case it.typ != "":
+				items[i] = placeholder(it.name) + ": " + it.typ
+			default:
+				items[i] = placeholder(it.name)
+			}
+		}
+		return "(" + strings.Join(items, ", ") + ")"
+	default:
+		return "_"
+	}
+}
+
+func placeholder(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// printPatternValue prints the value compared with the item of tuple or the field of object.
+// Values other than literals are enclosed in parentheses to distinguish them from bindings.
+func printPatternValue(e expression, level int) string {
+	s := printExpression(e, level)
+	if _, ok := e.(*literal); ok {
+		return s
+	}
+	return "(" + s + ")"
+}
+
+func printLiteral(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.LongNode:
+		if n.Value == math.MinInt64 {
+			return "(-9223372036854775807 - 1)"
+		}
+		return strconv.FormatInt(n.Value, 10)
+	case *ast.BooleanNode:
+		return strconv.FormatBool(n.Value)
+	case *ast.StringNode:
+		return quote(n.Value)
+	case *ast.BytesNode:
+		if len(n.Value) <= maxBase58Length {
+			return "base58'" + base58.Encode(n.Value) + "'"
+		}
+		return "base64'" + base64.StdEncoding.EncodeToString(n.Value) + "'"
+	default:
+		panic(fmt.Sprintf("unexpected literal type %T", node))
+	}
+}
+
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r <= 0xffff && !unicode.IsPrint(r) {
+				sb.WriteString(fmt.Sprintf(`\u%04x`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
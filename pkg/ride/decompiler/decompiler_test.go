@@ -0,0 +1,267 @@
+package decompiler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler"
+	"github.com/wavesplatform/gowaves/pkg/ride/serialization"
+)
+
+func compile(t *testing.T, code string, compact bool) *ast.Tree {
+	b, errs := compiler.Compile(code, compact, false)
+	require.Empty(t, errs, code)
+	tree, err := serialization.Parse(b)
+	require.NoError(t, err)
+	return tree
+}
+
+// normalize replaces the names of tuple variables, they depend on the position of declaration in source code.
+func normalize(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.AssignmentNode:
+		if strings.HasPrefix(n.Name, tuplePrefix) {
+			n.Name = tuplePrefix
+		}
+		normalize(n.Expression)
+		normalize(n.Block)
+	case *ast.FunctionDeclarationNode:
+		normalize(n.Body)
+		normalize(n.Block)
+	case *ast.ReferenceNode:
+		if strings.HasPrefix(n.Name, tuplePrefix) {
+			n.Name = tuplePrefix
+		}
+	case *ast.PropertyNode:
+		normalize(n.Object)
+	case *ast.ConditionalNode:
+		normalize(n.Condition)
+		normalize(n.TrueExpression)
+		normalize(n.FalseExpression)
+	case *ast.FunctionCallNode:
+		for _, a := range n.Arguments {
+			normalize(a)
+		}
+	}
+}
+
+func normalizeTree(tree *ast.Tree) {
+	for _, d := range tree.Declarations {
+		normalize(d)
+	}
+	for _, f := range tree.Functions {
+		normalize(f)
+	}
+	normalize(tree.Verifier)
+}
+
+func checkRoundTrip(t *testing.T, code string, compact bool) string {
+	expected := compile(t, code, compact)
+	src, err := Decompile(expected)
+	require.NoError(t, err)
+	actual := compile(t, src, compact)
+	assert.Equal(t, expected.LibVersion, actual.LibVersion)
+	assert.Equal(t, expected.ContentType, actual.ContentType)
+	normalizeTree(expected)
+	normalizeTree(actual)
+	if diff := deep.Equal(expected.Declarations, actual.Declarations); diff != nil {
+		t.Errorf("Declarations mismatch:\n%s\n%s", strings.Join(diff, "\n"), src)
+	}
+	if diff := deep.Equal(expected.Functions, actual.Functions); diff != nil {
+		t.Errorf("Functions mismatch:\n%s\n%s", strings.Join(diff, "\n"), src)
+	}
+	if diff := deep.Equal(expected.Verifier, actual.Verifier); diff != nil {
+		t.Errorf("Verifier mismatch:\n%s\n%s", strings.Join(diff, "\n"), src)
+	}
+	if diff := deep.Equal(expected.Meta.Functions, actual.Meta.Functions); diff != nil {
+		t.Errorf("Meta mismatch:\n%s\n%s", strings.Join(diff, "\n"), src)
+	}
+	return src
+}
+
+func TestDecompileScripts(t *testing.T) {
+	files, err := filepath.Glob("../compiler/testdata/*.ride")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		code, err := os.ReadFile(file)
+		require.NoError(t, err)
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			checkRoundTrip(t, string(code), false)
+		})
+		t.Run(filepath.Base(file)+"/compacted", func(t *testing.T) {
+			checkRoundTrip(t, string(code), true)
+		})
+	}
+}
+
+func TestDecompile(t *testing.T) {
+	for _, test := range []struct {
+		comment string
+		code    string
+	}{
+		{"V1 expression", `
+			{-# STDLIB_VERSION 1 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			let a = 1 + 2 * 3
+			let b = (1 + 2) * 3
+			let c = -a
+			match tx {
+			    case t: TransferTransaction => t.amount > a && !(b < c) || height >= 100
+			    case _ => sigVerify(tx.bodyBytes, tx.proofs[0], tx.senderPublicKey)
+			}`},
+		{"V2 strings and bytes", `
+			{-# STDLIB_VERSION 2 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			let s = "quote \" backslash \\ tab \t newline \n unicode é"
+			let b = base64'AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyAhIiMkJSYnKCkqKywtLi8wMTIzNDU2Nzg5Ojs8PT4/QEFC'
+			size(s) > 0 && size(b) > 0 && base58'' == base16'' && -9223372036854775807 - 1 < 0`},
+		{"V3 DApp", `
+			{-# STDLIB_VERSION 3 #-}
+			{-# CONTENT_TYPE DAPP #-}
+			{-# SCRIPT_TYPE ACCOUNT #-}
+			let owner = base58'3N1HYdheWWgSrHsRSGEHgwKTBiygmWxpyf5'
+			func key(a: String, n: Int) = a + "_" + toString(n)
+
+			@Callable(i)
+			func deposit(n: Int) = {
+			    let pmt = extract(i.payment)
+			    if (isDefined(pmt.assetId)) then throw("only waves")
+			    else WriteSet([DataEntry(key(toBase58String(i.caller.bytes), n), pmt.amount)])
+			}
+
+			@Verifier(tx)
+			func verify() = sigVerify(tx.bodyBytes, tx.proofs[0], owner)`},
+		{"V4 asset script", `
+			{-# STDLIB_VERSION 4 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			{-# SCRIPT_TYPE ASSET #-}
+			match tx {
+			    case t: ReissueTransaction|BurnTransaction => this.issuer == t.sender
+			    case _ => this.decimals == 8
+			}`},
+		{"V4 lists and fold", `
+			{-# STDLIB_VERSION 4 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			func sum(acc: Int, x: Int) = acc + x
+			let l = [1, 2, 3] :+ 4
+			let m = (0 :: (1 :: l)) ++ [5]
+			FOLD<5>(l, 0, sum) + m[0] == 10 && this == this`},
+		{"V5 tuples, strict and casts", `
+			{-# STDLIB_VERSION 5 #-}
+			{-# CONTENT_TYPE DAPP #-}
+			{-# SCRIPT_TYPE ACCOUNT #-}
+			let (a, b, c) = (1, "x", true)
+			func pair(x: Int|String, y: List[Int]) = match x {
+			    case i: Int => (i, y)
+			    case s: String => (size(s), y)
+			}
+
+			@Callable(inv)
+			func call(v: Int|String, l: List[Int]) = {
+			    strict (p, q) = pair(v, l)
+			    strict r = getInteger(this, "k").value()
+			    let t = (p, q, r)
+			    let u = match t {
+			        case (x: Int, _, 0) => x
+			        case (_, y: List[Int], z) => size(y) + z
+			    }
+			    ([IntegerEntry("u", u), StringEntry("b", b)], if (c) then a else u)
+			}`},
+		{"V6 object patterns and exact casts", `
+			{-# STDLIB_VERSION 6 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			func check(t: TransferTransaction|ExchangeTransaction) = match t {
+			    case TransferTransaction(amount = 10, fee = 1) => true
+			    case ExchangeTransaction(amount = 5) => false
+			}
+			let v = 10.toBigInt() * parseBigIntValue("2")
+			match tx {
+			    case t: TransferTransaction|ExchangeTransaction => check(t) && v.toInt().exactAs[Int] == 20
+			    case _ => throw()
+			}`},
+		{"V7 nested functions and matches", `
+			{-# STDLIB_VERSION 7 #-}
+			{-# CONTENT_TYPE DAPP #-}
+			{-# SCRIPT_TYPE ACCOUNT #-}
+			func outer(x: Int) = {
+			    func inner(y: Int) = match y {
+			        case 1 => "one"
+			        case n: Int => match n % 2 {
+			            case 0 => "even"
+			            case _ => "odd"
+			        }
+			    }
+			    inner(x)
+			}
+
+			@Callable(i)
+			func call(n: Int) = ([StringEntry("r", outer(n))], unit)`},
+		{"V8 expression", `
+			{-# STDLIB_VERSION 8 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			let x = if (height > 10) then { let y = 1; -y } else 2
+			x != 0 && [1, 2][0] == 1`},
+	} {
+		t.Run(test.comment, func(t *testing.T) {
+			checkRoundTrip(t, test.code, false)
+		})
+	}
+}
+
+func TestDecompileCompactedNames(t *testing.T) {
+	code := `
+		{-# STDLIB_VERSION 6 #-}
+		{-# CONTENT_TYPE DAPP #-}
+		{-# SCRIPT_TYPE ACCOUNT #-}
+		let config = "config"
+		func amountKey(address: String) = config + "_" + address
+
+		@Callable(inv)
+		func store(amount: Int) = [IntegerEntry(amountKey(toString(inv.caller)), amount)]`
+	src := checkRoundTrip(t, code, true)
+	assert.Contains(t, src, "let config = ")
+	assert.Contains(t, src, "func amountKey(address: String) = ")
+	assert.Contains(t, src, "@Callable(inv)\nfunc store(amount: Int) = ")
+}
+
+func TestDecompileDeclarations(t *testing.T) {
+	tree := compile(t, `
+		{-# STDLIB_VERSION 6 #-}
+		{-# CONTENT_TYPE DAPP #-}
+		{-# SCRIPT_TYPE ACCOUNT #-}
+		let config = "config"
+		func amountKey(address: String) = config + "_" + address
+
+		@Callable(inv)
+		func store(amount: Int) = [IntegerEntry(amountKey(toString(inv.caller)), amount)]`, true)
+	src, err := DecompileDeclarations(tree)
+	require.NoError(t, err)
+	assert.Equal(t, "let config = \"config\"\n\nfunc amountKey(address: String) = config + \"_\" + address\n\n", src)
+
+	src, err = DecompileDeclarations(compile(t, "{-# STDLIB_VERSION 6 #-}\n{-# CONTENT_TYPE EXPRESSION #-}\nlet x = 1\nx == 1", false))
+	require.NoError(t, err)
+	assert.Empty(t, src)
+}
+
+func TestDecompileErrors(t *testing.T) {
+	_, err := Decompile(nil)
+	assert.Error(t, err)
+	tree := &ast.Tree{
+		LibVersion:  ast.LibV6,
+		ContentType: ast.ContentTypeExpression,
+		Verifier:    ast.NewReferenceNode("$x"),
+	}
+	_, err = Decompile(tree)
+	assert.EqualError(t, err, "failed to decompile script: invalid identifier '$x'")
+	tree.Verifier = ast.NewStringNode("\xff")
+	_, err = Decompile(tree)
+	assert.Error(t, err)
+}
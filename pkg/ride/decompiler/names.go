@@ -0,0 +1,193 @@
+package decompiler
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler/stdlib"
+)
+
+var reservedWords = map[string]struct{}{
+	"let": {}, "strict": {}, "base16": {}, "base58": {}, "base64": {}, "true": {}, "false": {}, "if": {}, "then": {},
+	"else": {}, "match": {}, "case": {}, "func": {}, "FOLD": {},
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// checkIdentifier returns an error if the name can't be written in RIDE source code.
+func checkIdentifier(name string) error {
+	if _, ok := reservedWords[name]; ok {
+		return errors.Errorf("reserved word '%s' used as identifier", name)
+	}
+	valid := name != "" && (isLetter(name[0]) || name[0] == '_' && len(name) > 1 && isLetter(name[1]))
+	for i := 1; valid && i < len(name); i++ {
+		c := name[i]
+		valid = isLetter(c) || isDigit(c) || c == '_' && (i+1 == len(name) || name[i+1] != '_')
+	}
+	if !valid {
+		return errors.Errorf("invalid identifier '%s'", name)
+	}
+	return nil
+}
+
+// stdlibFunctions returns the names of standard library functions by their identifiers.
+func stdlibFunctions(v ast.LibraryVersion) map[string]string {
+	signatures := stdlib.FuncsByVersion()[v]
+	r := make(map[string]string, len(signatures.Funcs))
+	for name, overloads := range signatures.Funcs {
+		for _, o := range overloads {
+			r[o.ID.Name()] = name
+		}
+	}
+	return r
+}
+
+// renamer restores the original names of the compacted DApp. Declarations are renamed along with the references
+// in their scope, the same way as the compaction renamed them.
+type renamer struct {
+	tree     *ast.Tree
+	names    map[string]string
+	declared []string
+}
+
+// decompact returns the copy of the tree with the original names of declarations.
+func decompact(tree *ast.Tree) *ast.Tree {
+	if !tree.IsDApp() {
+		return tree
+	}
+	r := &renamer{tree: tree, names: compiler.OriginalNames(tree)}
+	res := *tree
+	res.Declarations = make([]ast.Node, len(tree.Declarations))
+	for i, d := range tree.Declarations {
+		res.Declarations[i] = r.declaration(d)
+	}
+	res.Functions = make([]ast.Node, len(tree.Functions))
+	for i, n := range tree.Functions {
+		f, ok := n.(*ast.FunctionDeclarationNode)
+		if !ok {
+			res.Functions[i] = n
+			continue
+		}
+		res.Functions[i] = r.callable(f, false)
+	}
+	if f, ok := tree.Verifier.(*ast.FunctionDeclarationNode); ok {
+		res.Verifier = r.callable(f, true)
+	}
+	return &res
+}
+
+func (r *renamer) original(name string) string {
+	if o, ok := r.names[name]; ok {
+		return o
+	}
+	if o, err := r.tree.Meta.Abbreviations.CompactToOriginal(name); err == nil {
+		return o
+	}
+	return name
+}
+
+func (r *renamer) reference(name string) string {
+	for i := len(r.declared) - 1; i >= 0; i-- {
+		if r.declared[i] == name {
+			return r.original(name)
+		}
+	}
+	return name
+}
+
+func (r *renamer) push(names ...string) {
+	r.declared = append(r.declared, names...)
+}
+
+func (r *renamer) pop(n int) {
+	r.declared = r.declared[:len(r.declared)-n]
+}
+
+// callable renames the annotated function, names of callable functions are not compacted.
+func (r *renamer) callable(f *ast.FunctionDeclarationNode, verifier bool) *ast.FunctionDeclarationNode {
+	r.push(f.InvocationParameter)
+	res := r.function(f)
+	if !verifier {
+		res.Name = f.Name
+	}
+	res.InvocationParameter = r.original(f.InvocationParameter)
+	r.pop(1)
+	return res
+}
+
+func (r *renamer) function(f *ast.FunctionDeclarationNode) *ast.FunctionDeclarationNode {
+	args := make([]string, len(f.Arguments))
+	for i, a := range f.Arguments {
+		args[i] = r.original(a)
+	}
+	r.push(f.Arguments...)
+	body := r.expression(f.Body)
+	r.pop(len(f.Arguments))
+	res := ast.NewFunctionDeclarationNode(r.original(f.Name), args, body, nil)
+	res.InvocationParameter = f.InvocationParameter
+	return res
+}
+
+// declaration renames the declaration and leaves its name in scope.
+func (r *renamer) declaration(node ast.Node) ast.Node {
+	switch n := node.(type) {
+	case *ast.FunctionDeclarationNode:
+		res := r.function(n)
+		r.push(n.Name)
+		return res
+	case *ast.AssignmentNode:
+		res := ast.NewAssignmentNode(r.original(n.Name), r.expression(n.Expression), nil)
+		res.NewBlock = n.NewBlock
+		r.push(n.Name)
+		return res
+	default:
+		return node
+	}
+}
+
+func (r *renamer) expression(node ast.Node) ast.Node {
+	switch n := node.(type) {
+	case *ast.FunctionDeclarationNode, *ast.AssignmentNode:
+		d := r.declaration(n)
+		d.SetBlock(r.expression(blockOf(n)))
+		r.pop(1)
+		return d
+	case *ast.FunctionCallNode:
+		f := n.Function
+		if uf, ok := f.(ast.UserFunction); ok {
+			f = ast.UserFunction(r.reference(string(uf)))
+		}
+		args := make([]ast.Node, len(n.Arguments))
+		for i, a := range n.Arguments {
+			args[i] = r.expression(a)
+		}
+		return ast.NewFunctionCallNode(f, args)
+	case *ast.ReferenceNode:
+		return ast.NewReferenceNode(r.reference(n.Name))
+	case *ast.PropertyNode:
+		return ast.NewPropertyNode(n.Name, r.expression(n.Object))
+	case *ast.ConditionalNode:
+		return ast.NewConditionalNode(r.expression(n.Condition), r.expression(n.TrueExpression),
+			r.expression(n.FalseExpression))
+	default:
+		return node
+	}
+}
+
+func blockOf(node ast.Node) ast.Node {
+	switch n := node.(type) {
+	case *ast.FunctionDeclarationNode:
+		return n.Block
+	case *ast.AssignmentNode:
+		return n.Block
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,210 @@
+package decompiler
+
+import (
+	"math"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler/stdlib"
+)
+
+// Precedences of expressions, from the loosest to the tightest binding.
+const (
+	precedenceCondition = iota
+	precedenceOr
+	precedenceAnd
+	precedenceEquality
+	precedenceComparison
+	precedenceList
+	precedenceSum
+	precedenceProduct
+	precedenceUnary
+	precedenceAtom
+)
+
+var operatorPrecedences = map[string]int{
+	"||": precedenceOr,
+	"&&": precedenceAnd,
+	"==": precedenceEquality, "!=": precedenceEquality,
+	">": precedenceComparison, ">=": precedenceComparison,
+	"::": precedenceList, ":+": precedenceList, "++": precedenceList,
+	"+": precedenceSum, "-": precedenceSum,
+	"*": precedenceProduct, "/": precedenceProduct, "%": precedenceProduct,
+}
+
+// expression is a construction of RIDE source code.
+type expression interface {
+	precedence() int
+}
+
+type literal struct {
+	node ast.Node
+}
+
+func (e *literal) precedence() int {
+	if l, ok := e.node.(*ast.LongNode); ok && l.Value < 0 && l.Value != math.MinInt64 {
+		return precedenceUnary
+	}
+	return precedenceAtom
+}
+
+type reference struct {
+	name string
+}
+
+func (e *reference) precedence() int { return precedenceAtom }
+
+// call is a call of user function, standard library function or object constructor.
+type call struct {
+	name string
+	id   string // Identifier of the overloaded standard library function
+	user bool
+	args []expression
+}
+
+func (e *call) precedence() int { return precedenceAtom }
+
+// operator is a binary operator or a unary operator with one argument.
+type operator struct {
+	op   string
+	id   string
+	args []expression
+}
+
+func (e *operator) precedence() int {
+	if len(e.args) == 1 {
+		return precedenceUnary
+	}
+	return operatorPrecedences[e.op]
+}
+
+type list struct {
+	items []expression
+}
+
+func (e *list) precedence() int { return precedenceAtom }
+
+type tuple struct {
+	items []expression
+}
+
+func (e *tuple) precedence() int { return precedenceAtom }
+
+type index struct {
+	list  expression
+	index expression
+}
+
+func (e *index) precedence() int { return precedenceAtom }
+
+type property struct {
+	object expression
+	name   string
+}
+
+func (e *property) precedence() int { return precedenceAtom }
+
+type condition struct {
+	condition expression
+	then      expression
+	otherwise expression
+}
+
+func (e *condition) precedence() int { return precedenceCondition }
+
+type block struct {
+	declarations []declaration
+	body         expression
+}
+
+func (e *block) precedence() int { return precedenceAtom }
+
+type cast struct {
+	value expression
+	typ   string
+	exact bool
+}
+
+func (e *cast) precedence() int { return precedenceAtom }
+
+type fold struct {
+	limit    int64
+	list     expression
+	start    expression
+	function string
+}
+
+func (e *fold) precedence() int { return precedenceAtom }
+
+type match struct {
+	value expression
+	cases []matchCase
+}
+
+func (e *match) precedence() int { return precedenceAtom }
+
+type matchCase struct {
+	pattern pattern
+	body    expression
+}
+
+// pattern is a pattern of match case.
+type pattern interface{}
+
+type typePattern struct {
+	name  string // Empty for placeholder
+	types []string
+}
+
+type valuePattern struct {
+	value expression
+}
+
+type objectPattern struct {
+	typ    string
+	fields []fieldPattern
+}
+
+// fieldPattern either binds the field to the name or compares it with the value.
+type fieldPattern struct {
+	field string
+	name  string
+	value expression
+}
+
+type tuplePattern struct {
+	items []tupleItem
+}
+
+// tupleItem is a placeholder if all fields are empty.
+type tupleItem struct {
+	name  string
+	typ   string
+	value expression
+}
+
+type defaultPattern struct{}
+
+// declaration is a declaration of variable or function.
+type declaration interface{}
+
+type variable struct {
+	name   string
+	value  expression
+	strict bool
+}
+
+type destructuring struct {
+	names  []string
+	value  expression
+	strict bool
+}
+
+// function is the declaration of user function with the types of arguments restored by inference.
+type function struct {
+	name   string
+	args   []string
+	body   expression
+	types  []stdlib.Type
+	usages []usage
+	result stdlib.Type
+}
@@ -0,0 +1,853 @@
+package decompiler
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler/stdlib"
+)
+
+const (
+	matchPrefix   = "$match"
+	tuplePrefix   = "$t0"
+	castName      = "@"
+	strictMessage = "Strict value is not equal to itself."
+	matchMessage  = "Match error"
+)
+
+var binaryOperators = map[string]string{
+	"0": "==", "!=": "!=",
+	"102": ">", "319": ">", "103": ">=", "320": ">=",
+	"100": "+", "311": "+", "300": "+", "203": "+", "101": "-", "312": "-",
+	"104": "*", "313": "*", "105": "/", "314": "/", "106": "%", "315": "%",
+	"1100": "::", "1101": ":+", "1102": "++",
+}
+
+var unaryOperators = map[string]string{
+	"!": "!", "-": "-", "318": "-",
+}
+
+// script is the source code of the script restored from the tree.
+type script struct {
+	version      ast.LibraryVersion
+	dApp         bool
+	asset        bool
+	declarations []declaration
+	callables    []*callable
+	verifier     *callable
+	expression   expression // Body of expression script
+	functions    []*function
+}
+
+type callable struct {
+	annotation string
+	parameter  string
+	function   *function
+	meta       bool // Types of arguments are taken from meta
+}
+
+// lifter restores source code constructions from the tree produced by the compiler.
+type lifter struct {
+	version   ast.LibraryVersion
+	functions map[string]string
+	objects   stdlib.ObjectsSignatures
+	scopes    []map[string]struct{} // Names of user functions
+	declared  []*function
+	asset     bool
+}
+
+func lift(tree *ast.Tree) (*script, error) {
+	l := &lifter{
+		version:   tree.LibVersion,
+		functions: stdlibFunctions(tree.LibVersion),
+		objects:   stdlib.ObjectsByVersion()[tree.LibVersion],
+		scopes:    []map[string]struct{}{{}},
+	}
+	s := &script{version: tree.LibVersion, dApp: tree.IsDApp()}
+	if !s.dApp {
+		e, err := l.block(tree.Verifier)
+		if err != nil {
+			return nil, err
+		}
+		s.expression = e
+		s.asset = l.asset
+		s.functions = l.declared
+		return s, nil
+	}
+	decls, err := l.topLevel(tree.Declarations)
+	if err != nil {
+		return nil, err
+	}
+	s.declarations = decls
+	for i, n := range tree.Functions {
+		f, ok := n.(*ast.FunctionDeclarationNode)
+		if !ok {
+			return nil, errors.Errorf("invalid callable function type '%T'", n)
+		}
+		c, err := l.callable("Callable", f)
+		if err != nil {
+			return nil, err
+		}
+		if i < len(tree.Meta.Functions) && len(tree.Meta.Functions[i].Arguments) == len(f.Arguments) {
+			c.meta = true
+			for j, t := range tree.Meta.Functions[i].Arguments {
+				c.function.types[j] = metaType(t)
+			}
+		}
+		s.callables = append(s.callables, c)
+	}
+	if tree.HasVerifier() {
+		f, ok := tree.Verifier.(*ast.FunctionDeclarationNode)
+		if !ok {
+			return nil, errors.Errorf("invalid verifier function type '%T'", tree.Verifier)
+		}
+		if s.verifier, err = l.callable("Verifier", f); err != nil {
+			return nil, err
+		}
+		s.verifier.meta = true
+	}
+	s.functions = l.declared
+	return s, nil
+}
+
+func (l *lifter) callable(annotation string, f *ast.FunctionDeclarationNode) (*callable, error) {
+	if err := checkIdentifier(f.InvocationParameter); err != nil {
+		return nil, err
+	}
+	fn, err := l.function(f)
+	if err != nil {
+		return nil, err
+	}
+	return &callable{annotation: annotation, parameter: f.InvocationParameter, function: fn}, nil
+}
+
+func (l *lifter) push() {
+	l.scopes = append(l.scopes, map[string]struct{}{})
+}
+
+func (l *lifter) pop() {
+	l.scopes = l.scopes[:len(l.scopes)-1]
+}
+
+func (l *lifter) isUserFunction(name string) bool {
+	for i := len(l.scopes) - 1; i >= 0; i-- {
+		if _, ok := l.scopes[i][name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// topLevel restores global declarations of DApp.
+func (l *lifter) topLevel(nodes []ast.Node) ([]declaration, error) {
+	// Declarations are linked in one block to recognize tuple declarations that span several nodes
+	end := ast.NewBooleanNode(true)
+	var node ast.Node = end
+	for i := len(nodes) - 1; i >= 0; i-- {
+		switch n := nodes[i].(type) {
+		case *ast.AssignmentNode:
+			c := *n
+			c.Block = node
+			node = &c
+		case *ast.FunctionDeclarationNode:
+			c := *n
+			c.Block = node
+			node = &c
+		default:
+			return nil, errors.Errorf("invalid declaration type '%T'", n)
+		}
+	}
+	var r []declaration
+	for node != end {
+		d, next, ok, err := l.declaration(node)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.Errorf("unsupported global declaration '%s'", node.(*ast.AssignmentNode).Name)
+		}
+		r = append(r, d)
+		node = next
+	}
+	return r, nil
+}
+
+// block restores declarations followed by expression, the expression itself is returned if there is no declarations.
+func (l *lifter) block(node ast.Node) (expression, error) {
+	l.push()
+	defer l.pop()
+	var decls []declaration
+	for {
+		d, next, ok, err := l.declaration(node)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		decls = append(decls, d)
+		node = next
+	}
+	body, err := l.expression(node)
+	if err != nil {
+		return nil, err
+	}
+	if len(decls) == 0 {
+		return body, nil
+	}
+	return &block{declarations: decls, body: body}, nil
+}
+
+// declaration restores the declaration and returns the node that follows it.
+func (l *lifter) declaration(node ast.Node) (declaration, ast.Node, bool, error) {
+	switch n := node.(type) {
+	case *ast.AssignmentNode:
+		if isSpecial(n) {
+			return nil, nil, false, nil
+		}
+		if strings.HasPrefix(n.Name, tuplePrefix) {
+			if d, next, ok, err := l.destructuring(n); ok || err != nil {
+				return d, next, ok, err
+			}
+		}
+		if err := checkIdentifier(n.Name); err != nil {
+			return nil, nil, false, err
+		}
+		v, err := l.expression(n.Expression)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if next, ok := strictCheck(n.Block, n.Name); ok {
+			return &variable{name: n.Name, value: v, strict: true}, next, true, nil
+		}
+		return &variable{name: n.Name, value: v}, n.Block, true, nil
+	case *ast.FunctionDeclarationNode:
+		f, err := l.function(n)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		l.scopes[len(l.scopes)-1][n.Name] = struct{}{}
+		return f, n.Block, true, nil
+	default:
+		return nil, nil, false, nil
+	}
+}
+
+func (l *lifter) function(n *ast.FunctionDeclarationNode) (*function, error) {
+	if err := checkIdentifier(n.Name); err != nil {
+		return nil, err
+	}
+	for _, a := range n.Arguments {
+		if err := checkIdentifier(a); err != nil {
+			return nil, err
+		}
+	}
+	body, err := l.expression(n.Body)
+	if err != nil {
+		return nil, err
+	}
+	f := &function{name: n.Name, args: n.Arguments, body: body, types: make([]stdlib.Type, len(n.Arguments))}
+	l.declared = append(l.declared, f)
+	return f, nil
+}
+
+// destructuring restores the declaration of tuple items, the tuple is assigned to the variable that has name
+// starting with "$t0" and the items are assigned to variables that follow it.
+// For strict declaration the items are assigned in reversed order.
+func (l *lifter) destructuring(n *ast.AssignmentNode) (declaration, ast.Node, bool, error) {
+	next, strict := strictCheck(n.Block, n.Name)
+	if !strict {
+		next = n.Block
+	}
+	items := make(map[int]string)
+	for {
+		a, ok := next.(*ast.AssignmentNode)
+		if !ok {
+			break
+		}
+		i, ok := itemIndex(a.Expression, n.Name)
+		if !ok {
+			break
+		}
+		if _, ok := items[i]; ok {
+			break
+		}
+		items[i] = a.Name
+		next = a.Block
+	}
+	if len(items) == 0 {
+		return nil, nil, false, nil
+	}
+	names := make([]string, len(items))
+	for i := range names {
+		name, ok := items[i+1]
+		if !ok {
+			return nil, nil, false, nil
+		}
+		if err := checkIdentifier(name); err != nil {
+			return nil, nil, false, err
+		}
+		names[i] = name
+	}
+	v, err := l.expression(n.Expression)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return &destructuring{names: names, value: v, strict: strict}, next, true, nil
+}
+
+func (l *lifter) expressions(nodes []ast.Node) ([]expression, error) {
+	r := make([]expression, len(nodes))
+	for i, n := range nodes {
+		e, err := l.expression(n)
+		if err != nil {
+			return nil, err
+		}
+		r[i] = e
+	}
+	return r, nil
+}
+
+func (l *lifter) expression(node ast.Node) (expression, error) {
+	switch n := node.(type) {
+	case *ast.LongNode, *ast.BooleanNode, *ast.BytesNode:
+		return &literal{node: n}, nil
+	case *ast.StringNode:
+		if !utf8.ValidString(n.Value) {
+			return nil, errors.Errorf("invalid UTF-8 string '%s'", n.Value)
+		}
+		return &literal{node: n}, nil
+	case *ast.ReferenceNode:
+		if n.Name == "nil" {
+			return &list{}, nil
+		}
+		if err := checkIdentifier(n.Name); err != nil {
+			return nil, err
+		}
+		return &reference{name: n.Name}, nil
+	case *ast.PropertyNode:
+		obj, err := l.expression(n.Object)
+		if err != nil {
+			return nil, err
+		}
+		if r, ok := n.Object.(*ast.ReferenceNode); ok && r.Name == "this" && n.Name != "bytes" {
+			l.asset = true // Only assets have fields other than bytes
+		}
+		return &property{object: obj, name: n.Name}, nil
+	case *ast.ConditionalNode:
+		c, err := l.expressions([]ast.Node{n.Condition, n.TrueExpression, n.FalseExpression})
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case isBool(n.TrueExpression, true):
+			return &operator{op: "||", args: []expression{c[0], c[2]}}, nil
+		case isBool(n.FalseExpression, false):
+			return &operator{op: "&&", args: []expression{c[0], c[1]}}, nil
+		default:
+			return &condition{condition: c[0], then: c[1], otherwise: c[2]}, nil
+		}
+	case *ast.AssignmentNode:
+		if e, ok, err := l.special(n); ok || err != nil {
+			return e, err
+		}
+		return l.block(n)
+	case *ast.FunctionDeclarationNode:
+		return l.block(n)
+	case *ast.FunctionCallNode:
+		return l.call(n)
+	default:
+		return nil, errors.Errorf("unsupported node type '%T'", node)
+	}
+}
+
+func (l *lifter) call(n *ast.FunctionCallNode) (expression, error) {
+	id := n.Function.Name()
+	_, user := n.Function.(ast.UserFunction)
+	if user && l.isUserFunction(id) {
+		args, err := l.expressions(n.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return &call{name: id, user: true, args: args}, nil
+	}
+	if items, ok := listItems(n); ok {
+		args, err := l.expressions(items)
+		if err != nil {
+			return nil, err
+		}
+		return &list{items: args}, nil
+	}
+	args, err := l.expressions(n.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	if op, ok := binaryOperators[id]; ok && len(args) == 2 {
+		return &operator{op: op, id: id, args: args}, nil
+	}
+	if op, ok := unaryOperators[id]; ok && len(args) == 1 {
+		return &operator{op: op, id: id, args: args}, nil
+	}
+	if id == "401" && len(args) == 2 {
+		return &index{list: args[0], index: args[1]}, nil
+	}
+	if k, err := strconv.Atoi(id); err == nil && !user && k >= 1300 && k-1300+2 == len(args) {
+		return &tuple{items: args}, nil
+	}
+	if name, ok := l.functions[id]; ok {
+		return &call{name: name, id: id, args: args}, nil
+	}
+	if user && l.objects.IsExist(id) {
+		return &call{name: id, args: args}, nil
+	}
+	return nil, errors.Errorf("unsupported function '%s'", id)
+}
+
+// special restores the expressions that are compiled into declarations of variables with reserved names.
+func (l *lifter) special(n *ast.AssignmentNode) (expression, bool, error) {
+	switch {
+	case strings.HasPrefix(n.Name, matchPrefix):
+		return l.match(n)
+	case n.Name == castName:
+		return l.cast(n)
+	case n.Name == "$l":
+		return l.fold(n)
+	default:
+		return nil, false, nil
+	}
+}
+
+func isSpecial(n *ast.AssignmentNode) bool {
+	switch {
+	case strings.HasPrefix(n.Name, matchPrefix):
+		return true
+	case n.Name == castName:
+		return castType(n) != ""
+	case n.Name == "$l":
+		_, _, ok := foldParameters(n)
+		return ok
+	default:
+		return false
+	}
+}
+
+// cast restores `value.as[T]` and `value.exactAs[T]` expressions.
+func (l *lifter) cast(n *ast.AssignmentNode) (expression, bool, error) {
+	t := castType(n)
+	if t == "" {
+		return nil, false, nil
+	}
+	v, err := l.expression(n.Expression)
+	if err != nil {
+		return nil, false, err
+	}
+	c := n.Block.(*ast.ConditionalNode)
+	return &cast{value: v, typ: t, exact: !isRef(c.FalseExpression, "unit")}, true, nil
+}
+
+func castType(n *ast.AssignmentNode) string {
+	c, ok := n.Block.(*ast.ConditionalNode)
+	if !ok || !isRef(c.TrueExpression, castName) {
+		return ""
+	}
+	return instanceCheck(c.Condition, castName)
+}
+
+// fold restores `FOLD<N>(list, start, function)` macro.
+func (l *lifter) fold(n *ast.AssignmentNode) (expression, bool, error) {
+	start, fn, ok := foldParameters(n)
+	if !ok {
+		return nil, false, nil
+	}
+	f2 := fn.Block.(*ast.FunctionDeclarationNode)
+	limit := f2.Block.(*ast.FunctionCallNode).Arguments[1].(*ast.LongNode).Value
+	name := fn.Body.(*ast.ConditionalNode).FalseExpression.(*ast.FunctionCallNode).Function.Name()
+	args, err := l.expressions([]ast.Node{n.Expression, start.Expression})
+	if err != nil {
+		return nil, false, err
+	}
+	return &fold{limit: limit, list: args[0], start: args[1], function: name}, true, nil
+}
+
+func foldParameters(n *ast.AssignmentNode) (*ast.AssignmentNode, *ast.FunctionDeclarationNode, bool) {
+	size, ok := n.Block.(*ast.AssignmentNode)
+	if !ok || size.Name != "$s" {
+		return nil, nil, false
+	}
+	start, ok := size.Block.(*ast.AssignmentNode)
+	if !ok || start.Name != "$acc0" {
+		return nil, nil, false
+	}
+	f1, ok := start.Block.(*ast.FunctionDeclarationNode)
+	if !ok || f1.Name != "$f0_1" {
+		return nil, nil, false
+	}
+	c, ok := f1.Body.(*ast.ConditionalNode)
+	if !ok {
+		return nil, nil, false
+	}
+	if fc, ok := c.FalseExpression.(*ast.FunctionCallNode); !ok || len(fc.Arguments) != 2 {
+		return nil, nil, false
+	}
+	f2, ok := f1.Block.(*ast.FunctionDeclarationNode)
+	if !ok || f2.Name != "$f0_2" {
+		return nil, nil, false
+	}
+	fc, ok := f2.Block.(*ast.FunctionCallNode)
+	if !ok || fc.Function.Name() != "$f0_2" || len(fc.Arguments) != 2 {
+		return nil, nil, false
+	}
+	if _, ok := fc.Arguments[1].(*ast.LongNode); !ok {
+		return nil, nil, false
+	}
+	return start, f1, true
+}
+
+// match restores match expression. The matched value is assigned to the variable with name "$matchN" followed by
+// the chain of conditions, one for each case.
+func (l *lifter) match(n *ast.AssignmentNode) (expression, bool, error) {
+	v, err := l.expression(n.Expression)
+	if err != nil {
+		return nil, false, err
+	}
+	m := &match{value: v}
+	node := n.Block
+	for {
+		c, ok := node.(*ast.ConditionalNode)
+		if !ok {
+			break
+		}
+		p, body, ok, err := l.pattern(n.Name, c)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			break
+		}
+		b, err := l.block(body)
+		if err != nil {
+			return nil, false, err
+		}
+		m.cases = append(m.cases, matchCase{pattern: p, body: b})
+		node = c.FalseExpression
+	}
+	if len(m.cases) == 0 || !isThrow(node, matchMessage) {
+		b, err := l.block(node)
+		if err != nil {
+			return nil, false, err
+		}
+		m.cases = append(m.cases, matchCase{pattern: &defaultPattern{}, body: b})
+	}
+	return m, true, nil
+}
+
+// pattern restores the pattern of the case from the condition, the body of the case is returned after bindings.
+func (l *lifter) pattern(m string, c *ast.ConditionalNode) (pattern, ast.Node, bool, error) {
+	if p, body, ok, err := l.objectPattern(m, c); ok || err != nil {
+		return p, body, ok, err
+	}
+	if p, body, ok, err := l.tuplePattern(m, c); ok || err != nil {
+		return p, body, ok, err
+	}
+	if types, ok := instanceChecks(c.Condition, m); ok {
+		p := &typePattern{types: types}
+		body := c.TrueExpression
+		if a, ok := body.(*ast.AssignmentNode); ok && a.Name != m && isRef(a.Expression, m) {
+			if err := checkIdentifier(a.Name); err != nil {
+				return nil, nil, false, err
+			}
+			p.name = a.Name
+			body = a.Block
+		}
+		return p, body, true, nil
+	}
+	if eq, ok := isCall(c.Condition, "0", 2); ok && isRef(eq.Arguments[1], m) {
+		v, err := l.expression(eq.Arguments[0])
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return &valuePattern{value: v}, c.TrueExpression, true, nil
+	}
+	return nil, nil, false, nil
+}
+
+func (l *lifter) objectPattern(m string, c *ast.ConditionalNode) (pattern, ast.Node, bool, error) {
+	oc, ok := c.Condition.(*ast.ConditionalNode)
+	if !ok || !isBool(oc.FalseExpression, false) {
+		return nil, nil, false, nil
+	}
+	t := instanceCheck(oc.Condition, m)
+	if t == "" {
+		return nil, nil, false, nil
+	}
+	a, ok := oc.TrueExpression.(*ast.AssignmentNode)
+	if !ok || a.Name != m || !isRef(a.Expression, m) {
+		return nil, nil, false, nil
+	}
+	tb, ok := c.TrueExpression.(*ast.AssignmentNode)
+	if !ok || tb.Name != m || !isRef(tb.Expression, m) {
+		return nil, nil, false, nil
+	}
+	p := &objectPattern{typ: t}
+	if node := a.Block; !isBool(node, true) {
+		for {
+			cc, ok := node.(*ast.ConditionalNode)
+			if !ok || !isBool(cc.FalseExpression, false) {
+				break
+			}
+			f, err := l.fieldValue(cc.Condition, m)
+			if err != nil || f == nil {
+				return nil, nil, false, err
+			}
+			p.fields = append(p.fields, *f)
+			node = cc.TrueExpression
+		}
+		f, err := l.fieldValue(node, m)
+		if err != nil || f == nil {
+			return nil, nil, false, err
+		}
+		p.fields = append(p.fields, *f)
+	}
+	body := tb.Block
+	for {
+		b, ok := body.(*ast.AssignmentNode)
+		if !ok {
+			break
+		}
+		pn, ok := b.Expression.(*ast.PropertyNode)
+		if !ok || !isRef(pn.Object, m) {
+			break
+		}
+		if err := checkIdentifier(b.Name); err != nil {
+			return nil, nil, false, err
+		}
+		p.fields = append(p.fields, fieldPattern{field: pn.Name, name: b.Name})
+		body = b.Block
+	}
+	return p, body, true, nil
+}
+
+func (l *lifter) fieldValue(node ast.Node, m string) (*fieldPattern, error) {
+	eq, ok := isCall(node, "0", 2)
+	if !ok {
+		return nil, nil
+	}
+	pn, ok := eq.Arguments[1].(*ast.PropertyNode)
+	if !ok || !isRef(pn.Object, m) {
+		return nil, nil
+	}
+	v, err := l.expression(eq.Arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	return &fieldPattern{field: pn.Name, value: v}, nil
+}
+
+func (l *lifter) tuplePattern(m string, c *ast.ConditionalNode) (pattern, ast.Node, bool, error) {
+	tc, ok := c.Condition.(*ast.ConditionalNode)
+	if !ok || !isBool(tc.FalseExpression, false) {
+		return nil, nil, false, nil
+	}
+	var size int
+	if eq, ok := isCall(tc.TrueExpression, "0", 2); ok {
+		s, ok := isCall(eq.Arguments[0], "1350", 1)
+		n, okN := eq.Arguments[1].(*ast.LongNode)
+		if !ok || !okN || !isRef(s.Arguments[0], m) {
+			return nil, nil, false, nil
+		}
+		size = int(n.Value)
+	} else if t := instanceCheck(tc.TrueExpression, m); strings.HasPrefix(t, "(") {
+		size = tupleLength(t)
+	} else {
+		return nil, nil, false, nil
+	}
+	if size < 1 || size > stdlib.MaxTupleLength {
+		return nil, nil, false, nil
+	}
+	p := &tuplePattern{items: make([]tupleItem, size)}
+	if node := tc.Condition; !isBool(node, true) {
+		for {
+			cc, ok := node.(*ast.ConditionalNode)
+			if !ok || !isBool(cc.FalseExpression, false) {
+				break
+			}
+			if ok, err := l.tupleElement(p, cc.Condition, m); !ok || err != nil {
+				return nil, nil, false, err
+			}
+			node = cc.TrueExpression
+		}
+		if ok, err := l.tupleElement(p, node, m); !ok || err != nil {
+			return nil, nil, false, err
+		}
+	}
+	body := c.TrueExpression
+	for {
+		b, ok := body.(*ast.AssignmentNode)
+		if !ok {
+			break
+		}
+		i, ok := itemIndex(b.Expression, m)
+		if !ok || i > size {
+			break
+		}
+		if err := checkIdentifier(b.Name); err != nil {
+			return nil, nil, false, err
+		}
+		p.items[i-1].name = b.Name
+		body = b.Block
+	}
+	return p, body, true, nil
+}
+
+func (l *lifter) tupleElement(p *tuplePattern, node ast.Node, m string) (bool, error) {
+	fc, ok := node.(*ast.FunctionCallNode)
+	if !ok || len(fc.Arguments) != 2 {
+		return false, nil
+	}
+	switch fc.Function.Name() {
+	case "1":
+		i, ok := itemIndex(fc.Arguments[0], m)
+		t, okT := fc.Arguments[1].(*ast.StringNode)
+		if !ok || !okT || i > len(p.items) {
+			return false, nil
+		}
+		p.items[i-1].typ = t.Value
+		return true, nil
+	case "0":
+		i, ok := itemIndex(fc.Arguments[1], m)
+		if !ok || i > len(p.items) {
+			return false, nil
+		}
+		v, err := l.expression(fc.Arguments[0])
+		if err != nil {
+			return false, err
+		}
+		p.items[i-1].value = v
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// tupleLength returns the number of items of the tuple type.
+func tupleLength(t string) int {
+	depth, n := 0, 1
+	for _, c := range t {
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 1 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// itemIndex returns the index of the tuple item if node is the access to the item of the tuple in variable.
+func itemIndex(node ast.Node, variable string) (int, bool) {
+	p, ok := node.(*ast.PropertyNode)
+	if !ok || !isRef(p.Object, variable) || !strings.HasPrefix(p.Name, "_") {
+		return 0, false
+	}
+	i, err := strconv.Atoi(p.Name[1:])
+	if err != nil || i < 1 {
+		return 0, false
+	}
+	return i, true
+}
+
+// instanceChecks returns the types of the chain of instance checks of the variable, the first check is the innermost.
+func instanceChecks(node ast.Node, variable string) ([]string, bool) {
+	if t := instanceCheck(node, variable); t != "" {
+		return []string{t}, true
+	}
+	c, ok := node.(*ast.ConditionalNode)
+	if !ok || !isBool(c.TrueExpression, true) {
+		return nil, false
+	}
+	t := instanceCheck(c.Condition, variable)
+	if t == "" {
+		return nil, false
+	}
+	r, ok := instanceChecks(c.FalseExpression, variable)
+	if !ok {
+		return nil, false
+	}
+	return append(r, t), true
+}
+
+// instanceCheck returns the type name if node is the instance check of the variable.
+func instanceCheck(node ast.Node, variable string) string {
+	fc, ok := isCall(node, "1", 2)
+	if !ok || !isRef(fc.Arguments[0], variable) {
+		return ""
+	}
+	s, ok := fc.Arguments[1].(*ast.StringNode)
+	if !ok {
+		return ""
+	}
+	return s.Value
+}
+
+// strictCheck returns the node that follows the check of the strict variable.
+func strictCheck(node ast.Node, variable string) (ast.Node, bool) {
+	c, ok := node.(*ast.ConditionalNode)
+	if !ok || !isThrow(c.FalseExpression, strictMessage) {
+		return nil, false
+	}
+	eq, ok := isCall(c.Condition, "0", 2)
+	if !ok || !isRef(eq.Arguments[0], variable) || !isRef(eq.Arguments[1], variable) {
+		return nil, false
+	}
+	return c.TrueExpression, true
+}
+
+// listItems returns the items of the list built by the chain of `cons` calls that ends with `nil`.
+func listItems(n *ast.FunctionCallNode) ([]ast.Node, bool) {
+	var items []ast.Node
+	var node ast.Node = n
+	for {
+		fc, ok := isCall(node, "1100", 2)
+		if !ok {
+			break
+		}
+		items = append(items, fc.Arguments[0])
+		node = fc.Arguments[1]
+	}
+	return items, isRef(node, "nil")
+}
+
+func isCall(node ast.Node, id string, args int) (*ast.FunctionCallNode, bool) {
+	fc, ok := node.(*ast.FunctionCallNode)
+	if !ok || fc.Function.Name() != id || len(fc.Arguments) != args {
+		return nil, false
+	}
+	return fc, true
+}
+
+func isRef(node ast.Node, name string) bool {
+	r, ok := node.(*ast.ReferenceNode)
+	return ok && r.Name == name
+}
+
+func isBool(node ast.Node, v bool) bool {
+	b, ok := node.(*ast.BooleanNode)
+	return ok && b.Value == v
+}
+
+func isThrow(node ast.Node, message string) bool {
+	fc, ok := isCall(node, "2", 1)
+	if !ok {
+		return false
+	}
+	s, ok := fc.Arguments[0].(*ast.StringNode)
+	return ok && s.Value == message
+}
@@ -0,0 +1,713 @@
+package decompiler
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler/stdlib"
+	"github.com/wavesplatform/gowaves/pkg/ride/meta"
+)
+
+// maxInferencePasses limits the number of passes of arguments types inference. Each pass refines the types of
+// arguments of functions that depend on the arguments of other functions.
+const maxInferencePasses = 10
+
+// operandTypes are the types of operands of arithmetic, comparison and logical operators by their identifiers.
+var operandTypes = map[string]stdlib.Type{
+	"100": stdlib.IntType, "101": stdlib.IntType, "102": stdlib.IntType, "103": stdlib.IntType,
+	"104": stdlib.IntType, "105": stdlib.IntType, "106": stdlib.IntType, "-": stdlib.IntType,
+	"311": stdlib.BigIntType, "312": stdlib.BigIntType, "313": stdlib.BigIntType, "314": stdlib.BigIntType,
+	"315": stdlib.BigIntType, "318": stdlib.BigIntType, "319": stdlib.BigIntType, "320": stdlib.BigIntType,
+	"300": stdlib.StringType, "203": stdlib.ByteVectorType, "!": stdlib.BooleanType,
+}
+
+// usage collects the types an argument of user function is used with.
+type usage struct {
+	calls  []stdlib.Type         // Types of values passed to the function, nil for values of unknown type
+	checks []stdlib.Type         // Types the argument is matched against
+	hints  []stdlib.Type         // Types expected by functions and operators the argument is passed to
+	items  map[int][]stdlib.Type // Types expected for the items of the argument of tuple type
+}
+
+func (u *usage) infer() stdlib.Type {
+	switch {
+	case len(u.calls) > 0:
+		for _, c := range u.calls {
+			if isAny(c) {
+				return stdlib.AnyType // Only the argument of type Any accepts values of type Any
+			}
+		}
+		if r := joinKnown(append(u.calls, u.checks...)...); r != nil {
+			return r
+		}
+		fallthrough
+	case len(u.checks) > 0:
+		if r := joinKnown(u.checks...); r != nil {
+			return r
+		}
+		fallthrough
+	default:
+		if r := narrowest(u.hints); r != nil || len(u.items) == 0 {
+			return r
+		}
+		n := 0
+		for i := range u.items {
+			n = max(n, i)
+		}
+		r := stdlib.TupleType{Types: make([]stdlib.Type, n)}
+		for i := range r.Types {
+			if r.Types[i] = narrowest(u.items[i+1]); r.Types[i] == nil {
+				r.Types[i] = stdlib.AnyType
+			}
+		}
+		return r
+	}
+}
+
+// narrowest returns the hint that is accepted in place of all other hints or the union of hints if there is no such.
+func narrowest(hints []stdlib.Type) stdlib.Type {
+	for _, h := range hints {
+		accepted := true
+		for _, o := range hints {
+			if !o.EqualWithEntry(h) {
+				accepted = false
+				break
+			}
+		}
+		if accepted {
+			return h
+		}
+	}
+	return joinKnown(hints...)
+}
+
+type binding struct {
+	typ      stdlib.Type
+	usage    *usage    // Not nil for arguments of user functions
+	function *function // Not nil for user functions
+}
+
+type scope struct {
+	parent   *scope
+	bindings map[string]binding
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{parent: parent, bindings: make(map[string]binding)}
+}
+
+func (s *scope) lookup(name string) (binding, bool) {
+	for c := s; c != nil; c = c.parent {
+		if b, ok := c.bindings[name]; ok {
+			return b, true
+		}
+	}
+	return binding{}, false
+}
+
+// typer evaluates the types of expressions the same way as the compiler does and collects the usages of
+// arguments of user functions.
+type typer struct {
+	functions stdlib.FunctionsSignatures
+	objects   stdlib.ObjectsSignatures
+	types     map[string]stdlib.Type
+}
+
+// infer restores the types of arguments of user functions. The arguments of callable functions are taken from
+// meta if it is available.
+func infer(s *script) {
+	t := &typer{
+		functions: stdlib.FuncsByVersion()[s.version],
+		objects:   stdlib.ObjectsByVersion()[s.version],
+		types:     stdlib.DefaultTypes()[s.version],
+	}
+	fixed := make(map[*function]bool)
+	for _, c := range s.callables {
+		fixed[c.function] = c.meta
+	}
+	for range maxInferencePasses {
+		for _, f := range s.functions {
+			f.usages = make([]usage, len(f.args))
+		}
+		t.script(s)
+		changed := false
+		for _, f := range s.functions {
+			if fixed[f] {
+				continue
+			}
+			for i := range f.args {
+				nt := f.usages[i].infer()
+				if typeKey(nt) != typeKey(f.types[i]) {
+					f.types[i] = nt
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+}
+
+func (t *typer) script(s *script) {
+	global := t.builtins(s)
+	if !s.dApp {
+		t.expression(s.expression, global)
+		return
+	}
+	for _, d := range s.declarations {
+		t.declaration(d, global)
+	}
+	for _, c := range s.callables {
+		sc := newScope(global)
+		sc.bindings[c.parameter] = binding{typ: stdlib.SimpleType{Type: "Invocation"}}
+		t.function(c.function, sc)
+	}
+	if s.verifier != nil {
+		sc := newScope(global)
+		sc.bindings[s.verifier.parameter] = binding{typ: t.transaction()}
+		t.function(s.verifier.function, sc)
+	}
+}
+
+// builtins returns the scope of variables available in the script.
+func (t *typer) builtins(s *script) *scope {
+	sc := newScope(nil)
+	for i := 0; i < int(s.version); i++ {
+		for _, v := range stdlib.Vars().Vars[i].Append {
+			sc.bindings[v.Name] = binding{typ: v.Type}
+		}
+		for _, v := range stdlib.Vars().Vars[i].Remove {
+			delete(sc.bindings, v)
+		}
+	}
+	if !s.dApp {
+		sc.bindings["tx"] = binding{typ: t.transaction()}
+	}
+	if s.version >= ast.LibV4 {
+		if s.asset {
+			sc.bindings["this"] = binding{typ: stdlib.SimpleType{Type: "Asset"}}
+		} else {
+			sc.bindings["this"] = binding{typ: stdlib.SimpleType{Type: "Address"}}
+		}
+	}
+	return newScope(sc)
+}
+
+// owners returns the union of object types that have the field.
+func (t *typer) owners(field string) stdlib.Type {
+	names := make([]string, 0)
+	for name, info := range t.objects.Obj {
+		for _, f := range info.Fields {
+			if f.Name == field {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	r := stdlib.UnionType{Types: []stdlib.Type{}}
+	for _, n := range names {
+		r.AppendType(stdlib.SimpleType{Type: n})
+	}
+	return r.Simplify()
+}
+
+func (t *typer) transaction() stdlib.Type {
+	return stdlib.JoinTypes(t.types["Transaction"], stdlib.SimpleType{Type: "Order"})
+}
+
+func (t *typer) function(f *function, env *scope) {
+	sc := newScope(env)
+	for i, a := range f.args {
+		sc.bindings[a] = binding{typ: f.types[i], usage: &f.usages[i]}
+	}
+	f.result = t.expression(f.body, sc)
+}
+
+func (t *typer) declaration(d declaration, env *scope) {
+	switch d := d.(type) {
+	case *variable:
+		env.bindings[d.name] = binding{typ: t.expression(d.value, env)}
+	case *destructuring:
+		v := t.expression(d.value, env)
+		for i, n := range d.names {
+			env.bindings[n] = binding{typ: tupleField(v, i+1)}
+		}
+	case *function:
+		t.function(d, env)
+		env.bindings[d.name] = binding{function: d}
+	}
+}
+
+func (t *typer) expressions(es []expression, env *scope) []stdlib.Type {
+	r := make([]stdlib.Type, len(es))
+	for i, e := range es {
+		r[i] = t.expression(e, env)
+	}
+	return r
+}
+
+func (t *typer) expression(e expression, env *scope) stdlib.Type {
+	switch e := e.(type) {
+	case *literal:
+		switch e.node.(type) {
+		case *ast.LongNode:
+			return stdlib.IntType
+		case *ast.BooleanNode:
+			return stdlib.BooleanType
+		case *ast.StringNode:
+			return stdlib.StringType
+		default:
+			return stdlib.ByteVectorType
+		}
+	case *reference:
+		if b, ok := env.lookup(e.name); ok {
+			return b.typ
+		}
+		return nil
+	case *call:
+		return t.call(e, env)
+	case *operator:
+		return t.operator(e, env)
+	case *list:
+		if len(e.items) == 0 {
+			return stdlib.ListType{}
+		}
+		return stdlib.ListType{Type: join(t.expressions(e.items, env)...)}
+	case *tuple:
+		return stdlib.TupleType{Types: t.expressions(e.items, env)}
+	case *index:
+		l := t.expression(e.list, env)
+		t.hint(e.list, env, stdlib.ListType{Type: stdlib.AnyType})
+		t.hint(e.index, env, stdlib.IntType)
+		t.expression(e.index, env)
+		if lt, ok := l.(stdlib.ListType); ok {
+			return lt.Type
+		}
+		return nil
+	case *property:
+		o := t.expression(e.object, env)
+		if i, ok := tupleIndex(e.name); ok {
+			if f := tupleField(o, i); f != nil {
+				return f
+			}
+		} else {
+			t.hint(e.object, env, t.owners(e.name))
+		}
+		if f, ok := t.objects.GetField(o, e.name); ok {
+			return f
+		}
+		return nil
+	case *condition:
+		t.hint(e.condition, env, stdlib.BooleanType)
+		t.expression(e.condition, env)
+		return join(t.expression(e.then, env), t.expression(e.otherwise, env))
+	case *block:
+		sc := newScope(env)
+		for _, d := range e.declarations {
+			t.declaration(d, sc)
+		}
+		return t.expression(e.body, sc)
+	case *cast:
+		t.expression(e.value, env)
+		typ := parseType(e.typ)
+		if e.exact {
+			return typ
+		}
+		return stdlib.JoinTypes(typ, stdlib.SimpleType{Type: "Unit"})
+	case *fold:
+		return t.fold(e, env)
+	case *match:
+		return t.match(e, env)
+	default:
+		return nil
+	}
+}
+
+func (t *typer) call(e *call, env *scope) stdlib.Type {
+	args := t.expressions(e.args, env)
+	if e.user {
+		b, ok := env.lookup(e.name)
+		if !ok || b.function == nil {
+			return nil
+		}
+		f := b.function
+		for i, a := range e.args {
+			if i < len(f.usages) {
+				f.usages[i].calls = append(f.usages[i].calls, args[i])
+				t.hint(a, env, f.types[i])
+			}
+		}
+		return f.result
+	}
+	if t.objects.IsExist(e.name) {
+		if info := t.objects.Obj[e.name]; len(info.Fields) == len(e.args) {
+			for i, a := range e.args {
+				t.hint(a, env, info.Fields[i].Type)
+			}
+		}
+		return stdlib.SimpleType{Type: e.name}
+	}
+	var overloads []stdlib.FunctionParams
+	for _, o := range t.functions.Funcs[e.name] {
+		if o.ID.Name() == e.id && len(o.Arguments) == len(e.args) {
+			overloads = []stdlib.FunctionParams{o}
+			break
+		}
+		if len(o.Arguments) == len(e.args) {
+			overloads = append(overloads, o)
+		}
+	}
+	for i, a := range e.args {
+		if expected := commonArgument(overloads, i); expected != nil {
+			t.hint(a, env, expected)
+		}
+	}
+	for i, a := range args {
+		if a == nil {
+			args[i] = stdlib.AnyType // Values of unknown type are passed as values of type Any
+		}
+	}
+	if sig, ok := t.functions.Get(e.name, args); ok {
+		return sig.ReturnType
+	}
+	if len(overloads) > 0 {
+		return overloads[0].ReturnType
+	}
+	return nil
+}
+
+// commonArgument returns the type of i-th argument if it is the same for all overloads.
+func commonArgument(overloads []stdlib.FunctionParams, i int) stdlib.Type {
+	if len(overloads) == 0 {
+		return nil
+	}
+	r := overloads[0].Arguments[i]
+	for _, o := range overloads[1:] {
+		if typeString(o.Arguments[i]) != typeString(r) {
+			return nil
+		}
+	}
+	return r
+}
+
+func (t *typer) operator(e *operator, env *scope) stdlib.Type {
+	args := t.expressions(e.args, env)
+	operand := operandTypes[e.id]
+	if len(args) == 1 {
+		t.hint(e.args[0], env, operand)
+		if operand == nil {
+			return args[0]
+		}
+		return operand
+	}
+	l, r := args[0], args[1]
+	switch e.op {
+	case "||", "&&":
+		t.hint(e.args[0], env, stdlib.BooleanType)
+		t.hint(e.args[1], env, stdlib.BooleanType)
+		return join(l, r)
+	case "==", "!=":
+		t.hint(e.args[0], env, r)
+		t.hint(e.args[1], env, l)
+		return stdlib.BooleanType
+	case ">", ">=":
+		t.hint(e.args[0], env, operand)
+		t.hint(e.args[1], env, operand)
+		return stdlib.BooleanType
+	case "::":
+		if lt, ok := r.(stdlib.ListType); ok {
+			res := stdlib.ListType{Type: l}
+			res.AppendList(lt)
+			return res
+		}
+		return stdlib.ListType{Type: l}
+	case ":+":
+		if lt, ok := l.(stdlib.ListType); ok {
+			lt.AppendType(r)
+			return lt
+		}
+		return nil
+	case "++":
+		lt, okL := l.(stdlib.ListType)
+		rt, okR := r.(stdlib.ListType)
+		if okL && okR {
+			lt.AppendList(rt)
+			return lt
+		}
+		t.hint(e.args[0], env, r)
+		t.hint(e.args[1], env, l)
+		return join(l, r)
+	default:
+		t.hint(e.args[0], env, operand)
+		t.hint(e.args[1], env, operand)
+		return operand
+	}
+}
+
+func (t *typer) fold(e *fold, env *scope) stdlib.Type {
+	l := t.expression(e.list, env)
+	s := t.expression(e.start, env)
+	b, ok := env.lookup(e.function)
+	if !ok || b.function == nil || len(b.function.usages) != 2 {
+		return nil
+	}
+	f := b.function
+	t.hint(e.list, env, stdlib.ListType{Type: f.types[1]})
+	f.usages[0].calls = append(f.usages[0].calls, s)
+	if f.result != nil {
+		f.usages[0].calls = append(f.usages[0].calls, f.result)
+	}
+	if lt, ok := l.(stdlib.ListType); ok && lt.Type != nil {
+		f.usages[1].calls = append(f.usages[1].calls, lt.Type)
+	}
+	return f.result
+}
+
+func (t *typer) match(e *match, env *scope) stdlib.Type {
+	v := t.expression(e.value, env)
+	var u *usage
+	if r, ok := e.value.(*reference); ok {
+		if b, ok := env.lookup(r.name); ok {
+			u = b.usage
+		}
+	}
+	check := func(typ stdlib.Type) {
+		if u != nil {
+			u.checks = append(u.checks, typ)
+		}
+	}
+	results := make([]stdlib.Type, 0, len(e.cases))
+	for _, c := range e.cases {
+		sc := newScope(env)
+		switch p := c.pattern.(type) {
+		case *typePattern:
+			types := make([]stdlib.Type, len(p.types))
+			for i, n := range p.types {
+				types[i] = parseType(n)
+				check(types[i])
+			}
+			if p.name != "" {
+				sc.bindings[p.name] = binding{typ: stdlib.JoinTypes(types...)}
+			}
+		case *valuePattern:
+			t.expression(p.value, env)
+		case *objectPattern:
+			typ := stdlib.SimpleType{Type: p.typ}
+			check(typ)
+			for _, f := range p.fields {
+				if f.value != nil {
+					t.expression(f.value, env)
+					continue
+				}
+				ft, _ := t.objects.GetField(typ, f.field)
+				sc.bindings[f.name] = binding{typ: ft}
+			}
+		case *tuplePattern:
+			for i, it := range p.items {
+				switch {
+				case it.value != nil:
+					t.expression(it.value, env)
+				case it.name == "":
+				case it.typ != "":
+					sc.bindings[it.name] = binding{typ: parseType(it.typ)}
+				default:
+					sc.bindings[it.name] = binding{typ: tupleField(v, i+1)}
+				}
+			}
+		}
+		results = append(results, t.expression(c.body, sc))
+	}
+	return join(results...)
+}
+
+// hint records the type expected in place of the expression if it is an argument of user function.
+// The expected types of items are passed down to the items of list and tuple literals.
+func (t *typer) hint(e expression, env *scope, typ stdlib.Type) {
+	if typ == nil || isAny(typ) {
+		return
+	}
+	switch e := e.(type) {
+	case *reference:
+		if b, ok := env.lookup(e.name); ok && b.usage != nil {
+			b.usage.hints = append(b.usage.hints, typ)
+		}
+	case *list:
+		if lt, ok := typ.(stdlib.ListType); ok {
+			for _, it := range e.items {
+				t.hint(it, env, lt.Type)
+			}
+		}
+	case *tuple:
+		if tt, ok := typ.(stdlib.TupleType); ok && len(tt.Types) == len(e.items) {
+			for i, it := range e.items {
+				t.hint(it, env, tt.Types[i])
+			}
+		}
+	case *index:
+		t.hint(e.list, env, stdlib.ListType{Type: typ})
+	case *property:
+		r, ok := e.object.(*reference)
+		i, okI := tupleIndex(e.name)
+		if !ok || !okI || i > stdlib.MaxTupleLength {
+			return
+		}
+		if b, ok := env.lookup(r.name); ok && b.usage != nil {
+			if b.usage.items == nil {
+				b.usage.items = make(map[int][]stdlib.Type)
+			}
+			b.usage.items[i] = append(b.usage.items[i], typ)
+		}
+	}
+}
+
+func tupleIndex(name string) (int, bool) {
+	if !strings.HasPrefix(name, "_") {
+		return 0, false
+	}
+	i, err := strconv.Atoi(name[1:])
+	return i, err == nil && i > 0
+}
+
+// tupleField returns the type of i-th item of the tuple or of the union of tuples.
+func tupleField(t stdlib.Type, i int) stdlib.Type {
+	switch tt := t.(type) {
+	case stdlib.TupleType:
+		if i <= len(tt.Types) {
+			return tt.Types[i-1]
+		}
+	case stdlib.UnionType:
+		var r []stdlib.Type
+		for _, m := range tt.Types {
+			if f := tupleField(m, i); f != nil {
+				r = append(r, f)
+			}
+		}
+		if len(r) > 0 {
+			return join(r...)
+		}
+	}
+	return nil
+}
+
+// join returns the union of known types. The Unknown type is returned if all known types are Unknown and nil is
+// returned if there is no known types.
+func join(types ...stdlib.Type) stdlib.Type {
+	r := stdlib.UnionType{Types: []stdlib.Type{}}
+	known := false
+	for _, t := range types {
+		if t != nil {
+			r.AppendType(t)
+			known = true
+		}
+	}
+	switch {
+	case !known:
+		return nil
+	case len(r.Types) == 0:
+		return stdlib.ThrowType
+	default:
+		return r.Simplify()
+	}
+}
+
+// joinKnown returns the union of types except Any and Unknown, nil is returned if no types left.
+func joinKnown(types ...stdlib.Type) stdlib.Type {
+	known := make([]stdlib.Type, 0, len(types))
+	for _, t := range types {
+		if t != nil && !isAny(t) && !stdlib.ThrowType.Equal(t) {
+			known = append(known, t)
+		}
+	}
+	if len(known) == 0 {
+		return nil
+	}
+	return join(known...)
+}
+
+func isAny(t stdlib.Type) bool {
+	return t != nil && stdlib.AnyType.Equal(t)
+}
+
+// typeKey distinguishes unknown types from the Any type.
+func typeKey(t stdlib.Type) string {
+	if t == nil {
+		return ""
+	}
+	return typeString(t)
+}
+
+// parseType parses the type name, Any is returned for invalid names.
+func parseType(name string) (t stdlib.Type) {
+	defer func() {
+		if r := recover(); r != nil {
+			t = stdlib.AnyType
+		}
+	}()
+	return stdlib.ParseType(name)
+}
+
+// typeString returns the type the way it is written in source code.
+func typeString(t stdlib.Type) string {
+	switch tt := t.(type) {
+	case stdlib.SimpleType:
+		return tt.Type
+	case stdlib.UnionType:
+		members := make([]string, 0, len(tt.Types))
+		for _, m := range tt.Types {
+			if stdlib.ThrowType.Equal(m) {
+				continue
+			}
+			members = append(members, typeString(m))
+		}
+		if len(members) == 0 {
+			return "Any"
+		}
+		return strings.Join(members, "|")
+	case stdlib.ListType:
+		if tt.Type == nil {
+			return "List[Any]"
+		}
+		return "List[" + typeString(tt.Type) + "]"
+	case stdlib.TupleType:
+		items := make([]string, len(tt.Types))
+		for i, it := range tt.Types {
+			items[i] = typeString(it)
+		}
+		return "(" + strings.Join(items, ", ") + ")"
+	default:
+		return "Any"
+	}
+}
+
+// metaType converts the type of callable function argument from meta.
+func metaType(t meta.Type) stdlib.Type {
+	switch tt := t.(type) {
+	case meta.SimpleType:
+		switch tt {
+		case meta.Int:
+			return stdlib.IntType
+		case meta.Bytes:
+			return stdlib.ByteVectorType
+		case meta.Boolean:
+			return stdlib.BooleanType
+		case meta.String:
+			return stdlib.StringType
+		}
+	case meta.UnionType:
+		r := stdlib.UnionType{Types: []stdlib.Type{}}
+		for _, m := range tt {
+			r.AppendType(metaType(m))
+		}
+		return r.Simplify()
+	case meta.ListType:
+		return stdlib.ListType{Type: metaType(tt.Inner)}
+	}
+	return stdlib.AnyType
+}
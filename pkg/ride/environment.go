@@ -1,6 +1,7 @@
 package ride
 
 import (
+	goctx "context"
 	"fmt"
 	"math"
 
@@ -1051,6 +1052,7 @@ type EvaluationEnvironment struct {
 	isProtobufTransaction              bool
 	mds                                int
 	cc                                 complexityCalculator
+	tr                                 *Tracer
 }
 
 func bytesSizeCheckV1V2(l int) bool {
@@ -1281,10 +1283,44 @@ func (e *EvaluationEnvironment) SetEthereumInvoke(tx *proto.EthereumTransaction,
 	return nil
 }
 
+// SetInvokeCaller replaces the caller and its public key in the invocation set by SetInvoke.
+// The caller becomes the origin caller of the invocation as well.
+func (e *EvaluationEnvironment) SetInvokeCaller(caller proto.WavesAddress, callerPK []byte) error {
+	switch inv := e.inv.(type) {
+	case rideInvocationV3:
+		inv.caller, inv.callerPublicKey = rideAddress(caller), callerPK
+		e.inv = inv
+	case rideInvocationV4:
+		inv.caller, inv.callerPublicKey = rideAddress(caller), callerPK
+		e.inv = inv
+	case rideInvocationV5:
+		inv.caller, inv.callerPublicKey = rideAddress(caller), callerPK
+		inv.originCaller, inv.originCallerPublicKey = rideAddress(caller), rideByteVector(callerPK)
+		e.inv = inv
+	default:
+		return errors.Errorf("unexpected invocation type '%T'", e.inv)
+	}
+	return nil
+}
+
 func (e *EvaluationEnvironment) SetLimit(limit uint32) {
 	e.cc.setLimit(limit)
 }
 
+// SetContext makes the evaluation interruptible, it fails on the next evaluation step after the context is done.
+func (e *EvaluationEnvironment) SetContext(ctx goctx.Context) {
+	e.cc = contextComplexityCalculator{complexityCalculator: e.cc, ctx: ctx}
+}
+
+// SetTracer enables recording of evaluation steps by tree evaluator.
+func (e *EvaluationEnvironment) SetTracer(t *Tracer) {
+	e.tr = t
+}
+
+func (e *EvaluationEnvironment) tracer() *Tracer {
+	return e.tr
+}
+
 func (e *EvaluationEnvironment) timestamp() uint64 {
 	return e.time
 }
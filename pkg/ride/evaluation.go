@@ -0,0 +1,179 @@
+package ride
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mr-tron/base58"
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/proto/ethabi"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+)
+
+// Value is the representation of RIDE value that is suitable for JSON serialization.
+// Values of objects are represented with their text form.
+type Value struct {
+	Type  string `json:"type"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Evaluation is the result of expression evaluation.
+type Evaluation struct {
+	Value      Value
+	Actions    []proto.ScriptAction // Actions produced by the expression or collected in wrapped state
+	Complexity int
+}
+
+// NewEvaluation makes the Evaluation of the callable function result, the value is the second element
+// of the tuple returned by the function or unit.
+func NewEvaluation(r Result) Evaluation {
+	return Evaluation{Value: exportValue(r.userResult()), Actions: r.ScriptActions(), Complexity: r.Complexity()}
+}
+
+// EvaluateExpression evaluates the expression in the scope of script declarations, so the expression
+// can refer to global variables and functions of the script. Unlike the script, the expression may produce
+// a value of any type. Invocations of other DApps are allowed only if the script is a DApp.
+func EvaluateExpression(env environment, tree *ast.Tree, expr ast.Node) (Evaluation, error) {
+	s, err := newEvaluationScope(tree.LibVersion, env, tree.IsDApp())
+	if err != nil {
+		return Evaluation{}, EvaluationFailure.Wrap(err, "failed to create scope")
+	}
+	for _, declaration := range tree.Declarations {
+		if err := s.declare(declaration); err != nil {
+			return Evaluation{}, EvaluationFailure.Wrap(err, "invalid declaration")
+		}
+	}
+	e := &treeEvaluator{dapp: tree.IsDApp(), f: expr, s: s, env: env, tr: tracer(env)}
+	r, err := e.walk(e.f)
+	if err != nil {
+		return Evaluation{}, EvaluationErrorSetComplexity(err, e.complexity())
+	}
+	return Evaluation{Value: exportValue(r), Actions: wrappedStateActions(env.state()), Complexity: e.complexity()}, nil
+}
+
+func exportValue(r rideType) Value {
+	switch v := r.(type) {
+	case nil, rideUnit:
+		return Value{Type: unitTypeName}
+	case rideBoolean:
+		return Value{Type: v.instanceOf(), Value: bool(v)}
+	case rideInt:
+		return Value{Type: v.instanceOf(), Value: int64(v)}
+	case rideBigInt:
+		return Value{Type: v.instanceOf(), Value: v.v.String()}
+	case rideString:
+		return Value{Type: v.instanceOf(), Value: string(v)}
+	case rideByteVector:
+		return Value{Type: v.instanceOf(), Value: base58.Encode(v)}
+	case rideAddress:
+		return Value{Type: v.instanceOf(), Value: proto.WavesAddress(v).String()}
+	case rideAlias:
+		return Value{Type: v.instanceOf(), Value: proto.Alias(v).String()}
+	case rideList:
+		items := make([]Value, len(v))
+		for i, item := range v {
+			items[i] = exportValue(item)
+		}
+		return Value{Type: v.instanceOf(), Value: items}
+	case rideTuple:
+		items := make([]Value, v.size())
+		for i := range items {
+			item, err := v.get(fmt.Sprintf("_%d", i+1))
+			if err != nil {
+				return Value{Type: r.instanceOf(), Value: r.String()}
+			}
+			items[i] = exportValue(item)
+		}
+		return Value{Type: r.instanceOf(), Value: items}
+	default:
+		return Value{Type: r.instanceOf(), Value: r.String()}
+	}
+}
+
+// NewABIValues converts the value returned by the callable function to the Ethereum ABI function outputs.
+// Unit is converted to no outputs, elements of a tuple become separate outputs, any other value is the only output.
+func NewABIValues(r Result) ([]ethabi.DataType, error) {
+	v, err := abiValue(r.userResult())
+	if err != nil {
+		return nil, err
+	}
+	switch r.userResult().(type) {
+	case nil, rideUnit, rideTuple:
+		return v.(ethabi.Tuple), nil
+	default:
+		return []ethabi.DataType{v}, nil
+	}
+}
+
+// abiValue converts the RIDE value to the ABI value. Addresses are represented by their bytes, aliases and
+// named types by their names, objects and tuples as ABI tuples of their fields and elements, unit as empty tuple.
+func abiValue(r rideType) (ethabi.DataType, error) {
+	switch v := r.(type) {
+	case nil, rideUnit:
+		return ethabi.Tuple{}, nil
+	case rideBoolean:
+		return ethabi.Bool(v), nil
+	case rideInt:
+		return ethabi.Int(v), nil
+	case rideBigInt:
+		return ethabi.BigInt{V: v.v}, nil
+	case rideString:
+		return ethabi.String(v), nil
+	case rideByteVector:
+		return ethabi.Bytes(v), nil
+	case rideAddress:
+		return ethabi.Bytes(proto.WavesAddress(v).Bytes()), nil
+	case rideAddressLike:
+		return ethabi.Bytes(v), nil
+	case rideAlias:
+		return ethabi.String(proto.Alias(v).String()), nil
+	case rideNamedType:
+		return ethabi.String(v.name), nil
+	case rideList:
+		items := make(ethabi.List, len(v))
+		for i, item := range v {
+			a, err := abiValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = a
+		}
+		return items, nil
+	case rideTuple:
+		items := make(ethabi.Tuple, v.size())
+		for i := range items {
+			item, err := v.get(fmt.Sprintf("_%d", i+1))
+			if err != nil {
+				return nil, err
+			}
+			if items[i], err = abiValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return abiObjectValue(r)
+	}
+}
+
+// abiObjectValue converts the object to the tuple of its fields in the order of the object constructor arguments.
+// Fields of generated objects are named after the properties of objects and declared in the constructor order.
+func abiObjectValue(r rideType) (ethabi.DataType, error) {
+	t := reflect.TypeOf(r)
+	if t.Kind() != reflect.Struct {
+		return nil, errors.Errorf("value of type '%s' can't be converted to ABI value", r.instanceOf())
+	}
+	fields := make(ethabi.Tuple, t.NumField())
+	for i := range fields {
+		f, err := r.get(t.Field(i).Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value of type '%s' can't be converted to ABI value", r.instanceOf())
+		}
+		if fields[i], err = abiValue(f); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
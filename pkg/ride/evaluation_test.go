@@ -0,0 +1,96 @@
+package ride
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/proto/ethabi"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	ridec "github.com/wavesplatform/gowaves/pkg/ride/compiler"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	dApp := newTestAccount(t, "DAPP")
+	sender := newTestAccount(t, "SENDER")
+	for _, test := range []struct {
+		expr       string
+		value      Value
+		complexity int
+		fails      bool
+	}{
+		{"f(2)", Value{Type: "Int", Value: int64(7)}, 1, false},
+		{"getIntegerValue(this, \"k\") + x", Value{Type: "Int", Value: int64(47)}, 11, false},
+		{"toBigInt(x) * toBigInt(x)", Value{Type: "BigInt", Value: "25"}, 66, false},
+		{"[\"a\", toString(x)]", Value{Type: "List[Any]", Value: []Value{
+			{Type: "String", Value: "a"}, {Type: "String", Value: "5"},
+		}}, 3, false},
+		{"(x > 1, base58'2')", Value{Type: "(Boolean, ByteVector)", Value: []Value{
+			{Type: "Boolean", Value: true}, {Type: "ByteVector", Value: "2"},
+		}}, 2, false},
+		{"unit", Value{Type: "Unit"}, 0, false},
+		{"loop(x)", Value{Type: "Int", Value: int64(78)}, 93, false},
+		{"throw(\"boom\")", Value{}, 0, true},
+		{"loop(loop(x))", Value{}, 0, true}, // Complexity limit exceeded
+	} {
+		t.Run(test.expr, func(t *testing.T) {
+			src := `
+			{-# STDLIB_VERSION 6 #-}
+			{-# CONTENT_TYPE DAPP #-}
+			{-# SCRIPT_TYPE ACCOUNT #-}
+			let x = 5
+			func f(a: Int) = a + x
+			func sum(a: Int, b: Int) = a + b + size(toString(a))
+			func loop(a: Int) = FOLD<20>([1, 2, 3, 4, 5, 6, 7, 8, 9, 10], a, sum)
+			func expression() = ` + test.expr
+			tree, errs := ridec.CompileToTree(src)
+			require.Empty(t, errs)
+			last := len(tree.Declarations) - 1
+			expr, ok := tree.Declarations[last].(*ast.FunctionDeclarationNode)
+			require.True(t, ok)
+			tree.Declarations = tree.Declarations[:last]
+
+			env := newTestEnv(t).withLibVersion(tree.LibVersion).withComplexityLimit(100).withRideV6Activated().
+				withSender(sender).withThis(dApp).withDApp(dApp).withInvocation("expression").
+				withDataEntries(dApp, &proto.IntegerDataEntry{Key: "k", Value: 42}).withWrappedState()
+			ev, err := EvaluateExpression(env.toEnv(), tree, expr.Body)
+			if test.fails {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.value, ev.Value)
+			assert.Equal(t, test.complexity, ev.Complexity)
+			assert.Empty(t, ev.Actions)
+		})
+	}
+}
+
+func TestNewABIValues(t *testing.T) {
+	addr := newTestAccount(t, "DAPP").address()
+	pair, err := newTuple2(nil, rideString("a"), rideList{rideInt(1), rideUnit{}})
+	require.NoError(t, err)
+	asset := newRideAssetV3(rideByteVector{1}, rideByteVector{2}, 3, 4, rideAddress(addr), true, false, true)
+	for _, test := range []struct {
+		value    rideType
+		expected []ethabi.DataType
+	}{
+		{rideUnit{}, []ethabi.DataType{}},
+		{rideInt(-5), []ethabi.DataType{ethabi.Int(-5)}},
+		{toRideBigInt(25), []ethabi.DataType{ethabi.BigInt{V: big.NewInt(25)}}},
+		{rideAddress(addr), []ethabi.DataType{ethabi.Bytes(addr.Bytes())}},
+		{rideNamedType{name: "Ceiling"}, []ethabi.DataType{ethabi.String("Ceiling")}},
+		{pair, []ethabi.DataType{ethabi.String("a"), ethabi.List{ethabi.Int(1), ethabi.Tuple{}}}},
+		{asset, []ethabi.DataType{ethabi.Tuple{
+			ethabi.Bytes{1}, ethabi.Bytes{2}, ethabi.Int(3), ethabi.Int(4), ethabi.Bytes(addr.Bytes()),
+			ethabi.Bool(true), ethabi.Bool(false), ethabi.Bool(true),
+		}}},
+	} {
+		values, err := NewABIValues(DAppResult{param: test.value})
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, values)
+	}
+}
@@ -0,0 +1,262 @@
+package ride
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+)
+
+// ExecutionMode selects the engine that evaluates scripts.
+type ExecutionMode byte
+
+const (
+	TreeExecution ExecutionMode = iota // Scripts are evaluated by tree evaluator
+	VMExecution                        // Scripts are compiled and evaluated by bytecode VM
+	DiffExecution                      // Scripts are evaluated by both engines, results of tree evaluator are used
+)
+
+func (m ExecutionMode) String() string {
+	switch m {
+	case TreeExecution:
+		return "tree"
+	case VMExecution:
+		return "vm"
+	case DiffExecution:
+		return "diff"
+	default:
+		return fmt.Sprintf("unknown(%d)", m)
+	}
+}
+
+// ParseExecutionMode returns the ExecutionMode by its name.
+func ParseExecutionMode(s string) (ExecutionMode, error) {
+	switch s {
+	case "tree":
+		return TreeExecution, nil
+	case "vm":
+		return VMExecution, nil
+	case "diff":
+		return DiffExecution, nil
+	default:
+		return 0, errors.Errorf("unknown execution mode '%s'", s)
+	}
+}
+
+// Program is a script tree accompanied with the code of bytecode VM.
+// The code is compiled on the first demand and shared between evaluations.
+type Program struct {
+	Tree *ast.Tree
+
+	once   sync.Once
+	script RideScript
+	err    error
+}
+
+func NewProgram(tree *ast.Tree) *Program {
+	return &Program{Tree: tree}
+}
+
+// Script returns the compiled script or an error if the tree can't be compiled.
+func (p *Program) Script() (RideScript, error) {
+	p.once.Do(func() {
+		p.script, p.err = Compile(p.Tree)
+	})
+	return p.script, p.err
+}
+
+// Divergence describes a difference between results of tree evaluator and bytecode VM.
+type Divergence struct {
+	Script   string // Address of DApp or ID of asset
+	Function string // Name of callable function, empty for verifier
+	Reason   string
+}
+
+// Executor evaluates verifiers and callable functions with the selected engine.
+// The nil Executor evaluates scripts with tree evaluator.
+type Executor struct {
+	mode   ExecutionMode
+	report func(Divergence)
+}
+
+// NewExecutor creates the Executor, report function is called on divergences found in DiffExecution mode.
+func NewExecutor(mode ExecutionMode, report func(Divergence)) *Executor {
+	return &Executor{mode: mode, report: report}
+}
+
+func (e *Executor) Mode() ExecutionMode {
+	if e == nil {
+		return TreeExecution
+	}
+	return e.mode
+}
+
+func (e *Executor) CallVerifier(env environment, p *Program) (Result, error) {
+	tree := func() (Result, error) { return CallVerifier(env, p.Tree) }
+	s, ok := e.script(p)
+	if !ok {
+		return tree()
+	}
+	vm := func() (Result, error) { return runVerifier(env, s) }
+	if e.mode == VMExecution {
+		return vm()
+	}
+	return e.compare(env, "", vm, tree)
+}
+
+func (e *Executor) CallFunction(env environment, p *Program, fc proto.FunctionCall) (Result, error) {
+	tree := func() (Result, error) { return CallFunction(env, p.Tree, fc) }
+	s, ok := e.script(p)
+	if !ok {
+		return tree()
+	}
+	vm := func() (Result, error) { return callFunction(env, s, fc) }
+	switch {
+	case e.mode == VMExecution:
+		return vm()
+	case s.prog().invokes():
+		// Nested invocations modify the state, so the script can't be evaluated twice
+		return tree()
+	default:
+		return e.compare(env, fc.Name(), vm, tree)
+	}
+}
+
+func (e *Executor) CallExpression(env environment, p *Program) (Result, error) {
+	tree := func() (Result, error) { return CallExpression(env, p.Tree) }
+	s, ok := e.script(p)
+	if !ok {
+		return tree()
+	}
+	vm := func() (Result, error) { return callExpression(env, s) }
+	switch {
+	case e.mode == VMExecution:
+		return vm()
+	case s.prog().invokes():
+		// Nested invocations modify the state, so the script can't be evaluated twice
+		return tree()
+	default:
+		return e.compare(env, "", vm, tree)
+	}
+}
+
+// script returns the compiled script if it has to be evaluated by VM.
+// Scripts that fail to compile are evaluated by tree evaluator.
+func (e *Executor) script(p *Program) (RideScript, bool) {
+	if e.Mode() == TreeExecution {
+		return nil, false
+	}
+	s, err := p.Script()
+	if err != nil {
+		return nil, false
+	}
+	return s, true
+}
+
+// compare evaluates the script with VM on the copy of complexity calculator and with tree evaluator
+// on the original complexity calculator. The result of tree evaluator is returned.
+func (e *Executor) compare(env environment, function string, vm, tree func() (Result, error)) (Result, error) {
+	cc := env.complexityCalculator()
+	env.setComplexityCalculator(cc.clone())
+	vr, vErr := vm()
+	vc := env.complexityCalculator().complexity()
+	env.setComplexityCalculator(cc)
+	tr, tErr := tree()
+	if reason := divergence(vr, vErr, vc, tr, tErr, cc.complexity()); reason != "" && e.report != nil {
+		e.report(Divergence{Script: scriptName(env), Function: function, Reason: reason})
+	}
+	return tr, tErr
+}
+
+func divergence(vr Result, vErr error, vc int, tr Result, tErr error, tc int) string {
+	switch {
+	case (vErr != nil) != (tErr != nil):
+		return fmt.Sprintf("errors differ: vm '%v', tree '%v'", vErr, tErr)
+	case vc != tc:
+		return fmt.Sprintf("complexities differ: vm %d, tree %d", vc, tc)
+	case vErr != nil:
+		vt, tt := GetEvaluationErrorType(vErr), GetEvaluationErrorType(tErr)
+		if vt != tt {
+			return fmt.Sprintf("error types differ: vm %d, tree %d", vt, tt)
+		}
+		// Messages of untyped errors and evaluation failures are not stored, because such errors reject transactions
+		if vt != Undefined && vt != EvaluationFailure && vErr.Error() != tErr.Error() {
+			return fmt.Sprintf("error messages differ: vm '%s', tree '%s'", vErr.Error(), tErr.Error())
+		}
+		if !reflect.DeepEqual(EvaluationErrorCallStack(vErr), EvaluationErrorCallStack(tErr)) {
+			return "error call stacks differ"
+		}
+		return ""
+	case vr.Result() != tr.Result():
+		return fmt.Sprintf("results differ: vm %t, tree %t", vr.Result(), tr.Result())
+	case vr.Complexity() != tr.Complexity():
+		return fmt.Sprintf("result complexities differ: vm %d, tree %d", vr.Complexity(), tr.Complexity())
+	case !reflect.DeepEqual(vr.ScriptActions(), tr.ScriptActions()):
+		return "script actions differ"
+	case !reflect.DeepEqual(vr.userResult(), tr.userResult()):
+		return "user results differ"
+	default:
+		return ""
+	}
+}
+
+func scriptName(env environment) string {
+	switch t := env.this().(type) {
+	case rideAddress:
+		return proto.WavesAddress(t).String()
+	case nil:
+		return ""
+	default:
+		if id, err := t.get(idField); err == nil {
+			if b, ok := id.(rideByteVector); ok {
+				s, _ := b.stringAndPrefix()
+				return s
+			}
+		}
+		return ""
+	}
+}
+
+// callFunction evaluates the callable function of the compiled DApp the same way as CallFunction does.
+func callFunction(env environment, s RideScript, fc proto.FunctionCall) (Result, error) {
+	name := fc.Name()
+	arguments, err := convertProtoArguments(fc.Arguments())
+	if err != nil {
+		return nil, EvaluationFailure.Wrapf(err, "failed to call function '%s'", name)
+	}
+	dApp, ok := s.(*DAppScript)
+	if !ok {
+		err = EvaluationFailure.Errorf("unable to call function '%s' on simple script", name)
+		return nil, EvaluationFailure.Wrapf(err, "failed to call function '%s'", name)
+	}
+	m, entry, err := dApp.function(env, name, arguments)
+	if err != nil {
+		return nil, EvaluationFailure.Wrapf(err, "failed to call function '%s'", name)
+	}
+	var r Result
+	v, err := m.run(entry)
+	if err == nil {
+		r, err = evaluationResult(env, v)
+	}
+	return functionResult(env, dApp.LibVersion, name, r, err)
+}
+
+// callExpression evaluates the compiled expression of InvokeExpression transaction the same way as CallExpression does.
+func callExpression(env environment, s RideScript) (Result, error) {
+	expr, ok := s.(*SimpleScript)
+	if !ok {
+		err := EvaluationFailure.New("unable to call DApp as expression")
+		return nil, RuntimeError.Wrap(err, "failed to call expression")
+	}
+	m, entry := expr.expression(env)
+	v, err := m.run(entry)
+	if err != nil {
+		return nil, err
+	}
+	r, err := evaluationResult(env, v)
+	return expressionResult(env, expr.LibVersion, r, err)
+}
@@ -0,0 +1,199 @@
+package ride
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	ridec "github.com/wavesplatform/gowaves/pkg/ride/compiler"
+)
+
+func TestParseExecutionMode(t *testing.T) {
+	for _, m := range []ExecutionMode{TreeExecution, VMExecution, DiffExecution} {
+		pm, err := ParseExecutionMode(m.String())
+		require.NoError(t, err)
+		assert.Equal(t, m, pm)
+	}
+	_, err := ParseExecutionMode("jit")
+	assert.Error(t, err)
+}
+
+func TestExecutorVerifierDivergence(t *testing.T) {
+	for _, test := range []struct {
+		comment string
+		source  string
+		limit   int
+		failed  bool
+	}{
+		{"dynamic scope of user functions", `
+			{-# STDLIB_VERSION 3 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			let ref = 999
+			func g(a: Int) = ref
+			func f(ref: Int) = g(ref)
+			f(1) == 999`, 2000, false},
+		{"lazy values", `
+			{-# STDLIB_VERSION 6 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			let x = throw("unused")
+			let y = { let z = 2; z * z }
+			func inc(v: Int) = { let r = v + 1; r }
+			inc(y) == y + 1 && inc(inc(y)) == 6`, 2000, false},
+		{"user error", `
+			{-# STDLIB_VERSION 6 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			func check(v: Int) = if (v > 0) then true else throw("negative " + toString(v))
+			let a = -1
+			check(a + 0)`, 2000, true},
+		{"complexity limit", `
+			{-# STDLIB_VERSION 6 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			let h = sha256(base58'') == sha256(base58'') && keccak256(base58'') == keccak256(base58'')
+			h`, 100, true},
+		{"property of list item", `
+			{-# STDLIB_VERSION 5 #-}
+			{-# CONTENT_TYPE EXPRESSION #-}
+			let t = [(1, "a"), (2, "b")]
+			t[1]._2 == "b"`, 2000, false},
+	} {
+		tree, errs := ridec.CompileToTree(test.source)
+		require.Empty(t, errs, test.comment)
+		var divergences []Divergence
+		ex := NewExecutor(DiffExecution, func(d Divergence) { divergences = append(divergences, d) })
+
+		env := newTestEnv(t).withLibVersion(tree.LibVersion).withComplexityLimit(test.limit).toEnv()
+		res, err := ex.CallVerifier(env, NewProgram(tree))
+		assert.Empty(t, divergences, test.comment)
+		assert.Equal(t, test.failed, err != nil, test.comment)
+
+		vmEnv := newTestEnv(t).withLibVersion(tree.LibVersion).withComplexityLimit(test.limit).toEnv()
+		vmRes, vmErr := NewExecutor(VMExecution, nil).CallVerifier(vmEnv, NewProgram(tree))
+		assert.Equal(t, res, vmRes, test.comment)
+		if test.failed {
+			require.Error(t, vmErr, test.comment)
+			assert.Equal(t, err.Error(), vmErr.Error(), test.comment)
+			assert.Equal(t, EvaluationErrorCallStack(err), EvaluationErrorCallStack(vmErr), test.comment)
+		}
+	}
+}
+
+func TestExecutorFunctionDivergence(t *testing.T) {
+	src := `
+		{-# STDLIB_VERSION 5 #-}
+		{-# CONTENT_TYPE DAPP #-}
+		{-# SCRIPT_TYPE ACCOUNT #-}
+		let prefix = "k_"
+		func key(n: String) = prefix + n
+
+		@Callable(i)
+		func store(n: String, v: Int) = {
+			let caller = toBase58String(i.caller.bytes)
+			([IntegerEntry(key(n), v), StringEntry(key("caller"), caller)], v)
+		}
+
+		@Callable(i)
+		func fail(v: Int) = if (v > 0) then throw("positive") else []
+
+		@Verifier(tx)
+		func verify() = sigVerify(tx.bodyBytes, tx.proofs[0], tx.senderPublicKey)
+	`
+	tree, errs := ridec.CompileToTree(src)
+	require.Empty(t, errs)
+	dApp := newTestAccount(t, "DAPP")
+	sender := newTestAccount(t, "SENDER")
+
+	for _, test := range []struct {
+		function string
+		args     proto.Arguments
+		failed   bool
+	}{
+		{"store", proto.Arguments{proto.NewStringArgument("a"), proto.NewIntegerArgument(42)}, false},
+		{"fail", proto.Arguments{proto.NewIntegerArgument(1)}, true},
+		{"fail", proto.Arguments{proto.NewIntegerArgument(0)}, false},
+		{"fail", proto.Arguments{}, true},
+		{"missing", proto.Arguments{}, true},
+	} {
+		newEnv := func() environment {
+			return newTestEnv(t).withLibVersion(tree.LibVersion).withComplexityLimit(10000).
+				withSender(sender).withThis(dApp).withDApp(dApp).withInvocation(test.function).
+				withWrappedState().toEnv()
+		}
+		var divergences []Divergence
+		ex := NewExecutor(DiffExecution, func(d Divergence) { divergences = append(divergences, d) })
+		fc := proto.NewFunctionCall(test.function, test.args)
+		res, err := ex.CallFunction(newEnv(), NewProgram(tree), fc)
+		assert.Empty(t, divergences, test.function)
+		assert.Equal(t, test.failed, err != nil, test.function)
+
+		vmRes, vmErr := NewExecutor(VMExecution, nil).CallFunction(newEnv(), NewProgram(tree), fc)
+		assert.Equal(t, res, vmRes, test.function)
+		assert.Equal(t, err != nil, vmErr != nil, test.function)
+	}
+}
+
+func TestExecutorReportsDivergence(t *testing.T) {
+	tree, errs := ridec.CompileToTree("{-# STDLIB_VERSION 6 #-}\n{-# CONTENT_TYPE EXPRESSION #-}\n1 + 1 == 2")
+	require.Empty(t, errs)
+	p := NewProgram(tree)
+	s, err := p.Script()
+	require.NoError(t, err)
+	// Break the compiled code to make the VM return a different result
+	s.prog().Constants[len(s.prog().Constants)-1] = rideInt(3)
+
+	var divergences []Divergence
+	ex := NewExecutor(DiffExecution, func(d Divergence) { divergences = append(divergences, d) })
+	acc := newTestAccount(t, "ACCOUNT")
+	env := newTestEnv(t).withLibVersion(tree.LibVersion).withComplexityLimit(2000).withThis(acc).toEnv()
+	res, err := ex.CallVerifier(env, p)
+	require.NoError(t, err)
+	assert.True(t, res.Result()) // Result of tree evaluator is returned
+	require.Len(t, divergences, 1)
+	assert.Equal(t, "results differ: vm false, tree true", divergences[0].Reason)
+	assert.Equal(t, acc.address().String(), divergences[0].Script)
+	assert.Empty(t, divergences[0].Function)
+}
+
+func TestExecutorExpressionInvocation(t *testing.T) {
+	caller := newTestAccount(t, "SENDER")
+	dApp := newTestAccount(t, "DAPP")
+	expr, errs := ridec.CompileToTree(fmt.Sprintf(`
+		{-# STDLIB_VERSION 6 #-}
+		{-# CONTENT_TYPE EXPRESSION #-}
+		{-# SCRIPT_TYPE ACCOUNT #-}
+		invoke(Address(base58'%s'), "answer", [], []) == 42`, dApp.address().String()))
+	require.Empty(t, errs)
+	callee, errs := ridec.CompileToTree(`
+		{-# STDLIB_VERSION 6 #-}
+		{-# CONTENT_TYPE DAPP #-}
+		{-# SCRIPT_TYPE ACCOUNT #-}
+		@Callable(i)
+		func answer() = ([IntegerEntry("answer", 42)], 42)`)
+	require.Empty(t, errs)
+
+	newEnv := func() *testEnv {
+		return newTestEnv(t).withLibVersion(ast.LibV6).withComplexityLimit(10000).withRideV6Activated().
+			withSender(caller).withThis(caller).withDApp(caller).
+			withAdditionalDApp(dApp).withTree(dApp, callee).withInvocation("").
+			withWavesBalance(caller, 10000).withWavesBalance(dApp, 0).
+			withWrappedState()
+	}
+	treeEnv, vmEnv := newEnv(), newEnv()
+	res, err := NewExecutor(TreeExecution, nil).CallExpression(treeEnv.toEnv(), NewProgram(expr))
+	require.NoError(t, err)
+	vmRes, err := NewExecutor(VMExecution, nil).CallExpression(vmEnv.toEnv(), NewProgram(expr))
+	require.NoError(t, err)
+	assert.True(t, res.Result())
+	assert.Equal(t, res, vmRes)
+	assert.Equal(t, treeEnv.ws.act, vmEnv.ws.act)
+	assert.Len(t, vmEnv.ws.act, 1)
+
+	// Invocations are not allowed in verifiers.
+	for _, mode := range []ExecutionMode{TreeExecution, VMExecution} {
+		_, err = NewExecutor(mode, nil).CallVerifier(newEnv().toEnv(), NewProgram(expr))
+		assert.Error(t, err)
+	}
+}
@@ -73,6 +73,11 @@ func NewAbbreviations(names []string) Abbreviations {
 	}
 }
 
+// Names returns the original names of declarations in the order they were compacted.
+func (a *Abbreviations) Names() []string {
+	return a.names
+}
+
 func (a *Abbreviations) CompactToOriginal(compact string) (string, error) {
 	if n, ok := a.compact2original[compact]; ok {
 		return n, nil
@@ -1,22 +1,27 @@
 package ride
 
 // Operation code is 1 byte.
-// Parameter is 2 bytes length
+// Identifiers of constants, names and functions are 2 bytes length, code positions are 4 bytes length.
 
 const (
-	OpHalt         byte = iota //00 - Halts program execution. No parameters.
-	OpReturn                   //01 - Returns from declaration to stored position. No parameters.
+	OpHalt         byte = iota //00 - Halts program execution and returns the value from the top of stack. No parameters.
+	OpReturn                   //01 - Returns from let expression or user function body to stored position. No parameters.
 	OpPush                     //02 - Put constant on stack. One parameter: constant ID.
-	OpPop                      //03 - Removes value from stack. No parameters.
-	OpTrue                     //04 - Put True value on stack. No parameters.
-	OpFalse                    //05 - Put False value on stack. No parameters.
-	OpJump                     //06 - Moves instruction pointer to new position. One parameter: new position.
-	OpJumpIfFalse              //07 - Moves instruction pointer to new position if value on stack is False. One parameter: new position.
-	OpProperty                 //08 - Puts value of object's property on stack. One parameter: constant ID that holds name of the property.
-	OpExternalCall             //09 - Call a standard library function. Two parameters: function ID, number of arguments.
-	OpCall                     //0a - Call a function declared at given address. Two parameters: position of function declaration, number of arguments.
-	OpGlobal                   //0b - Load global constant. One parameter: global constant ID.
-	OpLoad                     //0c - Evaluates an expression that declared at address. One parameter: position of declaration.
-	OpLoadLocal                //0d - Load an argument of function call on stack. One parameter: argument number.
-	OpRef                      //0e - Put reference to expression/function on stack. One parameter: position of declaration.
+	OpTrue                     //03 - Put True value on stack. No parameters.
+	OpFalse                    //04 - Put False value on stack. No parameters.
+	OpCondition                //05 - Starts evaluation of conditional expression. No parameters.
+	OpJumpIfFalse              //06 - Removes value from stack and moves instruction pointer to new position if the value is False. One parameter: new position.
+	OpJump                     //07 - Moves instruction pointer to new position. One parameter: new position.
+	OpEndCondition             //08 - Finishes evaluation of conditional expression. No parameters.
+	OpLet                      //09 - Declares value in the current scope. Two parameters: name ID, position of the value expression.
+	OpEndLet                   //0a - Removes the last declared value from the current scope. No parameters.
+	OpFunction                 //0b - Declares user function. One parameter: function ID.
+	OpEndFunction              //0c - Removes the last declared user function. No parameters.
+	OpLoad                     //0d - Put value of reference on stack, evaluates the value expression if necessary. One parameter: name ID.
+	OpProperty                 //0e - Starts evaluation of object's property. One parameter: constant ID that holds name of the property.
+	OpGetProperty              //0f - Replaces object on stack with the value of the property. No parameters.
+	OpExternal                 //10 - Prepares a call of a standard library function. One parameter: native function ID.
+	OpExternalCall             //11 - Call a standard library function. Two parameters: native function ID, number of arguments.
+	OpPrepareCall              //12 - Prepares a call of a user function or a standard library function with the same name. Two parameters: name ID, native function ID.
+	OpCall                     //13 - Call a user function or a standard library function with the same name. Three parameters: name ID, number of arguments, native function ID.
 )
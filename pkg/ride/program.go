@@ -1,103 +1,166 @@
 package ride
 
 import (
-	"github.com/pkg/errors"
 	"github.com/wavesplatform/gowaves/pkg/ride/ast"
 )
 
+// callable describes an entry point of DApp script: verifier or callable function.
 type callable struct {
-	entryPoint    int
-	parameterName string
+	entryPoint int
+	parameter  uint16   // Name ID of the invocation parameter
+	arguments  []uint16 // Name IDs of the function arguments
 }
 
+// userFunction is a compiled declaration of user function.
+type userFunction struct {
+	name      uint16
+	arguments []uint16
+	body      int
+}
+
+// declaration is a global declaration of DApp script: value or user function.
+type declaration struct {
+	function   bool
+	id         uint16 // Name ID of the value or ID of the function
+	expression int    // Position of the value expression
+}
+
+// nativeFunction is a standard library function resolved for all evaluation modes.
+// Functions are indexed by invocation availability, costs are indexed by version of evaluator.
+type nativeFunction struct {
+	name      string
+	functions [2]rideFunction
+	costs     [2]int
+}
+
+func (f *nativeFunction) function(invocation bool) rideFunction {
+	if invocation {
+		return f.functions[1]
+	}
+	return f.functions[0]
+}
+
+func (f *nativeFunction) cost(rideV6 bool) (int, bool) {
+	var c int
+	if rideV6 {
+		c = f.costs[1]
+	} else {
+		c = f.costs[0]
+	}
+	return c, c >= 0
+}
+
+// program holds compiled code and linkage information shared by simple and DApp scripts.
+type program struct {
+	LibVersion ast.LibraryVersion
+	Code       []byte
+	Constants  []rideType
+	names      []string
+	globals    []rideConstructor // Constructors of global constants by name ID, nil if the name is not a constant
+	natives    []nativeFunction
+	functions  []userFunction
+}
+
+// invokes reports if the program is able to call other DApps.
+func (p *program) invokes() bool {
+	for _, f := range p.natives {
+		if f.name == "1020" || f.name == "1021" {
+			return true
+		}
+	}
+	return false
+}
+
+// RideScript is a compiled script that can be evaluated by the bytecode VM.
 type RideScript interface {
+	// Run evaluates the verifier of the script.
 	Run(env environment) (Result, error)
+	verifier(env environment) (*vm, int, error)
 	code() []byte
+	prog() *program
 }
 
 type SimpleScript struct {
-	LibVersion ast.LibraryVersion
+	program
 	EntryPoint int
-	Code       []byte
-	Constants  []rideType
 }
 
 func (s *SimpleScript) Run(env environment) (Result, error) {
-	fs, err := selectFunctions(s.LibVersion)
-	if err != nil {
-		return nil, RuntimeError.Wrap(err, "simple script execution failed")
-	}
-	gcs, err := selectConstants(s.LibVersion)
-	if err != nil {
-		return nil, RuntimeError.Wrap(err, "simple script execution failed")
-	}
-	np, err := selectFunctionNameProvider(s.LibVersion)
-	if err != nil {
-		return nil, RuntimeError.Wrap(err, "simple script execution failed")
-	}
-	m := vm{
-		env:          env,
-		code:         s.Code,
-		ip:           0,
-		constants:    s.Constants,
-		functions:    fs,
-		globals:      gcs,
-		stack:        make([]rideType, 0, 2),
-		calls:        make([]frame, 0, 2),
-		functionName: np,
-	}
-	r, err := m.run()
-	if err != nil {
-		return nil, errors.Wrap(err, "simple script execution failed")
-	}
-	return r, nil
+	return runVerifier(env, s)
+}
+
+func (s *SimpleScript) verifier(env environment) (*vm, int, error) {
+	return newVM(env, &s.program, false), s.EntryPoint, nil
+}
+
+// expression prepares the VM to evaluate the script as the expression of InvokeExpression transaction.
+func (s *SimpleScript) expression(env environment) (*vm, int) {
+	return newVM(env, &s.program, true), s.EntryPoint
 }
 
 func (s *SimpleScript) code() []byte {
 	return s.Code
 }
 
+func (s *SimpleScript) prog() *program {
+	return &s.program
+}
+
 type DAppScript struct {
-	LibVersion  ast.LibraryVersion
-	Code        []byte
-	Constants   []rideType
-	EntryPoints map[string]callable
+	program
+	EntryPoints  map[string]callable
+	Declarations []declaration
 }
 
 func (s *DAppScript) Run(env environment) (Result, error) {
-	if _, ok := s.EntryPoints[""]; !ok {
-		return nil, errors.Errorf("no verifier")
-	}
-	fs, err := selectFunctions(s.LibVersion)
-	if err != nil {
-		return nil, errors.Wrap(err, "script execution failed")
-	}
-	gcs, err := selectConstants(s.LibVersion)
-	if err != nil {
-		return nil, errors.Wrap(err, "script execution failed")
+	return runVerifier(env, s)
+}
+
+func (s *DAppScript) verifier(env environment) (*vm, int, error) {
+	verifier, ok := s.EntryPoints[""]
+	if !ok {
+		return nil, 0, EvaluationFailure.New("no verifier declaration")
 	}
-	np, err := selectFunctionNameProvider(s.LibVersion)
-	if err != nil {
-		return nil, errors.Wrap(err, "script execution failed")
+	m := newVM(env, &s.program, false) // Invocation is disabled for expression calls
+	m.declare(s.Declarations)
+	m.setParameter(verifier.parameter, newTx)
+	return m, verifier.entryPoint, nil
+}
+
+// function prepares the VM to evaluate the callable function with given name and arguments.
+func (s *DAppScript) function(env environment, name string, args []rideType) (*vm, int, error) {
+	fn, ok := s.EntryPoints[name]
+	if !ok || name == "" {
+		return nil, 0, EvaluationFailure.Errorf("function '%s' not found", name)
 	}
-	m := vm{
-		env:          env,
-		code:         s.Code,
-		ip:           0,
-		constants:    s.Constants,
-		functions:    fs,
-		globals:      gcs,
-		stack:        make([]rideType, 0, 2),
-		calls:        make([]frame, 0, 2),
-		functionName: np,
+	m := newVM(env, &s.program, true)
+	m.declare(s.Declarations)
+	m.setParameter(fn.parameter, newInvocation)
+	if l := len(args); l != len(fn.arguments) {
+		return nil, 0, EvaluationFailure.Errorf("invalid arguments count %d for function '%s'", l, name)
 	}
-	r, err := m.run()
-	if err != nil {
-		return nil, errors.Wrap(err, "script execution failed")
+	for i, arg := range args {
+		m.pushValue(fn.arguments[i], arg)
 	}
-	return r, nil
+	return m, fn.entryPoint, nil
 }
 
 func (s *DAppScript) code() []byte {
 	return s.Code
 }
+
+func (s *DAppScript) prog() *program {
+	return &s.program
+}
+
+func runVerifier(env environment, s RideScript) (Result, error) {
+	m, entry, err := s.verifier(env)
+	if err != nil {
+		return nil, RuntimeError.Wrap(err, "failed to call verifier")
+	}
+	r, err := m.run(entry)
+	if err != nil {
+		return nil, err
+	}
+	return evaluationResult(env, r)
+}
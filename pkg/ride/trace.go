@@ -0,0 +1,99 @@
+package ride
+
+// Kinds of steps recorded in the trace.
+const (
+	TraceStepCall   = "call"   // Call of system or user function
+	TraceStepLet    = "let"    // Evaluation of variable's expression on the first reference
+	TraceStepBranch = "branch" // Branch of conditional expression taken
+)
+
+// DefaultMaxTraceSteps is the number of steps the Tracer records by default, steps above the limit are dropped.
+const DefaultMaxTraceSteps = 100_000
+
+// TraceStep describes a single step of script evaluation.
+// The complexity of the step includes the complexity of nested steps which have greater depth and follow the step.
+type TraceStep struct {
+	Kind       string  `json:"kind"`
+	Name       string  `json:"name"`
+	Depth      int     `json:"depth"`
+	Args       []Value `json:"args,omitempty"`
+	Value      *Value  `json:"value,omitempty"`
+	Complexity int     `json:"complexity"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Trace is the list of evaluation steps in the order they were started.
+type Trace struct {
+	Steps     []TraceStep `json:"steps"`
+	Truncated bool        `json:"truncated"`
+}
+
+// Tracer records the steps of script evaluation by tree evaluator, the nil Tracer records nothing.
+// Tracer is set to the environment with EvaluationEnvironment.SetTracer.
+type Tracer struct {
+	max   int
+	depth int
+	trace Trace
+}
+
+// NewTracer creates the Tracer that records up to maxSteps steps.
+func NewTracer(maxSteps int) *Tracer {
+	return &Tracer{max: maxSteps, trace: Trace{Steps: make([]TraceStep, 0)}}
+}
+
+// Trace returns the recorded trace.
+func (t *Tracer) Trace() Trace {
+	if t == nil {
+		return Trace{}
+	}
+	return t.trace
+}
+
+// begin records the start of the step and returns its index or -1 if the step was dropped.
+func (t *Tracer) begin(kind, name string, args []rideType) int {
+	if t == nil {
+		return -1
+	}
+	depth := t.depth
+	t.depth++
+	if len(t.trace.Steps) >= t.max {
+		t.trace.Truncated = true
+		return -1
+	}
+	var values []Value
+	if len(args) > 0 {
+		values = make([]Value, len(args))
+		for i, a := range args {
+			values[i] = exportValue(a)
+		}
+	}
+	t.trace.Steps = append(t.trace.Steps, TraceStep{Kind: kind, Name: name, Depth: depth, Args: values})
+	return len(t.trace.Steps) - 1
+}
+
+// end completes the step started with begin.
+func (t *Tracer) end(i int, r rideType, complexity int, err error) {
+	if t == nil {
+		return
+	}
+	t.depth--
+	if i < 0 {
+		return
+	}
+	s := &t.trace.Steps[i]
+	s.Complexity = complexity
+	if err != nil {
+		s.Error = err.Error()
+		return
+	}
+	v := exportValue(r)
+	s.Value = &v
+}
+
+// tracer returns the Tracer of the environment if it has one.
+func tracer(env environment) *Tracer {
+	if te, ok := env.(interface{ tracer() *Tracer }); ok {
+		return te.tracer()
+	}
+	return nil
+}
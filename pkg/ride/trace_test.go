@@ -0,0 +1,91 @@
+package ride
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	ridec "github.com/wavesplatform/gowaves/pkg/ride/compiler"
+)
+
+type tracedEnvironment struct {
+	*mockRideEnvironment
+	tr *Tracer
+}
+
+func (e *tracedEnvironment) tracer() *Tracer {
+	return e.tr
+}
+
+func traceExpression(t *testing.T, expr string, tr *Tracer) (Evaluation, error) {
+	src := `
+	{-# STDLIB_VERSION 6 #-}
+	{-# CONTENT_TYPE DAPP #-}
+	{-# SCRIPT_TYPE ACCOUNT #-}
+	func double(a: Int) = a * 2
+	func g(x: Int) = {
+		let y = double(x)
+		if (y > 10) then y else throw("too small")
+	}
+	func expression() = ` + expr
+	tree, errs := ridec.CompileToTree(src)
+	require.Empty(t, errs)
+	last := len(tree.Declarations) - 1
+	e, ok := tree.Declarations[last].(*ast.FunctionDeclarationNode)
+	require.True(t, ok)
+	tree.Declarations = tree.Declarations[:last]
+	dApp := newTestAccount(t, "DAPP")
+	env := newTestEnv(t).withLibVersion(tree.LibVersion).withComplexityLimit(1000).withRideV6Activated().
+		withThis(dApp).withDApp(dApp).withWrappedState()
+	return EvaluateExpression(&tracedEnvironment{mockRideEnvironment: env.toEnv(), tr: tr}, tree, e.Body)
+}
+
+func TestTracer(t *testing.T) {
+	tr := NewTracer(DefaultMaxTraceSteps)
+	ev, err := traceExpression(t, "g(6)", tr)
+	require.NoError(t, err)
+	intValue := func(v int64) *Value { return &Value{Type: "Int", Value: v} }
+	intArgs := func(vs ...int64) []Value {
+		r := make([]Value, len(vs))
+		for i, v := range vs {
+			r[i] = *intValue(v)
+		}
+		return r
+	}
+	trace := tr.Trace()
+	assert.False(t, trace.Truncated)
+	require.Len(t, trace.Steps, 6)
+	for i, step := range []TraceStep{
+		{Kind: TraceStepCall, Name: "g", Depth: 0, Args: intArgs(6), Value: intValue(12)},
+		{Kind: TraceStepLet, Name: "y", Depth: 1, Value: intValue(12)},
+		{Kind: TraceStepCall, Name: "double", Depth: 2, Args: intArgs(6), Value: intValue(12)},
+		{Kind: TraceStepCall, Name: "104", Depth: 3, Args: intArgs(6, 2), Value: intValue(12)},
+		{Kind: TraceStepCall, Name: "102", Depth: 1, Args: intArgs(12, 10), Value: &Value{Type: "Boolean", Value: true}},
+		{Kind: TraceStepBranch, Name: "then", Depth: 1, Value: intValue(12)},
+	} {
+		actual := trace.Steps[i]
+		actual.Complexity = 0
+		assert.Equal(t, step, actual, "step %d", i)
+	}
+	assert.Equal(t, ev.Complexity, trace.Steps[0].Complexity)
+	assert.Equal(t, 1, trace.Steps[3].Complexity)
+
+	tr = NewTracer(2)
+	_, err = traceExpression(t, "g(5)", tr)
+	require.Error(t, err)
+	trace = tr.Trace()
+	assert.True(t, trace.Truncated)
+	require.Len(t, trace.Steps, 2)
+	assert.NotEmpty(t, trace.Steps[0].Error)
+	assert.Nil(t, trace.Steps[0].Value)
+}
+
+func TestNilTracer(t *testing.T) {
+	ev, err := traceExpression(t, "g(6)", nil)
+	require.NoError(t, err)
+	assert.Equal(t, Value{Type: "Int", Value: int64(12)}, ev.Value)
+	var tr *Tracer
+	assert.Equal(t, Trace{}, tr.Trace())
+}
@@ -14,6 +14,16 @@ func CallVerifier(env environment, tree *ast.Tree) (Result, error) {
 	return e.evaluate()
 }
 
+// CallExpression evaluates the expression of InvokeExpression transaction.
+func CallExpression(env environment, tree *ast.Tree) (Result, error) {
+	e, err := treeExpressionEvaluator(env, tree)
+	if err != nil {
+		return nil, RuntimeError.Wrap(err, "failed to call expression")
+	}
+	r, err := e.evaluate()
+	return expressionResult(env, tree.LibVersion, r, err)
+}
+
 func CallFunction(env environment, tree *ast.Tree, fc proto.FunctionCall) (Result, error) {
 	var (
 		name = fc.Name()
@@ -30,6 +40,12 @@ func CallFunction(env environment, tree *ast.Tree, fc proto.FunctionCall) (Resul
 	// After that instruction script/function is executed,
 	// so result of the execution and spent complexity should be considered outside.
 	rideResult, err := e.evaluate()
+	return functionResult(env, tree.LibVersion, name, rideResult, err)
+}
+
+// functionResult completes the result of callable function evaluation.
+func functionResult(env environment, v ast.LibraryVersion, name string, rideResult Result, err error) (Result, error) {
+	complexity := env.complexityCalculator().complexity()
 	if err != nil {
 		// Evaluation failed we have to return a DAppResult that contains spent execution complexity
 		// Produced actions are not stored for failed transactions, no need to return them here
@@ -39,20 +55,20 @@ func CallFunction(env environment, tree *ast.Tree, fc proto.FunctionCall) (Resul
 				et.Wrap(err, "unhandled error"),
 				// Error was not handled in wrapped state properly,
 				// so we need to add both complexity from current evaluation and from internal invokes
-				e.complexity(),
+				complexity,
 			)
 		}
-		return nil, EvaluationErrorSetComplexity(err, e.complexity())
+		return nil, EvaluationErrorSetComplexity(err, complexity)
 	}
 	dAppResult, ok := rideResult.(DAppResult)
 	if !ok { // Unexpected result type
 		return nil, EvaluationErrorSetComplexity(
 			EvaluationFailure.Errorf("invalid result of call function '%s'", name),
 			// New error, both complexities should be added
-			e.complexity(),
+			complexity,
 		)
 	}
-	if tree.LibVersion < ast.LibV5 { // Shortcut because no wrapped state before version 5
+	if v < ast.LibV5 { // Shortcut because no wrapped state before version 5
 		return rideResult, nil
 	}
 	// Add actions from wrapped state
@@ -61,6 +77,19 @@ func CallFunction(env environment, tree *ast.Tree, fc proto.FunctionCall) (Resul
 	return dAppResult, nil
 }
 
+// expressionResult completes the result of expression evaluation with the actions of nested invocations.
+func expressionResult(env environment, v ast.LibraryVersion, r Result, err error) (Result, error) {
+	if err != nil {
+		return nil, err
+	}
+	dAppResult, ok := r.(DAppResult)
+	if !ok || v < ast.LibV5 {
+		return r, nil
+	}
+	dAppResult.actions = append(wrappedStateActions(env.state()), dAppResult.actions...)
+	return dAppResult, nil
+}
+
 func wrappedStateActions(state types.SmartState) []proto.ScriptAction {
 	ws, ok := state.(*WrappedState)
 	if !ok {
@@ -194,43 +194,60 @@ type treeEvaluator struct {
 	f    ast.Node
 	s    evaluationScope
 	env  environment
+	tr   *Tracer
 }
 
 func (e *treeEvaluator) complexity() int {
 	return e.env.complexityCalculator().complexity()
 }
 
+// trace starts the step of evaluation trace and returns the function that completes the step.
+func (e *treeEvaluator) trace(kind, name string, args []rideType) func(rideType, error) {
+	if e.tr == nil {
+		return func(rideType, error) {}
+	}
+	step, initialComplexity := e.tr.begin(kind, name, args), e.complexity()
+	return func(r rideType, err error) {
+		e.tr.end(step, r, e.complexity()-initialComplexity, err)
+	}
+}
+
 func (e *treeEvaluator) evaluate() (Result, error) {
 	r, err := e.walk(e.f)
 	if err != nil {
 		return nil, err // Evaluation failed somehow, then result just an error
 	}
+	return evaluationResult(e.env, r)
+}
 
+// evaluationResult converts the value produced by script evaluation into the Result.
+func evaluationResult(env environment, r rideType) (Result, error) {
+	complexity := env.complexityCalculator().complexity()
 	switch res := r.(type) {
 	case rideBoolean:
-		return ScriptResult{res: bool(res), complexity: e.complexity()}, nil
+		return ScriptResult{res: bool(res), complexity: complexity}, nil
 	case rideScriptResult, rideWriteSet, rideTransferSet:
-		a, err := objectToActions(e.env, res)
+		a, err := objectToActions(env, res)
 		if err != nil {
 			return nil, EvaluationFailure.Wrap(err, "failed to convert evaluation result")
 		}
-		return DAppResult{actions: a, complexity: e.complexity()}, nil
+		return DAppResult{actions: a, complexity: complexity}, nil
 	case rideList:
 		var actions []proto.ScriptAction
 		for _, item := range res {
-			a, err := convertToAction(e.env, item)
+			a, err := convertToAction(env, item)
 			if err != nil {
 				return nil, EvaluationFailure.Wrap(err, "failed to convert evaluation result")
 			}
 			actions = append(actions, a)
 		}
-		return DAppResult{actions: actions, complexity: e.complexity()}, nil
+		return DAppResult{actions: actions, complexity: complexity}, nil
 	case tuple2:
 		var actions []proto.ScriptAction
 		switch resAct := res.el1.(type) {
 		case rideList:
 			for _, item := range resAct {
-				a, err := convertToAction(e.env, item)
+				a, err := convertToAction(env, item)
 				if err != nil {
 					return nil, EvaluationFailure.Wrap(err, "failed to convert evaluation result")
 				}
@@ -239,7 +256,7 @@ func (e *treeEvaluator) evaluate() (Result, error) {
 		default:
 			return nil, EvaluationFailure.Errorf("unexpected result type '%T'", r)
 		}
-		return DAppResult{actions: actions, param: res.el2, complexity: e.complexity()}, nil
+		return DAppResult{actions: actions, param: res.el2, complexity: complexity}, nil
 	default:
 		return nil, EvaluationFailure.Errorf("unexpected result type '%T'", r)
 	}
@@ -257,7 +274,7 @@ func (e *treeEvaluator) materializeArguments(arguments []ast.Node) ([]rideType,
 	return args, nil
 }
 
-func (e *treeEvaluator) evaluateNativeFunction(name string, arguments []ast.Node) (rideType, error) {
+func (e *treeEvaluator) evaluateNativeFunction(name string, arguments []ast.Node) (r rideType, err error) {
 	f, ok := e.s.system(name)
 	if !ok {
 		return nil, EvaluationFailure.Errorf("failed to find system function '%s'", name)
@@ -270,6 +287,10 @@ func (e *treeEvaluator) evaluateNativeFunction(name string, arguments []ast.Node
 	if err != nil {
 		return nil, EvaluationErrorPushf(err, "failed to call system function '%s'", name)
 	}
+	done := e.trace(TraceStepCall, name, args)
+	defer func() { // Deferred before adding of complexity to complete the step after it
+		done(r, err)
+	}()
 	if tErr := e.env.complexityCalculator().testNativeFunctionComplexity(name, cost); tErr != nil {
 		eet := Undefined
 		if ccErr := complexityCalculatorError(nil); errors.As(tErr, &ccErr) {
@@ -280,14 +301,18 @@ func (e *treeEvaluator) evaluateNativeFunction(name string, arguments []ast.Node
 	defer func() {
 		e.env.complexityCalculator().addNativeFunctionComplexity(name, cost)
 	}()
-	r, err := f(e.env, args...)
+	r, err = f(e.env, args...)
 	if err != nil {
 		return nil, EvaluationErrorPushf(err, "failed to call system function '%s'", name)
 	}
 	return r, nil
 }
 
-func (e *treeEvaluator) evaluateUserFunction(name string, args []rideType) (rideType, error) {
+func (e *treeEvaluator) evaluateUserFunction(name string, args []rideType) (r rideType, err error) {
+	done := e.trace(TraceStepCall, name, args)
+	defer func() {
+		done(r, err)
+	}()
 	initialComplexity := e.env.complexityCalculator().complexity()
 	defer func() {
 		e.env.complexityCalculator().addAdditionalUserFunctionComplexity(name, initialComplexity)
@@ -308,7 +333,7 @@ func (e *treeEvaluator) evaluateUserFunction(name string, args []rideType) (ride
 	var tmp int
 	tmp, e.s.cl = e.s.cl, cl
 
-	r, err := e.walk(uf.Body)
+	r, err = e.walk(uf.Body)
 	if err != nil {
 		return nil, EvaluationErrorPushf(err, "failed to evaluate function '%s' body", name)
 	}
@@ -365,11 +390,14 @@ func (e *treeEvaluator) walk(node ast.Node) (rideType, error) {
 		if !ok {
 			return nil, RuntimeError.New("conditional is not a boolean")
 		}
-		if cr {
-			return e.walk(n.TrueExpression)
-		} else {
-			return e.walk(n.FalseExpression)
+		branch, expr := "then", n.TrueExpression
+		if !cr {
+			branch, expr = "else", n.FalseExpression
 		}
+		done := e.trace(TraceStepBranch, branch, nil)
+		r, err := e.walk(expr)
+		done(r, err)
+		return r, err
 
 	case *ast.AssignmentNode:
 		id := n.Name
@@ -404,7 +432,9 @@ func (e *treeEvaluator) walk(node ast.Node) (rideType, error) {
 			if v.expression == nil {
 				return nil, RuntimeError.Errorf("scope value '%s' is empty", id)
 			}
+			done := e.trace(TraceStepLet, id, nil)
 			r, err := e.walk(v.expression)
+			done(r, err)
 			if err != nil {
 				return nil, EvaluationErrorPushf(err, "failed to evaluate expression of scope value '%s'", id)
 			}
@@ -496,6 +526,7 @@ func treeVerifierEvaluator(env environment, tree *ast.Tree) (*treeEvaluator, err
 				f:    verifier.Body, // In DApp verifier is a function, so we have to pass its body
 				s:    s,
 				env:  env,
+				tr:   tracer(env),
 			}, nil
 		}
 		return nil, EvaluationFailure.New("no verifier declaration")
@@ -505,9 +536,23 @@ func treeVerifierEvaluator(env environment, tree *ast.Tree) (*treeEvaluator, err
 		f:    tree.Verifier, // In simple script verifier is an expression itself
 		s:    s,
 		env:  env,
+		tr:   tracer(env),
 	}, nil
 }
 
+// treeExpressionEvaluator creates the evaluator of the expression of InvokeExpression transaction.
+// Unlike verifiers, such expressions are allowed to invoke DApps.
+func treeExpressionEvaluator(env environment, tree *ast.Tree) (*treeEvaluator, error) {
+	if tree.IsDApp() {
+		return nil, EvaluationFailure.New("unable to call DApp as expression")
+	}
+	s, err := newEvaluationScope(tree.LibVersion, env, true)
+	if err != nil {
+		return nil, EvaluationFailure.Wrap(err, "failed to create scope")
+	}
+	return &treeEvaluator{dapp: false, f: tree.Verifier, s: s, env: env, tr: tracer(env)}, nil
+}
+
 func treeFunctionEvaluator(env environment, tree *ast.Tree, name string, args []rideType) (*treeEvaluator, error) {
 	s, err := newEvaluationScope(tree.LibVersion, env, true)
 	if err != nil {
@@ -535,7 +580,7 @@ func treeFunctionEvaluator(env environment, tree *ast.Tree, name string, args []
 			for i, arg := range args {
 				s.pushValue(function.Arguments[i], arg)
 			}
-			return &treeEvaluator{dapp: true, f: function.Body, s: s, env: env}, nil
+			return &treeEvaluator{dapp: true, f: function.Body, s: s, env: env, tr: tracer(env)}, nil
 		}
 	}
 	return nil, EvaluationFailure.Errorf("function '%s' not found", name)
@@ -4,173 +4,596 @@ import (
 	"encoding/binary"
 
 	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
 )
 
+// noExpression marks the scope value that was created with a value instead of an expression.
+const noExpression = -1
+
+// vmValue is a scope value, the value is evaluated lazily from the expression at given position.
+type vmValue struct {
+	id         uint16
+	value      rideType
+	expression int
+}
+
+// vmFunction is a declared user function with the depth of scopes at the place of declaration.
+type vmFunction struct {
+	fn *userFunction
+	sp int
+}
+
+// frame is a call frame of let expression or user function.
 type frame struct {
 	function bool
 	back     int
-	args     []rideType
+	// Position of the scope value that has to be updated with the result of let expression.
+	scope, pos int
+	id         uint16
+	// Lower bound of visible scopes of the caller.
+	cl int
+}
+
+type deferredKind byte
+
+const (
+	deferredConditional deferredKind = iota
+	deferredReference
+	deferredProperty
+	deferredUserFunction
+)
+
+// deferredComplexity is a complexity that has to be added on completion of a node evaluation.
+// In case of error all deferred complexities are added in reverse order.
+type deferredComplexity struct {
+	kind deferredKind
+	name string
+	ic   int
 }
 
-func newExpressionFrame(pos int) frame {
-	return frame{
-		back: pos,
+type contextKind byte
+
+const (
+	contextCondition contextKind = iota
+	contextAssignment
+	contextDeclaration
+	contextReference
+	contextNativeCall
+	contextUserCall
+	contextUserFunction
+	contextProperty
+)
+
+// context is a node under evaluation, contexts are used to build the call stack of evaluation error
+// the same way as tree evaluator does.
+type context struct {
+	kind contextKind
+	name string
+	sp   int // Depth of the stack at the beginning of the node evaluation
+}
+
+// vm evaluates compiled program. The scoping rules and complexity accounting of the vm are the same as
+// in tree evaluator, so both evaluators produce the same results, complexities and errors.
+type vm struct {
+	env        environment
+	program    *program
+	code       []byte
+	ip         int
+	invocation bool
+	rideV6     bool
+	stack      []rideType
+	scopes     [][]vmValue
+	cl         int
+	functions  []vmFunction
+	calls      []frame
+	deferred   []deferredComplexity
+	contexts   []context
+	constants  []rideType
+	parameter  int
+	param      rideConstructor
+}
+
+func newVM(env environment, p *program, invocation bool) *vm {
+	return &vm{
+		env:        env,
+		program:    p,
+		code:       p.Code,
+		invocation: invocation,
+		rideV6:     env.rideV6Activated(),
+		stack:      make([]rideType, 0, 8),
+		scopes:     [][]vmValue{make([]vmValue, 0)},
+		calls:      make([]frame, 0, 8),
+		constants:  make([]rideType, len(p.names)),
+		parameter:  -1,
 	}
 }
 
-func newFunctionFrame(pos int, args []rideType) frame {
-	return frame{
-		function: true,
-		back:     pos,
-		args:     args,
+// declare puts global declarations of DApp into the root scope.
+func (m *vm) declare(declarations []declaration) {
+	for _, d := range declarations {
+		if d.function {
+			m.functions = append(m.functions, vmFunction{fn: &m.program.functions[d.id], sp: len(m.scopes)})
+			continue
+		}
+		m.pushExpression(d.id, d.expression)
 	}
 }
 
-type vm struct {
-	env          environment
-	code         []byte
-	ip           int
-	constants    []rideType
-	functions    func(int) rideFunction
-	globals      func(int) rideConstructor
-	stack        []rideType
-	calls        []frame
-	functionName func(int) string
+// setParameter sets the constructor of invocation parameter, it overrides the global constant with the same name.
+func (m *vm) setParameter(id uint16, c rideConstructor) {
+	m.parameter = int(id)
+	m.param = c
+}
+
+func (m *vm) pushExpression(id uint16, pos int) {
+	m.scopes[len(m.scopes)-1] = append(m.scopes[len(m.scopes)-1], vmValue{id: id, expression: pos})
+}
+
+func (m *vm) pushValue(id uint16, v rideType) {
+	m.scopes[len(m.scopes)-1] = append(m.scopes[len(m.scopes)-1], vmValue{id: id, value: v, expression: noExpression})
+}
+
+func (m *vm) popValue() {
+	m.scopes[len(m.scopes)-1] = m.scopes[len(m.scopes)-1][:len(m.scopes[len(m.scopes)-1])-1]
+}
+
+func (m *vm) updateValue(scope, pos int, id uint16, v rideType) {
+	if sv := m.scopes[scope][pos]; sv.id == id && sv.value == nil {
+		m.scopes[scope][pos] = vmValue{id: id, value: v, expression: noExpression}
+	}
+}
+
+func lookupValue(s []vmValue, id uint16) (vmValue, bool, int) {
+	for i := len(s) - 1; i >= 0; i-- {
+		if v := s[i]; v.id == id {
+			return v, true, i
+		}
+	}
+	return vmValue{}, false, 0
+}
+
+func (m *vm) value(id uint16) (vmValue, bool, int, int) {
+	if i := len(m.scopes) - 1; i >= 0 {
+		v, ok, p := lookupValue(m.scopes[i], id)
+		if ok {
+			return v, true, i, p
+		}
+	}
+	for i := m.cl - 1; i >= 0; i-- {
+		v, ok, p := lookupValue(m.scopes[i], id)
+		if ok {
+			return v, true, i, p
+		}
+	}
+	return vmValue{}, false, 0, 0
+}
+
+func (m *vm) constant(id uint16) (rideType, bool) {
+	if v := m.constants[id]; v != nil {
+		return v, true
+	}
+	c := m.program.globals[id]
+	if int(id) == m.parameter {
+		c = m.param
+	}
+	if c == nil {
+		return nil, false
+	}
+	v := c(m.env)
+	m.constants[id] = v
+	return v, true
 }
 
-func (m *vm) run() (Result, error) {
-	if m.stack != nil {
-		m.stack = m.stack[0:0]
+func (m *vm) userFunction(id uint16) (*userFunction, int, bool) {
+	for i := len(m.functions) - 1; i >= 0; i-- {
+		if f := m.functions[i]; f.fn.name == id {
+			return f.fn, f.sp, true
+		}
 	}
-	if m.calls != nil {
-		m.calls = m.calls[0:0]
+	return nil, 0, false
+}
+
+func (m *vm) cc() complexityCalculator {
+	return m.env.complexityCalculator()
+}
+
+// run evaluates the code from the entry point. On error all deferred complexities are added,
+// so the complexity calculator holds the same complexity as after the failed evaluation of the tree.
+func (m *vm) run(entry int) (rideType, error) {
+	m.ip = entry
+	r, err := m.execute()
+	if err != nil {
+		for len(m.deferred) > 0 {
+			m.complete()
+		}
+		return nil, m.callStack(err)
+	}
+	return r, nil
+}
+
+func (m *vm) enter(kind contextKind, name string) {
+	m.contexts = append(m.contexts, context{kind: kind, name: name, sp: len(m.stack)})
+}
+
+func (m *vm) leave() {
+	m.contexts = m.contexts[:len(m.contexts)-1]
+}
+
+// callStack adds to the error the call stack of nodes under evaluation.
+func (m *vm) callStack(err error) error {
+	sp := len(m.stack)
+	for i := len(m.contexts) - 1; i >= 0; i-- {
+		c := m.contexts[i]
+		switch c.kind {
+		case contextCondition:
+			err = EvaluationErrorPushf(err, "failed to estimate the condition of if")
+		case contextAssignment:
+			err = EvaluationErrorPushf(err, "failed to evaluate block after declaration of variable '%s'", c.name)
+		case contextDeclaration:
+			err = EvaluationErrorPushf(err, "failed to evaluate block after declaration of function '%s'", c.name)
+		case contextReference:
+			err = EvaluationErrorPushf(err, "failed to evaluate expression of scope value '%s'", c.name)
+		case contextNativeCall:
+			// Arguments are evaluated one by one, so the number of values on the stack is the number of evaluated arguments
+			err = EvaluationErrorPushf(err, "failed to materialize argument %d", sp-c.sp+1)
+			err = EvaluationErrorPushf(err, "failed to call system function '%s'", c.name)
+		case contextUserCall:
+			err = EvaluationErrorPushf(err, "failed to materialize argument %d", sp-c.sp+1)
+			err = EvaluationErrorPushf(err, "failed to evaluate function '%s' body", c.name)
+		case contextUserFunction:
+			err = EvaluationErrorPushf(err, "failed to evaluate function '%s' body", c.name)
+		case contextProperty:
+			err = EvaluationErrorPushf(err, "failed to evaluate an object to get property '%s' on it", c.name)
+		}
+		sp = c.sp
 	}
-	m.ip = 0
+	return err
+}
+
+func (m *vm) execute() (rideType, error) {
 	for m.ip < len(m.code) {
 		op := m.code[m.ip]
 		m.ip++
 		switch op {
 		case OpPush:
-			m.push(m.constant())
-		case OpPop:
-			_, err := m.pop()
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to pop value")
+			v := m.Constant()
+			if err := m.walk(literalNode(v)); err != nil {
+				return nil, err
 			}
+			m.push(v)
 		case OpTrue:
+			if err := m.walk((*ast.BooleanNode)(nil)); err != nil {
+				return nil, err
+			}
 			m.push(rideBoolean(true))
 		case OpFalse:
+			if err := m.walk((*ast.BooleanNode)(nil)); err != nil {
+				return nil, err
+			}
 			m.push(rideBoolean(false))
-		case OpJump:
-			pos := m.arg16()
-			m.ip = pos
+		case OpCondition:
+			if err := m.walk((*ast.ConditionalNode)(nil)); err != nil {
+				return nil, err
+			}
+			if tErr := m.cc().testConditionalComplexity(); tErr != nil {
+				return nil, complexityError(tErr, "failed to test conditional complexity")
+			}
+			m.deferred = append(m.deferred, deferredComplexity{kind: deferredConditional})
+			m.enter(contextCondition, "")
 		case OpJumpIfFalse:
-			pos := m.arg16()
-			v, ok := m.current().(rideBoolean)
+			pos := m.arg32()
+			m.leave()
+			v, err := m.pop()
+			if err != nil {
+				return nil, err
+			}
+			cr, ok := v.(rideBoolean)
 			if !ok {
-				return nil, errors.Errorf("not a boolean value '%v' of type '%T'", m.current(), m.current())
+				return nil, RuntimeError.New("conditional is not a boolean")
 			}
-			if !v {
+			if !cr {
 				m.ip = pos
 			}
+		case OpJump:
+			m.ip = m.arg32()
+		case OpEndCondition:
+			m.complete()
+		case OpLet:
+			id := m.arg16()
+			pos := m.arg32()
+			if err := m.walk((*ast.AssignmentNode)(nil)); err != nil {
+				return nil, err
+			}
+			m.pushExpression(uint16(id), pos)
+			m.enter(contextAssignment, m.program.names[id])
+		case OpEndLet:
+			m.leave()
+			m.popValue()
+		case OpFunction:
+			id := m.arg16()
+			if err := m.walk((*ast.FunctionDeclarationNode)(nil)); err != nil {
+				return nil, err
+			}
+			fn := &m.program.functions[id]
+			m.functions = append(m.functions, vmFunction{fn: fn, sp: len(m.scopes)})
+			m.enter(contextDeclaration, m.program.names[fn.name])
+		case OpEndFunction:
+			m.leave()
+			if len(m.functions) == 0 {
+				return nil, EvaluationFailure.New("empty user functions scope")
+			}
+			m.functions = m.functions[:len(m.functions)-1]
+		case OpLoad:
+			if err := m.load(uint16(m.arg16())); err != nil {
+				return nil, err
+			}
 		case OpProperty:
-			obj, err := m.pop()
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to get object")
+			name := m.Constant()
+			if err := m.walk((*ast.PropertyNode)(nil)); err != nil {
+				return nil, err
+			}
+			if tErr := m.cc().testPropertyComplexity(); tErr != nil {
+				return nil, complexityError(tErr, "failed to test property complexity")
 			}
-			prop := m.constant()
-			p, ok := prop.(rideString)
+			m.deferred = append(m.deferred, deferredComplexity{kind: deferredProperty})
+			p, ok := name.(rideString)
 			if !ok {
-				return nil, errors.Errorf("invalid property name type '%s'", prop.instanceOf())
+				return nil, EvaluationFailure.Errorf("invalid property name type '%s'", name.instanceOf())
 			}
-			v, err := obj.get(string(p))
+			m.enter(contextProperty, string(p))
+		case OpGetProperty:
+			name := m.contexts[len(m.contexts)-1].name
+			m.leave()
+			obj, err := m.pop()
 			if err != nil {
 				return nil, err
 			}
+			v, err := obj.get(name)
+			if err != nil {
+				return nil, EvaluationErrorPushf(err, "failed to get property '%s'", name)
+			}
+			m.complete()
 			m.push(v)
-		case OpCall:
-			pos := m.arg16()
-			cnt := m.arg16()
-			in := make([]rideType, cnt)
-			for i := cnt - 1; i >= 0; i-- {
-				v, err := m.pop()
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to call function at position %d", pos)
-				}
-				in[i] = v
+		case OpExternal:
+			id := m.arg16()
+			if err := m.walk((*ast.FunctionCallNode)(nil)); err != nil {
+				return nil, err
+			}
+			if err := m.checkNative(id); err != nil {
+				return nil, err
 			}
-			frame := newFunctionFrame(m.ip, in) // Creating new function frame with return position
-			m.calls = append(m.calls, frame)
-			m.ip = pos // Continue to function
+			m.enter(contextNativeCall, m.program.natives[id].name)
 		case OpExternalCall:
-			// Before calling external function all parameters must be evaluated and placed on stack
+			m.leave()
 			id := m.arg16()
-			cnt := m.arg16()
-			in := make([]rideType, cnt) // Prepare input parameters for external call
-			for i := cnt - 1; i >= 0; i-- {
-				v, err := m.pop()
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to call external function '%s'", m.functionName(id))
-				}
-				in[i] = v
+			args, err := m.args(m.arg16())
+			if err != nil {
+				return nil, err
 			}
-			fn := m.functions(id)
-			if fn == nil {
-				return nil, errors.Errorf("external function '%s' not implemented", m.functionName(id))
+			if err := m.callNative(id, args); err != nil {
+				return nil, err
 			}
-			res, err := fn(m.env, in...)
-			if err != nil {
+		case OpPrepareCall:
+			name := uint16(m.arg16())
+			id := m.arg16()
+			if err := m.walk((*ast.FunctionCallNode)(nil)); err != nil {
 				return nil, err
 			}
-			m.push(res)
-		case OpLoad: // Evaluate expression behind a LET declaration
-			pos := m.arg16()
-			frame := newExpressionFrame(m.ip) // Creating new function frame with return position
-			m.calls = append(m.calls, frame)
-			m.ip = pos // Continue to expression
-		case OpLoadLocal:
-			n := m.arg16()
-			for i := len(m.calls) - 1; i >= 0; i-- {
-
+			if _, _, found := m.userFunction(name); found {
+				m.enter(contextUserCall, m.program.names[name])
+				continue
 			}
-			l := len(m.calls)
-			if l == 0 {
-				return nil, errors.New("failed to load argument on stack")
+			if err := m.checkNative(id); err != nil {
+				return nil, err
 			}
-			frame := m.calls[l-1]
-			if l := len(frame.args); l < n+1 {
-				return nil, errors.New("invalid arguments count")
+			m.enter(contextNativeCall, m.program.natives[id].name)
+		case OpCall:
+			m.leave()
+			name := uint16(m.arg16())
+			args, err := m.args(m.arg16())
+			if err != nil {
+				return nil, err
 			}
-			m.push(frame.args[n])
-		case OpReturn:
-			l := len(m.calls)
-			var f frame
-			f, m.calls = m.calls[l-1], m.calls[:l-1]
-			m.ip = f.back
-		case OpHalt:
-			if len(m.stack) > 0 {
-				v, err := m.pop()
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to get result value")
-				}
-				switch tv := v.(type) {
-				case rideBoolean:
-					return ScriptResult{res: bool(tv)}, nil
-				default:
-					return nil, errors.Errorf("unexpected result value '%v' of type '%T'", v, v)
+			id := m.arg16()
+			if _, _, found := m.userFunction(name); !found {
+				if err := m.callNative(id, args); err != nil {
+					return nil, err
 				}
+				continue
 			}
-			return nil, errors.New("no result after script execution")
-		case OpGlobal:
-			id := m.arg16()
-			constructor := m.globals(id)
-			v := constructor(m.env)
-			m.push(v)
+			if err := m.callUser(name, args); err != nil {
+				return nil, err
+			}
+		case OpReturn:
+			if err := m.ret(); err != nil {
+				return nil, err
+			}
+		case OpHalt:
+			return m.pop()
 		default:
-			return nil, errors.Errorf("unknown code %#x", op)
+			return nil, EvaluationFailure.Errorf("unknown code %#x", op)
+		}
+	}
+	return nil, EvaluationFailure.New("broken code")
+}
+
+// walk checks the complexity calculator before evaluation of the next node, as tree evaluator does.
+func (m *vm) walk(node ast.Node) error {
+	if err := m.cc().error(); err != nil {
+		eet := Undefined
+		if ccErr := complexityCalculatorError(nil); errors.As(err, &ccErr) {
+			eet = ccErr.EvaluationErrorWrapType()
 		}
+		return eet.Wrapf(err, "failed to walk node '%T'", node)
 	}
-	return nil, errors.New("broken code")
+	return nil
+}
+
+func literalNode(v rideType) ast.Node {
+	switch v.(type) {
+	case rideInt:
+		return (*ast.LongNode)(nil)
+	case rideByteVector:
+		return (*ast.BytesNode)(nil)
+	default:
+		return (*ast.StringNode)(nil)
+	}
+}
+
+func complexityError(err error, msg string) error {
+	eet := Undefined
+	if ccErr := complexityCalculatorError(nil); errors.As(err, &ccErr) {
+		eet = ccErr.EvaluationErrorWrapType()
+	}
+	return eet.Wrap(err, msg)
+}
+
+// complete adds the last deferred complexity.
+func (m *vm) complete() {
+	l := len(m.deferred)
+	var d deferredComplexity
+	d, m.deferred = m.deferred[l-1], m.deferred[:l-1]
+	cc := m.cc()
+	switch d.kind {
+	case deferredConditional:
+		cc.addConditionalComplexity()
+	case deferredReference:
+		cc.addReferenceComplexity()
+	case deferredProperty:
+		cc.addPropertyComplexity()
+	case deferredUserFunction:
+		cc.addAdditionalUserFunctionComplexity(d.name, d.ic)
+	}
+}
+
+func (m *vm) load(id uint16) error {
+	if err := m.walk((*ast.ReferenceNode)(nil)); err != nil {
+		return err
+	}
+	cc := m.cc()
+	if tErr := cc.testReferenceComplexity(); tErr != nil {
+		return complexityError(tErr, "failed to test reference complexity")
+	}
+	v, ok, f, p := m.value(id)
+	if !ok {
+		defer cc.addReferenceComplexity()
+		if c, ok := m.constant(id); ok {
+			m.push(c)
+			return nil
+		}
+		return RuntimeError.Errorf("value '%s' not found", m.program.names[id])
+	}
+	if v.value == nil {
+		if v.expression == noExpression {
+			cc.addReferenceComplexity()
+			return RuntimeError.Errorf("scope value '%s' is empty", m.program.names[id])
+		}
+		m.deferred = append(m.deferred, deferredComplexity{kind: deferredReference})
+		m.enter(contextReference, m.program.names[id])
+		m.calls = append(m.calls, frame{back: m.ip, scope: f, pos: p, id: id})
+		m.ip = v.expression
+		return nil
+	}
+	cc.addReferenceComplexity()
+	m.push(v.value)
+	return nil
+}
+
+func (m *vm) checkNative(id int) error {
+	fn := &m.program.natives[id]
+	if fn.function(m.invocation) == nil {
+		return EvaluationFailure.Errorf("failed to find system function '%s'", fn.name)
+	}
+	if _, ok := fn.cost(m.rideV6); !ok {
+		return EvaluationFailure.Errorf("failed to get cost of system function '%s'", fn.name)
+	}
+	return nil
+}
+
+func (m *vm) callNative(id int, args []rideType) error {
+	fn := &m.program.natives[id]
+	f := fn.function(m.invocation)
+	cost, ok := fn.cost(m.rideV6)
+	if f == nil || !ok { // Unreachable, function is checked before evaluation of arguments
+		return EvaluationFailure.Errorf("failed to find system function '%s'", fn.name)
+	}
+	cc := m.cc()
+	if tErr := cc.testNativeFunctionComplexity(fn.name, cost); tErr != nil {
+		return complexityError(tErr, "failed to test complexity of system function")
+	}
+	r, err := f(m.env, args...)
+	m.cc().addNativeFunctionComplexity(fn.name, cost)
+	if err != nil {
+		return EvaluationErrorPushf(err, "failed to call system function '%s'", fn.name)
+	}
+	m.push(r)
+	return nil
+}
+
+func (m *vm) callUser(id uint16, args []rideType) error {
+	name := m.program.names[id]
+	m.deferred = append(m.deferred, deferredComplexity{
+		kind: deferredUserFunction,
+		name: name,
+		ic:   m.cc().complexity(),
+	})
+	uf, cl, found := m.userFunction(id)
+	if !found {
+		return RuntimeError.Errorf("user function '%s' not found", name)
+	}
+	if len(args) != len(uf.arguments) {
+		return RuntimeError.Errorf("mismatched arguments number of user function '%s'", name)
+	}
+	avs := make([]vmValue, len(args))
+	for i, arg := range args {
+		avs[i] = vmValue{id: uf.arguments[i], value: arg, expression: noExpression}
+	}
+	m.scopes = append(m.scopes, avs)
+	m.calls = append(m.calls, frame{function: true, back: m.ip, cl: m.cl})
+	m.cl = cl
+	m.ip = uf.body
+	m.enter(contextUserFunction, name)
+	return nil
+}
+
+func (m *vm) ret() error {
+	l := len(m.calls)
+	if l == 0 {
+		return EvaluationFailure.New("return without call")
+	}
+	var f frame
+	f, m.calls = m.calls[l-1], m.calls[:l-1]
+	m.ip = f.back
+	m.leave()
+	if !f.function {
+		r, err := m.peek()
+		if err != nil {
+			return err
+		}
+		m.updateValue(f.scope, f.pos, f.id, r)
+		m.complete()
+		return nil
+	}
+	m.scopes = m.scopes[:len(m.scopes)-1]
+	m.cl = f.cl
+	d := m.deferred[len(m.deferred)-1]
+	if tErr := m.cc().testAdditionalUserFunctionComplexity(d.name, d.ic); tErr != nil {
+		return complexityError(tErr, "failed to test complexity of user function")
+	}
+	m.complete()
+	return nil
+}
+
+func (m *vm) args(n int) ([]rideType, error) {
+	l := len(m.stack)
+	if l < n {
+		return nil, EvaluationFailure.New("not enough arguments on stack")
+	}
+	args := make([]rideType, n)
+	copy(args, m.stack[l-n:])
+	m.stack = m.stack[:l-n]
+	return args, nil
 }
 
 func (m *vm) push(v rideType) {
@@ -179,46 +602,33 @@ func (m *vm) push(v rideType) {
 
 func (m *vm) pop() (rideType, error) {
 	if len(m.stack) == 0 {
-		return nil, errors.New("empty callStack")
+		return nil, EvaluationFailure.New("empty stack")
 	}
 	value := m.stack[len(m.stack)-1]
 	m.stack = m.stack[:len(m.stack)-1]
 	return value, nil
 }
 
-func (m *vm) current() rideType {
-	return m.stack[len(m.stack)-1]
+func (m *vm) peek() (rideType, error) {
+	if len(m.stack) == 0 {
+		return nil, EvaluationFailure.New("empty stack")
+	}
+	return m.stack[len(m.stack)-1], nil
 }
 
 func (m *vm) arg16() int {
-	//TODO: add check
 	res := binary.BigEndian.Uint16(m.code[m.ip : m.ip+2])
 	m.ip += 2
 	return int(res)
 }
 
-func (m *vm) constant() rideType {
-	//TODO: add check
-	return m.constants[m.arg16()]
+func (m *vm) arg32() int {
+	res := binary.BigEndian.Uint32(m.code[m.ip : m.ip+4])
+	m.ip += 4
+	return int(res)
 }
 
-//func (m *vm) scope() (*frame, int) {
-//	n := len(m.calls) - 1
-//	if n < 0 {
-//		return nil, n
-//	}
-//	return &m.calls[n], n
-//}
-
-//func (m *vm) resolve(name string) (int, error) {
-//	_ = name
-//	//TODO: implement
-//	return 0, errors.New("not implemented")
-//}
-
-//func (m *vm) returnPosition() int {
-//	if l := len(m.calls); l > 0 {
-//		return m.calls[l-1].back
-//	}
-//	return len(m.code)
-//}
+// Constant reads constant ID parameter and returns the constant.
+func (m *vm) Constant() rideType {
+	return m.program.Constants[m.arg16()]
+}
@@ -68,16 +68,20 @@ func TestExecution(t *testing.T) {
 		require.NoError(t, err, test.comment)
 		assert.NotNil(t, script, test.comment)
 
-		var env environment
-		if test.tEnv != nil {
-			env = test.tEnv.withLibVersion(tree.LibVersion).toEnv()
+		tEnv := test.tEnv
+		if tEnv == nil {
+			tEnv = newTestEnv(t)
 		}
-		res, err := script.Run(env)
+		res, err := script.Run(tEnv.withLibVersion(tree.LibVersion).withComplexityLimit(2000).toEnv())
 		require.NoError(t, err, test.comment)
 		assert.NotNil(t, res, test.comment)
 		r, ok := res.(ScriptResult)
 		assert.True(t, ok, test.comment)
 		assert.Equal(t, test.res, r.Result(), test.comment)
+
+		expected, err := CallVerifier(tEnv.withComplexityLimit(2000).toEnv(), tree)
+		require.NoError(t, err, test.comment)
+		assert.Equal(t, expected, res, test.comment)
 	}
 }
 
@@ -385,7 +389,7 @@ func BenchmarkSimplestScript(b *testing.B) {
 		prg, err := Compile(tree)
 		require.NoError(b, err)
 		assert.NotNil(b, prg)
-		res, err := prg.Run(nil)
+		res, err := prg.Run(newBenchmarkEnv())
 		require.NoError(b, err)
 		r := res.(ScriptResult)
 		assert.True(b, r.Result())
@@ -415,9 +419,22 @@ func BenchmarkEval(b *testing.B) {
 	assert.NotNil(b, prg)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		res, err := prg.Run(nil) //TODO: pass real value
+		res, err := prg.Run(newBenchmarkEnv())
 		require.NoError(b, err)
 		r := res.(ScriptResult)
 		assert.True(b, r.Result())
 	}
 }
+
+func newBenchmarkEnv() *mockRideEnvironment {
+	cc := newComplexityCalculatorByRideV6Activation(false)
+	cc.setLimit(2000)
+	return &mockRideEnvironment{
+		rideV6ActivatedFunc: func() bool {
+			return false
+		},
+		complexityCalculatorFunc: func() complexityCalculator {
+			return cc
+		},
+	}
+}
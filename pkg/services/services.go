@@ -1,7 +1,9 @@
 package services
 
 import (
+	"github.com/wavesplatform/gowaves/pkg/node/blockchain_updates"
 	"github.com/wavesplatform/gowaves/pkg/node/messages"
+	"github.com/wavesplatform/gowaves/pkg/node/notifications"
 	"github.com/wavesplatform/gowaves/pkg/node/peers"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/state"
@@ -28,6 +30,7 @@ type BlocksApplier interface {
 		block *proto.Block,
 		snapshots *proto.BlockSnapshot,
 	) (proto.Height, error)
+	RollbackTo(state state.State, blockID proto.BlockID) error
 }
 
 type MicroBlockCache interface {
@@ -57,4 +60,8 @@ type Services struct {
 	InternalChannel chan messages.InternalMessage
 	MinPeersMining  int
 	SkipMessageList *messages.SkipMessageList
+	// BlockchainUpdates is nil if the blockchain updates are disabled.
+	BlockchainUpdates *blockchain_updates.Tracker
+	// Notifications is nil if the node doesn't publish notifications about blocks and UTX pool.
+	Notifications *notifications.Hub
 }
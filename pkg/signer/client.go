@@ -0,0 +1,247 @@
+// Package signer implements the signing of blocks, micro blocks, transactions and VRF proofs by the external
+// daemon holding the wallet, so the secret keys of generators never appear on the host of the node.
+//
+// RemoteSigner is the client used by the node in place of the embedded wallet, Handler is the HTTP API of
+// the signer daemon.
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+const (
+	// DefaultTimeout is the default timeout of requests to the signer daemon.
+	DefaultTimeout = 3 * time.Second
+	// publicKeysTTL is the period the public keys of signer daemon are cached for. The scheduler asks for
+	// the keys on every block, so they are not requested each time.
+	publicKeysTTL = time.Minute
+
+	maxResponseSize = 1 << 20
+)
+
+// RemoteSigner signs on behalf of accounts of the wallet held by the signer daemon. All signatures returned
+// by the daemon are verified before use.
+type RemoteSigner struct {
+	url    *url.URL
+	apiKey string
+	scheme proto.Scheme
+	client *http.Client
+
+	mu        sync.Mutex
+	pks       []crypto.PublicKey
+	pksExpiry time.Time
+}
+
+// NewRemoteSigner creates the client of the signer daemon at the given base URL.
+func NewRemoteSigner(baseURL, apiKey string, scheme proto.Scheme, timeout time.Duration) (*RemoteSigner, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid signer URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("unsupported signer URL scheme '%s'", u.Scheme)
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &RemoteSigner{
+		url:    u,
+		apiKey: apiKey,
+		scheme: scheme,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (s *RemoteSigner) PublicKeys() ([]crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pks != nil && time.Now().Before(s.pksExpiry) {
+		return s.pks, nil
+	}
+	var resp publicKeysResponse
+	if err := s.do(http.MethodGet, publicKeysPath, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.PublicKeys == nil {
+		resp.PublicKeys = []crypto.PublicKey{}
+	}
+	s.pks = resp.PublicKeys
+	s.pksExpiry = time.Now().Add(publicKeysTTL)
+	return s.pks, nil
+}
+
+func (s *RemoteSigner) SignTransactionWith(pk crypto.PublicKey, tx proto.Transaction) error {
+	js, err := json.Marshal(tx)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal transaction")
+	}
+	var resp txResponse
+	if err := s.do(http.MethodPost, signTxPath, txRequest{PublicKey: pk, Transaction: js}, &resp); err != nil {
+		return err
+	}
+	signed, err := proto.GuessTransactionType(&proto.TransactionTypeVersion{Type: tx.GetType(), Version: tx.GetVersion()})
+	if err != nil {
+		return err
+	}
+	if err := proto.UnmarshalTransactionFromJSON(resp.Transaction, s.scheme, signed); err != nil {
+		return errors.Wrap(err, "invalid signed transaction")
+	}
+	ok, err := verifyTransaction(s.scheme, pk, signed)
+	if err := checkSignature(ok, err, "transaction"); err != nil {
+		return err
+	}
+	// The transaction is updated in place, like it's done by the embedded wallet.
+	return proto.UnmarshalTransactionFromJSON(resp.Transaction, s.scheme, tx)
+}
+
+func (s *RemoteSigner) SignBlock(pk crypto.PublicKey, b *proto.Block) error {
+	req := blockRequest{PublicKey: pk, Protobuf: b.Version >= proto.ProtobufBlockVersion}
+	var err error
+	req.Data, err = b.Marshal(s.scheme)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal block")
+	}
+	var resp signatureResponse
+	if err := s.do(http.MethodPost, signBlockPath, req, &resp); err != nil {
+		return err
+	}
+	b.BlockSignature = resp.Signature
+	ok, err := b.VerifySignature(s.scheme)
+	return checkSignature(ok, err, "block")
+}
+
+func (s *RemoteSigner) SignMicroBlock(pk crypto.PublicKey, m *proto.MicroBlock) error {
+	req := blockRequest{PublicKey: pk, Protobuf: m.VersionField >= byte(proto.ProtobufBlockVersion)}
+	var err error
+	if req.Protobuf {
+		req.Data, err = m.MarshalToProtobuf(s.scheme)
+	} else {
+		req.Data, err = m.MarshalBinary(s.scheme)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal micro block")
+	}
+	var resp signatureResponse
+	if err := s.do(http.MethodPost, signMicroBlockPath, req, &resp); err != nil {
+		return err
+	}
+	m.Signature = resp.Signature
+	ok, err := m.VerifySignature(s.scheme)
+	return checkSignature(ok, err, "micro block")
+}
+
+func (s *RemoteSigner) SignMicroBlockInv(pk crypto.PublicKey, inv *proto.MicroBlockInv) error {
+	data, err := inv.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal micro block inv")
+	}
+	var resp signatureResponse
+	if err := s.do(http.MethodPost, signInvPath, invRequest{PublicKey: pk, Data: data}, &resp); err != nil {
+		return err
+	}
+	inv.Signature = resp.Signature
+	ok, err := inv.Verify(s.scheme)
+	return checkSignature(ok, err, "micro block inv")
+}
+
+// SignVRF returns the VRF proof of the message. The proof is verified by the caller, which needs the VRF value.
+func (s *RemoteSigner) SignVRF(pk crypto.PublicKey, msg []byte) ([]byte, error) {
+	var resp vrfResponse
+	if err := s.do(http.MethodPost, signVRFPath, vrfRequest{PublicKey: pk, Message: msg}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Proof, nil
+}
+
+// Load always fails, the wallet is loaded by the signer daemon.
+func (s *RemoteSigner) Load([]byte) error {
+	return errors.New("wallet is held by the remote signer")
+}
+
+// AccountSeeds returns nothing, the seeds never leave the signer daemon.
+func (s *RemoteSigner) AccountSeeds() [][]byte {
+	return nil
+}
+
+func (s *RemoteSigner) do(method, path string, req, resp any) error {
+	var body io.Reader
+	if req != nil {
+		bts, err := json.Marshal(req)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal signer request")
+		}
+		body = bytes.NewReader(bts)
+	}
+	httpReq, err := http.NewRequestWithContext(context.Background(), method, s.url.JoinPath(path).String(), body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create signer request")
+	}
+	httpReq.Header.Set(APIKeyHeader, s.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := s.client.Do(httpReq)
+	if err != nil {
+		return errors.Wrap(err, "signer request failed")
+	}
+	defer func() {
+		_ = httpResp.Body.Close()
+	}()
+	bts, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize))
+	if err != nil {
+		return errors.Wrap(err, "failed to read signer response")
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		if httpResp.StatusCode == http.StatusNotFound {
+			return wallet.PublicKeyNotFound
+		}
+		var e errorResponse
+		if json.Unmarshal(bts, &e) != nil || e.Message == "" {
+			e.Message = http.StatusText(httpResp.StatusCode)
+		}
+		return errors.Errorf("signer responded with status %d: %s", httpResp.StatusCode, e.Message)
+	}
+	if err := json.Unmarshal(bts, resp); err != nil {
+		return errors.Wrap(err, "invalid signer response")
+	}
+	return nil
+}
+
+func checkSignature(ok bool, err error, what string) error {
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify signature of %s", what)
+	}
+	if !ok {
+		return errors.Errorf("invalid signature of %s by signer", what)
+	}
+	return nil
+}
+
+// selfVerifier is the transaction that checks its own signature.
+type selfVerifier interface {
+	GetSenderPK() crypto.PublicKey
+	Verify(scheme proto.Scheme, pk crypto.PublicKey) (bool, error)
+}
+
+// verifyTransaction checks that the transaction is sent and signed by the account.
+func verifyTransaction(scheme proto.Scheme, pk crypto.PublicKey, tx proto.Transaction) (bool, error) {
+	v, ok := tx.(selfVerifier)
+	if !ok {
+		return false, errors.Errorf("unsupported transaction type %T", tx)
+	}
+	if v.GetSenderPK() != pk {
+		return false, errors.New("sender doesn't match the public key")
+	}
+	return v.Verify(scheme, pk)
+}
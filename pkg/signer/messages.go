@@ -0,0 +1,62 @@
+package signer
+
+import (
+	"encoding/json"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+)
+
+// APIKeyHeader is the HTTP header with the API key of the signer daemon.
+const APIKeyHeader = "X-API-Key" // #nosec: it's a header name
+
+const (
+	publicKeysPath     = "/public-keys"
+	signBlockPath      = "/sign/block"
+	signMicroBlockPath = "/sign/microblock"
+	signInvPath        = "/sign/microblock-inv"
+	signTxPath         = "/sign/transaction"
+	signVRFPath        = "/sign/vrf"
+)
+
+type publicKeysResponse struct {
+	PublicKeys []crypto.PublicKey `json:"publicKeys"`
+}
+
+// blockRequest is the request to sign the block or the micro block. The block is serialized to protobuf or,
+// for the legacy versions, to the binary format.
+type blockRequest struct {
+	PublicKey crypto.PublicKey `json:"publicKey"`
+	Protobuf  bool             `json:"protobuf"`
+	Data      []byte           `json:"data"`
+}
+
+type invRequest struct {
+	PublicKey crypto.PublicKey `json:"publicKey"`
+	Data      []byte           `json:"data"`
+}
+
+type txRequest struct {
+	PublicKey   crypto.PublicKey `json:"publicKey"`
+	Transaction json.RawMessage  `json:"transaction"`
+}
+
+type txResponse struct {
+	Transaction json.RawMessage `json:"transaction"`
+}
+
+type vrfRequest struct {
+	PublicKey crypto.PublicKey `json:"publicKey"`
+	Message   []byte           `json:"message"`
+}
+
+type signatureResponse struct {
+	Signature crypto.Signature `json:"signature"`
+}
+
+type vrfResponse struct {
+	Proof []byte `json:"proof"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
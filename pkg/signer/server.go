@@ -0,0 +1,242 @@
+package signer
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/types"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+const maxRequestSize = 10 << 20
+
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(err error) error {
+	return &httpError{status: http.StatusBadRequest, err: err}
+}
+
+// HandlerOptions are the settings of the signer daemon API.
+type HandlerOptions struct {
+	Scheme proto.Scheme
+	// APIKey is required in all requests, it must not be empty.
+	APIKey string
+	// AllowTransactions enables signing of transactions. Only blocks and VRF proofs are signed by default.
+	AllowTransactions bool
+}
+
+type handler struct {
+	signer types.Signer
+	opts   HandlerOptions
+}
+
+// NewHandler creates the HTTP API of the signer daemon on top of the signer, usually the embedded wallet.
+// The objects to sign are checked to be generated or sent by the requested account.
+func NewHandler(s types.Signer, opts HandlerOptions) (http.Handler, error) {
+	if opts.APIKey == "" {
+		return nil, errors.New("empty API key")
+	}
+	h := &handler{signer: s, opts: opts}
+	r := chi.NewRouter()
+	r.Use(h.auth)
+	r.Get(publicKeysPath, h.wrap(h.publicKeys))
+	r.Post(signBlockPath, h.wrap(h.signBlock))
+	r.Post(signMicroBlockPath, h.wrap(h.signMicroBlock))
+	r.Post(signInvPath, h.wrap(h.signInv))
+	r.Post(signTxPath, h.wrap(h.signTransaction))
+	r.Post(signVRFPath, h.wrap(h.signVRF))
+	return r, nil
+}
+
+func (h *handler) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(APIKeyHeader)
+		if subtle.ConstantTimeCompare([]byte(key), []byte(h.opts.APIKey)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Message: "invalid API key"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *handler) wrap(f func(r *http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+		resp, err := f(r)
+		if err != nil {
+			status := http.StatusInternalServerError
+			var he *httpError
+			switch {
+			case errors.As(err, &he):
+				status = he.status
+			case errors.Is(err, wallet.PublicKeyNotFound):
+				status = http.StatusNotFound
+			}
+			zap.S().Warnf("Failed to handle request '%s' from '%s': %v", r.URL.Path, r.RemoteAddr, err)
+			writeJSON(w, status, errorResponse{Message: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		zap.S().Debugf("Failed to write response: %v", err)
+	}
+}
+
+func decodeRequest(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest(errors.Wrap(err, "invalid request"))
+	}
+	return nil
+}
+
+func checkAccount(requested, actual crypto.PublicKey) error {
+	if requested != actual {
+		return badRequest(errors.Errorf("public key '%s' doesn't match the requested '%s'", actual, requested))
+	}
+	return nil
+}
+
+func (h *handler) publicKeys(*http.Request) (any, error) {
+	pks, err := h.signer.PublicKeys()
+	if err != nil {
+		return nil, err
+	}
+	return publicKeysResponse{PublicKeys: pks}, nil
+}
+
+func (h *handler) signBlock(r *http.Request) (any, error) {
+	var req blockRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	b := new(proto.Block)
+	var err error
+	if req.Protobuf {
+		err = b.UnmarshalFromProtobuf(req.Data)
+	} else {
+		err = b.UnmarshalBinary(req.Data, h.opts.Scheme)
+	}
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "invalid block"))
+	}
+	if err := checkAccount(req.PublicKey, b.GeneratorPublicKey); err != nil {
+		return nil, err
+	}
+	if err := h.signer.SignBlock(req.PublicKey, b); err != nil {
+		return nil, err
+	}
+	zap.S().Infof("Signed block of generator '%s' with parent '%s'", req.PublicKey, b.Parent)
+	return signatureResponse{Signature: b.BlockSignature}, nil
+}
+
+func (h *handler) signMicroBlock(r *http.Request) (any, error) {
+	var req blockRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	m := new(proto.MicroBlock)
+	var err error
+	if req.Protobuf {
+		err = m.UnmarshalFromProtobuf(req.Data)
+	} else {
+		err = m.UnmarshalBinary(req.Data, h.opts.Scheme)
+	}
+	if err != nil {
+		return nil, badRequest(errors.Wrap(err, "invalid micro block"))
+	}
+	if err := checkAccount(req.PublicKey, m.SenderPK); err != nil {
+		return nil, err
+	}
+	if err := h.signer.SignMicroBlock(req.PublicKey, m); err != nil {
+		return nil, err
+	}
+	zap.S().Debugf("Signed micro block of generator '%s' referencing '%s'", req.PublicKey, m.Reference)
+	return signatureResponse{Signature: m.Signature}, nil
+}
+
+func (h *handler) signInv(r *http.Request) (any, error) {
+	var req invRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	inv := new(proto.MicroBlockInv)
+	if err := inv.UnmarshalBinary(req.Data); err != nil {
+		return nil, badRequest(errors.Wrap(err, "invalid micro block inv"))
+	}
+	if err := checkAccount(req.PublicKey, inv.PublicKey); err != nil {
+		return nil, err
+	}
+	if err := h.signer.SignMicroBlockInv(req.PublicKey, inv); err != nil {
+		return nil, err
+	}
+	return signatureResponse{Signature: inv.Signature}, nil
+}
+
+func (h *handler) signTransaction(r *http.Request) (any, error) {
+	if !h.opts.AllowTransactions {
+		return nil, &httpError{status: http.StatusForbidden, err: errors.New("signing of transactions is disabled")}
+	}
+	var req txRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	tt := proto.TransactionTypeVersion{}
+	if err := json.Unmarshal(req.Transaction, &tt); err != nil {
+		return nil, badRequest(errors.Wrap(err, "invalid transaction"))
+	}
+	tx, err := proto.GuessTransactionType(&tt)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+	if err := proto.UnmarshalTransactionFromJSON(req.Transaction, h.opts.Scheme, tx); err != nil {
+		return nil, badRequest(errors.Wrap(err, "invalid transaction"))
+	}
+	v, ok := tx.(selfVerifier)
+	if !ok {
+		return nil, badRequest(errors.Errorf("unsupported transaction type %T", tx))
+	}
+	if err := checkAccount(req.PublicKey, v.GetSenderPK()); err != nil {
+		return nil, err
+	}
+	if err := h.signer.SignTransactionWith(req.PublicKey, tx); err != nil {
+		return nil, err
+	}
+	js, err := json.Marshal(tx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal transaction")
+	}
+	zap.S().Infof("Signed transaction of type %d by '%s'", tx.GetType(), req.PublicKey)
+	return txResponse{Transaction: js}, nil
+}
+
+func (h *handler) signVRF(r *http.Request) (any, error) {
+	var req vrfRequest
+	if err := decodeRequest(r, &req); err != nil {
+		return nil, err
+	}
+	proof, err := h.signer.SignVRF(req.PublicKey, req.Message)
+	if err != nil {
+		return nil, err
+	}
+	return vrfResponse{Proof: proof}, nil
+}
@@ -0,0 +1,124 @@
+package signer
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+const (
+	testAPIKey = "secret"
+	testSeed   = "generator seed"
+)
+
+func newTestSigner(t *testing.T, allowTransactions bool) (*RemoteSigner, proto.KeyPair) {
+	kp, err := proto.NewKeyPair([]byte(testSeed))
+	require.NoError(t, err)
+	w := wallet.NewEmbeddedWallet(nil, wallet.Stub{S: [][]byte{[]byte(testSeed)}}, proto.TestNetScheme)
+	h, err := NewHandler(w, HandlerOptions{
+		Scheme:            proto.TestNetScheme,
+		APIKey:            testAPIKey,
+		AllowTransactions: allowTransactions,
+	})
+	require.NoError(t, err)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	s, err := NewRemoteSigner(srv.URL, testAPIKey, proto.TestNetScheme, time.Second)
+	require.NoError(t, err)
+	return s, kp
+}
+
+func TestRemoteSigner_Blocks(t *testing.T) {
+	s, kp := newTestSigner(t, false)
+
+	pks, err := s.PublicKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []crypto.PublicKey{kp.Public}, pks)
+
+	for v, parent := range map[proto.BlockVersion]proto.BlockID{
+		proto.NgBlockVersion:       proto.NewBlockIDFromSignature(crypto.Signature{1}),
+		proto.ProtobufBlockVersion: proto.NewBlockIDFromDigest(crypto.Digest{1}),
+	} {
+		nxt := proto.NxtConsensus{BaseTarget: 100, GenSignature: make([]byte, 32)}
+		b, err := proto.CreateBlock(proto.Transactions{}, 1000, parent,
+			kp.Public, nxt, v, nil, -1, proto.TestNetScheme, nil)
+		require.NoError(t, err)
+		require.NoError(t, s.SignBlock(kp.Public, b))
+		ok, err := b.VerifySignature(proto.TestNetScheme)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NoError(t, b.GenerateBlockID(proto.TestNetScheme))
+		m := &proto.MicroBlock{
+			VersionField:          byte(v),
+			SenderPK:              kp.Public,
+			Transactions:          proto.Transactions{},
+			Reference:             b.BlockID(),
+			TotalResBlockSigField: b.BlockSignature,
+			TotalBlockID:          b.BlockID(),
+		}
+		require.NoError(t, s.SignMicroBlock(kp.Public, m))
+		ok, err = m.VerifySignature(proto.TestNetScheme)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	inv := proto.NewUnsignedMicroblockInv(kp.Public, proto.NewBlockIDFromDigest(crypto.Digest{2}),
+		proto.NewBlockIDFromDigest(crypto.Digest{3}))
+	require.NoError(t, s.SignMicroBlockInv(kp.Public, inv))
+	ok, err := inv.Verify(proto.TestNetScheme)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	msg := []byte("hit source")
+	proof, err := s.SignVRF(kp.Public, msg)
+	require.NoError(t, err)
+	ok, _, err = crypto.VerifyVRF(kp.Public, msg, proof)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// The account is checked, the daemon doesn't sign the block of another generator.
+	other, err := proto.NewKeyPair([]byte("other seed"))
+	require.NoError(t, err)
+	_, err = s.SignVRF(other.Public, msg)
+	assert.ErrorIs(t, err, wallet.PublicKeyNotFound)
+	inv = proto.NewUnsignedMicroblockInv(other.Public, proto.NewBlockIDFromDigest(crypto.Digest{2}),
+		proto.NewBlockIDFromDigest(crypto.Digest{3}))
+	assert.ErrorContains(t, s.SignMicroBlockInv(kp.Public, inv), "doesn't match")
+}
+
+func TestRemoteSigner_Transactions(t *testing.T) {
+	recipient, err := proto.NewKeyPair([]byte("recipient seed"))
+	require.NoError(t, err)
+	addr, err := recipient.Addr(proto.TestNetScheme)
+	require.NoError(t, err)
+	newTx := func(pk crypto.PublicKey) *proto.TransferWithProofs {
+		return proto.NewUnsignedTransferWithProofs(3, pk, proto.NewOptionalAssetWaves(),
+			proto.NewOptionalAssetWaves(), 1000, 1, 100000, proto.NewRecipientFromAddress(addr), nil)
+	}
+
+	s, kp := newTestSigner(t, false)
+	assert.ErrorContains(t, s.SignTransactionWith(kp.Public, newTx(kp.Public)), "disabled")
+
+	s, kp = newTestSigner(t, true)
+	tx := newTx(kp.Public)
+	require.NoError(t, s.SignTransactionWith(kp.Public, tx))
+	ok, err := tx.Verify(proto.TestNetScheme, kp.Public)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.NotNil(t, tx.ID)
+}
+
+func TestRemoteSigner_InvalidAPIKey(t *testing.T) {
+	s, _ := newTestSigner(t, false)
+	s.apiKey = "wrong"
+	_, err := s.PublicKeys()
+	assert.ErrorContains(t, err, "invalid API key")
+}
@@ -347,6 +347,36 @@ func (s *accountsDataStorage) retrieveEntry(addr proto.Address, key string) (pro
 	return entry, nil
 }
 
+// retrieveEntryAtHeight returns the data entry stored at the given height.
+// The height must be within the range of heights the history is kept for.
+func (s *accountsDataStorage) retrieveEntryAtHeight(addr proto.Address, key string, height proto.Height) (proto.DataEntry, error) {
+	addrNum, err := s.addrToNum(addr)
+	if err != nil {
+		return nil, err
+	}
+	storKey := accountsDataStorKey{addrNum, key}
+	recordBytes, err := s.hs.entryDataAtHeight(storKey.bytes(), height)
+	if err != nil {
+		return nil, err
+	}
+	if recordBytes == nil {
+		return nil, keyvalue.ErrNotFound
+	}
+	var record dataEntryRecord
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return nil, err
+	}
+	entry, err := proto.NewDataEntryFromValueBytes(record.value)
+	if err != nil {
+		return nil, err
+	}
+	if entry.GetValueType() == proto.DataDelete {
+		return nil, keyvalue.ErrNotFound // The entry was removed at the height, so it doesn't exist as of the height.
+	}
+	entry.SetKey(key)
+	return entry, nil
+}
+
 func (s *accountsDataStorage) retrieveNewestIntegerEntry(addr proto.Address, key string) (*proto.IntegerDataEntry, error) {
 	id := entryId{addr.ID(), key}
 	if entry, ok := s.uncertainEntries[id]; ok {
@@ -4,6 +4,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 )
 
@@ -86,6 +89,55 @@ func TestRetrieveEntries(t *testing.T) {
 	assert.ElementsMatch(t, properEntries, entries)
 }
 
+func TestRetrieveEntryAtHeight(t *testing.T) {
+	to := createAccountsDataStorage(t, true)
+
+	addr := testGlobal.senderInfo.addr
+	const key = "Whatever"
+	to.stor.addBlock(t, blockID0)
+	entry0 := &proto.IntegerDataEntry{Key: key, Value: 100500}
+	require.NoError(t, to.accountsDataStor.appendEntry(addr, entry0, blockID0))
+	to.stor.addBlock(t, blockID1)
+	entry1 := &proto.StringDataEntry{Key: key, Value: "value"}
+	require.NoError(t, to.accountsDataStor.appendEntry(addr, entry1, blockID1))
+	to.stor.addBlock(t, blockID2)
+	require.NoError(t, to.accountsDataStor.appendEntry(addr, &proto.DeleteDataEntry{Key: key}, blockID2))
+	to.stor.flush(t)
+
+	entry, err := to.accountsDataStor.retrieveEntryAtHeight(addr, key, 1)
+	require.NoError(t, err)
+	assert.Equal(t, entry0, entry)
+	entry, err = to.accountsDataStor.retrieveEntryAtHeight(addr, key, 2)
+	require.NoError(t, err)
+	assert.Equal(t, entry1, entry)
+	_, err = to.accountsDataStor.retrieveEntryAtHeight(addr, "unknown", 2)
+	assert.ErrorIs(t, err, keyvalue.ErrNotFound)
+}
+
+func TestRetrieveEntryAtHeightAfterDelete(t *testing.T) {
+	to := createAccountsDataStorage(t, true)
+
+	addr := testGlobal.senderInfo.addr
+	const key = "Whatever"
+	to.stor.addBlock(t, blockID0)
+	entry := &proto.IntegerDataEntry{Key: key, Value: 100500}
+	require.NoError(t, to.accountsDataStor.appendEntry(addr, entry, blockID0))
+	to.stor.addBlock(t, blockID1)
+	require.NoError(t, to.accountsDataStor.appendEntry(addr, &proto.DeleteDataEntry{Key: key}, blockID1))
+	to.stor.addBlock(t, blockID2)
+	to.stor.flush(t)
+
+	actual, err := to.accountsDataStor.retrieveEntryAtHeight(addr, key, 1)
+	require.NoError(t, err)
+	assert.Equal(t, entry, actual)
+	// The entry is reported as missing both at the height of deletion and above it.
+	for _, height := range []proto.Height{2, 3} {
+		_, err = to.accountsDataStor.retrieveEntryAtHeight(addr, key, height)
+		assert.ErrorIs(t, err, keyvalue.ErrNotFound, "height %d", height)
+		assert.True(t, IsNotFound(wrapErr(RetrievalError, err)), "height %d", height)
+	}
+}
+
 func TestRollbackEntry(t *testing.T) {
 	to := createAccountsDataStorage(t, true)
 
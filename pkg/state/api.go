@@ -10,6 +10,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/libs/ntptime"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
 	"github.com/wavesplatform/gowaves/pkg/ride/ast"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/types"
@@ -28,6 +29,12 @@ type TransactionIterator interface {
 	Error() error
 }
 
+// AssetHolder is the address with non-zero balance of an asset.
+type AssetHolder struct {
+	Address proto.WavesAddress
+	Balance uint64
+}
+
 // StateInfo returns information that corresponds to latest fully applied block.
 // This should be used for APIs and other modules where stable, fully verified state is needed.
 // Methods of this interface are thread-safe.
@@ -50,6 +57,24 @@ type StateInfo interface {
 	GeneratingBalance(account proto.Recipient, height proto.Height) (uint64, error)
 	// AssetBalance retrieves balance of account in specific currency, asset is asset's ID.
 	AssetBalance(account proto.Recipient, assetID proto.AssetID) (uint64, error)
+	// WavesBalanceAtHeight and AssetBalanceAtHeight return balances of account as of the given height.
+	// The height must be within the rollback window, otherwise InvalidInputError is returned.
+	WavesBalanceAtHeight(account proto.Recipient, height proto.Height) (uint64, error)
+	AssetBalanceAtHeight(account proto.Recipient, assetID proto.AssetID, height proto.Height) (uint64, error)
+	// MinWavesBalanceInRange returns minimal regular Waves balance of account in range [startHeight, endHeight].
+	MinWavesBalanceInRange(account proto.Recipient, startHeight, endHeight proto.Height) (uint64, error)
+	// MinEffectiveBalanceInRange returns minimal effective balance of account in range [startHeight, endHeight].
+	MinEffectiveBalanceInRange(account proto.Recipient, startHeight, endHeight proto.Height) (uint64, error)
+	// AssetBalances returns non-zero balances of all assets of account, keys are full asset IDs.
+	AssetBalances(account proto.Recipient) (map[crypto.Digest]uint64, error)
+	// AssetDistribution returns no more than limit holders of given asset ordered by addresses, starting right
+	// after the given address if it's not nil. The flag reports whether there are more holders to return.
+	// Balances at the given height are returned, zero height means the current one. Non-zero height must be
+	// within the rollback window, otherwise InvalidInputError is returned.
+	// It iterates over asset balances of all assets until the page is filled, so it is slow for rare assets.
+	AssetDistribution(
+		assetID proto.AssetID, height proto.Height, after *proto.WavesAddress, limit int,
+	) ([]AssetHolder, bool, error)
 	// WavesAddressesNumber returns total number of Waves addresses in state.
 	// It is extremely slow, so it is recommended to only use for testing purposes.
 	WavesAddressesNumber() (uint64, error)
@@ -82,6 +107,8 @@ type StateInfo interface {
 	// Accounts data storage.
 	RetrieveEntries(account proto.Recipient) ([]proto.DataEntry, error)
 	RetrieveEntry(account proto.Recipient, key string) (proto.DataEntry, error)
+	// RetrieveEntryAtHeight returns data entry as of the given height within the rollback window.
+	RetrieveEntryAtHeight(account proto.Recipient, key string, height proto.Height) (proto.DataEntry, error)
 	RetrieveIntegerEntry(account proto.Recipient, key string) (*proto.IntegerDataEntry, error)
 	RetrieveBooleanEntry(account proto.Recipient, key string) (*proto.BooleanDataEntry, error)
 	RetrieveStringEntry(account proto.Recipient, key string) (*proto.StringDataEntry, error)
@@ -91,6 +118,9 @@ type StateInfo interface {
 	TransactionByID(id []byte) (proto.Transaction, error)
 	TransactionByIDWithStatus(id []byte) (proto.Transaction, proto.TransactionStatus, error)
 	TransactionHeightByID(id []byte) (uint64, error)
+	// TraceInvoke replays the invoke transaction against the newest state recording up to maxSteps steps
+	// of script evaluation.
+	TraceInvoke(id []byte, maxSteps int) (*InvokeTrace, error)
 	// NewAddrTransactionsIterator() returns iterator to iterate all transactions that affected
 	// given address.
 	// Iterator will move in range from most recent to oldest transactions.
@@ -108,11 +138,17 @@ type StateInfo interface {
 	ScriptInfoByAccount(account proto.Recipient) (*proto.ScriptInfo, error)
 	ScriptInfoByAsset(assetID proto.AssetID) (*proto.ScriptInfo, error)
 	NewestScriptByAccount(account proto.Recipient) (*ast.Tree, error)
+	// ScriptByAccountAtHeight returns account script as of the given height within the rollback window.
+	ScriptByAccountAtHeight(account proto.Recipient, height proto.Height) (*ast.Tree, error)
 	NewestScriptBytesByAccount(account proto.Recipient) (proto.Script, error)
 
 	// Leases.
 	IsActiveLeasing(leaseID crypto.Digest) (bool, error)
 
+	// Fees.
+	// MinFee returns the minimal fee of the transaction in its fee asset according to the current state.
+	MinFee(tx proto.Transaction) (uint64, error)
+
 	// Invoke results.
 	InvokeResultByID(invokeID crypto.Digest) (*proto.ScriptResult, error)
 	// True if state stores additional information in order to provide extended API.
@@ -187,6 +223,15 @@ type StateModifier interface {
 		blockVersion proto.BlockVersion,
 		acceptFailed bool,
 	) ([]proto.AtomicSnapshot, error)
+	// DryRunNextTx() validates transaction like ValidateNextTx() does, but doesn't accept failed transactions and
+	// returns the details of the validation. Signatures are not verified if checkSignatures is false.
+	// The result is returned even if the transaction is invalid.
+	DryRunNextTx(
+		tx proto.Transaction,
+		currentTimestamp, parentTimestamp uint64,
+		blockVersion proto.BlockVersion,
+		checkSignatures bool,
+	) (*DryRunResult, error)
 	// ResetValidationList() resets the validation list, so you can ValidateNextTx() from scratch after calling it.
 	ResetValidationList()
 
@@ -214,6 +259,12 @@ type TxValidation interface {
 		blockVersion proto.BlockVersion,
 		acceptFailed bool,
 	) ([]proto.AtomicSnapshot, error)
+	DryRunNextTx(
+		tx proto.Transaction,
+		currentTimestamp, parentTimestamp uint64,
+		blockVersion proto.BlockVersion,
+		checkSignatures bool,
+	) (*DryRunResult, error)
 }
 
 type State interface {
@@ -277,6 +328,8 @@ func DefaultTestingStorageParams() StorageParams {
 type ValidationParams struct {
 	VerificationGoroutinesNum int
 	Time                      types.Time
+	// RideExecution selects the engine that runs verifiers and callable functions.
+	RideExecution ride.ExecutionMode
 }
 
 type StateParams struct {
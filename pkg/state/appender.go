@@ -10,6 +10,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/errs"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/types"
 )
@@ -64,12 +65,13 @@ func newTxAppender(
 	stateDB *stateDB,
 	atx *addressTransactions,
 	snapshotApplier *blockSnapshotsApplier,
+	executor *ride.Executor,
 ) (*txAppender, error) {
 	buildAPIData, err := stateDB.stateStoresApiData()
 	if err != nil {
 		return nil, err
 	}
-	sc, err := newScriptCaller(state, stor, settings)
+	sc, err := newScriptCaller(state, stor, settings, executor)
 	if err != nil {
 		return nil, err
 	}
@@ -381,11 +383,14 @@ func (a *txAppender) commitTxApplication(
 func (a *txAppender) verifyWavesTxSigAndData(tx proto.Transaction, params *appendTxParams, accountHasVerifierScript bool) error {
 	// Detect what signatures must be checked for this transaction.
 	// For transaction with SmartAccount we don't check signature.
-	checkTxSig := !accountHasVerifierScript
+	checkTxSig := !accountHasVerifierScript && !params.skipSignatures
 	checkOrder1, checkOrder2, err := a.needToCheckOrdersSignatures(tx)
 	if err != nil {
 		return err
 	}
+	if params.skipSignatures {
+		checkOrder1, checkOrder2 = false, false
+	}
 	if checkSequentially := params.validatingUtx; checkSequentially {
 		vp := proto.TransactionValidationParams{
 			Scheme:       a.settings.AddressSchemeCharacter,
@@ -424,6 +429,8 @@ type appendTxParams struct {
 	lightNodeActivated               bool
 	validatingUtx                    bool // if validatingUtx == false then chans MUST be initialized with non nil value
 	currentMinerPK                   crypto.PublicKey
+	skipSignatures                   bool          // signatures are not verified, scripts are evaluated anyway
+	dryRun                           *DryRunResult // can be nil, collects the details of transaction validation
 }
 
 func (a *txAppender) handleInvokeOrExchangeTransaction(
@@ -547,7 +554,11 @@ func (a *txAppender) handleTxAndScripts(
 }
 
 func (a *txAppender) appendTx(tx proto.Transaction, params *appendTxParams) (txSnapshot, error) {
+	initialComplexity := a.sc.getTotalComplexity()
 	defer func() {
+		if params.dryRun != nil {
+			params.dryRun.Complexity = int(a.sc.getTotalComplexity() - initialComplexity)
+		}
 		a.sc.resetRecentTxComplexity()
 		a.stor.dropUncertain()
 	}()
@@ -603,6 +614,14 @@ func (a *txAppender) appendTx(tx proto.Transaction, params *appendTxParams) (txS
 		return txSnapshot{}, errs.Extend(err, "get transaction id")
 	}
 
+	if params.dryRun != nil && invocationResult != nil {
+		sr, srErr := toScriptResult(invocationResult)
+		if srErr != nil {
+			return txSnapshot{}, errs.Extend(srErr, "convert invocation result")
+		}
+		params.dryRun.Actions = invocationResult.actions
+		params.dryRun.ScriptResult = sr
+	}
 	// invocationResult may be empty if it was not an Invoke Transaction
 	snapshot, err := a.commitTxApplication(tx, params, invocationResult, applicationRes)
 	if err != nil {
@@ -615,6 +634,13 @@ func (a *txAppender) appendTx(tx proto.Transaction, params *appendTxParams) (txS
 			return txSnapshot{}, errs.Extend(err, "save transaction id by addresses")
 		}
 	}
+	if !params.validatingUtx {
+		if applicationRes.status {
+			countTransaction(tx, txStatusSucceeded)
+		} else {
+			countTransaction(tx, txStatusFailed)
+		}
+	}
 	return snapshot, nil
 }
 
@@ -709,6 +735,7 @@ func (a *txAppender) appendTxs(
 				return proto.BlockSnapshot{}, crypto.Digest{}, errAppendTx
 			}
 			zap.S().Debugf("Elided tx detected (ID=%q): %v", base58.Encode(txID), errAppendTx)
+			countTransaction(tx, txStatusElided)
 			txSnap = txSnapshot{
 				regular: []proto.AtomicSnapshot{
 					&proto.TransactionStatusSnapshot{Status: proto.TransactionElided},
@@ -968,6 +995,7 @@ type fallibleValidationParams struct {
 	*appendTxParams
 	senderScripted bool
 	senderAddress  proto.Address
+	tracer         *ride.Tracer // can be nil, records the evaluation of invoked script
 }
 
 type applicationResult struct {
@@ -1150,27 +1178,61 @@ func (a *txAppender) validateNextTx(
 	version proto.BlockVersion,
 	acceptFailed bool,
 ) ([]proto.AtomicSnapshot, error) {
+	snapshot, err := a.validateNextTxWithParams(tx, currentTimestamp, parentTimestamp, version, acceptFailed, false, nil)
+	if err != nil {
+		return nil, proto.NewInfoMsg(err)
+	}
+	return snapshot.regular, nil
+}
+
+// dryRunNextTx validates the transaction like validateNextTx does and collects the details of its validation.
+// Failed transactions are not accepted. Signatures are not verified if checkSignatures is false.
+// The result is returned even if the transaction is invalid.
+func (a *txAppender) dryRunNextTx(
+	tx proto.Transaction,
+	currentTimestamp,
+	parentTimestamp uint64,
+	version proto.BlockVersion,
+	checkSignatures bool,
+) (*DryRunResult, error) {
+	r := new(DryRunResult)
+	snapshot, err := a.validateNextTxWithParams(tx, currentTimestamp, parentTimestamp, version, false, !checkSignatures, r)
+	if err != nil {
+		return r, err
+	}
+	r.Snapshots = snapshot.regular
+	return r, nil
+}
+
+func (a *txAppender) validateNextTxWithParams(
+	tx proto.Transaction,
+	currentTimestamp,
+	parentTimestamp uint64,
+	version proto.BlockVersion,
+	acceptFailed, skipSignatures bool,
+	dryRun *DryRunResult,
+) (txSnapshot, error) {
 	// TODO: Doesn't work correctly if miner doesn't work in NG mode.
 	// In this case it returns the last block instead of what is being mined.
 	block, err := a.currentBlock()
 	if err != nil {
-		return nil, errs.Extend(err, "failed get currentBlock")
+		return txSnapshot{}, errs.Extend(err, "failed get currentBlock")
 	}
 	blockInfo, err := a.currentBlockInfo()
 	if err != nil {
-		return nil, errs.Extend(err, "failed get currentBlockInfo")
+		return txSnapshot{}, errs.Extend(err, "failed get currentBlockInfo")
 	}
 	rideV5Activated, err := a.stor.features.newestIsActivated(int16(settings.RideV5))
 	if err != nil {
-		return nil, errs.Extend(err, "failed to check 'RideV5' is activated")
+		return txSnapshot{}, errs.Extend(err, "failed to check 'RideV5' is activated")
 	}
 	rideV6Activated, err := a.stor.features.newestIsActivated(int16(settings.RideV6))
 	if err != nil {
-		return nil, errs.Extend(err, "failed to check 'RideV6' is activated")
+		return txSnapshot{}, errs.Extend(err, "failed to check 'RideV6' is activated")
 	}
 	blockRewardDistribution, err := a.stor.features.newestIsActivated(int16(settings.BlockRewardDistribution))
 	if err != nil {
-		return nil, errs.Extend(err, "failed to check 'BlockRewardDistribution' is activated")
+		return txSnapshot{}, errs.Extend(err, "failed to check 'BlockRewardDistribution' is activated")
 	}
 	blockInfo.Timestamp = currentTimestamp
 	checkerInfo := &checkerInfo{
@@ -1185,19 +1247,19 @@ func (a *txAppender) validateNextTx(
 	}
 	blockV5Activated, err := a.stor.features.newestIsActivated(int16(settings.BlockV5))
 	if err != nil {
-		return nil, errs.Extend(err, "failed to check 'BlockV5' is activated")
+		return txSnapshot{}, errs.Extend(err, "failed to check 'BlockV5' is activated")
 	}
 	consensusImprovementsActivated, err := a.stor.features.newestIsActivated(int16(settings.ConsensusImprovements))
 	if err != nil {
-		return nil, errs.Extend(err, "failed to check 'ConsensusImprovements' is activated")
+		return txSnapshot{}, errs.Extend(err, "failed to check 'ConsensusImprovements' is activated")
 	}
 	blockRewardDistributionActivated, err := a.stor.features.newestIsActivated(int16(settings.BlockRewardDistribution))
 	if err != nil {
-		return nil, errs.Extend(err, "failed to check 'BlockRewardDistribution' is activated")
+		return txSnapshot{}, errs.Extend(err, "failed to check 'BlockRewardDistribution' is activated")
 	}
 	lightNodeActivated, err := a.stor.features.newestIsActivated(int16(settings.LightNode))
 	if err != nil {
-		return nil, errs.Extend(err, "failed to check 'Light Node' is activated")
+		return txSnapshot{}, errs.Extend(err, "failed to check 'Light Node' is activated")
 	}
 	// it's correct to use new proto.StateActionsCounter because there's no block exists,
 	// but this field is necessary in tx performer
@@ -1218,12 +1280,10 @@ func (a *txAppender) validateNextTx(
 		blockRewardDistributionActivated: blockRewardDistributionActivated,
 		lightNodeActivated:               lightNodeActivated,
 		validatingUtx:                    true,
+		skipSignatures:                   skipSignatures,
+		dryRun:                           dryRun,
 	}
-	snapshot, err := a.appendTx(tx, appendTxArgs)
-	if err != nil {
-		return nil, proto.NewInfoMsg(err)
-	}
-	return snapshot.regular, nil
+	return a.appendTx(tx, appendTxArgs)
 }
 
 func (a *txAppender) createNextSnapshotHash(
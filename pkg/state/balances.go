@@ -581,6 +581,135 @@ func (s *balances) wavesAddressesNumber() (uint64, error) {
 	return addressesNumber, nil
 }
 
+// assetBalances returns non-zero balances of all assets of the given address.
+func (s *balances) assetBalances(addr proto.AddressID) (map[crypto.Digest]uint64, error) {
+	key := assetBalanceKey{address: addr}
+	iter, err := s.hs.newTopEntryIteratorByPrefix(key.addressPrefix())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			zap.S().Fatalf("Iterator error: %v", err)
+		}
+	}()
+
+	res := make(map[crypto.Digest]uint64)
+	var (
+		k assetBalanceKey
+		r assetBalanceRecord
+	)
+	for iter.Next() {
+		if err := r.unmarshalBinary(keyvalue.SafeValue(iter)); err != nil {
+			return nil, err
+		}
+		if r.balance == 0 {
+			continue
+		}
+		if err := k.unmarshal(keyvalue.SafeKey(iter)); err != nil {
+			return nil, err
+		}
+		ai, err := s.assets.assetInfo(k.asset)
+		if err != nil {
+			return nil, err
+		}
+		res[proto.ReconstructDigest(k.asset, ai.Tail)] = r.balance
+	}
+	return res, nil
+}
+
+// addressBalance is the balance of the address.
+type addressBalance struct {
+	address proto.AddressID
+	balance uint64
+}
+
+// assetDistribution returns no more than limit non-zero balances of the given asset ordered by address IDs,
+// starting right after the given address ID if it's not nil. The balances at the given height are returned,
+// zero height means the latest stored balances. The flag reports whether there are more balances to return.
+// Balances of all assets are iterated until the page is filled, so it is slow for rare assets.
+func (s *balances) assetDistribution(
+	assetID proto.AssetID,
+	height proto.Height,
+	after *proto.AddressID,
+	limit int,
+) ([]addressBalance, bool, error) {
+	prefix := []byte{assetBalanceKeyPrefix}
+	var start []byte
+	if after != nil {
+		start = (&assetBalanceKey{address: *after, asset: assetID}).bytes()
+	}
+	var (
+		iter *topEntryIterator
+		err  error
+	)
+	if height == 0 {
+		iter, err = s.hs.newTopEntryIteratorFrom(prefix, start)
+	} else {
+		iter, err = s.hs.newEntryAtHeightIteratorFrom(prefix, start, height)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			zap.S().Fatalf("Iterator error: %v", err)
+		}
+	}()
+
+	var (
+		res []addressBalance
+		k   assetBalanceKey
+		r   assetBalanceRecord
+	)
+	for iter.Next() {
+		if err := k.unmarshal(keyvalue.SafeKey(iter)); err != nil {
+			return nil, false, err
+		}
+		if k.asset != assetID || (after != nil && k.address == *after) {
+			continue
+		}
+		if err := r.unmarshalBinary(keyvalue.SafeValue(iter)); err != nil {
+			return nil, false, err
+		}
+		if r.balance == 0 {
+			continue
+		}
+		if len(res) == limit {
+			return res, true, nil
+		}
+		res = append(res, addressBalance{address: k.address, balance: r.balance})
+	}
+	return res, false, nil
+}
+
+// minBalanceInRange returns minimal regular balance in range [startHeight, endHeight].
+func (s *balances) minBalanceInRange(addr proto.AddressID, startHeight, endHeight uint64) (uint64, error) {
+	key := wavesBalanceKey{address: addr}
+	records, err := s.hs.entriesDataInHeightRange(key.bytes(), startHeight, endHeight)
+	if err != nil {
+		if isNotFoundInHistoryOrDBErr(err) {
+			// Unknown address, expected behavior is to return 0 and no errors in this case.
+			return 0, nil
+		}
+		return 0, err
+	}
+	minBalance := uint64(math.MaxUint64)
+	for _, recordBytes := range records {
+		var record wavesBalanceRecord
+		if err := record.unmarshalBinary(recordBytes); err != nil {
+			return 0, err
+		}
+		minBalance = min(minBalance, record.balance)
+	}
+	if minBalance == math.MaxUint64 {
+		minBalance = 0
+	}
+	return minBalance, nil
+}
+
 func minEffectiveBalanceInRangeCommon(records [][]byte) (uint64, error) {
 	minBalance := uint64(math.MaxUint64)
 	for _, recordBytes := range records {
@@ -798,6 +927,20 @@ func (s *balances) assetBalance(addr proto.AddressID, assetID proto.AssetID) (ui
 	return s.assetBalanceFromRecordBytes(recordBytes)
 }
 
+// assetBalanceAtHeight returns stored asset balance at the given height.
+// The height must be within the range of heights the history is kept for.
+func (s *balances) assetBalanceAtHeight(addr proto.AddressID, assetID proto.AssetID, height proto.Height) (uint64, error) {
+	key := assetBalanceKey{address: addr, asset: assetID}
+	recordBytes, err := s.hs.entryDataAtHeight(key.bytes(), height)
+	if err == keyvalue.ErrNotFound || err == errEmptyHist || (err == nil && recordBytes == nil) {
+		// Unknown address or no balance at this height, expected behavior is to return 0 and no errors in this case.
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return s.assetBalanceFromRecordBytes(recordBytes)
+}
+
 func (s *balances) newestAssetBalance(addr proto.AddressID, asset proto.AssetID) (uint64, error) {
 	key := assetBalanceKey{address: addr, asset: asset}
 	recordBytes, err := s.hs.newestTopEntryData(key.bytes())
@@ -861,6 +1004,24 @@ func (s *balances) wavesBalance(addr proto.AddressID) (balanceProfile, error) {
 	return r.balanceProfile, nil
 }
 
+// wavesBalanceAtHeight returns stored waves balanceProfile at the given height.
+// The height must be within the range of heights the history is kept for.
+func (s *balances) wavesBalanceAtHeight(addr proto.AddressID, height proto.Height) (balanceProfile, error) {
+	key := wavesBalanceKey{address: addr}
+	recordBytes, err := s.hs.entryDataAtHeight(key.bytes(), height)
+	if err == keyvalue.ErrNotFound || err == errEmptyHist || (err == nil && recordBytes == nil) {
+		// Unknown address or no balance at this height, expected behavior is to return empty profile.
+		return balanceProfile{}, nil
+	} else if err != nil {
+		return balanceProfile{}, err
+	}
+	var record wavesBalanceRecord
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return balanceProfile{}, errors.Wrapf(err, "failed to unmarshal data to %T", record)
+	}
+	return record.balanceProfile, nil
+}
+
 func (s *balances) calculateStateHashesAssetBalance(addr proto.AddressID, assetID proto.AssetID,
 	balance uint64, blockID proto.BlockID, keyStr string) error {
 	info, err := s.assets.newestConstInfo(assetID)
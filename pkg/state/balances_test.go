@@ -1,6 +1,8 @@
 package state
 
 import (
+	"bytes"
+	"slices"
 	"strconv"
 	"testing"
 
@@ -245,6 +247,93 @@ func TestMinBalanceInRange(t *testing.T) {
 	}
 }
 
+func TestBalancesAtHeight(t *testing.T) {
+	to := createBalances(t)
+
+	addr, err := proto.NewAddressFromString(addr0)
+	require.NoError(t, err, "NewAddressFromString() failed")
+	asset := proto.AssetIDFromDigest(genAsset(1))
+	addTailInfoToAssetsState(to.stor.entities.assets, genAsset(1))
+	generateBlocksWithIncreasingBalance(t, to, 10, addr.ID())
+	// Asset balance changes only at heights 3 and 7.
+	err = to.balances.setAssetBalance(addr.ID(), asset, 300, genBlockId(3))
+	require.NoError(t, err)
+	err = to.balances.setAssetBalance(addr.ID(), asset, 700, genBlockId(7))
+	require.NoError(t, err)
+	to.stor.flush(t)
+
+	for h := proto.Height(1); h <= 10; h++ {
+		profile, err := to.balances.wavesBalanceAtHeight(addr.ID(), h)
+		require.NoError(t, err)
+		assert.Equal(t, h, profile.balance)
+	}
+	for _, tc := range []struct {
+		height  proto.Height
+		balance uint64
+	}{{1, 0}, {2, 0}, {3, 300}, {6, 300}, {7, 700}, {10, 700}} {
+		balance, err := to.balances.assetBalanceAtHeight(addr.ID(), asset, tc.height)
+		require.NoError(t, err)
+		assert.Equal(t, tc.balance, balance, "height %d", tc.height)
+	}
+	unknown, err := proto.NewAddressFromString(addr1)
+	require.NoError(t, err)
+	profile, err := to.balances.wavesBalanceAtHeight(unknown.ID(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, balanceProfile{}, profile)
+}
+
+func TestAssetDistribution(t *testing.T) {
+	to := createBalances(t)
+
+	ids := make([]proto.AddressID, 0, 4)
+	for _, s := range []string{addr0, addr1, addr2, addr3} {
+		addr, err := proto.NewAddressFromString(s)
+		require.NoError(t, err)
+		ids = append(ids, addr.ID())
+	}
+	slices.SortFunc(ids, func(a, b proto.AddressID) int { return bytes.Compare(a[:], b[:]) })
+	asset := proto.AssetIDFromDigest(genAsset(1))
+	other := proto.AssetIDFromDigest(genAsset(2))
+	addTailInfoToAssetsState(to.stor.entities.assets, genAsset(1))
+	addTailInfoToAssetsState(to.stor.entities.assets, genAsset(2))
+	generateBlocksWithIncreasingBalance(t, to, 5, ids[0])
+	// At height 2 all addresses hold the asset, at height 4 the second one has no asset and the last one has
+	// only the other asset.
+	for i, id := range ids {
+		err := to.balances.setAssetBalance(id, asset, uint64(i+1), genBlockId(2))
+		require.NoError(t, err)
+	}
+	err := to.balances.setAssetBalance(ids[1], asset, 0, genBlockId(4))
+	require.NoError(t, err)
+	err = to.balances.setAssetBalance(ids[3], asset, 0, genBlockId(4))
+	require.NoError(t, err)
+	err = to.balances.setAssetBalance(ids[3], other, 10, genBlockId(4))
+	require.NoError(t, err)
+	to.stor.flush(t)
+
+	for _, tc := range []struct {
+		height   proto.Height
+		after    *proto.AddressID
+		limit    int
+		expected []addressBalance
+		hasNext  bool
+	}{
+		{0, nil, 10, []addressBalance{{ids[0], 1}, {ids[2], 3}}, false},
+		{0, nil, 1, []addressBalance{{ids[0], 1}}, true},
+		{0, &ids[0], 1, []addressBalance{{ids[2], 3}}, false},
+		{0, &ids[2], 1, nil, false},
+		{1, nil, 10, nil, false},
+		{3, nil, 10, []addressBalance{{ids[0], 1}, {ids[1], 2}, {ids[2], 3}, {ids[3], 4}}, false},
+		{3, &ids[0], 2, []addressBalance{{ids[1], 2}, {ids[2], 3}}, true},
+		{4, nil, 10, []addressBalance{{ids[0], 1}, {ids[2], 3}}, false},
+	} {
+		res, hasNext, err := to.balances.assetDistribution(asset, tc.height, tc.after, tc.limit)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, res, "height %d, limit %d", tc.height, tc.limit)
+		assert.Equal(t, tc.hasNext, hasNext, "height %d, limit %d", tc.height, tc.limit)
+	}
+}
+
 func TestBalancesChangesByStoredChallenge(t *testing.T) {
 	to := createBalances(t)
 
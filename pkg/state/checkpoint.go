@@ -0,0 +1,758 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	stderrs "errors"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+)
+
+const (
+	checkpointManifestFile = "checkpoint.json"
+	checkpointVersion      = 2
+	// checkpointStateDir is the directory of checkpoint with the files of state sections.
+	checkpointStateDir = "state"
+	// checkpointBlocksDir is the directory of checkpoint with the files of blocks storage.
+	checkpointBlocksDir = "blocks"
+	// checkpointWorkDir is the temporary directory of checkpoint with the copy of state during export.
+	checkpointWorkDir = ".work"
+	// lockFile is the name of database lock file, it's not a part of state.
+	lockFile = "LOCK"
+	// bloomFile is the name of stored bloom filter, it's rebuilt from the database if missing.
+	bloomFile = "bloom"
+	// chainSection is the section of all state records that don't belong to other sections.
+	chainSection = "chain"
+
+	maxCheckpointRecordLength = 256 * 1024 * 1024
+	checkpointImportBatchSize = 10000
+)
+
+// checkpointSections are the sections of state by the prefixes of database keys.
+// Records with other prefixes are exported to the chain section.
+var checkpointSections = []struct {
+	name     string
+	prefixes []byte
+}{
+	{"balances", []byte{wavesBalanceKeyPrefix, assetBalanceKeyPrefix}},
+	{"assets", []byte{assetConstKeyPrefix, assetHistKeyPrefix, sponsorshipKeyPrefix}},
+	{"data", []byte{lastAccountsStorAddrNumKeyPrefix, accountStorAddrToNumKeyPrefix, accountsDataStorKeyPrefix}},
+	{"scripts", []byte{accountScriptKeyPrefix, assetScriptKeyPrefix, scriptBasicInfoKeyPrefix,
+		accountScriptComplexityKeyPrefix, assetScriptComplexityKeyPrefix}},
+	{"leases", []byte{leaseKeyPrefix}},
+	{"aliases", []byte{aliasKeyPrefix, addressToAliasesPrefix, disabledAliasKeyPrefix}},
+	{"features", []byte{activatedFeaturesKeyPrefix, approvedFeaturesKeyPrefix, votesFeaturesKeyPrefix}},
+	{chainSection, nil},
+}
+
+// checkpointSectionName returns the name of section the record with the key prefix belongs to.
+func checkpointSectionName(prefix byte) string {
+	for _, s := range checkpointSections {
+		if slices.Contains(s.prefixes, prefix) {
+			return s.name
+		}
+	}
+	return chainSection
+}
+
+// CheckpointFeature is the feature activated at the height of checkpoint.
+type CheckpointFeature struct {
+	ID               int16        `json:"id"`
+	ActivationHeight proto.Height `json:"activationHeight"`
+}
+
+// CheckpointSection describes the file of checkpoint with the records of state section.
+// The file is a sequence of records sorted by keys, each record is the uvarint length of key, the key,
+// the uvarint length of value and the value. Keys and values are the ones of state database,
+// so the section can be imported to the database of any backend.
+type CheckpointSection struct {
+	Name     string `json:"name"`
+	Records  uint64 `json:"records"`
+	Checksum string `json:"checksum"`
+}
+
+// Checkpoint is the manifest of the state exported at the given height.
+// The state at the height is committed by the block ID and the snapshot state hash,
+// the sections of the state and the files of blocks storage are protected by SHA-256 checksums.
+type Checkpoint struct {
+	Version           int                 `json:"version"`
+	Scheme            string              `json:"scheme"`
+	Height            proto.Height        `json:"height"`
+	BlockID           proto.BlockID       `json:"blockId"`
+	SnapshotStateHash crypto.Digest       `json:"snapshotStateHash"`
+	LegacyStateHash   *crypto.Digest      `json:"legacyStateHash,omitempty"`
+	Features          []CheckpointFeature `json:"features"`
+	Sections          []CheckpointSection `json:"sections"`
+	Files             map[string]string   `json:"files"`
+}
+
+// TrustedHeader is the ID and the state hash of the block at the height of checkpoint,
+// taken from the block header provided by a trusted source, e.g. a trusted node.
+type TrustedHeader struct {
+	BlockID   proto.BlockID
+	StateHash crypto.Digest
+}
+
+// matches checks that both checkpoints commit to the same state.
+func (c *Checkpoint) matches(other *Checkpoint) error {
+	switch {
+	case c.Scheme != other.Scheme:
+		return errors.Errorf("scheme mismatch: '%s' != '%s'", c.Scheme, other.Scheme)
+	case c.Height != other.Height:
+		return errors.Errorf("height mismatch: %d != %d", c.Height, other.Height)
+	case c.BlockID != other.BlockID:
+		return errors.Errorf("block ID mismatch: %s != %s", c.BlockID.String(), other.BlockID.String())
+	case c.SnapshotStateHash != other.SnapshotStateHash:
+		return errors.Errorf("snapshot state hash mismatch: %s != %s",
+			c.SnapshotStateHash.String(), other.SnapshotStateHash.String())
+	case c.LegacyStateHash != nil && other.LegacyStateHash != nil && *c.LegacyStateHash != *other.LegacyStateHash:
+		return errors.Errorf("legacy state hash mismatch: %s != %s",
+			c.LegacyStateHash.String(), other.LegacyStateHash.String())
+	case !slices.Equal(c.Features, other.Features):
+		return errors.New("activated features mismatch")
+	default:
+		return nil
+	}
+}
+
+// matchesHeader checks that the checkpoint commits to the block of trusted header.
+func (c *Checkpoint) matchesHeader(trusted TrustedHeader) error {
+	if c.BlockID != trusted.BlockID {
+		return errors.Errorf("block ID '%s' at height %d differs from the trusted one '%s'",
+			c.BlockID.String(), c.Height, trusted.BlockID.String())
+	}
+	if c.SnapshotStateHash != trusted.StateHash {
+		return errors.Errorf("snapshot state hash '%s' at height %d differs from the trusted one '%s'",
+			c.SnapshotStateHash.Hex(), c.Height, trusted.StateHash.Hex())
+	}
+	return nil
+}
+
+// ExportCheckpoint exports the state from dataDir at the given height to checkpointDir and writes the manifest
+// of checkpoint. The state is copied to the temporary directory and rolled back there, after that its records
+// are exported by sections and the files of blocks storage are copied. The latest height is used if height is 0.
+// The height must be within the rollback range of the state. The state at dataDir must not be in use.
+func ExportCheckpoint(
+	dataDir, checkpointDir string,
+	height proto.Height,
+	params StateParams,
+	settings *settings.BlockchainSettings,
+) (_ *Checkpoint, retErr error) {
+	if err := ensureEmptyDir(checkpointDir); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			retErr = stderrs.Join(retErr, removeDirContent(checkpointDir))
+		}
+	}()
+	workDir := filepath.Join(checkpointDir, checkpointWorkDir)
+	zap.S().Infof("Copying state from '%s' to '%s'", dataDir, workDir)
+	if err := copyDir(dataDir, workDir, lockFile); err != nil {
+		return nil, errors.Wrap(err, "failed to copy state")
+	}
+	cp, err := rollbackCheckpoint(workDir, height, params, settings)
+	if err != nil {
+		return nil, err
+	}
+	zap.S().Info("Exporting state sections")
+	sections, err := exportStateSections(KeyValueDir(workDir), filepath.Join(checkpointDir, checkpointStateDir),
+		params.DbParams)
+	if err != nil {
+		return nil, err
+	}
+	blocksDir := filepath.Join(checkpointDir, checkpointBlocksDir)
+	if cpErr := copyDir(filepath.Join(workDir, blocksStorDir), blocksDir, bloomFile, bloomFile+"tmp"); cpErr != nil {
+		return nil, errors.Wrap(cpErr, "failed to copy blocks storage")
+	}
+	files, err := checksums(blocksDir)
+	if err != nil {
+		return nil, err
+	}
+	if rmErr := os.RemoveAll(workDir); rmErr != nil {
+		return nil, errors.Wrap(rmErr, "failed to remove temporary copy of state")
+	}
+	cp.Sections = sections
+	cp.Files = files
+	if err := writeCheckpointManifest(checkpointDir, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// ReadCheckpoint reads the manifest of checkpoint without verification of the checkpoint.
+func ReadCheckpoint(checkpointDir string) (*Checkpoint, error) {
+	b, err := os.ReadFile(filepath.Join(checkpointDir, checkpointManifestFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read checkpoint manifest")
+	}
+	cp := new(Checkpoint)
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, errors.Wrap(err, "failed to parse checkpoint manifest")
+	}
+	if cp.Version != checkpointVersion {
+		return nil, errors.Errorf("unsupported checkpoint version %d", cp.Version)
+	}
+	return cp, nil
+}
+
+// VerifyCheckpoint checks that the checkpoint commits to the block of trusted header and that the sections
+// of the state and the files of blocks storage are not altered.
+func VerifyCheckpoint(checkpointDir string, trusted TrustedHeader) (*Checkpoint, error) {
+	cp, err := ReadCheckpoint(checkpointDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := cp.matchesHeader(trusted); err != nil {
+		return nil, err
+	}
+	if err := verifyStateSections(filepath.Join(checkpointDir, checkpointStateDir), cp.Sections); err != nil {
+		return nil, err
+	}
+	files, err := checksums(filepath.Join(checkpointDir, checkpointBlocksDir))
+	if err != nil {
+		return nil, err
+	}
+	for name, sum := range cp.Files {
+		actual, ok := files[name]
+		if !ok {
+			return nil, errors.Errorf("file '%s' of checkpoint is missing", name)
+		}
+		if actual != sum {
+			return nil, errors.Errorf("checksum mismatch for file '%s' of checkpoint", name)
+		}
+	}
+	for name := range files {
+		if _, ok := cp.Files[name]; !ok {
+			return nil, errors.Errorf("unexpected file '%s' in checkpoint", name)
+		}
+	}
+	return cp, nil
+}
+
+// ImportCheckpoint verifies the checkpoint against the trusted header and imports its state to the empty dataDir.
+// The sections of the state are written to the database of the backend selected by params.
+// The imported state is opened to check that it matches the commitment of the checkpoint,
+// after that the node can be started with dataDir and synchronize the following blocks over the network.
+func ImportCheckpoint(
+	checkpointDir, dataDir string,
+	trusted TrustedHeader,
+	params StateParams,
+	settings *settings.BlockchainSettings,
+) (_ *Checkpoint, retErr error) {
+	cp, err := VerifyCheckpoint(checkpointDir, trusted)
+	if err != nil {
+		return nil, err
+	}
+	if scheme := string(rune(settings.AddressSchemeCharacter)); cp.Scheme != scheme {
+		return nil, errors.Errorf("checkpoint of blockchain '%s' can't be imported to blockchain '%s'",
+			cp.Scheme, scheme)
+	}
+	if err := ensureEmptyDir(dataDir); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			retErr = stderrs.Join(retErr, removeDirContent(dataDir))
+		}
+	}()
+	zap.S().Infof("Importing state sections from '%s' to '%s'", checkpointDir, dataDir)
+	err = importStateSections(filepath.Join(checkpointDir, checkpointStateDir), KeyValueDir(dataDir), cp.Sections,
+		params.DbParams)
+	if err != nil {
+		return nil, err
+	}
+	err = copyDir(filepath.Join(checkpointDir, checkpointBlocksDir), filepath.Join(dataDir, blocksStorDir))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to copy blocks storage")
+	}
+	imported, err := rollbackCheckpoint(dataDir, cp.Height, params, settings)
+	if err != nil {
+		return nil, err
+	}
+	if err := imported.matches(cp); err != nil {
+		return nil, errors.Wrap(err, "imported state doesn't match checkpoint")
+	}
+	return cp, nil
+}
+
+// rollbackCheckpoint opens the state, rolls it back to the height if necessary and returns its commitment.
+func rollbackCheckpoint(
+	dataDir string,
+	height proto.Height,
+	params StateParams,
+	settings *settings.BlockchainSettings,
+) (_ *Checkpoint, retErr error) {
+	s, err := newStateManager(dataDir, false, params, settings, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open state")
+	}
+	defer func() {
+		if err := s.Close(); err != nil {
+			retErr = stderrs.Join(retErr, errors.Wrap(err, "failed to close state"))
+		}
+	}()
+	top, err := s.Height()
+	if err != nil {
+		return nil, err
+	}
+	if height == 0 {
+		height = top
+	}
+	if height > top {
+		return nil, errors.Errorf("height %d is above the state height %d", height, top)
+	}
+	if height < top {
+		zap.S().Infof("Rolling state back from height %d to %d", top, height)
+		if err := s.RollbackToHeight(height); err != nil {
+			return nil, errors.Wrapf(err, "failed to rollback state to height %d", height)
+		}
+	}
+	return stateCheckpoint(s, height, params.BuildStateHashes)
+}
+
+func stateCheckpoint(s *stateManager, height proto.Height, legacyHash bool) (*Checkpoint, error) {
+	blockID, err := s.HeightToBlockID(height)
+	if err != nil {
+		return nil, err
+	}
+	header, err := s.NewestHeaderByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := s.SnapshotStateHashAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if committed, ok := header.GetStateHash(); ok && committed != sh {
+		return nil, errors.Errorf("snapshot state hash %s differs from the one in block header %s",
+			sh.String(), committed.String())
+	}
+	cp := &Checkpoint{
+		Version:           checkpointVersion,
+		Scheme:            string(rune(s.settings.AddressSchemeCharacter)),
+		Height:            height,
+		BlockID:           blockID,
+		SnapshotStateHash: sh,
+	}
+	if legacyHash {
+		lsh, lshErr := s.LegacyStateHashAtHeight(height)
+		if lshErr != nil {
+			return nil, lshErr
+		}
+		cp.LegacyStateHash = &lsh.SumHash
+	}
+	features, err := activatedFeatures(s)
+	if err != nil {
+		return nil, err
+	}
+	cp.Features = features
+	return cp, nil
+}
+
+func activatedFeatures(s *stateManager) ([]CheckpointFeature, error) {
+	voted, err := s.AllFeatures()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int16, 0, len(settings.FeaturesInfo)+len(voted))
+	for f := range settings.FeaturesInfo {
+		ids = append(ids, int16(f))
+	}
+	ids = append(ids, voted...)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	var features []CheckpointFeature
+	for _, id := range ids {
+		activated, aErr := s.IsActivated(id)
+		if aErr != nil {
+			return nil, aErr
+		}
+		if !activated {
+			continue
+		}
+		h, hErr := s.ActivationHeight(id)
+		if hErr != nil {
+			return nil, hErr
+		}
+		features = append(features, CheckpointFeature{ID: id, ActivationHeight: h})
+	}
+	return features, nil
+}
+
+func writeCheckpointManifest(dir string, cp *Checkpoint) error {
+	b, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal checkpoint manifest")
+	}
+	if err := os.WriteFile(filepath.Join(dir, checkpointManifestFile), b, 0600); err != nil {
+		return errors.Wrap(err, "failed to write checkpoint manifest")
+	}
+	return nil
+}
+
+// checkpointDBParams returns the parameters of database used to export or import the sections of state.
+// The bloom filter is disabled, the state rebuilds it on opening.
+func checkpointDBParams(params keyvalue.KeyValParams) keyvalue.KeyValParams {
+	params.BloomFilterParams = keyvalue.BloomFilterParams{Disable: true, Store: keyvalue.NoOpStore{}}
+	return params
+}
+
+// exportStateSections writes the records of database at dbDir to the files of sections in dir.
+// The database is opened with the backend it was created by.
+func exportStateSections(dbDir, dir string, params keyvalue.KeyValParams) (_ []CheckpointSection, retErr error) {
+	backend, ok, err := keyvalue.DetectBackend(dbDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect database backend")
+	}
+	if !ok {
+		return nil, errors.Errorf("no database at '%s'", dbDir)
+	}
+	params.Backend = backend
+	db, err := keyvalue.NewKeyValue(dbDir, checkpointDBParams(params))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database")
+	}
+	defer func() {
+		retErr = stderrs.Join(retErr, db.Close())
+	}()
+	if mkErr := os.MkdirAll(dir, 0750); mkErr != nil {
+		return nil, errors.Wrapf(mkErr, "failed to create directory '%s'", dir)
+	}
+	writers := make(map[string]*sectionWriter, len(checkpointSections))
+	defer func() {
+		for _, w := range writers {
+			retErr = stderrs.Join(retErr, w.close())
+		}
+	}()
+	for _, s := range checkpointSections {
+		w, wErr := newSectionWriter(filepath.Join(dir, s.name))
+		if wErr != nil {
+			return nil, wErr
+		}
+		writers[s.name] = w
+	}
+	// Records are iterated by prefixes, so the keys of every section are written in ascending order.
+	for prefix := range 256 {
+		w := writers[checkpointSectionName(byte(prefix))]
+		if wErr := exportPrefix(db, byte(prefix), w); wErr != nil {
+			return nil, wErr
+		}
+	}
+	sections := make([]CheckpointSection, 0, len(checkpointSections))
+	for _, s := range checkpointSections {
+		w := writers[s.name]
+		if fErr := w.flush(); fErr != nil {
+			return nil, errors.Wrapf(fErr, "failed to write section '%s'", s.name)
+		}
+		sections = append(sections, CheckpointSection{Name: s.name, Records: w.records, Checksum: w.checksum()})
+	}
+	return sections, nil
+}
+
+func exportPrefix(db keyvalue.IterableKeyVal, prefix byte, w *sectionWriter) error {
+	it, err := db.NewKeyIterator([]byte{prefix})
+	if err != nil {
+		return errors.Wrap(err, "failed to create database iterator")
+	}
+	defer it.Release()
+	for it.Next() {
+		if wErr := w.write(it.Key(), it.Value()); wErr != nil {
+			return wErr
+		}
+	}
+	if itErr := it.Error(); itErr != nil {
+		return errors.Wrap(itErr, "failed to iterate database")
+	}
+	return nil
+}
+
+// verifyStateSections checks that all sections are present and not altered.
+func verifyStateSections(dir string, sections []CheckpointSection) error {
+	if len(sections) != len(checkpointSections) {
+		return errors.Errorf("checkpoint has %d state sections instead of %d", len(sections), len(checkpointSections))
+	}
+	for i, s := range sections {
+		if expected := checkpointSections[i].name; s.Name != expected {
+			return errors.Errorf("unexpected state section '%s' instead of '%s'", s.Name, expected)
+		}
+		var prev []byte
+		err := readStateSection(dir, s, func(key, _ []byte) error {
+			if checkpointSectionName(key[0]) != s.Name {
+				return errors.Errorf("record with prefix %d doesn't belong to section", key[0])
+			}
+			if prev != nil && bytes.Compare(prev, key) >= 0 {
+				return errors.New("records are not sorted by keys")
+			}
+			prev = key
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importStateSections writes the records of sections to the new database at dbDir.
+func importStateSections(dir, dbDir string, sections []CheckpointSection, params keyvalue.KeyValParams) (retErr error) {
+	db, err := keyvalue.NewKeyValue(dbDir, checkpointDBParams(params))
+	if err != nil {
+		return errors.Wrap(err, "failed to create database")
+	}
+	defer func() {
+		retErr = stderrs.Join(retErr, db.Close())
+	}()
+	batch, err := db.NewBatch()
+	if err != nil {
+		return errors.Wrap(err, "failed to create database batch")
+	}
+	n := 0
+	for _, s := range sections {
+		err = readStateSection(dir, s, func(key, value []byte) error {
+			batch.Put(key, value)
+			if n++; n%checkpointImportBatchSize != 0 {
+				return nil
+			}
+			if fErr := db.Flush(batch); fErr != nil {
+				return errors.Wrap(fErr, "failed to write to database")
+			}
+			batch.Reset()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if fErr := db.Flush(batch); fErr != nil {
+		return errors.Wrap(fErr, "failed to write to database")
+	}
+	return nil
+}
+
+// readStateSection reads the records of section and checks the number of records and the checksum of section.
+func readStateSection(dir string, s CheckpointSection, fn func(key, value []byte) error) (retErr error) {
+	f, err := os.Open(filepath.Clean(filepath.Join(dir, s.Name)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to open state section '%s'", s.Name)
+	}
+	defer func() {
+		retErr = stderrs.Join(retErr, f.Close())
+	}()
+	h := sha256.New()
+	r := bufio.NewReader(io.TeeReader(f, h))
+	var records uint64
+	for {
+		key, rErr := readSectionField(r)
+		if errors.Is(rErr, io.EOF) {
+			break
+		}
+		if rErr != nil || len(key) == 0 {
+			return errors.Errorf("malformed key of record %d in state section '%s'", records, s.Name)
+		}
+		value, rErr := readSectionField(r)
+		if rErr != nil {
+			return errors.Errorf("malformed value of record %d in state section '%s'", records, s.Name)
+		}
+		if fnErr := fn(key, value); fnErr != nil {
+			return errors.Wrapf(fnErr, "record %d of state section '%s'", records, s.Name)
+		}
+		records++
+	}
+	if records != s.Records {
+		return errors.Errorf("state section '%s' has %d records instead of %d", s.Name, records, s.Records)
+	}
+	if hex.EncodeToString(h.Sum(nil)) != s.Checksum {
+		return errors.Errorf("checksum mismatch for state section '%s'", s.Name)
+	}
+	return nil
+}
+
+// readSectionField reads the length prefixed field of record, io.EOF is returned only at the end of section.
+func readSectionField(r *bufio.Reader) ([]byte, error) {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if l > maxCheckpointRecordLength {
+		return nil, errors.Errorf("too long field of %d bytes", l)
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, io.ErrUnexpectedEOF // The end of section is expected only before the record.
+	}
+	return b, nil
+}
+
+// sectionWriter writes the records of state section to the file and calculates its checksum.
+type sectionWriter struct {
+	f       *os.File
+	w       *bufio.Writer
+	h       hash.Hash
+	records uint64
+	buf     []byte
+}
+
+func newSectionWriter(path string) (*sectionWriter, error) {
+	f, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create state section")
+	}
+	h := sha256.New()
+	return &sectionWriter{f: f, w: bufio.NewWriter(io.MultiWriter(f, h)), h: h}, nil
+}
+
+func (w *sectionWriter) write(key, value []byte) error {
+	w.buf = binary.AppendUvarint(w.buf[:0], uint64(len(key)))
+	w.buf = append(w.buf, key...)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(value)))
+	w.buf = append(w.buf, value...)
+	if _, err := w.w.Write(w.buf); err != nil {
+		return errors.Wrap(err, "failed to write state section")
+	}
+	w.records++
+	return nil
+}
+
+func (w *sectionWriter) flush() error {
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	return w.f.Sync()
+}
+
+func (w *sectionWriter) checksum() string {
+	return hex.EncodeToString(w.h.Sum(nil))
+}
+
+func (w *sectionWriter) close() error {
+	return w.f.Close()
+}
+
+// ensureEmptyDir creates the directory if it doesn't exist and checks that it's empty otherwise.
+func ensureEmptyDir(dir string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return errors.Wrapf(err, "failed to create directory '%s'", dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read directory '%s'", dir)
+	}
+	if len(entries) != 0 {
+		return errors.Errorf("directory '%s' is not empty", dir)
+	}
+	return nil
+}
+
+func removeDirContent(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyDir copies files from src to dst recursively, the files with skipped names are not copied.
+func copyDir(src, dst string, skip ...string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0750)
+		}
+		if slices.Contains(skip, d.Name()) {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) (retErr error) {
+	in, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = stderrs.Join(retErr, in.Close())
+	}()
+	out, err := os.OpenFile(filepath.Clean(dst), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = stderrs.Join(retErr, out.Close())
+	}()
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// checksums returns SHA-256 checksums of the files by their paths relative to the directory.
+func checksums(dir string) (map[string]string, error) {
+	res := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sum, err := fileChecksum(path)
+		if err != nil {
+			return err
+		}
+		res[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to calculate checksums of files")
+	}
+	return res, nil
+}
+
+func fileChecksum(path string) (_ string, retErr error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		retErr = stderrs.Join(retErr, f.Close())
+	}()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
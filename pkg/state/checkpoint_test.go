@@ -0,0 +1,176 @@
+package state
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/importer"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+)
+
+func TestCheckpointExportImport(t *testing.T) {
+	const (
+		blocksNum        = 300
+		checkpointHeight = 200
+	)
+	bs := settings.MustMainNetSettings()
+	params := DefaultTestingStateParams()
+	params.BuildStateHashes = true
+
+	dataDir := t.TempDir()
+	st, err := NewState(dataDir, false, params, bs, false)
+	require.NoError(t, err)
+	blocksPath, err := blocksPath()
+	require.NoError(t, err)
+	err = importer.ApplyFromFile(
+		context.Background(),
+		importer.ImportParams{Schema: bs.AddressSchemeCharacter, BlockchainPath: blocksPath, LightNodeMode: false},
+		st, blocksNum, 1)
+	require.NoError(t, err)
+	expectedID, err := st.HeightToBlockID(checkpointHeight)
+	require.NoError(t, err)
+	expectedHash, err := st.LegacyStateHashAtHeight(checkpointHeight)
+	require.NoError(t, err)
+	expectedSnapshotHash, err := st.SnapshotStateHashAtHeight(checkpointHeight)
+	require.NoError(t, err)
+	addr := proto.MustAddressFromString("3PAWwWa6GbwcJaFzwqXQN5KQm7H96Y7SHTQ")
+	expectedBalance, err := st.WavesBalance(proto.NewRecipientFromAddress(addr))
+	require.NoError(t, err)
+	require.NoError(t, st.Close())
+	trusted := TrustedHeader{BlockID: expectedID, StateHash: expectedSnapshotHash}
+
+	checkpointDir := t.TempDir()
+	cp, err := ExportCheckpoint(dataDir, checkpointDir, checkpointHeight, params, bs)
+	require.NoError(t, err)
+	assert.Equal(t, proto.Height(checkpointHeight), cp.Height)
+	assert.Equal(t, expectedID, cp.BlockID)
+	assert.Equal(t, expectedSnapshotHash, cp.SnapshotStateHash)
+	require.NotNil(t, cp.LegacyStateHash)
+	assert.Equal(t, expectedHash.SumHash, *cp.LegacyStateHash)
+	require.Len(t, cp.Sections, len(checkpointSections))
+	for _, s := range cp.Sections {
+		if s.Name == "balances" || s.Name == chainSection { // Only these records are produced by first blocks.
+			assert.NotZero(t, s.Records, "section %s", s.Name)
+		}
+	}
+	assert.NotEmpty(t, cp.Files)
+	assert.NoDirExists(t, filepath.Join(checkpointDir, checkpointWorkDir))
+
+	verified, err := VerifyCheckpoint(checkpointDir, trusted)
+	require.NoError(t, err)
+	assert.Equal(t, cp, verified)
+	_, err = VerifyCheckpoint(checkpointDir, TrustedHeader{BlockID: expectedID, StateHash: crypto.Digest{1}})
+	assert.ErrorContains(t, err, "differs from the trusted one")
+
+	// The source state stays untouched.
+	st, err = NewState(dataDir, false, params, bs, false)
+	require.NoError(t, err)
+	height, err := st.Height()
+	require.NoError(t, err)
+	assert.Equal(t, proto.Height(blocksNum+1), height)
+	require.NoError(t, st.Close())
+
+	// The checkpoint is imported to the database of another backend.
+	importParams := params
+	importParams.DbParams.Backend = keyvalue.PebbleBackend
+	importDir := t.TempDir()
+	imported, err := ImportCheckpoint(checkpointDir, importDir, trusted, importParams, bs)
+	require.NoError(t, err)
+	assert.Equal(t, cp, imported)
+
+	st, err = NewState(importDir, false, importParams, bs, false)
+	require.NoError(t, err)
+	height, err = st.Height()
+	require.NoError(t, err)
+	assert.Equal(t, proto.Height(checkpointHeight), height)
+	id, err := st.HeightToBlockID(checkpointHeight)
+	require.NoError(t, err)
+	assert.Equal(t, expectedID, id)
+	balance, err := st.WavesBalance(proto.NewRecipientFromAddress(addr))
+	require.NoError(t, err)
+	assert.Equal(t, expectedBalance, balance)
+	require.NoError(t, st.Close())
+
+	_, err = ImportCheckpoint(checkpointDir, importDir, trusted, importParams, bs)
+	assert.ErrorContains(t, err, "is not empty")
+}
+
+func TestVerifyCheckpointDetectsAlteredFiles(t *testing.T) {
+	dbDir := t.TempDir()
+	db, err := keyvalue.NewKeyValue(dbDir, keyvalue.KeyValParams{
+		CacheParams:       keyvalue.CacheParams{Size: 1024},
+		BloomFilterParams: keyvalue.BloomFilterParams{Disable: true, Store: keyvalue.NoOpStore{}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Put([]byte{wavesBalanceKeyPrefix, 1}, []byte{2}))
+	require.NoError(t, db.Put([]byte{aliasKeyPrefix, 1}, []byte{3}))
+	require.NoError(t, db.Put([]byte{scoreKeyPrefix, 1}, []byte{4}))
+	require.NoError(t, db.Close())
+
+	dir := t.TempDir()
+	sections, err := exportStateSections(dbDir, filepath.Join(dir, checkpointStateDir), keyvalue.KeyValParams{})
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, checkpointBlocksDir), 0750))
+	file := filepath.Join(dir, checkpointBlocksDir, "headers")
+	require.NoError(t, os.WriteFile(file, []byte("data"), 0600))
+	files, err := checksums(filepath.Join(dir, checkpointBlocksDir))
+	require.NoError(t, err)
+	trusted := TrustedHeader{BlockID: proto.NewBlockIDFromDigest(crypto.Digest{1}), StateHash: crypto.Digest{2}}
+	cp := &Checkpoint{
+		Version:           checkpointVersion,
+		Scheme:            "W",
+		Height:            1,
+		BlockID:           trusted.BlockID,
+		SnapshotStateHash: trusted.StateHash,
+		Sections:          sections,
+		Files:             files,
+	}
+	require.NoError(t, writeCheckpointManifest(dir, cp))
+
+	_, err = VerifyCheckpoint(dir, trusted)
+	require.NoError(t, err)
+	for _, s := range cp.Sections {
+		switch s.Name {
+		case "balances", "aliases", chainSection:
+			assert.Equal(t, uint64(1), s.Records, "section %s", s.Name)
+		default:
+			assert.Zero(t, s.Records, "section %s", s.Name)
+		}
+	}
+
+	_, err = VerifyCheckpoint(dir, TrustedHeader{BlockID: proto.NewBlockIDFromDigest(crypto.Digest{3})})
+	assert.ErrorContains(t, err, "block ID")
+
+	section := filepath.Join(dir, checkpointStateDir, "balances")
+	data, err := os.ReadFile(section)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(section, append(slices.Clone(data[:len(data)-1]), 5), 0600))
+	_, err = VerifyCheckpoint(dir, trusted)
+	assert.ErrorContains(t, err, "checksum mismatch for state section 'balances'")
+	require.NoError(t, os.WriteFile(section, data[:len(data)-1], 0600))
+	_, err = VerifyCheckpoint(dir, trusted)
+	assert.ErrorContains(t, err, "malformed value of record 0 in state section 'balances'")
+	require.NoError(t, os.WriteFile(section, data, 0600))
+
+	require.NoError(t, os.WriteFile(file, []byte("altered"), 0600))
+	_, err = VerifyCheckpoint(dir, trusted)
+	assert.ErrorContains(t, err, "checksum mismatch for file 'headers'")
+
+	require.NoError(t, os.Remove(file))
+	_, err = VerifyCheckpoint(dir, trusted)
+	assert.ErrorContains(t, err, "is missing")
+
+	require.NoError(t, os.WriteFile(file, []byte("data"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, checkpointBlocksDir, "extra"), []byte("data"), 0600))
+	_, err = VerifyCheckpoint(dir, trusted)
+	assert.ErrorContains(t, err, "unexpected file 'extra'")
+}
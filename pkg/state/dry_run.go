@@ -0,0 +1,78 @@
+package state
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/errs"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// MaxDryRunPendingTransactions is the maximal number of pending transactions applied before the validated one.
+// The validation holds the state lock, so the rest of pending transactions is not taken into account.
+const MaxDryRunPendingTransactions = 100
+
+// DryRunResult describes the validation of transaction which doesn't change the state.
+type DryRunResult struct {
+	Error        error                  // Validation error, nil if the transaction is valid
+	Snapshots    []proto.AtomicSnapshot // Snapshots of the transaction, empty if the transaction is invalid
+	Complexity   int                    // Complexity of the scripts evaluated during the validation
+	Actions      []proto.ScriptAction   // Actions of the invoked script, empty for other transactions
+	ScriptResult *proto.ScriptResult    // Result of the invoked script, nil for other transactions
+}
+
+// DryRun validates the transaction against the newest state adjusted by the pending transactions,
+// usually the transactions of UTX pool. Only the first MaxDryRunPendingTransactions pending transactions are applied.
+// Invalid pending transactions and the pending transaction with the same ID as the validated one are skipped.
+// Nothing is changed in the state.
+// The error is returned if the validation can't be performed, the validation error is returned in the result.
+func DryRun(
+	s State, pending []proto.Transaction, tx proto.Transaction, currentTimestamp uint64, checkSignatures bool,
+) (*DryRunResult, error) {
+	bs, err := s.BlockchainSettings()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get blockchain settings")
+	}
+	// Transaction data is validated in advance because the ID of malformed transaction can't be calculated.
+	if _, vErr := tx.Validate(proto.TransactionValidationParams{Scheme: bs.AddressSchemeCharacter}); vErr != nil {
+		return &DryRunResult{Error: errs.Extend(vErr, "invalid tx data")}, nil
+	}
+	id, err := tx.GetID(bs.AddressSchemeCharacter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction ID")
+	}
+	if len(pending) > MaxDryRunPendingTransactions {
+		pending = pending[:MaxDryRunPendingTransactions]
+	}
+	top := s.TopBlock()
+	var r *DryRunResult
+	err = s.TxValidation(func(v TxValidation) error {
+		for _, p := range pending {
+			if pid, idErr := p.GetID(bs.AddressSchemeCharacter); idErr == nil && bytes.Equal(pid, id) {
+				continue
+			}
+			_, _ = v.ValidateNextTx(p, currentTimestamp, top.Timestamp, top.Version, false)
+		}
+		var vErr error
+		r, vErr = v.DryRunNextTx(tx, currentTimestamp, top.Timestamp, top.Version, checkSignatures)
+		r.Error = vErr
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DryRunNextTx validates transaction like ValidateNextTx does, but returns the details of the validation.
+// It must be used for validation of transactions by API only.
+func (s *stateManager) DryRunNextTx(
+	tx proto.Transaction,
+	currentTimestamp,
+	parentTimestamp uint64,
+	v proto.BlockVersion,
+	checkSignatures bool,
+) (*DryRunResult, error) {
+	return s.appender.dryRunNextTx(tx, currentTimestamp, parentTimestamp, v, checkSignatures)
+}
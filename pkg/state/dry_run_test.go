@@ -0,0 +1,79 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/errs"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+)
+
+func TestDryRun(t *testing.T) {
+	bs := settings.MustMainNetSettings()
+	bs.PreactivatedFeatures = []int16{int16(settings.SmartAccounts)}
+	manager := newTestStateManager(t, true, DefaultTestingStateParams(), bs)
+	st := NewThreadSafeState(manager)
+	ts := manager.TopBlock().Timestamp + 1000
+	transfer := func(amount uint64) *proto.TransferWithProofs {
+		waves := proto.NewOptionalAssetWaves()
+		rcp := proto.NewRecipientFromAddress(testGlobal.recipientInfo.addr)
+		tx := proto.NewUnsignedTransferWithProofs(2, testGlobal.senderInfo.pk, waves, waves, ts, amount, FeeUnit,
+			rcp, nil)
+		tx.Proofs = proto.NewProofs()
+		return tx
+	}
+	signed := func(tx *proto.TransferWithProofs) *proto.TransferWithProofs {
+		require.NoError(t, tx.Sign(proto.MainNetScheme, testGlobal.senderInfo.sk))
+		return tx
+	}
+	err := manager.stateDB.addBlock(blockID0)
+	require.NoError(t, err)
+	waves := newWavesValueFromProfile(balanceProfile{balance: 2*FeeUnit + 100})
+	err = manager.stor.balances.setWavesBalance(testGlobal.senderInfo.addr.ID(), waves, blockID0)
+	require.NoError(t, err)
+	require.NoError(t, manager.flush())
+
+	r, err := DryRun(st, nil, signed(transfer(100)), ts, true)
+	require.NoError(t, err)
+	require.NoError(t, r.Error)
+	assert.NotEmpty(t, r.Snapshots)
+	assert.Zero(t, r.Complexity)
+	assert.Nil(t, r.ScriptResult)
+
+	// Unsigned transaction is valid only if signatures are not checked.
+	r, err = DryRun(st, nil, transfer(100), ts, true)
+	require.NoError(t, err)
+	assert.Error(t, r.Error)
+	assert.Empty(t, r.Snapshots)
+	r, err = DryRun(st, nil, transfer(100), ts, false)
+	require.NoError(t, err)
+	assert.NoError(t, r.Error)
+
+	// Pending transaction spends the balance, but the pending copy of the validated transaction is skipped.
+	pending := signed(transfer(FeeUnit))
+	r, err = DryRun(st, []proto.Transaction{pending}, signed(transfer(100)), ts, true)
+	require.NoError(t, err)
+	assert.Error(t, r.Error)
+	assert.Equal(t, "AccountBalanceError", errs.TypeName(r.Error))
+	r, err = DryRun(st, []proto.Transaction{pending}, pending, ts, true)
+	require.NoError(t, err)
+	assert.NoError(t, r.Error)
+
+	// Pending transactions over the limit are not applied.
+	pendingOverLimit := make([]proto.Transaction, 0, MaxDryRunPendingTransactions+1)
+	for range MaxDryRunPendingTransactions {
+		pendingOverLimit = append(pendingOverLimit, signed(transfer(10*FeeUnit))) // Invalid, not enough balance.
+	}
+	pendingOverLimit = append(pendingOverLimit, pending)
+	r, err = DryRun(st, pendingOverLimit, signed(transfer(100)), ts, true)
+	require.NoError(t, err)
+	assert.NoError(t, r.Error)
+
+	// State is not changed.
+	balance, err := manager.NewestWavesBalance(proto.NewRecipientFromAddress(testGlobal.senderInfo.addr))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2*FeeUnit+100), balance)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/proto/ethabi"
+	"github.com/wavesplatform/gowaves/pkg/ride"
 	"github.com/wavesplatform/gowaves/pkg/ride/ast"
 	"github.com/wavesplatform/gowaves/pkg/ride/serialization"
 	"github.com/wavesplatform/gowaves/pkg/settings"
@@ -314,7 +315,7 @@ func TestEthereumInvoke(t *testing.T) {
 	fallibleInfo := &fallibleValidationParams{appendTxParams: appendTxParams, senderScripted: false, senderAddress: sender}
 	scriptAddress, tree := applyScript(t, &tx, storage)
 	fallibleInfo.rideV5Activated = true
-	res, err := txAppend.ia.sc.invokeFunction(tree, nil, &tx, fallibleInfo, scriptAddress)
+	res, err := txAppend.ia.sc.invokeFunction(ride.NewProgram(tree), nil, &tx, fallibleInfo, scriptAddress)
 	assert.NoError(t, err)
 	assert.True(t, res.Result())
 
@@ -445,7 +446,7 @@ func TestEthereumInvokeWithoutPaymentsAndArguments(t *testing.T) {
 	fallibleInfo := &fallibleValidationParams{appendTxParams: appendTxParams, senderScripted: false, senderAddress: sender}
 	scriptAddress, tree := applyScript(t, &tx, storage)
 	fallibleInfo.rideV5Activated = true
-	res, err := txAppend.ia.sc.invokeFunction(tree, nil, &tx, fallibleInfo, scriptAddress)
+	res, err := txAppend.ia.sc.invokeFunction(ride.NewProgram(tree), nil, &tx, fallibleInfo, scriptAddress)
 	assert.NoError(t, err)
 	assert.True(t, res.Result())
 
@@ -522,7 +523,7 @@ func TestEthereumInvokeAllArguments(t *testing.T) {
 	fallibleInfo := &fallibleValidationParams{appendTxParams: appendTxParams, senderScripted: false, senderAddress: sender}
 	scriptAddress, tree := applyScript(t, &tx, storage)
 	fallibleInfo.rideV5Activated = true
-	res, err := txAppend.ia.sc.invokeFunction(tree, nil, &tx, fallibleInfo, scriptAddress)
+	res, err := txAppend.ia.sc.invokeFunction(ride.NewProgram(tree), nil, &tx, fallibleInfo, scriptAddress)
 	assert.NoError(t, err)
 	assert.True(t, res.Result())
 
@@ -0,0 +1,169 @@
+package state
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/types"
+)
+
+// evaluationInvokeFee is the fee of the invoke transaction the evaluation is performed on behalf of.
+const evaluationInvokeFee = proto.MinFeeInvokeScript
+
+// EvaluationCaller is the account on behalf of which the function is evaluated.
+type EvaluationCaller struct {
+	Address   proto.WavesAddress
+	PublicKey []byte // Empty if the public key of the account is unknown, e.g. for Ethereum accounts.
+}
+
+// NewEvaluationEnvironment creates the RIDE environment for read-only evaluation of scripts against the newest state.
+// The evaluation is performed as if the DApp at the given address was invoked with the call and payments by
+// the caller, or by the DApp itself if the caller is nil. The balance of the caller is not checked by the payments.
+// The tree is the script of the DApp, it can be nil for the address without script. Changes produced by
+// the evaluation are kept in the wrapped state and never applied to the state. It must be used by API only.
+func NewEvaluationEnvironment(
+	s State,
+	addr proto.WavesAddress,
+	tree *ast.Tree,
+	call proto.FunctionCall,
+	payments proto.ScriptPayments,
+	caller *EvaluationCaller,
+	complexityLimit uint32,
+) (*ride.EvaluationEnvironment, error) {
+	st, ok := s.(types.EnrichedSmartState)
+	if !ok {
+		return nil, errors.New("state doesn't support scripts evaluation")
+	}
+	bs, err := s.BlockchainSettings()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get blockchain settings")
+	}
+	features := []settings.Feature{
+		settings.BlockV5, settings.RideV5, settings.RideV6, settings.ConsensusImprovements,
+		settings.BlockRewardDistribution, settings.LightNode,
+	}
+	active := make(map[settings.Feature]bool, len(features))
+	for _, f := range features {
+		if active[f], err = s.IsActivated(int16(f)); err != nil {
+			return nil, errors.Wrapf(err, "failed to check activation of feature %d", f)
+		}
+	}
+	env, err := ride.NewEnvironment(
+		bs.AddressSchemeCharacter,
+		st,
+		bs.InternalInvokePaymentsValidationAfterHeight,
+		bs.PaymentsFixAfterHeight,
+		active[settings.BlockV5],
+		active[settings.RideV6],
+		active[settings.ConsensusImprovements],
+		active[settings.BlockRewardDistribution],
+		active[settings.LightNode],
+	)
+	if err != nil {
+		return nil, err
+	}
+	height, err := s.Height()
+	if err != nil {
+		return nil, err
+	}
+	info, err := st.NewestBlockInfoByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	version := ast.CurrentMaxLibraryVersion()
+	if tree != nil {
+		version = tree.LibVersion
+	}
+	now := uint64(time.Now().UnixMilli())
+	env.SetThisFromAddress(addr)
+	env.ChooseSizeCheck(version)
+	if err := env.SetLastBlockFromBlockInfo(info); err != nil {
+		return nil, err
+	}
+	env.SetTimestamp(now)
+	env.ChooseTakeString(active[settings.RideV5])
+	env.ChooseMaxDataEntriesSize(active[settings.RideV5])
+	env.SetLimit(complexityLimit)
+
+	pk, err := st.NewestScriptPKByAddr(addr)
+	if err != nil && !IsNotFound(err) {
+		return nil, err
+	}
+	tx := proto.NewUnsignedInvokeScriptWithProofs(2, pk, proto.NewRecipientFromAddress(addr), call, payments,
+		proto.NewOptionalAssetWaves(), evaluationInvokeFee, now)
+	tx.ID = &crypto.Digest{}
+	if err := env.SetTransaction(tx); err != nil {
+		return nil, err
+	}
+	if err := env.SetInvoke(tx, version); err != nil {
+		return nil, err
+	}
+	sender := addr
+	if caller != nil {
+		if err := env.SetInvokeCaller(caller.Address, caller.PublicKey); err != nil {
+			return nil, err
+		}
+		sender = caller.Address
+	}
+	if version < ast.LibV5 { // No wrapped state before version 5
+		return env, nil
+	}
+	return ride.NewEnvironmentWithWrappedState(env, st, payments, sender, true, version, false)
+}
+
+// EvaluatedInvokeFee returns the minimal fee in Waves of the invocation by the sender without verifier script,
+// which spends the given complexity and produces the given actions. The fee of invoke transaction is charged
+// for each started step of the callable complexity limit of the script version, the fee for issued assets is added.
+// Smart assets scripts are not charged since RideV5 activation, which is required for Ethereum transactions.
+func EvaluatedInvokeFee(
+	s State, version ast.LibraryVersion, complexity int, actions []proto.ScriptAction,
+) (uint64, error) {
+	step, err := maxCallableComplexity(version)
+	if err != nil {
+		return 0, err
+	}
+	steps := uint64(1)
+	if complexity > step {
+		steps = uint64((complexity + step - 1) / step)
+	}
+	fee := feeConstants[proto.InvokeScriptTransaction] * FeeUnit * steps
+	for _, action := range actions {
+		a, ok := action.(*proto.IssueScriptAction)
+		if !ok {
+			continue
+		}
+		if a.Quantity == 1 && a.Decimals == 0 && !a.Reissuable {
+			nftActivated, err := s.IsActivated(int16(settings.ReducedNFTFee))
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to check activation of reduced NFT fee")
+			}
+			if nftActivated {
+				continue
+			}
+		}
+		fee += feeConstants[proto.IssueTransaction] * FeeUnit
+	}
+	return fee, nil
+}
+
+// maxCallableComplexity returns the complexity limit of callable function of the script version.
+func maxCallableComplexity(version ast.LibraryVersion) (int, error) {
+	switch version {
+	case ast.LibV1, ast.LibV2:
+		return MaxCallableScriptComplexityV12, nil
+	case ast.LibV3, ast.LibV4:
+		return MaxCallableScriptComplexityV34, nil
+	case ast.LibV5:
+		return MaxCallableScriptComplexityV5, nil
+	case ast.LibV6, ast.LibV7, ast.LibV8:
+		return MaxCallableScriptComplexityV6, nil
+	default:
+		return 0, errors.Errorf("unknown script LibVersion=%d", version)
+	}
+}
@@ -0,0 +1,36 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+)
+
+func TestEvaluatedInvokeFee(t *testing.T) {
+	invokeFee := feeConstants[proto.InvokeScriptTransaction] * FeeUnit
+	issueFee := feeConstants[proto.IssueTransaction] * FeeUnit
+	issue := &proto.IssueScriptAction{Quantity: 1000, Decimals: 2, Reissuable: true}
+	for _, test := range []struct {
+		version    ast.LibraryVersion
+		complexity int
+		actions    []proto.ScriptAction
+		fee        uint64
+	}{
+		{ast.LibV5, 0, nil, invokeFee},
+		{ast.LibV5, MaxCallableScriptComplexityV5, nil, invokeFee},
+		{ast.LibV5, MaxCallableScriptComplexityV5 + 1, nil, 2 * invokeFee},
+		{ast.LibV4, 3*MaxCallableScriptComplexityV34 - 1, nil, 3 * invokeFee},
+		{ast.LibV6, 2 * MaxCallableScriptComplexityV6, nil, 2 * invokeFee},
+		{ast.LibV6, 100, []proto.ScriptAction{issue, &proto.BurnScriptAction{}}, invokeFee + issueFee},
+	} {
+		fee, err := EvaluatedInvokeFee(nil, test.version, test.complexity, test.actions)
+		require.NoError(t, err)
+		assert.Equal(t, test.fee, fee, "version %d, complexity %d", test.version, test.complexity)
+	}
+	_, err := EvaluatedInvokeFee(nil, ast.LibraryVersion(100), 1, nil)
+	assert.Error(t, err)
+}
@@ -13,7 +13,7 @@ import (
 )
 
 const (
-	scriptExtraFee = 400000
+	ScriptExtraFee = 400000
 	FeeUnit        = 100000
 
 	SetScriptTransactionV6Fee = 1
@@ -218,8 +218,8 @@ type txCosts struct {
 }
 
 func newTxCosts(smartAssets, smartAccounts uint64, isSmartAssetsFree, isSmartAccountFree bool) *txCosts {
-	smartAssetsFee := smartAssets * scriptExtraFee
-	smartAccountsFee := smartAccounts * scriptExtraFee
+	smartAssetsFee := smartAssets * ScriptExtraFee
+	smartAccountsFee := smartAccounts * ScriptExtraFee
 	if isSmartAssetsFree {
 		smartAssetsFee = 0
 	}
@@ -360,3 +360,85 @@ func checkMinFeeAsset(tx proto.Transaction, feeAssetID crypto.Digest, params *fe
 	}
 	return nil
 }
+
+// feeAssets returns the assets of the transaction which scripts are taken into account in the minimal fee.
+func feeAssets(tx proto.Transaction) []proto.OptionalAsset {
+	switch t := tx.(type) {
+	case *proto.TransferWithSig:
+		return []proto.OptionalAsset{t.AmountAsset}
+	case *proto.TransferWithProofs:
+		return []proto.OptionalAsset{t.AmountAsset}
+	case *proto.ReissueWithSig:
+		return []proto.OptionalAsset{*proto.NewOptionalAssetFromDigest(t.AssetID)}
+	case *proto.ReissueWithProofs:
+		return []proto.OptionalAsset{*proto.NewOptionalAssetFromDigest(t.AssetID)}
+	case *proto.BurnWithSig:
+		return []proto.OptionalAsset{*proto.NewOptionalAssetFromDigest(t.AssetID)}
+	case *proto.BurnWithProofs:
+		return []proto.OptionalAsset{*proto.NewOptionalAssetFromDigest(t.AssetID)}
+	case *proto.MassTransferWithProofs:
+		return []proto.OptionalAsset{t.Asset}
+	case *proto.UpdateAssetInfoWithProofs:
+		return []proto.OptionalAsset{*proto.NewOptionalAssetFromDigest(t.AssetID)}
+	case *proto.InvokeScriptWithProofs:
+		assets := make([]proto.OptionalAsset, len(t.Payments))
+		for i := range t.Payments {
+			assets[i] = t.Payments[i].Asset
+		}
+		return assets
+	case proto.Exchange:
+		pair := t.GetOrder1().GetAssetPair()
+		if pair.AmountAsset == pair.PriceAsset {
+			return []proto.OptionalAsset{pair.AmountAsset}
+		}
+		return []proto.OptionalAsset{pair.AmountAsset, pair.PriceAsset}
+	default:
+		return nil
+	}
+}
+
+// feeSmartAssets returns the scripted assets of the transaction that require an extra fee.
+func feeSmartAssets(stor *blockchainEntitiesStorage, tx proto.Transaction) ([]crypto.Digest, error) {
+	if stx, ok := tx.(*proto.SetAssetScriptWithProofs); ok {
+		// The asset is scripted by definition.
+		return []crypto.Digest{stx.AssetID}, nil
+	}
+	var smartAssets []crypto.Digest
+	for _, asset := range feeAssets(tx) {
+		if !asset.Present {
+			// Waves can not be scripted.
+			continue
+		}
+		hasScript, err := stor.scriptsStorage.newestIsSmartAsset(proto.AssetIDFromDigest(asset.ID))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check newestIsSmartAsset for asset %q", asset.String())
+		}
+		if hasScript {
+			smartAssets = append(smartAssets, asset.ID)
+		}
+	}
+	return smartAssets, nil
+}
+
+// minFee returns the minimal fee of the transaction in the fee asset of the parameters.
+func minFee(tx proto.Transaction, params *feeValidationParams) (uint64, error) {
+	minWaves, err := minFeeInWaves(tx, params)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to calculate min fee in Waves")
+	}
+	if !params.txAssets.feeAsset.Present {
+		return minWaves.total, nil
+	}
+	feeAssetID := params.txAssets.feeAsset.ID
+	shortFeeAssetID := proto.AssetIDFromDigest(feeAssetID)
+	isSponsored, err := params.stor.sponsoredAssets.newestIsSponsored(shortFeeAssetID)
+	if err != nil {
+		return 0, errors.Wrap(err, "newestIsSponsored")
+	}
+	if !isSponsored {
+		return 0, errs.NewTxValidationError(fmt.Sprintf("Asset %s is not sponsored, cannot be used to pay fees",
+			feeAssetID.String(),
+		))
+	}
+	return params.stor.sponsoredAssets.wavesToSponsoredAsset(shortFeeAssetID, minWaves.total)
+}
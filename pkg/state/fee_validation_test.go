@@ -27,7 +27,7 @@ func TestAssetScriptExtraFee(t *testing.T) {
 	to.stor.createSmartAsset(t, tx.AssetID)
 
 	// This fee would be valid for simple Smart Account (without Smart asset).
-	tx.Fee = 1*FeeUnit + scriptExtraFee
+	tx.Fee = 1*FeeUnit + ScriptExtraFee
 	params := &feeValidationParams{
 		stor:            to.stor.entities,
 		settings:        settings.MustMainNetSettings(),
@@ -37,7 +37,7 @@ func TestAssetScriptExtraFee(t *testing.T) {
 	err = checkMinFeeWaves(tx, params) // it doesn't matter for these tests what version estimator is
 	assert.Error(t, err, "checkMinFeeWaves() did not fail with invalid Burn fee")
 	// One more extra fee for asset script must be added.
-	tx.Fee += scriptExtraFee
+	tx.Fee += ScriptExtraFee
 	err = checkMinFeeWaves(tx, params)
 	assert.NoError(t, err, "checkMinFeeWaves() failed with valid Burn fee")
 }
@@ -145,7 +145,7 @@ func TestAccountScriptExtraFee(t *testing.T) {
 	}
 	err = checkMinFeeWaves(tx, params)
 	assert.Error(t, err, "checkMinFeeWaves() did not fail with invalid Burn fee")
-	tx.Fee += scriptExtraFee
+	tx.Fee += ScriptExtraFee
 	err = checkMinFeeWaves(tx, params)
 	assert.NoError(t, err, "checkMinFeeWaves() failed with valid Burn fee")
 }
@@ -341,3 +341,39 @@ func TestSetScriptTransactionDynamicFee(t *testing.T) {
 	err = checkMinFeeWaves(tx, params)
 	assert.NoError(t, err, "checkMinFeeWaves() failed with valid SetScriptTx fee")
 }
+
+func TestMinFee(t *testing.T) {
+	to := createSponsoredAssets(t, true)
+
+	tx := createBurnWithSig(t)
+	to.stor.createSmartAsset(t, tx.AssetID)
+	smartAssets, err := feeSmartAssets(to.stor.entities, tx)
+	require.NoError(t, err)
+	assert.Equal(t, []crypto.Digest{tx.AssetID}, smartAssets)
+	params := &feeValidationParams{
+		stor:            to.stor.entities,
+		settings:        settings.MustMainNetSettings(),
+		txAssets:        &txAssets{feeAsset: proto.NewOptionalAssetWaves(), smartAssets: smartAssets},
+		rideV5Activated: false,
+	}
+	fee, err := minFee(tx, params)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1*FeeUnit+ScriptExtraFee), fee)
+	tx.Fee = fee
+	assert.NoError(t, checkMinFeeWaves(tx, params))
+
+	transfer := createTransferWithSig(t)
+	params.txAssets = &txAssets{feeAsset: transfer.FeeAsset}
+	_, err = minFee(transfer, params)
+	assert.Error(t, err, "minFee() did not fail with not sponsored fee asset")
+
+	to.stor.addBlock(t, blockID0)
+	assetCost := uint64(4)
+	err = to.sponsoredAssets.sponsorAsset(transfer.FeeAsset.ID, assetCost, blockID0)
+	require.NoError(t, err, "sponsorAsset() failed")
+	to.stor.flush(t)
+	fee, err = minFee(transfer, params)
+	require.NoError(t, err)
+	// The fee asset is scripted, so the extra fee is taken.
+	assert.Equal(t, assetCost*(FeeUnit+ScriptExtraFee)/FeeUnit, fee)
+}
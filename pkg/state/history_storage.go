@@ -375,6 +375,10 @@ type topEntryIterator struct {
 	dbIter keyvalue.Iterator
 	fmt    *historyFormatter
 	amend  bool
+	// If atBlock is set, the newest entries not after the block with number blockNum are returned
+	// instead of the top ones, keys without such entries are skipped.
+	atBlock  bool
+	blockNum uint32
 
 	err    error
 	curKey []byte
@@ -396,6 +400,15 @@ func (i *topEntryIterator) Next() bool {
 		if len(history.entries) == 0 {
 			continue
 		}
+		if i.atBlock {
+			data, ok := i.entryDataAtBlock(history)
+			if !ok {
+				continue
+			}
+			i.curKey = i.dbIter.Key()
+			i.curVal = data
+			return true
+		}
 		topEntry, err := history.topEntry()
 		if err != nil {
 			i.err = err
@@ -408,6 +421,20 @@ func (i *topEntryIterator) Next() bool {
 	return false
 }
 
+func (i *topEntryIterator) entryDataAtBlock(history *historyRecord) ([]byte, bool) {
+	var (
+		data []byte
+		ok   bool
+	)
+	for _, entry := range history.entries {
+		if entry.blockNum > i.blockNum {
+			break
+		}
+		data, ok = entry.data, true
+	}
+	return data, ok
+}
+
 func (i *topEntryIterator) Release() {
 	i.dbIter.Release()
 }
@@ -528,6 +555,35 @@ func (hs *historyStorage) newTopEntryIteratorByPrefix(prefix []byte) (*topEntryI
 	return &topEntryIterator{dbIter: dbIter, fmt: hs.fmt, amend: hs.amend}, nil
 }
 
+// newTopEntryIteratorFrom returns the iterator over the top entries of the keys with the given prefix,
+// starting from the start key.
+func (hs *historyStorage) newTopEntryIteratorFrom(prefix, start []byte) (*topEntryIterator, error) {
+	dbIter, err := hs.db.NewKeyIteratorFrom(prefix, start)
+	if err != nil {
+		return nil, err
+	}
+	return &topEntryIterator{dbIter: dbIter, fmt: hs.fmt, amend: hs.amend}, nil
+}
+
+// newEntryAtHeightIteratorFrom returns the iterator over the entries that were actual at the given height
+// for the keys with the given prefix, starting from the start key.
+func (hs *historyStorage) newEntryAtHeightIteratorFrom(
+	prefix, start []byte,
+	height proto.Height,
+) (*topEntryIterator, error) {
+	blockNum, err := hs.stateDB.blockNumByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := hs.newTopEntryIteratorFrom(prefix, start)
+	if err != nil {
+		return nil, err
+	}
+	iter.atBlock = true
+	iter.blockNum = blockNum
+	return iter, nil
+}
+
 func (hs *historyStorage) newTopEntryIterator(entity blockchainEntity) (*topEntryIterator, error) {
 	prefix, err := prefixByEntity(entity)
 	if err != nil {
@@ -827,7 +827,7 @@ type scriptParameters struct {
 	scriptAddr     proto.WavesAddress
 	txID           crypto.Digest
 	sender         proto.Address
-	tree           *ast.Tree
+	program        *ride.Program
 	scriptPK       crypto.PublicKey
 }
 
@@ -846,7 +846,7 @@ func (ia *invokeApplier) scriptParametersFromInvokeScript(
 	if err != nil {
 		return scriptParameters{}, errors.Wrapf(err, "failed to apply script invocation")
 	}
-	scriptParams.tree, err = ia.stor.scriptsStorage.newestScriptByAddr(scriptParams.scriptAddr)
+	scriptParams.program, err = ia.stor.scriptsStorage.newestProgramByAddr(scriptParams.scriptAddr)
 	if err != nil {
 		return scriptParameters{},
 			errors.Wrapf(err, "failed to instantiate script on address '%s'", scriptParams.scriptAddr.String())
@@ -869,10 +869,11 @@ func (ia *invokeApplier) scriptParametersFromInvokeExpression(
 	}
 	scriptParams.sender = addr
 	scriptParams.scriptAddr = addr
-	scriptParams.tree, err = serialization.Parse(transaction.Expression)
+	tree, err := serialization.Parse(transaction.Expression)
 	if err != nil {
 		return scriptParameters{}, errors.Wrap(err, "failed to parse decoded invoke expression into tree")
 	}
+	scriptParams.program = ride.NewProgram(tree)
 	scriptParams.txID = *transaction.ID
 	scriptParams.scriptPK = transaction.SenderPK
 	return scriptParams, nil
@@ -893,7 +894,7 @@ func (ia *invokeApplier) scriptParametersFromEthereumInvokeTransaction(
 	if err != nil {
 		return scriptParameters{}, errors.Wrapf(err, "failed to apply script invocation")
 	}
-	scriptParams.tree, err = ia.stor.scriptsStorage.newestScriptByAddr(scriptParams.scriptAddr)
+	scriptParams.program, err = ia.stor.scriptsStorage.newestProgramByAddr(scriptParams.scriptAddr)
 	if err != nil {
 		return scriptParameters{},
 			errors.Wrapf(err, "failed to instantiate script on address '%s'", scriptParams.scriptAddr.String())
@@ -1042,12 +1043,12 @@ func (ia *invokeApplier) applyInvokeScript(
 
 	// Check that the script's library supports multiple payments.
 	// We don't have to check feature activation because we've done it before.
-	if scriptParams.paymentsLength >= maxPaymentsLengthBeforeLibV4 && scriptParams.tree.LibVersion < ast.LibV4 {
+	if scriptParams.paymentsLength >= maxPaymentsLengthBeforeLibV4 && scriptParams.program.Tree.LibVersion < ast.LibV4 {
 		return nil, nil,
-			errors.Errorf("multiple payments is not allowed for RIDE library version %d", scriptParams.tree.LibVersion)
+			errors.Errorf("multiple payments is not allowed for RIDE library version %d", scriptParams.program.Tree.LibVersion)
 	}
 	// Refuse payments to DApp itself since activation of BlockV5 (acceptFailed) and for DApps with StdLib V4.
-	disableSelfTransfers := info.acceptFailed && scriptParams.tree.LibVersion >= ast.LibV4
+	disableSelfTransfers := info.acceptFailed && scriptParams.program.Tree.LibVersion >= ast.LibV4
 	if ia.refusePayments(scriptParams, disableSelfTransfers) {
 		return nil, nil, errors.New("paying to DApp itself is forbidden since RIDE V4")
 	}
@@ -1060,7 +1061,7 @@ func (ia *invokeApplier) applyInvokeScript(
 	}
 
 	// Call script function.
-	r, err := ia.sc.invokeFunction(scriptParams.tree, scriptEstimationUpdate, tx, info, scriptParams.scriptAddr)
+	r, err := ia.sc.invokeFunction(scriptParams.program, scriptEstimationUpdate, tx, info, scriptParams.scriptAddr)
 
 	if err != nil {
 		// Script returned error, it's OK, but we have to decide if it's a failed or rejected transaction.
@@ -1081,7 +1082,7 @@ func (ia *invokeApplier) applyInvokeScript(
 		actions:                  r.ScriptActions(),
 		paymentSmartAssets:       paymentSmartAssets,
 		disableSelfTransfers:     disableSelfTransfers,
-		libVersion:               scriptParams.tree.LibVersion,
+		libVersion:               scriptParams.program.Tree.LibVersion,
 	})
 	invocationRes, err := ia.handleFallibleValidationError(err, scriptParams.txID, code, info, scriptRuns, r)
 	if err != nil {
@@ -1130,7 +1131,7 @@ func (ia *invokeApplier) checkFullFee(tx proto.Transaction, scriptRuns, issuedAs
 		return nil
 	}
 	minIssueFee := feeConstants[proto.IssueTransaction] * FeeUnit * issuedAssetsCount
-	minWavesFee := scriptExtraFee*scriptRuns + feeConstants[proto.InvokeScriptTransaction]*FeeUnit + minIssueFee
+	minWavesFee := ScriptExtraFee*scriptRuns + feeConstants[proto.InvokeScriptTransaction]*FeeUnit + minIssueFee
 
 	wavesFee := tx.GetFee()
 
@@ -0,0 +1,122 @@
+package state
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+)
+
+// InvokeTrace is the result of invoke transaction replay with tracing of script evaluation.
+type InvokeTrace struct {
+	Trace      ride.Trace
+	Actions    []proto.ScriptAction // Actions produced by the script, empty if the evaluation failed
+	Complexity int
+	Error      error // Error of script evaluation, nil if the script succeeded
+}
+
+// newestValidationParams returns the parameters of transaction validation on top of the newest state.
+func (a *txAppender) newestValidationParams() (*fallibleValidationParams, error) {
+	block, err := a.currentBlock()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get current block")
+	}
+	blockInfo, err := a.currentBlockInfo()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get current block info")
+	}
+	var blockV5, rideV5, rideV6, consensusImprovements, blockRewardDistribution, lightNode bool
+	for f, v := range map[settings.Feature]*bool{
+		settings.BlockV5:                 &blockV5,
+		settings.RideV5:                  &rideV5,
+		settings.RideV6:                  &rideV6,
+		settings.ConsensusImprovements:   &consensusImprovements,
+		settings.BlockRewardDistribution: &blockRewardDistribution,
+		settings.LightNode:               &lightNode,
+	} {
+		if *v, err = a.stor.features.newestIsActivated(int16(f)); err != nil {
+			return nil, errors.Wrapf(err, "failed to check activation of feature %d", f)
+		}
+	}
+	return &fallibleValidationParams{
+		appendTxParams: &appendTxParams{
+			checkerInfo: &checkerInfo{
+				currentTimestamp:        blockInfo.Timestamp,
+				blockID:                 block.BlockID(),
+				blockVersion:            block.Version,
+				blockchainHeight:        blockInfo.Height - 1,
+				rideV5Activated:         rideV5,
+				rideV6Activated:         rideV6,
+				blockRewardDistribution: blockRewardDistribution,
+			},
+			blockInfo:                        blockInfo,
+			block:                            block,
+			acceptFailed:                     blockV5,
+			blockV5Activated:                 blockV5,
+			rideV5Activated:                  rideV5,
+			rideV6Activated:                  rideV6,
+			consensusImprovementsActivated:   consensusImprovements,
+			blockRewardDistributionActivated: blockRewardDistribution,
+			lightNodeActivated:               lightNode,
+			validatingUtx:                    true,
+		},
+	}, nil
+}
+
+// traceInvoke evaluates the script invoked by the transaction with the tree evaluator recording the evaluation steps.
+// Nothing is applied to the state.
+func (a *txAppender) traceInvoke(
+	tx proto.Transaction, params *fallibleValidationParams, maxSteps int,
+) (*InvokeTrace, error) {
+	scriptParams, err := a.ia.collectScriptParameters(tx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to collect script parameters")
+	}
+	tr := ride.NewTracer(maxSteps)
+	info := &fallibleValidationParams{
+		appendTxParams: params.appendTxParams,
+		senderAddress:  scriptParams.sender,
+		tracer:         tr,
+	}
+	// The separate script caller with nil executor evaluates scripts by tree evaluator and doesn't affect
+	// the complexity counters of the appender.
+	sc, err := newScriptCaller(a.sc.state, a.stor, a.settings, nil)
+	if err != nil {
+		return nil, err
+	}
+	r, err := sc.invokeFunction(scriptParams.program, nil, tx, info, scriptParams.scriptAddr)
+	if err != nil {
+		return &InvokeTrace{Trace: tr.Trace(), Complexity: ride.EvaluationErrorSpentComplexity(err), Error: err}, nil
+	}
+	return &InvokeTrace{Trace: tr.Trace(), Actions: r.ScriptActions(), Complexity: r.Complexity()}, nil
+}
+
+// TraceInvoke replays the invoke transaction with the given ID recording the trace of script evaluation.
+// The script is evaluated against the newest state, so the result may differ from the original one
+// if the state has been changed since the transaction was applied.
+func (s *stateManager) TraceInvoke(id []byte, maxSteps int) (*InvokeTrace, error) {
+	tx, err := s.TransactionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	invoke := false
+	switch t := tx.(type) {
+	case *proto.InvokeScriptWithProofs, *proto.InvokeExpressionTransactionWithProofs:
+		invoke = true
+	case *proto.EthereumTransaction:
+		_, invoke = t.TxKind.(*proto.EthereumInvokeScriptTxKind)
+	}
+	if !invoke {
+		return nil, wrapErr(InvalidInputError, errors.Errorf("transaction %s is not an invoke", tx.GetTypeInfo().Type))
+	}
+	params, err := s.appender.newestValidationParams()
+	if err != nil {
+		return nil, wrapErr(Other, err)
+	}
+	r, err := s.appender.traceInvoke(tx, params, maxSteps)
+	if err != nil {
+		return nil, wrapErr(Other, err)
+	}
+	return r, nil
+}
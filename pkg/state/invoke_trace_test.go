@@ -0,0 +1,51 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
+)
+
+func TestTraceInvoke(t *testing.T) {
+	to := createInvokeApplierTestObjects(t)
+	info := to.fallibleValidationParams(t)
+	to.setDApp(t, "dapp.base64", testGlobal.recipientInfo)
+	to.setAndCheckInitialWavesBalance(t, testGlobal.senderInfo.addr, invokeFee+100)
+
+	findStep := func(trace ride.Trace, kind, name string) (ride.TraceStep, bool) {
+		for _, s := range trace.Steps {
+			if s.Kind == kind && s.Name == name {
+				return s, true
+			}
+		}
+		return ride.TraceStep{}, false
+	}
+
+	pmts := []proto.ScriptPayment{{Amount: 10}}
+	tx := createInvokeScriptWithProofs(t, pmts, proto.NewFunctionCall("deposit", proto.Arguments{}), feeAsset, invokeFee)
+	r, err := to.state.appender.traceInvoke(tx, info, ride.DefaultMaxTraceSteps)
+	require.NoError(t, err)
+	require.NoError(t, r.Error)
+	assert.Len(t, r.Actions, 1)
+	assert.Positive(t, r.Complexity)
+	step, ok := findStep(r.Trace, ride.TraceStepLet, "pmt")
+	require.True(t, ok)
+	assert.Equal(t, "AttachedPayment", step.Value.Type)
+	_, ok = findStep(r.Trace, ride.TraceStepBranch, "else")
+	assert.True(t, ok)
+
+	args := proto.Arguments{proto.NewIntegerArgument(-1)}
+	tx = createInvokeScriptWithProofs(t, nil, proto.NewFunctionCall("withdraw", args), feeAsset, invokeFee)
+	r, err = to.state.appender.traceInvoke(tx, info, ride.DefaultMaxTraceSteps)
+	require.NoError(t, err)
+	assert.Error(t, r.Error)
+	assert.Empty(t, r.Actions)
+	step, ok = findStep(r.Trace, ride.TraceStepBranch, "then")
+	require.True(t, ok)
+	assert.NotEmpty(t, step.Error)
+	assert.Nil(t, step.Value)
+}
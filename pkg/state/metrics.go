@@ -0,0 +1,93 @@
+package state
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const stateMetricsNamespace = "state"
+
+const (
+	applyStageValidation = "validation"
+	applyStageScripts    = "scripts"
+	applyStageFlush      = "flush"
+)
+
+const (
+	txStatusSucceeded = "succeeded"
+	txStatusFailed    = "failed"
+	txStatusElided    = "elided"
+)
+
+const (
+	scriptKindAccount    = "account"
+	scriptKindAsset      = "asset"
+	scriptKindDApp       = "dapp"
+	scriptKindExpression = "expression"
+)
+
+var (
+	metricBlocksApplyDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: stateMetricsNamespace,
+			Name:      "blocks_apply_duration_seconds",
+			Help:      "Duration of application of blocks batch by stages: validation, scripts execution and flush.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+		},
+		[]string{"stage"},
+	)
+
+	metricBlocksApplied = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: stateMetricsNamespace,
+			Name:      "blocks_applied_total",
+			Help:      "Number of applied blocks, including the blocks re-applied with new microblocks.",
+		},
+	)
+
+	metricTransactions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: stateMetricsNamespace,
+			Name:      "transactions_total",
+			Help:      "Number of transactions applied in blocks by type and status.",
+		},
+		[]string{"type", "status"},
+	)
+
+	metricScriptComplexity = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: stateMetricsNamespace,
+			Name:      "ride_complexity",
+			Help:      "Complexity of RIDE scripts executions by script kind.",
+			Buckets:   prometheus.ExponentialBuckets(10, 2, 14),
+		},
+		[]string{"kind"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricBlocksApplyDuration,
+		metricBlocksApplied,
+		metricTransactions,
+		metricScriptComplexity,
+	)
+}
+
+func observeBlocksApply(blocks int, validation, scripts, flush time.Duration) {
+	metricBlocksApplyDuration.WithLabelValues(applyStageValidation).Observe(validation.Seconds())
+	metricBlocksApplyDuration.WithLabelValues(applyStageScripts).Observe(scripts.Seconds())
+	metricBlocksApplyDuration.WithLabelValues(applyStageFlush).Observe(flush.Seconds())
+	metricBlocksApplied.Add(float64(blocks))
+}
+
+func countTransaction(tx proto.Transaction, status string) {
+	metricTransactions.WithLabelValues(tx.GetTypeInfo().Type.String(), status).Inc()
+}
+
+func observeScriptComplexity(kind string, complexity int) {
+	metricScriptComplexity.WithLabelValues(kind).Observe(float64(complexity))
+}
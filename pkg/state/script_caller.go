@@ -1,8 +1,11 @@
 package state
 
 import (
+	"time"
+
 	"github.com/mr-tron/base58/base58"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/errs"
@@ -18,23 +21,73 @@ type scriptCaller struct {
 
 	stor     *blockchainEntitiesStorage
 	settings *settings.BlockchainSettings
+	executor *ride.Executor
 
 	totalComplexity    uint64
 	recentTxComplexity uint64
+	// scriptsDuration accumulates the time spent on scripts execution, it's reset by the caller.
+	scriptsDuration time.Duration
 }
 
 func newScriptCaller(
 	state types.EnrichedSmartState,
 	stor *blockchainEntitiesStorage,
 	settings *settings.BlockchainSettings,
+	executor *ride.Executor,
 ) (*scriptCaller, error) {
 	return &scriptCaller{
 		state:    state,
 		stor:     stor,
 		settings: settings,
+		executor: executor,
 	}, nil
 }
 
+// newRideExecutor creates the executor of scripts that logs divergences of RIDE engines found in diff mode.
+func newRideExecutor(mode ride.ExecutionMode) *ride.Executor {
+	return ride.NewExecutor(mode, func(d ride.Divergence) {
+		zap.S().Warnf("RIDE engines diverged on script '%s' function '%s': %s", d.Script, d.Function, d.Reason)
+	})
+}
+
+// callVerifier executes the verifier of script, measuring the execution time and complexity.
+func (a *scriptCaller) callVerifier(
+	env *ride.EvaluationEnvironment, program *ride.Program, kind string,
+) (ride.Result, error) {
+	start := time.Now()
+	r, err := a.executor.CallVerifier(env, program)
+	a.scriptsDuration += time.Since(start)
+	observeScriptComplexity(kind, spentComplexity(r, err))
+	return r, err
+}
+
+// callExpression executes the expression of InvokeExpression transaction, measuring the execution time and complexity.
+func (a *scriptCaller) callExpression(env *ride.EvaluationEnvironment, program *ride.Program) (ride.Result, error) {
+	start := time.Now()
+	r, err := a.executor.CallExpression(env, program)
+	a.scriptsDuration += time.Since(start)
+	observeScriptComplexity(scriptKindExpression, spentComplexity(r, err))
+	return r, err
+}
+
+// callFunction executes the callable function of dApp, measuring the execution time and complexity.
+func (a *scriptCaller) callFunction(
+	env *ride.EvaluationEnvironment, program *ride.Program, call proto.FunctionCall,
+) (ride.Result, error) {
+	start := time.Now()
+	r, err := a.executor.CallFunction(env, program, call)
+	a.scriptsDuration += time.Since(start)
+	observeScriptComplexity(scriptKindDApp, spentComplexity(r, err))
+	return r, err
+}
+
+func spentComplexity(r ride.Result, err error) int {
+	if err != nil {
+		return ride.EvaluationErrorSpentComplexity(err)
+	}
+	return r.Complexity()
+}
+
 // callAccountScriptWithOrder calls account script. This method must not be called for proto.EthereumAddress.
 func (a *scriptCaller) callAccountScriptWithOrder(order proto.Order, lastBlockInfo *proto.BlockInfo, info *fallibleValidationParams) error {
 	senderAddr, err := order.GetSender(a.settings.AddressSchemeCharacter)
@@ -49,7 +102,7 @@ func (a *scriptCaller) callAccountScriptWithOrder(order proto.Order, lastBlockIn
 	if err != nil {
 		return err
 	}
-	tree, err := a.stor.scriptsStorage.newestScriptByAddr(senderWavesAddr)
+	program, err := a.stor.scriptsStorage.newestProgramByAddr(senderWavesAddr)
 	if err != nil {
 		return errors.Wrap(err, "failed to retrieve account script")
 	}
@@ -68,17 +121,17 @@ func (a *scriptCaller) callAccountScriptWithOrder(order proto.Order, lastBlockIn
 		return errors.Wrap(err, "failed to create RIDE environment")
 	}
 	env.SetThisFromAddress(senderWavesAddr)
-	env.ChooseSizeCheck(tree.LibVersion)
+	env.ChooseSizeCheck(program.Tree.LibVersion)
 	if err = env.SetLastBlockFromBlockInfo(lastBlockInfo); err != nil {
 		return errors.Wrap(err, "failed to convert order")
 	}
 	env.ChooseTakeString(info.rideV5Activated)
 	env.ChooseMaxDataEntriesSize(info.rideV5Activated)
 	env.SetLimit(ride.MaxVerifierComplexity(info.rideV5Activated))
-	if err = env.SetTransactionFromOrder(order, tree.LibVersion); err != nil {
+	if err = env.SetTransactionFromOrder(order, program.Tree.LibVersion); err != nil {
 		return errors.Wrap(err, "failed to convert order")
 	}
-	r, err := ride.CallVerifier(env, tree)
+	r, err := a.callVerifier(env, program, scriptKindAccount)
 	if err != nil {
 		return errors.Errorf("account script on order '%s' thrown error with message: %s", base58.Encode(id), err.Error())
 	}
@@ -109,7 +162,7 @@ func (a *scriptCaller) callAccountScriptWithTx(tx proto.Transaction, params *app
 	if !ok {
 		return errors.Errorf("address %q must be a waves address, not %T", senderAddr.String(), senderAddr)
 	}
-	tree, err := a.stor.scriptsStorage.newestScriptByAddr(senderWavesAddr)
+	program, err := a.stor.scriptsStorage.newestProgramByAddr(senderWavesAddr)
 	if err != nil {
 		return err
 	}
@@ -131,7 +184,7 @@ func (a *scriptCaller) callAccountScriptWithTx(tx proto.Transaction, params *app
 	if err != nil {
 		return errors.Wrapf(err, "failed to call account script on transaction '%s'", base58.Encode(id))
 	}
-	env.ChooseSizeCheck(tree.LibVersion)
+	env.ChooseSizeCheck(program.Tree.LibVersion)
 	env.ChooseTakeString(params.rideV5Activated)
 	env.ChooseMaxDataEntriesSize(params.rideV5Activated)
 	env.SetThisFromAddress(senderWavesAddr)
@@ -142,7 +195,7 @@ func (a *scriptCaller) callAccountScriptWithTx(tx proto.Transaction, params *app
 	if err := env.SetTransaction(tx); err != nil {
 		return errors.Wrapf(err, "failed to call account script on transaction '%s'", base58.Encode(id))
 	}
-	r, err := ride.CallVerifier(env, tree)
+	r, err := a.callVerifier(env, program, scriptKindAccount)
 	if err != nil {
 		return errors.Errorf("account script on transaction '%s' failed with error: %v", base58.Encode(id), err.Error())
 	}
@@ -164,21 +217,21 @@ func (a *scriptCaller) callAccountScriptWithTx(tx proto.Transaction, params *app
 }
 
 func (a *scriptCaller) callAssetScriptCommon(env *ride.EvaluationEnvironment, setTx func(*ride.EvaluationEnvironment) error, assetID crypto.Digest, params *appendTxParams) (ride.Result, error) {
-	tree, err := a.stor.scriptsStorage.newestScriptByAsset(proto.AssetIDFromDigest(assetID))
+	program, err := a.stor.scriptsStorage.newestProgramByAsset(proto.AssetIDFromDigest(assetID))
 	if err != nil {
 		return nil, err
 	}
-	env.ChooseSizeCheck(tree.LibVersion)
+	env.ChooseSizeCheck(program.Tree.LibVersion)
 	env.ChooseTakeString(params.rideV5Activated)
 	env.ChooseMaxDataEntriesSize(params.rideV5Activated)
-	env.SetLimit(ride.MaxAssetVerifierComplexity(tree.LibVersion))
+	env.SetLimit(ride.MaxAssetVerifierComplexity(program.Tree.LibVersion))
 
 	// Set transaction only after library version is set by `env.ChooseSizeCheck`
 	if err = setTx(env); err != nil {
 		return nil, err
 	}
 
-	switch tree.LibVersion {
+	switch program.Tree.LibVersion {
 	case ast.LibV1, ast.LibV2, ast.LibV3:
 		assetInfo, err := a.state.NewestAssetInfo(assetID)
 		if err != nil {
@@ -195,7 +248,7 @@ func (a *scriptCaller) callAssetScriptCommon(env *ride.EvaluationEnvironment, se
 	if err := env.SetLastBlockFromBlockInfo(params.blockInfo); err != nil {
 		return nil, err
 	}
-	r, err := ride.CallVerifier(env, tree)
+	r, err := a.callVerifier(env, program, scriptKindAsset)
 	if err != nil {
 		return nil, errs.NewTransactionNotAllowedByScript(err.Error(), assetID.Bytes())
 	}
@@ -263,7 +316,7 @@ func (a *scriptCaller) callAssetScript(tx proto.Transaction, assetID crypto.Dige
 }
 
 func (a *scriptCaller) invokeFunction(
-	tree *ast.Tree,
+	program *ride.Program,
 	scriptEstimationUpdate *scriptEstimation, // can be nil
 	tx proto.Transaction,
 	info *fallibleValidationParams,
@@ -284,18 +337,19 @@ func (a *scriptCaller) invokeFunction(
 		return nil, errors.Wrap(err, "failed to create RIDE environment")
 	}
 	env.SetThisFromAddress(scriptAddress)
-	env.ChooseSizeCheck(tree.LibVersion)
+	env.ChooseSizeCheck(program.Tree.LibVersion)
 	if err := env.SetLastBlockFromBlockInfo(info.blockInfo); err != nil {
 		return nil, errors.Wrap(err, "failed to create RIDE environment")
 	}
 	env.SetTimestamp(tx.GetTimestamp())
 	env.ChooseTakeString(info.rideV5Activated)
 	env.ChooseMaxDataEntriesSize(info.rideV5Activated)
-	limit, err := ride.MaxChainInvokeComplexityByVersion(tree.LibVersion)
+	limit, err := ride.MaxChainInvokeComplexityByVersion(program.Tree.LibVersion)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to set limit for invoke")
 	}
 	env.SetLimit(limit)
+	env.SetTracer(info.tracer)
 
 	err = env.SetTransaction(tx)
 	if err != nil {
@@ -310,7 +364,7 @@ func (a *scriptCaller) invokeFunction(
 	if err != nil {
 		return nil, err
 	}
-	r, functionCall, err := a.doTxInvoke(tx, sender, scriptAddress, env, tree, scriptEstimationUpdate, info)
+	r, functionCall, err := a.doTxInvoke(tx, sender, scriptAddress, env, program, scriptEstimationUpdate, info)
 	if err != nil {
 		return nil, err
 	}
@@ -363,7 +417,7 @@ func (a *scriptCaller) doTxInvoke(
 	sender proto.WavesAddress,
 	scriptAddress proto.WavesAddress,
 	env *ride.EvaluationEnvironment,
-	tree *ast.Tree,
+	program *ride.Program,
 	scriptEstimationUpdate *scriptEstimation,
 	info *fallibleValidationParams,
 ) (ride.Result, proto.FunctionCall, error) {
@@ -384,7 +438,7 @@ func (a *scriptCaller) doTxInvoke(
 	switch transaction := tx.(type) {
 	case *proto.InvokeScriptWithProofs:
 		r, functionCall, err := a.invokeFunctionByInvokeWithProofsTx(transaction, sender, scriptAddress,
-			env, tree, scriptEstimationUpdate, info,
+			env, program, scriptEstimationUpdate, info,
 		)
 		if err != nil {
 			return nil, functionCall, err
@@ -394,7 +448,7 @@ func (a *scriptCaller) doTxInvoke(
 		// don't initialize function call because invoke expression tx can call only default function
 		var functionCall proto.FunctionCall
 		r, err := a.invokeFunctionByInvokeExpressionWithProofsTx(transaction, sender, scriptAddress,
-			env, tree, scriptEstimationUpdate, info,
+			env, program, scriptEstimationUpdate, info,
 		)
 		if err != nil {
 			return nil, functionCall, err
@@ -402,7 +456,7 @@ func (a *scriptCaller) doTxInvoke(
 		return r, functionCall, nil
 	case *proto.EthereumTransaction:
 		r, functionCall, err := a.invokeFunctionByEthereumTx(transaction, sender, scriptAddress,
-			env, tree, scriptEstimationUpdate, info,
+			env, program, scriptEstimationUpdate, info,
 		)
 		if err != nil {
 			return nil, functionCall, err
@@ -419,19 +473,19 @@ func (a *scriptCaller) invokeFunctionByInvokeWithProofsTx(
 	sender proto.WavesAddress,
 	scriptAddress proto.WavesAddress,
 	env *ride.EvaluationEnvironment,
-	tree *ast.Tree,
+	program *ride.Program,
 	scriptEstimationUpdate *scriptEstimation,
 	info *fallibleValidationParams,
 ) (ride.Result, proto.FunctionCall, error) {
-	err := env.SetInvoke(tx, tree.LibVersion)
+	err := env.SetInvoke(tx, program.Tree.LibVersion)
 	if err != nil {
 		return nil, proto.FunctionCall{}, err
 	}
 
 	// Since V5 we have to create environment with wrapped state to which we put attached payments
-	if tree.LibVersion >= ast.LibV5 {
+	if program.Tree.LibVersion >= ast.LibV5 {
 		isPbTx := proto.IsProtobufTx(tx)
-		env, err = ride.NewEnvironmentWithWrappedState(env, a.state, tx.Payments, sender, isPbTx, tree.LibVersion, true)
+		env, err = ride.NewEnvironmentWithWrappedState(env, a.state, tx.Payments, sender, isPbTx, program.Tree.LibVersion, true)
 		if err != nil {
 			return nil, proto.FunctionCall{}, errors.Wrapf(err, "failed to create RIDE environment with wrapped state")
 		}
@@ -439,7 +493,7 @@ func (a *scriptCaller) invokeFunctionByInvokeWithProofsTx(
 
 	functionCall := tx.FunctionCall
 
-	r, err := ride.CallFunction(env, tree, functionCall)
+	r, err := a.callFunction(env, program, functionCall)
 	if err != nil {
 		complexity := ride.EvaluationErrorSpentComplexity(err)
 		appendErr := a.appendFunctionComplexity(complexity, scriptAddress, scriptEstimationUpdate, functionCall, info)
@@ -456,7 +510,7 @@ func (a *scriptCaller) invokeFunctionByEthereumTx(
 	sender proto.WavesAddress,
 	scriptAddress proto.WavesAddress,
 	env *ride.EvaluationEnvironment,
-	tree *ast.Tree,
+	program *ride.Program,
 	scriptEstimationUpdate *scriptEstimation,
 	info *fallibleValidationParams,
 ) (ride.Result, proto.FunctionCall, error) {
@@ -471,18 +525,18 @@ func (a *scriptCaller) invokeFunctionByEthereumTx(
 		scriptPayments = append(scriptPayments, scriptPayment)
 	}
 
-	err := env.SetEthereumInvoke(tx, tree.LibVersion, scriptPayments)
+	err := env.SetEthereumInvoke(tx, program.Tree.LibVersion, scriptPayments)
 	if err != nil {
 		return nil, proto.FunctionCall{}, err
 	}
 	// Since V5 we have to create environment with wrapped state to which we put attached payments
-	if tree.LibVersion >= ast.LibV5 {
+	if program.Tree.LibVersion >= ast.LibV5 {
 		const checkSenderBalance = false // skip initial payments validation for eth tx, see PR #965 for more info
 		//TODO: Update last argument of the followinxg call with new feature activation flag or
 		// something else depending on NODE-2531 issue resolution in scala implementation.
 		isPbTx := proto.IsProtobufTx(tx)
 		env, err = ride.NewEnvironmentWithWrappedState(env, a.state, scriptPayments, sender,
-			isPbTx, tree.LibVersion, checkSenderBalance,
+			isPbTx, program.Tree.LibVersion, checkSenderBalance,
 		)
 		if err != nil {
 			return nil, proto.FunctionCall{}, errors.Wrap(err, "failed to create RIDE environment with wrapped state")
@@ -496,7 +550,7 @@ func (a *scriptCaller) invokeFunctionByEthereumTx(
 	}
 	functionCall := proto.NewFunctionCall(decodedData.Name, arguments)
 
-	r, err := ride.CallFunction(env, tree, functionCall)
+	r, err := a.callFunction(env, program, functionCall)
 	if err != nil {
 		complexity := ride.EvaluationErrorSpentComplexity(err)
 		appendErr := a.appendFunctionComplexity(complexity, scriptAddress, scriptEstimationUpdate, functionCall, info)
@@ -513,11 +567,11 @@ func (a *scriptCaller) invokeFunctionByInvokeExpressionWithProofsTx(
 	sender proto.WavesAddress,
 	scriptAddress proto.WavesAddress,
 	env *ride.EvaluationEnvironment,
-	tree *ast.Tree,
+	program *ride.Program,
 	scriptEstimationUpdate *scriptEstimation,
 	info *fallibleValidationParams,
 ) (ride.Result, error) {
-	err := env.SetInvoke(tx, tree.LibVersion)
+	err := env.SetInvoke(tx, program.Tree.LibVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -526,15 +580,15 @@ func (a *scriptCaller) invokeFunctionByInvokeExpressionWithProofsTx(
 		payments     proto.ScriptPayments // payments aren't available for invoke expression transaction
 	)
 	// Since V5 we have to create environment with wrapped state to which we put attached payments
-	if tree.LibVersion >= ast.LibV5 {
+	if program.Tree.LibVersion >= ast.LibV5 {
 		isPbTx := proto.IsProtobufTx(tx)
-		env, err = ride.NewEnvironmentWithWrappedState(env, a.state, payments, sender, isPbTx, tree.LibVersion, true)
+		env, err = ride.NewEnvironmentWithWrappedState(env, a.state, payments, sender, isPbTx, program.Tree.LibVersion, true)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create RIDE environment with wrapped state")
 		}
 	}
 
-	r, err := ride.CallVerifier(env, tree)
+	r, err := a.callExpression(env, program)
 	if err != nil {
 		complexity := ride.EvaluationErrorSpentComplexity(err)
 		appendErr := a.appendFunctionComplexity(complexity, scriptAddress, scriptEstimationUpdate, functionCall, info)
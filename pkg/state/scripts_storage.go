@@ -9,6 +9,7 @@ import (
 
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
 	"github.com/wavesplatform/gowaves/pkg/ride/ast"
 	"github.com/wavesplatform/gowaves/pkg/ride/serialization"
 )
@@ -228,7 +229,7 @@ func (ss *scriptsStorage) setScript(scriptType blockchainEntity, key scriptKey,
 		ss.cache.deleteIfExists(scriptKeyBytes)
 		return nil
 	}
-	ss.cache.set(scriptKeyBytes, *dbItem.tree, scriptSize)
+	ss.cache.set(scriptKeyBytes, ride.NewProgram(dbItem.tree), scriptSize)
 	return nil
 }
 
@@ -361,20 +362,35 @@ func (ss *scriptsStorage) isSmartAsset(assetID proto.AssetID) (bool, error) {
 }
 
 func (ss *scriptsStorage) newestScriptByAsset(assetID proto.AssetID) (*ast.Tree, error) {
+	program, err := ss.newestProgramByAsset(assetID)
+	if err != nil {
+		return nil, err
+	}
+	return program.Tree, nil
+}
+
+// newestProgramByAsset returns the asset script together with its compiled code.
+// Compiled code of the cached program is shared between evaluations.
+func (ss *scriptsStorage) newestProgramByAsset(assetID proto.AssetID) (*ride.Program, error) {
 	if r, ok := ss.uncertainAssetScripts[assetID]; ok {
-		return ss.scriptAstFromRecordBytes(r.scriptDBItem.script) // Possible errors `proto.ErrNotFound` and parsing errors.
+		tree, err := ss.scriptAstFromRecordBytes(r.scriptDBItem.script) // Possible errors `proto.ErrNotFound` and parsing errors.
+		if err != nil {
+			return nil, err
+		}
+		return ride.NewProgram(tree), nil
 	}
 	key := assetScriptKey{assetID}
 	keyBytes := key.bytes()
-	if script, has := ss.cache.get(keyBytes); has {
-		return &script, nil
+	if program, has := ss.cache.get(keyBytes); has {
+		return program, nil
 	}
 	tree, err := ss.newestScriptAstByKey(keyBytes)
 	if err != nil {
 		return nil, err
 	}
-	ss.cache.set(keyBytes, *tree, scriptSize)
-	return tree, nil
+	program := ride.NewProgram(tree)
+	ss.cache.set(keyBytes, program, scriptSize)
+	return program, nil
 }
 
 func (ss *scriptsStorage) scriptByAsset(assetID proto.AssetID) (*ast.Tree, error) {
@@ -423,8 +439,8 @@ func (ss *scriptsStorage) setAccountScript(addr proto.WavesAddress, script proto
 func (ss *scriptsStorage) newestAccountIsDApp(addr proto.WavesAddress) (bool, error) {
 	key := accountScriptKey{addr.ID()}
 	keyBytes := key.bytes()
-	if script, has := ss.cache.get(keyBytes); has {
-		return script.IsDApp(), nil
+	if program, has := ss.cache.get(keyBytes); has {
+		return program.Tree.IsDApp(), nil
 	}
 	infoKey := scriptBasicInfoKey{scriptKey: &key}
 	recordBytes, err := ss.hs.newestTopEntryData(infoKey.bytes())
@@ -462,8 +478,8 @@ func (ss *scriptsStorage) accountIsDApp(addr proto.WavesAddress) (bool, error) {
 func (ss *scriptsStorage) newestAccountHasVerifier(addr proto.WavesAddress) (bool, error) {
 	key := accountScriptKey{addr.ID()}
 	keyBytes := key.bytes()
-	if script, has := ss.cache.get(keyBytes); has {
-		return script.HasVerifier(), nil
+	if program, has := ss.cache.get(keyBytes); has {
+		return program.Tree.HasVerifier(), nil
 	}
 	infoKey := scriptBasicInfoKey{scriptKey: &key}
 	recordBytes, err := ss.hs.newestTopEntryData(infoKey.bytes())
@@ -527,17 +543,28 @@ func (ss *scriptsStorage) accountHasScript(addr proto.WavesAddress) (bool, error
 }
 
 func (ss *scriptsStorage) newestScriptByAddr(addr proto.WavesAddress) (*ast.Tree, error) {
+	program, err := ss.newestProgramByAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	return program.Tree, nil
+}
+
+// newestProgramByAddr returns the account script together with its compiled code.
+// Compiled code of the cached program is shared between evaluations.
+func (ss *scriptsStorage) newestProgramByAddr(addr proto.WavesAddress) (*ride.Program, error) {
 	key := accountScriptKey{addr.ID()}
 	keyBytes := key.bytes()
-	if tree, has := ss.cache.get(keyBytes); has {
-		return &tree, nil
+	if program, has := ss.cache.get(keyBytes); has {
+		return program, nil
 	}
 	tree, err := ss.newestScriptAstByKey(keyBytes)
 	if err != nil {
 		return nil, err
 	}
-	ss.cache.set(keyBytes, *tree, scriptSize)
-	return tree, nil
+	program := ride.NewProgram(tree)
+	ss.cache.set(keyBytes, program, scriptSize)
+	return program, nil
 }
 
 func (ss *scriptsStorage) newestScriptBasicInfoByAddressID(addressID proto.AddressID) (scriptBasicInfoRecord, error) {
@@ -579,6 +606,16 @@ func (ss *scriptsStorage) scriptByAddr(addr proto.WavesAddress) (*ast.Tree, erro
 	return ss.scriptTreeByKey(key.bytes())
 }
 
+// scriptByAddrAtHeight returns script of corresponding proto.WavesAddress as of the given height.
+func (ss *scriptsStorage) scriptByAddrAtHeight(addr proto.WavesAddress, height proto.Height) (*ast.Tree, error) {
+	key := accountScriptKey{addr: addr.ID()}
+	script, err := ss.hs.entryDataAtHeight(key.bytes(), height)
+	if err != nil {
+		return nil, err
+	}
+	return ss.scriptAstFromRecordBytes(script) // Possible errors `proto.ErrNotFound` and parsing errors.
+}
+
 // scriptBytesByAddr returns script bytes of corresponding proto.WavesAddress.
 // Note that only real proto.WavesAddress account can have a scripts.
 func (ss *scriptsStorage) scriptBytesByAddr(addr proto.WavesAddress) (proto.Script, error) {
@@ -3,6 +3,7 @@ package state
 import (
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
 	"github.com/wavesplatform/gowaves/pkg/ride/ast"
 )
 
@@ -16,6 +17,7 @@ type scriptStorageState interface {
 	newestIsSmartAsset(assetID proto.AssetID) (bool, error)
 	isSmartAsset(assetID proto.AssetID) (bool, error)
 	newestScriptByAsset(assetID proto.AssetID) (*ast.Tree, error)
+	newestProgramByAsset(assetID proto.AssetID) (*ride.Program, error)
 	scriptByAsset(assetID proto.AssetID) (*ast.Tree, error)
 	scriptBytesByAsset(assetID proto.AssetID) (proto.Script, error)
 	newestScriptBytesByAsset(assetID proto.AssetID) (proto.Script, error)
@@ -28,9 +30,11 @@ type scriptStorageState interface {
 	newestAccountHasScript(addr proto.WavesAddress) (bool, error)
 	accountHasScript(addr proto.WavesAddress) (bool, error)
 	newestScriptByAddr(addr proto.WavesAddress) (*ast.Tree, error)
+	newestProgramByAddr(addr proto.WavesAddress) (*ride.Program, error)
 	newestScriptBasicInfoByAddressID(addressID proto.AddressID) (scriptBasicInfoRecord, error)
 	scriptBasicInfoByAddressID(addressID proto.AddressID) (scriptBasicInfoRecord, error)
 	scriptByAddr(addr proto.WavesAddress) (*ast.Tree, error)
+	scriptByAddrAtHeight(addr proto.WavesAddress, height proto.Height) (*ast.Tree, error)
 	scriptBytesByAddr(addr proto.WavesAddress) (proto.Script, error)
 	clearCache() error
 	prepareHashes() error
@@ -6,6 +6,7 @@ package state
 import (
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride"
 	"github.com/wavesplatform/gowaves/pkg/ride/ast"
 	"sync"
 )
@@ -59,6 +60,12 @@ var _ scriptStorageState = &mockScriptStorageState{}
 //			newestIsSmartAssetFunc: func(assetID proto.AssetID) (bool, error) {
 //				panic("mock out the newestIsSmartAsset method")
 //			},
+//			newestProgramByAddrFunc: func(addr proto.WavesAddress) (*ride.Program, error) {
+//				panic("mock out the newestProgramByAddr method")
+//			},
+//			newestProgramByAssetFunc: func(assetID proto.AssetID) (*ride.Program, error) {
+//				panic("mock out the newestProgramByAsset method")
+//			},
 //			newestScriptBasicInfoByAddressIDFunc: func(addressID proto.AddressID) (scriptBasicInfoRecord, error) {
 //				panic("mock out the newestScriptBasicInfoByAddressID method")
 //			},
@@ -86,6 +93,9 @@ var _ scriptStorageState = &mockScriptStorageState{}
 //			scriptByAddrFunc: func(addr proto.WavesAddress) (*ast.Tree, error) {
 //				panic("mock out the scriptByAddr method")
 //			},
+//			scriptByAddrAtHeightFunc: func(addr proto.WavesAddress, height proto.Height) (*ast.Tree, error) {
+//				panic("mock out the scriptByAddrAtHeight method")
+//			},
 //			scriptByAssetFunc: func(assetID proto.AssetID) (*ast.Tree, error) {
 //				panic("mock out the scriptByAsset method")
 //			},
@@ -153,6 +163,12 @@ type mockScriptStorageState struct {
 	// newestIsSmartAssetFunc mocks the newestIsSmartAsset method.
 	newestIsSmartAssetFunc func(assetID proto.AssetID) (bool, error)
 
+	// newestProgramByAddrFunc mocks the newestProgramByAddr method.
+	newestProgramByAddrFunc func(addr proto.WavesAddress) (*ride.Program, error)
+
+	// newestProgramByAssetFunc mocks the newestProgramByAsset method.
+	newestProgramByAssetFunc func(assetID proto.AssetID) (*ride.Program, error)
+
 	// newestScriptBasicInfoByAddressIDFunc mocks the newestScriptBasicInfoByAddressID method.
 	newestScriptBasicInfoByAddressIDFunc func(addressID proto.AddressID) (scriptBasicInfoRecord, error)
 
@@ -180,6 +196,9 @@ type mockScriptStorageState struct {
 	// scriptByAddrFunc mocks the scriptByAddr method.
 	scriptByAddrFunc func(addr proto.WavesAddress) (*ast.Tree, error)
 
+	// scriptByAddrAtHeightFunc mocks the scriptByAddrAtHeight method.
+	scriptByAddrAtHeightFunc func(addr proto.WavesAddress, height proto.Height) (*ast.Tree, error)
+
 	// scriptByAssetFunc mocks the scriptByAsset method.
 	scriptByAssetFunc func(assetID proto.AssetID) (*ast.Tree, error)
 
@@ -260,6 +279,16 @@ type mockScriptStorageState struct {
 			// AssetID is the assetID argument value.
 			AssetID proto.AssetID
 		}
+		// newestProgramByAddr holds details about calls to the newestProgramByAddr method.
+		newestProgramByAddr []struct {
+			// Addr is the addr argument value.
+			Addr proto.WavesAddress
+		}
+		// newestProgramByAsset holds details about calls to the newestProgramByAsset method.
+		newestProgramByAsset []struct {
+			// AssetID is the assetID argument value.
+			AssetID proto.AssetID
+		}
 		// newestScriptBasicInfoByAddressID holds details about calls to the newestScriptBasicInfoByAddressID method.
 		newestScriptBasicInfoByAddressID []struct {
 			// AddressID is the addressID argument value.
@@ -301,6 +330,13 @@ type mockScriptStorageState struct {
 			// Addr is the addr argument value.
 			Addr proto.WavesAddress
 		}
+		// scriptByAddrAtHeight holds details about calls to the scriptByAddrAtHeight method.
+		scriptByAddrAtHeight []struct {
+			// Addr is the addr argument value.
+			Addr proto.WavesAddress
+			// Height is the height argument value.
+			Height proto.Height
+		}
 		// scriptByAsset holds details about calls to the scriptByAsset method.
 		scriptByAsset []struct {
 			// AssetID is the assetID argument value.
@@ -362,6 +398,8 @@ type mockScriptStorageState struct {
 	locknewestAccountHasVerifier         sync.RWMutex
 	locknewestAccountIsDApp              sync.RWMutex
 	locknewestIsSmartAsset               sync.RWMutex
+	locknewestProgramByAddr              sync.RWMutex
+	locknewestProgramByAsset             sync.RWMutex
 	locknewestScriptBasicInfoByAddressID sync.RWMutex
 	locknewestScriptByAddr               sync.RWMutex
 	locknewestScriptByAsset              sync.RWMutex
@@ -371,6 +409,7 @@ type mockScriptStorageState struct {
 	lockreset                            sync.RWMutex
 	lockscriptBasicInfoByAddressID       sync.RWMutex
 	lockscriptByAddr                     sync.RWMutex
+	lockscriptByAddrAtHeight             sync.RWMutex
 	lockscriptByAsset                    sync.RWMutex
 	lockscriptBytesByAddr                sync.RWMutex
 	lockscriptBytesByAsset               sync.RWMutex
@@ -776,6 +815,70 @@ func (mock *mockScriptStorageState) newestIsSmartAssetCalls() []struct {
 	return calls
 }
 
+// newestProgramByAddr calls newestProgramByAddrFunc.
+func (mock *mockScriptStorageState) newestProgramByAddr(addr proto.WavesAddress) (*ride.Program, error) {
+	if mock.newestProgramByAddrFunc == nil {
+		panic("mockScriptStorageState.newestProgramByAddrFunc: method is nil but scriptStorageState.newestProgramByAddr was just called")
+	}
+	callInfo := struct {
+		Addr proto.WavesAddress
+	}{
+		Addr: addr,
+	}
+	mock.locknewestProgramByAddr.Lock()
+	mock.calls.newestProgramByAddr = append(mock.calls.newestProgramByAddr, callInfo)
+	mock.locknewestProgramByAddr.Unlock()
+	return mock.newestProgramByAddrFunc(addr)
+}
+
+// newestProgramByAddrCalls gets all the calls that were made to newestProgramByAddr.
+// Check the length with:
+//
+//	len(mockedscriptStorageState.newestProgramByAddrCalls())
+func (mock *mockScriptStorageState) newestProgramByAddrCalls() []struct {
+	Addr proto.WavesAddress
+} {
+	var calls []struct {
+		Addr proto.WavesAddress
+	}
+	mock.locknewestProgramByAddr.RLock()
+	calls = mock.calls.newestProgramByAddr
+	mock.locknewestProgramByAddr.RUnlock()
+	return calls
+}
+
+// newestProgramByAsset calls newestProgramByAssetFunc.
+func (mock *mockScriptStorageState) newestProgramByAsset(assetID proto.AssetID) (*ride.Program, error) {
+	if mock.newestProgramByAssetFunc == nil {
+		panic("mockScriptStorageState.newestProgramByAssetFunc: method is nil but scriptStorageState.newestProgramByAsset was just called")
+	}
+	callInfo := struct {
+		AssetID proto.AssetID
+	}{
+		AssetID: assetID,
+	}
+	mock.locknewestProgramByAsset.Lock()
+	mock.calls.newestProgramByAsset = append(mock.calls.newestProgramByAsset, callInfo)
+	mock.locknewestProgramByAsset.Unlock()
+	return mock.newestProgramByAssetFunc(assetID)
+}
+
+// newestProgramByAssetCalls gets all the calls that were made to newestProgramByAsset.
+// Check the length with:
+//
+//	len(mockedscriptStorageState.newestProgramByAssetCalls())
+func (mock *mockScriptStorageState) newestProgramByAssetCalls() []struct {
+	AssetID proto.AssetID
+} {
+	var calls []struct {
+		AssetID proto.AssetID
+	}
+	mock.locknewestProgramByAsset.RLock()
+	calls = mock.calls.newestProgramByAsset
+	mock.locknewestProgramByAsset.RUnlock()
+	return calls
+}
+
 // newestScriptBasicInfoByAddressID calls newestScriptBasicInfoByAddressIDFunc.
 func (mock *mockScriptStorageState) newestScriptBasicInfoByAddressID(addressID proto.AddressID) (scriptBasicInfoRecord, error) {
 	if mock.newestScriptBasicInfoByAddressIDFunc == nil {
@@ -1054,6 +1157,42 @@ func (mock *mockScriptStorageState) scriptByAddrCalls() []struct {
 	return calls
 }
 
+// scriptByAddrAtHeight calls scriptByAddrAtHeightFunc.
+func (mock *mockScriptStorageState) scriptByAddrAtHeight(addr proto.WavesAddress, height proto.Height) (*ast.Tree, error) {
+	if mock.scriptByAddrAtHeightFunc == nil {
+		panic("mockScriptStorageState.scriptByAddrAtHeightFunc: method is nil but scriptStorageState.scriptByAddrAtHeight was just called")
+	}
+	callInfo := struct {
+		Addr   proto.WavesAddress
+		Height proto.Height
+	}{
+		Addr:   addr,
+		Height: height,
+	}
+	mock.lockscriptByAddrAtHeight.Lock()
+	mock.calls.scriptByAddrAtHeight = append(mock.calls.scriptByAddrAtHeight, callInfo)
+	mock.lockscriptByAddrAtHeight.Unlock()
+	return mock.scriptByAddrAtHeightFunc(addr, height)
+}
+
+// scriptByAddrAtHeightCalls gets all the calls that were made to scriptByAddrAtHeight.
+// Check the length with:
+//
+//	len(mockedscriptStorageState.scriptByAddrAtHeightCalls())
+func (mock *mockScriptStorageState) scriptByAddrAtHeightCalls() []struct {
+	Addr   proto.WavesAddress
+	Height proto.Height
+} {
+	var calls []struct {
+		Addr   proto.WavesAddress
+		Height proto.Height
+	}
+	mock.lockscriptByAddrAtHeight.RLock()
+	calls = mock.calls.scriptByAddrAtHeight
+	mock.lockscriptByAddrAtHeight.RUnlock()
+	return calls
+}
+
 // scriptByAsset calls scriptByAssetFunc.
 func (mock *mockScriptStorageState) scriptByAsset(assetID proto.AssetID) (*ast.Tree, error) {
 	if mock.scriptByAssetFunc == nil {
@@ -122,6 +122,25 @@ func TestSetAccountScript(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestAccountScriptAtHeight(t *testing.T) {
+	to := createScriptsStorageTestObjects(t)
+
+	addr := testGlobal.senderInfo.addr
+	to.stor.addBlock(t, blockID0)
+	err := to.scriptsStorage.setAccountScript(addr, testGlobal.scriptBytes, testGlobal.senderInfo.pk, blockID0)
+	require.NoError(t, err)
+	to.stor.addBlock(t, blockID1)
+	err = to.scriptsStorage.setAccountScript(addr, proto.Script{}, testGlobal.senderInfo.pk, blockID1)
+	require.NoError(t, err)
+	to.stor.flush(t)
+
+	scriptAst, err := to.scriptsStorage.scriptByAddrAtHeight(addr, 1)
+	require.NoError(t, err)
+	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	_, err = to.scriptsStorage.scriptByAddrAtHeight(addr, 2)
+	assert.True(t, IsNotFound(err))
+}
+
 func TestSetAssetScript(t *testing.T) {
 	to := createScriptsStorageTestObjects(t)
 
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/mr-tron/base58"
 	"github.com/pkg/errors"
@@ -437,15 +438,20 @@ type stateManager struct {
 	enableLightNode bool
 }
 
+// KeyValueDir returns the path to the key-value database of the state stored in dataDir.
+func KeyValueDir(dataDir string) string {
+	return filepath.Join(dataDir, keyvalueDir)
+}
+
 func initDatabase(
 	dataDir, blockStorageDir string,
 	amend bool,
 	params StateParams,
-) (_ *keyvalue.KeyVal, _ keyvalue.Batch, _ *stateDB, _ bool, retErr error) {
-	dbDir := filepath.Join(dataDir, keyvalueDir)
+) (_ keyvalue.IterableKeyVal, _ keyvalue.Batch, _ *stateDB, _ bool, retErr error) {
+	dbDir := KeyValueDir(dataDir)
 	zap.S().Info("Initializing state database, will take up to few minutes...")
 	params.DbParams.BloomFilterParams.Store.WithPath(filepath.Join(blockStorageDir, "bloom"))
-	db, err := keyvalue.NewKeyVal(dbDir, params.DbParams)
+	db, err := keyvalue.NewKeyValue(dbDir, params.DbParams)
 	if err != nil {
 		return nil, nil, nil, false, wrapErr(Other, errors.Wrap(err, "failed to create db"))
 	}
@@ -603,7 +609,8 @@ func newStateManager(
 	// Set fields which depend on state.
 	// Consensus validator is needed to check block headers.
 	snapshotApplier := newBlockSnapshotsApplier(nil, newSnapshotApplierStorages(stor, rw))
-	appender, err := newTxAppender(state, rw, stor, settings, sdb, atx, &snapshotApplier)
+	executor := newRideExecutor(params.RideExecution)
+	appender, err := newTxAppender(state, rw, stor, settings, sdb, atx, &snapshotApplier, executor)
 	if err != nil {
 		return nil, wrapErr(Other, err)
 	}
@@ -641,6 +648,21 @@ func (s *stateManager) NewestScriptByAccount(account proto.Recipient) (*ast.Tree
 	return tree, nil
 }
 
+func (s *stateManager) ScriptByAccountAtHeight(account proto.Recipient, height proto.Height) (*ast.Tree, error) {
+	if err := s.checkHistoryHeight(height); err != nil {
+		return nil, err
+	}
+	addr, err := s.recipientToAddress(account)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	tree, err := s.stor.scriptsStorage.scriptByAddrAtHeight(addr, height)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	return tree, nil
+}
+
 func (s *stateManager) NewestScriptBytesByAccount(account proto.Recipient) (proto.Script, error) {
 	addr, err := s.NewestRecipientToAddress(account)
 	if err != nil {
@@ -1213,6 +1235,127 @@ func (s *stateManager) AssetBalance(account proto.Recipient, assetID proto.Asset
 	return balance, nil
 }
 
+// checkHistoryHeight checks that the history of changes is kept for the given height.
+func (s *stateManager) checkHistoryHeight(height proto.Height) error {
+	maxHeight, err := s.Height()
+	if err != nil {
+		return wrapErr(RetrievalError, err)
+	}
+	minHeight, err := s.stateDB.getRollbackMinHeight()
+	if err != nil {
+		return wrapErr(RetrievalError, err)
+	}
+	if height < minHeight || height > maxHeight {
+		return wrapErr(InvalidInputError,
+			errors.Errorf("height %d is outside of the available history range [%d, %d]", height, minHeight, maxHeight))
+	}
+	return nil
+}
+
+func (s *stateManager) WavesBalanceAtHeight(account proto.Recipient, height proto.Height) (uint64, error) {
+	if err := s.checkHistoryHeight(height); err != nil {
+		return 0, err
+	}
+	addr, err := s.recipientToAddress(account)
+	if err != nil {
+		return 0, wrapErr(RetrievalError, err)
+	}
+	profile, err := s.stor.balances.wavesBalanceAtHeight(addr.ID(), height)
+	if err != nil {
+		return 0, wrapErr(RetrievalError, err)
+	}
+	return profile.balance, nil
+}
+
+func (s *stateManager) AssetBalanceAtHeight(
+	account proto.Recipient,
+	assetID proto.AssetID,
+	height proto.Height,
+) (uint64, error) {
+	if err := s.checkHistoryHeight(height); err != nil {
+		return 0, err
+	}
+	addr, err := s.recipientToAddress(account)
+	if err != nil {
+		return 0, wrapErr(RetrievalError, err)
+	}
+	balance, err := s.stor.balances.assetBalanceAtHeight(addr.ID(), assetID, height)
+	if err != nil {
+		return 0, wrapErr(RetrievalError, err)
+	}
+	return balance, nil
+}
+
+func (s *stateManager) MinWavesBalanceInRange(account proto.Recipient, startHeight, endHeight proto.Height) (uint64, error) {
+	addr, err := s.recipientToAddress(account)
+	if err != nil {
+		return 0, wrapErr(RetrievalError, err)
+	}
+	balance, err := s.stor.balances.minBalanceInRange(addr.ID(), startHeight, endHeight)
+	if err != nil {
+		return 0, wrapErr(RetrievalError, err)
+	}
+	return balance, nil
+}
+
+func (s *stateManager) MinEffectiveBalanceInRange(
+	account proto.Recipient,
+	startHeight, endHeight proto.Height,
+) (uint64, error) {
+	addr, err := s.recipientToAddress(account)
+	if err != nil {
+		return 0, wrapErr(RetrievalError, err)
+	}
+	balance, err := s.stor.balances.minEffectiveBalanceInRange(addr.ID(), startHeight, endHeight)
+	if err != nil {
+		if isNotFoundInHistoryOrDBErr(err) {
+			return 0, nil
+		}
+		return 0, wrapErr(RetrievalError, err)
+	}
+	return balance, nil
+}
+
+func (s *stateManager) AssetBalances(account proto.Recipient) (map[crypto.Digest]uint64, error) {
+	addr, err := s.recipientToAddress(account)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	balances, err := s.stor.balances.assetBalances(addr.ID())
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	return balances, nil
+}
+
+func (s *stateManager) AssetDistribution(
+	assetID proto.AssetID, height proto.Height, after *proto.WavesAddress, limit int,
+) ([]AssetHolder, bool, error) {
+	if height != 0 {
+		if err := s.checkHistoryHeight(height); err != nil {
+			return nil, false, err
+		}
+	}
+	var afterID *proto.AddressID
+	if after != nil {
+		id := after.ID()
+		afterID = &id
+	}
+	distribution, hasNext, err := s.stor.balances.assetDistribution(assetID, height, afterID, limit)
+	if err != nil {
+		return nil, false, wrapErr(RetrievalError, err)
+	}
+	res := make([]AssetHolder, len(distribution))
+	for i, item := range distribution {
+		addr, err := item.address.ToWavesAddress(s.settings.AddressSchemeCharacter)
+		if err != nil {
+			return nil, false, wrapErr(RetrievalError, err)
+		}
+		res[i] = AssetHolder{Address: addr, Balance: item.balance}
+	}
+	return res, hasNext, nil
+}
+
 func (s *stateManager) WavesAddressesNumber() (uint64, error) {
 	res, err := s.stor.balances.wavesAddressesNumber()
 	if err != nil {
@@ -1879,6 +2022,8 @@ func (s *stateManager) addBlocks() (_ *proto.Block, retErr error) { //nolint:non
 		return nil, wrapErr(RetrievalError, hErr)
 	}
 	headers := make([]proto.BlockHeader, blocksNumber)
+	start := time.Now()
+	s.appender.sc.scriptsDuration = 0
 
 	// Launch verifier that checks signatures of blocks and transactions.
 	chans := launchVerifier(ctx, s.verificationGoroutinesNum, s.settings.AddressSchemeCharacter)
@@ -1920,9 +2065,12 @@ func (s *stateManager) addBlocks() (_ *proto.Block, retErr error) { //nolint:non
 		return nil, wrapErr(ValidationError, vErr)
 	}
 	// After everything is validated, save all the changes to DB.
+	validated := time.Now()
 	if fErr := s.flush(); fErr != nil {
 		return nil, wrapErr(ModificationError, fErr)
 	}
+	scripts := s.appender.sc.scriptsDuration
+	observeBlocksApply(blocksNumber, validated.Sub(start)-scripts, scripts, time.Since(validated))
 	zap.S().Infof(
 		"Height: %d; Block ID: %s, GenSig: %s, ts: %d",
 		height+uint64(blocksNumber),
@@ -2394,6 +2542,25 @@ func (s *stateManager) RetrieveEntry(account proto.Recipient, key string) (proto
 	return entry, nil
 }
 
+func (s *stateManager) RetrieveEntryAtHeight(
+	account proto.Recipient,
+	key string,
+	height proto.Height,
+) (proto.DataEntry, error) {
+	if err := s.checkHistoryHeight(height); err != nil {
+		return nil, err
+	}
+	addr, err := s.recipientToAddress(account)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	entry, err := s.stor.accountsDataStor.retrieveEntryAtHeight(addr, key, height)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	return entry, nil
+}
+
 func (s *stateManager) RetrieveNewestIntegerEntry(account proto.Recipient, key string) (*proto.IntegerDataEntry, error) {
 	addr, err := s.NewestRecipientToAddress(account)
 	if err != nil {
@@ -2939,6 +3106,28 @@ func (s *stateManager) storesExtendedApiData() (bool, error) {
 	return stores, nil
 }
 
+func (s *stateManager) MinFee(tx proto.Transaction) (uint64, error) {
+	rideV5Activated, err := s.stor.features.newestIsActivated(int16(settings.RideV5))
+	if err != nil {
+		return 0, wrapErr(RetrievalError, err)
+	}
+	smartAssets, err := feeSmartAssets(s.stor, tx)
+	if err != nil {
+		return 0, wrapErr(RetrievalError, err)
+	}
+	params := &feeValidationParams{
+		stor:            s.stor,
+		settings:        s.settings,
+		txAssets:        &txAssets{feeAsset: tx.GetFeeAsset(), smartAssets: smartAssets},
+		rideV5Activated: rideV5Activated,
+	}
+	fee, err := minFee(tx, params)
+	if err != nil {
+		return 0, wrapErr(TxValidationError, err)
+	}
+	return fee, nil
+}
+
 func (s *stateManager) ProvidesExtendedApi() (bool, error) {
 	hasData, err := s.storesExtendedApiData()
 	if err != nil {
@@ -3,9 +3,11 @@ package state
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	stderrs "errors"
 	"fmt"
 	"math/big"
+	"os"
 	"path/filepath"
 	"sync"
 	"testing"
@@ -311,6 +313,45 @@ func TestStateIntegrated(t *testing.T) {
 	}
 }
 
+func TestWavesBalanceAtHeight(t *testing.T) {
+	dir, err := getLocalDir()
+	require.NoError(t, err)
+	blocksPath, err := blocksPath()
+	require.NoError(t, err)
+	bs := settings.MustMainNetSettings()
+	manager := newTestStateManager(t, true, DefaultTestingStateParams(), bs)
+	err = importer.ApplyFromFile(
+		context.Background(),
+		importer.ImportParams{Schema: bs.AddressSchemeCharacter, BlockchainPath: blocksPath, LightNodeMode: false},
+		manager, blocksToImport, 1)
+	require.NoError(t, err)
+
+	for _, height := range []proto.Height{1, 31, 901} {
+		data, rErr := os.ReadFile(filepath.Join(dir, "testdata", fmt.Sprintf("accounts-%d", height)))
+		require.NoError(t, rErr)
+		var expected map[string]uint64
+		require.NoError(t, json.Unmarshal(data, &expected))
+		for addrStr, expectedBalance := range expected {
+			addr, aErr := proto.NewAddressFromString(addrStr)
+			require.NoError(t, aErr)
+			balance, bErr := manager.WavesBalanceAtHeight(proto.NewRecipientFromAddress(addr), height)
+			require.NoError(t, bErr)
+			assert.Equal(t, expectedBalance, balance, "address %s at height %d", addrStr, height)
+		}
+	}
+	addr, err := proto.NewAddressFromString("3P8dpAGBNsECCcZKohYtGNgQtkSLx1dvgA1")
+	require.NoError(t, err)
+	rcp := proto.NewRecipientFromAddress(addr)
+	for _, height := range []proto.Height{0, blocksToImport + 2} {
+		_, err = manager.WavesBalanceAtHeight(rcp, height)
+		assert.True(t, IsInvalidInput(err), "height %d", height)
+		_, err = manager.AssetBalanceAtHeight(rcp, proto.AssetID{}, height)
+		assert.True(t, IsInvalidInput(err), "height %d", height)
+		_, err = manager.RetrieveEntryAtHeight(rcp, "key", height)
+		assert.True(t, IsInvalidInput(err), "height %d", height)
+	}
+}
+
 func TestPreactivatedFeatures(t *testing.T) {
 	blocksPath, err := blocksPath()
 	assert.NoError(t, err)
@@ -523,6 +564,7 @@ func createMockStateManager(t *testing.T, bs *settings.BlockchainSettings) (*sta
 		state.stateDB,
 		state.atx,
 		&snapshotApplier,
+		nil, // scripts are evaluated by tree evaluator
 	)
 	require.NoError(t, err, "newTxAppender() failed")
 	state.appender = appender
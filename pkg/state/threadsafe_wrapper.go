@@ -104,6 +104,54 @@ func (a *ThreadSafeReadWrapper) AssetBalance(account proto.Recipient, asset prot
 	return a.s.AssetBalance(account, asset)
 }
 
+func (a *ThreadSafeReadWrapper) WavesBalanceAtHeight(account proto.Recipient, height proto.Height) (uint64, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.WavesBalanceAtHeight(account, height)
+}
+
+func (a *ThreadSafeReadWrapper) AssetBalanceAtHeight(
+	account proto.Recipient,
+	asset proto.AssetID,
+	height proto.Height,
+) (uint64, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.AssetBalanceAtHeight(account, asset, height)
+}
+
+func (a *ThreadSafeReadWrapper) MinWavesBalanceInRange(
+	account proto.Recipient,
+	startHeight, endHeight proto.Height,
+) (uint64, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.MinWavesBalanceInRange(account, startHeight, endHeight)
+}
+
+func (a *ThreadSafeReadWrapper) MinEffectiveBalanceInRange(
+	account proto.Recipient,
+	startHeight, endHeight proto.Height,
+) (uint64, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.MinEffectiveBalanceInRange(account, startHeight, endHeight)
+}
+
+func (a *ThreadSafeReadWrapper) AssetBalances(account proto.Recipient) (map[crypto.Digest]uint64, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.AssetBalances(account)
+}
+
+func (a *ThreadSafeReadWrapper) AssetDistribution(
+	assetID proto.AssetID, height proto.Height, after *proto.WavesAddress, limit int,
+) ([]AssetHolder, bool, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.AssetDistribution(assetID, height, after, limit)
+}
+
 func (a *ThreadSafeReadWrapper) WavesAddressesNumber() (uint64, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -212,6 +260,16 @@ func (a *ThreadSafeReadWrapper) RetrieveEntry(account proto.Recipient, key strin
 	return a.s.RetrieveEntry(account, key)
 }
 
+func (a *ThreadSafeReadWrapper) RetrieveEntryAtHeight(
+	account proto.Recipient,
+	key string,
+	height proto.Height,
+) (proto.DataEntry, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.RetrieveEntryAtHeight(account, key, height)
+}
+
 func (a *ThreadSafeReadWrapper) RetrieveIntegerEntry(account proto.Recipient, key string) (*proto.IntegerDataEntry, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -256,6 +314,12 @@ func (a *ThreadSafeReadWrapper) TransactionHeightByID(id []byte) (uint64, error)
 	return a.s.TransactionHeightByID(id)
 }
 
+func (a *ThreadSafeReadWrapper) TraceInvoke(id []byte, maxSteps int) (*InvokeTrace, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.TraceInvoke(id, maxSteps)
+}
+
 func (a *ThreadSafeReadWrapper) NewAddrTransactionsIterator(addr proto.Address) (TransactionIterator, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -322,6 +386,12 @@ func (a *ThreadSafeReadWrapper) NewestScriptByAccount(recipient proto.Recipient)
 	return a.s.NewestScriptByAccount(recipient)
 }
 
+func (a *ThreadSafeReadWrapper) ScriptByAccountAtHeight(account proto.Recipient, height proto.Height) (*ast.Tree, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.ScriptByAccountAtHeight(account, height)
+}
+
 func (a *ThreadSafeReadWrapper) NewestScriptBytesByAccount(recipient proto.Recipient) (proto.Script, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -364,6 +434,12 @@ func (a *ThreadSafeReadWrapper) CreateNextSnapshotHash(block *proto.Block) (cryp
 	return a.s.CreateNextSnapshotHash(block)
 }
 
+func (a *ThreadSafeReadWrapper) MinFee(tx proto.Transaction) (uint64, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.s.MinFee(tx)
+}
+
 func (a *ThreadSafeReadWrapper) ProvidesExtendedApi() (bool, error) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -440,6 +516,15 @@ func (a *ThreadSafeWriteWrapper) ValidateNextTx(
 	panic("Invalid ValidateNextTx usage on thread safe wrapper. Should call TxValidation")
 }
 
+func (a *ThreadSafeWriteWrapper) DryRunNextTx(
+	_ proto.Transaction,
+	_, _ uint64,
+	_ proto.BlockVersion,
+	_ bool,
+) (*DryRunResult, error) {
+	panic("Invalid DryRunNextTx usage on thread safe wrapper. Should call TxValidation")
+}
+
 func (a *ThreadSafeWriteWrapper) ResetValidationList() {
 	panic("invalid ResetValidationList usage")
 }
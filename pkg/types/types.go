@@ -29,6 +29,32 @@ type UtxPool interface {
 	AllTransactions() []*TransactionWithBytes
 	Count() int
 	ExistsByID(id []byte) bool
+	Details() UtxPoolDetails
+}
+
+// UtxPoolDetails is the snapshot of UTX pool contents for inspection.
+type UtxPoolDetails struct {
+	Size         uint64 // Total size of transactions in bytes.
+	Transactions []UtxTransactionDetails
+	Evictions    []UtxEviction // Recent evictions, the oldest first.
+}
+
+// UtxTransactionDetails describes the transaction in UTX pool and its priority.
+type UtxTransactionDetails struct {
+	ID         crypto.Digest
+	Sender     string
+	DApp       string // Empty if transaction is not an invocation.
+	Size       int
+	Fee        uint64
+	FeeInWaves uint64
+	Complexity uint64
+}
+
+// UtxEviction describes the transaction evicted from UTX pool in favor of transaction of higher priority.
+type UtxEviction struct {
+	ID        crypto.Digest
+	Reason    string
+	Timestamp time.Time
 }
 
 type TransactionWithBytes struct {
@@ -185,7 +211,7 @@ type InvRequester interface {
 type BaseTarget = uint64
 
 type Miner interface {
-	MineKeyBlock(ctx context.Context, t proto.Timestamp, k proto.KeyPair, parent proto.BlockID, baseTarget BaseTarget, gs []byte, vrf []byte) (*proto.Block, proto.MiningLimits, error)
+	MineKeyBlock(ctx context.Context, t proto.Timestamp, pk crypto.PublicKey, parent proto.BlockID, baseTarget BaseTarget, gs []byte, vrf []byte) (*proto.Block, proto.MiningLimits, error)
 }
 
 type Time interface {
@@ -201,8 +227,19 @@ type MinerConsensus interface {
 	IsMiningAllowed() bool
 }
 
-type EmbeddedWallet interface {
+// Signer signs blocks, micro blocks, transactions and generates VRF proofs on behalf of accounts identified by
+// public keys. The secret keys of accounts may be held outside the node, by the remote signer.
+type Signer interface {
+	PublicKeys() ([]crypto.PublicKey, error)
 	SignTransactionWith(pk crypto.PublicKey, tx proto.Transaction) error
+	SignBlock(pk crypto.PublicKey, b *proto.Block) error
+	SignMicroBlock(pk crypto.PublicKey, m *proto.MicroBlock) error
+	SignMicroBlockInv(pk crypto.PublicKey, inv *proto.MicroBlockInv) error
+	SignVRF(pk crypto.PublicKey, msg []byte) ([]byte, error)
+}
+
+type EmbeddedWallet interface {
+	Signer
 	Load(password []byte) error
 	AccountSeeds() [][]byte
 }
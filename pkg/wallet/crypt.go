@@ -58,3 +58,89 @@ func (a *crypt) Decrypt(ciphertext []byte) ([]byte, error) {
 
 	return ciphertext, nil
 }
+
+const (
+	kdfSaltSize    = 32
+	kdfKeySize     = 32
+	kdfTime        = 4
+	kdfMemory      = 64 * 1024
+	kdfThreads     = 4
+	kdfMaxTime     = 64
+	kdfMaxMemory   = 4 * 1024 * 1024 // 4 GiB in KiB.
+	kdfMinSaltSize = 16
+)
+
+// kdfParams are the parameters of Argon2id key derivation, they are stored in the wallet along with the random salt.
+type kdfParams struct {
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+func newKDFParams() (kdfParams, error) {
+	salt := make([]byte, kdfSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return kdfParams{}, errors.Wrap(err, "failed to generate salt")
+	}
+	return kdfParams{Salt: salt, Time: kdfTime, Memory: kdfMemory, Threads: kdfThreads}, nil
+}
+
+// validate checks the parameters read from the wallet, so the tampered wallet can't make the key derivation
+// unbearably expensive.
+func (p kdfParams) validate() error {
+	switch {
+	case len(p.Salt) < kdfMinSaltSize:
+		return errors.Errorf("invalid KDF salt size %d", len(p.Salt))
+	case p.Time == 0 || p.Time > kdfMaxTime:
+		return errors.Errorf("invalid KDF time %d", p.Time)
+	case p.Memory == 0 || p.Memory > kdfMaxMemory:
+		return errors.Errorf("invalid KDF memory %d", p.Memory)
+	case p.Threads == 0:
+		return errors.New("invalid KDF threads number 0")
+	default:
+		return nil
+	}
+}
+
+// aeadCrypt encrypts and authenticates data with AES-256-GCM under the key derived from the password.
+type aeadCrypt struct {
+	aead cipher.AEAD
+}
+
+func newAEADCrypt(password []byte, params kdfParams) (*aeadCrypt, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	key := argon2.IDKey(password, params.Salt, params.Time, params.Memory, params.Threads, kdfKeySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aeadCrypt{aead: aead}, nil
+}
+
+// Seal encrypts the plaintext with the random nonce, the additional data is authenticated but not encrypted.
+func (a *aeadCrypt) Seal(plaintext, additionalData []byte) (nonce, ciphertext []byte, err error) {
+	nonce = make([]byte, a.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, a.aead.Seal(nil, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts the ciphertext, the error is returned if the password is wrong or the data was modified.
+func (a *aeadCrypt) Open(nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != a.aead.NonceSize() {
+		return nil, errors.Errorf("invalid nonce size %d", len(nonce))
+	}
+	plaintext, err := a.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, InvalidPassword
+	}
+	return plaintext, nil
+}
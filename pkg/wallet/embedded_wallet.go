@@ -18,18 +18,70 @@ type EmbeddedWalletImpl struct {
 	mu     sync.Mutex
 }
 
-func (a *EmbeddedWalletImpl) SignTransactionWith(pk crypto.PublicKey, tx proto.Transaction) error {
-	seeds := a.seeder.AccountSeeds()
-	for _, s := range seeds {
+func (a *EmbeddedWalletImpl) secretKey(pk crypto.PublicKey) (crypto.SecretKey, error) {
+	for _, s := range a.AccountSeeds() {
 		secret, public, err := crypto.GenerateKeyPair(s)
 		if err != nil {
-			return err
+			return crypto.SecretKey{}, err
 		}
 		if public == pk {
-			return tx.Sign(a.scheme, secret)
+			return secret, nil
 		}
 	}
-	return PublicKeyNotFound
+	return crypto.SecretKey{}, PublicKeyNotFound
+}
+
+func (a *EmbeddedWalletImpl) PublicKeys() ([]crypto.PublicKey, error) {
+	seeds := a.AccountSeeds()
+	pks := make([]crypto.PublicKey, 0, len(seeds))
+	for _, s := range seeds {
+		_, public, err := crypto.GenerateKeyPair(s)
+		if err != nil {
+			return nil, err
+		}
+		pks = append(pks, public)
+	}
+	return pks, nil
+}
+
+func (a *EmbeddedWalletImpl) SignTransactionWith(pk crypto.PublicKey, tx proto.Transaction) error {
+	sk, err := a.secretKey(pk)
+	if err != nil {
+		return err
+	}
+	return tx.Sign(a.scheme, sk)
+}
+
+func (a *EmbeddedWalletImpl) SignBlock(pk crypto.PublicKey, b *proto.Block) error {
+	sk, err := a.secretKey(pk)
+	if err != nil {
+		return err
+	}
+	return b.Sign(a.scheme, sk)
+}
+
+func (a *EmbeddedWalletImpl) SignMicroBlock(pk crypto.PublicKey, m *proto.MicroBlock) error {
+	sk, err := a.secretKey(pk)
+	if err != nil {
+		return err
+	}
+	return m.Sign(a.scheme, sk)
+}
+
+func (a *EmbeddedWalletImpl) SignMicroBlockInv(pk crypto.PublicKey, inv *proto.MicroBlockInv) error {
+	sk, err := a.secretKey(pk)
+	if err != nil {
+		return err
+	}
+	return inv.Sign(sk, a.scheme)
+}
+
+func (a *EmbeddedWalletImpl) SignVRF(pk crypto.PublicKey, msg []byte) ([]byte, error) {
+	sk, err := a.secretKey(pk)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.SignVRF(sk, msg)
 }
 
 func (a *EmbeddedWalletImpl) Load(password []byte) error {
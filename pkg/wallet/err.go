@@ -3,3 +3,5 @@ package wallet
 import "errors"
 
 var PublicKeyNotFound = errors.New("public key not found")
+
+var InvalidPassword = errors.New("invalid password or corrupted wallet")
@@ -3,20 +3,53 @@ package wallet
 import (
 	"encoding/binary"
 	"encoding/json"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/util/common"
 )
 
-const curVersion = 1
+const (
+	// LegacyVersion is the version of wallet with the list of seeds encrypted by AES-CFB under the key derived
+	// with the hard-coded salt. It can be read, but it's never written.
+	LegacyVersion = 1
+	curVersion    = 2
+
+	versionSize = 4
+)
+
+// Account is the account seed stored in the wallet along with its description.
+type Account struct {
+	Seed      []byte       `json:"seed"`
+	Label     string       `json:"label"`
+	Scheme    proto.Scheme `json:"scheme"` // Zero if the scheme is unknown.
+	CreatedAt time.Time    `json:"createdAt"`
+}
 
 type WalletFormat struct {
+	Accounts []Account `json:"accounts"`
+}
+
+// legacyWalletFormat is the content of wallet of LegacyVersion.
+type legacyWalletFormat struct {
 	Seed [][]byte `json:"seeds"`
 }
 
+// encryptedWallet is the wallet of the current version as it's stored after the version.
+type encryptedWallet struct {
+	KDF        kdfParams `json:"kdf"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
 type Wallet interface {
 	AccountSeeds() [][]byte
 	AddAccountSeed([]byte) error
+	Accounts() []Account
+	AddAccount(Account) error
+	RemoveAccount(i int) error
+	RenameAccount(i int, label string) error
 	Encode(pass []byte) ([]byte, error)
 }
 
@@ -26,54 +59,149 @@ type WalletImpl struct {
 }
 
 func (a *WalletImpl) AccountSeeds() [][]byte {
-	return a.format.Seed
+	seeds := make([][]byte, len(a.format.Accounts))
+	for i := range a.format.Accounts {
+		seeds[i] = a.format.Accounts[i].Seed
+	}
+	return seeds
 }
 
 func NewWallet() *WalletImpl {
 	return &WalletImpl{
-		format: WalletFormat{},
+		Version: curVersion,
+		format:  WalletFormat{},
 	}
 }
 
+// AddAccountSeed adds the account without label and scheme.
 func (a *WalletImpl) AddAccountSeed(seed []byte) error {
-	s := common.Dup(seed)
-	a.format.Seed = append(a.format.Seed, s)
+	return a.AddAccount(Account{Seed: seed, CreatedAt: time.Now().UTC()})
+}
+
+func (a *WalletImpl) Accounts() []Account {
+	return a.format.Accounts
+}
+
+func (a *WalletImpl) AddAccount(account Account) error {
+	if len(account.Seed) == 0 {
+		return errors.New("empty account seed")
+	}
+	account.Seed = common.Dup(account.Seed)
+	a.format.Accounts = append(a.format.Accounts, account)
 	return nil
 }
 
-func (a *WalletImpl) Encode(password []byte) ([]byte, error) {
+func (a *WalletImpl) RemoveAccount(i int) error {
+	if err := a.checkIndex(i); err != nil {
+		return err
+	}
+	a.format.Accounts = append(a.format.Accounts[:i], a.format.Accounts[i+1:]...)
+	return nil
+}
 
-	crypt := NewCrypt(password)
+func (a *WalletImpl) RenameAccount(i int, label string) error {
+	if err := a.checkIndex(i); err != nil {
+		return err
+	}
+	a.format.Accounts[i].Label = label
+	return nil
+}
+
+func (a *WalletImpl) checkIndex(i int) error {
+	if i < 0 || i >= len(a.format.Accounts) {
+		return errors.Errorf("account number %d is out of range [0, %d)", i, len(a.format.Accounts))
+	}
+	return nil
+}
+
+// Encode encrypts the wallet with the password. The wallet is always encoded in the current version with
+// the new random salt.
+func (a *WalletImpl) Encode(password []byte) ([]byte, error) {
 	walletData, err := json.Marshal(a.format)
 	if err != nil {
 		return nil, err
 	}
-
-	rs, err := crypt.Encrypt(walletData)
+	params, err := newKDFParams()
 	if err != nil {
 		return nil, err
 	}
-	rs = append(make([]byte, 4), rs...)
-	binary.BigEndian.PutUint32(rs[:4], curVersion)
-	return rs, nil
-}
-
-func Decode(walletData []byte, password []byte) (Wallet, error) {
-	version := binary.BigEndian.Uint32(walletData[:4])
-	walletData = walletData[4:]
-	crypt := NewCrypt(password)
-	bts, err := crypt.Decrypt(walletData)
+	crypt, err := newAEADCrypt(password, params)
+	if err != nil {
+		return nil, err
+	}
+	version := binary.BigEndian.AppendUint32(nil, curVersion)
+	nonce, ciphertext, err := crypt.Seal(walletData, version)
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := json.Marshal(encryptedWallet{KDF: params, Nonce: nonce, Ciphertext: ciphertext})
 	if err != nil {
 		return nil, err
 	}
+	return append(version, envelope...), nil
+}
 
-	format := WalletFormat{}
-	err = json.Unmarshal(bts, &format)
+// Decode decrypts the wallet of the current or legacy version.
+func Decode(walletData []byte, password []byte) (Wallet, error) {
+	if len(walletData) < versionSize {
+		return nil, errors.Errorf("invalid wallet size %d", len(walletData))
+	}
+	version := binary.BigEndian.Uint32(walletData[:versionSize])
+	var (
+		format WalletFormat
+		err    error
+	)
+	switch version {
+	case LegacyVersion:
+		format, err = decodeLegacy(walletData[versionSize:], password)
+	case curVersion:
+		format, err = decode(walletData[:versionSize], walletData[versionSize:], password)
+	default:
+		return nil, errors.Errorf("unsupported wallet version %d", version)
+	}
 	if err != nil {
-		return nil, errors.New("invalid password")
+		return nil, err
 	}
 	return &WalletImpl{
 		Version: version,
 		format:  format,
 	}, nil
 }
+
+func decode(version, walletData, password []byte) (WalletFormat, error) {
+	var envelope encryptedWallet
+	if err := json.Unmarshal(walletData, &envelope); err != nil {
+		return WalletFormat{}, errors.Wrap(err, "invalid wallet data")
+	}
+	crypt, err := newAEADCrypt(password, envelope.KDF)
+	if err != nil {
+		return WalletFormat{}, err
+	}
+	bts, err := crypt.Open(envelope.Nonce, envelope.Ciphertext, version)
+	if err != nil {
+		return WalletFormat{}, err
+	}
+	var format WalletFormat
+	if err := json.Unmarshal(bts, &format); err != nil {
+		return WalletFormat{}, errors.Wrap(err, "invalid wallet data")
+	}
+	return format, nil
+}
+
+func decodeLegacy(walletData, password []byte) (WalletFormat, error) {
+	crypt := NewCrypt(password)
+	bts, err := crypt.Decrypt(walletData)
+	if err != nil {
+		return WalletFormat{}, err
+	}
+	legacy := legacyWalletFormat{}
+	err = json.Unmarshal(bts, &legacy)
+	if err != nil {
+		return WalletFormat{}, InvalidPassword
+	}
+	format := WalletFormat{Accounts: make([]Account, len(legacy.Seed))}
+	for i, s := range legacy.Seed {
+		format.Accounts[i] = Account{Seed: s}
+	}
+	return format, nil
+}
@@ -1,7 +1,11 @@
 package wallet
 
 import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -46,3 +50,81 @@ func TestWallet_EncodeDecodeMultipleAccountSeeds(t *testing.T) {
 	_, err = Decode(bts, []byte("unknown password"))
 	require.Error(t, err)
 }
+
+func TestWallet_Accounts(t *testing.T) {
+	password := []byte("123456")
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	w := NewWallet()
+	require.NoError(t, w.AddAccount(Account{Seed: []byte("first"), Label: "miner", Scheme: 'T', CreatedAt: created}))
+	require.NoError(t, w.AddAccount(Account{Seed: []byte("second"), Label: "temp", Scheme: 'W', CreatedAt: created}))
+	require.NoError(t, w.AddAccount(Account{Seed: []byte("third"), Scheme: 'W', CreatedAt: created}))
+	require.Error(t, w.AddAccount(Account{}))
+	require.NoError(t, w.RemoveAccount(1))
+	require.NoError(t, w.RenameAccount(1, "payments"))
+	require.Error(t, w.RemoveAccount(2))
+	require.Error(t, w.RenameAccount(-1, "x"))
+
+	bts, err := w.Encode(password)
+	require.NoError(t, err)
+	w2, err := Decode(bts, password)
+	require.NoError(t, err)
+	assert.Equal(t, []Account{
+		{Seed: []byte("first"), Label: "miner", Scheme: 'T', CreatedAt: created},
+		{Seed: []byte("third"), Label: "payments", Scheme: 'W', CreatedAt: created},
+	}, w2.Accounts())
+}
+
+func TestWallet_DecodeLegacy(t *testing.T) {
+	password := []byte("123456")
+	seeds := [][]byte{[]byte("first"), []byte("second")}
+	data, err := json.Marshal(legacyWalletFormat{Seed: seeds})
+	require.NoError(t, err)
+	encrypted, err := NewCrypt(password).Encrypt(data)
+	require.NoError(t, err)
+	bts := append(binary.BigEndian.AppendUint32(nil, LegacyVersion), encrypted...)
+
+	w, err := Decode(bts, password)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(LegacyVersion), w.(*WalletImpl).Version)
+	assert.Equal(t, seeds, w.AccountSeeds())
+
+	_, err = Decode(bts, []byte("unknown password"))
+	assert.ErrorIs(t, err, InvalidPassword)
+
+	// Migration is just encoding of the decoded wallet.
+	migrated, err := w.Encode(password)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(curVersion), binary.BigEndian.Uint32(migrated))
+	w2, err := Decode(migrated, password)
+	require.NoError(t, err)
+	assert.Equal(t, seeds, w2.AccountSeeds())
+}
+
+func TestWallet_DecodeTampered(t *testing.T) {
+	password := []byte("123456")
+	w := NewWallet()
+	require.NoError(t, w.AddAccountSeed([]byte("seed")))
+	bts, err := w.Encode(password)
+	require.NoError(t, err)
+
+	_, err = Decode(bts, []byte("unknown password"))
+	assert.ErrorIs(t, err, InvalidPassword)
+
+	var envelope encryptedWallet
+	require.NoError(t, json.Unmarshal(bts[versionSize:], &envelope))
+	envelope.Ciphertext[0] ^= 0xff
+	tampered, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	_, err = Decode(append(bts[:versionSize:versionSize], tampered...), password)
+	assert.ErrorIs(t, err, InvalidPassword)
+
+	envelope.KDF.Memory = math.MaxUint32
+	tampered, err = json.Marshal(envelope)
+	require.NoError(t, err)
+	_, err = Decode(append(bts[:versionSize:versionSize], tampered...), password)
+	assert.ErrorContains(t, err, "invalid KDF memory")
+
+	_, err = Decode([]byte{0, 0}, password)
+	assert.Error(t, err)
+}